@@ -0,0 +1,37 @@
+//go:build js && wasm
+
+// Command urlsluice-wasm is the WebAssembly build of the extractors for browser tools:
+//
+//	GOOS=js GOARCH=wasm go build -o urlsluice.wasm ./cmd/urlsluice-wasm
+//
+// It registers a global urlsluice object whose extract(text, options) and
+// generateWordlist(urls) functions take and return JSON strings; urlsluice.js wraps them.
+package main
+
+import (
+	"context"
+	"syscall/js"
+
+	"github.com/PeteJStewart/urlsluice/internal/wasmapi"
+)
+
+func main() {
+	js.Global().Set("urlsluice", js.ValueOf(map[string]interface{}{
+		"extract": js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			return wasmapi.ExtractJSON(context.Background(), arg(args, 0), arg(args, 1))
+		}),
+		"generateWordlist": js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			return wasmapi.GenerateWordlistJSON(arg(args, 0))
+		}),
+	}))
+	// The functions are called from JavaScript for as long as the page lives
+	select {}
+}
+
+// arg returns the string argument at i, or "" when it was not passed
+func arg(args []js.Value, i int) string {
+	if i >= len(args) || args[i].Type() != js.TypeString {
+		return ""
+	}
+	return args[i].String()
+}
@@ -0,0 +1,37 @@
+// JavaScript wrapper of urlsluice.wasm, the WebAssembly build of the urlsluice extractors.
+// Load wasm_exec.js from the Go distribution first, which defines the Go class:
+//
+//   <script src="wasm_exec.js"></script>
+//   <script type="module">
+//     import { load } from "./urlsluice.js";
+//     const urlsluice = await load("urlsluice.wasm");
+//     const findings = urlsluice.extract(text, { emails: true, domains: true });
+//     const words = urlsluice.generateWordlist(["https://target.com/admin/users?id=1"]);
+//   </script>
+
+// load fetches and starts urlsluice.wasm and returns the extract and generateWordlist functions
+export async function load(url = "urlsluice.wasm") {
+  const go = new Go();
+  const { instance } = await WebAssembly.instantiateStreaming(fetch(url), go.importObject);
+  go.run(instance);
+  const api = globalThis.urlsluice;
+  return {
+    // extract returns the findings in text as they appear in -json output; options select the
+    // extractors with the names of the command line flags in camel case, e.g. { queryParams: true }
+    extract(text, options = {}) {
+      return call(api.extract(String(text), JSON.stringify(options))).findings ?? [];
+    },
+    // generateWordlist returns the sorted words of the paths and parameters of urls
+    generateWordlist(urls) {
+      return call(api.generateWordlist(JSON.stringify(urls))).words ?? [];
+    },
+  };
+}
+
+function call(result) {
+  const response = JSON.parse(result);
+  if (response.error) {
+    throw new Error(`urlsluice: ${response.error}`);
+  }
+  return response;
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/timestamps"
+	"github.com/PeteJStewart/urlsluice/internal/uuids"
+)
+
+// newUUIDCorrelator returns the correlator recognizing version 3 and 5 UUIDs generated from a
+// name in the -uuid-names wordlist and one of the -uuid-namespaces namespaces
+func newUUIDCorrelator(config *Config) (*uuids.Correlator, error) {
+	names, err := readWordlist(config.UUIDNames)
+	if err != nil {
+		return nil, fmt.Errorf("error reading UUID names: %w", err)
+	}
+
+	namespaces := []uuids.UUID{uuids.NamespaceDNS, uuids.NamespaceURL, uuids.NamespaceOID, uuids.NamespaceX500}
+	if len(config.UUIDNamespaces) > 0 {
+		namespaces = namespaces[:0]
+		for _, s := range config.UUIDNamespaces {
+			ns, err := uuids.ParseNamespace(s)
+			if err != nil {
+				return nil, err
+			}
+			namespaces = append(namespaces, ns)
+		}
+	}
+
+	return uuids.NewCorrelator(namespaces, names), nil
+}
+
+// readWordlist returns the non-empty lines of path, skipping "#" comments
+func readWordlist(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		word := strings.TrimSpace(scanner.Text())
+		if word != "" && !strings.HasPrefix(word, "#") {
+			words = append(words, word)
+		}
+	}
+	return words, scanner.Err()
+}
+
+// printUUIDVersions writes the distribution of UUID versions among findings, warning
+// about version 1 UUIDs since they leak the generating host's MAC address and clock
+func printUUIDVersions(w io.Writer, findings []finding.Finding) error {
+	dist := uuids.Versions(findings)
+	if len(dist) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprintf(w, "\nUUID Versions:\n"); err != nil {
+		return err
+	}
+	for _, v := range dist {
+		line := fmt.Sprintf("v%d: %d (%.1f%%)", v.Version, v.Count, v.Percent)
+		if v.Version == 1 {
+			line += " time-based, leaks MAC address and creation time"
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printTimestampRanges writes the earliest and latest decoded creation time per identifier kind
+func printTimestampRanges(w io.Writer, findings []finding.Finding) error {
+	ranges := timestamps.Ranges(findings)
+	if len(ranges) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprintf(w, "\nTimestamp Ranges:\n"); err != nil {
+		return err
+	}
+	for _, r := range ranges {
+		line := fmt.Sprintf("%s: %d, %s to %s", r.Kind, r.Count, r.Earliest.Format(time.RFC3339), r.Latest.Format(time.RFC3339))
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
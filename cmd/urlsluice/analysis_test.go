@@ -0,0 +1,326 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/PeteJStewart/urlsluice/internal/avatars"
+	"github.com/PeteJStewart/urlsluice/internal/output"
+	"github.com/PeteJStewart/urlsluice/internal/redact"
+)
+
+func TestRun_UUIDNames(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "ids.txt")
+	content := "/api/users/2ed6657d-e927-568b-95e1-2665a8aea6a2\n/api/users/74738ff5-5367-5958-9aee-98fffdcd1876\n"
+	if err := os.WriteFile(input, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	names := filepath.Join(dir, "names.txt")
+	if err := os.WriteFile(names, []byte("# hostnames\nwww.example.com\nadmin\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	oldArgs := os.Args
+	oldFlagCommandLine := flag.CommandLine
+	oldStdout := os.Stdout
+	defer func() {
+		os.Args = oldArgs
+		flag.CommandLine = oldFlagCommandLine
+		os.Stdout = oldStdout
+	}()
+
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	os.Args = []string{"cmd", "-uuid", "5", "-uuid-names", names, "-uuid-namespaces", "dns", "-json", "-file", input}
+
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := run(context.Background())
+	w.Close()
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	var doc output.Document
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if len(doc.Findings) != 2 {
+		t.Fatalf("got %d findings, want 2", len(doc.Findings))
+	}
+	for _, f := range doc.Findings {
+		recovered := f.Value == "2ed6657d-e927-568b-95e1-2665a8aea6a2"
+		if recovered && (f.Metadata["namespace"] != "dns" || f.Metadata["name"] != "www.example.com") {
+			t.Errorf("%s metadata = %v, want dns/www.example.com", f.Value, f.Metadata)
+		}
+		if !recovered && f.Metadata["name"] != "" {
+			t.Errorf("%s unexpectedly matched %v", f.Value, f.Metadata)
+		}
+	}
+}
+
+func TestRun_UUIDDetect(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "ids.txt")
+	content := "550e8400-e29b-41d4-a716-446655440000\n6ba7b810-9dad-11d1-80b4-00c04fd430c8\n" +
+		"550e8400-e29b-41d4-a716-446655440001\n550e8400-e29b-41d4-a716-446655440002\n"
+	if err := os.WriteFile(input, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	oldArgs := os.Args
+	oldFlagCommandLine := flag.CommandLine
+	oldStdout := os.Stdout
+	defer func() {
+		os.Args = oldArgs
+		flag.CommandLine = oldFlagCommandLine
+		os.Stdout = oldStdout
+	}()
+
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	os.Args = []string{"cmd", "-uuid-detect", "-file", input}
+
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := run(context.Background())
+	w.Close()
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	want := "\nExtracted UUIDs:\n550e8400-e29b-41d4-a716-446655440000\n550e8400-e29b-41d4-a716-446655440001\n" +
+		"550e8400-e29b-41d4-a716-446655440002\n6ba7b810-9dad-11d1-80b4-00c04fd430c8\n" +
+		"\nUUID Versions:\nv4: 3 (75.0%)\nv1: 1 (25.0%) time-based, leaks MAC address and creation time\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestRun_Timestamps(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "ids.txt")
+	content := "/orders?id=01ARZ3NDEKTSV4RRFFQ69G5FAV&created=1700000000\n/orders?id=01BX5ZZKBKACTAV9WEVGEMMVRZ\n"
+	if err := os.WriteFile(input, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	oldArgs := os.Args
+	oldFlagCommandLine := flag.CommandLine
+	oldStdout := os.Stdout
+	defer func() {
+		os.Args = oldArgs
+		flag.CommandLine = oldFlagCommandLine
+		os.Stdout = oldStdout
+	}()
+
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	os.Args = []string{"cmd", "-timestamps", "-file", input}
+
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := run(context.Background())
+	w.Close()
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	want := "\nExtracted Timestamps:\n" +
+		"01ARZ3NDEKTSV4RRFFQ69G5FAV [ulid, 2016-07-30T23:54:10.259Z]\n" +
+		"01BX5ZZKBKACTAV9WEVGEMMVRZ [ulid, 2017-10-24T01:29:36.371Z]\n" +
+		"1700000000 [epoch_s, 2023-11-14T22:13:20Z]\n" +
+		"\nTimestamp Ranges:\n" +
+		"epoch_s: 1, 2023-11-14T22:13:20Z to 2023-11-14T22:13:20Z\n" +
+		"ulid: 2, 2016-07-30T23:54:10Z to 2017-10-24T01:29:36Z\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestRun_Usernames(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "team.html")
+	content := `<a href="mailto:ksmith@target.com">Kim</a> <a href="/users/jdoe">Jane</a>
+<a href="mailto:jdoe@target.com">Jane</a> <a href="mailto:info@target.com">Info</a>
+`
+	if err := os.WriteFile(input, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	oldArgs := os.Args
+	oldFlagCommandLine := flag.CommandLine
+	oldStdout := os.Stdout
+	defer func() {
+		os.Args = oldArgs
+		flag.CommandLine = oldFlagCommandLine
+		os.Stdout = oldStdout
+	}()
+
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	os.Args = []string{"cmd", "-uuid", "0", "-usernames", "-silent", "-file", input}
+
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := run(context.Background())
+	w.Close()
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if got, want := buf.String(), "jdoe\nksmith\n"; got != want {
+		t.Errorf("output = %q, want the most frequent username first: %q", got, want)
+	}
+}
+
+func TestRun_AvatarCorrelate(t *testing.T) {
+	hash, _ := avatars.Hashes("jane@target.com")
+	input := filepath.Join(t.TempDir(), "team.html")
+	content := `<img src="https://secure.gravatar.com/avatar/` + hash + `?s=64"> Jane
+<img src="https://secure.gravatar.com/avatar/00000000000000000000000000000000?s=64"> Someone else
+Contact: jane@target.com
+`
+	if err := os.WriteFile(input, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	oldArgs := os.Args
+	oldFlagCommandLine := flag.CommandLine
+	oldStdout := os.Stdout
+	defer func() {
+		os.Args = oldArgs
+		flag.CommandLine = oldFlagCommandLine
+		os.Stdout = oldStdout
+	}()
+
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	os.Args = []string{"cmd", "-avatar-correlate", "-file", input}
+
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := run(context.Background())
+	w.Close()
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"Extracted Avatar Hashes:", hash + " [jane@target.com]\n", "00000000000000000000000000000000\n", "jane@target.com\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output should contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestRun_Xref(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "crawl.txt")
+	content := "https://app.target.com/login?next=https://evil.com/&id=7\nhttps://app.target.com/sso?return=https%3A%2F%2Fsso.target.com%2F\nops@target.com dev@agency.io\n"
+	if err := os.WriteFile(input, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	scopePath := filepath.Join(dir, "scope.txt")
+	if err := os.WriteFile(scopePath, []byte("target.com\n*.target.com\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	oldArgs := os.Args
+	oldFlagCommandLine := flag.CommandLine
+	oldStdout := os.Stdout
+	defer func() {
+		os.Args = oldArgs
+		flag.CommandLine = oldFlagCommandLine
+		os.Stdout = oldStdout
+	}()
+
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	os.Args = []string{"cmd", "-uuid", "0", "-queryParams", "-emails", "-xref", "-no-color", "-scope-file", scopePath, "-file", input}
+
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := run(context.Background())
+	w.Close()
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	want := "\nDomains in Parameter Values:\nevil.com <- next=https://evil.com/\n\nOut-of-Scope Email Domains:\nagency.io <- dev@agency.io\n"
+	if got := buf.String(); !strings.HasSuffix(got, want) {
+		t.Errorf("output = %q, want it to end with %q", got, want)
+	}
+	if strings.Contains(buf.String(), "dev@agency.io\n\n") {
+		t.Errorf("output = %q, want the out-of-scope email dropped from the findings", buf.String())
+	}
+}
+
+func TestRun_XrefRedact(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "crawl.txt")
+	content := "https://app.target.com/login?next=https://evil.com/u/alice@other.org\nops@target.com bob@other.org\n"
+	if err := os.WriteFile(input, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	scopePath := filepath.Join(dir, "scope.txt")
+	if err := os.WriteFile(scopePath, []byte("target.com\n*.target.com\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	oldArgs := os.Args
+	oldFlagCommandLine := flag.CommandLine
+	oldStdout := os.Stdout
+	defer func() {
+		os.Args = oldArgs
+		flag.CommandLine = oldFlagCommandLine
+		os.Stdout = oldStdout
+	}()
+
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	os.Args = []string{"cmd", "-uuid", "0", "-queryParams", "-emails", "-usernames", "-xref", "-redact", "-no-color", "-scope-file", scopePath, "-file", input}
+
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := run(context.Background())
+	w.Close()
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	got := buf.String()
+	for _, clear := range []string{"alice", "bob", "ops\n"} {
+		if strings.Contains(got, clear) {
+			t.Errorf("output = %q, want %q redacted", got, clear)
+		}
+	}
+	alice := "[redacted:" + redact.Hash("alice@other.org") + "]@other.org"
+	bob := "[redacted:" + redact.Hash("bob@other.org") + "]@other.org"
+	emails := []string{alice, bob}
+	sort.Strings(emails)
+	want := "\nDomains in Parameter Values:\nevil.com <- next=https://evil.com/u/" + alice +
+		"\n\nOut-of-Scope Email Domains:\nother.org <- " + strings.Join(emails, ", ") + "\n"
+	if !strings.HasSuffix(got, want) {
+		t.Errorf("output = %q, want it to end with %q", got, want)
+	}
+}
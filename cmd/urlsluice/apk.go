@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/PeteJStewart/urlsluice/internal/apk"
+	"github.com/PeteJStewart/urlsluice/internal/output"
+	"github.com/PeteJStewart/urlsluice/internal/pipeline"
+)
+
+// apkSource reads the -apk package and emits one batch per entry, named "app.apk!entry" so
+// findings are attributed to the file inside the package they were found in
+func apkSource(config *Config, runInfo *output.Run) pipeline.Source {
+	return func(ctx context.Context, emit pipeline.Emit) error {
+		data, err := os.ReadFile(config.APKPath)
+		if err != nil {
+			return fmt.Errorf("error reading APK: %w", err)
+		}
+		runInfo.AddInput(config.APKPath, data)
+		err = apk.Walk(data, func(name string, text []byte) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return emit(pipeline.Batch{Source: config.APKPath + "!" + name, Data: text})
+		})
+		if err != nil {
+			return fmt.Errorf("error reading APK %s: %w", config.APKPath, err)
+		}
+		return nil
+	}
+}
+
+// inputSource returns the source for the -file, -url, -apk, -openapi, -postman, -graphql and -traffic inputs given in config
+func inputSource(config *Config, runInfo *output.Run) pipeline.Source {
+	return func(ctx context.Context, emit pipeline.Emit) error {
+		if err := textSource(config, runInfo)(ctx, emit); err != nil {
+			return err
+		}
+		if config.APKPath != "" {
+			if err := apkSource(config, runInfo)(ctx, emit); err != nil {
+				return err
+			}
+		}
+		if config.OpenAPIPath != "" {
+			if err := openAPISource(config, runInfo)(ctx, emit); err != nil {
+				return err
+			}
+		}
+		if len(config.PostmanPaths) > 0 {
+			if err := postmanSource(config, runInfo)(ctx, emit); err != nil {
+				return err
+			}
+		}
+		if config.GraphQLPath != "" {
+			if err := graphQLSource(config, runInfo)(ctx, emit); err != nil {
+				return err
+			}
+		}
+		if config.TrafficPath != "" {
+			return trafficSource(config, runInfo)(ctx, emit)
+		}
+		return nil
+	}
+}
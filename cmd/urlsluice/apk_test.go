@@ -0,0 +1,107 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunAPK(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.apk")
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, data := range map[string]string{
+		"classes.dex":                  "dex\n035\x00\x00\x01https://api.target.com/v1/login\x00\x02",
+		"lib/arm64-v8a/libnative.so":   "\x7fELF\x02\x01\x00\x00support@target.com\x00",
+		"assets/www/config.properties": "cdn=https://cdn.target.com/\n",
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantOutput []string
+		wantErr    string
+	}{
+		{
+			name: "grouped by entry",
+			args: []string{"-apk", path, "-domains", "-emails"},
+			wantOutput: []string{
+				"== " + path + "!assets/www/config.properties ==\n\nExtracted Domains:\ncdn.target.com\n",
+				"== " + path + "!classes.dex ==\n\nExtracted Domains:\napi.target.com\n",
+				"== " + path + "!lib/arm64-v8a/libnative.so ==\n\nExtracted Emails:\nsupport@target.com\n",
+			},
+		},
+		{
+			name:       "json sources",
+			args:       []string{"-apk", path, "-emails", "-json"},
+			wantOutput: []string{`"source": "` + path + `!lib/arm64-v8a/libnative.so"`},
+		},
+		{
+			name:    "not an archive",
+			args:    []string{"-apk", "apk_test.go", "-domains"},
+			wantErr: "error opening archive",
+		},
+		{
+			name:    "wordlist needs a file",
+			args:    []string{"-apk", path, "-wordlist"},
+			wantErr: "reads URL lines from -file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldArgs := os.Args
+			oldStdout := os.Stdout
+			oldFlagCommandLine := flag.CommandLine
+			defer func() {
+				os.Args = oldArgs
+				os.Stdout = oldStdout
+				flag.CommandLine = oldFlagCommandLine
+			}()
+			flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			os.Args = append([]string{"cmd"}, tt.args...)
+
+			r, w, _ := os.Pipe()
+			os.Stdout = w
+
+			err := run(context.Background())
+			w.Close()
+			var out bytes.Buffer
+			out.ReadFrom(r)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("run() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			for _, want := range tt.wantOutput {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("output should contain %q, got %q", want, out.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/PeteJStewart/urlsluice/internal/audit"
+)
+
+// runAudit implements "urlsluice audit verify FILE...", checking that -audit-log files have
+// not been edited or truncated since the entries were appended
+func runAudit(ctx context.Context, args []string) error {
+	if len(args) == 0 || args[0] != "verify" {
+		return fmt.Errorf("usage: urlsluice audit verify FILE...")
+	}
+	fs := flag.NewFlagSet("audit verify", flag.ContinueOnError)
+	if err := fs.Parse(args[1:]); err != nil {
+		return fmt.Errorf("error parsing flags: %w", err)
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("error parsing flags: an audit log is required")
+	}
+
+	var errs []error
+	for _, path := range fs.Args() {
+		n, err := audit.VerifyFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		fmt.Printf("%s: ok (%d entries)\n", path, n)
+	}
+	return errors.Join(errs...)
+}
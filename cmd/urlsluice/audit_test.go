@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun_AuditLog(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "urls.txt")
+	if err := os.WriteFile(input, []byte("https://api.target.com/\ndev@target.com\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	logPath := filepath.Join(dir, "audit.ndjson")
+
+	oldArgs := os.Args
+	oldFlagCommandLine := flag.CommandLine
+	oldStdout := os.Stdout
+	defer func() {
+		os.Args = oldArgs
+		flag.CommandLine = oldFlagCommandLine
+		os.Stdout = oldStdout
+	}()
+
+	runArgs := func(args ...string) string {
+		t.Helper()
+		flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+		os.Args = append([]string{"cmd"}, args...)
+		r, w, _ := os.Pipe()
+		os.Stdout = w
+		err := run(context.Background())
+		w.Close()
+		var buf bytes.Buffer
+		buf.ReadFrom(r)
+		os.Stdout = oldStdout
+		if err != nil {
+			t.Fatalf("run(%v) error = %v", args, err)
+		}
+		return buf.String()
+	}
+
+	// Limits and silent output apply to the report, not to the audit log
+	runArgs("-domains", "-emails", "-silent", "-max-per-category", "1", "-audit-log", logPath, "-file", input)
+	runArgs("-domains", "-json", "-audit-log", logPath, "-file", input)
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("audit log has %d entries, want 3:\n%s", len(lines), data)
+	}
+	if !strings.Contains(lines[0], `"value":"dev@target.com"`) || !strings.Contains(lines[1], `"value":"api.target.com"`) {
+		t.Errorf("audit log = %s", data)
+	}
+
+	if got, want := runArgs("audit", "verify", logPath), logPath+": ok (3 entries)\n"; got != want {
+		t.Errorf("audit verify = %q, want %q", got, want)
+	}
+	if err := os.WriteFile(logPath, bytes.Replace(data, []byte("dev@"), []byte("ops@"), 1), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := runAudit(context.Background(), []string{"verify", logPath}); err == nil || !strings.Contains(err.Error(), "chain broken") {
+		t.Errorf("audit verify of an edited log error = %v", err)
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+// extractorEntry registers an extractor: the -only category naming it, the flag enabling it
+// and the flags and configuration file sections tuning it. Descriptions and defaults come
+// from the flags themselves, so the catalog cannot drift from -help.
+type extractorEntry struct {
+	name     string
+	category finding.Type
+	flag     string
+	// description is used for extractors without a flag of their own
+	description string
+	options     []string
+	config      []string
+	// all marks the extractors -all enables: those needing no network access or extra input
+	all bool
+}
+
+// extractorRegistry lists every extractor in the output order of its finding type
+var extractorRegistry = []extractorEntry{
+	{name: "uuids", category: finding.TypeUUID, flag: "uuid", options: []string{"uuid-detect", "uuid-names", "uuid-namespaces"}, all: true},
+	{name: "emails", category: finding.TypeEmail, flag: "emails", options: []string{"emails-deobfuscate"}, all: true},
+	{name: "domains", category: finding.TypeDomain, flag: "domains", options: []string{"tlds", "exclude-tlds", "include-reserved"}, all: true},
+	{name: "ips", category: finding.TypeIP, flag: "ips", all: true},
+	{name: "params", category: finding.TypeParam, flag: "queryParams", options: []string{"params-mode", "decode-params", "dedupe-params"}, all: true},
+	{name: "urls", category: finding.TypeURL, flag: "urls", options: []string{"parse-urls", "dedupe-trailing-slash", "dedupe-locale"}, all: true},
+	{name: "tokens", category: finding.TypeToken, flag: "entropy-min", config: []string{"remediation"}},
+	{name: "handles", category: finding.TypeHandle, flag: "handles", all: true},
+	{name: "crypto", category: finding.TypeCrypto, flag: "crypto", all: true},
+	{name: "cloud-config", category: finding.TypeCloudConfig, flag: "cloud-config", config: []string{"remediation"}, all: true},
+	{name: "config-secrets", category: finding.TypeConfigSecret, flag: "config-secrets", config: []string{"remediation"}, all: true},
+	{name: "timestamps", category: finding.TypeTimestamp, flag: "timestamps", all: true},
+	{name: "header-issues", category: finding.TypeHeaderIssue, flag: "security-headers", config: []string{"remediation"}},
+	{name: "usernames", category: finding.TypeUsername, flag: "usernames", all: true},
+	{name: "avatar-hashes", category: finding.TypeAvatarHash, flag: "avatar-hashes", options: []string{"avatar-correlate"}, all: true},
+	{name: "buckets", category: finding.TypeBucket, flag: "buckets", options: []string{"probe-s3", "s3-endpoint"}, all: true},
+	{name: "takeovers", category: finding.TypeTakeover, flag: "takeover", config: []string{"remediation"}},
+	{name: "jsonp", category: finding.TypeJSONP, flag: "jsonp", config: []string{"remediation"}, all: true},
+	{name: "cors", category: finding.TypeCORS, flag: "cors", config: []string{"remediation"}},
+	{name: "errors", category: finding.TypeVerboseError, flag: "errors", config: []string{"remediation"}, all: true},
+	{name: "comments", category: finding.TypeComment, flag: "comments", options: []string{"comment-keywords"}, all: true},
+	{name: "locales", category: finding.TypeLocale, flag: "locales", all: true},
+	{name: "tracking-params", category: finding.TypeTrackingParam, flag: "queryParams", options: []string{"keep-tracking-params"}, config: []string{"tracking_params"}, all: true},
+	{name: "plugins", category: finding.TypePlugin, description: "Findings reported by the external and WebAssembly plugins of the configuration file", config: []string{"plugins"}},
+}
+
+// lookupExtractor returns the registry entry of the extractor producing findings of type t
+func lookupExtractor(t finding.Type) (extractorEntry, bool) {
+	for _, e := range extractorRegistry {
+		if e.category == t {
+			return e, true
+		}
+	}
+	return extractorEntry{}, false
+}
+
+// catalogEntry is an extractor as written by "urlsluice extractors -json"
+type catalogEntry struct {
+	Name        string          `json:"name"`
+	Flag        string          `json:"flag,omitempty"`
+	Description string          `json:"description"`
+	Category    finding.Type    `json:"category"`
+	Default     bool            `json:"default"`
+	All         bool            `json:"all"`
+	Options     []catalogOption `json:"options,omitempty"`
+	Config      []string        `json:"config,omitempty"`
+}
+
+// catalogOption is a flag tuning an extractor
+type catalogOption struct {
+	Flag        string `json:"flag"`
+	Description string `json:"description"`
+	Default     string `json:"default,omitempty"`
+}
+
+// extractorCatalog describes the registered extractors with the usage and defaults of
+// their flags
+func extractorCatalog() ([]catalogEntry, error) {
+	fs := flag.NewFlagSet("urlsluice", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if _, err := parseFlagSet(fs, nil); err != nil {
+		return nil, err
+	}
+	lookup := func(name string) (*flag.Flag, error) {
+		f := fs.Lookup(name)
+		if f == nil {
+			return nil, fmt.Errorf("extractor flag -%s is not defined", name)
+		}
+		return f, nil
+	}
+
+	catalog := make([]catalogEntry, 0, len(extractorRegistry))
+	for _, e := range extractorRegistry {
+		entry := catalogEntry{
+			Name:        e.name,
+			Description: e.description,
+			Category:    e.category,
+			All:         e.all,
+			Config:      e.config,
+		}
+		if e.flag != "" {
+			f, err := lookup(e.flag)
+			if err != nil {
+				return nil, err
+			}
+			entry.Flag = "-" + e.flag
+			entry.Description = f.Usage
+			// Only UUID extraction is on by default, through the -uuid version
+			entry.Default = f.DefValue != "false" && f.DefValue != "0"
+		}
+		for _, name := range e.options {
+			f, err := lookup(name)
+			if err != nil {
+				return nil, err
+			}
+			option := catalogOption{Flag: "-" + name, Description: f.Usage}
+			if f.DefValue != "false" && f.DefValue != "0" {
+				option.Default = f.DefValue
+			}
+			entry.Options = append(entry.Options, option)
+		}
+		catalog = append(catalog, entry)
+	}
+	return catalog, nil
+}
+
+// runExtractors implements "urlsluice extractors", listing the extractors with their flags
+// so wrappers and documentation can be generated from the binary they run
+func runExtractors(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("extractors", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "Write the catalog as JSON")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("error parsing flags: %w", err)
+	}
+	catalog, err := extractorCatalog()
+	if err != nil {
+		return err
+	}
+	return writeCatalog(os.Stdout, catalog, *asJSON)
+}
+
+func writeCatalog(w io.Writer, catalog []catalogEntry, asJSON bool) error {
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(catalog)
+	}
+
+	for _, e := range catalog {
+		var notes []string
+		if e.Flag != "" {
+			notes = append(notes, e.Flag)
+		}
+		if e.Default {
+			notes = append(notes, "default")
+		}
+		if e.All {
+			notes = append(notes, "-all")
+		}
+		if len(notes) > 0 {
+			fmt.Fprintf(w, "%s (%s)\n", e.Name, strings.Join(notes, ", "))
+		} else {
+			fmt.Fprintf(w, "%s\n", e.Name)
+		}
+		fmt.Fprintf(w, "        %s\n", e.Description)
+		for _, o := range e.Options {
+			fmt.Fprintf(w, "        %s: %s\n", o.Flag, o.Description)
+		}
+		for _, c := range e.Config {
+			fmt.Fprintf(w, "        config: %s\n", c)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+func TestExtractorRegistry(t *testing.T) {
+	if len(extractorRegistry) != len(finding.Types) {
+		t.Fatalf("registry has %d extractors, want one for each of the %d finding types", len(extractorRegistry), len(finding.Types))
+	}
+	for i, e := range extractorRegistry {
+		if e.category != finding.Types[i] {
+			t.Errorf("extractor %d is %s, want %s", i, e.category, finding.Types[i])
+		}
+		if got, err := parseCategory(e.name); err != nil || got != e.category {
+			t.Errorf("parseCategory(%q) = %v, %v, want %s", e.name, got, err, e.category)
+		}
+	}
+	if _, err := extractorCatalog(); err != nil {
+		t.Errorf("extractorCatalog() error = %v", err)
+	}
+}
+
+func TestRunExtractors(t *testing.T) {
+	oldArgs := os.Args
+	oldStdout := os.Stdout
+	defer func() {
+		os.Args = oldArgs
+		os.Stdout = oldStdout
+	}()
+
+	os.Args = []string{"cmd", "extractors", "-json"}
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := run(context.Background())
+	w.Close()
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	var catalog []catalogEntry
+	if err := json.Unmarshal(buf.Bytes(), &catalog); err != nil {
+		t.Fatalf("catalog is not valid JSON: %v", err)
+	}
+	uuids := catalog[0]
+	if uuids.Name != "uuids" || uuids.Flag != "-uuid" || !uuids.Default || len(uuids.Options) != 3 {
+		t.Errorf("uuids entry = %+v", uuids)
+	}
+	for _, e := range catalog {
+		if e.Name == "takeovers" && (e.All || e.Default) {
+			t.Errorf("takeovers entry = %+v, want neither default nor enabled by -all", e)
+		}
+	}
+}
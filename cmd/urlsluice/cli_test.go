@@ -29,6 +29,26 @@ func TestGetProgramName(t *testing.T) {
 			osArgs:   []string{"main"},
 			expected: "urlsluice",
 		},
+		{
+			name:     "go run on macOS",
+			osArgs:   []string{"/var/folders/x7/T/go-build2841/b001/exe/urlsluice"},
+			expected: "urlsluice",
+		},
+		{
+			name:     "renamed binary",
+			osArgs:   []string{"/opt/recon/bin/sluice"},
+			expected: "sluice",
+		},
+		{
+			name:     "debugger binary",
+			osArgs:   []string{"/home/dev/urlsluice/cmd/urlsluice/__debug_bin3350"},
+			expected: "urlsluice",
+		},
+		{
+			name:     "exe suffix",
+			osArgs:   []string{"sluice.EXE"},
+			expected: "sluice",
+		},
 	}
 
 	for _, tt := range tests {
@@ -56,7 +76,7 @@ func TestGenerateHelpText(t *testing.T) {
 			progName: "urlsluice",
 			wantContains: []string{
 				"URL Sluice - Extract patterns from text files",
-				"Usage: urlsluice [options]",
+				"Usage: urlsluice [command] [options]",
 				"-file string",
 				"-uuid int",
 				"-emails",
@@ -80,6 +100,12 @@ func TestGenerateHelpText(t *testing.T) {
 					t.Errorf("Help text missing expected content: %q", want)
 				}
 			}
+			// Every subcommand is listed, by itself or followed by its arguments
+			for name := range commands {
+				if !strings.Contains(output, "\n  "+name+" ") && !strings.Contains(output, "\n  "+name+"\n") {
+					t.Errorf("Help text missing command %q", name)
+				}
+			}
 		})
 	}
 }
@@ -113,9 +139,9 @@ https://example.com/goto?redirect=//evil.com`,
 			wantOutput: []string{
 				"Potential Open Redirects:",
 				"https://example.com/login?next=https://evil.com",
-				"Parameter: next = https://evil.com (Known: true)",
+				"Parameter: next = https://evil.com (Known: true, Confidence: high, Rule: known-param-url)",
 				"https://example.com/goto?redirect=//evil.com",
-				"Parameter: redirect = //evil.com (Known: true)",
+				"Parameter: redirect = //evil.com (Known: true, Confidence: high, Rule: known-param-url)",
 			},
 		},
 		{
@@ -135,7 +161,29 @@ https://example.com/goto?redirect=//evil.com`,
 			wantOutput: []string{
 				"Potential Open Redirects:",
 				"https://example.com/login?custom=https://evil.com",
-				"Parameter: custom = https://evil.com (Known: true)",
+				"Parameter: custom = https://evil.com (Known: true, Confidence: high, Rule: known-param-url)",
+			},
+		},
+		{
+			name: "xss parameter detection",
+			input: `https://example.com/search?q=%3Cscript%3E&page=2
+https://example.com/api?callback=cb`,
+			args: []string{"-detect-xss-params"},
+			wantOutput: []string{
+				"Potential XSS Parameters:",
+				"Parameter: q = <script> (Reason: markup)",
+				"Parameter: callback = cb (Reason: sink)",
+			},
+		},
+		{
+			name:  "redirect and xss parameter detection",
+			input: `https://example.com/login?next=https://evil.com&msg=%22%3E%3Cimg%3E`,
+			args:  []string{"-detect-redirects", "-detect-xss-params"},
+			wantOutput: []string{
+				"Potential Open Redirects:",
+				"Parameter: next = https://evil.com (Known: true, Confidence: high, Rule: known-param-url)",
+				"Potential XSS Parameters:",
+				"Parameter: msg = \"><img> (Reason: markup)",
 			},
 		},
 	}
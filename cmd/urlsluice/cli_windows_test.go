@@ -0,0 +1,25 @@
+//go:build windows
+
+package main
+
+import "testing"
+
+func TestProgramName_Windows(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{`C:\Tools\urlsluice.exe`, "urlsluice"},
+		{`C:\Tools\sluice.exe`, "sluice"},
+		{`C:\Users\dev\AppData\Local\Temp\go-build3012\b001\exe\main.exe`, "urlsluice"},
+		{`C:\Users\dev\AppData\Local\Temp\go-build3012\b001\exe\urlsluice.exe`, "urlsluice"},
+		{`D:\src\urlsluice\cmd\urlsluice\__debug_bin1204.exe`, "urlsluice"},
+		{`\\fileserver\share\bin\sluice.exe`, "sluice"},
+		{`sluice`, "sluice"},
+	}
+	for _, tt := range tests {
+		if got := programName(tt.path); got != tt.want {
+			t.Errorf("programName(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	configfile "github.com/PeteJStewart/urlsluice/internal/config"
+	"github.com/PeteJStewart/urlsluice/internal/monitor"
+	"github.com/PeteJStewart/urlsluice/internal/redirect"
+	"github.com/PeteJStewart/urlsluice/internal/server"
+	"github.com/PeteJStewart/urlsluice/internal/xss"
+)
+
+// runConfig implements "urlsluice config validate [-type config|monitor|redirect|tokens|xss] FILE...",
+// checking configuration files without running anything. Besides the checks made when a file
+// is loaded, the flags of profiles and monitor jobs are parsed as they would be by a scan.
+func runConfig(ctx context.Context, args []string) error {
+	if len(args) == 0 || args[0] != "validate" {
+		return fmt.Errorf("usage: urlsluice config validate [-type config|monitor|redirect|tokens|xss] FILE...")
+	}
+	fs := flag.NewFlagSet("config validate", flag.ContinueOnError)
+	kind := fs.String("type", "config", "Kind of the files: config (-config), monitor (daemon -config), redirect (-redirect-config), tokens (serve -tokens) or xss (-xss-config)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return fmt.Errorf("error parsing flags: %w", err)
+	}
+	validate, ok := configValidators[*kind]
+	if !ok {
+		return fmt.Errorf("error parsing flags: invalid -type %q: must be config, monitor, redirect, tokens or xss", *kind)
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("error parsing flags: a configuration file is required")
+	}
+
+	var errs []error
+	for _, path := range fs.Args() {
+		// The redirect and XSS detectors fall back to their defaults for missing files
+		if _, err := os.Stat(path); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := validate(path); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		fmt.Printf("%s: ok\n", path)
+	}
+	return errors.Join(errs...)
+}
+
+// configValidators check a configuration file of each -type
+var configValidators = map[string]func(path string) error{
+	"config": func(path string) error {
+		settings, err := configfile.Load(path)
+		if err != nil {
+			return err
+		}
+		for _, name := range settings.ProfileNames() {
+			// Load checked that profiles can be turned into flags
+			args, _ := settings.Profiles[name].Args()
+			for _, arg := range args {
+				if flagName, _, _ := strings.Cut(arg[1:], "="); flagName == "config" || flagName == "profile" {
+					return fmt.Errorf("%s: profiles.%s: a profile cannot set -%s", path, name, flagName)
+				}
+			}
+			if err := validateFlags(append([]string{"-config", path}, args...)); err != nil {
+				return fmt.Errorf("%s: profiles.%s: %w", path, name, err)
+			}
+		}
+		for _, w := range settings.PatternWarnings() {
+			fmt.Fprintf(os.Stderr, "Warning: %s: %s\n", path, w)
+		}
+		return nil
+	},
+	"monitor": func(path string) error {
+		config, err := monitor.Load(path)
+		if err != nil {
+			return err
+		}
+		for _, job := range config.Jobs {
+			if err := validateFlags(job.Args); err != nil {
+				return fmt.Errorf("%s: job %s: %w", path, job.Name, err)
+			}
+		}
+		return nil
+	},
+	"redirect": func(path string) error {
+		_, err := redirect.NewRedirectDetector(path)
+		return err
+	},
+	"tokens": func(path string) error {
+		_, err := server.LoadTokens(path)
+		return err
+	},
+	"xss": func(path string) error {
+		_, err := xss.NewXSSDetector(path)
+		return err
+	},
+}
+
+// validateFlags parses args as the flags of a scan, loading the files they name, without
+// running it
+func validateFlags(args []string) error {
+	fs := flag.NewFlagSet("urlsluice", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	_, err := parseFlagSet(fs, args)
+	return err
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun_ConfigValidate(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"good.yaml":     "profiles:\n  recon:\n    domains: true\n    silent: true\n",
+		"typo.yaml":     "tags:\n  - pattern: \"*.target.com\"\n    tga: prod\n",
+		"profile.yaml":  "profiles:\n  recon:\n    uuid: 9\n",
+		"nested.yaml":   "profiles:\n  recon:\n    profile: other\n",
+		"monitor.yaml":  "jobs:\n  - name: app\n    schedule: '@hourly'\n    args: [-file, urls.txt, -domian]\n",
+		"redirect.yaml": "params: [next]\nskip: true\n",
+		"tokens.yaml":   "tokens:\n  - name: ci\n",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := func(name string) string { return filepath.Join(dir, name) }
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "valid file",
+			args: []string{"config", "validate", path("good.yaml")},
+			want: path("good.yaml") + ": ok\n",
+		},
+		{
+			name:    "misspelled key",
+			args:    []string{"config", "validate", path("good.yaml"), path("typo.yaml")},
+			want:    path("good.yaml") + ": ok\n",
+			wantErr: `line 3, column 5: unknown key "tga" in tags[0], did you mean "tag"?`,
+		},
+		{
+			name:    "invalid profile flag",
+			args:    []string{"config", "validate", path("profile.yaml")},
+			wantErr: "profiles.recon: invalid UUID version",
+		},
+		{
+			name:    "nested profile",
+			args:    []string{"config", "validate", path("nested.yaml")},
+			wantErr: "profiles.recon: a profile cannot set -profile",
+		},
+		{
+			name:    "monitor job flag",
+			args:    []string{"config", "validate", "-type", "monitor", path("monitor.yaml")},
+			wantErr: "job app: flag provided but not defined: -domian, did you mean -domains?",
+		},
+		{
+			name:    "redirect config",
+			args:    []string{"config", "validate", "-type", "redirect", path("redirect.yaml")},
+			wantErr: `unknown key "skip"`,
+		},
+		{
+			name:    "tokens file",
+			args:    []string{"config", "validate", "-type", "tokens", path("tokens.yaml")},
+			wantErr: "line 2, column 5: tokens[0]: token or sha256 is required",
+		},
+		{
+			name:    "missing file",
+			args:    []string{"config", "validate", path("missing.yaml")},
+			wantErr: "no such file",
+		},
+		{
+			name:    "invalid type",
+			args:    []string{"config", "validate", "-type", "scope", path("good.yaml")},
+			wantErr: `invalid -type "scope"`,
+		},
+		{
+			name:    "missing subcommand",
+			args:    []string{"config", path("good.yaml")},
+			wantErr: "usage: urlsluice config validate",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldArgs := os.Args
+			oldFlagCommandLine := flag.CommandLine
+			oldStdout := os.Stdout
+			defer func() {
+				os.Args = oldArgs
+				flag.CommandLine = oldFlagCommandLine
+				os.Stdout = oldStdout
+			}()
+
+			flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			os.Args = append([]string{"cmd"}, tt.args...)
+
+			r, w, _ := os.Pipe()
+			os.Stdout = w
+
+			err := run(context.Background())
+			w.Close()
+			var buf bytes.Buffer
+			buf.ReadFrom(r)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("run() error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
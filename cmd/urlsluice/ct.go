@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/PeteJStewart/urlsluice/internal/ct"
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/pipeline"
+)
+
+// ctBaseURL is the CT search endpoint; tests point it at a local server
+var ctBaseURL = ct.DefaultBaseURL
+
+// runCT implements "urlsluice ct -domain example.com". Hostnames found in Certificate
+// Transparency logs are reported as domain findings, merged with the findings from -file
+// and -apk when given so both sources share the same filters and output.
+func runCT(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("ct", flag.ContinueOnError)
+	domain := fs.String("domain", "", "Domain to look up in Certificate Transparency logs (required)")
+
+	config, err := parseFlagSet(fs, args)
+	if err != nil {
+		return fmt.Errorf("error parsing flags: %w", err)
+	}
+	if *domain == "" {
+		return fmt.Errorf("error parsing flags: domain is required")
+	}
+
+	if config.DryRun {
+		inputs := append(planInputs(config), planInput{Name: "Certificate Transparency lookup of " + *domain})
+		return writePlan(os.Stdout, config, inputs)
+	}
+
+	runInfo := newRun(config)
+	source := func(ctx context.Context, emit pipeline.Emit) error {
+		if err := inputSource(config, runInfo)(ctx, emit); err != nil {
+			return err
+		}
+
+		// CT lookups query an API rather than crawl a site, so robots.txt does not apply
+		apiConfig := *config
+		apiConfig.IgnoreRobots = true
+		client, err := newHTTPClient(&apiConfig)
+		if err != nil {
+			return fmt.Errorf("error creating HTTP client: %w", err)
+		}
+
+		names, err := (&ct.Client{HTTP: client, BaseURL: ctBaseURL, Cache: openCache(config)}).Hostnames(ctx, *domain)
+		if err != nil {
+			return err
+		}
+		batch := pipeline.Batch{Source: "crt.sh"}
+		for _, name := range names {
+			batch.Findings = append(batch.Findings, finding.Finding{
+				Type:       finding.TypeDomain,
+				Value:      name,
+				Source:     "crt.sh",
+				Confidence: finding.ConfidenceHigh,
+				Tags:       []string{"ct"},
+			})
+		}
+		return emit(batch)
+	}
+
+	return process(ctx, config, runInfo, source)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRunCT(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"common_name": "target.com", "name_value": "target.com\n*.dev.target.com"}]`)
+	}))
+	defer srv.Close()
+
+	oldBaseURL := ctBaseURL
+	ctBaseURL = srv.URL
+	defer func() { ctBaseURL = oldBaseURL }()
+
+	tmpfile, err := os.CreateTemp("", "ct*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	if _, err := tmpfile.WriteString("https://static.target.com/app.js\n"); err != nil {
+		t.Fatal(err)
+	}
+	tmpfile.Close()
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantOutput []string
+		wantErr    string
+	}{
+		{
+			name:       "ct only",
+			args:       []string{"ct", "-domain", "target.com", "-silent"},
+			wantOutput: []string{"dev.target.com\ntarget.com\n"},
+		},
+		{
+			name:       "merged with file",
+			args:       []string{"ct", "-domain", "target.com", "-domains", "-file", tmpfile.Name()},
+			wantOutput: []string{"Extracted Domains:", "dev.target.com", "static.target.com", "target.com"},
+		},
+		{
+			name:    "missing domain",
+			args:    []string{"ct"},
+			wantErr: "domain is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldArgs := os.Args
+			oldStdout := os.Stdout
+			defer func() {
+				os.Args = oldArgs
+				os.Stdout = oldStdout
+			}()
+			os.Args = append([]string{"cmd"}, tt.args...)
+
+			r, w, _ := os.Pipe()
+			os.Stdout = w
+
+			err := run(context.Background())
+			w.Close()
+			var buf bytes.Buffer
+			buf.ReadFrom(r)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("run() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			for _, want := range tt.wantOutput {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("output should contain %q, got %q", want, buf.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/PeteJStewart/urlsluice/internal/httpclient"
+	"github.com/PeteJStewart/urlsluice/internal/monitor"
+	"github.com/PeteJStewart/urlsluice/internal/output"
+	"github.com/PeteJStewart/urlsluice/internal/watch"
+)
+
+// runJobScan runs the scan of a monitor job as a separate urlsluice process and returns its
+// -json document, so a failing scan cannot take the daemon down; tests replace it
+var runJobScan = func(ctx context.Context, args []string) ([]byte, error) {
+	path, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.CommandContext(ctx, path, append(append([]string{}, args...), "-json")...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// runDaemon implements "urlsluice daemon -config monitor.yaml", running the jobs of a monitor
+// configuration on their cron schedules until interrupted. Each scan's findings are merged
+// into the job's baseline, and findings that were not in it are posted to the job's webhook.
+// With -once every job runs immediately, one after the other, and the command exits.
+//
+// The configuration file and the files the jobs' scans read are checked for changes every
+// -reload-interval and on SIGHUP. A changed configuration replaces the running one without a
+// restart, keeping the next run of jobs whose schedule did not change.
+func runDaemon(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("daemon", flag.ContinueOnError)
+	configPath := fs.String("config", "", "Path to the monitor configuration file")
+	once := fs.Bool("once", false, "Run every job once and exit instead of following the schedules")
+	reloadInterval := fs.Duration("reload-interval", 5*time.Second, "How often to check the configuration and the files read by jobs for changes (0 only checks on SIGHUP)")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("error parsing flags: %w", err)
+	}
+	if *configPath == "" {
+		return fmt.Errorf("error parsing flags: -config is required")
+	}
+	config, err := monitor.Load(*configPath)
+	if err != nil {
+		return fmt.Errorf("error loading monitor configuration: %w", err)
+	}
+	// Webhooks are API calls rather than crawling, so robots.txt does not apply
+	client, err := newHTTPClient(&Config{
+		UserAgent:      httpclient.DefaultUserAgent,
+		RequestTimeout: 30 * time.Second,
+		Retries:        2,
+		IgnoreRobots:   true,
+	})
+	if err != nil {
+		return fmt.Errorf("error creating HTTP client: %w", err)
+	}
+
+	if *once {
+		var errs []error
+		for i := range config.Jobs {
+			if err := runMonitorJob(ctx, config, &config.Jobs[i], client); err != nil {
+				errs = append(errs, fmt.Errorf("job %s: %w", config.Jobs[i].Name, err))
+			}
+		}
+		return errors.Join(errs...)
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	var poll <-chan time.Time
+	if *reloadInterval > 0 {
+		ticker := time.NewTicker(*reloadInterval)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
+	files := watch.New(monitorFiles(*configPath, config)...)
+	next := scheduleJobs(config, nil, nil, time.Now())
+	for {
+		due := time.Time{}
+		for _, t := range next {
+			if !t.IsZero() && (due.IsZero() || t.Before(due)) {
+				due = t
+			}
+		}
+		if due.IsZero() {
+			return fmt.Errorf("no job has an upcoming run")
+		}
+		timer := time.NewTimer(time.Until(due))
+		reload := false
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-poll:
+			reload = true
+		case <-hup:
+			reload = true
+		case <-timer.C:
+		}
+		if reload {
+			timer.Stop()
+			if updated := reloadMonitor(*configPath, config, files); updated != config {
+				next = scheduleJobs(updated, config, next, time.Now())
+				config = updated
+			}
+			continue
+		}
+		for i := range config.Jobs {
+			job := &config.Jobs[i]
+			if next[i].IsZero() || next[i].After(due) {
+				continue
+			}
+			// Scans run one at a time; a run that overlaps a job's next slot skips that slot
+			if err := runMonitorJob(ctx, config, job, client); err != nil {
+				logJob(job, "failed: %v", err)
+			}
+			next[i] = job.Next(time.Now())
+		}
+	}
+}
+
+// scheduleJobs returns the next run of each job of config. Jobs that were in the previous
+// configuration, prev, with the same schedule keep their next run from prevNext.
+func scheduleJobs(config, prev *monitor.Config, prevNext []time.Time, now time.Time) []time.Time {
+	kept := make(map[string]time.Time)
+	if prev != nil {
+		for i, job := range prev.Jobs {
+			kept[job.Name+"\x00"+job.Schedule] = prevNext[i]
+		}
+	}
+	next := make([]time.Time, len(config.Jobs))
+	for i := range config.Jobs {
+		job := &config.Jobs[i]
+		if t, ok := kept[job.Name+"\x00"+job.Schedule]; ok && !t.IsZero() {
+			next[i] = t
+			continue
+		}
+		next[i] = job.Next(now)
+		logJob(job, "scheduled, next run at %s", next[i].Format(time.RFC3339))
+	}
+	return next
+}
+
+// jobFileFlags are the flags naming files that a scan reads its settings from
+var jobFileFlags = map[string]bool{
+	"config":          true,
+	"redirect-config": true,
+	"scope-file":      true,
+	"suppress-file":   true,
+	"xss-config":      true,
+}
+
+// monitorFiles returns the files watched for changes: the monitor configuration at path and
+// the settings files named in the arguments of its jobs
+func monitorFiles(path string, config *monitor.Config) []string {
+	paths := []string{path}
+	for _, job := range config.Jobs {
+		for i := 0; i < len(job.Args); i++ {
+			arg := job.Args[i]
+			if arg == "--" {
+				break
+			}
+			name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+			if !strings.HasPrefix(arg, "-") || !jobFileFlags[name] {
+				continue
+			}
+			if !hasValue {
+				if i+1 == len(job.Args) {
+					break
+				}
+				i++
+				value = job.Args[i]
+			}
+			paths = append(paths, value)
+		}
+	}
+	return paths
+}
+
+// reloadMonitor logs the changes to the files watched by files and returns the configuration
+// to use from now on: config itself, unless the configuration file at path changed and still
+// loads. Scans read their other files when they start, so changes to those apply to the next
+// scan without a reload.
+func reloadMonitor(path string, config *monitor.Config, files *watch.Files) *monitor.Config {
+	updated := config
+	for _, change := range files.Poll() {
+		if change.Path != path {
+			if change.New == nil {
+				logDaemon("%s can no longer be read; scans using it will fail", change.Path)
+				continue
+			}
+			logDaemon("%s changed, used from the next scan: %s", change.Path, describeLines(change))
+			continue
+		}
+		loaded, err := monitor.Load(path)
+		if err != nil {
+			logDaemon("error reloading monitor configuration, keeping the current one: %v", err)
+			continue
+		}
+		diff := monitor.Diff(config, loaded)
+		if len(diff) == 0 {
+			logDaemon("%s reloaded, no changes to the jobs", path)
+		}
+		for _, line := range diff {
+			logDaemon("%s reloaded: %s", path, line)
+		}
+		updated = loaded
+	}
+	files.Set(monitorFiles(path, updated)...)
+	return updated
+}
+
+// maxLoggedLines caps the added and removed lines logged for a changed file
+const maxLoggedLines = 5
+
+// describeLines summarizes the lines added and removed by a change, such as
+// "added *.api.target.com; removed old.target.com"
+func describeLines(change watch.Change) string {
+	added, removed := change.Lines()
+	var parts []string
+	for _, side := range []struct {
+		verb  string
+		lines []string
+	}{{"added", added}, {"removed", removed}} {
+		if len(side.lines) == 0 {
+			continue
+		}
+		shown := side.lines
+		if len(shown) > maxLoggedLines {
+			shown = shown[:maxLoggedLines]
+		}
+		part := side.verb + " " + strings.Join(shown, ", ")
+		if n := len(side.lines) - len(shown); n > 0 {
+			part += fmt.Sprintf(" and %d more", n)
+		}
+		parts = append(parts, part)
+	}
+	if len(parts) == 0 {
+		return "only white space or line order changed"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// runMonitorJob scans once for job, updates its baseline and notifies its webhook of new
+// findings. The scan that creates a baseline only records it, so the first run does not
+// report everything as new.
+func runMonitorJob(ctx context.Context, config *monitor.Config, job *monitor.Job, client *httpclient.Client) error {
+	started := time.Now().UTC()
+	data, err := runJobScan(ctx, job.Args)
+	if err != nil {
+		return fmt.Errorf("scan failed: %w", err)
+	}
+	doc, err := output.ReadDocument(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("error reading scan results: %w", err)
+	}
+	added, created, err := monitor.UpdateBaseline(config.BaselinePath(job), doc)
+	if err != nil {
+		return fmt.Errorf("error updating baseline: %w", err)
+	}
+	switch {
+	case created:
+		logJob(job, "baseline created with %d findings", len(doc.Findings))
+		return nil
+	case len(added) == 0:
+		logJob(job, "no new findings")
+		return nil
+	}
+	logJob(job, "%d new findings", len(added))
+	if job.Webhook == "" {
+		return nil
+	}
+	n := monitor.Notification{
+		Job:      job.Name,
+		Time:     started,
+		Text:     fmt.Sprintf("urlsluice job %s: %d new findings", job.Name, len(added)),
+		Findings: added,
+	}
+	if err := monitor.Notify(ctx, client, job.Webhook, n); err != nil {
+		return fmt.Errorf("error notifying webhook: %w", err)
+	}
+	return nil
+}
+
+// logJob writes a timestamped status line about job to standard error
+func logJob(job *monitor.Job, format string, args ...interface{}) {
+	logDaemon("%s: %s", job.Name, fmt.Sprintf(format, args...))
+}
+
+// logDaemon writes a timestamped status line to standard error
+func logDaemon(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "%s %s\n", time.Now().UTC().Format(time.RFC3339), fmt.Sprintf(format, args...))
+}
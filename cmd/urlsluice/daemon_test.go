@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/monitor"
+	"github.com/PeteJStewart/urlsluice/internal/output"
+	"github.com/PeteJStewart/urlsluice/internal/watch"
+)
+
+func TestRun_Daemon(t *testing.T) {
+	var notifications []monitor.Notification
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n monitor.Notification
+		if err := json.NewDecoder(r.Body).Decode(&n); err != nil {
+			t.Error(err)
+		}
+		notifications = append(notifications, n)
+	}))
+	defer server.Close()
+
+	// Each scan reports one more domain than the one before
+	scans := 0
+	oldScan := runJobScan
+	defer func() { runJobScan = oldScan }()
+	runJobScan = func(ctx context.Context, args []string) ([]byte, error) {
+		if strings.Join(args, " ") == "-file broken.txt" {
+			return nil, fmt.Errorf("exit status 1: Error: file not found")
+		}
+		scans++
+		var findings []finding.Finding
+		for i := 0; i <= scans; i++ {
+			findings = append(findings, finding.Finding{Type: finding.TypeDomain, Value: fmt.Sprintf("host%d.target.com", i)})
+		}
+		var buf bytes.Buffer
+		err := output.WriteJSON(&buf, &output.Run{StartedAt: time.Now().UTC()}, findings)
+		return buf.Bytes(), err
+	}
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "monitor.yaml")
+	config := "webhook: " + server.URL + "\njobs:\n  - name: app\n    schedule: '@hourly'\n    args: [-file, urls.txt, -domains]\n"
+	if err := os.WriteFile(configPath, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	brokenPath := filepath.Join(dir, "broken.yaml")
+	if err := os.WriteFile(brokenPath, []byte("jobs:\n  - name: broken\n    schedule: '@daily'\n    args: [-file, broken.txt]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantLog string
+		wantErr string
+	}{
+		{name: "baseline created", args: []string{"daemon", "-config", configPath, "-once"}, wantLog: "app: baseline created with 2 findings"},
+		{name: "new finding", args: []string{"daemon", "-config", configPath, "-once"}, wantLog: "app: 1 new findings"},
+		{name: "scan failure", args: []string{"daemon", "-config", brokenPath, "-once"}, wantErr: "job broken: scan failed: exit status 1: Error: file not found"},
+		{name: "missing config", args: []string{"daemon", "-once"}, wantErr: "-config is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldArgs := os.Args
+			oldFlagCommandLine := flag.CommandLine
+			oldStderr := os.Stderr
+			defer func() {
+				os.Args = oldArgs
+				flag.CommandLine = oldFlagCommandLine
+				os.Stderr = oldStderr
+			}()
+			flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			os.Args = append([]string{"cmd"}, tt.args...)
+
+			r, w, _ := os.Pipe()
+			os.Stderr = w
+			err := run(context.Background())
+			w.Close()
+			var log bytes.Buffer
+			log.ReadFrom(r)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("run() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if !strings.Contains(log.String(), tt.wantLog) {
+				t.Errorf("log = %q, want %q", log.String(), tt.wantLog)
+			}
+		})
+	}
+
+	if len(notifications) != 1 {
+		t.Fatalf("got %d notifications, want 1", len(notifications))
+	}
+	n := notifications[0]
+	if n.Job != "app" || len(n.Findings) != 1 || n.Findings[0].Value != "host2.target.com" {
+		t.Errorf("notification = %+v", n)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "baselines", "app.json")); err != nil {
+		t.Errorf("baseline not written: %v", err)
+	}
+}
+
+func TestReloadMonitor(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "monitor.yaml")
+	scopePath := filepath.Join(dir, "scope.txt")
+	write := func(path, data string) {
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(configPath, "jobs:\n  - name: app\n    schedule: '@daily'\n    args: [-file, urls.txt, -domains, -scope-file, "+scopePath+"]\n  - name: js\n    schedule: '@hourly'\n    args: [-file, app.js, -urls]\n")
+	write(scopePath, "*.target.com\nold.partner.com\n")
+	config, err := monitor.Load(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := watch.New(monitorFiles(configPath, config)...)
+	now := time.Date(2026, 5, 6, 12, 30, 0, 0, time.Local)
+
+	reload := func() (*monitor.Config, string) {
+		oldStderr := os.Stderr
+		defer func() { os.Stderr = oldStderr }()
+		r, w, _ := os.Pipe()
+		os.Stderr = w
+		updated := reloadMonitor(configPath, config, files)
+		w.Close()
+		var log bytes.Buffer
+		log.ReadFrom(r)
+		return updated, log.String()
+	}
+
+	if updated, log := reload(); updated != config || log != "" {
+		t.Fatalf("reload without changes = %p, %q", updated, log)
+	}
+
+	write(scopePath, "*.target.com\n*.api.target.com\n")
+	if updated, log := reload(); updated != config || !strings.Contains(log, scopePath+" changed, used from the next scan: added *.api.target.com; removed old.partner.com") {
+		t.Errorf("reload after a scope change = %p, %q", updated, log)
+	}
+
+	// An invalid configuration is reported and the running one kept
+	write(configPath, "jobs:\n  - name: app\n    schedule: '@sometimes'\n    args: [-file, urls.txt]\n")
+	if updated, log := reload(); updated != config || !strings.Contains(log, "error reloading monitor configuration, keeping the current one") {
+		t.Errorf("reload of an invalid configuration = %p, %q", updated, log)
+	}
+
+	write(configPath, "jobs:\n  - name: app\n    schedule: '@daily'\n    args: [-file, urls.txt, -domains]\n  - name: js\n    schedule: '@weekly'\n    args: [-file, app.js, -urls]\n")
+	updated, log := reload()
+	if updated == config || !strings.Contains(log, `job js: schedule changed from "@hourly" to "@weekly"`) || !strings.Contains(log, "job app: args changed") {
+		t.Fatalf("reload of a valid configuration = %p, %q", updated, log)
+	}
+	next := []time.Time{now.Add(time.Hour), now.Add(time.Minute)}
+	got := scheduleJobs(updated, config, next, now)
+	if !got[0].Equal(next[0]) || !got[1].Equal(updated.Jobs[1].Next(now)) {
+		t.Errorf("scheduleJobs() = %v, want the next run of app kept and js rescheduled", got)
+	}
+
+	// The scope file is no longer used by any job
+	write(scopePath, "*.target.com\n")
+	if _, log := reload(); log != "" {
+		t.Errorf("reload after changing an unused file logged %q", log)
+	}
+}
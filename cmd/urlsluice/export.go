@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/idor"
+)
+
+// exportIDOR is the -export format listing IDOR candidate URLs
+const exportIDOR = "idor"
+
+// idorNotice heads the IDOR export so candidates are not mistaken for confirmed issues
+const idorNotice = "# IDOR candidates: unverified. Replay each request with the session of a user who should not " +
+	"have access and compare the responses manually."
+
+// writeExport writes the candidates of the -export format derived from the URL findings.
+// In silent mode only the candidate URLs are written.
+func writeExport(w io.Writer, config *Config, findings []finding.Finding) error {
+	if !config.Silent {
+		if _, err := fmt.Fprintln(w, idorNotice); err != nil {
+			return err
+		}
+	}
+	seen := make(map[string]bool)
+	for _, f := range findings {
+		if f.Type != finding.TypeURL {
+			continue
+		}
+		for _, c := range idor.Candidates(f.Value) {
+			if seen[c.URL] {
+				continue
+			}
+			seen[c.URL] = true
+			line := c.URL
+			if !config.Silent {
+				line += fmt.Sprintf("\t%s: %s -> %s", c.Param, c.Original, c.Value)
+			}
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"testing"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+func TestWriteExport_IDOR(t *testing.T) {
+	findings := []finding.Finding{
+		{Type: finding.TypeURL, Value: "https://api.example.com/invoice?id=5"},
+		{Type: finding.TypeURL, Value: "https://api.example.com/search?q=5"},
+		{Type: finding.TypeDomain, Value: "api.example.com"},
+	}
+
+	var buf bytes.Buffer
+	if err := writeExport(&buf, &Config{Export: exportIDOR, Silent: true}, findings); err != nil {
+		t.Fatalf("writeExport() error = %v", err)
+	}
+	want := "https://api.example.com/invoice?id=4\nhttps://api.example.com/invoice?id=6\n" +
+		"https://api.example.com/invoice?id=15\nhttps://api.example.com/invoice?id=0\n" +
+		"https://api.example.com/invoice?id=1\nhttps://api.example.com/invoice?id=2\n" +
+		"https://api.example.com/invoice?id=100\nhttps://api.example.com/invoice?id=1000\n"
+	if got := buf.String(); got != want {
+		t.Errorf("silent export = %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	if err := writeExport(&buf, &Config{Export: exportIDOR}, findings[:1]); err != nil {
+		t.Fatalf("writeExport() error = %v", err)
+	}
+	if got := buf.String(); !bytes.HasPrefix(buf.Bytes(), []byte(idorNotice+"\n")) ||
+		!bytes.Contains(buf.Bytes(), []byte("https://api.example.com/invoice?id=4\tid: 5 -> 4\n")) {
+		t.Errorf("export = %q, want notice and annotated candidates", got)
+	}
+}
+
+func TestParseFlagSet_Export(t *testing.T) {
+	config, err := parseFlagSet(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-file", "x", "-export", "idor"})
+	if err != nil {
+		t.Fatalf("parseFlagSet() error = %v", err)
+	}
+	if !config.ExtractURLs {
+		t.Error("-export did not enable URL extraction")
+	}
+
+	if _, err := parseFlagSet(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-file", "x", "-export", "csv"}); err == nil {
+		t.Error("parseFlagSet() accepted unknown export format")
+	}
+}
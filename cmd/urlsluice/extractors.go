@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	configfile "github.com/PeteJStewart/urlsluice/internal/config"
+	"github.com/PeteJStewart/urlsluice/internal/extractor"
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/plugin"
+)
+
+// extractors creates the extractor for each input, limited by the file_types rules of the
+// configuration file to the extractors that apply to the input's extension.
+// Extractors are shared between inputs that match the same rule. The plugins of the
+// configuration file run on every input.
+type extractors struct {
+	base     extractor.Config
+	settings *configfile.Config
+	plugins  []*plugin.Plugin
+	cache    map[string]extractor.Extractor
+}
+
+func newExtractors(config *Config) *extractors {
+	exts := &extractors{
+		// Crawling and -group-by host need URLs even when they are not reported
+		base: extractor.Config{
+			UUIDVersion:       config.UUIDVersion,
+			UUIDAll:           config.UUIDDetect,
+			ExtractEmails:     config.ExtractEmails,
+			DeobfuscateEmails: config.DeobfuscateEmails,
+			ExtractDomains:    config.ExtractDomains,
+			ExtractIPs:        config.ExtractIPs,
+			ExtractParams:     config.ExtractParams,
+			ExtractURLs:       config.ExtractURLs || config.CrawlDepth > 0 || config.GroupBy == groupByHost,
+			ExtractHandles:    config.ExtractHandles,
+			ExtractCrypto:     config.ExtractCrypto,
+			ExtractCloud:      config.ExtractCloud,
+			ExtractSecrets:    config.ExtractSecrets,
+			ExtractTimes:      config.Timestamps,
+			ExtractUsernames:  config.Usernames,
+			ExtractAvatars:    config.AvatarHashes,
+			ExtractBuckets:    config.ExtractBuckets,
+			ExtractJSONP:      config.ExtractJSONP,
+			ExtractErrors:     config.ExtractErrors,
+			ExtractComments:   config.ExtractComments,
+			CommentKeywords:   config.CommentKeywords,
+			ExtractLocales:    config.ExtractLocales,
+			EntropyMin:        config.EntropyMin,
+			DecodeParams:      config.DecodeParams,
+			ParseURLs:         config.ParseURLs,
+			Structured:        config.Structured,
+			MaxLineLength:     int(config.MaxLineLength),
+		},
+		settings: config.Settings,
+		plugins:  config.Plugins,
+		cache:    make(map[string]extractor.Extractor),
+	}
+	if config.LowMemory {
+		exts.base.Workers, exts.base.ChunkSize = 1, lowMemoryChunkSize
+	}
+	return exts
+}
+
+// configFor returns the extractor configuration for the input at name, a file path or URL path
+func (e *extractors) configFor(name string) extractor.Config {
+	cfg := e.base
+	if e.settings != nil {
+		if allowed := e.settings.Extractors(name); allowed != nil {
+			cfg = cfg.Only(allowed)
+		}
+	}
+	return cfg
+}
+
+// forPath returns the extractor for the input at name, a file path or URL path
+func (e *extractors) forPath(name string) (extractor.Extractor, error) {
+	cfg := e.configFor(name)
+	key := fmt.Sprintf("%+v", cfg)
+	if ext, ok := e.cache[key]; ok {
+		return ext, nil
+	}
+	ext, err := extractor.New(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error creating extractor: %w", err)
+	}
+	if len(e.plugins) > 0 {
+		ext = withPlugins{Extractor: ext, plugins: e.plugins}
+	}
+	e.cache[key] = ext
+	return ext, nil
+}
+
+// withPlugins runs extractor plugins on the input of an extractor and merges their findings
+type withPlugins struct {
+	extractor.Extractor
+	plugins []*plugin.Plugin
+}
+
+func (w withPlugins) Extract(ctx context.Context, reader io.Reader) (extractor.Results, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return extractor.Results{}, &extractor.ExtractorError{Op: "Extract", Err: err}
+	}
+	results, err := w.Extractor.Extract(ctx, bytes.NewReader(data))
+	if err != nil {
+		return results, err
+	}
+	merged := &finding.Set{}
+	for _, f := range results.Findings {
+		merged.Add(f)
+	}
+	for _, p := range w.plugins {
+		r, err := p.Extract(ctx, bytes.NewReader(data))
+		if err != nil {
+			return extractor.Results{}, err
+		}
+		for _, f := range r.Findings {
+			merged.Add(f)
+		}
+	}
+	return extractor.Results{Findings: merged.Findings()}, nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	configfile "github.com/PeteJStewart/urlsluice/internal/config"
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/plugin"
+)
+
+func TestExtractors_ForPath(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "urlsluice.yaml")
+	if err := os.WriteFile(configPath, []byte("file_types:\n  - extensions: [.js]\n    include: [url]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	settings, err := configfile.Load(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exts := newExtractors(&Config{ExtractEmails: true, ExtractURLs: true, Settings: settings})
+
+	input := "fetch('https://api.example.com/v1'); // admin@example.com"
+	tests := []struct {
+		path string
+		want []finding.Type
+	}{
+		{"bundle.js", []finding.Type{finding.TypeURL}},
+		{"/static/bundle.js", []finding.Type{finding.TypeURL}},
+		{"page.html", []finding.Type{finding.TypeEmail, finding.TypeURL}},
+	}
+
+	for _, tt := range tests {
+		ext, err := exts.forPath(tt.path)
+		if err != nil {
+			t.Fatalf("forPath(%q) error = %v", tt.path, err)
+		}
+		results, err := ext.Extract(context.Background(), strings.NewReader(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []finding.Type
+		for _, f := range results.Findings {
+			got = append(got, f.Type)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("forPath(%q) extracted %v, want %v", tt.path, got, tt.want)
+		}
+	}
+	if len(exts.cache) != 2 {
+		t.Errorf("cached %d extractors, want 2", len(exts.cache))
+	}
+}
+
+// TestHelperPlugin is not a real test: it is the extractor plugin run by TestExtractors_Plugins,
+// reporting the words that start with "key_"
+func TestHelperPlugin(t *testing.T) {
+	if os.Getenv("URLSLUICE_TEST_PLUGIN") == "" {
+		return
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var req struct {
+			Line int    `json:"line"`
+			Text string `json:"text"`
+		}
+		json.Unmarshal(scanner.Bytes(), &req)
+		for _, word := range strings.Fields(req.Text) {
+			if strings.HasPrefix(word, "key_") {
+				fmt.Printf("{\"value\": %q, \"line\": %d}\n", word, req.Line)
+			}
+		}
+	}
+	os.Exit(0)
+}
+
+func TestExtractors_Plugins(t *testing.T) {
+	t.Setenv("URLSLUICE_TEST_PLUGIN", "1")
+	// Other tests replace os.Args, so the test binary is found through os.Executable
+	self, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exts := newExtractors(&Config{
+		ExtractEmails: true,
+		Plugins:       []*plugin.Plugin{{Name: "keys", Command: []string{self, "-test.run=^TestHelperPlugin$"}}},
+	})
+	ext, err := exts.forPath("app.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := ext.Extract(context.Background(), strings.NewReader("admin@example.com\nconst k = key_1234\n"))
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	want := []finding.Finding{
+		{Type: finding.TypeEmail, Value: "admin@example.com", Line: 1, Confidence: finding.ConfidenceHigh},
+		{Type: finding.TypePlugin, Value: "key_1234", Line: 2, Confidence: finding.ConfidenceMedium, Metadata: map[string]string{"plugin": "keys"}},
+	}
+	if !reflect.DeepEqual(results.Findings, want) {
+		t.Errorf("Extract() = %+v, want %+v", results.Findings, want)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/graphql"
+	"github.com/PeteJStewart/urlsluice/internal/output"
+	"github.com/PeteJStewart/urlsluice/internal/pipeline"
+)
+
+// readGraphQL reads and parses the -graphql introspection result, recording it in runInfo
+func readGraphQL(config *Config, runInfo *output.Run) (*graphql.Schema, error) {
+	data, err := os.ReadFile(config.GraphQLPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading GraphQL introspection: %w", err)
+	}
+	runInfo.AddInput(config.GraphQLPath, data)
+	schema, err := graphql.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("error reading GraphQL introspection %s: %w", config.GraphQLPath, err)
+	}
+	return schema, nil
+}
+
+// graphQLSource emits the arguments of the schema's queries, mutations and subscriptions
+// as parameters when -queryParams is set
+func graphQLSource(config *Config, runInfo *output.Run) pipeline.Source {
+	return func(ctx context.Context, emit pipeline.Emit) error {
+		schema, err := readGraphQL(config, runInfo)
+		if err != nil {
+			return err
+		}
+		var findings []finding.Finding
+		if newExtractors(config).base.ExtractParams {
+			findings = graphQLFindings(schema)
+		}
+		return emit(pipeline.Batch{Source: config.GraphQLPath, Findings: withSource(findings, config.GraphQLPath)})
+	}
+}
+
+// graphQLFindings reports every argument of every operation as "name=", recording the
+// operation, e.g. "mutation updateUser", and the argument type
+func graphQLFindings(schema *graphql.Schema) []finding.Finding {
+	var findings []finding.Finding
+	for _, op := range schema.Operations() {
+		for _, arg := range op.Field.Args {
+			f := finding.Finding{Type: finding.TypeParam, Value: arg.Name + "=", Confidence: finding.ConfidenceHigh}
+			f.SetMeta("operation", op.Kind+" "+op.Field.Name)
+			f.SetMeta("type", arg.Type)
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
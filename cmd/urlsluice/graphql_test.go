@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testIntrospection = `{"data": {"__schema": {
+  "queryType": {"name": "Query"},
+  "mutationType": {"name": "Mutation"},
+  "types": [
+    {"kind": "OBJECT", "name": "Query", "fields": [
+      {"name": "user", "args": [{"name": "userId", "type": {"kind": "SCALAR", "name": "ID"}}], "type": {"kind": "OBJECT", "name": "User"}}
+    ]},
+    {"kind": "OBJECT", "name": "Mutation", "fields": [
+      {"name": "resetPassword", "args": [{"name": "email", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "String"}}}], "type": {"kind": "SCALAR", "name": "Boolean"}}
+    ]},
+    {"kind": "OBJECT", "name": "User", "fields": [{"name": "apiToken", "args": []}]}
+  ]
+}}}`
+
+func TestRunGraphQL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "introspection.json")
+	if err := os.WriteFile(path, []byte(testIntrospection), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantOutput string
+		wantErr    string
+	}{
+		{
+			name:       "operation arguments",
+			args:       []string{"-graphql", path, "-queryParams", "-json"},
+			wantOutput: `"operation": "mutation resetPassword"`,
+		},
+		{
+			name:       "wordlist",
+			args:       []string{"-graphql", path, "-wordlist"},
+			wantOutput: "Mutation\nQuery\nUser\napiToken\nemail\nresetPassword\nuser\nuserId\n",
+		},
+		{
+			name:    "not an introspection result",
+			args:    []string{"-graphql", "graphql_test.go", "-queryParams"},
+			wantErr: "error reading GraphQL introspection",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldArgs := os.Args
+			oldStdout := os.Stdout
+			oldFlagCommandLine := flag.CommandLine
+			defer func() {
+				os.Args = oldArgs
+				os.Stdout = oldStdout
+				flag.CommandLine = oldFlagCommandLine
+			}()
+			flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			os.Args = append([]string{"cmd"}, tt.args...)
+
+			r, w, _ := os.Pipe()
+			os.Stdout = w
+
+			err := run(context.Background())
+			w.Close()
+			var out bytes.Buffer
+			out.ReadFrom(r)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("run() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if !strings.Contains(out.String(), tt.wantOutput) {
+				t.Errorf("output should contain %q, got %q", tt.wantOutput, out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/PeteJStewart/urlsluice/internal/cache"
+	"github.com/PeteJStewart/urlsluice/internal/httpclient"
+)
+
+// userCacheDir locates the default -cache-dir; tests point it at a temporary directory
+var userCacheDir = cache.DefaultDir
+
+// newHTTPClient builds the shared HTTP client from the network flags.
+// Every feature that makes requests must use it so proxy and TLS settings apply everywhere.
+func newHTTPClient(config *Config) (*httpclient.Client, error) {
+	headers := make(http.Header)
+	for _, spec := range config.Headers {
+		name, value, err := httpclient.ParseHeader(spec)
+		if err != nil {
+			return nil, err
+		}
+		headers.Add(name, value)
+	}
+
+	maxRedirects := config.MaxRedirects
+	if maxRedirects == 0 {
+		maxRedirects = -1
+	}
+	return httpclient.New(httpclient.Options{
+		Timeout:       config.RequestTimeout,
+		MaxBodySize:   int64(config.MaxBody),
+		MaxRedirects:  maxRedirects,
+		UserAgent:     config.UserAgent,
+		Headers:       headers,
+		Proxy:         config.Proxy,
+		Insecure:      config.Insecure,
+		Retries:       config.Retries,
+		HostDelay:     config.HostDelay,
+		RateLimiter:   config.RateLimiter,
+		RespectRobots: !config.IgnoreRobots,
+	})
+}
+
+var cacheWarning sync.Once
+
+// openCache opens the network cache unless -no-cache is given. Results fetched with other
+// request headers are kept apart. The cache only saves work, so when it cannot be opened a
+// warning is printed and the run continues without it.
+func openCache(config *Config) *cache.Cache {
+	if config.NoCache {
+		return nil
+	}
+	dir := config.CacheDir
+	if dir == "" {
+		var err error
+		if dir, err = userCacheDir(); err != nil {
+			cacheWarning.Do(func() { fmt.Fprintf(os.Stderr, "Warning: network cache disabled: %v\n", err) })
+			return nil
+		}
+	}
+	variant := config.UserAgent + "\n" + strings.Join(config.Headers, "\n")
+	c, err := cache.New(dir, config.CacheTTL, variant)
+	if err != nil {
+		cacheWarning.Do(func() { fmt.Fprintf(os.Stderr, "Warning: network cache disabled: %v\n", err) })
+		return nil
+	}
+	return c
+}
@@ -0,0 +1,7 @@
+//go:build hyperscan && cgo
+
+package main
+
+func init() {
+	buildFeatures = append(buildFeatures, "hyperscan")
+}
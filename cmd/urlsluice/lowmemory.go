@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"flag"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+
+	"github.com/PeteJStewart/urlsluice/internal/audit"
+	"github.com/PeteJStewart/urlsluice/internal/bloom"
+	"github.com/PeteJStewart/urlsluice/internal/decode"
+	"github.com/PeteJStewart/urlsluice/internal/extractor"
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/httpclient"
+	"github.com/PeteJStewart/urlsluice/internal/output"
+	"github.com/PeteJStewart/urlsluice/internal/pipeline"
+	"github.com/PeteJStewart/urlsluice/internal/redact"
+	"github.com/PeteJStewart/urlsluice/internal/transform"
+)
+
+// Settings of -low-memory, for a Raspberry Pi or a VPS with a few hundred MB of RAM
+const (
+	// lowMemoryBuffer is the number of batches queued between pipeline stages
+	lowMemoryBuffer = 1
+	// lowMemoryChunkSize is the amount of input matched at a time
+	lowMemoryChunkSize = 64 * 1024
+	// lowMemoryMaxLineLength is the -max-line-length default
+	lowMemoryMaxLineLength = 64 * 1024
+	// lowMemoryFindings is the number of distinct findings the Bloom filter is sized for,
+	// and lowMemoryFalsePositives the share of them wrongly taken for duplicates: about 1.8MB
+	lowMemoryFindings       = 1 << 20
+	lowMemoryFalsePositives = 0.001
+)
+
+// applyLowMemory checks that -low-memory is not combined with options that need every
+// finding before writing, and lowers the defaults of -max-line-length and
+// -crawl-concurrency unless they are given
+func applyLowMemory(fs *flag.FlagSet, config *Config) error {
+	for _, opt := range []struct {
+		name string
+		set  bool
+	}{
+		{"json", config.JSON},
+		{"export", config.Export != ""},
+		{"group-by", config.GroupBy != ""},
+		{"unique-values", config.UniqueValues},
+		{"max-per-category", config.MaxPerCategory > 0},
+		{"avatar-correlate", config.AvatarCorrelate},
+		{"xref", config.Xref},
+		{"uuid-detect", config.UUIDDetect},
+		{"timestamps", config.Timestamps},
+	} {
+		if opt.set {
+			return fmt.Errorf("-low-memory cannot be combined with -%s, which needs every finding before writing", opt.name)
+		}
+	}
+	if !flagPassed(fs, "max-line-length") {
+		config.MaxLineLength = lowMemoryMaxLineLength
+	}
+	if !flagPassed(fs, "crawl-concurrency") {
+		config.CrawlConcurrency = 1
+	}
+	return nil
+}
+
+// processStream runs the pipeline like process with -low-memory, writing the findings of
+// each batch as it leaves the pipeline instead of collecting them. The -file and -url
+// inputs are read a chunk at a time by streamInput, so their findings are written chunk by
+// chunk and memory use does not grow with the size of the inputs.
+func processStream(ctx context.Context, config *Config, runInfo *output.Run, source pipeline.Source) error {
+	stages, finish, err := newStages(config)
+	if err != nil {
+		return err
+	}
+
+	stats := &pipeline.Stats{}
+	stream := newStreamWriter(os.Stdout, config, runInfo)
+	p := &pipeline.Pipeline{Buffer: lowMemoryBuffer, Stats: stats, ReadTimeout: config.TimeoutRead}
+	err = p.Run(ctx, source, stages, stream.Write)
+	if err == nil {
+		err = finish()
+	}
+	if config.Stats {
+		stats.Write(os.Stderr)
+	}
+	return err
+}
+
+// streamFile emits the -file input at path, "-" being standard input, a chunk at a time
+func streamFile(ctx context.Context, config *Config, runInfo *output.Run, path string, emit pipeline.Emit) error {
+	source, r := path, io.Reader(os.Stdin)
+	if path == stdinPath {
+		source = stdinSource
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("error reading file: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+	if err := streamInput(ctx, config, runInfo, source, r, emit); err != nil {
+		return fmt.Errorf("error reading file: %w", err)
+	}
+	return nil
+}
+
+// streamURL emits the body of the -url page at rawURL a chunk at a time
+func streamURL(ctx context.Context, config *Config, runInfo *output.Run, client *httpclient.Client, rawURL string, emit pipeline.Emit) error {
+	body, err := openInput(ctx, client, rawURL)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	return streamInput(ctx, config, runInfo, rawURL, body, emit)
+}
+
+// streamInput emits the input read from r in batches of lowMemoryChunkSize bytes of whole
+// lines, converted to UTF-8 and numbered by their first line, and records its checksum in
+// runInfo. The encoding is detected once from the start of the input, which is also where
+// binary input is recognized and skipped or, with -strings, reduced to its printable strings.
+func streamInput(ctx context.Context, config *Config, runInfo *output.Run, source string, r io.Reader, emit pipeline.Emit) error {
+	sum := &inputSum{hash: sha256.New()}
+	br := bufio.NewReaderSize(io.TeeReader(r, sum), lowMemoryChunkSize)
+	head, _ := br.Peek(lowMemoryChunkSize)
+	binary := (config.Encoding == decode.Auto || config.Encoding == "") && decode.Binary(head)
+
+	var err error
+	switch {
+	case binary && !config.Strings:
+		fmt.Fprintf(os.Stderr, "Warning: skipping binary input %s; use -strings to extract its printable text\n", source)
+		_, err = io.Copy(io.Discard, br)
+	case binary:
+		// Strings are numbered by their line in the strings of the whole input
+		line := 1
+		err = extractor.Chunks(br, lowMemoryChunkSize, func(chunk []byte, _ int) error {
+			text := decode.Strings(chunk, decode.MinStringLength)
+			b := pipeline.Batch{Source: source, Data: text, Decoded: true, Line: line}
+			line += bytes.Count(text, []byte("\n"))
+			return emitChunk(ctx, emit, b)
+		})
+	default:
+		err = extractor.Chunks(decode.NewReader(br, config.Encoding), lowMemoryChunkSize, func(chunk []byte, line int) error {
+			return emitChunk(ctx, emit, pipeline.Batch{Source: source, Data: chunk, Decoded: true, Line: line})
+		})
+	}
+	if err != nil {
+		return err
+	}
+	runInfo.AddInputSum(source, sum.hash.Sum(nil), sum.size)
+	return nil
+}
+
+// emitChunk emits b unless the run was cancelled
+func emitChunk(ctx context.Context, emit pipeline.Emit, b pipeline.Batch) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return emit(b)
+}
+
+// inputSum computes the checksum and size of an input as it is read
+type inputSum struct {
+	hash hash.Hash
+	size int64
+}
+
+func (s *inputSum) Write(p []byte) (int, error) {
+	s.size += int64(len(p))
+	return s.hash.Write(p)
+}
+
+// streamWriter writes findings in text as they are found, skipping those already written.
+// Findings are remembered by a Bloom filter, so memory stays bounded however many there
+// are, at the cost of dropping the few wrongly taken for duplicates.
+type streamWriter struct {
+	config  *Config
+	runInfo *output.Run
+	runID   string
+	key     func(finding.Finding) string
+	seen    *bloom.Filter
+	out     *bufio.Writer
+}
+
+func newStreamWriter(w io.Writer, config *Config, runInfo *output.Run) *streamWriter {
+	key := finding.Finding.Key
+	if config.Dedupe.Enabled() {
+		key = config.Dedupe.KeyFunc()
+	}
+	return &streamWriter{
+		config:  config,
+		runInfo: runInfo,
+		runID:   audit.NewRunID(),
+		key:     key,
+		seen:    bloom.New(lowMemoryFindings, lowMemoryFalsePositives),
+		out:     bufio.NewWriter(w),
+	}
+}
+
+// Write writes the findings of b not seen before, in finding.Sort order, and flushes them
+func (s *streamWriter) Write(b pipeline.Batch) error {
+	// Parameters split by -params and transformed values are deduplicated as written
+	var findings []finding.Finding
+	for _, f := range transform.Apply(paramParts(b.Findings, s.config.ParamsMode), s.config.TransformRules) {
+		if !s.seen.Add(s.key(f)) {
+			findings = append(findings, f)
+		}
+	}
+	if len(findings) == 0 {
+		return nil
+	}
+	finding.Sort(findings)
+	if s.config.AuditLog != "" {
+		if err := audit.Append(s.config.AuditLog, s.runID, s.runInfo.StartedAt, findings); err != nil {
+			return fmt.Errorf("error writing audit log: %w", err)
+		}
+	}
+	if s.config.Redact {
+		findings = redact.Findings(findings)
+	}
+	opts := textOptions(s.config)
+	for _, f := range findings {
+		if err := output.WriteFinding(s.out, f, opts); err != nil {
+			return err
+		}
+	}
+	return s.out.Flush()
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/output"
+	"github.com/PeteJStewart/urlsluice/internal/pipeline"
+)
+
+func TestParseFlagSet_LowMemory(t *testing.T) {
+	config, err := parseFlagSet(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-file", "x", "-low-memory"})
+	if err != nil {
+		t.Fatalf("parseFlagSet() error = %v", err)
+	}
+	if config.MaxLineLength != lowMemoryMaxLineLength || config.CrawlConcurrency != 1 {
+		t.Errorf("-low-memory set -max-line-length %d and -crawl-concurrency %d", config.MaxLineLength, config.CrawlConcurrency)
+	}
+
+	config, err = parseFlagSet(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-file", "x", "-low-memory", "-max-line-length", "2MB", "-crawl-concurrency", "2"})
+	if err != nil {
+		t.Fatalf("parseFlagSet() error = %v", err)
+	}
+	if config.MaxLineLength != 2<<20 || config.CrawlConcurrency != 2 {
+		t.Errorf("-low-memory overrode -max-line-length %d and -crawl-concurrency %d", config.MaxLineLength, config.CrawlConcurrency)
+	}
+
+	if _, err := parseFlagSet(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-file", "x", "-low-memory", "-max-per-category", "10"}); err == nil {
+		t.Error("parseFlagSet() accepted -low-memory with -max-per-category")
+	}
+}
+
+func TestStreamWriter(t *testing.T) {
+	var buf bytes.Buffer
+	stream := newStreamWriter(&buf, &Config{Silent: true, NoColor: true}, &output.Run{})
+	batches := []pipeline.Batch{
+		{Source: "a.html", Findings: []finding.Finding{
+			{Type: finding.TypeURL, Value: "https://target.com/login"},
+			{Type: finding.TypeEmail, Value: "dev@target.com"},
+		}},
+		// Nothing new
+		{Source: "b.html", Findings: []finding.Finding{{Type: finding.TypeEmail, Value: "dev@target.com"}}},
+		{Source: "c.html", Findings: []finding.Finding{
+			{Type: finding.TypeEmail, Value: "dev@target.com"},
+			{Type: finding.TypeDomain, Value: "target.com"},
+		}},
+	}
+	for _, b := range batches {
+		if err := stream.Write(b); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	// Each batch is sorted and written once it arrives
+	want := "dev@target.com\nhttps://target.com/login\ntarget.com\n"
+	if got := buf.String(); got != want {
+		t.Errorf("streamed output = %q, want %q", got, want)
+	}
+}
+
+func TestProcessStream_FlatMemory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("generates a 32MB input")
+	}
+	// Few distinct findings, so that only reading the input could use much memory
+	const size = 32 << 20
+	path := filepath.Join(t.TempDir(), "access.log")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := bufio.NewWriter(f)
+	for n, i := 0, 0; n < size; i++ {
+		c, _ := fmt.Fprintf(w, "10.0.%d.%d - - GET /static/app.js?v=%d HTTP/1.1 https://cdn%d.target.com/\n", i%200, i%250, i, i%50)
+		n += c
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	config, err := parseFlagSet(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-file", path, "-low-memory", "-uuid", "0", "-urls", "-silent"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+	oldStdout := os.Stdout
+	os.Stdout = devNull
+	defer func() { os.Stdout = oldStdout }()
+
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	base := stats.HeapAlloc
+	var peak atomic.Uint64
+	done := make(chan struct{})
+	sampled := make(chan struct{})
+	go func() {
+		defer close(sampled)
+		var stats runtime.MemStats
+		for {
+			runtime.ReadMemStats(&stats)
+			if stats.HeapAlloc > peak.Load() {
+				peak.Store(stats.HeapAlloc)
+			}
+			select {
+			case <-done:
+				return
+			case <-time.After(time.Millisecond):
+			}
+		}
+	}()
+
+	err = processStream(context.Background(), config, &output.Run{}, fileSource(config, &output.Run{}))
+	close(done)
+	<-sampled
+	if err != nil {
+		t.Fatalf("processStream() error = %v", err)
+	}
+	// Reading the input whole would take 32MB; chunks, the Bloom filter and garbage take a few
+	if grown := int64(peak.Load()) - int64(base); grown > 16<<20 {
+		t.Errorf("heap grew by %dMB processing a %dMB input, want it to stay flat", grown>>20, size>>20)
+	}
+}
+
+func TestStreamInput(t *testing.T) {
+	var text strings.Builder
+	for text.Len() < 3*lowMemoryChunkSize {
+		text.WriteString("filler line\n")
+	}
+	lines := strings.Count(text.String(), "\n")
+	text.WriteString("https://target.com/é\n")
+	// UTF-16 with a byte order mark, which only the first chunk starts with
+	data := []byte{0xFF, 0xFE}
+	for _, r := range text.String() {
+		data = append(data, byte(r), byte(r>>8))
+	}
+
+	runInfo := &output.Run{}
+	var batches []pipeline.Batch
+	err := streamInput(context.Background(), &Config{Encoding: "auto"}, runInfo, "in.txt", bytes.NewReader(data), func(b pipeline.Batch) error {
+		batches = append(batches, b)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("streamInput() error = %v", err)
+	}
+	if len(batches) < 3 {
+		t.Fatalf("streamInput() emitted %d batches, want the input in chunks", len(batches))
+	}
+	var joined strings.Builder
+	line := 1
+	for _, b := range batches {
+		if !b.Decoded || b.Line != line || len(b.Data) > 2*lowMemoryChunkSize {
+			t.Errorf("batch at line %d: Decoded = %v, Line = %d, %d bytes", line, b.Decoded, b.Line, len(b.Data))
+		}
+		joined.Write(b.Data)
+		line += bytes.Count(b.Data, []byte("\n"))
+	}
+	if joined.String() != text.String() {
+		t.Error("batches do not make up the decoded input")
+	}
+	last := batches[len(batches)-1]
+	if want := lines + 1; last.Line+bytes.Count(last.Data, []byte("\n"))-1 != want {
+		t.Errorf("last line numbered %d, want %d", last.Line+bytes.Count(last.Data, []byte("\n"))-1, want)
+	}
+	if len(runInfo.Inputs) != 1 || runInfo.Inputs[0].Size != int64(len(data)) {
+		t.Errorf("recorded inputs %+v, want in.txt of %d bytes", runInfo.Inputs, len(data))
+	}
+}
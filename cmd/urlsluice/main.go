@@ -1,40 +1,186 @@
 package main
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
-	"sort"
+	"runtime"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"flag"
 
+	"github.com/PeteJStewart/urlsluice/internal/audit"
+	"github.com/PeteJStewart/urlsluice/internal/cache"
+	"github.com/PeteJStewart/urlsluice/internal/classify"
+	configfile "github.com/PeteJStewart/urlsluice/internal/config"
+	"github.com/PeteJStewart/urlsluice/internal/decode"
+	"github.com/PeteJStewart/urlsluice/internal/dedupe"
 	"github.com/PeteJStewart/urlsluice/internal/extractor"
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/grep"
+	"github.com/PeteJStewart/urlsluice/internal/httpclient"
+	"github.com/PeteJStewart/urlsluice/internal/output"
+	"github.com/PeteJStewart/urlsluice/internal/pipeline"
+	"github.com/PeteJStewart/urlsluice/internal/plugin"
 	"github.com/PeteJStewart/urlsluice/internal/redirect"
+	"github.com/PeteJStewart/urlsluice/internal/regexlint"
+	"github.com/PeteJStewart/urlsluice/internal/remediation"
+	"github.com/PeteJStewart/urlsluice/internal/scope"
+	"github.com/PeteJStewart/urlsluice/internal/suppress"
+	"github.com/PeteJStewart/urlsluice/internal/tempdir"
+	"github.com/PeteJStewart/urlsluice/internal/transform"
+	"github.com/PeteJStewart/urlsluice/internal/uuids"
 	"github.com/PeteJStewart/urlsluice/internal/wordlist"
+	"github.com/PeteJStewart/urlsluice/internal/xref"
+	"github.com/PeteJStewart/urlsluice/internal/xss"
 )
 
 // Config holds the command-line configuration
 type Config struct {
-	FilePath         string
-	UUIDVersion      int
-	ExtractEmails    bool
-	ExtractDomains   bool
-	ExtractIPs       bool
-	ExtractParams    bool
-	Silent           bool
-	GenerateWordlist bool
-	DetectRedirects  bool
-	RedirectConfig   string
+	FilePaths          []string
+	URLs               []string
+	UUIDVersion        int
+	ExtractEmails      bool
+	DeobfuscateEmails  bool
+	ExtractDomains     bool
+	ExtractIPs         bool
+	ExtractParams      bool
+	Silent             bool
+	GenerateWordlist   bool
+	WordlistLang       string
+	WordlistNGrams     int
+	WordlistMutate     bool
+	WordlistYears      string
+	WordlistSubtract   []string
+	DetectRedirects    bool
+	RedirectConfig     string
+	DetectXSSParams    bool
+	XSSConfig          string
+	MinConfidence      finding.Confidence
+	EntropyMin         float64
+	TLDs               []string
+	ExcludeTLDs        []string
+	IncludeReserved    bool
+	OnlyInternal       bool
+	InternalSection    bool
+	ScopeFile          string
+	Scope              *scope.Scope
+	SuppressFile       string
+	SuppressKey        string
+	Suppressions       *suppress.List
+	OutOfScopeReport   string
+	AuditLog           string
+	Redact             bool
+	Tags               []string
+	ConfigFile         string
+	Profile            string
+	Settings           *configfile.Config
+	Remediation        *remediation.Catalog
+	TagRules           []classify.TagRule
+	TransformRules     []transform.Rule
+	Plugins            []*plugin.Plugin
+	KeepTrackingParams bool
+	ExtractURLs        bool
+	DecodeParams       bool
+	ParamsMode         string
+	ParseURLs          bool
+	MaxPerCategory     int
+	UniqueValues       bool
+	GroupBy            string
+	Xref               bool
+	XrefIndex          *xref.Index
+	Dedupe             dedupe.Options
+	NoColor            bool
+	Only               []finding.Type
+	All                bool
+	None               bool
+	Grep               []string
+	VGrep              []string
+	LineFilter         *grep.Filter
+	DedupeLines        int
+	LowMemory          bool
+	MaxLineLength      byteSize
+	Encoding           decode.Encoding
+	Strings            bool
+	APKPath            string
+	OpenAPIPath        string
+	PostmanPaths       []string
+	GraphQLPath        string
+	TrafficPath        string
+	Structured         bool
+	ExtractHandles     bool
+	UUIDDetect         bool
+	UUIDNames          string
+	UUIDNamespaces     []string
+	ExtractCrypto      bool
+	ExtractCloud       bool
+	ExtractSecrets     bool
+	Timestamps         bool
+	Usernames          bool
+	AvatarHashes       bool
+	AvatarCorrelate    bool
+	ExtractBuckets     bool
+	ExtractJSONP       bool
+	ExtractErrors      bool
+	ExtractComments    bool
+	CommentKeywords    []string
+	ExtractLocales     bool
+	ProbeS3            bool
+	S3Endpoint         string
+	Takeover           bool
+	SecurityHeaders    bool
+	CORS               bool
+	Export             string
+	Stats              bool
+	DryRun             bool
+	TimeoutRead        time.Duration
+	TimeoutExtract     time.Duration
+	TimeoutEnrich      time.Duration
+	CrawlDepth         int
+	CrawlConcurrency   int
+	CrawlDelay         time.Duration
+	UserAgent          string
+	Headers            []string
+	Proxy              string
+	Insecure           bool
+	Retries            int
+	HostDelay          time.Duration
+	MaxBody            byteSize
+	MaxRedirects       int
+	RequestTimeout     time.Duration
+	Rate               httpclient.Rate
+	RatePerHost        httpclient.Rate
+	RateLimiter        *httpclient.RateLimiter
+	IgnoreRobots       bool
+	CacheDir           string
+	WorkDir            string
+	CacheTTL           time.Duration
+	NoCache            bool
+	Enrich             bool
+	Probe              bool
+	OnlyAlive          bool
+	JSON               bool
 }
 
 func getProgramName() string {
-	name := filepath.Base(os.Args[0])
-	// Handle both temporary build paths and direct go run cases
-	if strings.HasPrefix(name, "/tmp/go-build") || name == "main" {
+	return programName(os.Args[0])
+}
+
+// programName returns the name of the binary at path without its .exe suffix. The
+// temporary binaries of go run and debuggers are shown as urlsluice: they are built in a
+// go-build directory under the temporary directory of any OS, or named main or __debug_bin.
+func programName(path string) string {
+	name := filepath.Base(path)
+	if ext := filepath.Ext(name); strings.EqualFold(ext, ".exe") {
+		name = strings.TrimSuffix(name, ext)
+	}
+	if name == "main" || strings.HasPrefix(name, "__debug_bin") || strings.Contains(filepath.ToSlash(filepath.Dir(path)), "/go-build") {
 		return "urlsluice"
 	}
 	return name
@@ -43,63 +189,389 @@ func getProgramName() string {
 // Move the help text generation to a separate function
 func generateHelpText(w io.Writer, progName string) {
 	fmt.Fprintf(w, "URL Sluice - Extract patterns from text files\n\n")
-	fmt.Fprintf(w, "Usage: %s [options]\n\n", progName)
+	fmt.Fprintf(w, "Usage: %s [command] [options]\n\n", progName)
+	fmt.Fprintf(w, "Commands:\n")
+	fmt.Fprintf(w, "  audit verify file...\n")
+	fmt.Fprintf(w, "        Check that -audit-log files are intact and report the number of entries\n")
+	fmt.Fprintf(w, "  config validate [-type config|monitor|redirect|tokens|xss] file...\n")
+	fmt.Fprintf(w, "        Check configuration files without running anything, parsing the flags of profiles and monitor jobs\n")
+	fmt.Fprintf(w, "  ct -domain string\n")
+	fmt.Fprintf(w, "        Add hostnames from Certificate Transparency logs to the domain results\n")
+	fmt.Fprintf(w, "  daemon -config file [-once]\n")
+	fmt.Fprintf(w, "        Run the jobs of a monitor configuration on cron schedules and notify webhooks of new findings\n")
+	fmt.Fprintf(w, "  extractors [-json]\n")
+	fmt.Fprintf(w, "        List every extractor with its category, flag and tuning options\n")
+	fmt.Fprintf(w, "  merge [-o file] document...\n")
+	fmt.Fprintf(w, "        Combine -json documents of earlier runs, deduplicating findings and recording when each was first seen\n")
+	fmt.Fprintf(w, "  query [-json] [-silent] document expression\n")
+	fmt.Fprintf(w, "        Print the findings of a -json document matching an expression, e.g. 'type==domain && value endswith \".dev\"'\n")
+	fmt.Fprintf(w, "  schema\n")
+	fmt.Fprintf(w, "        Print the JSON Schema of the -json output\n")
+	fmt.Fprintf(w, "  serve [-addr address] [-tokens file] [-workers n]\n")
+	fmt.Fprintf(w, "        Serve the extraction HTTP API, with background jobs polled or posted to webhooks\n")
+	fmt.Fprintf(w, "  suppress -type type [-expires date] [-comment text] [-append file] value...\n")
+	fmt.Fprintf(w, "        Print allowlist entries for -suppress-file that keep findings of the given type and values out of reports\n")
+	fmt.Fprintf(w, "  update [-check-only]\n")
+	fmt.Fprintf(w, "        Replace this binary with the latest verified GitHub release\n")
+	fmt.Fprintf(w, "  version [-json]\n")
+	fmt.Fprintf(w, "        Print the version, commit, build date and features of this binary\n\n")
 	fmt.Fprintf(w, "Options:\n")
-	fmt.Fprintf(w, "  -file string\n")
-	fmt.Fprintf(w, "        Path to the input file (required)\n")
+	fmt.Fprintf(w, "  -file value\n")
+	fmt.Fprintf(w, "        Path to an input file, or - for standard input (repeatable)\n")
+	fmt.Fprintf(w, "  -url value\n")
+	fmt.Fprintf(w, "        URL of a page to fetch and extract from (repeatable)\n")
+	fmt.Fprintf(w, "  -apk string\n")
+	fmt.Fprintf(w, "        Path to an Android APK or other zipped app bundle; findings are grouped by file inside the package\n")
+	fmt.Fprintf(w, "  -openapi string\n")
+	fmt.Fprintf(w, "        Path to an OpenAPI or Swagger document whose servers, endpoints and parameters are reported\n")
+	fmt.Fprintf(w, "  -postman value\n")
+	fmt.Fprintf(w, "        Path to a Postman collection, environment or globals export (repeatable)\n")
+	fmt.Fprintf(w, "  -graphql string\n")
+	fmt.Fprintf(w, "        Path to a GraphQL introspection result whose operation arguments and names are reported\n")
+	fmt.Fprintf(w, "  -traffic string\n")
+	fmt.Fprintf(w, "        Path to a HAR file or Burp Suite XML export whose response bodies are extracted from\n")
+	fmt.Fprintf(w, "  -encoding string\n")
+	fmt.Fprintf(w, "        Character encoding of the input: auto, utf-8, utf-16le, utf-16be, latin-1 or windows-1252 (default auto)\n")
+	fmt.Fprintf(w, "  -strings\n")
+	fmt.Fprintf(w, "        Extract from the printable strings of binary input instead of skipping it\n")
+	fmt.Fprintf(w, "  -grep value\n")
+	fmt.Fprintf(w, "        Only extract from input lines matching this regular expression (repeatable)\n")
+	fmt.Fprintf(w, "  -vgrep value\n")
+	fmt.Fprintf(w, "        Skip input lines matching this regular expression (repeatable)\n")
+	fmt.Fprintf(w, "  -max-line-length size\n")
+	fmt.Fprintf(w, "        Longest input line matched as a whole; longer lines, such as minified JavaScript, are matched in overlapping windows (default 1MB)\n")
+	fmt.Fprintf(w, "  -dedupe-lines int\n")
+	fmt.Fprintf(w, "        Skip input lines identical to one of the last N distinct lines, so repeated lines are extracted once (0 disables)\n")
+	fmt.Fprintf(w, "  -low-memory\n")
+	fmt.Fprintf(w, "        Use small buffers and a single worker, and stream text output deduplicated with a Bloom filter, for small machines such as a Raspberry Pi\n")
+	fmt.Fprintf(w, "  -config string\n")
+	fmt.Fprintf(w, "        Path to a YAML configuration file (tag rules, ...)\n")
+	fmt.Fprintf(w, "  -profile string\n")
+	fmt.Fprintf(w, "        Name of a profile in the -config file whose flags are applied before the command line ones\n")
 	fmt.Fprintf(w, "  -uuid int\n")
 	fmt.Fprintf(w, "        UUID version to extract (1-5) (default 4)\n")
+	fmt.Fprintf(w, "  -uuid-detect\n")
+	fmt.Fprintf(w, "        Extract UUIDs of every version and report the version distribution\n")
+	fmt.Fprintf(w, "  -uuid-names string\n")
+	fmt.Fprintf(w, "        Wordlist of candidate names used to recover the inputs of v3/v5 UUIDs\n")
+	fmt.Fprintf(w, "  -uuid-namespaces string\n")
+	fmt.Fprintf(w, "        Comma-separated namespaces (dns, url, oid, x500 or UUIDs) tried with -uuid-names (default dns,url,oid,x500)\n")
 	fmt.Fprintf(w, "  -emails\n")
 	fmt.Fprintf(w, "        Extract email addresses\n")
+	fmt.Fprintf(w, "  -emails-deobfuscate\n")
+	fmt.Fprintf(w, "        Also extract emails written as \"user [at] example [dot] com\" or with HTML entities (implies -emails)\n")
 	fmt.Fprintf(w, "  -domains\n")
 	fmt.Fprintf(w, "        Extract domain names\n")
 	fmt.Fprintf(w, "  -ips\n")
 	fmt.Fprintf(w, "        Extract IP addresses\n")
 	fmt.Fprintf(w, "  -queryParams\n")
 	fmt.Fprintf(w, "        Extract query parameters\n")
+	fmt.Fprintf(w, "  -params-mode string\n")
+	fmt.Fprintf(w, "        Report parameters as key=value pairs, or only their unique keys or values (pairs, keys or values) (default \"pairs\")\n")
+	fmt.Fprintf(w, "  -decode-params\n")
+	fmt.Fprintf(w, "        Percent-decode parameter keys and values, e.g. \"q=hello world\" (implies -queryParams)\n")
+	fmt.Fprintf(w, "  -urls\n")
+	fmt.Fprintf(w, "        Extract absolute HTTP(S) URLs\n")
+	fmt.Fprintf(w, "  -parse-urls\n")
+	fmt.Fprintf(w, "        Parse lines that hold a single URL with a URL parser instead of regexes\n")
+	fmt.Fprintf(w, "  -structured\n")
+	fmt.Fprintf(w, "        Walk YAML and JSON inputs such as Kubernetes manifests value by value, reporting the path of each finding\n")
+	fmt.Fprintf(w, "  -handles\n")
+	fmt.Fprintf(w, "        Extract GitHub, GitLab, Twitter/X, LinkedIn and Discord handles\n")
+	fmt.Fprintf(w, "  -crypto\n")
+	fmt.Fprintf(w, "        Extract Bitcoin, Ethereum and Monero addresses\n")
+	fmt.Fprintf(w, "  -cloud-config\n")
+	fmt.Fprintf(w, "        Extract keys from embedded Firebase, Google Maps, Sentry, Segment and Amplitude configs\n")
+	fmt.Fprintf(w, "  -config-secrets\n")
+	fmt.Fprintf(w, "        Extract PASSWORD, SECRET, TOKEN and DSN style assignments from dotenv, ini and YAML files\n")
+	fmt.Fprintf(w, "  -timestamps\n")
+	fmt.Fprintf(w, "        Decode creation times embedded in UUIDs, ULIDs, snowflake IDs and epoch parameters\n")
+	fmt.Fprintf(w, "  -usernames\n")
+	fmt.Fprintf(w, "        Derive usernames from email local parts, profile URL paths and author metadata, most frequent first\n")
+	fmt.Fprintf(w, "  -avatar-hashes\n")
+	fmt.Fprintf(w, "        Extract the email hashes of Gravatar and Libravatar URLs and avatar fields\n")
+	fmt.Fprintf(w, "  -avatar-correlate\n")
+	fmt.Fprintf(w, "        Match avatar hashes against the MD5 and SHA-256 of the extracted emails (implies -avatar-hashes and -emails)\n")
+	fmt.Fprintf(w, "  -jsonp\n")
+	fmt.Fprintf(w, "        Report URLs passing a function name in a callback parameter as potential JSONP endpoints\n")
+	fmt.Fprintf(w, "  -errors\n")
+	fmt.Fprintf(w, "        Report stack traces and verbose error pages of ASP.NET, Django, PHP and other frameworks\n")
+	fmt.Fprintf(w, "  -comments\n")
+	fmt.Fprintf(w, "        Extract HTML comments and JavaScript block comments\n")
+	fmt.Fprintf(w, "  -comment-keywords string\n")
+	fmt.Fprintf(w, "        Comma-separated list of words; only comments containing one of them are reported (e.g. TODO,FIXME,password) (implies -comments)\n")
+	fmt.Fprintf(w, "  -locales\n")
+	fmt.Fprintf(w, "        Report locale paths such as /en-us/ and i18n translation files of URLs\n")
+	fmt.Fprintf(w, "  -buckets\n")
+	fmt.Fprintf(w, "        Extract S3, Google Cloud Storage and Azure Blob bucket names from endpoints, URIs and ARNs\n")
+	fmt.Fprintf(w, "  -security-headers\n")
+	fmt.Fprintf(w, "        Report CSP hosts as domains and weak CSP, CORS and HSTS headers of -traffic and crawled responses\n")
+	fmt.Fprintf(w, "  -cors\n")
+	fmt.Fprintf(w, "        Report -traffic responses that echo the request Origin or allow any origin with credentials as CORS misconfigurations\n")
+	fmt.Fprintf(w, "  -export string\n")
+	fmt.Fprintf(w, "        Write test candidates derived from the findings instead of the findings (idor)\n")
+	fmt.Fprintf(w, "  -timeout-read duration\n")
+	fmt.Fprintf(w, "        Maximum time for reading the input, including CT lookups (0 means no limit)\n")
+	fmt.Fprintf(w, "  -timeout-extract duration\n")
+	fmt.Fprintf(w, "        Maximum time for extracting findings from the input (0 means no limit)\n")
+	fmt.Fprintf(w, "  -timeout-enrich duration\n")
+	fmt.Fprintf(w, "        Maximum time for -enrich, -probe, -probe-s3 and -takeover; findings not checked in time are reported unchecked\n")
+	fmt.Fprintf(w, "  -dry-run\n")
+	fmt.Fprintf(w, "        Print the inputs, extractors, filters and outputs a run would use without reading any input\n")
+	fmt.Fprintf(w, "  -stats\n")
+	fmt.Fprintf(w, "        Write per-stage timings and counts to stderr\n")
+	fmt.Fprintf(w, "  -max-per-category int\n")
+	fmt.Fprintf(w, "        Report at most this many findings of each type (0 means no limit)\n")
+	fmt.Fprintf(w, "  -dedupe-case string\n")
+	fmt.Fprintf(w, "        Comma-separated categories whose values are deduplicated ignoring case, e.g. emails,domains\n")
+	fmt.Fprintf(w, "  -dedupe-params string\n")
+	fmt.Fprintf(w, "        Deduplicate parameters by key and value (full) or by key alone (keys-only) (default full)\n")
+	fmt.Fprintf(w, "  -dedupe-trailing-slash\n")
+	fmt.Fprintf(w, "        Treat URLs that differ only by a trailing slash in the path as duplicates\n")
+	fmt.Fprintf(w, "  -keep-tracking-params\n")
+	fmt.Fprintf(w, "        Keep utm_*, gclid and other tracking parameters in URLs and report them with the other parameters\n")
+	fmt.Fprintf(w, "  -dedupe-locale\n")
+	fmt.Fprintf(w, "        Treat URLs that differ only by a locale path segment such as /en-us/ or /fr/ as duplicates\n")
+	fmt.Fprintf(w, "  -unique-values\n")
+	fmt.Fprintf(w, "        Report each distinct value once with the categories it was found in\n")
+	fmt.Fprintf(w, "  -group-by string\n")
+	fmt.Fprintf(w, "        Group the report by host, listing the parameters, paths, secrets and redirect candidates of each host together\n")
+	fmt.Fprintf(w, "  -xref\n")
+	fmt.Fprintf(w, "        Cross-reference findings: domains in parameter values and, with -scope-file, out-of-scope email domains\n")
+	fmt.Fprintf(w, "  -json\n")
+	fmt.Fprintf(w, "        Write findings as a JSON document\n")
+	fmt.Fprintf(w, "  -only string\n")
+	fmt.Fprintf(w, "        Comma-separated categories to extract and report, e.g. emails or domains,ips\n")
+	fmt.Fprintf(w, "  -all\n")
+	fmt.Fprintf(w, "        Enable every extractor that needs no network access or extra input\n")
+	fmt.Fprintf(w, "  -none\n")
+	fmt.Fprintf(w, "        Disable the extractors enabled by default, so only the ones named by -only or extractor flags run\n")
+	fmt.Fprintf(w, "  -no-color\n")
+	fmt.Fprintf(w, "        Disable colored output (colors are only used on terminals; NO_COLOR is honored)\n")
 	fmt.Fprintf(w, "  -silent\n")
 	fmt.Fprintf(w, "        Output data without titles\n")
 	fmt.Fprintf(w, "  -wordlist\n")
 	fmt.Fprintf(w, "        Generate a wordlist from URLs in file\n")
+	fmt.Fprintf(w, "  -wordlist-lang string\n")
+	fmt.Fprintf(w, "        Language whose stopwords are dropped from -wordlist output: auto, none or %s (default \"auto\")\n", strings.Join(wordlist.Languages(), ", "))
+	fmt.Fprintf(w, "  -wl-ngrams int\n")
+	fmt.Fprintf(w, "        Also add runs of 2 up to this many adjacent path tokens to -wordlist output, joined by - and _ (e.g. admin-panel)\n")
+	fmt.Fprintf(w, "  -wl-mutate\n")
+	fmt.Fprintf(w, "        Also add every -wordlist word with environment and backup prefixes and suffixes (dev-, -staging, .bak, _old, ...)\n")
+	fmt.Fprintf(w, "  -wl-years string\n")
+	fmt.Fprintf(w, "        Keep the years and dates of -wordlist words, strip them, or replace them with each year of a range such as 2019-2025 (default \"keep\")\n")
+	fmt.Fprintf(w, "  -wl-subtract string\n")
+	fmt.Fprintf(w, "        Leave the words of this generic wordlist, such as a SecLists file, out of -wordlist output (repeatable)\n")
 	fmt.Fprintf(w, "  -detect-redirects\n")
 	fmt.Fprintf(w, "        Detect potential open redirects\n")
 	fmt.Fprintf(w, "  -redirect-config string\n")
-	fmt.Fprintf(w, "        Path to redirect detection configuration file\n\n")
+	fmt.Fprintf(w, "        Path to redirect detection configuration file\n")
+	fmt.Fprintf(w, "  -detect-xss-params\n")
+	fmt.Fprintf(w, "        Detect parameters with markup in their values or named after common reflection sinks\n")
+	fmt.Fprintf(w, "  -xss-config string\n")
+	fmt.Fprintf(w, "        Path to XSS parameter detection configuration file\n")
+	fmt.Fprintf(w, "  -entropy-min float\n")
+	fmt.Fprintf(w, "        Report random-looking tokens with at least this Shannon entropy (e.g. 4.0)\n")
+	fmt.Fprintf(w, "  -tlds string\n")
+	fmt.Fprintf(w, "        Comma-separated list of TLDs to keep in domain results (e.g. com,net,io)\n")
+	fmt.Fprintf(w, "  -exclude-tlds string\n")
+	fmt.Fprintf(w, "        Comma-separated list of TLDs to drop from domain results (e.g. local,test)\n")
+	fmt.Fprintf(w, "  -include-reserved\n")
+	fmt.Fprintf(w, "        Keep RFC 2606 reserved domains such as example.com in domain results\n")
+	fmt.Fprintf(w, "  -only-internal\n")
+	fmt.Fprintf(w, "        Only report internal hosts and URLs (private IPs, .local, .corp, intranet names, ...)\n")
+	fmt.Fprintf(w, "  -internal-section\n")
+	fmt.Fprintf(w, "        List internal domains and IPs in their own Internal Hosts section of the text output\n")
+	fmt.Fprintf(w, "  -scope-file string\n")
+	fmt.Fprintf(w, "        File of in-scope hosts, *.wildcards and CIDRs; everything else is dropped\n")
+	fmt.Fprintf(w, "  -out-of-scope-report string\n")
+	fmt.Fprintf(w, "        With -scope-file, write the findings dropped as out of scope to this file\n")
+	fmt.Fprintf(w, "  -audit-log string\n")
+	fmt.Fprintf(w, "        Append every reported finding with the run ID and time to this NDJSON log, chained so edits are detected\n")
+	fmt.Fprintf(w, "  -redact\n")
+	fmt.Fprintf(w, "        Mask secrets, email addresses and card numbers in the report, keeping a hash of each to match the unredacted output\n")
+	fmt.Fprintf(w, "  -suppress-file string\n")
+	fmt.Fprintf(w, "        Allowlist of accepted findings, generated with \"urlsluice suppress\", kept out of the report\n")
+	fmt.Fprintf(w, "  -suppress-key string\n")
+	fmt.Fprintf(w, "        Base64 Ed25519 public key that must have signed the -suppress-file, in the file named after it with .sig appended\n")
+	fmt.Fprintf(w, "  -tag string\n")
+	fmt.Fprintf(w, "        Comma-separated list of tags; only findings carrying one of them are reported\n")
+	fmt.Fprintf(w, "  -crawl-depth int\n")
+	fmt.Fprintf(w, "        Fetch discovered in-scope URLs and extract from their bodies, up to this many levels\n")
+	fmt.Fprintf(w, "  -crawl-concurrency int\n")
+	fmt.Fprintf(w, "        Maximum number of parallel requests while crawling or enriching (default 4)\n")
+	fmt.Fprintf(w, "  -crawl-delay duration\n")
+	fmt.Fprintf(w, "        Minimum delay between crawl requests (default 500ms)\n")
+	fmt.Fprintf(w, "  -enrich\n")
+	fmt.Fprintf(w, "        Fetch / of each reported domain and record status, title, server and favicon hash\n")
+	fmt.Fprintf(w, "  -probe\n")
+	fmt.Fprintf(w, "        Check every reported URL and record its status code and content length\n")
+	fmt.Fprintf(w, "  -probe-s3\n")
+	fmt.Fprintf(w, "        Check whether extracted S3 buckets can be listed or their ACL read anonymously (implies -buckets)\n")
+	fmt.Fprintf(w, "  -s3-endpoint string\n")
+	fmt.Fprintf(w, "        Base URL of an S3 compatible API for -probe-s3 (default AWS)\n")
+	fmt.Fprintf(w, "  -takeover\n")
+	fmt.Fprintf(w, "        Report dangling CNAMEs and storage buckets that do not exist as takeover candidates (implies -domains and -buckets)\n")
+	fmt.Fprintf(w, "  -only-alive\n")
+	fmt.Fprintf(w, "        With -probe, drop URLs that are unreachable or return errors\n")
+	fmt.Fprintf(w, "  -user-agent string\n")
+	fmt.Fprintf(w, "        User-Agent sent with HTTP requests (default \"urlsluice\")\n")
+	fmt.Fprintf(w, "  -H value\n")
+	fmt.Fprintf(w, "        Custom \"Name: value\" header sent with HTTP requests (repeatable)\n")
+	fmt.Fprintf(w, "  -proxy string\n")
+	fmt.Fprintf(w, "        HTTP or SOCKS5 proxy URL for HTTP requests (default from HTTP_PROXY/HTTPS_PROXY)\n")
+	fmt.Fprintf(w, "  -insecure\n")
+	fmt.Fprintf(w, "        Skip TLS certificate verification, e.g. behind an intercepting proxy\n")
+	fmt.Fprintf(w, "  -retries int\n")
+	fmt.Fprintf(w, "        Number of retries for failed HTTP requests (default 2)\n")
+	fmt.Fprintf(w, "  -host-delay duration\n")
+	fmt.Fprintf(w, "        Minimum delay between HTTP requests to the same host\n")
+	fmt.Fprintf(w, "  -max-body size\n")
+	fmt.Fprintf(w, "        Maximum size of an HTTP response body; longer bodies are cut off (default 5MB, 0 means no limit)\n")
+	fmt.Fprintf(w, "  -max-redirects int\n")
+	fmt.Fprintf(w, "        Maximum number of redirects followed per HTTP request; 0 does not follow redirects (default 10)\n")
+	fmt.Fprintf(w, "  -request-timeout duration\n")
+	fmt.Fprintf(w, "        Maximum time for each HTTP request attempt, including reading the body (default 10s)\n")
+	fmt.Fprintf(w, "  -rate string\n")
+	fmt.Fprintf(w, "        Maximum rate of HTTP requests across all hosts, e.g. 10/s or 100/m\n")
+	fmt.Fprintf(w, "  -rate-per-host string\n")
+	fmt.Fprintf(w, "        Maximum rate of HTTP requests to each host, e.g. 2/s\n")
+	fmt.Fprintf(w, "  -ignore-robots\n")
+	fmt.Fprintf(w, "        Fetch URLs even when robots.txt disallows them\n")
+	fmt.Fprintf(w, "  -cache-dir string\n")
+	fmt.Fprintf(w, "        Directory caching DNS, probe, enrichment and CT results between runs (default the user cache directory)\n")
+	fmt.Fprintf(w, "  -cache-ttl duration\n")
+	fmt.Fprintf(w, "        How long cached network results are used (default 24h)\n")
+	fmt.Fprintf(w, "  -no-cache\n")
+	fmt.Fprintf(w, "        Neither read nor write the network cache\n")
+	fmt.Fprintf(w, "  -workdir string\n")
+	fmt.Fprintf(w, "        Directory for temporary files, removed when the run ends or is interrupted (default the system temporary directory)\n")
+	fmt.Fprintf(w, "  -min-confidence string\n")
+	fmt.Fprintf(w, "        Minimum confidence of reported findings (low, medium, high) (default low)\n\n")
 	fmt.Fprintf(w, "Examples:\n")
 	fmt.Fprintf(w, "  Extract all patterns:\n")
 	fmt.Fprintf(w, "    %s -file input.txt -emails -domains -ips -queryParams\n\n", progName)
 	fmt.Fprintf(w, "  Extract only domains and IPs in silent mode:\n")
 	fmt.Fprintf(w, "    %s -file input.txt -domains -ips -silent\n\n", progName)
 	fmt.Fprintf(w, "  Extract specific UUID version:\n")
-	fmt.Fprintf(w, "    %s -file input.txt -uuid 4\n", progName)
+	fmt.Fprintf(w, "    %s -file input.txt -uuid 4\n\n", progName)
+	fmt.Fprintf(w, "  Merge CT log hostnames with domains from a file:\n")
+	fmt.Fprintf(w, "    %s ct -domain example.com -file input.txt -domains\n", progName)
 }
 
 func main() {
-	ctx := context.Background()
+	// An interrupt cancels the run so its temporary files are removed before exiting; a
+	// second one exits at once
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
 
-	if err := run(ctx); err != nil {
+	err := run(ctx)
+	interrupted := ctx.Err() != nil
+	stop()
+	if cleanupErr := tempdir.Cleanup(); cleanupErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: error removing temporary files: %v\n", cleanupErr)
+	}
+	if interrupted {
+		fmt.Fprintln(os.Stderr, "Interrupted")
+		os.Exit(130)
+	}
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
+// commands maps subcommand names to their entry points; anything else runs the default extraction
+var commands = map[string]func(ctx context.Context, args []string) error{
+	"config":     runConfig,
+	"audit":      runAudit,
+	"ct":         runCT,
+	"daemon":     runDaemon,
+	"extractors": runExtractors,
+	"merge":      runMerge,
+	"query":      runQuery,
+	"schema":     runSchema,
+	"serve":      runServe,
+	"suppress":   runSuppress,
+	"update":     runUpdate,
+	"version":    runVersion,
+}
+
 func run(ctx context.Context) error {
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			return command(ctx, os.Args[2:])
+		}
+	}
+
 	// Parse flags
 	config, err := parseFlags()
 	if err != nil {
 		return fmt.Errorf("error parsing flags: %w", err)
 	}
+	for _, w := range patternWarnings(config) {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+	}
+	if config.DryRun {
+		return writePlan(os.Stdout, config, planInputs(config))
+	}
+	runInfo := newRun(config)
+
+	if !config.GenerateWordlist && !config.DetectRedirects && !config.DetectXSSParams {
+		return process(ctx, config, runInfo, inputSource(config, runInfo))
+	}
 
-	// Open and read input file
-	data, err := os.ReadFile(config.FilePath)
+	var urls []string
+	err = textSource(config, runInfo)(ctx, func(b pipeline.Batch) error {
+		data := b.Data
+		if !b.Decoded {
+			data = decode.Convert(data, config.Encoding)
+		}
+		urls = append(urls, inScopeLines(config, config.LineFilter.Lines(strings.Split(string(data), "\n")))...)
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("error reading file: %w", err)
+		return err
 	}
 
 	// Handle wordlist generation
 	if config.GenerateWordlist {
-		urls := strings.Split(string(data), "\n")
-		tokens := wordlist.GenerateWordlist(urls)
+		if config.OpenAPIPath != "" {
+			spec, err := readOpenAPI(config, runInfo)
+			if err != nil {
+				return err
+			}
+			urls = append(urls, inScopeLines(config, openAPIWordlistURLs(spec))...)
+		}
+		// Validated when the flags were parsed
+		years, _ := wordlist.ParseYears(config.WordlistYears)
+		opts := wordlist.Options{Language: config.WordlistLang, NGrams: config.WordlistNGrams, Years: years}
+		if config.WordlistMutate {
+			mutations := wordlistMutations(config)
+			opts.Mutations = &mutations
+		}
+		tokens, lang := wordlist.Generate(urls, opts)
+		if config.WordlistLang == wordlist.LanguageAuto && !config.Silent {
+			// Report the detected language on stderr, keeping stdout to the words
+			fmt.Fprintf(os.Stderr, "Wordlist language: %s (override with -wordlist-lang)\n", lang)
+		}
+		if config.GraphQLPath != "" {
+			schema, err := readGraphQL(config, runInfo)
+			if err != nil {
+				return err
+			}
+			tokens = wordlist.Merge(tokens, wordlist.GenerateFromNames(schema.Names()))
+		}
+		for _, path := range config.WordlistSubtract {
+			common, err := readWordlist(path)
+			if err != nil {
+				return fmt.Errorf("error reading -wl-subtract wordlist: %w", err)
+			}
+			tokens = wordlist.Subtract(tokens, common)
+		}
 		for _, token := range tokens {
 			fmt.Println(token)
 		}
@@ -113,127 +585,619 @@ func run(ctx context.Context) error {
 			return fmt.Errorf("error creating redirect detector: %w", err)
 		}
 
-		urls := strings.Split(string(data), "\n")
 		results := detector.ScanURLs(urls)
 
-		if !config.Silent {
-			fmt.Println("\nPotential Open Redirects:")
+		write := output.WriteRedirects
+		if config.GroupBy == groupByHost {
+			write = output.WriteRedirectsByHost
+		}
+		if err := write(os.Stdout, results, textOptions(config)); err != nil {
+			return err
+		}
+		if guidance, ok := remediationCatalog(config).Lookup(remediation.OpenRedirect); ok && anyVulnerable(results) {
+			if err := output.WriteRemediation(os.Stdout, guidance, textOptions(config)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if config.DetectXSSParams {
+		detector, err := xss.NewXSSDetector(config.XSSConfig)
+		if err != nil {
+			return fmt.Errorf("error creating XSS parameter detector: %w", err)
 		}
 
-		for _, result := range results {
-			if result.IsVulnerable {
-				fmt.Println(result.URL)
-				if !config.Silent {
-					for _, param := range result.MatchedParams {
-						fmt.Printf("  Parameter: %s = %s (Known: %v)\n",
-							param.Name, param.Value, param.IsKnown)
-					}
-					fmt.Println()
-				}
+		results := detector.ScanURLs(urls)
+
+		return output.WriteXSSParams(os.Stdout, results, textOptions(config))
+	}
+
+	return nil
+}
+
+// anyVulnerable reports whether a potential open redirect was found
+func anyVulnerable(results []redirect.RedirectResult) bool {
+	for _, result := range results {
+		if result.IsVulnerable {
+			return true
+		}
+	}
+	return false
+}
+
+// groupByHost is the -group-by mode listing the findings of each host together
+const groupByHost = "host"
+
+// remediationCatalog returns the remediation guidance of the -config file, or the built-in
+// guidance without one
+func remediationCatalog(config *Config) *remediation.Catalog {
+	if config.Remediation != nil {
+		return config.Remediation
+	}
+	return remediation.Default()
+}
+
+// wordlistMutations returns the -wl-mutate prefixes and suffixes, with the lists set in the
+// -config file replacing the built-in ones
+func wordlistMutations(config *Config) wordlist.Mutations {
+	if config.Settings != nil {
+		return wordlist.DefaultMutations().With(config.Settings.Mutations())
+	}
+	return wordlist.DefaultMutations()
+}
+
+func printResults(results extractor.Results, opts output.TextOptions) error {
+	return output.WriteTextWith(os.Stdout, results.Findings, opts)
+}
+
+// textOptions returns the text output settings; colors are used on terminals unless -no-color is set
+func textOptions(config *Config) output.TextOptions {
+	return output.TextOptions{
+		Silent:          config.Silent,
+		Colors:          output.Palette{Enabled: !config.NoColor && output.ColorTerminal(os.Stdout)},
+		InternalSection: config.InternalSection,
+	}
+}
+
+// patternWarnings returns the problems regexlint finds in the -grep and -vgrep patterns and
+// in those of the -config file, so they can be fixed before a long run
+func patternWarnings(config *Config) []string {
+	var warnings []string
+	for _, f := range []struct {
+		name     string
+		patterns []string
+	}{{"grep", config.Grep}, {"vgrep", config.VGrep}} {
+		for _, p := range f.patterns {
+			for _, w := range regexlint.Check(p, regexlint.Line) {
+				warnings = append(warnings, fmt.Sprintf("-%s %q %s", f.name, p, w))
 			}
 		}
-		return nil
 	}
+	if config.Settings != nil {
+		for _, w := range config.Settings.PatternWarnings() {
+			warnings = append(warnings, fmt.Sprintf("%s: %s", config.ConfigFile, w))
+		}
+	}
+	return warnings
+}
 
-	// Create extractor for pattern extraction
-	ext, err := extractor.New(extractor.Config{
-		UUIDVersion:    config.UUIDVersion,
-		ExtractEmails:  config.ExtractEmails,
-		ExtractDomains: config.ExtractDomains,
-		ExtractIPs:     config.ExtractIPs,
-		ExtractParams:  config.ExtractParams,
-	})
+func parseFlags() (*Config, error) {
+	config, err := parseFlagSet(flag.CommandLine, os.Args[1:])
 	if err != nil {
-		return fmt.Errorf("error creating extractor: %w", err)
+		return nil, err
+	}
+
+	if len(config.FilePaths) == 0 && len(config.URLs) == 0 && config.APKPath == "" && config.OpenAPIPath == "" && len(config.PostmanPaths) == 0 && config.GraphQLPath == "" && config.TrafficPath == "" {
+		return nil, fmt.Errorf("file path is required")
+	}
+	if len(config.FilePaths) == 0 && len(config.URLs) == 0 && config.DetectRedirects {
+		return nil, fmt.Errorf("-detect-redirects reads URL lines from -file or -url")
+	}
+	if len(config.FilePaths) == 0 && len(config.URLs) == 0 && config.DetectXSSParams {
+		return nil, fmt.Errorf("-detect-xss-params reads URL lines from -file or -url")
+	}
+	if len(config.FilePaths) == 0 && len(config.URLs) == 0 && config.OpenAPIPath == "" && config.GraphQLPath == "" && config.GenerateWordlist {
+		return nil, fmt.Errorf("-wordlist reads URL lines from -file, -url, -openapi or -graphql")
+	}
+	if config.SecurityHeaders && config.TrafficPath == "" && config.CrawlDepth == 0 {
+		return nil, fmt.Errorf("-security-headers analyses the responses of -traffic or -crawl-depth")
+	}
+	if config.CORS && config.TrafficPath == "" {
+		return nil, fmt.Errorf("-cors analyses the requests and responses of -traffic")
 	}
 
-	// Process file
-	results, err := ext.Extract(ctx, bytes.NewReader(data))
+	return config, nil
+}
+
+// parseFlagSet registers the extraction, network and filter flags on fs and parses args.
+// Subcommands use it so every mode shares the same options and output pipeline.
+func parseFlagSet(fs *flag.FlagSet, args []string) (*Config, error) {
+	config := &Config{}
+
+	fs.Var((*stringList)(&config.FilePaths), "file", "Path to an input file, or - for standard input (repeatable)")
+	fs.Var((*stringList)(&config.URLs), "url", "URL of a page to fetch and extract from (repeatable)")
+	fs.StringVar(&config.APKPath, "apk", "", "Path to an Android APK or other zipped app bundle to extract from")
+	fs.StringVar(&config.OpenAPIPath, "openapi", "", "Path to an OpenAPI or Swagger document whose servers, endpoints and parameters are reported")
+	fs.Var((*stringList)(&config.PostmanPaths), "postman", "Path to a Postman collection, environment or globals export (repeatable)")
+	fs.StringVar(&config.GraphQLPath, "graphql", "", "Path to a GraphQL introspection result whose operation arguments and names are reported")
+	fs.StringVar(&config.TrafficPath, "traffic", "", "Path to a HAR file or Burp Suite XML export whose response bodies are extracted from")
+	encoding := fs.String("encoding", string(decode.Auto), "Character encoding of the input: auto, utf-8, utf-16le, utf-16be, latin-1 or windows-1252")
+	fs.BoolVar(&config.Strings, "strings", false, "Extract from the printable strings of binary input instead of skipping it")
+	fs.Var((*stringList)(&config.Grep), "grep", "Only extract from input lines matching this regular expression (repeatable)")
+	fs.Var((*stringList)(&config.VGrep), "vgrep", "Skip input lines matching this regular expression (repeatable)")
+	config.MaxLineLength = extractor.DefaultMaxLineLength
+	fs.Var(&config.MaxLineLength, "max-line-length", "Longest input line matched as a whole; longer lines, such as minified JavaScript, are matched in overlapping windows")
+	fs.IntVar(&config.DedupeLines, "dedupe-lines", 0, "Skip input lines identical to one of the last N distinct lines, so repeated lines are extracted once (0 disables)")
+	fs.BoolVar(&config.LowMemory, "low-memory", false, "Use small buffers and a single worker, and stream text output deduplicated with a Bloom filter, for small machines such as a Raspberry Pi")
+	fs.StringVar(&config.ConfigFile, "config", "", "Path to a YAML configuration file (tag rules, ...)")
+	fs.StringVar(&config.Profile, "profile", "", "Name of a profile in the -config file whose flags are applied before the command line ones")
+	fs.IntVar(&config.UUIDVersion, "uuid", 4, "UUID version to extract (1-5)")
+	fs.BoolVar(&config.UUIDDetect, "uuid-detect", false, "Extract UUIDs of every version and report the version distribution")
+	fs.StringVar(&config.UUIDNames, "uuid-names", "", "Wordlist of candidate names used to recover the inputs of v3/v5 UUIDs")
+	uuidNamespaces := fs.String("uuid-namespaces", "", "Comma-separated namespaces (dns, url, oid, x500 or UUIDs) tried with -uuid-names (default dns,url,oid,x500)")
+	fs.BoolVar(&config.ExtractEmails, "emails", false, "Extract email addresses")
+	fs.BoolVar(&config.DeobfuscateEmails, "emails-deobfuscate", false, "Also extract emails written as \"user [at] example [dot] com\" or with HTML entities (implies -emails)")
+	fs.BoolVar(&config.ExtractDomains, "domains", false, "Extract domain names")
+	fs.BoolVar(&config.ExtractIPs, "ips", false, "Extract IP addresses")
+	fs.BoolVar(&config.ExtractParams, "queryParams", false, "Extract query parameters")
+	fs.StringVar(&config.ParamsMode, "params-mode", paramsPairs, "Report parameters as key=value pairs, or only their unique keys or values (pairs, keys or values)")
+	fs.BoolVar(&config.DecodeParams, "decode-params", false, "Percent-decode parameter keys and values, e.g. \"q=hello world\" (implies -queryParams)")
+	fs.BoolVar(&config.ExtractURLs, "urls", false, "Extract absolute HTTP(S) URLs")
+	fs.BoolVar(&config.ParseURLs, "parse-urls", false, "Parse lines that hold a single URL with a URL parser instead of regexes")
+	fs.BoolVar(&config.Structured, "structured", false, "Walk YAML and JSON inputs value by value, reporting the path of each finding")
+	fs.BoolVar(&config.ExtractHandles, "handles", false, "Extract GitHub, GitLab, Twitter/X, LinkedIn and Discord handles")
+	fs.BoolVar(&config.ExtractCrypto, "crypto", false, "Extract Bitcoin, Ethereum and Monero addresses")
+	fs.BoolVar(&config.ExtractCloud, "cloud-config", false, "Extract keys from embedded Firebase, Google Maps, Sentry, Segment and Amplitude configs")
+	fs.BoolVar(&config.ExtractSecrets, "config-secrets", false, "Extract PASSWORD, SECRET, TOKEN and DSN style assignments from dotenv, ini and YAML files")
+	fs.BoolVar(&config.Timestamps, "timestamps", false, "Decode creation times embedded in UUIDs, ULIDs, snowflake IDs and epoch parameters")
+	fs.BoolVar(&config.Usernames, "usernames", false, "Derive usernames from email local parts, profile URL paths and author metadata, most frequent first")
+	fs.BoolVar(&config.AvatarHashes, "avatar-hashes", false, "Extract the email hashes of Gravatar and Libravatar URLs and avatar fields")
+	fs.BoolVar(&config.AvatarCorrelate, "avatar-correlate", false, "Match avatar hashes against the MD5 and SHA-256 of the extracted emails (implies -avatar-hashes and -emails)")
+	fs.BoolVar(&config.ExtractJSONP, "jsonp", false, "Report URLs passing a function name in a callback parameter as potential JSONP endpoints")
+	fs.BoolVar(&config.ExtractErrors, "errors", false, "Report stack traces and verbose error pages of ASP.NET, Django, PHP and other frameworks")
+	fs.BoolVar(&config.ExtractComments, "comments", false, "Extract HTML comments and JavaScript block comments")
+	fs.BoolVar(&config.ExtractLocales, "locales", false, "Report locale paths such as /en-us/ and i18n translation files of URLs")
+	commentKeywords := fs.String("comment-keywords", "", "Comma-separated list of words; only comments containing one of them are reported (e.g. TODO,FIXME,password) (implies -comments)")
+	fs.BoolVar(&config.ExtractBuckets, "buckets", false, "Extract S3, Google Cloud Storage and Azure Blob bucket names from endpoints, URIs and ARNs")
+	fs.BoolVar(&config.SecurityHeaders, "security-headers", false, "Report CSP hosts as domains and weak CSP, CORS and HSTS headers of -traffic and crawled responses")
+	fs.BoolVar(&config.CORS, "cors", false, "Report -traffic responses that echo the request Origin or allow any origin with credentials as CORS misconfigurations")
+	fs.StringVar(&config.Export, "export", "", "Write test candidates derived from the findings instead of the findings (idor)")
+	fs.DurationVar(&config.TimeoutRead, "timeout-read", 0, "Maximum time for reading the input, including CT lookups (0 means no limit)")
+	fs.DurationVar(&config.TimeoutExtract, "timeout-extract", 0, "Maximum time for extracting findings from the input (0 means no limit)")
+	fs.DurationVar(&config.TimeoutEnrich, "timeout-enrich", 0, "Maximum time for -enrich, -probe, -probe-s3 and -takeover; findings not checked in time are reported unchecked")
+	fs.BoolVar(&config.DryRun, "dry-run", false, "Print the inputs, extractors, filters and outputs a run would use without reading any input")
+	fs.BoolVar(&config.Stats, "stats", false, "Write per-stage timings and counts to stderr")
+	fs.IntVar(&config.MaxPerCategory, "max-per-category", 0, "Report at most this many findings of each type (0 means no limit)")
+	dedupeCase := fs.String("dedupe-case", "", "Comma-separated categories whose values are deduplicated ignoring case, e.g. emails,domains")
+	dedupeParams := fs.String("dedupe-params", dedupe.ParamsFull, "Deduplicate parameters by key and value (full) or by key alone (keys-only)")
+	fs.BoolVar(&config.Dedupe.IgnoreTrailingSlash, "dedupe-trailing-slash", false, "Treat URLs that differ only by a trailing slash in the path as duplicates")
+	fs.BoolVar(&config.KeepTrackingParams, "keep-tracking-params", false, "Keep utm_*, gclid and other tracking parameters in URLs and report them with the other parameters")
+	fs.BoolVar(&config.Dedupe.IgnoreLocale, "dedupe-locale", false, "Treat URLs that differ only by a locale path segment such as /en-us/ or /fr/ as duplicates")
+	fs.BoolVar(&config.UniqueValues, "unique-values", false, "Report each distinct value once with the categories it was found in")
+	fs.StringVar(&config.GroupBy, "group-by", "", "Group the report by host, listing the parameters, paths, secrets and redirect candidates of each host together")
+	fs.BoolVar(&config.Xref, "xref", false, "Cross-reference findings: domains in parameter values and, with -scope-file, out-of-scope email domains")
+	fs.BoolVar(&config.JSON, "json", false, "Write findings as a JSON document")
+	fs.BoolVar(&config.Silent, "silent", false, "Output data without titles")
+	only := fs.String("only", "", "Comma-separated categories to extract and report, e.g. emails or domains,ips")
+	fs.BoolVar(&config.All, "all", false, "Enable every extractor that needs no network access or extra input")
+	fs.BoolVar(&config.None, "none", false, "Disable the extractors enabled by default, so only the ones named by -only or extractor flags run")
+	fs.BoolVar(&config.NoColor, "no-color", false, "Disable colored output (colors are only used on terminals; NO_COLOR is honored)")
+	fs.BoolVar(&config.GenerateWordlist, "wordlist", false, "Generate a wordlist from URLs in file")
+	fs.IntVar(&config.WordlistNGrams, "wl-ngrams", 0, "Also add runs of 2 up to this many adjacent path tokens to -wordlist output, joined by - and _ (e.g. admin-panel)")
+	fs.BoolVar(&config.WordlistMutate, "wl-mutate", false, "Also add every -wordlist word with environment and backup prefixes and suffixes (dev-, -staging, .bak, _old, ...)")
+	fs.StringVar(&config.WordlistYears, "wl-years", wordlist.YearsKeep, "Keep the years and dates of -wordlist words, strip them, or replace them with each year of a range such as 2019-2025")
+	fs.Var((*stringList)(&config.WordlistSubtract), "wl-subtract", "Leave the words of this generic wordlist, such as a SecLists file, out of -wordlist output (repeatable)")
+	fs.StringVar(&config.WordlistLang, "wordlist-lang", wordlist.LanguageAuto, "Language whose stopwords are dropped from -wordlist output: auto, none or "+strings.Join(wordlist.Languages(), ", "))
+	fs.BoolVar(&config.DetectRedirects, "detect-redirects", false, "Detect potential open redirects")
+	fs.StringVar(&config.RedirectConfig, "redirect-config", "", "Path to redirect detection configuration file")
+	fs.BoolVar(&config.DetectXSSParams, "detect-xss-params", false, "Detect parameters with markup in their values or named after common reflection sinks")
+	fs.StringVar(&config.XSSConfig, "xss-config", "", "Path to XSS parameter detection configuration file")
+	fs.Float64Var(&config.EntropyMin, "entropy-min", 0, "Report random-looking tokens with at least this Shannon entropy (e.g. 4.0)")
+	fs.IntVar(&config.CrawlDepth, "crawl-depth", 0, "Fetch discovered in-scope URLs and extract from their bodies, up to this many levels")
+	fs.IntVar(&config.CrawlConcurrency, "crawl-concurrency", 4, "Maximum number of parallel requests while crawling or enriching")
+	fs.DurationVar(&config.CrawlDelay, "crawl-delay", 500*time.Millisecond, "Minimum delay between crawl requests")
+	fs.BoolVar(&config.Enrich, "enrich", false, "Fetch / of each reported domain and record status, title, server and favicon hash")
+	fs.BoolVar(&config.Probe, "probe", false, "Check every reported URL and record its status code and content length")
+	fs.BoolVar(&config.ProbeS3, "probe-s3", false, "Check whether extracted S3 buckets can be listed or their ACL read anonymously (implies -buckets)")
+	fs.StringVar(&config.S3Endpoint, "s3-endpoint", "", "Base URL of an S3 compatible API for -probe-s3 (default AWS)")
+	fs.BoolVar(&config.Takeover, "takeover", false, "Report dangling CNAMEs and storage buckets that do not exist as takeover candidates (implies -domains and -buckets)")
+	fs.BoolVar(&config.OnlyAlive, "only-alive", false, "With -probe, drop URLs that are unreachable or return errors")
+	fs.StringVar(&config.UserAgent, "user-agent", httpclient.DefaultUserAgent, "User-Agent sent with HTTP requests")
+	fs.Var((*stringList)(&config.Headers), "H", "Custom \"Name: value\" header sent with HTTP requests (repeatable)")
+	fs.StringVar(&config.Proxy, "proxy", "", "HTTP or SOCKS5 proxy URL for HTTP requests (default from HTTP_PROXY/HTTPS_PROXY)")
+	fs.BoolVar(&config.Insecure, "insecure", false, "Skip TLS certificate verification, e.g. behind an intercepting proxy")
+	fs.IntVar(&config.Retries, "retries", 2, "Number of retries for failed HTTP requests")
+	fs.DurationVar(&config.HostDelay, "host-delay", 0, "Minimum delay between HTTP requests to the same host")
+	config.MaxBody = defaultMaxBody
+	fs.Var(&config.MaxBody, "max-body", "Maximum size of an HTTP response body; longer bodies are cut off (0 means no limit)")
+	fs.IntVar(&config.MaxRedirects, "max-redirects", 10, "Maximum number of redirects followed per HTTP request; 0 does not follow redirects")
+	fs.DurationVar(&config.RequestTimeout, "request-timeout", 10*time.Second, "Maximum time for each HTTP request attempt, including reading the body")
+	rate := fs.String("rate", "", "Maximum rate of HTTP requests across all hosts, e.g. 10/s or 100/m")
+	ratePerHost := fs.String("rate-per-host", "", "Maximum rate of HTTP requests to each host, e.g. 2/s")
+	fs.BoolVar(&config.IgnoreRobots, "ignore-robots", false, "Fetch URLs even when robots.txt disallows them")
+	fs.StringVar(&config.CacheDir, "cache-dir", "", "Directory caching DNS, probe, enrichment and CT results between runs (default the user cache directory)")
+	fs.DurationVar(&config.CacheTTL, "cache-ttl", cache.DefaultTTL, "How long cached network results are used")
+	fs.BoolVar(&config.NoCache, "no-cache", false, "Neither read nor write the network cache")
+	fs.StringVar(&config.WorkDir, "workdir", "", "Directory for temporary files, removed when the run ends or is interrupted (default the system temporary directory)")
+	tlds := fs.String("tlds", "", "Comma-separated list of TLDs to keep in domain results (e.g. com,net,io)")
+	excludeTLDs := fs.String("exclude-tlds", "", "Comma-separated list of TLDs to drop from domain results (e.g. local,test)")
+	fs.BoolVar(&config.IncludeReserved, "include-reserved", false, "Keep RFC 2606 reserved domains such as example.com in domain results")
+	fs.BoolVar(&config.OnlyInternal, "only-internal", false, "Only report internal hosts and URLs (private IPs, .local, .corp, intranet names, ...)")
+	fs.BoolVar(&config.InternalSection, "internal-section", false, "List internal domains and IPs in their own Internal Hosts section of the text output")
+	fs.StringVar(&config.ScopeFile, "scope-file", "", "File of in-scope hosts, *.wildcards and CIDRs; everything else is dropped")
+	fs.StringVar(&config.OutOfScopeReport, "out-of-scope-report", "", "With -scope-file, write the findings dropped as out of scope to this file")
+	fs.StringVar(&config.AuditLog, "audit-log", "", "Append every reported finding with the run ID and time to this NDJSON log, chained so edits are detected")
+	fs.BoolVar(&config.Redact, "redact", false, "Mask secrets, email addresses and card numbers in the report, keeping a hash of each to match the unredacted output")
+	fs.StringVar(&config.SuppressFile, "suppress-file", "", "Allowlist of accepted findings, generated with \"urlsluice suppress\", kept out of the report")
+	fs.StringVar(&config.SuppressKey, "suppress-key", "", "Base64 Ed25519 public key that must have signed the -suppress-file, in the file named after it with .sig appended")
+	tags := fs.String("tag", "", "Comma-separated list of tags; only findings carrying one of them are reported")
+	minConfidence := fs.String("min-confidence", string(finding.ConfidenceLow), "Minimum confidence of reported findings (low, medium, high)")
+
+	args, err := withProfile(fs, args)
 	if err != nil {
-		return fmt.Errorf("extraction failed: %w", err)
+		return nil, err
+	}
+	if err := fs.Parse(args); err != nil {
+		if name, ok := strings.CutPrefix(err.Error(), "flag provided but not defined: -"); ok {
+			return nil, fmt.Errorf("%w%s", err, flagHint(fs, name))
+		}
+		return nil, err
 	}
 
-	// Handle redirect detection if enabled
-	if config.DetectRedirects {
-		detector, err := redirect.NewRedirectDetector(config.RedirectConfig)
+	confidence, err := finding.ParseConfidence(*minConfidence)
+	if err != nil {
+		return nil, err
+	}
+	config.MinConfidence = confidence
+	if config.OnlyAlive && !config.Probe {
+		return nil, fmt.Errorf("-only-alive requires -probe")
+	}
+	if err := validateInputs(config); err != nil {
+		return nil, err
+	}
+	if config.Xref {
+		config.XrefIndex = &xref.Index{}
+	}
+	if config.ParamsMode, err = parseParamsMode(config.ParamsMode); err != nil {
+		return nil, err
+	}
+	if !wordlist.ValidLanguage(config.WordlistLang) {
+		return nil, fmt.Errorf("invalid -wordlist-lang %q: must be auto, none or one of %s", config.WordlistLang, strings.Join(wordlist.Languages(), ", "))
+	}
+	if config.WordlistNGrams != 0 && (config.WordlistNGrams < 2 || config.WordlistNGrams > wordlist.MaxNGrams) {
+		return nil, fmt.Errorf("invalid -wl-ngrams %d: must be between 2 and %d, or 0 to disable n-grams", config.WordlistNGrams, wordlist.MaxNGrams)
+	}
+	if _, err := wordlist.ParseYears(config.WordlistYears); err != nil {
+		return nil, fmt.Errorf("invalid -wl-years %q: %w", config.WordlistYears, err)
+	}
+	switch config.Export {
+	case "", exportIDOR:
+	default:
+		return nil, fmt.Errorf("invalid export format %q: must be %s", config.Export, exportIDOR)
+	}
+	switch config.GroupBy {
+	case "", groupByHost:
+	default:
+		return nil, fmt.Errorf("invalid -group-by %q: must be %s", config.GroupBy, groupByHost)
+	}
+	if config.GroupBy != "" && config.UniqueValues {
+		return nil, fmt.Errorf("-group-by cannot be combined with -unique-values")
+	}
+	if config.DeobfuscateEmails {
+		config.ExtractEmails = true
+	}
+	if config.DecodeParams {
+		config.ExtractParams = true
+	}
+	if config.AvatarCorrelate {
+		config.AvatarHashes, config.ExtractEmails = true, true
+	}
+	if config.ProbeS3 {
+		config.ExtractBuckets = true
+	}
+	if config.Takeover {
+		config.ExtractDomains, config.ExtractBuckets = true, true
+	}
+	// Probing reports URLs and exports are generated from them, so both imply URL extraction
+	if config.Probe || config.Export != "" {
+		config.ExtractURLs = true
+	}
+	if config.CrawlDepth < 0 {
+		return nil, fmt.Errorf("crawl depth must not be negative")
+	}
+	if config.MaxPerCategory < 0 {
+		return nil, fmt.Errorf("max per category must not be negative")
+	}
+	if config.UUIDVersion < 0 || config.UUIDVersion > 5 {
+		return nil, fmt.Errorf("invalid UUID version %d: must be between 1 and 5, or 0 to disable UUID extraction", config.UUIDVersion)
+	}
+	if config.TimeoutRead < 0 || config.TimeoutExtract < 0 || config.TimeoutEnrich < 0 || config.RequestTimeout < 0 {
+		return nil, fmt.Errorf("timeouts must not be negative")
+	}
+	if config.CacheTTL < 0 {
+		return nil, fmt.Errorf("cache TTL must not be negative")
+	}
+	if config.MaxRedirects < 0 {
+		return nil, fmt.Errorf("max redirects must not be negative")
+	}
+	if *rate != "" {
+		if config.Rate, err = httpclient.ParseRate(*rate); err != nil {
+			return nil, fmt.Errorf("invalid -rate: %w", err)
+		}
+	}
+	if *ratePerHost != "" {
+		if config.RatePerHost, err = httpclient.ParseRate(*ratePerHost); err != nil {
+			return nil, fmt.Errorf("invalid -rate-per-host: %w", err)
+		}
+	}
+	if config.Rate.Requests > 0 || config.RatePerHost.Requests > 0 {
+		config.RateLimiter = httpclient.NewRateLimiter(config.Rate, config.RatePerHost)
+	}
+	config.TLDs = splitList(*tlds)
+	config.ExcludeTLDs = splitList(*excludeTLDs)
+	config.Tags = splitList(*tags)
+	if config.CommentKeywords = splitList(*commentKeywords); len(config.CommentKeywords) > 0 {
+		config.ExtractComments = true
+	}
+	config.UUIDNamespaces = splitList(*uuidNamespaces)
+	for _, ns := range config.UUIDNamespaces {
+		if _, err := uuids.ParseNamespace(ns); err != nil {
+			return nil, err
+		}
+	}
+	if config.All && config.None {
+		return nil, fmt.Errorf("-all and -none cannot be combined")
+	}
+	if config.None && !flagPassed(fs, "uuid") {
+		config.UUIDVersion = 0
+	}
+	if config.All {
+		for _, t := range finding.Types {
+			if e, ok := lookupExtractor(t); !ok || !e.all {
+				continue
+			}
+			if err := enableCategory(config, t); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, name := range splitList(*only) {
+		t, err := parseCategory(name)
 		if err != nil {
-			return fmt.Errorf("error creating redirect detector: %w", err)
+			return nil, err
 		}
+		if err := enableCategory(config, t); err != nil {
+			return nil, err
+		}
+		config.Only = append(config.Only, t)
+	}
 
-		urls := strings.Split(string(data), "\n")
-		results := detector.ScanURLs(urls)
+	for _, name := range splitList(*dedupeCase) {
+		t, err := parseCategory(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -dedupe-case: %w", err)
+		}
+		config.Dedupe.IgnoreCase = append(config.Dedupe.IgnoreCase, t)
+	}
+	if config.Dedupe.ParamKeysOnly, err = dedupe.ParseParamsMode(*dedupeParams); err != nil {
+		return nil, err
+	}
 
-		if !config.Silent {
-			fmt.Println("\nPotential Open Redirects:")
+	if config.OutOfScopeReport != "" && config.ScopeFile == "" {
+		return nil, fmt.Errorf("-out-of-scope-report requires -scope-file")
+	}
+	if config.AuditLog != "" && !audit.Locking {
+		return nil, fmt.Errorf("-audit-log is not supported on %s, where the log cannot be locked against other runs", runtime.GOOS)
+	}
+	if config.Encoding, err = decode.ParseEncoding(*encoding); err != nil {
+		return nil, err
+	}
+	if config.LineFilter, err = grep.New(config.Grep, config.VGrep); err != nil {
+		return nil, fmt.Errorf("invalid -grep or -vgrep: %w", err)
+	}
+	if config.LowMemory {
+		if err := applyLowMemory(fs, config); err != nil {
+			return nil, err
+		}
+	}
+	if config.MaxLineLength < extractor.MinMaxLineLength {
+		return nil, fmt.Errorf("-max-line-length must be at least 1KB")
+	}
+	if config.DedupeLines < 0 {
+		return nil, fmt.Errorf("-dedupe-lines must not be negative")
+	}
+	if config.DedupeLines > 0 && config.Structured {
+		return nil, fmt.Errorf("-dedupe-lines cannot be used with -structured")
+	}
+	if config.WorkDir != "" {
+		if err := tempdir.SetBase(config.WorkDir); err != nil {
+			return nil, fmt.Errorf("invalid -workdir: %w", err)
+		}
+	}
+	if config.ScopeFile != "" {
+		if config.Scope, err = scope.Load(config.ScopeFile); err != nil {
+			return nil, fmt.Errorf("error loading scope file: %w", err)
 		}
+	}
+	if config.Suppressions, err = loadSuppressions(config); err != nil {
+		return nil, err
+	}
 
-		for _, result := range results {
-			if result.IsVulnerable {
-				fmt.Println(result.URL)
-				if !config.Silent {
-					for _, param := range result.MatchedParams {
-						fmt.Printf("  Parameter: %s = %s (Known: %v)\n",
-							param.Name, param.Value, param.IsKnown)
-					}
-					fmt.Println()
-				}
-			}
+	if config.ConfigFile != "" {
+		settings, err := configfile.Load(config.ConfigFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading config: %w", err)
+		}
+		config.Settings = settings
+		if config.TagRules, err = settings.TagRules(); err != nil {
+			return nil, fmt.Errorf("error loading config: %w", err)
+		}
+		if config.TransformRules, err = settings.TransformRules(); err != nil {
+			return nil, fmt.Errorf("error loading config: %w", err)
+		}
+		if config.Plugins, err = settings.ExtractorPlugins(); err != nil {
+			return nil, fmt.Errorf("error loading config: %w", err)
+		}
+		if config.Remediation, err = settings.RemediationCatalog(); err != nil {
+			return nil, fmt.Errorf("error loading config: %w", err)
 		}
 	}
 
-	// Print results
-	return printResults(results, config.Silent)
+	return config, nil
 }
 
-func printResults(results extractor.Results, silent bool) error {
-	printSection := func(label string, items map[string]bool) {
-		if len(items) == 0 {
-			return
+// splitList splits a comma-separated flag value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
 		}
+	}
+	return items
+}
+
+// categoryAliases maps the extractor flag names and plurals accepted by -only to finding types
+var categoryAliases = map[string]finding.Type{
+	"uuids":           finding.TypeUUID,
+	"emails":          finding.TypeEmail,
+	"domains":         finding.TypeDomain,
+	"ips":             finding.TypeIP,
+	"params":          finding.TypeParam,
+	"queryparams":     finding.TypeParam,
+	"urls":            finding.TypeURL,
+	"tokens":          finding.TypeToken,
+	"handles":         finding.TypeHandle,
+	"cloud-config":    finding.TypeCloudConfig,
+	"config-secrets":  finding.TypeConfigSecret,
+	"secrets":         finding.TypeConfigSecret,
+	"timestamps":      finding.TypeTimestamp,
+	"header-issues":   finding.TypeHeaderIssue,
+	"usernames":       finding.TypeUsername,
+	"avatar-hashes":   finding.TypeAvatarHash,
+	"buckets":         finding.TypeBucket,
+	"takeovers":       finding.TypeTakeover,
+	"jsonp":           finding.TypeJSONP,
+	"errors":          finding.TypeVerboseError,
+	"comments":        finding.TypeComment,
+	"locales":         finding.TypeLocale,
+	"tracking-params": finding.TypeTrackingParam,
+	"plugins":         finding.TypePlugin,
+}
 
-		// Convert map keys to sorted slice
-		sorted := make([]string, 0, len(items))
-		for item := range items {
-			sorted = append(sorted, item)
+// flagPassed reports whether the flag named name was given on the command line
+func flagPassed(fs *flag.FlagSet, name string) bool {
+	passed := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			passed = true
 		}
-		sort.Strings(sorted)
+	})
+	return passed
+}
 
-		if !silent {
-			fmt.Printf("\nExtracted %s:\n", label)
+// parseCategory converts an -only entry such as "emails" or "config_secret" into a finding type
+func parseCategory(name string) (finding.Type, error) {
+	if t, ok := categoryAliases[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return t, nil
+	}
+	return finding.ParseType(name)
+}
+
+// enableCategory turns on the extractor producing findings of type t
+func enableCategory(config *Config, t finding.Type) error {
+	switch t {
+	case finding.TypeUUID:
+		if config.UUIDVersion == 0 {
+			config.UUIDDetect = true
 		}
-		for _, item := range sorted {
-			fmt.Println(item)
+	case finding.TypeEmail:
+		config.ExtractEmails = true
+	case finding.TypeDomain:
+		config.ExtractDomains = true
+	case finding.TypeIP:
+		config.ExtractIPs = true
+	case finding.TypeParam:
+		config.ExtractParams = true
+	case finding.TypeURL:
+		config.ExtractURLs = true
+	case finding.TypeToken:
+		if config.EntropyMin == 0 {
+			return fmt.Errorf("-only tokens requires -entropy-min")
 		}
+	case finding.TypeHandle:
+		config.ExtractHandles = true
+	case finding.TypeCrypto:
+		config.ExtractCrypto = true
+	case finding.TypeCloudConfig:
+		config.ExtractCloud = true
+	case finding.TypeConfigSecret:
+		config.ExtractSecrets = true
+	case finding.TypeTimestamp:
+		config.Timestamps = true
+	case finding.TypeHeaderIssue:
+		config.SecurityHeaders = true
+	case finding.TypeUsername:
+		config.Usernames = true
+	case finding.TypeAvatarHash:
+		config.AvatarHashes = true
+	case finding.TypeBucket:
+		config.ExtractBuckets = true
+	case finding.TypeTakeover:
+		config.Takeover, config.ExtractDomains, config.ExtractBuckets = true, true, true
+	case finding.TypeJSONP:
+		config.ExtractJSONP = true
+	case finding.TypeCORS:
+		config.CORS = true
+	case finding.TypeVerboseError:
+		config.ExtractErrors = true
+	case finding.TypeComment:
+		config.ExtractComments = true
+	case finding.TypeLocale:
+		config.ExtractLocales = true
+	case finding.TypeTrackingParam:
+		config.ExtractParams = true
 	}
+	return nil
+}
 
-	printSection("UUIDs", results.UUIDs)
-	printSection("Emails", results.Emails)
-	printSection("Domains", results.Domains)
-	printSection("IP Addresses", results.IPs)
-	printSection("Query Parameters", results.Params)
+// stringList is a flag.Value collecting every occurrence of a repeatable flag
+type stringList []string
 
-	return nil
+func (l *stringList) String() string {
+	return strings.Join(*l, ", ")
 }
 
-func parseFlags() (*Config, error) {
-	config := &Config{}
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
 
-	flag.StringVar(&config.FilePath, "file", "", "Path to the input file (required)")
-	flag.IntVar(&config.UUIDVersion, "uuid", 4, "UUID version to extract (1-5)")
-	flag.BoolVar(&config.ExtractEmails, "emails", false, "Extract email addresses")
-	flag.BoolVar(&config.ExtractDomains, "domains", false, "Extract domain names")
-	flag.BoolVar(&config.ExtractIPs, "ips", false, "Extract IP addresses")
-	flag.BoolVar(&config.ExtractParams, "queryParams", false, "Extract query parameters")
-	flag.BoolVar(&config.Silent, "silent", false, "Output data without titles")
-	flag.BoolVar(&config.GenerateWordlist, "wordlist", false, "Generate a wordlist from URLs in file")
-	flag.BoolVar(&config.DetectRedirects, "detect-redirects", false, "Detect potential open redirects")
-	flag.StringVar(&config.RedirectConfig, "redirect-config", "", "Path to redirect detection configuration file")
+// defaultMaxBody is the default -max-body limit (5MB)
+const defaultMaxBody byteSize = 5 * 1024 * 1024
 
-	flag.Parse()
+// byteSize is a flag.Value holding a size in bytes, given as a number with an optional
+// KB, MB or GB suffix (powers of 1024)
+type byteSize int64
 
-	if config.FilePath == "" {
-		return nil, fmt.Errorf("file path is required")
+func (s *byteSize) String() string {
+	switch n := int64(*s); {
+	case n != 0 && n%(1<<30) == 0:
+		return fmt.Sprintf("%dGB", n>>30)
+	case n != 0 && n%(1<<20) == 0:
+		return fmt.Sprintf("%dMB", n>>20)
+	case n != 0 && n%(1<<10) == 0:
+		return fmt.Sprintf("%dKB", n>>10)
+	default:
+		return strconv.FormatInt(n, 10)
 	}
+}
 
-	return config, nil
+func (s *byteSize) Set(value string) error {
+	n, err := httpclient.ParseSize(value)
+	if err != nil {
+		return err
+	}
+	*s = byteSize(n)
+	return nil
 }
@@ -3,16 +3,37 @@ package main
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"flag"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
 	"time"
 
+	configfile "github.com/PeteJStewart/urlsluice/internal/config"
+	"github.com/PeteJStewart/urlsluice/internal/decode"
 	"github.com/PeteJStewart/urlsluice/internal/extractor"
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/httpclient"
+	"github.com/PeteJStewart/urlsluice/internal/output"
+	"github.com/PeteJStewart/urlsluice/internal/remediation"
+	"github.com/PeteJStewart/urlsluice/internal/wordlist"
 )
 
+func TestMain(m *testing.M) {
+	// Keep the network cache of test runs out of the user's cache directory
+	dir, err := os.MkdirTemp("", "urlsluice-cache")
+	if err != nil {
+		panic(err)
+	}
+	userCacheDir = func() (string, error) { return dir, nil }
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
 // Move osExit to package level
 var osExit = os.Exit
 
@@ -31,9 +52,9 @@ func TestPrintResults(t *testing.T) {
 		{
 			name: "normal output",
 			results: extractor.Results{
-				Emails: map[string]bool{
-					"test@example.com": true,
-					"abc@example.com":  true,
+				Findings: []finding.Finding{
+					{Type: finding.TypeEmail, Value: "test@example.com"},
+					{Type: finding.TypeEmail, Value: "abc@example.com"},
 				},
 			},
 			silent:   false,
@@ -42,8 +63,8 @@ func TestPrintResults(t *testing.T) {
 		{
 			name: "silent output",
 			results: extractor.Results{
-				Emails: map[string]bool{
-					"test@example.com": true,
+				Findings: []finding.Finding{
+					{Type: finding.TypeEmail, Value: "test@example.com"},
 				},
 			},
 			silent:   true,
@@ -63,7 +84,7 @@ func TestPrintResults(t *testing.T) {
 			r, w, _ := os.Pipe()
 			os.Stdout = w
 
-			printResults(tt.results, tt.silent)
+			printResults(tt.results, output.TextOptions{Silent: tt.silent})
 
 			w.Close()
 			var buf bytes.Buffer
@@ -89,15 +110,352 @@ func TestParseFlags(t *testing.T) {
 			name: "all flags set",
 			args: []string{"-uuid", "4", "-emails", "-domains", "-ips", "-queryParams", "-silent", "-file", "testfile"},
 			wantConfig: Config{
-				FilePath:       "testfile",
-				UUIDVersion:    4,
-				ExtractEmails:  true,
-				ExtractDomains: true,
-				ExtractIPs:     true,
-				ExtractParams:  true,
-				Silent:         true,
+				FilePaths:        []string{"testfile"},
+				UUIDVersion:      4,
+				ExtractEmails:    true,
+				ExtractDomains:   true,
+				ExtractIPs:       true,
+				ExtractParams:    true,
+				Silent:           true,
+				MinConfidence:    finding.ConfidenceLow,
+				Encoding:         decode.Auto,
+				CrawlConcurrency: 4,
+				CrawlDelay:       500 * time.Millisecond,
+				UserAgent:        "urlsluice",
+				Retries:          2,
+				MaxBody:          defaultMaxBody,
+				MaxLineLength:    extractor.DefaultMaxLineLength,
+				MaxRedirects:     10,
+				RequestTimeout:   10 * time.Second,
+				CacheTTL:         24 * time.Hour,
+				ParamsMode:       paramsPairs,
+				WordlistLang:     wordlist.LanguageAuto,
+				WordlistYears:    wordlist.YearsKeep,
+			},
+		},
+		{
+			name: "min confidence",
+			args: []string{"-emails", "-min-confidence", "high", "-file", "testfile"},
+			wantConfig: Config{
+				FilePaths:        []string{"testfile"},
+				UUIDVersion:      4,
+				ExtractEmails:    true,
+				MinConfidence:    finding.ConfidenceHigh,
+				Encoding:         decode.Auto,
+				CrawlConcurrency: 4,
+				CrawlDelay:       500 * time.Millisecond,
+				UserAgent:        "urlsluice",
+				Retries:          2,
+				MaxBody:          defaultMaxBody,
+				MaxLineLength:    extractor.DefaultMaxLineLength,
+				MaxRedirects:     10,
+				RequestTimeout:   10 * time.Second,
+				CacheTTL:         24 * time.Hour,
+				ParamsMode:       paramsPairs,
+				WordlistLang:     wordlist.LanguageAuto,
+				WordlistYears:    wordlist.YearsKeep,
+			},
+		},
+		{
+			name: "deobfuscated emails imply emails",
+			args: []string{"-emails-deobfuscate", "-file", "testfile"},
+			wantConfig: Config{
+				FilePaths:         []string{"testfile"},
+				UUIDVersion:       4,
+				ExtractEmails:     true,
+				DeobfuscateEmails: true,
+				MinConfidence:     finding.ConfidenceLow,
+				Encoding:          decode.Auto,
+				CrawlConcurrency:  4,
+				CrawlDelay:        500 * time.Millisecond,
+				UserAgent:         "urlsluice",
+				Retries:           2,
+				MaxBody:           defaultMaxBody,
+				MaxLineLength:     extractor.DefaultMaxLineLength,
+				MaxRedirects:      10,
+				RequestTimeout:    10 * time.Second,
+				CacheTTL:          24 * time.Hour,
+				ParamsMode:        paramsPairs,
+				WordlistLang:      wordlist.LanguageAuto,
+				WordlistYears:     wordlist.YearsKeep,
+			},
+		},
+		{
+			name: "tld filters",
+			args: []string{"-domains", "-tlds", "com, io", "-exclude-tlds", "local", "-include-reserved", "-file", "testfile"},
+			wantConfig: Config{
+				FilePaths:        []string{"testfile"},
+				UUIDVersion:      4,
+				ExtractDomains:   true,
+				MinConfidence:    finding.ConfidenceLow,
+				Encoding:         decode.Auto,
+				CrawlConcurrency: 4,
+				CrawlDelay:       500 * time.Millisecond,
+				UserAgent:        "urlsluice",
+				Retries:          2,
+				MaxBody:          defaultMaxBody,
+				MaxLineLength:    extractor.DefaultMaxLineLength,
+				MaxRedirects:     10,
+				RequestTimeout:   10 * time.Second,
+				CacheTTL:         24 * time.Hour,
+				ParamsMode:       paramsPairs,
+				WordlistLang:     wordlist.LanguageAuto,
+				WordlistYears:    wordlist.YearsKeep,
+				TLDs:             []string{"com", "io"},
+				ExcludeTLDs:      []string{"local"},
+				IncludeReserved:  true,
+			},
+		},
+		{
+			name: "comment keywords imply comments",
+			args: []string{"-comment-keywords", "TODO, password", "-file", "testfile"},
+			wantConfig: Config{
+				FilePaths:        []string{"testfile"},
+				UUIDVersion:      4,
+				ExtractComments:  true,
+				CommentKeywords:  []string{"TODO", "password"},
+				MinConfidence:    finding.ConfidenceLow,
+				Encoding:         decode.Auto,
+				CrawlConcurrency: 4,
+				CrawlDelay:       500 * time.Millisecond,
+				UserAgent:        "urlsluice",
+				Retries:          2,
+				MaxBody:          defaultMaxBody,
+				MaxLineLength:    extractor.DefaultMaxLineLength,
+				MaxRedirects:     10,
+				RequestTimeout:   10 * time.Second,
+				CacheTTL:         24 * time.Hour,
+				ParamsMode:       paramsPairs,
+				WordlistLang:     wordlist.LanguageAuto,
+				WordlistYears:    wordlist.YearsKeep,
+			},
+		},
+		{
+			name: "network options",
+			args: []string{"-urls", "-H", "Cookie: a=b", "-H", "X-Test: 1", "-proxy", "socks5://127.0.0.1:9050", "-user-agent", "scanner", "-retries", "0", "-file", "testfile"},
+			wantConfig: Config{
+				FilePaths:        []string{"testfile"},
+				UUIDVersion:      4,
+				ExtractURLs:      true,
+				MinConfidence:    finding.ConfidenceLow,
+				Encoding:         decode.Auto,
+				CrawlConcurrency: 4,
+				CrawlDelay:       500 * time.Millisecond,
+				UserAgent:        "scanner",
+				Headers:          []string{"Cookie: a=b", "X-Test: 1"},
+				Proxy:            "socks5://127.0.0.1:9050",
+				MaxBody:          defaultMaxBody,
+				MaxLineLength:    extractor.DefaultMaxLineLength,
+				MaxRedirects:     10,
+				RequestTimeout:   10 * time.Second,
+				CacheTTL:         24 * time.Hour,
+				ParamsMode:       paramsPairs,
+				WordlistLang:     wordlist.LanguageAuto,
+				WordlistYears:    wordlist.YearsKeep,
+			},
+		},
+		{
+			name: "tag filter",
+			args: []string{"-domains", "-tag", "staging, prod", "-file", "testfile"},
+			wantConfig: Config{
+				FilePaths:        []string{"testfile"},
+				UUIDVersion:      4,
+				ExtractDomains:   true,
+				MinConfidence:    finding.ConfidenceLow,
+				Encoding:         decode.Auto,
+				CrawlConcurrency: 4,
+				CrawlDelay:       500 * time.Millisecond,
+				UserAgent:        "urlsluice",
+				Retries:          2,
+				MaxBody:          defaultMaxBody,
+				MaxLineLength:    extractor.DefaultMaxLineLength,
+				MaxRedirects:     10,
+				RequestTimeout:   10 * time.Second,
+				CacheTTL:         24 * time.Hour,
+				ParamsMode:       paramsPairs,
+				WordlistLang:     wordlist.LanguageAuto,
+				WordlistYears:    wordlist.YearsKeep,
+				Tags:             []string{"staging", "prod"},
+			},
+		},
+		{
+			name:        "missing config file",
+			args:        []string{"-config", "does-not-exist.yaml", "-file", "testfile"},
+			wantErr:     true,
+			wantErrText: "error loading config",
+		},
+		{
+			name:        "invalid UUID namespace",
+			args:        []string{"-uuid-namespaces", "dns,users", "-file", "testfile"},
+			wantErr:     true,
+			wantErrText: "invalid UUID namespace",
+		},
+		{
+			name:        "invalid min confidence",
+			args:        []string{"-min-confidence", "certain", "-file", "testfile"},
+			wantErr:     true,
+			wantErrText: "invalid confidence level",
+		},
+		{
+			name:        "max line length too small",
+			args:        []string{"-max-line-length", "100", "-file", "testfile"},
+			wantErr:     true,
+			wantErrText: "-max-line-length must be at least 1KB",
+		},
+		{
+			name:        "unknown -only category",
+			args:        []string{"-only", "emails,passwords", "-file", "testfile"},
+			wantErr:     true,
+			wantErrText: "unknown finding type \"passwords\"",
+		},
+		{
+			name:        "-all with -none",
+			args:        []string{"-all", "-none", "-file", "testfile"},
+			wantErr:     true,
+			wantErrText: "-all and -none cannot be combined",
+		},
+		{
+			name:        "invalid -group-by",
+			args:        []string{"-group-by", "source", "-file", "testfile"},
+			wantErr:     true,
+			wantErrText: "invalid -group-by \"source\": must be host",
+		},
+		{
+			name:        "invalid -wordlist-lang",
+			args:        []string{"-wordlist", "-wordlist-lang", "german", "-file", "testfile"},
+			wantErr:     true,
+			wantErrText: "invalid -wordlist-lang \"german\": must be auto, none or one of de, en, es, fr, it, nl, pt",
+		},
+		{
+			name:        "invalid -wl-ngrams",
+			args:        []string{"-wordlist", "-wl-ngrams", "1", "-file", "testfile"},
+			wantErr:     true,
+			wantErrText: "invalid -wl-ngrams 1: must be between 2 and 4, or 0 to disable n-grams",
+		},
+		{
+			name:        "invalid -wl-years",
+			args:        []string{"-wordlist", "-wl-years", "2025-2019", "-file", "testfile"},
+			wantErr:     true,
+			wantErrText: "invalid -wl-years \"2025-2019\": the range starts after it ends",
+		},
+		{
+			name:        "-workdir not a directory",
+			args:        []string{"-workdir", "main.go", "-file", "testfile"},
+			wantErr:     true,
+			wantErrText: "invalid -workdir: main.go is not a directory",
+		},
+		{
+			name:        "-low-memory with -json",
+			args:        []string{"-low-memory", "-json", "-file", "testfile"},
+			wantErr:     true,
+			wantErrText: "-low-memory cannot be combined with -json, which needs every finding before writing",
+		},
+		{
+			name:        "-only tokens without entropy threshold",
+			args:        []string{"-only", "tokens", "-file", "testfile"},
+			wantErr:     true,
+			wantErrText: "-only tokens requires -entropy-min",
+		},
+		{
+			name:        "unsupported encoding",
+			args:        []string{"-file", "testfile", "-encoding", "ebcdic"},
+			wantErr:     true,
+			wantErrText: "unsupported encoding \"ebcdic\"",
+		},
+		{
+			name: "network limits",
+			args: []string{"-max-body", "512KB", "-max-redirects", "0", "-request-timeout", "30s", "-file", "testfile"},
+			wantConfig: Config{
+				FilePaths:        []string{"testfile"},
+				UUIDVersion:      4,
+				MinConfidence:    finding.ConfidenceLow,
+				Encoding:         decode.Auto,
+				CrawlConcurrency: 4,
+				CrawlDelay:       500 * time.Millisecond,
+				UserAgent:        "urlsluice",
+				Retries:          2,
+				MaxBody:          512 * 1024,
+				MaxLineLength:    extractor.DefaultMaxLineLength,
+				RequestTimeout:   30 * time.Second,
+				CacheTTL:         24 * time.Hour,
+				ParamsMode:       paramsPairs,
+				WordlistLang:     wordlist.LanguageAuto,
+				WordlistYears:    wordlist.YearsKeep,
+			},
+		},
+		{
+			name: "rate limits",
+			args: []string{"-rate", "10/s", "-rate-per-host", "2/s", "-file", "testfile"},
+			wantConfig: Config{
+				FilePaths:        []string{"testfile"},
+				UUIDVersion:      4,
+				MinConfidence:    finding.ConfidenceLow,
+				Encoding:         decode.Auto,
+				CrawlConcurrency: 4,
+				CrawlDelay:       500 * time.Millisecond,
+				UserAgent:        "urlsluice",
+				Retries:          2,
+				MaxBody:          defaultMaxBody,
+				MaxLineLength:    extractor.DefaultMaxLineLength,
+				MaxRedirects:     10,
+				RequestTimeout:   10 * time.Second,
+				CacheTTL:         24 * time.Hour,
+				ParamsMode:       paramsPairs,
+				WordlistLang:     wordlist.LanguageAuto,
+				WordlistYears:    wordlist.YearsKeep,
+				Rate:             httpclient.Rate{Requests: 10, Per: time.Second},
+				RatePerHost:      httpclient.Rate{Requests: 2, Per: time.Second},
+				RateLimiter:      httpclient.NewRateLimiter(httpclient.Rate{Requests: 10, Per: time.Second}, httpclient.Rate{Requests: 2, Per: time.Second}),
+			},
+		},
+		{
+			name: "multiple inputs",
+			args: []string{"-file", "a.txt", "-file", "-", "-url", "https://target.com/app.js", "-emails"},
+			wantConfig: Config{
+				FilePaths:        []string{"a.txt", "-"},
+				URLs:             []string{"https://target.com/app.js"},
+				UUIDVersion:      4,
+				ExtractEmails:    true,
+				MinConfidence:    finding.ConfidenceLow,
+				Encoding:         decode.Auto,
+				CrawlConcurrency: 4,
+				CrawlDelay:       500 * time.Millisecond,
+				UserAgent:        "urlsluice",
+				Retries:          2,
+				MaxBody:          defaultMaxBody,
+				MaxLineLength:    extractor.DefaultMaxLineLength,
+				MaxRedirects:     10,
+				RequestTimeout:   10 * time.Second,
+				CacheTTL:         24 * time.Hour,
+				ParamsMode:       paramsPairs,
+				WordlistLang:     wordlist.LanguageAuto,
+				WordlistYears:    wordlist.YearsKeep,
 			},
 		},
+		{
+			name:        "stdin twice",
+			args:        []string{"-file", "-", "-file", "-"},
+			wantErr:     true,
+			wantErrText: "can only be given once",
+		},
+		{
+			name:        "relative url",
+			args:        []string{"-url", "/app.js"},
+			wantErr:     true,
+			wantErrText: "invalid -url",
+		},
+		{
+			name:        "invalid rate",
+			args:        []string{"-rate", "fast", "-file", "testfile"},
+			wantErr:     true,
+			wantErrText: "invalid -rate",
+		},
+		{
+			name:        "invalid max body",
+			args:        []string{"-max-body", "5 megs", "-file", "testfile"},
+			wantErr:     true,
+			wantErrText: "invalid size",
+		},
 		{
 			name:        "missing file",
 			args:        []string{"-emails"},
@@ -169,6 +527,50 @@ func TestRun(t *testing.T) {
 			args:    []string{"-uuid", "4", "-file", "testfile"},
 			wantErr: false,
 		},
+		{
+			name:    "valid content with high entropy tokens",
+			content: "session=q8Xv2LmZ7pRt0KwN4sYb",
+			args:    []string{"-entropy-min", "4.0", "-file", "testfile"},
+			wantErr: false,
+		},
+		{
+			name:    "valid content with handles",
+			content: `<a href="https://github.com/acme/widgets">GitHub</a> <a href="https://discord.gg/acme">Discord</a>`,
+			args:    []string{"-handles", "-file", "testfile"},
+			wantErr: false,
+		},
+		{
+			name:    "valid content with crypto addresses",
+			content: "BTC: 1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa",
+			args:    []string{"-crypto", "-file", "testfile"},
+			wantErr: false,
+		},
+		{
+			name:    "valid content with cloud configs",
+			content: `Sentry.init({dsn:"https://0123456789abcdef0123456789abcdef@o42.ingest.sentry.io/1337"})`,
+			args:    []string{"-cloud-config", "-file", "testfile"},
+			wantErr: false,
+		},
+		{
+			name:    "valid content with config secrets",
+			content: "DB_HOST=db.internal\nDB_PASSWORD=hunter2\n",
+			args:    []string{"-config-secrets", "-file", "testfile"},
+			wantErr: false,
+		},
+		{
+			name:        "negative entropy threshold",
+			content:     "session=q8Xv2LmZ7pRt0KwN4sYb",
+			args:        []string{"-entropy-min", "-1", "-file", "testfile"},
+			wantErr:     true,
+			wantErrText: "invalid entropy threshold",
+		},
+		{
+			name:        "invalid UUID version",
+			content:     "550e8400-e29b-41d4-a716-446655440000",
+			args:        []string{"-uuid", "7", "-file", "testfile"},
+			wantErr:     true,
+			wantErrText: "invalid UUID version 7",
+		},
 		{
 			name:    "empty content",
 			content: "",
@@ -247,6 +649,146 @@ func TestMain_Integration(t *testing.T) {
 			wantErr:    false,
 			wantOutput: "\nExtracted Emails:\ntest@example.com\n",
 		},
+		{
+			name:       "only internal hosts",
+			args:       []string{"-domains", "-only-internal", "-file", "testfile"},
+			inputFile:  "https://www.target.com/\nhttps://jira.corp.target.com/browse\nhttp://10.0.0.5:8080/",
+			wantErr:    false,
+			wantOutput: "\nExtracted Domains:\n10.0.0.5\njira.corp.target.com\n",
+		},
+		{
+			name:       "internal hosts stay with the other domains by default",
+			args:       []string{"-domains", "-file", "testfile"},
+			inputFile:  "https://www.target.com/\nhttps://jira.corp.target.com/browse",
+			wantErr:    false,
+			wantOutput: "\nExtracted Domains:\njira.corp.target.com\nwww.target.com\n",
+		},
+		{
+			name:       "internal hosts section",
+			args:       []string{"-domains", "-internal-section", "-file", "testfile"},
+			inputFile:  "https://www.target.com/\nhttps://jira.corp.target.com/browse\nhttp://10.0.0.5:8080/",
+			wantErr:    false,
+			wantOutput: "\nExtracted Domains:\nwww.target.com\n\nExtracted Internal Hosts:\n10.0.0.5\njira.corp.target.com\n",
+		},
+		{
+			name:       "only one category in silent mode",
+			args:       []string{"-emails", "-only", "domains", "-silent", "-file", "testfile"},
+			inputFile:  "https://www.target.com/?id=550e8400-e29b-41d4-a716-446655440000\ndev@target.com",
+			wantErr:    false,
+			wantOutput: "www.target.com\n",
+		},
+		{
+			name:       "grep and vgrep",
+			args:       []string{"-urls", "-grep", `api\.target\.com`, "-vgrep", "/health", "-silent", "-file", "testfile"},
+			inputFile:  "https://api.target.com/v1/users\nhttps://cdn.target.com/app.js\nhttps://api.target.com/health",
+			wantErr:    false,
+			wantOutput: "https://api.target.com/v1/users\n",
+		},
+		{
+			name:       "only several categories",
+			args:       []string{"-only", "emails,ip", "-silent", "-file", "testfile"},
+			inputFile:  "https://www.target.com/\ndev@target.com\nhttp://8.8.8.8/",
+			wantErr:    false,
+			wantOutput: "dev@target.com\n8.8.8.8\n",
+		},
+		{
+			name:       "none with an extractor flag",
+			args:       []string{"-none", "-emails", "-silent", "-file", "testfile"},
+			inputFile:  "https://www.target.com/?id=550e8400-e29b-41d4-a716-446655440000\ndev@target.com",
+			wantErr:    false,
+			wantOutput: "dev@target.com\n",
+		},
+		{
+			name:       "redact",
+			args:       []string{"-emails", "-redact", "-silent", "-file", "testfile"},
+			inputFile:  "dev@target.com",
+			wantErr:    false,
+			wantOutput: "[redacted:5e7392645d7e]@target.com\n",
+		},
+		{
+			name:       "low memory",
+			args:       []string{"-emails", "-domains", "-low-memory", "-file", "testfile"},
+			inputFile:  "contact dev@target.com\nsee https://www.target.com/ or mail dev@target.com",
+			wantErr:    false,
+			wantOutput: "Emails: dev@target.com\nDomains: www.target.com\n",
+		},
+		{
+			name:       "wordlist without the stopwords of the detected language",
+			args:       []string{"-wordlist", "-file", "testfile"},
+			inputFile:  "https://target.fr/offres-pour-les-entreprises\nhttps://target.fr/nous-contacter?sujet=la-facture",
+			wantErr:    false,
+			wantOutput: "Wordlist language: fr (override with -wordlist-lang)\ncontacter\nentreprises\nfacture\noffres\nsujet\n",
+		},
+		{
+			name:       "wordlist keeping stopwords",
+			args:       []string{"-wordlist", "-wordlist-lang", "none", "-file", "testfile"},
+			inputFile:  "https://target.fr/offres-pour-les-entreprises",
+			wantErr:    false,
+			wantOutput: "entreprises\nles\noffres\npour\n",
+		},
+		{
+			name:       "wordlist with path pairs",
+			args:       []string{"-wordlist", "-wl-ngrams", "2", "-silent", "-file", "testfile"},
+			inputFile:  "https://target.com/admin/panel\nhttps://target.com/user-settings",
+			wantErr:    false,
+			wantOutput: "admin\nadmin-panel\nadmin_panel\npanel\nsettings\nuser\nuser-settings\nuser_settings\n",
+		},
+		{
+			name:       "wordlist with mutations",
+			args:       []string{"-wordlist", "-wl-mutate", "-silent", "-file", "testfile"},
+			inputFile:  "https://target.com/admin",
+			wantErr:    false,
+			wantOutput: "_admin\nadmin\nadmin-backup\nadmin-dev\nadmin-old\nadmin-staging\nadmin-test\nadmin.bak\nadmin.old\nadmin.orig\nadmin_bak\nadmin_old\nadmin~\nbackup-admin\ndev-admin\nold-admin\nstaging-admin\ntest-admin\n",
+		},
+		{
+			name:       "wordlist with a range of years",
+			args:       []string{"-wordlist", "-wl-years", "2023-2024", "-silent", "-file", "testfile"},
+			inputFile:  "https://target.com/backup_2021.zip",
+			wantErr:    false,
+			wantOutput: "2021\n2023\n2024\nbackup\nzip\n",
+		},
+		{
+			name:       "group by host",
+			args:       []string{"-queryParams", "-group-by", "host", "-no-color", "-file", "testfile"},
+			inputFile:  "https://api.target.com/users?id=5\nhttps://www.target.com/login?next=/home",
+			wantErr:    false,
+			wantOutput: "\n== api.target.com ==\n\nExtracted Query Parameters:\nid=5\n\n== www.target.com ==\n\nExtracted Query Parameters:\nnext=/home\n",
+		},
+		{
+			name:       "utf-16 input without a bom",
+			args:       []string{"-emails", "-silent", "-file", "testfile"},
+			inputFile:  "d\x00e\x00v\x00@\x00t\x00a\x00r\x00g\x00e\x00t\x00.\x00c\x00o\x00m\x00\n\x00",
+			wantErr:    false,
+			wantOutput: "dev@target.com\n",
+		},
+		{
+			name:       "forced latin-1 input",
+			args:       []string{"-emails", "-silent", "-encoding", "latin-1", "-file", "testfile"},
+			inputFile:  "Jos\xe9 <jose@target.com>",
+			wantErr:    false,
+			wantOutput: "jose@target.com\n",
+		},
+		{
+			name:       "structured yaml",
+			args:       []string{"-domains", "-config-secrets", "-structured", "-file", "testfile"},
+			inputFile:  "db:\n  host: postgres.target.com\n  password: hunter2\n",
+			wantErr:    false,
+			wantOutput: "\nExtracted Domains:\npostgres.target.com (db.host)\n\nExtracted Config Secrets:\npassword=hunter2 (db.password)\n",
+		},
+		{
+			name:       "binary input is skipped",
+			args:       []string{"-emails", "-silent", "-file", "testfile"},
+			inputFile:  "\x7fELF\x02\x01\x01\x00\x00\x00dev@target.com\x00",
+			wantErr:    false,
+			wantOutput: "",
+		},
+		{
+			name:       "strings from binary input",
+			args:       []string{"-emails", "-silent", "-strings", "-file", "testfile"},
+			inputFile:  "\x7fELF\x02\x01\x01\x00\x00\x00dev@target.com\x00\x03\x00",
+			wantErr:    false,
+			wantOutput: "dev@target.com\n",
+		},
 	}
 
 	for _, tt := range tests {
@@ -342,3 +884,389 @@ func TestMain_Integration(t *testing.T) {
 		})
 	}
 }
+
+func TestRun_JSONOutput(t *testing.T) {
+	tmpfile, err := os.CreateTemp("", "json*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	if _, err := tmpfile.WriteString("contact: dev@target.com\n"); err != nil {
+		t.Fatal(err)
+	}
+	tmpfile.Close()
+
+	oldArgs := os.Args
+	oldFlagCommandLine := flag.CommandLine
+	oldStdout := os.Stdout
+	defer func() {
+		os.Args = oldArgs
+		flag.CommandLine = oldFlagCommandLine
+		os.Stdout = oldStdout
+	}()
+
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	os.Args = []string{"cmd", "-emails", "-json", "-file", tmpfile.Name()}
+
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err = run(context.Background())
+	w.Close()
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	var doc output.Document
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	want := []finding.Finding{{
+		Type:       finding.TypeEmail,
+		Value:      "dev@target.com",
+		Source:     tmpfile.Name(),
+		Line:       1,
+		Confidence: finding.ConfidenceHigh,
+	}}
+	if !reflect.DeepEqual(doc.Findings, want) {
+		t.Errorf("findings = %+v, want %+v", doc.Findings, want)
+	}
+
+	if doc.Run == nil {
+		t.Fatal("document has no run metadata")
+	}
+	if doc.Run.Tool != "urlsluice" || doc.Run.Version != version {
+		t.Errorf("run = %s %s, want urlsluice %s", doc.Run.Tool, doc.Run.Version, version)
+	}
+	if len(doc.Run.Inputs) != 1 || doc.Run.Inputs[0].Path != tmpfile.Name() || doc.Run.Inputs[0].Size != 24 {
+		t.Errorf("run inputs = %+v, want %s (24 bytes)", doc.Run.Inputs, tmpfile.Name())
+	}
+	if doc.Run.StartedAt.IsZero() || doc.Run.FinishedAt.Before(doc.Run.StartedAt) {
+		t.Errorf("run timestamps = %v to %v", doc.Run.StartedAt, doc.Run.FinishedAt)
+	}
+}
+
+func TestRun_TagRules(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "urls.txt")
+	if err := os.WriteFile(input, []byte("https://api.staging.target.com/\nhttps://www.target.com/\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(dir, "urlsluice.yaml")
+	if err := os.WriteFile(configPath, []byte("tags:\n  - pattern: staging\n    tag: staging\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		args  []string
+		want  string
+		exact bool
+	}{
+		{
+			name: "tags in json",
+			args: []string{"-json"},
+			want: `"tags": [
+        "staging"
+      ]`,
+		},
+		{
+			name:  "tag filter",
+			args:  []string{"-silent", "-tag", "staging"},
+			want:  "api.staging.target.com\n",
+			exact: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldArgs := os.Args
+			oldFlagCommandLine := flag.CommandLine
+			oldStdout := os.Stdout
+			defer func() {
+				os.Args = oldArgs
+				flag.CommandLine = oldFlagCommandLine
+				os.Stdout = oldStdout
+			}()
+
+			flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			os.Args = append([]string{"cmd", "-domains", "-config", configPath, "-file", input}, tt.args...)
+
+			r, w, _ := os.Pipe()
+			os.Stdout = w
+
+			err := run(context.Background())
+			w.Close()
+			var buf bytes.Buffer
+			buf.ReadFrom(r)
+
+			if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if got := buf.String(); !strings.Contains(got, tt.want) || (tt.exact && got != tt.want) {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun_Remediation(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, ".env")
+	if err := os.WriteFile(input, []byte("DB_PASSWORD=s3cr3t-Passw0rd\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(dir, "urlsluice.yaml")
+	if err := os.WriteFile(configPath, []byte("remediation:\n  config_secret:\n    reference: https://wiki.corp.example/secrets\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	oldArgs := os.Args
+	oldFlagCommandLine := flag.CommandLine
+	oldStdout := os.Stdout
+	defer func() {
+		os.Args = oldArgs
+		flag.CommandLine = oldFlagCommandLine
+		os.Stdout = oldStdout
+	}()
+
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	os.Args = []string{"cmd", "-config-secrets", "-json", "-config", configPath, "-file", input}
+
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := run(context.Background())
+	w.Close()
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	var doc output.Document
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if len(doc.Findings) != 1 {
+		t.Fatalf("findings = %+v, want one secret", doc.Findings)
+	}
+	want, _ := remediation.Default().Lookup(string(finding.TypeConfigSecret))
+	want.Reference = "https://wiki.corp.example/secrets"
+	if got := doc.Findings[0].Remediation; got == nil || *got != want {
+		t.Errorf("remediation = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseFlags_AllNone(t *testing.T) {
+	parse := func(args ...string) *Config {
+		t.Helper()
+		flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+		oldArgs := os.Args
+		os.Args = append([]string{"cmd"}, args...)
+		defer func() { os.Args = oldArgs }()
+		config, err := parseFlags()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return config
+	}
+
+	all := parse("-all", "-file", "testfile")
+	if !all.ExtractEmails || !all.ExtractDomains || !all.ExtractSecrets || !all.ExtractErrors || all.UUIDVersion != 4 {
+		t.Errorf("-all did not enable every offline extractor: %+v", all)
+	}
+	if all.Takeover || all.CORS || all.SecurityHeaders {
+		t.Errorf("-all enabled an extractor that needs network access or extra input: %+v", all)
+	}
+
+	if none := parse("-none", "-only", "config-secrets", "-file", "testfile"); none.UUIDVersion != 0 || none.UUIDDetect || !none.ExtractSecrets {
+		t.Errorf("-none -only config-secrets = uuid %d, uuid-detect %v, secrets %v", none.UUIDVersion, none.UUIDDetect, none.ExtractSecrets)
+	}
+	if none := parse("-none", "-uuid", "1", "-file", "testfile"); none.UUIDVersion != 1 {
+		t.Errorf("-none -uuid 1 = uuid %d, want 1", none.UUIDVersion)
+	}
+}
+
+func TestPatternWarnings(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "urlsluice.yaml")
+	data := "tags:\n  - pattern: staging\n    tag: staging\n  - pattern: '^admin|manage'\n    tag: admin\n" +
+		"transforms:\n  - pattern: '^(.*)/$'\n    replace: $1\n  - lowercase: true\n"
+	if err := os.WriteFile(configPath, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	settings, err := configfile.Load(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := &Config{
+		Grep:       []string{`^https://api\.target\.com/`},
+		VGrep:      []string{`static.target.com`},
+		ConfigFile: configPath,
+		Settings:   settings,
+	}
+	want := []string{
+		`-vgrep "static.target.com" uses . in static.target.com, where it matches any character and not only a dot; escape it as \.`,
+		configPath + `: tags[1]: pattern "^admin|manage" anchors only its first alternative with ^; group the alternatives, e.g. ^(?:a|b)`,
+	}
+	if got := patternWarnings(config); !reflect.DeepEqual(got, want) {
+		t.Errorf("patternWarnings() = %q, want %q", got, want)
+	}
+}
+
+func TestRun_Transforms(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "urls.txt")
+	if err := os.WriteFile(input, []byte("https://www.target.com/\nhttps://Target.com/login\nhttps://api.target.com/\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(dir, "urlsluice.yaml")
+	config := `transforms:
+  - types: [domain]
+    lowercase: true
+    pattern: '^www\.'
+  - types: [domain]
+    prefix: https://
+`
+	if err := os.WriteFile(configPath, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	oldArgs := os.Args
+	oldFlagCommandLine := flag.CommandLine
+	oldStdout := os.Stdout
+	defer func() {
+		os.Args = oldArgs
+		flag.CommandLine = oldFlagCommandLine
+		os.Stdout = oldStdout
+	}()
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	os.Args = []string{"cmd", "-domains", "-silent", "-config", configPath, "-file", input}
+
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	err := run(context.Background())
+	w.Close()
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if want := "https://api.target.com\nhttps://target.com\n"; buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestRun_TrackingParams(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "urls.txt")
+	if err := os.WriteFile(input, []byte("https://target.com/item?id=7&utm_source=mail&gclid=abc&ref=home\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(dir, "urlsluice.yaml")
+	if err := os.WriteFile(configPath, []byte("tracking_params: [ref]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "stripped and separated",
+			args: []string{"-urls", "-queryParams"},
+			want: "\nExtracted Query Parameters:\nid=7\nref=home\n\nExtracted URLs:\nhttps://target.com/item?id=7&ref=home\n\nExtracted Tracking Parameters:\ngclid=abc\nutm_source=mail\n",
+		},
+		{
+			name: "config list",
+			args: []string{"-urls", "-config", configPath, "-silent"},
+			want: "https://target.com/item?id=7\n",
+		},
+		{
+			name: "only params",
+			args: []string{"-only", "params", "-silent"},
+			want: "id=7\nref=home\n",
+		},
+		{
+			name: "kept",
+			args: []string{"-urls", "-queryParams", "-keep-tracking-params", "-silent"},
+			want: "gclid=abc\nid=7\nref=home\nutm_source=mail\nhttps://target.com/item?id=7&utm_source=mail&gclid=abc&ref=home\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldArgs := os.Args
+			oldFlagCommandLine := flag.CommandLine
+			oldStdout := os.Stdout
+			defer func() {
+				os.Args = oldArgs
+				flag.CommandLine = oldFlagCommandLine
+				os.Stdout = oldStdout
+			}()
+
+			flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			os.Args = append([]string{"cmd", "-no-color", "-file", input}, tt.args...)
+
+			r, w, _ := os.Pipe()
+			os.Stdout = w
+
+			err := run(context.Background())
+			w.Close()
+			var buf bytes.Buffer
+			buf.ReadFrom(r)
+
+			if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun_WordlistSubtract(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "urls.txt")
+	if err := os.WriteFile(input, []byte("https://target.com/admin/tenant-billing?redirect=login\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	common := filepath.Join(dir, "common.txt")
+	if err := os.WriteFile(common, []byte("# generic directories\nadmin\nLogin\nredirect/\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	oldArgs := os.Args
+	oldFlagCommandLine := flag.CommandLine
+	oldStdout := os.Stdout
+	defer func() {
+		os.Args = oldArgs
+		flag.CommandLine = oldFlagCommandLine
+		os.Stdout = oldStdout
+	}()
+
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	os.Args = []string{"cmd", "-wordlist", "-silent", "-wl-subtract", common, "-file", input}
+
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := run(context.Background())
+	w.Close()
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if got, want := buf.String(), "billing\ntenant\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
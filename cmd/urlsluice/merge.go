@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/PeteJStewart/urlsluice/internal/output"
+)
+
+// runMerge implements "urlsluice merge a.json b.json -o combined.json", combining the -json
+// documents of earlier runs into one so scans split across machines or days can be
+// consolidated. Findings are deduplicated by type and value, each recording when it was
+// first seen, and the merged files are listed as the inputs of the new document.
+func runMerge(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("merge", flag.ContinueOnError)
+	out := fs.String("o", "", "File to write the merged document to (default standard output)")
+
+	// Flags may follow the file names, as in "merge a.json b.json -o combined.json"
+	var paths []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return fmt.Errorf("error parsing flags: %w", err)
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		paths = append(paths, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("error parsing flags: at least one JSON document is required")
+	}
+
+	run := &output.Run{
+		Tool:      "urlsluice",
+		Version:   version,
+		Build:     buildInfo(),
+		Command:   redactArgs(os.Args),
+		StartedAt: time.Now().UTC(),
+	}
+	docs := make([]output.Document, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("error reading document: %w", err)
+		}
+		run.AddInput(path, data)
+		doc, err := output.ReadDocument(bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", path, err)
+		}
+		docs = append(docs, doc)
+	}
+	findings := output.MergeDocuments(docs)
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return fmt.Errorf("error creating merged document: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+	run.FinishedAt = time.Now().UTC()
+	if err := output.WriteJSON(w, run, findings); err != nil {
+		return fmt.Errorf("error writing merged document: %w", err)
+	}
+	return nil
+}
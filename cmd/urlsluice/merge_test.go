@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/output"
+)
+
+func TestRun_Merge(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, started time.Time, findings ...finding.Finding) string {
+		var buf bytes.Buffer
+		if err := output.WriteJSON(&buf, &output.Run{Tool: "urlsluice", StartedAt: started}, findings); err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	monday := write("monday.json", time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC),
+		finding.Finding{Type: finding.TypeDomain, Value: "a.target.com", Source: "monday.txt"})
+	tuesday := write("tuesday.json", time.Date(2026, 5, 5, 9, 0, 0, 0, time.UTC),
+		finding.Finding{Type: finding.TypeDomain, Value: "a.target.com", Source: "tuesday.txt"},
+		finding.Finding{Type: finding.TypeEmail, Value: "dev@target.com", Source: "tuesday.txt"})
+	invalid := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(invalid, []byte(`{"findings": []}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	combined := filepath.Join(dir, "combined.json")
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "flags after documents",
+			args: []string{"merge", tuesday, monday, "-o", combined},
+		},
+		{
+			name: "standard output",
+			args: []string{"merge", monday, tuesday},
+			want: []string{
+				`"first_seen": "2026-05-04T09:00:00Z"`,
+				`"first_seen": "2026-05-05T09:00:00Z"`,
+				`"source": "monday.txt"`,
+				`"path": "` + tuesday + `"`,
+			},
+		},
+		{
+			name:    "no documents",
+			args:    []string{"merge", "-o", combined},
+			wantErr: "at least one JSON document is required",
+		},
+		{
+			name:    "not a document",
+			args:    []string{"merge", monday, invalid},
+			wantErr: "error reading " + invalid + ": not a urlsluice JSON document",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldArgs := os.Args
+			oldFlagCommandLine := flag.CommandLine
+			oldStdout := os.Stdout
+			defer func() {
+				os.Args = oldArgs
+				flag.CommandLine = oldFlagCommandLine
+				os.Stdout = oldStdout
+			}()
+
+			flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			os.Args = append([]string{"cmd"}, tt.args...)
+
+			r, w, _ := os.Pipe()
+			os.Stdout = w
+
+			err := run(context.Background())
+			w.Close()
+			var buf bytes.Buffer
+			buf.ReadFrom(r)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("run() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("output should contain %q, got:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+
+	f, err := os.Open(combined)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	doc, err := output.ReadDocument(f)
+	if err != nil {
+		t.Fatalf("merged document: %v", err)
+	}
+	if len(doc.Findings) != 2 {
+		t.Fatalf("merged findings = %+v, want 2", doc.Findings)
+	}
+	for _, f := range doc.Findings {
+		if f.Value == "a.target.com" && (f.Source != "monday.txt" || f.Metadata["first_seen"] != "2026-05-04T09:00:00Z") {
+			t.Errorf("merged a.target.com = %+v, want the monday finding", f)
+		}
+	}
+}
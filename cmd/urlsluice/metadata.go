@@ -0,0 +1,68 @@
+package main
+
+import (
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/PeteJStewart/urlsluice/internal/output"
+)
+
+// version, commit and buildDate are set at build time with -ldflags "-X main.version=...";
+// see buildInfo for the fallbacks used by "go install" builds
+var (
+	version   = "dev"
+	commit    = ""
+	buildDate = ""
+)
+
+// newRun starts the provenance record written at the top of structured output
+func newRun(config *Config) *output.Run {
+	run := &output.Run{
+		Tool:      "urlsluice",
+		Version:   version,
+		Build:     buildInfo(),
+		Command:   redactArgs(os.Args),
+		StartedAt: time.Now().UTC(),
+	}
+	if config.Settings != nil {
+		run.ConfigSHA256 = config.Settings.SHA256
+	}
+	return run
+}
+
+// redactArgs copies a command line, hiding header values and proxy credentials so that
+// cookies and tokens passed with -H do not end up in reports
+func redactArgs(args []string) []string {
+	redacted := make([]string, len(args))
+	copy(redacted, args)
+	for i := 1; i < len(redacted); i++ {
+		name, value, inline := strings.Cut(redacted[i], "=")
+		flagName := strings.TrimLeft(name, "-")
+		if flagName != "H" && flagName != "proxy" {
+			continue
+		}
+		if inline {
+			redacted[i] = name + "=" + redactValue(flagName, value)
+		} else if i+1 < len(redacted) {
+			i++
+			redacted[i] = redactValue(flagName, redacted[i])
+		}
+	}
+	return redacted
+}
+
+// redactValue hides the value of a header or the password of a proxy URL
+func redactValue(flagName, value string) string {
+	if flagName == "H" {
+		if header, _, ok := strings.Cut(value, ":"); ok {
+			return header + ": REDACTED"
+		}
+		return value
+	}
+	if u, err := url.Parse(value); err == nil {
+		return u.Redacted()
+	}
+	return value
+}
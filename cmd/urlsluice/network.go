@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"sync"
+
+	"github.com/PeteJStewart/urlsluice/internal/cache"
+	"github.com/PeteJStewart/urlsluice/internal/crawl"
+	"github.com/PeteJStewart/urlsluice/internal/decode"
+	"github.com/PeteJStewart/urlsluice/internal/enrich"
+	"github.com/PeteJStewart/urlsluice/internal/filter"
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/pipeline"
+	"github.com/PeteJStewart/urlsluice/internal/probe"
+	"github.com/PeteJStewart/urlsluice/internal/s3probe"
+	"github.com/PeteJStewart/urlsluice/internal/takeover"
+)
+
+// newCrawlStage fetches the URLs found in each input and re-runs the extractors that apply to
+// the page's path on every fetched body, emitting one batch per page after the input's batch
+func newCrawlStage(config *Config, exts *extractors) (func(context.Context, pipeline.Batch, pipeline.Emit) error, error) {
+	client, err := newHTTPClient(config)
+	if err != nil {
+		return nil, err
+	}
+	crawler := &crawl.Crawler{
+		Client:      client,
+		Depth:       config.CrawlDepth,
+		Concurrency: config.CrawlConcurrency,
+		Delay:       config.CrawlDelay,
+	}
+	if config.Scope != nil {
+		crawler.InScope = config.Scope.URL
+	}
+
+	return func(ctx context.Context, b pipeline.Batch, emit pipeline.Emit) error {
+		// Later stages modify the findings once emitted, so read the seeds first
+		var seeds []string
+		for _, f := range b.Findings {
+			if f.Type == finding.TypeURL {
+				seeds = append(seeds, f.Value)
+			}
+		}
+		if err := emit(b); err != nil {
+			return err
+		}
+
+		var emitErr error
+		err := crawler.Crawl(ctx, seeds, func(page crawl.Page) []string {
+			ext, err := exts.forPath(pagePath(page.URL))
+			if err != nil || emitErr != nil {
+				return nil
+			}
+			results, err := ext.Extract(ctx, bytes.NewReader(decode.Text(page.Body)))
+			if err != nil {
+				return nil
+			}
+			next := results.Values(finding.TypeURL)
+			findings := append(results.Findings, headerFindings(config, page.URL, page.Header)...)
+			emitErr = emit(pipeline.Batch{Source: page.URL, Findings: withSource(findings, page.URL)})
+			return next
+		})
+		if emitErr != nil {
+			return emitErr
+		}
+		if err != nil {
+			return fmt.Errorf("crawl failed: %w", err)
+		}
+		return nil
+	}, nil
+}
+
+// pagePath returns the path of a crawled URL, used to select extractors by extension
+func pagePath(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil {
+		return u.Path
+	}
+	return ""
+}
+
+// newEnrichStage records host details on domain findings with -enrich, liveness on URL
+// findings with -probe and anonymous access on bucket findings with -probe-s3, and adds
+// takeover candidates found among domains and buckets with -takeover. Each host, URL and
+// bucket is checked once, however often it is found.
+// When -timeout-enrich expires, the remaining findings are passed on unchecked.
+func newEnrichStage(config *Config) (func(context.Context, pipeline.Batch, pipeline.Emit) error, error) {
+	client, err := newHTTPClient(config)
+	if err != nil {
+		return nil, err
+	}
+	networkCache := openCache(config)
+	enricher := &enrich.Enricher{Client: client, Concurrency: config.CrawlConcurrency, Cache: networkCache}
+	prober := &probe.Prober{Client: client, Concurrency: config.CrawlConcurrency, Cache: networkCache}
+	// Bucket and takeover checks query storage APIs and the root page of dangling hosts rather
+	// than crawl a site, so robots.txt does not apply
+	apiConfig := *config
+	apiConfig.IgnoreRobots = true
+	apiClient, err := newHTTPClient(&apiConfig)
+	if err != nil {
+		return nil, err
+	}
+	s3Prober := &s3probe.Prober{Client: apiClient, Concurrency: config.CrawlConcurrency, Delay: config.CrawlDelay, Endpoint: config.S3Endpoint}
+	checker := &takeover.Checker{
+		Client:      apiClient,
+		Resolver:    &cache.Resolver{Cache: networkCache},
+		S3:          s3Prober,
+		Concurrency: config.CrawlConcurrency,
+	}
+	seen := make(map[string]bool)
+	var warnOnce sync.Once
+
+	return func(ctx context.Context, b pipeline.Batch, emit pipeline.Emit) error {
+		var fresh []int
+		for i, f := range b.Findings {
+			if !seen[f.Key()] {
+				seen[f.Key()] = true
+				fresh = append(fresh, i)
+			}
+		}
+		checked := make([]finding.Finding, len(fresh))
+		for i, idx := range fresh {
+			checked[i] = b.Findings[idx]
+		}
+
+		if config.Enrich && ctx.Err() == nil {
+			enricher.Enrich(ctx, checked)
+		}
+		if config.Probe && ctx.Err() == nil {
+			prober.Probe(ctx, checked)
+		}
+		if config.ProbeS3 && ctx.Err() == nil {
+			s3Prober.Probe(ctx, checked)
+		}
+		var candidates []finding.Finding
+		if config.Takeover && ctx.Err() == nil {
+			candidates = checker.Check(ctx, checked)
+		}
+		if err := ctx.Err(); err != nil {
+			// Running out of enrichment time keeps the findings, just without the details
+			if !pipeline.TimedOut(ctx) {
+				return fmt.Errorf("enrichment failed: %w", err)
+			}
+			warnOnce.Do(func() {
+				fmt.Fprintf(os.Stderr, "Warning: %v; the remaining findings were not enriched or probed\n", context.Cause(ctx))
+			})
+		}
+		for i, idx := range fresh {
+			b.Findings[idx] = checked[i]
+		}
+		// Repeated URLs were not probed again; dropping them is safe because the output keeps
+		// the first occurrence, which carries the probe result
+		if config.Probe && config.OnlyAlive {
+			b.Findings = filter.Apply(b.Findings, filter.OnlyAlive())
+		}
+		if len(candidates) > 0 {
+			b.Findings = append(b.Findings, candidates...)
+		}
+		if config.Takeover && len(config.Only) > 0 {
+			b.Findings = filter.Apply(b.Findings, filter.Types(config.Only...))
+		}
+		return emit(b)
+	}, nil
+}
@@ -0,0 +1,381 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRun_Crawl(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<p>Contact security@target.com</p>")
+	}))
+	defer srv.Close()
+
+	tmpfile, err := os.CreateTemp("", "crawl*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	if _, err := tmpfile.WriteString(srv.URL + "/about\n"); err != nil {
+		t.Fatal(err)
+	}
+	tmpfile.Close()
+
+	oldArgs := os.Args
+	oldFlagCommandLine := flag.CommandLine
+	oldStdout := os.Stdout
+	defer func() {
+		os.Args = oldArgs
+		flag.CommandLine = oldFlagCommandLine
+		os.Stdout = oldStdout
+	}()
+
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	os.Args = []string{"cmd", "-emails", "-silent", "-crawl-depth", "1", "-crawl-delay", "0", "-file", tmpfile.Name()}
+
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err = run(context.Background())
+	w.Close()
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	output := buf.String()
+	if !strings.Contains(output, "security@target.com") {
+		t.Errorf("output should contain crawled email, got %q", output)
+	}
+	if strings.Contains(output, srv.URL) {
+		t.Errorf("output should not contain URLs unless -urls is set, got %q", output)
+	}
+}
+
+func TestRun_CrawlSecurityHeaders(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Security-Policy", "default-src 'self'; connect-src https://api.target.com; script-src 'self' 'unsafe-eval'")
+		fmt.Fprint(w, "<p>Welcome</p>")
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "urls.txt")
+	if err := os.WriteFile(path, []byte(srv.URL+"/\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	oldArgs := os.Args
+	oldFlagCommandLine := flag.CommandLine
+	oldStdout := os.Stdout
+	defer func() {
+		os.Args = oldArgs
+		flag.CommandLine = oldFlagCommandLine
+		os.Stdout = oldStdout
+	}()
+
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	os.Args = []string{"cmd", "-domains", "-security-headers", "-crawl-depth", "1", "-crawl-delay", "0", "-file", path}
+
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := run(context.Background())
+	w.Close()
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	output := buf.String()
+	for _, want := range []string{"api.target.com", "Security Header Issues:", srv.URL + " CSP script-src allows 'unsafe-eval'"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output should contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestRun_Probe(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ok" {
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	tmpfile, err := os.CreateTemp("", "probe*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	if _, err := fmt.Fprintf(tmpfile, "%s/ok\n%s/missing\n", srv.URL, srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	tmpfile.Close()
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "annotated",
+			args: []string{"-probe"},
+			want: fmt.Sprintf("\nExtracted URLs:\n%s/missing [404, 19 bytes]\n%s/ok [200]\n", srv.URL, srv.URL),
+		},
+		{
+			name: "only alive",
+			args: []string{"-probe", "-only-alive", "-silent"},
+			want: srv.URL + "/ok\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldArgs := os.Args
+			oldFlagCommandLine := flag.CommandLine
+			oldStdout := os.Stdout
+			defer func() {
+				os.Args = oldArgs
+				flag.CommandLine = oldFlagCommandLine
+				os.Stdout = oldStdout
+			}()
+
+			flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			os.Args = append([]string{"cmd", "-file", tmpfile.Name()}, tt.args...)
+
+			r, w, _ := os.Pipe()
+			os.Stdout = w
+
+			err := run(context.Background())
+			w.Close()
+			var buf bytes.Buffer
+			buf.ReadFrom(r)
+
+			if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun_ProbeTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	input := filepath.Join(t.TempDir(), "urls.txt")
+	if err := os.WriteFile(input, []byte(srv.URL+"/slow\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	oldArgs := os.Args
+	oldFlagCommandLine := flag.CommandLine
+	oldStdout := os.Stdout
+	oldStderr := os.Stderr
+	defer func() {
+		os.Args = oldArgs
+		flag.CommandLine = oldFlagCommandLine
+		os.Stdout = oldStdout
+		os.Stderr = oldStderr
+	}()
+
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	os.Args = []string{"cmd", "-probe", "-timeout-enrich", "100ms", "-file", input}
+
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	er, ew, _ := os.Pipe()
+	os.Stderr = ew
+
+	start := time.Now()
+	err := run(context.Background())
+	w.Close()
+	ew.Close()
+	var stdout, stderr bytes.Buffer
+	stdout.ReadFrom(r)
+	stderr.ReadFrom(er)
+
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("run() took %s, want the probe to stop after the enrich timeout", elapsed)
+	}
+	if want := "\nExtracted URLs:\n" + srv.URL + "/slow\n"; stdout.String() != want {
+		t.Errorf("output = %q, want %q", stdout.String(), want)
+	}
+	if !strings.Contains(stderr.String(), "enrich stage timed out after 100ms") {
+		t.Errorf("stderr = %q, want timeout warning", stderr.String())
+	}
+}
+
+func TestRun_ProbeS3(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/public-assets/":
+			fmt.Fprint(w, "<ListBucketResult></ListBucketResult>")
+		case "/gone-assets/":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "<Error><Code>NoSuchBucket</Code></Error>")
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, "<Error><Code>AccessDenied</Code></Error>")
+		}
+	}))
+	defer srv.Close()
+
+	input := filepath.Join(t.TempDir(), "app.js")
+	data := "s3://public-assets/logo.png\ns3://locked-assets/db.sql\ns3://gone-assets/old.zip\n"
+	if err := os.WriteFile(input, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	oldArgs := os.Args
+	oldFlagCommandLine := flag.CommandLine
+	oldStdout := os.Stdout
+	defer func() {
+		os.Args = oldArgs
+		flag.CommandLine = oldFlagCommandLine
+		os.Stdout = oldStdout
+	}()
+
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	os.Args = []string{"cmd", "-file", input, "-probe-s3", "-s3-endpoint", srv.URL, "-crawl-delay", "0"}
+
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := run(context.Background())
+	w.Close()
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	want := "\nExtracted Storage Buckets:\ngone-assets [missing]\nlocked-assets [denied]\npublic-assets [public-listable]\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestRun_Takeover(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gone-assets/" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "<Error><Code>NoSuchBucket</Code></Error>")
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	input := filepath.Join(t.TempDir(), "app.js")
+	data := "s3://locked-assets/db.sql\ns3://gone-assets/old.zip\n"
+	if err := os.WriteFile(input, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	oldArgs := os.Args
+	oldFlagCommandLine := flag.CommandLine
+	oldStdout := os.Stdout
+	defer func() {
+		os.Args = oldArgs
+		flag.CommandLine = oldFlagCommandLine
+		os.Stdout = oldStdout
+	}()
+
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	os.Args = []string{"cmd", "-file", input, "-only", "takeovers", "-s3-endpoint", srv.URL, "-crawl-delay", "0"}
+
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := run(context.Background())
+	w.Close()
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	want := "\nExtracted Takeover Candidates:\ns3://gone-assets [aws-s3: bucket does not exist]\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestRun_ProbeCache(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ok" {
+			atomic.AddInt32(&requests, 1)
+		}
+	}))
+	defer srv.Close()
+
+	input := filepath.Join(t.TempDir(), "urls.txt")
+	if err := os.WriteFile(input, []byte(srv.URL+"/ok\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cacheDir := t.TempDir()
+
+	tests := []struct {
+		name         string
+		args         []string
+		wantRequests int32
+	}{
+		{name: "first run", args: []string{"-cache-dir", cacheDir}, wantRequests: 1},
+		{name: "cached", args: []string{"-cache-dir", cacheDir}, wantRequests: 1},
+		{name: "no cache", args: []string{"-cache-dir", cacheDir, "-no-cache"}, wantRequests: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldArgs := os.Args
+			oldFlagCommandLine := flag.CommandLine
+			oldStdout := os.Stdout
+			defer func() {
+				os.Args = oldArgs
+				flag.CommandLine = oldFlagCommandLine
+				os.Stdout = oldStdout
+			}()
+
+			flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			os.Args = append([]string{"cmd", "-file", input, "-probe", "-silent"}, tt.args...)
+
+			r, w, _ := os.Pipe()
+			os.Stdout = w
+
+			err := run(context.Background())
+			w.Close()
+			var buf bytes.Buffer
+			buf.ReadFrom(r)
+
+			if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if got := atomic.LoadInt32(&requests); got != tt.wantRequests {
+				t.Errorf("server saw %d requests, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/extractor"
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/openapi"
+	"github.com/PeteJStewart/urlsluice/internal/output"
+	"github.com/PeteJStewart/urlsluice/internal/pipeline"
+)
+
+// openAPIConfig limits cfg to the finding types an OpenAPI document yields
+func openAPIConfig(cfg extractor.Config) extractor.Config {
+	return cfg.Only(func(t finding.Type) bool {
+		return t == finding.TypeDomain || t == finding.TypeIP || t == finding.TypeParam || t == finding.TypeURL
+	})
+}
+
+// readOpenAPI reads and parses the -openapi document, recording it in runInfo
+func readOpenAPI(config *Config, runInfo *output.Run) (*openapi.Spec, error) {
+	data, err := os.ReadFile(config.OpenAPIPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading OpenAPI spec: %w", err)
+	}
+	runInfo.AddInput(config.OpenAPIPath, data)
+	spec, err := openapi.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("error reading OpenAPI spec %s: %w", config.OpenAPIPath, err)
+	}
+	return spec, nil
+}
+
+// openAPISource emits the servers, endpoints and parameters of the -openapi document as
+// findings, so they share the filters and output of extracted findings
+func openAPISource(config *Config, runInfo *output.Run) pipeline.Source {
+	return func(ctx context.Context, emit pipeline.Emit) error {
+		spec, err := readOpenAPI(config, runInfo)
+		if err != nil {
+			return err
+		}
+		findings := openAPIFindings(spec, openAPIConfig(newExtractors(config).base))
+		return emit(pipeline.Batch{Source: config.OpenAPIPath, Findings: withSource(findings, config.OpenAPIPath)})
+	}
+}
+
+// openAPIFindings reports the hosts of the servers, the URL of every endpoint on every
+// server, with example parameter values filled in, and every parameter as "name=example"
+func openAPIFindings(spec *openapi.Spec, cfg extractor.Config) []finding.Finding {
+	var findings []finding.Finding
+	servers := spec.Servers
+	if len(servers) == 0 {
+		// Without servers, endpoints are reported as paths
+		servers = []string{""}
+	}
+	for _, server := range spec.Servers {
+		u, err := url.Parse(server)
+		if err != nil || u.Hostname() == "" {
+			continue
+		}
+		host := u.Hostname()
+		if ip := net.ParseIP(host); ip != nil {
+			if cfg.ExtractIPs && ip.To4() != nil {
+				findings = append(findings, finding.Finding{Type: finding.TypeIP, Value: host, Confidence: finding.ConfidenceHigh})
+			}
+		} else if cfg.ExtractDomains {
+			findings = append(findings, finding.Finding{Type: finding.TypeDomain, Value: host, Confidence: finding.ConfidenceHigh})
+		}
+	}
+
+	for _, e := range spec.Endpoints {
+		if cfg.ExtractURLs {
+			for _, server := range servers {
+				f := finding.Finding{Type: finding.TypeURL, Value: e.URL(server), Confidence: finding.ConfidenceHigh}
+				f.SetMeta("path_template", e.Path)
+				f.SetMeta("methods", strings.Join(e.Methods, ","))
+				findings = append(findings, f)
+			}
+		}
+		if cfg.ExtractParams {
+			for _, p := range e.Params {
+				f := finding.Finding{Type: finding.TypeParam, Value: p.Name + "=" + p.Example, Confidence: finding.ConfidenceHigh}
+				f.SetMeta("in", p.In)
+				f.SetMeta("path_template", e.Path)
+				findings = append(findings, f)
+			}
+		}
+	}
+	return findings
+}
+
+// openAPIWordlistURLs returns a URL per endpoint and server carrying every parameter name
+// and example in its query, for -wordlist
+func openAPIWordlistURLs(spec *openapi.Spec) []string {
+	servers := spec.Servers
+	if len(servers) == 0 {
+		servers = []string{""}
+	}
+	unbrace := strings.NewReplacer("{", "", "}", "")
+	var urls []string
+	for _, e := range spec.Endpoints {
+		query := url.Values{}
+		for _, p := range e.Params {
+			query.Add(p.Name, p.Example)
+		}
+		for _, server := range servers {
+			u := strings.TrimSuffix(server, "/") + unbrace.Replace(e.Path)
+			if len(query) > 0 {
+				u += "?" + query.Encode()
+			}
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
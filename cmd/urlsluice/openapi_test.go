@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testSpec = `openapi: 3.0.0
+servers:
+  - url: https://api.target.com/v2
+paths:
+  /users/{userId}/orders:
+    get:
+      parameters:
+        - {name: userId, in: path, example: 7}
+        - {name: status, in: query, schema: {enum: [open, closed]}}
+  /admin/export:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              properties:
+                format: {type: string}
+`
+
+func TestRunOpenAPI(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spec.yaml")
+	if err := os.WriteFile(path, []byte(testSpec), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantOutput string
+		wantErr    string
+	}{
+		{
+			name:       "endpoints and params",
+			args:       []string{"-openapi", path, "-domains", "-urls", "-queryParams", "-silent"},
+			wantOutput: "api.target.com\nformat=\nstatus=open\nuserId=7\nhttps://api.target.com/v2/admin/export\nhttps://api.target.com/v2/users/7/orders?status=open\n",
+		},
+		{
+			name:       "wordlist",
+			args:       []string{"-openapi", path, "-wordlist"},
+			wantOutput: "admin\nexport\nformat\nopen\norders\nstatus\nuserid\nusers\n",
+		},
+		{
+			name:    "not a spec",
+			args:    []string{"-openapi", "openapi_test.go", "-urls"},
+			wantErr: "error reading OpenAPI spec",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldArgs := os.Args
+			oldStdout := os.Stdout
+			oldFlagCommandLine := flag.CommandLine
+			defer func() {
+				os.Args = oldArgs
+				os.Stdout = oldStdout
+				flag.CommandLine = oldFlagCommandLine
+			}()
+			flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			os.Args = append([]string{"cmd"}, tt.args...)
+
+			r, w, _ := os.Pipe()
+			os.Stdout = w
+
+			err := run(context.Background())
+			w.Close()
+			var out bytes.Buffer
+			out.ReadFrom(r)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("run() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if out.String() != tt.wantOutput {
+				t.Errorf("output = %q, want %q", out.String(), tt.wantOutput)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+// -params-mode values selecting which part of each query parameter is reported
+const (
+	paramsPairs  = "pairs"
+	paramsKeys   = "keys"
+	paramsValues = "values"
+)
+
+// parseParamsMode validates a -params-mode value
+func parseParamsMode(s string) (string, error) {
+	switch mode := strings.ToLower(strings.TrimSpace(s)); mode {
+	case paramsPairs, paramsKeys, paramsValues:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid -params-mode %q: must be %s, %s or %s", s, paramsPairs, paramsKeys, paramsValues)
+}
+
+// paramParts replaces each parameter finding with its key or value, according to mode, and
+// merges the findings that become equal. The part is also recorded in the "key" or "value"
+// metadata so structured output does not split it again. Parameters with an empty value are
+// dropped in values mode.
+func paramParts(findings []finding.Finding, mode string) []finding.Finding {
+	if mode == "" || mode == paramsPairs {
+		return findings
+	}
+	set := &finding.Set{}
+	for _, f := range findings {
+		if f.Type != finding.TypeParam {
+			set.Add(f)
+			continue
+		}
+		key, value, _ := strings.Cut(f.Value, "=")
+		meta := make(map[string]string, len(f.Metadata)+1)
+		for k, v := range f.Metadata {
+			meta[k] = v
+		}
+		switch mode {
+		case paramsKeys:
+			f.Value, meta["key"] = key, key
+		case paramsValues:
+			if value == "" {
+				continue
+			}
+			f.Value, meta["value"] = value, value
+		}
+		f.Metadata = meta
+		set.Add(f)
+	}
+	return set.Findings()
+}
@@ -0,0 +1,540 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/PeteJStewart/urlsluice/internal/audit"
+	"github.com/PeteJStewart/urlsluice/internal/avatars"
+	"github.com/PeteJStewart/urlsluice/internal/classify"
+	"github.com/PeteJStewart/urlsluice/internal/decode"
+	"github.com/PeteJStewart/urlsluice/internal/extractor"
+	"github.com/PeteJStewart/urlsluice/internal/filter"
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/grep"
+	"github.com/PeteJStewart/urlsluice/internal/httpclient"
+	"github.com/PeteJStewart/urlsluice/internal/linecache"
+	"github.com/PeteJStewart/urlsluice/internal/output"
+	"github.com/PeteJStewart/urlsluice/internal/pipeline"
+	"github.com/PeteJStewart/urlsluice/internal/redact"
+	"github.com/PeteJStewart/urlsluice/internal/tracking"
+	"github.com/PeteJStewart/urlsluice/internal/transform"
+	"github.com/PeteJStewart/urlsluice/internal/uuids"
+	"github.com/PeteJStewart/urlsluice/internal/xref"
+)
+
+// process runs the batches produced by source through the decode, extract, crawl, filter and
+// enrich stages and writes the collected findings. runInfo is completed and included in
+// structured output. With -stats the stage timings are written to stderr.
+func process(ctx context.Context, config *Config, runInfo *output.Run, source pipeline.Source) error {
+	if config.LowMemory {
+		return processStream(ctx, config, runInfo, source)
+	}
+	stages, finish, err := newStages(config)
+	if err != nil {
+		return err
+	}
+
+	stats := &pipeline.Stats{}
+	findings := &finding.Set{}
+	if config.Dedupe.Enabled() {
+		findings.KeyFunc = config.Dedupe.KeyFunc()
+	}
+	p := &pipeline.Pipeline{Stats: stats, ReadTimeout: config.TimeoutRead}
+	err = p.Run(ctx, source, stages, func(b pipeline.Batch) error {
+		for _, f := range b.Findings {
+			findings.Add(f)
+		}
+		return nil
+	})
+	if err == nil {
+		err = finish()
+	}
+	if err == nil {
+		err = stats.Measure("output", func() error {
+			all := transform.Apply(paramParts(findings.Findings(), config.ParamsMode), config.TransformRules)
+			if config.AvatarCorrelate {
+				avatars.Correlate(all)
+			}
+			return report(config, all, runInfo)
+		})
+	}
+	if config.Stats {
+		stats.Write(os.Stderr)
+	}
+	return err
+}
+
+// stdinPath is the -file value that reads standard input
+const stdinPath = "-"
+
+// stdinSource identifies standard input as the source of findings
+const stdinSource = "stdin"
+
+// validateInputs checks the -file and -url inputs: standard input can be read only once and
+// URLs must be absolute HTTP(S) URLs
+func validateInputs(config *Config) error {
+	stdin := 0
+	for _, path := range config.FilePaths {
+		if path == stdinPath {
+			stdin++
+		}
+	}
+	if stdin > 1 {
+		return fmt.Errorf("-file %s can only be given once", stdinPath)
+	}
+	for _, raw := range config.URLs {
+		if u, err := url.Parse(raw); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid -url %q: must be an absolute http or https URL", raw)
+		}
+	}
+	return nil
+}
+
+// textSource returns the source for the -file and -url inputs, in that order
+func textSource(config *Config, runInfo *output.Run) pipeline.Source {
+	return func(ctx context.Context, emit pipeline.Emit) error {
+		if err := fileSource(config, runInfo)(ctx, emit); err != nil {
+			return err
+		}
+		return urlSource(config, runInfo)(ctx, emit)
+	}
+}
+
+// fileSource reads the -file inputs, "-" being standard input, and records them in runInfo
+func fileSource(config *Config, runInfo *output.Run) pipeline.Source {
+	return func(ctx context.Context, emit pipeline.Emit) error {
+		for _, path := range config.FilePaths {
+			if config.LowMemory {
+				if err := streamFile(ctx, config, runInfo, path, emit); err != nil {
+					return err
+				}
+				continue
+			}
+			source, data, err := readInput(path)
+			if err != nil {
+				return fmt.Errorf("error reading file: %w", err)
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			runInfo.AddInput(source, data)
+			if err := emit(pipeline.Batch{Source: source, Data: data}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// readInput reads the -file input at path, returning the source name its findings are
+// attributed to
+func readInput(path string) (string, []byte, error) {
+	if path == stdinPath {
+		data, err := io.ReadAll(os.Stdin)
+		return stdinSource, data, err
+	}
+	data, err := os.ReadFile(path)
+	return path, data, err
+}
+
+// urlSource fetches the -url inputs and records the response bodies in runInfo. Pages that
+// cannot be fetched fail the run, like missing files.
+func urlSource(config *Config, runInfo *output.Run) pipeline.Source {
+	return func(ctx context.Context, emit pipeline.Emit) error {
+		if len(config.URLs) == 0 {
+			return nil
+		}
+		client, err := newHTTPClient(config)
+		if err != nil {
+			return fmt.Errorf("error creating HTTP client: %w", err)
+		}
+		for _, u := range config.URLs {
+			if config.LowMemory {
+				if err := streamURL(ctx, config, runInfo, client, u, emit); err != nil {
+					return fmt.Errorf("error fetching %s: %w", u, err)
+				}
+				continue
+			}
+			data, err := fetchInput(ctx, client, u)
+			if err != nil {
+				return fmt.Errorf("error fetching %s: %w", u, err)
+			}
+			runInfo.AddInput(u, data)
+			if err := emit(pipeline.Batch{Source: u, Data: data}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// fetchInput returns the body of the page at rawURL, failing on error statuses
+func fetchInput(ctx context.Context, client *httpclient.Client, rawURL string) ([]byte, error) {
+	body, err := openInput(ctx, client, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
+// openInput requests the page at rawURL and returns its body, failing on error statuses
+func openInput(ctx context.Context, client *httpclient.Client, rawURL string) (io.ReadCloser, error) {
+	resp, err := client.Get(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// newStages returns the pipeline stages enabled by config and a function completing work
+// that needs every batch, such as the out-of-scope report, to be called after the pipeline ran
+func newStages(config *Config) ([]pipeline.Stage, func() error, error) {
+	exts := newExtractors(config)
+	stages := []pipeline.Stage{{Name: "decode", Process: decodeStage(config.Encoding, config.Strings)}}
+	if config.LineFilter != nil {
+		stages = append(stages, pipeline.Stage{Name: "grep", Process: grepStage(config.LineFilter)})
+	}
+	var lines *linecache.Cache
+	if config.DedupeLines > 0 {
+		lines = linecache.New(config.DedupeLines)
+		stages = append(stages, pipeline.Stage{Name: "dedupe-lines", Process: dedupeLinesStage(lines)})
+	}
+	stages = append(stages, pipeline.Stage{Name: "extract", Timeout: config.TimeoutExtract, Process: extractStage(exts)})
+	if config.CrawlDepth > 0 {
+		crawlStage, err := newCrawlStage(config, exts)
+		if err != nil {
+			return nil, nil, err
+		}
+		stages = append(stages, pipeline.Stage{Name: "crawl", Process: crawlStage})
+	}
+
+	filterStage, finish, err := newFilterStage(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	stages = append(stages, pipeline.Stage{Name: "filter", Process: filterStage})
+	if lines != nil && config.Stats {
+		filterFinish := finish
+		finish = func() error {
+			seen, skipped := lines.Counts()
+			fmt.Fprintf(os.Stderr, "dedupe-lines: skipped %d of %d lines\n", skipped, seen)
+			return filterFinish()
+		}
+	}
+
+	if config.Enrich || config.Probe || config.ProbeS3 || config.Takeover {
+		enrichStage, err := newEnrichStage(config)
+		if err != nil {
+			return nil, nil, err
+		}
+		stages = append(stages, pipeline.Stage{Name: "enrich", Timeout: config.TimeoutEnrich, Process: enrichStage})
+	}
+	return stages, finish, nil
+}
+
+// decodeStage converts the raw input from enc, or the detected encoding, to UTF-8 text.
+// When the encoding is detected, binary input is skipped with a warning or, with strs,
+// reduced to its printable strings.
+func decodeStage(enc decode.Encoding, strs bool) func(context.Context, pipeline.Batch, pipeline.Emit) error {
+	return func(ctx context.Context, b pipeline.Batch, emit pipeline.Emit) error {
+		switch {
+		case b.Data == nil, b.Decoded:
+		case (enc == decode.Auto || enc == "") && decode.Binary(b.Data):
+			if strs {
+				b.Data = decode.Strings(b.Data, decode.MinStringLength)
+				break
+			}
+			fmt.Fprintf(os.Stderr, "Warning: skipping binary input %s; use -strings to extract its printable text\n", b.Source)
+			b.Data = nil
+		default:
+			b.Data = decode.Convert(b.Data, enc)
+		}
+		return emit(b)
+	}
+}
+
+// dedupeLinesStage blanks the input lines seen recently, keeping line numbers intact. The
+// cache is shared by every input, so a line repeated across files is extracted once.
+func dedupeLinesStage(lines *linecache.Cache) func(context.Context, pipeline.Batch, pipeline.Emit) error {
+	return func(ctx context.Context, b pipeline.Batch, emit pipeline.Emit) error {
+		if b.Data != nil {
+			b.Data = lines.Blank(b.Data)
+		}
+		return emit(b)
+	}
+}
+
+// grepStage blanks the input lines rejected by -grep and -vgrep, keeping line numbers intact
+func grepStage(lines *grep.Filter) func(context.Context, pipeline.Batch, pipeline.Emit) error {
+	return func(ctx context.Context, b pipeline.Batch, emit pipeline.Emit) error {
+		if b.Data != nil {
+			b.Data = lines.Blank(b.Data)
+		}
+		return emit(b)
+	}
+}
+
+// extractStage runs the extractors that apply to each input, replacing its data with the
+// findings, numbered by their line in the whole input. Batches without data, such as CT
+// lookups, are passed on unchanged.
+func extractStage(exts *extractors) func(context.Context, pipeline.Batch, pipeline.Emit) error {
+	return func(ctx context.Context, b pipeline.Batch, emit pipeline.Emit) error {
+		if b.Data == nil {
+			return emit(b)
+		}
+		name := b.Source
+		if strings.HasPrefix(name, "http://") || strings.HasPrefix(name, "https://") {
+			name = pagePath(name)
+		}
+		ext, err := exts.forPath(name)
+		if err != nil {
+			return err
+		}
+		results, err := ext.Extract(ctx, bytes.NewReader(b.Data))
+		if err != nil {
+			return fmt.Errorf("extraction failed: %w", err)
+		}
+		b.Data = nil
+		b.Findings = withSource(results.Findings, b.Source)
+		if b.Line > 1 {
+			for i := range b.Findings {
+				if b.Findings[i].Line > 0 {
+					b.Findings[i].Line += b.Line - 1
+				}
+			}
+		}
+		return emit(b)
+	}
+}
+
+// newFilterStage classifies findings and drops those rejected by the scope and filters
+func newFilterStage(config *Config) (func(context.Context, pipeline.Batch, pipeline.Emit) error, func() error, error) {
+	var correlator *uuids.Correlator
+	if config.UUIDNames != "" {
+		var err error
+		if correlator, err = newUUIDCorrelator(config); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	filters := []filter.Func{
+		filter.MinConfidence(config.MinConfidence),
+		filter.TLDs(config.TLDs, config.ExcludeTLDs, config.IncludeReserved),
+	}
+	if !config.ExtractURLs {
+		filters = append(filters, filter.ExcludeTypes(finding.TypeURL))
+	}
+	if len(config.Only) > 0 {
+		only := config.Only
+		// Takeover candidates are derived from domains and buckets in the enrich stage, which
+		// applies -only again once they are checked
+		if config.Takeover {
+			only = append(only[:len(only):len(only)], finding.TypeDomain, finding.TypeBucket)
+		}
+		filters = append(filters, filter.Types(only...))
+	}
+	if config.OnlyInternal {
+		filters = append(filters, filter.Tagged(finding.TagInternal))
+	}
+	if len(config.Tags) > 0 {
+		filters = append(filters, filter.Tagged(config.Tags...))
+	}
+	if config.Suppressions != nil {
+		filters = append(filters, func(f finding.Finding) bool { return !config.Suppressions.Suppressed(f) })
+		if expired := config.Suppressions.Expired(); len(expired) > 0 {
+			fmt.Fprintf(os.Stderr, "Warning: expired entries in %s no longer suppress findings (%d)\n", config.SuppressFile, len(expired))
+		}
+	}
+
+	tracked := trackingParams(config)
+	dropped := &finding.Set{}
+	stage := func(ctx context.Context, b pipeline.Batch, emit pipeline.Emit) error {
+		if config.GroupBy == groupByHost {
+			// Attribute findings to the URLs on their line before unrequested URLs are dropped
+			b.Findings = output.WithHosts(b.Findings)
+		}
+		nameErrorPages(b.Findings)
+		if tracked != nil {
+			tracked.Separate(b.Findings)
+		}
+		classify.Internal(b.Findings)
+		classify.Tag(b.Findings, config.TagRules)
+		if config.XrefIndex != nil {
+			// Cross-references include out-of-scope findings, e.g. emails of other organizations
+			config.XrefIndex.Add(b.Findings)
+		}
+		if config.UUIDDetect {
+			uuids.MarkTimeBased(b.Findings)
+		}
+		if correlator != nil {
+			correlator.Correlate(b.Findings)
+		}
+		if config.Scope != nil {
+			var out []finding.Finding
+			b.Findings, out = config.Scope.Split(b.Findings)
+			for _, f := range out {
+				dropped.Add(f)
+			}
+		}
+		b.Findings = filter.Apply(b.Findings, filters...)
+		return emit(b)
+	}
+	finish := func() error {
+		if config.OutOfScopeReport == "" {
+			return nil
+		}
+		findings := dropped.Findings()
+		if config.Redact {
+			findings = redact.Findings(findings)
+		}
+		return writeOutOfScopeReport(config.OutOfScopeReport, findings)
+	}
+	return stage, finish, nil
+}
+
+// nameErrorPages names verbose errors after the page they were found on, recorded in the "url"
+// metadata: the URL of fetched, crawled and -traffic responses, or else the input file
+func nameErrorPages(findings []finding.Finding) {
+	for i := range findings {
+		f := &findings[i]
+		if f.Type != finding.TypeVerboseError || f.Source == "" {
+			continue
+		}
+		page := f.Source
+		// -traffic bodies are named "traffic.har!URL"
+		if _, u, ok := strings.Cut(page, "!"); ok && isHTTPURL(u) {
+			page = u
+		}
+		if isHTTPURL(page) {
+			f.SetMeta("url", page)
+		}
+		f.Value = page + " " + f.Metadata["framework"]
+	}
+}
+
+// isHTTPURL reports whether s starts like an HTTP or HTTPS URL
+func isHTTPURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+// trackingParams returns the built-in tracking parameters extended with the tracking_params
+// of the configuration file, or nil with -keep-tracking-params
+func trackingParams(config *Config) *tracking.List {
+	if config.KeepTrackingParams {
+		return nil
+	}
+	if config.Settings != nil {
+		return tracking.Default().With(config.Settings.TrackingParams)
+	}
+	return tracking.Default()
+}
+
+// withSource attributes findings to source
+func withSource(findings []finding.Finding, source string) []finding.Finding {
+	for i := range findings {
+		findings[i].Source = source
+	}
+	return findings
+}
+
+// report writes the findings in the format selected by config
+func report(config *Config, findings []finding.Finding, runInfo *output.Run) error {
+	// Summaries such as the UUID version distribution describe every finding, not just the reported ones
+	all := findings
+	if config.AuditLog != "" {
+		if err := audit.Append(config.AuditLog, audit.NewRunID(), runInfo.StartedAt, all); err != nil {
+			return fmt.Errorf("error writing audit log: %w", err)
+		}
+	}
+	findings, truncated := output.Limit(findings, config.MaxPerCategory)
+	if config.Redact {
+		// Redacted after the audit log, which keeps the values the hashes are matched against
+		findings = redact.Findings(findings)
+	}
+
+	if config.Export != "" {
+		if err := writeExport(os.Stdout, config, findings); err != nil {
+			return err
+		}
+		return output.WriteTruncated(os.Stderr, truncated, config.MaxPerCategory)
+	}
+	if config.JSON {
+		runInfo.Truncated = truncated
+		runInfo.FinishedAt = time.Now().UTC()
+		doc := output.Document{Run: runInfo, Findings: remediationCatalog(config).Apply(findings)}
+		if config.GroupBy == groupByHost {
+			doc.Findings = output.WithHosts(doc.Findings)
+		}
+		if config.UniqueValues {
+			doc.Values = output.UniqueValues(findings)
+		}
+		if config.XrefIndex != nil {
+			report := crossReference(config)
+			doc.Xref = &report
+		}
+		return output.WriteDocument(os.Stdout, doc)
+	}
+	if config.UniqueValues {
+		if err := output.WriteUniqueValues(os.Stdout, output.UniqueValues(findings), textOptions(config)); err != nil {
+			return err
+		}
+	} else if config.GroupBy == groupByHost {
+		if err := output.WriteTextByHost(os.Stdout, findings, textOptions(config)); err != nil {
+			return err
+		}
+	} else if config.APKPath != "" || config.TrafficPath != "" {
+		// Findings of app packages and recorded traffic are grouped by the entry they came from
+		if err := output.WriteTextBySource(os.Stdout, findings, textOptions(config)); err != nil {
+			return err
+		}
+	} else if err := printResults(extractor.Results{Findings: findings}, textOptions(config)); err != nil {
+		return err
+	}
+	if config.Silent {
+		// Keep stdout to bare values for piping
+		return output.WriteTruncated(os.Stderr, truncated, config.MaxPerCategory)
+	}
+	if err := output.WriteTruncated(os.Stdout, truncated, config.MaxPerCategory); err != nil {
+		return err
+	}
+	if config.XrefIndex != nil {
+		if err := output.WriteXref(os.Stdout, crossReference(config), textOptions(config)); err != nil {
+			return err
+		}
+	}
+	if config.UUIDDetect {
+		if err := printUUIDVersions(os.Stdout, all); err != nil {
+			return err
+		}
+	}
+	if config.Timestamps {
+		return printTimestampRanges(os.Stdout, all)
+	}
+	return nil
+}
+
+// crossReference returns the cross-references of the findings collected with -xref, checking
+// hosts against the scope file when one is given, masked with -redact
+func crossReference(config *Config) xref.Report {
+	var inScope func(string) bool
+	if config.Scope != nil {
+		inScope = config.Scope.Host
+	}
+	report := config.XrefIndex.Report(inScope)
+	if config.Redact {
+		report = redact.Report(report)
+	}
+	return report
+}
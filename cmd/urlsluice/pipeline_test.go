@@ -0,0 +1,429 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PeteJStewart/urlsluice/internal/output"
+)
+
+func TestRun_Stats(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "urls.txt")
+	// UTF-16LE with a byte order mark, as written by PowerShell redirection
+	content := []byte{0xFF, 0xFE}
+	for _, c := range "https://api.target.com/\n" {
+		content = append(content, byte(c), 0)
+	}
+	if err := os.WriteFile(input, content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	oldArgs := os.Args
+	oldFlagCommandLine := flag.CommandLine
+	oldStdout := os.Stdout
+	oldStderr := os.Stderr
+	defer func() {
+		os.Args = oldArgs
+		flag.CommandLine = oldFlagCommandLine
+		os.Stdout = oldStdout
+		os.Stderr = oldStderr
+	}()
+
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	os.Args = []string{"cmd", "-domains", "-silent", "-stats", "-file", input}
+
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	er, ew, _ := os.Pipe()
+	os.Stderr = ew
+
+	err := run(context.Background())
+	w.Close()
+	ew.Close()
+	var stdout, stderr bytes.Buffer
+	stdout.ReadFrom(r)
+	stderr.ReadFrom(er)
+
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if got := stdout.String(); got != "api.target.com\n" {
+		t.Errorf("output = %q, want %q", got, "api.target.com\n")
+	}
+	var stages []string
+	for _, line := range strings.Split(strings.TrimSpace(stderr.String()), "\n")[1:] {
+		stages = append(stages, strings.Fields(line)[0])
+	}
+	if want := "read decode extract filter output"; strings.Join(stages, " ") != want {
+		t.Errorf("stats stages = %v, want %s\n%s", stages, want, stderr.String())
+	}
+}
+
+func TestRun_MaxPerCategory(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "urls.txt")
+	content := "https://a.target.com/\nhttps://b.target.com/\nhttps://c.target.com/\ndev@target.com\n"
+	if err := os.WriteFile(input, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantStdout string
+		wantStderr string
+	}{
+		{
+			name:       "text",
+			args:       []string{"-domains", "-emails", "-max-per-category", "2"},
+			wantStdout: "\nExtracted Emails:\ndev@target.com\n\nExtracted Domains:\na.target.com\nb.target.com\n\nTruncated to 2 per category: 1 more Domains not shown\n",
+		},
+		{
+			name:       "silent",
+			args:       []string{"-domains", "-silent", "-max-per-category", "1"},
+			wantStdout: "a.target.com\n",
+			wantStderr: "\nTruncated to 1 per category: 2 more Domains not shown\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldArgs := os.Args
+			oldFlagCommandLine := flag.CommandLine
+			oldStdout := os.Stdout
+			oldStderr := os.Stderr
+			defer func() {
+				os.Args = oldArgs
+				flag.CommandLine = oldFlagCommandLine
+				os.Stdout = oldStdout
+				os.Stderr = oldStderr
+			}()
+
+			flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			os.Args = append(append([]string{"cmd"}, tt.args...), "-file", input)
+			r, w, _ := os.Pipe()
+			os.Stdout = w
+			er, ew, _ := os.Pipe()
+			os.Stderr = ew
+
+			err := run(context.Background())
+			w.Close()
+			ew.Close()
+			var stdout, stderr bytes.Buffer
+			stdout.ReadFrom(r)
+			stderr.ReadFrom(er)
+
+			if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if stdout.String() != tt.wantStdout {
+				t.Errorf("stdout = %q, want %q", stdout.String(), tt.wantStdout)
+			}
+			if stderr.String() != tt.wantStderr {
+				t.Errorf("stderr = %q, want %q", stderr.String(), tt.wantStderr)
+			}
+		})
+	}
+}
+
+func TestRun_Dedupe(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "page.html")
+	content := `Admin@Target.com admin@target.com
+https://target.com/api/?id=1 https://target.com/api?id=2 https://target.com/docs/ https://target.com/docs
+`
+	if err := os.WriteFile(input, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "exact",
+			args: []string{"-emails", "-queryParams"},
+			want: "Admin@Target.com\nadmin@target.com\nid=1\nid=2\n",
+		},
+		{
+			name: "normalized",
+			args: []string{"-emails", "-queryParams", "-dedupe-case", "emails", "-dedupe-params", "keys-only"},
+			want: "Admin@Target.com\nid=1\n",
+		},
+		{
+			name: "trailing slash",
+			args: []string{"-urls", "-dedupe-trailing-slash"},
+			want: "https://target.com/api/?id=1\nhttps://target.com/api?id=2\nhttps://target.com/docs\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldArgs := os.Args
+			oldFlagCommandLine := flag.CommandLine
+			oldStdout := os.Stdout
+			defer func() {
+				os.Args = oldArgs
+				flag.CommandLine = oldFlagCommandLine
+				os.Stdout = oldStdout
+			}()
+
+			flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			os.Args = append([]string{"cmd", "-uuid", "0", "-silent", "-file", input}, tt.args...)
+
+			r, w, _ := os.Pipe()
+			os.Stdout = w
+
+			err := run(context.Background())
+			w.Close()
+			var buf bytes.Buffer
+			buf.ReadFrom(r)
+
+			if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun_DedupeLines(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "wayback.txt")
+	content := "https://a.target.com/\nhttps://a.target.com/\nhttps://b.target.com/\nhttps://a.target.com/\n"
+	if err := os.WriteFile(input, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantStdout string
+		wantStderr string
+		wantErr    string
+	}{
+		{
+			name:       "skips repeated lines",
+			args:       []string{"-domains", "-silent", "-stats", "-dedupe-lines", "10"},
+			wantStdout: "a.target.com\nb.target.com\n",
+			wantStderr: "dedupe-lines: skipped 2 of 4 lines\n",
+		},
+		{
+			name:    "negative",
+			args:    []string{"-domains", "-dedupe-lines", "-1"},
+			wantErr: "-dedupe-lines must not be negative",
+		},
+		{
+			name:    "structured",
+			args:    []string{"-domains", "-structured", "-dedupe-lines", "10"},
+			wantErr: "-dedupe-lines cannot be used with -structured",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldArgs := os.Args
+			oldFlagCommandLine := flag.CommandLine
+			oldStdout := os.Stdout
+			oldStderr := os.Stderr
+			defer func() {
+				os.Args = oldArgs
+				flag.CommandLine = oldFlagCommandLine
+				os.Stdout = oldStdout
+				os.Stderr = oldStderr
+			}()
+
+			flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			os.Args = append(append([]string{"cmd"}, tt.args...), "-file", input)
+			r, w, _ := os.Pipe()
+			os.Stdout = w
+			er, ew, _ := os.Pipe()
+			os.Stderr = ew
+
+			err := run(context.Background())
+			w.Close()
+			ew.Close()
+			var stdout, stderr bytes.Buffer
+			stdout.ReadFrom(r)
+			stderr.ReadFrom(er)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("run() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if stdout.String() != tt.wantStdout {
+				t.Errorf("stdout = %q, want %q", stdout.String(), tt.wantStdout)
+			}
+			if !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("stderr = %q, want it to contain %q", stderr.String(), tt.wantStderr)
+			}
+			if !strings.Contains(stderr.String(), "\ndedupe-lines ") {
+				t.Errorf("stats do not list the dedupe-lines stage:\n%s", stderr.String())
+			}
+		})
+	}
+}
+
+func TestRun_ParamsMode(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "page.html")
+	content := `https://target.com/api?id=1&next=%2Fhome&debug=
+https://target.com/api?id=2&user=1&next=%2Fhome
+`
+	if err := os.WriteFile(input, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "pairs",
+			args: []string{"-queryParams"},
+			want: "debug=\nid=1\nid=2\nnext=%2Fhome\nuser=1\n",
+		},
+		{
+			name: "keys",
+			args: []string{"-queryParams", "-params-mode", "keys"},
+			want: "debug\nid\nnext\nuser\n",
+		},
+		{
+			name: "decoded values",
+			args: []string{"-decode-params", "-params-mode", "values"},
+			want: "/home\n1\n2\n",
+		},
+		{
+			name: "unique values",
+			args: []string{"-domains", "-decode-params", "-unique-values"},
+			want: "/home\n1\n2\ntarget.com\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldArgs := os.Args
+			oldFlagCommandLine := flag.CommandLine
+			oldStdout := os.Stdout
+			defer func() {
+				os.Args = oldArgs
+				flag.CommandLine = oldFlagCommandLine
+				os.Stdout = oldStdout
+			}()
+
+			flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			os.Args = append([]string{"cmd", "-uuid", "0", "-silent", "-file", input}, tt.args...)
+
+			r, w, _ := os.Pipe()
+			os.Stdout = w
+
+			err := run(context.Background())
+			w.Close()
+			var buf bytes.Buffer
+			buf.ReadFrom(r)
+
+			if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun_MultipleInputs(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`<a href="mailto:web@target.com">contact</a> shared@target.com`))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.txt")
+	second := filepath.Join(dir, "second.txt")
+	if err := os.WriteFile(first, []byte("one@target.com shared@target.com\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(second, []byte("two@target.com\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	oldArgs := os.Args
+	oldFlagCommandLine := flag.CommandLine
+	oldStdin := os.Stdin
+	oldStdout := os.Stdout
+	defer func() {
+		os.Args = oldArgs
+		flag.CommandLine = oldFlagCommandLine
+		os.Stdin = oldStdin
+		os.Stdout = oldStdout
+	}()
+
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	os.Args = []string{"cmd", "-uuid", "0", "-emails", "-json", "-no-cache",
+		"-file", first, "-file", second, "-file", "-", "-url", srv.URL + "/contact"}
+
+	stdinR, stdinW, _ := os.Pipe()
+	stdinW.Write([]byte("piped@target.com\n"))
+	stdinW.Close()
+	os.Stdin = stdinR
+
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := run(context.Background())
+	w.Close()
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	var doc output.Document
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	sources := make(map[string]string)
+	for _, f := range doc.Findings {
+		sources[f.Value] = f.Source
+	}
+	want := map[string]string{
+		"one@target.com":    first,
+		"shared@target.com": first,
+		"two@target.com":    second,
+		"piped@target.com":  "stdin",
+		"web@target.com":    srv.URL + "/contact",
+	}
+	if len(sources) != len(want) {
+		t.Errorf("findings = %v, want %v", sources, want)
+	}
+	for value, source := range want {
+		if sources[value] != source {
+			t.Errorf("%s source = %q, want %q", value, sources[value], source)
+		}
+	}
+	var inputs []string
+	for _, in := range doc.Run.Inputs {
+		inputs = append(inputs, in.Path)
+	}
+	if wantInputs := []string{first, second, "stdin", srv.URL + "/contact"}; strings.Join(inputs, " ") != strings.Join(wantInputs, " ") {
+		t.Errorf("run inputs = %v, want %v", inputs, wantInputs)
+	}
+}
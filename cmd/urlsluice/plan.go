@@ -0,0 +1,368 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/PeteJStewart/urlsluice/internal/decode"
+	"github.com/PeteJStewart/urlsluice/internal/extractor"
+	"github.com/PeteJStewart/urlsluice/internal/output"
+)
+
+// Kinds of files a run reads
+const (
+	// inputFile is a file whose extension selects extractors
+	inputFile = "file"
+	// inputAPK is an app package whose entries are extracted separately
+	inputAPK = "APK"
+	// inputOpenAPI is an OpenAPI or Swagger document turned into findings without extractors
+	inputOpenAPI = "OpenAPI spec"
+	// inputPostman is a Postman export whose requests are extracted separately
+	inputPostman = "Postman export"
+	// inputGraphQL is a GraphQL introspection result turned into findings without extractors
+	inputGraphQL = "GraphQL introspection"
+	// inputTraffic is a HAR file or Burp Suite export whose response bodies are extracted separately
+	inputTraffic = "recorded traffic"
+	// inputStdin is standard input, extracted like a file without an extension
+	inputStdin = "standard input"
+	// inputURL is a page fetched before extraction, whose URL path extension selects extractors
+	inputURL = "page"
+)
+
+// planInput is an input a run would process
+type planInput struct {
+	// Name is the file path or a description of the lookup
+	Name string
+	// Kind is the kind of file Name is, or "" for lookups
+	Kind string
+}
+
+// planInputs returns the -file, -url, -apk, -openapi, -postman, -graphql and -traffic inputs given in config
+func planInputs(config *Config) []planInput {
+	var inputs []planInput
+	for _, path := range config.FilePaths {
+		if path == stdinPath {
+			inputs = append(inputs, planInput{Name: stdinSource, Kind: inputStdin})
+		} else {
+			inputs = append(inputs, planInput{Name: path, Kind: inputFile})
+		}
+	}
+	for _, u := range config.URLs {
+		inputs = append(inputs, planInput{Name: u, Kind: inputURL})
+	}
+	if config.APKPath != "" {
+		inputs = append(inputs, planInput{Name: config.APKPath, Kind: inputAPK})
+	}
+	if config.OpenAPIPath != "" {
+		inputs = append(inputs, planInput{Name: config.OpenAPIPath, Kind: inputOpenAPI})
+	}
+	for _, path := range config.PostmanPaths {
+		inputs = append(inputs, planInput{Name: path, Kind: inputPostman})
+	}
+	if config.GraphQLPath != "" {
+		inputs = append(inputs, planInput{Name: config.GraphQLPath, Kind: inputGraphQL})
+	}
+	if config.TrafficPath != "" {
+		inputs = append(inputs, planInput{Name: config.TrafficPath, Kind: inputTraffic})
+	}
+	return inputs
+}
+
+// writePlan describes what a run with config would do without reading any input or sending
+// any request: the inputs, the extractors enabled for each of them, the stages and filters,
+// and where the output goes
+func writePlan(w io.Writer, config *Config, inputs []planInput) error {
+	var b strings.Builder
+	line := func(format string, args ...interface{}) {
+		fmt.Fprintf(&b, format+"\n", args...)
+	}
+
+	line("Dry run: no input is read and no requests are sent.")
+	line("")
+	switch {
+	case config.GenerateWordlist:
+		line("Mode: wordlist generation from URL lines")
+	case config.DetectRedirects:
+		redirectConfig := "built-in parameters"
+		if config.RedirectConfig != "" {
+			redirectConfig = config.RedirectConfig
+		}
+		line("Mode: open redirect detection (%s)", redirectConfig)
+		if config.DetectXSSParams {
+			line("Mode: XSS parameter detection (%s)", xssConfig(config))
+		}
+	case config.DetectXSSParams:
+		line("Mode: XSS parameter detection (%s)", xssConfig(config))
+	default:
+		line("Mode: extraction")
+	}
+
+	line("Inputs:")
+	for _, in := range inputs {
+		switch in.Kind {
+		case "":
+			line("  %s", in.Name)
+			continue
+		case inputStdin, inputURL:
+			line("  %s (%s)", in.Name, in.Kind)
+			continue
+		}
+		if info, err := os.Stat(in.Name); err != nil {
+			line("  %s (%s, not found)", in.Name, in.Kind)
+		} else {
+			line("  %s (%s, %d bytes)", in.Name, in.Kind, info.Size())
+		}
+	}
+	if config.Settings != nil {
+		line("Config file: %s (sha256 %s)", config.ConfigFile, config.Settings.SHA256)
+	}
+	if config.Profile != "" {
+		line("Profile: %s", config.Profile)
+	}
+	if config.Scope != nil {
+		line("Scope: %s", config.ScopeFile)
+	}
+
+	if !config.GenerateWordlist && !config.DetectRedirects && !config.DetectXSSParams {
+		planExtraction(line, config, inputs)
+	}
+
+	line("Output:")
+	switch {
+	case config.Export != "":
+		line("  %s candidates to stdout", config.Export)
+	case config.JSON && config.UniqueValues:
+		line("  JSON document (schema version %s) with each distinct value to stdout", output.SchemaVersion)
+	case config.JSON:
+		line("  JSON document (schema version %s) to stdout", output.SchemaVersion)
+	case config.UniqueValues:
+		line("  each distinct value once, with its categories, to stdout")
+	case config.Silent:
+		line("  values without titles to stdout")
+	default:
+		line("  text sections to stdout")
+	}
+	if config.GroupBy == groupByHost {
+		line("  grouped by host")
+	}
+	if config.MaxPerCategory > 0 {
+		line("  at most %d findings per category", config.MaxPerCategory)
+	}
+	if config.Xref && config.Scope != nil {
+		line("  cross-references: out-of-scope domains in parameter values and out-of-scope email domains")
+	} else if config.Xref {
+		line("  cross-references: domains in parameter values")
+	}
+	if config.AuditLog != "" {
+		line("  every reported finding appended to the audit log %s", config.AuditLog)
+	}
+	if config.OutOfScopeReport != "" {
+		line("  out-of-scope findings to %s", config.OutOfScopeReport)
+	}
+	if config.Stats {
+		line("  stage statistics to stderr")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// planExtraction describes the extractors, stages and filters of an extraction run
+func planExtraction(line func(string, ...interface{}), config *Config, inputs []planInput) {
+	exts := newExtractors(config)
+	line("Extractors:")
+	for _, in := range inputs {
+		switch {
+		case in.Kind == inputFile || in.Kind == inputStdin:
+			line("  %s: %s", in.Name, typeList(exts.configFor(in.Name)))
+		case in.Kind == inputURL:
+			line("  %s: %s", in.Name, typeList(exts.configFor(pagePath(in.Name))))
+		case in.Kind == inputAPK && config.Settings != nil && len(config.Settings.FileTypes) > 0:
+			line("  %s entries: by entry extension, otherwise %s", in.Name, typeList(exts.base))
+		case in.Kind == inputAPK:
+			line("  %s entries: %s", in.Name, typeList(exts.base))
+		case in.Kind == inputPostman:
+			line("  %s requests and variables: %s", in.Name, typeList(exts.base))
+		case in.Kind == inputGraphQL && exts.base.ExtractParams:
+			line("  %s: operation arguments as params", in.Name)
+		case in.Kind == inputTraffic:
+			line("  %s response bodies: %s", in.Name, typeList(exts.base))
+		case in.Kind == inputOpenAPI:
+			line("  %s: server URLs, endpoints and parameters as %s", in.Name, typeList(openAPIConfig(exts.base)))
+		}
+	}
+	if config.CrawlDepth > 0 {
+		if config.Settings != nil && len(config.Settings.FileTypes) > 0 {
+			line("  crawled pages: by URL path extension, otherwise %s", typeList(exts.base))
+		} else {
+			line("  crawled pages: %s", typeList(exts.base))
+		}
+	}
+
+	if config.DeobfuscateEmails {
+		line("  emails: also \"[at]\", \"(at)\", \"at ... dot\" and HTML entity forms")
+	}
+	if config.DecodeParams {
+		line("  parameters: keys and values percent-decoded")
+	}
+	if config.ParamsMode == paramsKeys || config.ParamsMode == paramsValues {
+		line("  parameters: only the unique %s reported", config.ParamsMode)
+	}
+	if config.ParseURLs {
+		line("  URL lines: parsed with net/url for domains, IPs, parameters and URLs")
+	}
+	if config.Structured {
+		line("  YAML and JSON documents: walked value by value, with the path of each finding")
+	}
+	for _, p := range config.Plugins {
+		run := strings.Join(p.Command, " ")
+		if p.WASM != "" {
+			run = p.WASM
+		}
+		line("  every input: plugin %s (%s)", p.Name, run)
+	}
+	if config.CORS {
+		line("  recorded traffic: reflected origins and wildcard origins with credentials as CORS misconfigurations")
+	}
+	if config.SecurityHeaders {
+		line("  response headers: weak CSP, CORS and HSTS settings as header issues")
+		if config.ExtractDomains {
+			line("  response headers: Content-Security-Policy hosts as domains")
+		}
+	}
+
+	switch {
+	case config.Encoding != "" && config.Encoding != decode.Auto:
+		line("  input encoding: %s", config.Encoding)
+	case config.Strings:
+		line("  binary input: printable strings")
+	}
+	if len(config.Grep) > 0 {
+		line("  input lines: only those matching %s", strings.Join(config.Grep, " or "))
+	}
+	if len(config.VGrep) > 0 {
+		line("  input lines: skipping those matching %s", strings.Join(config.VGrep, " or "))
+	}
+
+	stages := []string{"read", "decode"}
+	if config.LineFilter != nil {
+		stages = append(stages, "grep")
+	}
+	if config.DedupeLines > 0 {
+		stages = append(stages, fmt.Sprintf("dedupe-lines (last %d)", config.DedupeLines))
+	}
+	stages = append(stages, "extract")
+	if config.CrawlDepth > 0 {
+		stages = append(stages, fmt.Sprintf("crawl (depth %d)", config.CrawlDepth))
+	}
+	stages = append(stages, "filter")
+	if config.Enrich || config.Probe || config.ProbeS3 || config.Takeover {
+		var checks []string
+		if config.Enrich {
+			checks = append(checks, "enrich domains")
+		}
+		if config.Probe {
+			checks = append(checks, "probe URLs")
+		}
+		if config.ProbeS3 {
+			checks = append(checks, "probe S3 buckets")
+		}
+		if config.Takeover {
+			checks = append(checks, "check takeover candidates")
+		}
+		stages = append(stages, "enrich ("+strings.Join(checks, ", ")+")")
+	}
+	if len(config.TransformRules) > 0 {
+		stages = append(stages, fmt.Sprintf("transform (%d rules)", len(config.TransformRules)))
+	}
+	stages = append(stages, "output")
+	line("Stages: %s", strings.Join(stages, " -> "))
+	line("Timeouts: read %s, extract %s, enrich %s",
+		timeoutText(config.TimeoutRead), timeoutText(config.TimeoutExtract), timeoutText(config.TimeoutEnrich))
+
+	classifiers := []string{"internal hosts"}
+	if len(config.TagRules) > 0 {
+		classifiers = append(classifiers, fmt.Sprintf("tag rules (%d)", len(config.TagRules)))
+	}
+	if !config.KeepTrackingParams {
+		classifiers = append(classifiers, "tracking parameters")
+	}
+	if config.UUIDDetect {
+		classifiers = append(classifiers, "time-based UUIDs")
+	}
+	if config.UUIDNames != "" {
+		classifiers = append(classifiers, "UUID names from "+config.UUIDNames)
+	}
+	if config.AvatarCorrelate {
+		classifiers = append(classifiers, "avatar hashes of reported emails")
+	}
+	line("Classification: %s", strings.Join(classifiers, ", "))
+
+	line("Filters:")
+	if config.Scope != nil {
+		line("  drop findings outside %s", config.ScopeFile)
+	}
+	line("  minimum confidence %s", config.MinConfidence)
+	if len(config.TLDs) > 0 {
+		line("  keep domains with TLD %s", strings.Join(config.TLDs, ", "))
+	}
+	if len(config.ExcludeTLDs) > 0 {
+		line("  drop domains with TLD %s", strings.Join(config.ExcludeTLDs, ", "))
+	}
+	if !config.IncludeReserved {
+		line("  drop reserved domains (example.com, .test, ...)")
+	}
+	if !config.ExtractURLs && config.CrawlDepth > 0 {
+		line("  drop URLs (extracted for crawling only)")
+	}
+	if len(config.Only) > 0 {
+		var names []string
+		for _, t := range config.Only {
+			names = append(names, string(t))
+		}
+		line("  keep %s findings only", strings.Join(names, ", "))
+	}
+	if config.OnlyInternal {
+		line("  keep internal hosts only")
+	}
+	if len(config.Tags) > 0 {
+		line("  keep findings tagged %s", strings.Join(config.Tags, ", "))
+	}
+	if config.Suppressions != nil {
+		line("  drop findings suppressed by %s", config.SuppressFile)
+	}
+	if config.OnlyAlive {
+		line("  drop URLs that are not alive")
+	}
+}
+
+// typeList names the finding types produced by cfg
+func typeList(cfg extractor.Config) string {
+	types := cfg.Types()
+	if len(types) == 0 {
+		return "none"
+	}
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = string(t)
+	}
+	return strings.Join(names, ", ")
+}
+
+func timeoutText(d time.Duration) string {
+	if d == 0 {
+		return "none"
+	}
+	return d.String()
+}
+
+// xssConfig names the XSS parameter detection configuration used by the run
+func xssConfig(config *Config) string {
+	if config.XSSConfig != "" {
+		return config.XSSConfig
+	}
+	return "built-in sinks and markup patterns"
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWritePlan(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "urlsluice.yaml")
+	content := "tags:\n  - pattern: staging\n    tag: staging\nfile_types:\n  - extensions: [.js]\n    include: [url]\n"
+	if err := os.WriteFile(configPath, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	input := filepath.Join(dir, "bundle.js")
+	if err := os.WriteFile(input, []byte("0123456789"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := parseFlagSet(flag.NewFlagSet("test", flag.ContinueOnError), []string{
+		"-file", input, "-config", configPath, "-emails", "-urls", "-probe", "-only-alive",
+		"-tlds", "com,io", "-timeout-enrich", "30s", "-json", "-stats", "-dry-run",
+	})
+	if err != nil {
+		t.Fatalf("parseFlagSet() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := writePlan(&buf, config, []planInput{{Name: input, Kind: inputFile}, {Name: "Certificate Transparency lookup of target.com"}}); err != nil {
+		t.Fatalf("writePlan() error = %v", err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"Mode: extraction\n",
+		"  " + input + " (file, 10 bytes)\n",
+		"  Certificate Transparency lookup of target.com\n",
+		"Config file: " + configPath + " (sha256 ",
+		"  " + input + ": url\n",
+		"Stages: read -> decode -> extract -> filter -> enrich (probe URLs) -> output\n",
+		"Timeouts: read none, extract none, enrich 30s\n",
+		"Classification: internal hosts, tag rules (1), tracking parameters\n",
+		"  keep domains with TLD com, io\n",
+		"  drop URLs that are not alive\n",
+		"  JSON document (schema version ",
+		"  stage statistics to stderr\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("plan does not contain %q:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/output"
+	"github.com/PeteJStewart/urlsluice/internal/patterns"
+	"github.com/PeteJStewart/urlsluice/internal/pipeline"
+	"github.com/PeteJStewart/urlsluice/internal/postman"
+)
+
+// postmanSource reads the -postman collections and environments. Each request is extracted
+// from as "METHOD URL", headers, body and scripts, with {{variables}} resolved from the
+// collections and, taking precedence, the environments. With -config-secrets, variable values,
+// authorization settings and credential headers are reported as secrets. Batches are named
+// "collection.json!Folder/Request" so findings are attributed to the request they came from.
+func postmanSource(config *Config, runInfo *output.Run) pipeline.Source {
+	return func(ctx context.Context, emit pipeline.Emit) error {
+		docs := make([]*postman.Document, len(config.PostmanPaths))
+		for i, path := range config.PostmanPaths {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("error reading Postman export: %w", err)
+			}
+			runInfo.AddInput(path, data)
+			if docs[i], err = postman.Parse(data); err != nil {
+				return fmt.Errorf("error reading Postman export %s: %w", path, err)
+			}
+		}
+
+		vars := make(map[string]string)
+		for _, environment := range []bool{false, true} {
+			for _, doc := range docs {
+				if doc.Environment == environment {
+					for _, v := range doc.Variables {
+						vars[v.Key] = v.Value
+					}
+				}
+			}
+		}
+
+		secrets := newExtractors(config).base.ExtractSecrets
+		for i, doc := range docs {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			for _, b := range postmanBatches(config.PostmanPaths[i], doc, vars, secrets) {
+				if err := emit(b); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+}
+
+// postmanBatches returns the batches of one document: the secrets found in its settings,
+// followed by the text of its variables, scripts and requests
+func postmanBatches(path string, doc *postman.Document, vars map[string]string, secrets bool) []pipeline.Batch {
+	var batches []pipeline.Batch
+	scope := "collection"
+	if doc.Environment {
+		scope = "environment"
+	}
+	addSecrets := func(source string, findings []finding.Finding) {
+		if secrets && len(findings) > 0 {
+			batches = append(batches, pipeline.Batch{Source: source, Findings: withSource(findings, source)})
+		}
+	}
+	addText := func(source string, lines []string) {
+		if text := strings.TrimSpace(strings.Join(lines, "\n")); text != "" {
+			batches = append(batches, pipeline.Batch{Source: source, Data: []byte(postman.Resolve(text, vars) + "\n")})
+		}
+	}
+
+	source := path + "!variables"
+	var found []finding.Finding
+	var lines []string
+	for _, v := range doc.Variables {
+		if v.Value == "" {
+			continue
+		}
+		value := postman.Resolve(v.Value, vars)
+		confidence := finding.ConfidenceMedium
+		switch {
+		case v.Secret || patterns.SensitiveKeyRegex.MatchString(v.Key):
+			confidence = finding.ConfidenceHigh
+		case strings.Contains(value, "{{") || strings.Contains(value, "://"):
+			// Templates and base URLs are rarely credentials
+			confidence = finding.ConfidenceLow
+		}
+		found = append(found, postmanSecret(v.Key, value, confidence, scope))
+		lines = append(lines, v.Key+"="+v.Value)
+	}
+	addSecrets(source, found)
+	addText(source, lines)
+
+	source = path + "!settings"
+	addSecrets(source, postmanSecrets(doc.Auth, nil, vars))
+	addText(source, doc.Scripts)
+
+	for _, req := range doc.Requests {
+		source := path + "!" + req.Name
+		addSecrets(source, postmanSecrets(req.Auth, req.Headers, vars))
+		lines := []string{strings.TrimSpace(req.Method + " " + req.URL)}
+		for _, h := range req.Headers {
+			lines = append(lines, h.Key+": "+h.Value)
+		}
+		lines = append(lines, req.Body)
+		addText(source, append(lines, req.Scripts...))
+	}
+	return batches
+}
+
+// postmanSecrets reports the credentials among authorization settings and headers
+func postmanSecrets(auth, headers []postman.Pair, vars map[string]string) []finding.Finding {
+	var found []finding.Finding
+	add := func(key, value string) {
+		if value = postman.Resolve(value, vars); value == "" {
+			return
+		}
+		confidence := finding.ConfidenceHigh
+		if strings.Contains(value, "{{") {
+			// The variable is not defined in any of the given files
+			confidence = finding.ConfidenceLow
+		}
+		found = append(found, postmanSecret(key, value, confidence, "request"))
+	}
+	for _, a := range auth {
+		_, setting, _ := strings.Cut(a.Key, ".")
+		if a.Key == "apikey.value" || patterns.SensitiveKeyRegex.MatchString(setting) {
+			add("auth."+a.Key, a.Value)
+		}
+	}
+	for _, h := range headers {
+		if sensitiveHeader(h.Key) {
+			add(h.Key, h.Value)
+		}
+	}
+	return found
+}
+
+func postmanSecret(key, value string, confidence finding.Confidence, scope string) finding.Finding {
+	f := finding.Finding{Type: finding.TypeConfigSecret, Value: key + "=" + value, Confidence: confidence}
+	f.SetMeta("key", key)
+	f.SetMeta("scope", scope)
+	return f
+}
+
+// sensitiveHeader reports whether a request header usually carries a credential
+func sensitiveHeader(name string) bool {
+	switch strings.ToLower(name) {
+	case "authorization", "proxy-authorization", "cookie":
+		return true
+	}
+	return patterns.SensitiveKeyRegex.MatchString(strings.ReplaceAll(name, "-", "_"))
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunPostman(t *testing.T) {
+	dir := t.TempDir()
+	collection := filepath.Join(dir, "api.postman_collection.json")
+	environment := filepath.Join(dir, "prod.postman_environment.json")
+	files := map[string]string{
+		collection: `{
+  "info": {"name": "Target API"},
+  "variable": [{"key": "baseUrl", "value": "https://staging.target.com"}],
+  "item": [{
+    "name": "Users",
+    "request": {
+      "method": "GET",
+      "url": {"raw": "{{baseUrl}}/users?owner=admin@target.com"},
+      "header": [{"key": "Authorization", "value": "Bearer {{token}}"}]
+    }
+  }]
+}`,
+		environment: `{
+  "name": "Production",
+  "values": [
+    {"key": "baseUrl", "value": "https://api.target.com", "enabled": true},
+    {"key": "token", "value": "prod-token-123", "type": "secret", "enabled": true}
+  ]
+}`,
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantOutput []string
+		notWant    string
+		wantErr    string
+	}{
+		{
+			name: "environment overrides collection variables",
+			args: []string{"-postman", collection, "-postman", environment, "-domains", "-emails", "-silent"},
+			// The collection's own baseUrl is still reported from its variables
+			wantOutput: []string{"admin@target.com\napi.target.com\nstaging.target.com\n"},
+		},
+		{
+			name: "secrets",
+			args: []string{"-postman", collection, "-postman", environment, "-config-secrets", "-min-confidence", "medium", "-json"},
+			wantOutput: []string{
+				`"value": "token=prod-token-123"`,
+				`"value": "Authorization=Bearer prod-token-123"`,
+				`"source": "` + collection + `!Users"`,
+			},
+			// Base URLs are low confidence secrets
+			notWant: "baseUrl=",
+		},
+		{
+			name:    "not a postman export",
+			args:    []string{"-postman", "postman_test.go", "-urls"},
+			wantErr: "error reading Postman export",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldArgs := os.Args
+			oldStdout := os.Stdout
+			oldFlagCommandLine := flag.CommandLine
+			defer func() {
+				os.Args = oldArgs
+				os.Stdout = oldStdout
+				flag.CommandLine = oldFlagCommandLine
+			}()
+			flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			os.Args = append([]string{"cmd"}, tt.args...)
+
+			r, w, _ := os.Pipe()
+			os.Stdout = w
+
+			err := run(context.Background())
+			w.Close()
+			var out bytes.Buffer
+			out.ReadFrom(r)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("run() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			for _, want := range tt.wantOutput {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("output should contain %q, got %q", want, out.String())
+				}
+			}
+			if tt.notWant != "" && strings.Contains(out.String(), tt.notWant) {
+				t.Errorf("output should not contain %q, got %q", tt.notWant, out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	configfile "github.com/PeteJStewart/urlsluice/internal/config"
+	"github.com/PeteJStewart/urlsluice/internal/suggest"
+)
+
+// withProfile expands the -profile named in args into the flags it defines, read from the
+// -config file. The profile's flags come first so flags given on the command line override
+// them. Profiles cannot select a config file or another profile.
+func withProfile(fs *flag.FlagSet, args []string) ([]string, error) {
+	path, name := lookupArg(fs, args, "config"), lookupArg(fs, args, "profile")
+	if name == "" {
+		return args, nil
+	}
+	if path == "" {
+		return nil, fmt.Errorf("-profile requires -config")
+	}
+	settings, err := configfile.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("error loading config file: %w", err)
+	}
+	profile, ok := settings.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile %q in %s (profiles: %s)", name, path, strings.Join(settings.ProfileNames(), ", "))
+	}
+	profileArgs, err := profile.Args()
+	if err != nil {
+		return nil, fmt.Errorf("profile %q: %w", name, err)
+	}
+	for _, arg := range profileArgs {
+		flagName, _, _ := strings.Cut(strings.TrimPrefix(arg, "-"), "=")
+		if flagName == "config" || flagName == "profile" || fs.Lookup(flagName) == nil {
+			return nil, fmt.Errorf("profile %q: unknown flag -%s%s", name, flagName, flagHint(fs, flagName))
+		}
+	}
+	return append(profileArgs, args...), nil
+}
+
+// lookupArg returns the last value given to the flag name in args before the flags are
+// parsed. Like fs.Parse, it stops at the first non-flag argument or "--".
+func lookupArg(fs *flag.FlagSet, args []string, name string) string {
+	var found string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" || len(arg) < 2 || arg[0] != '-' {
+			break
+		}
+		flagName, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !hasValue {
+			if f := fs.Lookup(flagName); f != nil && !isBoolFlag(f) && i+1 < len(args) {
+				i++
+				value = args[i]
+			}
+		}
+		if flagName == name {
+			found = value
+		}
+	}
+	return found
+}
+
+// isBoolFlag reports whether f is a boolean flag, which takes no separate value argument
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// flagHint suggests the flag of fs that an unknown flag name was probably meant to be
+func flagHint(fs *flag.FlagSet, name string) string {
+	var names []string
+	fs.VisitAll(func(f *flag.Flag) {
+		if f.Name != "config" && f.Name != "profile" {
+			names = append(names, f.Name)
+		}
+	})
+	if s := suggest.Closest(name, names); s != "" {
+		return fmt.Sprintf(", did you mean -%s?", s)
+	}
+	return ""
+}
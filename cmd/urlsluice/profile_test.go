@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun_Profile(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "urlsluice.yaml")
+	config := `profiles:
+  contacts:
+    emails: true
+    uuid: 0
+    silent: true
+  recon:
+    domains: true
+    uuid: 0
+    silent: true
+    tlds: com
+    vgrep: [staging, internal]
+  broken:
+    emial: true
+`
+	if err := os.WriteFile(configPath, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	input := filepath.Join(dir, "page.html")
+	if err := os.WriteFile(input, []byte("https://api.target.com/ https://cdn.target.io/ ops@target.com\nhttps://staging.target.com/\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "profile",
+			args: []string{"-config", configPath, "-profile", "contacts", "-file", input},
+			want: "ops@target.com\n",
+		},
+		{
+			name: "list flag",
+			args: []string{"-profile=recon", "-file", input, "-config=" + configPath},
+			want: "api.target.com\n",
+		},
+		{
+			name: "command line overrides profile",
+			args: []string{"-config", configPath, "-profile", "recon", "-file", input, "-tlds", "io"},
+			want: "cdn.target.io\n",
+		},
+		{
+			name:    "unknown profile",
+			args:    []string{"-config", configPath, "-profile", "audit", "-file", input},
+			wantErr: `unknown profile "audit"`,
+		},
+		{
+			name:    "unknown flag",
+			args:    []string{"-config", configPath, "-profile", "broken", "-file", input},
+			wantErr: "unknown flag -emial, did you mean -emails?",
+		},
+		{
+			name:    "missing config",
+			args:    []string{"-profile", "recon", "-file", input},
+			wantErr: "-profile requires -config",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldArgs := os.Args
+			oldFlagCommandLine := flag.CommandLine
+			oldStdout := os.Stdout
+			defer func() {
+				os.Args = oldArgs
+				flag.CommandLine = oldFlagCommandLine
+				os.Stdout = oldStdout
+			}()
+
+			flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			flag.CommandLine.SetOutput(io.Discard)
+			os.Args = append([]string{"cmd"}, tt.args...)
+
+			r, w, _ := os.Pipe()
+			os.Stdout = w
+
+			err := run(context.Background())
+			w.Close()
+			var buf bytes.Buffer
+			buf.ReadFrom(r)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("run() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
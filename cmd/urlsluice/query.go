@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/PeteJStewart/urlsluice/internal/output"
+	"github.com/PeteJStewart/urlsluice/internal/query"
+)
+
+// runQuery implements "urlsluice query results.json 'type==domain && value endswith \".dev\"'",
+// printing the findings of a saved -json document that match an expression so results can be
+// sliced without ad-hoc scripts. The document is read from standard input when its path is "-".
+func runQuery(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("query", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "Write the matching findings as a JSON document")
+	silent := fs.Bool("silent", false, "Output values without titles")
+	noColor := fs.Bool("no-color", false, "Disable colored output")
+
+	// Flags may follow the document and expression, as in "query results.json 'type==ip' -silent"
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return fmt.Errorf("error parsing flags: %w", err)
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(positional) != 2 {
+		return fmt.Errorf("error parsing flags: query takes a JSON document and an expression")
+	}
+	path, expr := positional[0], positional[1]
+
+	q, err := query.Parse(expr)
+	if err != nil {
+		return fmt.Errorf("invalid query: %w", err)
+	}
+
+	var data []byte
+	if path == stdinPath {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return fmt.Errorf("error reading document: %w", err)
+	}
+	doc, err := output.ReadDocument(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", path, err)
+	}
+
+	matched := q.Filter(doc.Findings)
+	if *asJSON {
+		return output.WriteJSON(os.Stdout, doc.Run, matched)
+	}
+	return output.WriteTextWith(os.Stdout, matched, output.TextOptions{
+		Silent: *silent,
+		Colors: output.Palette{Enabled: !*noColor && output.ColorTerminal(os.Stdout)},
+	})
+}
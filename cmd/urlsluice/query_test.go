@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/output"
+)
+
+func TestRun_Query(t *testing.T) {
+	var doc bytes.Buffer
+	err := output.WriteJSON(&doc, nil, []finding.Finding{
+		{Type: finding.TypeDomain, Value: "api.target.dev"},
+		{Type: finding.TypeDomain, Value: "www.target.com"},
+		{Type: finding.TypeEmail, Value: "dev@target.dev"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "results.json")
+	if err := os.WriteFile(path, doc.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		stdin   string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "silent",
+			args: []string{"query", "-silent", path, `type==domain && value endswith ".dev"`},
+			want: "api.target.dev\n",
+		},
+		{
+			name: "flags after arguments",
+			args: []string{"query", path, `value endswith .dev`, "-silent"},
+			want: "dev@target.dev\napi.target.dev\n",
+		},
+		{
+			name:  "standard input",
+			args:  []string{"query", "-json", "-", `type == email`},
+			stdin: doc.String(),
+			want:  `"value": "dev@target.dev"`,
+		},
+		{
+			name:    "invalid expression",
+			args:    []string{"query", path, `type = domain`},
+			wantErr: "invalid query: unexpected character '=' at offset 5",
+		},
+		{
+			name:    "missing expression",
+			args:    []string{"query", path},
+			wantErr: "query takes a JSON document and an expression",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldArgs := os.Args
+			oldFlagCommandLine := flag.CommandLine
+			oldStdout := os.Stdout
+			oldStdin := os.Stdin
+			defer func() {
+				os.Args = oldArgs
+				flag.CommandLine = oldFlagCommandLine
+				os.Stdout = oldStdout
+				os.Stdin = oldStdin
+			}()
+
+			flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			os.Args = append([]string{"cmd"}, tt.args...)
+
+			inR, inW, _ := os.Pipe()
+			inW.WriteString(tt.stdin)
+			inW.Close()
+			os.Stdin = inR
+			r, w, _ := os.Pipe()
+			os.Stdout = w
+
+			err := run(context.Background())
+			w.Close()
+			var buf bytes.Buffer
+			buf.ReadFrom(r)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("run() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if tt.stdin != "" {
+				if !strings.Contains(buf.String(), tt.want) {
+					t.Errorf("output = %q, want it to contain %q", buf.String(), tt.want)
+				}
+			} else if buf.String() != tt.want {
+				t.Errorf("output = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/PeteJStewart/urlsluice/internal/output"
+)
+
+// runSchema implements "urlsluice schema", printing the JSON Schema of the -json output
+// so downstream consumers can validate documents against the version they were built for
+func runSchema(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("schema", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("error parsing flags: %w", err)
+	}
+	_, err := os.Stdout.Write(output.Schema)
+	return err
+}
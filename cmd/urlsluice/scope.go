@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/output"
+)
+
+// inScopeLines drops the URL lines whose host is out of -scope-file scope.
+// Lines that are not absolute URLs are kept.
+func inScopeLines(config *Config, lines []string) []string {
+	if config.Scope == nil {
+		return lines
+	}
+	kept := lines[:0:0]
+	for _, line := range lines {
+		u, err := url.Parse(strings.TrimSpace(line))
+		if err == nil && u.Host != "" && !config.Scope.URL(u) {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return kept
+}
+
+// writeOutOfScopeReport writes the findings dropped by -scope-file to path, grouped by type
+func writeOutOfScopeReport(path string, dropped []finding.Finding) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error writing out-of-scope report: %w", err)
+	}
+	if err := output.WriteText(f, dropped, false); err != nil {
+		f.Close()
+		return fmt.Errorf("error writing out-of-scope report: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("error writing out-of-scope report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRun_ScopeFile(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "urls.txt")
+	content := "https://api.target.com/v1?id=7\nhttps://cdn.thirdparty.net/lib.js\nhttps://10.1.2.3/\n"
+	if err := os.WriteFile(input, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	scopePath := filepath.Join(dir, "scope.txt")
+	if err := os.WriteFile(scopePath, []byte("*.target.com\n10.1.0.0/16\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	reportPath := filepath.Join(dir, "dropped.txt")
+
+	oldArgs := os.Args
+	oldFlagCommandLine := flag.CommandLine
+	oldStdout := os.Stdout
+	defer func() {
+		os.Args = oldArgs
+		flag.CommandLine = oldFlagCommandLine
+		os.Stdout = oldStdout
+	}()
+
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	os.Args = []string{"cmd", "-domains", "-urls", "-silent", "-scope-file", scopePath,
+		"-out-of-scope-report", reportPath, "-file", input}
+
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := run(context.Background())
+	w.Close()
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	want := "10.1.2.3\napi.target.com\nhttps://10.1.2.3/\nhttps://api.target.com/v1?id=7\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+
+	report, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantReport := "\nExtracted Domains:\ncdn.thirdparty.net\n\nExtracted URLs:\nhttps://cdn.thirdparty.net/lib.js\n"
+	if string(report) != wantReport {
+		t.Errorf("report = %q, want %q", report, wantReport)
+	}
+}
+
+func TestParseFlagSet_OutOfScopeReportRequiresScope(t *testing.T) {
+	_, err := parseFlagSet(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-file", "x", "-out-of-scope-report", "out.txt"})
+	if err == nil {
+		t.Error("parseFlagSet() accepted -out-of-scope-report without -scope-file")
+	}
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/PeteJStewart/urlsluice/internal/api"
+	configfile "github.com/PeteJStewart/urlsluice/internal/config"
+	"github.com/PeteJStewart/urlsluice/internal/httpclient"
+	"github.com/PeteJStewart/urlsluice/internal/redirect"
+	"github.com/PeteJStewart/urlsluice/internal/scope"
+	"github.com/PeteJStewart/urlsluice/internal/server"
+	"github.com/PeteJStewart/urlsluice/internal/watch"
+)
+
+// defaultServeMaxBody is the default -max-body limit of serve (10MB)
+const defaultServeMaxBody byteSize = 10 * 1024 * 1024
+
+// runServe implements "urlsluice serve -addr 127.0.0.1:8080", serving the HTTP API of the
+// server package until interrupted. Requests in progress get a few seconds to finish on
+// shutdown. The tokens file and the -config, -scope-file and -redirect-config files applied
+// to every request are checked for changes every -reload-interval and on SIGHUP, like the
+// configuration of the daemon.
+func runServe(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := fs.String("addr", "127.0.0.1:8080", "Address to listen on")
+	tokensPath := fs.String("tokens", "", "YAML file of the API tokens accepted and their limits; without it no token is required")
+	rateFlag := fs.String("rate", "", "Maximum rate of requests of each client without a rate of its own, e.g. 60/m")
+	maxBody := defaultServeMaxBody
+	fs.Var(&maxBody, "max-body", "Maximum size of the request body of each client without a limit of its own (0 means no limit)")
+	configPath := fs.String("config", "", "YAML configuration file whose tags, transforms and tracking_params apply to every request")
+	scopePath := fs.String("scope-file", "", "File of in-scope hosts, *.wildcards and CIDRs; findings outside it are dropped from every request")
+	redirectPath := fs.String("redirect-config", "", "Redirect detection configuration; URL findings with likely open redirect parameters are tagged open-redirect")
+	reloadInterval := fs.Duration("reload-interval", 5*time.Second, "How often to check the tokens and settings files for changes (0 only checks on SIGHUP)")
+	workers := fs.Int("workers", 2, "Number of jobs processed at the same time")
+	maxClientJobs := fs.Int("max-client-jobs", 10, "Number of jobs each client without a limit of its own may have queued or running at once")
+	jobTTL := fs.Duration("job-ttl", time.Hour, "How long the results of finished jobs can be polled")
+	jobDir := fs.String("job-dir", "", "Directory holding the text of jobs until they finish (default the system temporary directory)")
+	var webhookAllow []string
+	fs.Var((*stringList)(&webhookAllow), "webhook-allow", "Host, address or CIDR network that job webhooks may reach although internal, e.g. 10.1.0.0/16 (repeatable)")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("error parsing flags: %w", err)
+	}
+	if *workers < 1 {
+		return fmt.Errorf("error parsing flags: -workers must be at least 1")
+	}
+	if *maxClientJobs < 1 {
+		return fmt.Errorf("error parsing flags: -max-client-jobs must be at least 1")
+	}
+	if *jobTTL <= 0 {
+		return fmt.Errorf("error parsing flags: -job-ttl must be positive")
+	}
+	webhooks, err := server.NewWebhookPolicy(webhookAllow)
+	if err != nil {
+		return fmt.Errorf("error parsing flags: -webhook-allow: %w", err)
+	}
+	// Webhook hosts are chosen by API clients, so the client connects through the policy and
+	// does not follow redirects, which could lead to internal addresses. Webhooks are API calls
+	// rather than crawling, so robots.txt does not apply.
+	client, err := httpclient.New(httpclient.Options{
+		Timeout:      30 * time.Second,
+		MaxRedirects: -1,
+		UserAgent:    httpclient.DefaultUserAgent,
+		Retries:      2,
+		DialContext:  webhooks.DialContext,
+	})
+	if err != nil {
+		return fmt.Errorf("error creating HTTP client: %w", err)
+	}
+	opts := server.Options{
+		MaxBody:       int64(maxBody),
+		Workers:       *workers,
+		MaxClientJobs: *maxClientJobs,
+		JobTTL:        *jobTTL,
+		JobDir:        *jobDir,
+		Client:        client,
+		Webhooks:      webhooks,
+	}
+	if *rateFlag != "" {
+		rate, err := httpclient.ParseRate(*rateFlag)
+		if err != nil {
+			return fmt.Errorf("error parsing flags: -rate: %w", err)
+		}
+		opts.Rate = rate
+	}
+	var watched []string
+	if *tokensPath != "" {
+		tokens, err := server.LoadTokens(*tokensPath)
+		if err != nil {
+			return fmt.Errorf("error loading tokens: %w", err)
+		}
+		opts.Tokens = tokens
+		watched = append(watched, *tokensPath)
+	}
+	settingsFiles := serveSettingsFiles{config: *configPath, scope: *scopePath, redirect: *redirectPath}
+	if opts.Settings, err = settingsFiles.load(); err != nil {
+		return err
+	}
+	watched = append(watched, settingsFiles.paths()...)
+	handler := server.New(opts)
+	defer handler.Close()
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return fmt.Errorf("error listening: %w", err)
+	}
+	srv := &http.Server{
+		Handler:           logRequests(handler),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	served := make(chan error, 1)
+	go func() {
+		served <- srv.Serve(listener)
+	}()
+	logDaemon("listening on http://%s", listener.Addr())
+
+	hup := make(chan os.Signal, 1)
+	var poll <-chan time.Time
+	files := watch.New(watched...)
+	if len(watched) > 0 {
+		signal.Notify(hup, syscall.SIGHUP)
+		defer signal.Stop(hup)
+		if *reloadInterval > 0 {
+			ticker := time.NewTicker(*reloadInterval)
+			defer ticker.Stop()
+			poll = ticker.C
+		}
+	}
+	tokens := opts.Tokens
+wait:
+	for {
+		select {
+		case err := <-served:
+			return err
+		case <-ctx.Done():
+			break wait
+		case <-poll:
+		case <-hup:
+		}
+		var reloadTokens, reloadSettings bool
+		for _, change := range files.Poll() {
+			if change.Path == *tokensPath {
+				reloadTokens = true
+				continue
+			}
+			reloadSettings = true
+			if change.New == nil {
+				logDaemon("%s can no longer be read", change.Path)
+				continue
+			}
+			logDaemon("%s changed: %s", change.Path, describeLines(change))
+		}
+		if reloadTokens {
+			tokens = reloadServeTokens(*tokensPath, tokens, handler)
+		}
+		if reloadSettings {
+			settings, err := settingsFiles.load()
+			if err != nil {
+				logDaemon("%v, keeping the current settings", err)
+				continue
+			}
+			handler.SetSettings(settings)
+			logDaemon("settings reloaded")
+		}
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("error shutting down: %w", err)
+	}
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// reloadServeTokens loads the tokens file at path and passes the tokens to handler, logging
+// the changes from tokens, and returns the tokens in use from now on
+func reloadServeTokens(path string, tokens []server.Token, handler *server.Server) []server.Token {
+	updated, err := server.LoadTokens(path)
+	if err != nil {
+		logDaemon("error reloading tokens, keeping the current ones: %v", err)
+		return tokens
+	}
+	for _, change := range server.DiffTokens(tokens, updated) {
+		logDaemon("%s reloaded: %s", path, change)
+	}
+	handler.SetTokens(updated)
+	return updated
+}
+
+// serveSettingsFiles are the files of the settings serve applies to every request; each may
+// be empty
+type serveSettingsFiles struct {
+	config   string
+	scope    string
+	redirect string
+}
+
+// paths returns the files that are set
+func (f serveSettingsFiles) paths() []string {
+	var paths []string
+	for _, path := range []string{f.config, f.scope, f.redirect} {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// load reads the settings files. Only the tags, transforms and tracking_params of the
+// configuration file apply; the API never runs plugins.
+func (f serveSettingsFiles) load() (*api.Settings, error) {
+	settings := &api.Settings{}
+	if f.config != "" {
+		config, err := configfile.Load(f.config)
+		if err != nil {
+			return nil, fmt.Errorf("error loading config: %w", err)
+		}
+		if settings.Tags, err = config.TagRules(); err != nil {
+			return nil, fmt.Errorf("error loading config: %w", err)
+		}
+		if settings.Transforms, err = config.TransformRules(); err != nil {
+			return nil, fmt.Errorf("error loading config: %w", err)
+		}
+		settings.TrackingParams = config.TrackingParams
+	}
+	if f.scope != "" {
+		s, err := scope.Load(f.scope)
+		if err != nil {
+			return nil, fmt.Errorf("error loading scope file: %w", err)
+		}
+		settings.Scope = s
+	}
+	if f.redirect != "" {
+		detector, err := redirect.NewRedirectDetector(f.redirect)
+		if err != nil {
+			return nil, fmt.Errorf("error creating redirect detector: %w", err)
+		}
+		settings.Redirects = detector
+	}
+	return settings, nil
+}
+
+// statusRecorder remembers the status code written to a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// logRequests logs the method, path, status and duration of every request to standard error
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		logDaemon("%s %s %d %s", r.Method, r.URL.Path, rec.status, time.Since(started).Round(time.Millisecond))
+	})
+}
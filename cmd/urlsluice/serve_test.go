@@ -0,0 +1,241 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PeteJStewart/urlsluice/internal/api"
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+func TestRun_Serve(t *testing.T) {
+	oldStderr := os.Stderr
+	defer func() { os.Stderr = oldStderr }()
+	r, w, _ := os.Pipe()
+	os.Stderr = w
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- runServe(ctx, []string{"-addr", "127.0.0.1:0"})
+	}()
+
+	log := bufio.NewReader(r)
+	line, err := log.ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, url, ok := strings.Cut(strings.TrimSpace(line), "listening on ")
+	if !ok {
+		t.Fatalf("first log line = %q, want the address", line)
+	}
+	resp, err := http.Post(url+"/v1/extract", "application/json", strings.NewReader(`{"text": "admin@target.com", "options": {"emails": true}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("POST /v1/extract = %d", resp.StatusCode)
+	}
+	if line, _ = log.ReadString('\n'); !strings.Contains(line, "POST /v1/extract 200") {
+		t.Errorf("request log = %q", line)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("runServe() error = %v", err)
+	}
+	w.Close()
+
+	if err := runServe(context.Background(), []string{"-addr", "not an address"}); err == nil || !strings.Contains(err.Error(), "error listening") {
+		t.Errorf("runServe() with an invalid address error = %v", err)
+	}
+}
+
+func TestRun_ServeTokens(t *testing.T) {
+	dir := t.TempDir()
+	tokensPath := filepath.Join(dir, "tokens.yaml")
+	if err := os.WriteFile(tokensPath, []byte("tokens:\n  - name: ci\n    token: first\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	oldStderr := os.Stderr
+	defer func() { os.Stderr = oldStderr }()
+	r, w, _ := os.Pipe()
+	os.Stderr = w
+	defer w.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- runServe(ctx, []string{"-addr", "127.0.0.1:0", "-tokens", tokensPath, "-reload-interval", "10ms"})
+	}()
+	log := bufio.NewReader(r)
+	line, err := log.ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, url, _ := strings.Cut(strings.TrimSpace(line), "listening on ")
+
+	extract := func(token string) int {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodPost, url+"/v1/extract", strings.NewReader(`{"text": "admin@target.com", "options": {"emails": true}}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		log.ReadString('\n')
+		return resp.StatusCode
+	}
+	if status := extract("first"); status != http.StatusOK {
+		t.Errorf("request with the token = %d", status)
+	}
+
+	// Rotating the token takes effect without a restart
+	if err := os.WriteFile(tokensPath, []byte("tokens:\n  - name: ci\n    token: second\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// A poll in the middle of the write may see a partial file, which is logged and skipped
+	for {
+		line, err := log.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(line, tokensPath+" reloaded: token ci: token changed") {
+			break
+		}
+		if !strings.Contains(line, "error reloading tokens, keeping the current ones") {
+			t.Fatalf("reload log = %q", line)
+		}
+	}
+	if status := extract("first"); status != http.StatusUnauthorized {
+		t.Errorf("request with the old token = %d", status)
+	}
+	if status := extract("second"); status != http.StatusOK {
+		t.Errorf("request with the new token = %d", status)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("runServe() error = %v", err)
+	}
+	if err := runServe(context.Background(), []string{"-rate", "fast"}); err == nil || !strings.Contains(err.Error(), `-rate: invalid rate "fast"`) {
+		t.Errorf("runServe() with an invalid rate error = %v", err)
+	}
+}
+
+func TestRun_ServeSettings(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		t.Helper()
+		// Files are replaced at once, so that a poll never sees them half written
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path+".tmp", []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(path+".tmp", path); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	configPath := write("config.yaml", "tags:\n  - pattern: /login\n    tag: auth\n")
+	scopePath := write("scope.txt", "*.target.com\n")
+	redirectPath := write("redirect.yaml", "redirect_params:\n  - next\n")
+
+	oldStderr := os.Stderr
+	defer func() { os.Stderr = oldStderr }()
+	r, w, _ := os.Pipe()
+	os.Stderr = w
+	defer w.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- runServe(ctx, []string{"-addr", "127.0.0.1:0", "-config", configPath, "-scope-file", scopePath, "-redirect-config", redirectPath, "-reload-interval", "10ms"})
+	}()
+	log := bufio.NewReader(r)
+	line, err := log.ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, url, _ := strings.Cut(strings.TrimSpace(line), "listening on ")
+
+	extract := func() []finding.Finding {
+		t.Helper()
+		body := `{"text": "https://a.target.com/login?next=https://evil.com\nhttps://b.other.com/login\n", "options": {"urls": true}}`
+		resp, err := http.Post(url+"/v1/extract", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		log.ReadString('\n')
+		var result api.Result
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			t.Fatal(err)
+		}
+		return result.Findings
+	}
+	if got := extract(); len(got) != 1 || got[0].Value != "https://a.target.com/login?next=https://evil.com" ||
+		!got[0].HasTag("auth") || !got[0].HasTag(api.RedirectTag) {
+		t.Errorf("findings = %+v, want the in-scope URL tagged auth and %s", got, api.RedirectTag)
+	}
+
+	// A changed scope applies to the next request without a restart, and the change is logged
+	write("scope.txt", "*.other.com\n")
+	var changed bool
+	for {
+		line, err := log.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(line, scopePath+" changed: added *.other.com; removed *.target.com") {
+			changed = true
+		}
+		if strings.Contains(line, "settings reloaded") {
+			break
+		}
+	}
+	if !changed {
+		t.Error("scope change not logged")
+	}
+	if got := extract(); len(got) != 1 || got[0].Value != "https://b.other.com/login" || got[0].HasTag(api.RedirectTag) {
+		t.Errorf("findings after the reload = %+v", got)
+	}
+
+	// Invalid settings are reported and the current ones kept
+	write("config.yaml", "tags:\n  - pattern: '('\n    tag: auth\n")
+	for {
+		line, err := log.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(line, "error loading config") && strings.Contains(line, "keeping the current settings") {
+			break
+		}
+		if strings.Contains(line, "settings reloaded") {
+			t.Fatal("invalid settings were reloaded")
+		}
+	}
+	if got := extract(); len(got) != 1 || !got[0].HasTag("auth") {
+		t.Errorf("findings after an invalid reload = %+v", got)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("runServe() error = %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/suppress"
+)
+
+// runSuppress implements "urlsluice suppress -type <type> <value>...", printing an allowlist
+// entry for each value so accepted-risk findings stay out of the reports of runs given
+// -suppress-file. With -append, the entries are added to an allowlist file instead.
+func runSuppress(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("suppress", flag.ContinueOnError)
+	typeFlag := fs.String("type", "", "Finding type of the values, such as email or domain")
+	expires := fs.String("expires", "", "Last day the entries apply, as YYYY-MM-DD (default never)")
+	comment := fs.String("comment", "", "Reason the findings were accepted, recorded with each entry")
+	appendTo := fs.String("append", "", "Allowlist file to add the entries to instead of printing them")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("error parsing flags: %w", err)
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("error parsing flags: at least one finding value is required")
+	}
+	if *typeFlag == "" {
+		return fmt.Errorf("error parsing flags: -type is required, since entries only suppress findings of one type")
+	}
+	typ, err := finding.ParseType(*typeFlag)
+	if err != nil {
+		return fmt.Errorf("error parsing flags: invalid -type: %w", err)
+	}
+
+	entry := suppress.Entry{Comment: *comment}
+	if *expires != "" {
+		if entry.Expires, err = suppress.ParseDate(*expires); err != nil {
+			return fmt.Errorf("error parsing flags: %w", err)
+		}
+	}
+
+	var w io.Writer = os.Stdout
+	if *appendTo != "" {
+		f, err := os.OpenFile(*appendTo, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return fmt.Errorf("error opening allowlist: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+	for _, value := range fs.Args() {
+		entry.Hash = suppress.Hash(typ, value)
+		if _, err := fmt.Fprintln(w, entry); err != nil {
+			return fmt.Errorf("error writing allowlist: %w", err)
+		}
+	}
+	return nil
+}
+
+// loadSuppressions loads the -suppress-file, if any, checking its signature with the
+// -suppress-key when one is given
+func loadSuppressions(config *Config) (*suppress.List, error) {
+	if config.SuppressKey != "" && config.SuppressFile == "" {
+		return nil, fmt.Errorf("-suppress-key requires -suppress-file")
+	}
+	if config.SuppressFile == "" {
+		return nil, nil
+	}
+	var list *suppress.List
+	var err error
+	if config.SuppressKey != "" {
+		key, decodeErr := base64.StdEncoding.DecodeString(config.SuppressKey)
+		if decodeErr != nil || len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid -suppress-key: must be a base64 Ed25519 public key")
+		}
+		list, err = suppress.LoadSigned(config.SuppressFile, key)
+	} else {
+		list, err = suppress.Load(config.SuppressFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error loading suppress file: %w", err)
+	}
+	return list, nil
+}
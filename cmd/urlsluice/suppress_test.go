@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/suppress"
+)
+
+func TestRun_Suppress(t *testing.T) {
+	dir := t.TempDir()
+	allowlist := filepath.Join(dir, "allowlist.txt")
+	input := filepath.Join(dir, "page.html")
+	if err := os.WriteFile(input, []byte("press@target.com admin@target.com old@target.com\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	public, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "print entry",
+			args: []string{"suppress", "-type", "email", "-expires", "2099-12-31", "-comment", "public contact", "press@target.com"},
+			want: suppress.Hash(finding.TypeEmail, "press@target.com") + " expires:2099-12-31 # public contact\n",
+		},
+		{
+			name: "append entries",
+			args: []string{"suppress", "-type", "email", "-append", allowlist, "-comment", "accepted", "press@target.com"},
+		},
+		{
+			name: "append expired entry",
+			args: []string{"suppress", "-type", "email", "-append", allowlist, "-expires", "2000-01-01", "old@target.com"},
+		},
+		{
+			name: "suppressed in report",
+			args: []string{"-file", input, "-emails", "-silent", "-suppress-file", allowlist},
+			want: "admin@target.com\nold@target.com\n",
+		},
+		{
+			name:    "missing value",
+			args:    []string{"suppress", "-type", "email", "-comment", "accepted"},
+			wantErr: "at least one finding value is required",
+		},
+		{
+			name:    "missing type",
+			args:    []string{"suppress", "press@target.com"},
+			wantErr: "-type is required",
+		},
+		{
+			name:    "unknown type",
+			args:    []string{"suppress", "-type", "mail", "press@target.com"},
+			wantErr: "unknown finding type",
+		},
+		{
+			name:    "key without allowlist",
+			args:    []string{"-file", input, "-emails", "-suppress-key", "AAAA"},
+			wantErr: "-suppress-key requires -suppress-file",
+		},
+		{
+			name:    "unsigned allowlist",
+			args:    []string{"-file", input, "-emails", "-suppress-file", allowlist, "-suppress-key", base64.StdEncoding.EncodeToString(public)},
+			wantErr: "error reading signature",
+		},
+		{
+			name:    "invalid date",
+			args:    []string{"suppress", "-type", "email", "-expires", "next week", "press@target.com"},
+			wantErr: "invalid expiry date",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldArgs := os.Args
+			oldFlagCommandLine := flag.CommandLine
+			oldStdout := os.Stdout
+			oldStderr := os.Stderr
+			defer func() {
+				os.Args = oldArgs
+				flag.CommandLine = oldFlagCommandLine
+				os.Stdout = oldStdout
+				os.Stderr = oldStderr
+			}()
+
+			flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			os.Args = append([]string{"cmd"}, tt.args...)
+
+			r, w, _ := os.Pipe()
+			os.Stdout = w
+			errR, errW, _ := os.Pipe()
+			os.Stderr = errW
+
+			err := run(context.Background())
+			w.Close()
+			errW.Close()
+			var buf, errBuf bytes.Buffer
+			buf.ReadFrom(r)
+			errBuf.ReadFrom(errR)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("run() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+			if tt.name == "suppressed in report" && !strings.Contains(errBuf.String(), "expired entries in "+allowlist+" no longer suppress findings (1)") {
+				t.Errorf("stderr = %q, want a warning about the expired entry", errBuf.String())
+			}
+		})
+	}
+}
+
+func TestLoadSuppressions_Signed(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	allowlist := filepath.Join(t.TempDir(), "allowlist.txt")
+	data := []byte(suppress.Hash(finding.TypeEmail, "press@target.com") + "\n")
+	if err := os.WriteFile(allowlist, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(allowlist+suppress.SignatureSuffix, ed25519.Sign(private, data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	config := &Config{SuppressFile: allowlist, SuppressKey: base64.StdEncoding.EncodeToString(public)}
+	list, err := loadSuppressions(config)
+	if err != nil {
+		t.Fatalf("loadSuppressions() error = %v", err)
+	}
+	if !list.Suppressed(finding.Finding{Type: finding.TypeEmail, Value: "press@target.com"}) {
+		t.Error("signed allowlist does not suppress its entry")
+	}
+
+	other, _, _ := ed25519.GenerateKey(nil)
+	config.SuppressKey = base64.StdEncoding.EncodeToString(other)
+	if _, err := loadSuppressions(config); err == nil || !strings.Contains(err.Error(), "invalid signature") {
+		t.Errorf("loadSuppressions() with another key error = %v", err)
+	}
+}
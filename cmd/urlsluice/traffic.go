@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/headers"
+	"github.com/PeteJStewart/urlsluice/internal/output"
+	"github.com/PeteJStewart/urlsluice/internal/pipeline"
+	"github.com/PeteJStewart/urlsluice/internal/traffic"
+)
+
+// trafficSource reads the -traffic HAR file or Burp Suite export and emits one batch per
+// recorded response body, named "traffic.har!URL". With -security-headers and -cors, the
+// findings of the response headers are emitted in a batch of their own before the body.
+func trafficSource(config *Config, runInfo *output.Run) pipeline.Source {
+	return func(ctx context.Context, emit pipeline.Emit) error {
+		data, err := os.ReadFile(config.TrafficPath)
+		if err != nil {
+			return fmt.Errorf("error reading traffic: %w", err)
+		}
+		runInfo.AddInput(config.TrafficPath, data)
+		responses, err := traffic.Parse(data)
+		if err != nil {
+			return fmt.Errorf("error reading traffic %s: %w", config.TrafficPath, err)
+		}
+		for _, r := range responses {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			source := config.TrafficPath + "!" + r.URL
+			findings := headerFindings(config, r.URL, r.Header)
+			if config.CORS {
+				findings = append(findings, headers.CORS(r.URL, r.RequestHeader, r.Header)...)
+			}
+			if len(findings) > 0 {
+				if err := emit(pipeline.Batch{Source: source, Findings: withSource(findings, source)}); err != nil {
+					return err
+				}
+			}
+			if len(r.Body) == 0 {
+				continue
+			}
+			if err := emit(pipeline.Batch{Source: source, Data: r.Body}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// headerFindings analyses the headers h of the response to pageURL with -security-headers:
+// the hosts of its Content-Security-Policy are reported with -domains, and weak CSP, CORS
+// and HSTS settings as header issues
+func headerFindings(config *Config, pageURL string, h http.Header) []finding.Finding {
+	if !config.SecurityHeaders {
+		return nil
+	}
+	var findings []finding.Finding
+	if config.ExtractDomains {
+		findings = append(findings, headers.Hosts(h)...)
+	}
+	return append(findings, headers.Issues(pageURL, h)...)
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testHAR = `{"log": {"version": "1.2", "entries": [
+  {"request": {"method": "GET", "url": "https://app.target.com/"},
+   "response": {"status": 200,
+     "headers": [
+       {"name": "Content-Security-Policy", "value": "script-src 'self' 'unsafe-inline' https://*.cdn.target.com"},
+       {"name": "Access-Control-Allow-Origin", "value": "*"}
+     ],
+     "content": {"mimeType": "text/html", "text": "<p>Contact security@target.com</p>"}}},
+  {"request": {"method": "GET", "url": "https://api.target.com/v1/me", "headers": [{"name": "Origin", "value": "https://evil.com"}]},
+   "response": {"status": 200,
+     "headers": [
+       {"name": "Access-Control-Allow-Origin", "value": "https://evil.com"},
+       {"name": "Access-Control-Allow-Credentials", "value": "true"}
+     ],
+     "content": {"mimeType": "application/json", "text": ""}}},
+  {"request": {"method": "POST", "url": "https://legacy.target.com/login.aspx"},
+   "response": {"status": 500, "headers": [],
+     "content": {"mimeType": "text/html", "text": "<h1>Server Error in '/Portal' Application.</h1>\n<pre>[SqlException]: Unclosed quotation mark after the character string ''.</pre>"}}}
+]}}`
+
+func TestRunTraffic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.har")
+	if err := os.WriteFile(path, []byte(testHAR), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantOutput []string
+		notWant    []string
+		wantErr    string
+	}{
+		{
+			name:       "response bodies",
+			args:       []string{"-traffic", path, "-emails"},
+			wantOutput: []string{"== " + path + "!https://app.target.com/ ==", "security@target.com"},
+			notWant:    []string{"Security Header Issues"},
+		},
+		{
+			name: "security headers",
+			args: []string{"-traffic", path, "-domains", "-security-headers", "-json"},
+			wantOutput: []string{
+				`"value": "cdn.target.com"`,
+				`"directive": "script-src"`,
+				`"value": "https://app.target.com CSP script-src allows 'unsafe-inline'"`,
+				`"value": "https://app.target.com CORS allows any origin"`,
+			},
+		},
+		{
+			name:       "only header issues",
+			args:       []string{"-traffic", path, "-only", "header-issues", "-silent"},
+			wantOutput: []string{"https://app.target.com CSP script-src allows 'unsafe-inline'\n"},
+			notWant:    []string{"cdn.target.com\n", "security@target.com"},
+		},
+		{
+			name:    "not recorded traffic",
+			args:    []string{"-traffic", "traffic_test.go", "-emails"},
+			wantErr: "error reading traffic",
+		},
+		{
+			name: "cors misconfigurations",
+			args: []string{"-traffic", path, "-cors", "-json"},
+			wantOutput: []string{
+				`"type": "cors"`,
+				`"value": "https://api.target.com CORS reflects origin https://evil.com with credentials"`,
+				`"origin": "https://evil.com"`,
+				`"allow_credentials": "true"`,
+			},
+			notWant: []string{"CORS allows any origin"},
+		},
+		{
+			name: "verbose errors",
+			args: []string{"-traffic", path, "-errors", "-json"},
+			wantOutput: []string{
+				`"value": "https://legacy.target.com/login.aspx ASP.NET"`,
+				`"value": "https://legacy.target.com/login.aspx SQL Server"`,
+				`"url": "https://legacy.target.com/login.aspx"`,
+				`"evidence": "Server Error in '/Portal' Application"`,
+			},
+		},
+		{
+			name:    "cors without traffic",
+			args:    []string{"-file", "traffic_test.go", "-cors"},
+			wantErr: "-cors analyses the requests and responses of -traffic",
+		},
+		{
+			name:    "security headers without responses",
+			args:    []string{"-file", "traffic_test.go", "-security-headers"},
+			wantErr: "-security-headers analyses the responses of -traffic or -crawl-depth",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldArgs := os.Args
+			oldStdout := os.Stdout
+			oldFlagCommandLine := flag.CommandLine
+			defer func() {
+				os.Args = oldArgs
+				os.Stdout = oldStdout
+				flag.CommandLine = oldFlagCommandLine
+			}()
+			flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+			os.Args = append([]string{"cmd"}, tt.args...)
+
+			r, w, _ := os.Pipe()
+			os.Stdout = w
+
+			err := run(context.Background())
+			w.Close()
+			var out bytes.Buffer
+			out.ReadFrom(r)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("run() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			for _, want := range tt.wantOutput {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("output should contain %q, got:\n%s", want, out.String())
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(out.String(), notWant) {
+					t.Errorf("output should not contain %q, got:\n%s", notWant, out.String())
+				}
+			}
+		})
+	}
+}
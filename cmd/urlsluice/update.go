@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/PeteJStewart/urlsluice/internal/httpclient"
+	"github.com/PeteJStewart/urlsluice/internal/update"
+)
+
+// updateBaseURL is the GitHub API endpoint; tests point it at a local server
+var updateBaseURL = update.DefaultBaseURL
+
+// updatePublicKey is the base64 Ed25519 key release checksums are signed with. Forks
+// publishing their own releases replace it at build time with
+// -ldflags "-X main.updatePublicKey=...".
+var updatePublicKey = "x/7NTWz01AEFAI0VWAtrsBkNBBZazAaYFOd0TsoG9js="
+
+// updateExecutable returns the path of the binary to replace; tests override it
+var updateExecutable = func() (string, error) {
+	path, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(path)
+}
+
+// runUpdate implements "urlsluice update", replacing the running binary with the latest
+// GitHub release after verifying the signature of its checksums and its checksum. Without
+// a valid signature the binary is only replaced with -insecure-skip-signature.
+func runUpdate(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("update", flag.ContinueOnError)
+	checkOnly := fs.Bool("check-only", false, "Report whether a newer release is available without installing it")
+	skipSignature := fs.Bool("insecure-skip-signature", false, "Install a release without verifying the signature of its checksums, only its checksum")
+	config := &Config{}
+	fs.StringVar(&config.UserAgent, "user-agent", httpclient.DefaultUserAgent, "User-Agent sent with HTTP requests")
+	fs.StringVar(&config.Proxy, "proxy", "", "HTTP or SOCKS5 proxy URL for HTTP requests (default from HTTP_PROXY/HTTPS_PROXY)")
+	fs.BoolVar(&config.Insecure, "insecure", false, "Skip TLS certificate verification, e.g. behind an intercepting proxy")
+	fs.IntVar(&config.Retries, "retries", 2, "Number of retries for failed HTTP requests")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("error parsing flags: %w", err)
+	}
+
+	// Release downloads are API requests rather than crawling, so robots.txt does not apply.
+	// Release assets are served through redirects and can exceed the default body limit.
+	config.IgnoreRobots = true
+	config.MaxRedirects = 10
+	client, err := newHTTPClient(config)
+	if err != nil {
+		return fmt.Errorf("error creating HTTP client: %w", err)
+	}
+	updater := &update.Updater{Client: client, BaseURL: updateBaseURL}
+	if updatePublicKey != "" && !*skipSignature {
+		key, err := base64.StdEncoding.DecodeString(updatePublicKey)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return fmt.Errorf("invalid update public key in this build")
+		}
+		updater.PublicKey = key
+	}
+
+	release, err := updater.Latest(ctx)
+	if err != nil {
+		return err
+	}
+	if !update.Newer(version, release.TagName) {
+		fmt.Printf("urlsluice %s is up to date\n", version)
+		return nil
+	}
+	fmt.Printf("urlsluice %s is available (current: %s)\n", release.TagName, version)
+	if *checkOnly {
+		return nil
+	}
+
+	path, err := updateExecutable()
+	if err != nil {
+		return fmt.Errorf("error locating the urlsluice binary: %w", err)
+	}
+	switch {
+	case *skipSignature:
+		fmt.Fprintln(os.Stderr, "Warning: -insecure-skip-signature is set; only the release checksum is verified")
+	case updater.PublicKey == nil:
+		return fmt.Errorf("this build has no update public key to verify releases with; use -insecure-skip-signature to install %s verifying only its checksum", release.TagName)
+	}
+	binary, err := updater.Download(ctx, release)
+	if err != nil {
+		if updater.PublicKey != nil {
+			return fmt.Errorf("%w; the binary was not replaced", err)
+		}
+		return err
+	}
+	if err := update.Replace(path, binary); err != nil {
+		return fmt.Errorf("error replacing %s: %w", path, err)
+	}
+	fmt.Printf("Updated %s to %s\n", path, release.TagName)
+	return nil
+}
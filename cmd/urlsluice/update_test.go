@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/PeteJStewart/urlsluice/internal/update"
+)
+
+func TestRunUpdate(t *testing.T) {
+	binary := []byte("new urlsluice")
+	sum := sha256.Sum256(binary)
+	name := update.AssetName(runtime.GOOS, runtime.GOARCH)
+	sums := fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum[:]), name)
+	if key, err := base64.StdEncoding.DecodeString(updatePublicKey); err != nil || len(key) != ed25519.PublicKeySize {
+		t.Errorf("built-in update public key %q is not a base64 Ed25519 key", updatePublicKey)
+	}
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// signature is served as the signature of the checksums; without it the release is unsigned
+	var signature []byte
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/PeteJStewart/urlsluice/releases/latest":
+			assets := fmt.Sprintf(`{"name": %q, "browser_download_url": "%s/bin"}, {"name": "checksums.txt", "browser_download_url": "%s/sums"}`, name, srv.URL, srv.URL)
+			if signature != nil {
+				assets += fmt.Sprintf(`, {"name": "checksums.txt.sig", "browser_download_url": "%s/sig"}`, srv.URL)
+			}
+			fmt.Fprintf(w, `{"tag_name": "v2.0.0", "assets": [%s]}`, assets)
+		case "/bin":
+			w.Write(binary)
+		case "/sums":
+			fmt.Fprint(w, sums)
+		case "/sig":
+			w.Write(signature)
+		}
+	}))
+	defer srv.Close()
+
+	oldBaseURL, oldVersion, oldExecutable, oldKey := updateBaseURL, version, updateExecutable, updatePublicKey
+	defer func() {
+		updateBaseURL, version, updateExecutable, updatePublicKey = oldBaseURL, oldVersion, oldExecutable, oldKey
+	}()
+	updateBaseURL = srv.URL
+	path := filepath.Join(t.TempDir(), "urlsluice")
+	updateExecutable = func() (string, error) { return path, nil }
+
+	tests := []struct {
+		name       string
+		version    string
+		args       []string
+		noKey      bool
+		signature  []byte
+		wantOutput string
+		wantErr    string
+		wantBinary string
+	}{
+		{name: "up to date", version: "v2.0.0", args: []string{"update"}, wantOutput: "is up to date", wantBinary: "old urlsluice"},
+		{name: "check only", version: "v1.0.0", args: []string{"update", "-check-only"}, wantOutput: "v2.0.0 is available", wantBinary: "old urlsluice"},
+		{name: "unsigned", version: "v1.0.0", args: []string{"update"}, wantErr: "release v2.0.0 is not signed; the binary was not replaced", wantBinary: "old urlsluice"},
+		{name: "bad signature", version: "v1.0.0", args: []string{"update"}, signature: make([]byte, ed25519.SignatureSize), wantErr: "invalid signature of checksums.txt", wantBinary: "old urlsluice"},
+		{name: "no public key", version: "v1.0.0", args: []string{"update"}, noKey: true, wantErr: "use -insecure-skip-signature", wantBinary: "old urlsluice"},
+		{name: "update", version: "v1.0.0", args: []string{"update"}, signature: ed25519.Sign(privateKey, []byte(sums)), wantOutput: "Updated " + path + " to v2.0.0", wantBinary: "new urlsluice"},
+		{name: "skip signature", version: "v1.0.0", args: []string{"update", "-insecure-skip-signature"}, wantOutput: "Updated " + path + " to v2.0.0", wantBinary: "new urlsluice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldArgs := os.Args
+			oldStdout, oldStderr := os.Stdout, os.Stderr
+			defer func() {
+				os.Args = oldArgs
+				os.Stdout, os.Stderr = oldStdout, oldStderr
+			}()
+			if err := os.WriteFile(path, []byte("old urlsluice"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+
+			version = tt.version
+			signature = tt.signature
+			updatePublicKey = base64.StdEncoding.EncodeToString(publicKey)
+			if tt.noKey {
+				updatePublicKey = ""
+			}
+			os.Args = append([]string{"cmd"}, tt.args...)
+			r, w, _ := os.Pipe()
+			os.Stdout = w
+			os.Stderr = w
+
+			err := run(context.Background())
+			w.Close()
+			var buf bytes.Buffer
+			buf.ReadFrom(r)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("run() error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if !strings.Contains(buf.String(), tt.wantOutput) {
+				t.Errorf("output = %q, want it to contain %q", buf.String(), tt.wantOutput)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.wantBinary {
+				t.Errorf("binary = %q, want %q", data, tt.wantBinary)
+			}
+		})
+	}
+}
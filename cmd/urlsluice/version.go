@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/output"
+)
+
+// buildFeatures lists optional capabilities compiled in with build tags; files guarded by
+// a tag append to it from init
+var buildFeatures []string
+
+// readBuildInfo is debug.ReadBuildInfo; tests replace it
+var readBuildInfo = debug.ReadBuildInfo
+
+func init() {
+	// "go install module@v1.2.3" builds carry their module version instead of ldflags
+	if info, ok := readBuildInfo(); ok && version == "dev" && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		version = info.Main.Version
+	}
+}
+
+// buildInfo describes the running binary. Values not set with -ldflags are taken from the
+// module and VCS metadata the Go toolchain embeds, so "go install" builds still report
+// their commit.
+func buildInfo() *output.Build {
+	build := &output.Build{
+		Commit:    commit,
+		Date:      buildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+
+	if info, ok := readBuildInfo(); ok {
+		var modified bool
+		var revision, revisionTime string
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				revision = setting.Value
+			case "vcs.time":
+				revisionTime = setting.Value
+			case "vcs.modified":
+				modified = setting.Value == "true"
+			}
+		}
+		if build.Commit == "" && revision != "" {
+			if len(revision) > 7 {
+				revision = revision[:7]
+			}
+			build.Commit = revision
+			if modified {
+				build.Commit += "-dirty"
+			}
+		}
+		if build.Date == "" {
+			build.Date = revisionTime
+		}
+	}
+
+	build.Features = append(build.Features, buildFeatures...)
+	if updatePublicKey != "" {
+		build.Features = append(build.Features, "signed-updates")
+	}
+	return build
+}
+
+// versionInfo is the document written by "urlsluice version -json"
+type versionInfo struct {
+	Version string `json:"version"`
+	*output.Build
+}
+
+// runVersion implements "urlsluice version"
+func runVersion(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("version", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "Write the version and build information as JSON")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("error parsing flags: %w", err)
+	}
+	return writeVersion(os.Stdout, *asJSON)
+}
+
+func writeVersion(w io.Writer, asJSON bool) error {
+	build := buildInfo()
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(versionInfo{Version: version, Build: build})
+	}
+
+	details := []string{}
+	if build.Commit != "" {
+		details = append(details, "commit "+build.Commit)
+	}
+	if build.Date != "" {
+		details = append(details, "built "+build.Date)
+	}
+	details = append(details, build.GoVersion, build.Platform)
+	fmt.Fprintf(w, "urlsluice %s (%s)\n", version, strings.Join(details, ", "))
+	if len(build.Features) > 0 {
+		fmt.Fprintf(w, "features: %s\n", strings.Join(build.Features, ", "))
+	}
+	return nil
+}
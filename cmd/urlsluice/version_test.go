@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+func TestWriteVersion(t *testing.T) {
+	oldVersion, oldCommit, oldDate, oldKey, oldRead := version, commit, buildDate, updatePublicKey, readBuildInfo
+	defer func() {
+		version, commit, buildDate, updatePublicKey, readBuildInfo = oldVersion, oldCommit, oldDate, oldKey, oldRead
+	}()
+	version, commit, buildDate, updatePublicKey = "v1.4.0", "", "", "key"
+	readBuildInfo = func() (*debug.BuildInfo, bool) {
+		return &debug.BuildInfo{Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "3f2c1a9e5b7d"},
+			{Key: "vcs.time", Value: "2024-04-28T09:12:00Z"},
+			{Key: "vcs.modified", Value: "true"},
+		}}, true
+	}
+
+	var buf bytes.Buffer
+	if err := writeVersion(&buf, true); err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	for key, want := range map[string]string{"version": "v1.4.0", "commit": "3f2c1a9-dirty", "date": "2024-04-28T09:12:00Z"} {
+		if got[key] != want {
+			t.Errorf("%s = %v, want %q", key, got[key], want)
+		}
+	}
+	if features, _ := got["features"].([]interface{}); len(features) != 1 || features[0] != "signed-updates" {
+		t.Errorf("features = %v, want [signed-updates]", got["features"])
+	}
+
+	// ldflags take precedence over the embedded VCS metadata
+	commit, buildDate = "abc1234", "2024-05-01T00:00:00Z"
+	buf.Reset()
+	if err := writeVersion(&buf, false); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "urlsluice v1.4.0 (commit abc1234, built 2024-05-01T00:00:00Z, go") {
+		t.Errorf("text output = %q", buf.String())
+	}
+	if !strings.Contains(buf.String(), "features: signed-updates\n") {
+		t.Errorf("text output is missing features: %q", buf.String())
+	}
+}
@@ -2,4 +2,7 @@ module github.com/PeteJStewart/urlsluice
 
 go 1.21
 
-require gopkg.in/yaml.v3 v3.0.1
+require (
+	github.com/tetratelabs/wazero v1.8.2
+	gopkg.in/yaml.v3 v3.0.1
+)
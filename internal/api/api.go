@@ -0,0 +1,309 @@
+// Package api is the options model shared by the programmatic front ends of urlsluice: the
+// HTTP server ("urlsluice serve") and the WebAssembly build. Options mirror the command line
+// flags and are validated the same way wherever they come from, so a request that works in
+// one front end works in the others.
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/classify"
+	"github.com/PeteJStewart/urlsluice/internal/extractor"
+	"github.com/PeteJStewart/urlsluice/internal/filter"
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/redirect"
+	"github.com/PeteJStewart/urlsluice/internal/scope"
+	"github.com/PeteJStewart/urlsluice/internal/suggest"
+	"github.com/PeteJStewart/urlsluice/internal/tracking"
+	"github.com/PeteJStewart/urlsluice/internal/transform"
+)
+
+// Options selects the extractors, filters and output detail of a request. The fields mirror
+// the command line flags of the same names; Scope holds the lines of a -scope-file.
+type Options struct {
+	UUID               int      `json:"uuid"`
+	UUIDDetect         bool     `json:"uuidDetect"`
+	Emails             bool     `json:"emails"`
+	EmailsDeobfuscate  bool     `json:"emailsDeobfuscate"`
+	Domains            bool     `json:"domains"`
+	IPs                bool     `json:"ips"`
+	QueryParams        bool     `json:"queryParams"`
+	DecodeParams       bool     `json:"decodeParams"`
+	URLs               bool     `json:"urls"`
+	Handles            bool     `json:"handles"`
+	Crypto             bool     `json:"crypto"`
+	CloudConfig        bool     `json:"cloudConfig"`
+	ConfigSecrets      bool     `json:"configSecrets"`
+	Timestamps         bool     `json:"timestamps"`
+	Usernames          bool     `json:"usernames"`
+	AvatarHashes       bool     `json:"avatarHashes"`
+	Buckets            bool     `json:"buckets"`
+	JSONP              bool     `json:"jsonp"`
+	Errors             bool     `json:"errors"`
+	Comments           bool     `json:"comments"`
+	CommentKeywords    []string `json:"commentKeywords"`
+	Locales            bool     `json:"locales"`
+	EntropyMin         float64  `json:"entropyMin"`
+	ParseURLs          bool     `json:"parseUrls"`
+	Structured         bool     `json:"structured"`
+	MinConfidence      string   `json:"minConfidence"`
+	IncludeReserved    bool     `json:"includeReserved"`
+	KeepTrackingParams bool     `json:"keepTrackingParams"`
+	Scope              []string `json:"scope"`
+	// Detail selects how much of each finding is returned, see the Detail constants
+	Detail string `json:"detail"`
+	// Offset is the number of findings skipped, to page through large results
+	Offset int `json:"offset"`
+	// Limit is the maximum number of findings returned (0 means no limit)
+	Limit int `json:"limit"`
+}
+
+// Settings are applied to every request of a front end, such as the files "urlsluice serve"
+// was started with. Unlike Options, they are chosen by the operator rather than the client.
+type Settings struct {
+	// Tags are the tagging rules of a configuration file
+	Tags []classify.TagRule
+	// Transforms rewrite the values of findings, after filtering
+	Transforms []transform.Rule
+	// TrackingParams extend the built-in tracking parameters
+	TrackingParams []string
+	// Scope drops the findings outside it, in addition to the scope of the request
+	Scope *scope.Scope
+	// Redirects tags URL findings with parameters that look like open redirects
+	Redirects *redirect.RedirectDetector
+}
+
+// RedirectTag is the tag of URL findings that Settings.Redirects reports as potential open
+// redirects
+const RedirectTag = "open-redirect"
+
+// Output detail levels
+const (
+	// DetailFull returns findings with their source, line, tags and metadata
+	DetailFull = "full"
+	// DetailValues returns only the type and value of each finding
+	DetailValues = "values"
+	// DetailCounts returns only the number of findings of each type
+	DetailCounts = "counts"
+)
+
+// DecodeOptions decodes a JSON object of options. Unknown fields are rejected, with the
+// closest known field as a suggestion, rather than silently running a different request.
+func DecodeOptions(data []byte) (Options, error) {
+	var opts Options
+	if len(bytes.TrimSpace(data)) == 0 {
+		return opts, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&opts); err != nil {
+		return opts, unknownFieldHint(err)
+	}
+	return opts, nil
+}
+
+// unknownFieldHint adds a suggestion to the error of an unknown field
+func unknownFieldHint(err error) error {
+	name, ok := strings.CutPrefix(err.Error(), "json: unknown field ")
+	if !ok {
+		return err
+	}
+	name = strings.Trim(name, `"`)
+	if s := suggest.Closest(name, fieldNames()); s != "" {
+		return fmt.Errorf("%w, did you mean %q?", err, s)
+	}
+	return err
+}
+
+// fieldNames returns the JSON names of the fields of Options
+func fieldNames() []string {
+	t := reflect.TypeOf(Options{})
+	names := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		names = append(names, name)
+	}
+	return names
+}
+
+// Validate checks the options, returning the first problem found
+func (o Options) Validate() error {
+	if o.UUID < 0 || o.UUID > 5 {
+		return fmt.Errorf("uuid: invalid UUID version %d: must be between 1 and 5, or 0 to disable UUID extraction", o.UUID)
+	}
+	if o.EntropyMin < 0 {
+		return fmt.Errorf("entropyMin: must not be negative")
+	}
+	if o.MinConfidence != "" {
+		if _, err := finding.ParseConfidence(o.MinConfidence); err != nil {
+			return fmt.Errorf("minConfidence: %w", err)
+		}
+	}
+	switch o.Detail {
+	case "", DetailFull, DetailValues, DetailCounts:
+	default:
+		return fmt.Errorf("detail: invalid detail level %q: must be %s, %s or %s", o.Detail, DetailFull, DetailValues, DetailCounts)
+	}
+	if o.Offset < 0 {
+		return fmt.Errorf("offset: must not be negative")
+	}
+	if o.Limit < 0 {
+		return fmt.Errorf("limit: must not be negative, or 0 for no limit")
+	}
+	if _, err := o.scope(); err != nil {
+		return fmt.Errorf("scope: %w", err)
+	}
+	if len(o.extractorConfig().Types()) == 0 {
+		return errors.New("no extractors enabled")
+	}
+	return nil
+}
+
+// scope returns the parsed scope, or nil when the options have none
+func (o Options) scope() (*scope.Scope, error) {
+	if len(o.Scope) == 0 {
+		return nil, nil
+	}
+	return scope.Parse(strings.NewReader(strings.Join(o.Scope, "\n")))
+}
+
+// extractorConfig returns the extractor configuration selected by the options, with the same
+// implied extractors as the command line
+func (o Options) extractorConfig() extractor.Config {
+	return extractor.Config{
+		UUIDVersion:       o.UUID,
+		UUIDAll:           o.UUIDDetect,
+		ExtractEmails:     o.Emails || o.EmailsDeobfuscate,
+		DeobfuscateEmails: o.EmailsDeobfuscate,
+		ExtractDomains:    o.Domains,
+		ExtractIPs:        o.IPs,
+		ExtractParams:     o.QueryParams || o.DecodeParams,
+		DecodeParams:      o.DecodeParams,
+		ExtractURLs:       o.URLs,
+		ExtractHandles:    o.Handles,
+		ExtractCrypto:     o.Crypto,
+		ExtractCloud:      o.CloudConfig,
+		ExtractSecrets:    o.ConfigSecrets,
+		ExtractTimes:      o.Timestamps,
+		ExtractUsernames:  o.Usernames,
+		ExtractAvatars:    o.AvatarHashes,
+		ExtractBuckets:    o.Buckets,
+		ExtractJSONP:      o.JSONP,
+		ExtractErrors:     o.Errors,
+		ExtractComments:   o.Comments || len(o.CommentKeywords) > 0,
+		CommentKeywords:   o.CommentKeywords,
+		ExtractLocales:    o.Locales,
+		EntropyMin:        o.EntropyMin,
+		ParseURLs:         o.ParseURLs,
+		Structured:        o.Structured,
+	}
+}
+
+// Result is the outcome of a request at the selected detail level
+type Result struct {
+	// Findings are omitted at the counts detail level
+	Findings []finding.Finding `json:"findings,omitempty"`
+	// Counts is the number of findings of each type, including those left out of the page
+	Counts map[finding.Type]int `json:"counts"`
+	// Total is the number of findings, including those left out of the page
+	Total int `json:"total"`
+	// Truncated is the number of findings after the page, left out by the limit
+	Truncated int `json:"truncated,omitempty"`
+}
+
+// Page returns the result with only the findings from offset, at most limit of them unless
+// limit is 0. Findings keep the order of the result, so that consecutive pages of the same
+// request do not overlap.
+func (r *Result) Page(offset, limit int) *Result {
+	page := *r
+	if r.Findings == nil {
+		return &page
+	}
+	findings := r.Findings[min(offset, len(r.Findings)):]
+	if limit > 0 && len(findings) > limit {
+		page.Truncated = len(findings) - limit
+		findings = findings[:limit]
+	}
+	page.Findings = findings
+	return &page
+}
+
+// Extract returns the findings in r, classified and filtered like the findings of a command
+// line run without a configuration file or network access
+func Extract(ctx context.Context, r io.Reader, opts Options) (*Result, error) {
+	return ExtractWith(ctx, r, opts, nil)
+}
+
+// ExtractWith is Extract with the settings of the front end, which may be nil
+func ExtractWith(ctx context.Context, r io.Reader, opts Options, settings *Settings) (*Result, error) {
+	if settings == nil {
+		settings = &Settings{}
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	min := finding.ConfidenceLow
+	if opts.MinConfidence != "" {
+		min, _ = finding.ParseConfidence(opts.MinConfidence)
+	}
+	ext, err := extractor.New(opts.extractorConfig())
+	if err != nil {
+		return nil, err
+	}
+	results, err := ext.Extract(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	findings := results.Findings
+	if !opts.KeepTrackingParams {
+		params := tracking.Default()
+		if len(settings.TrackingParams) > 0 {
+			params = params.With(settings.TrackingParams)
+		}
+		params.Separate(findings)
+	}
+	classify.Internal(findings)
+	classify.Tag(findings, settings.Tags)
+	if settings.Redirects != nil {
+		for i := range findings {
+			if findings[i].Type == finding.TypeURL && settings.Redirects.DetectRedirectParams(findings[i].Value) {
+				findings[i].AddTag(RedirectTag)
+			}
+		}
+	}
+	filters := []filter.Func{filter.MinConfidence(min), filter.TLDs(nil, nil, opts.IncludeReserved)}
+	if s, _ := opts.scope(); s != nil {
+		filters = append(filters, s.Contains)
+	}
+	if settings.Scope != nil {
+		filters = append(filters, settings.Scope.Contains)
+	}
+	findings = transform.Apply(filter.Apply(findings, filters...), settings.Transforms)
+	return newResult(findings, opts.Detail).Page(opts.Offset, opts.Limit), nil
+}
+
+// newResult reduces findings to the detail level
+func newResult(findings []finding.Finding, detail string) *Result {
+	r := &Result{Counts: make(map[finding.Type]int), Total: len(findings)}
+	for _, f := range findings {
+		r.Counts[f.Type]++
+	}
+	switch detail {
+	case DetailCounts:
+	case DetailValues:
+		r.Findings = make([]finding.Finding, len(findings))
+		for i, f := range findings {
+			r.Findings[i] = finding.Finding{Type: f.Type, Value: f.Value}
+		}
+	default:
+		r.Findings = findings
+	}
+	return r
+}
@@ -0,0 +1,172 @@
+package api
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/PeteJStewart/urlsluice/internal/classify"
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/redirect"
+	"github.com/PeteJStewart/urlsluice/internal/scope"
+	"github.com/PeteJStewart/urlsluice/internal/transform"
+)
+
+func TestDecodeOptions(t *testing.T) {
+	opts, err := DecodeOptions([]byte(`{"emails": true, "uuid": 4, "scope": ["*.target.com"], "detail": "values"}`))
+	if err != nil {
+		t.Fatalf("DecodeOptions() error = %v", err)
+	}
+	want := Options{Emails: true, UUID: 4, Scope: []string{"*.target.com"}, Detail: DetailValues}
+	if !reflect.DeepEqual(opts, want) {
+		t.Errorf("DecodeOptions() = %+v, want %+v", opts, want)
+	}
+	if opts, err := DecodeOptions(nil); err != nil || !reflect.DeepEqual(opts, Options{}) {
+		t.Errorf("DecodeOptions(nil) = %+v, %v", opts, err)
+	}
+
+	tests := map[string]string{
+		`{"emial": true}`:      `json: unknown field "emial", did you mean "emails"?`,
+		`{"frobnicate": true}`: `json: unknown field "frobnicate"`,
+		`{"uuid": "4"}`:        "json: cannot unmarshal string",
+	}
+	for data, want := range tests {
+		if _, err := DecodeOptions([]byte(data)); err == nil || !strings.HasPrefix(err.Error(), want) {
+			t.Errorf("DecodeOptions(%s) error = %v, want %q", data, err, want)
+		}
+	}
+}
+
+func TestOptions_Validate(t *testing.T) {
+	tests := []struct {
+		opts Options
+		want string
+	}{
+		{Options{Emails: true}, ""},
+		{Options{}, "no extractors enabled"},
+		{Options{UUID: 7}, "uuid: invalid UUID version 7"},
+		{Options{Emails: true, EntropyMin: -1}, "entropyMin: must not be negative"},
+		{Options{Emails: true, MinConfidence: "sure"}, `minConfidence: invalid confidence level "sure"`},
+		{Options{Emails: true, Detail: "verbose"}, `detail: invalid detail level "verbose"`},
+		{Options{Emails: true, Offset: -1}, "offset: must not be negative"},
+		{Options{Emails: true, Limit: -5}, "limit: must not be negative"},
+		{Options{Emails: true, Scope: []string{"*.target.com", "10.0.0.0/99"}}, "scope: line 2: "},
+	}
+	for _, tt := range tests {
+		err := tt.opts.Validate()
+		if tt.want == "" && err != nil || tt.want != "" && (err == nil || !strings.HasPrefix(err.Error(), tt.want)) {
+			t.Errorf("Validate(%+v) = %v, want %q", tt.opts, err, tt.want)
+		}
+	}
+}
+
+func TestExtract(t *testing.T) {
+	text := "admin@target.com and ops@partner.com wrote https://api.target.com/v1?id=7 and https://cdn.partner.com/"
+	values := func(findings []finding.Finding) []string {
+		var out []string
+		for _, f := range findings {
+			out = append(out, string(f.Type)+":"+f.Value)
+		}
+		return out
+	}
+
+	r, err := Extract(context.Background(), strings.NewReader(text), Options{Emails: true, Domains: true, Scope: []string{"*.target.com", "target.com"}})
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	if got, want := values(r.Findings), []string{"email:admin@target.com", "domain:api.target.com"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Extract() with a scope = %v, want %v", got, want)
+	}
+	if r.Findings[0].Line != 1 {
+		t.Errorf("full detail finding = %+v, want its line", r.Findings[0])
+	}
+
+	r, err = Extract(context.Background(), strings.NewReader(text), Options{Emails: true, Detail: DetailValues})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (finding.Finding{Type: finding.TypeEmail, Value: "admin@target.com"}); !reflect.DeepEqual(r.Findings[0], want) {
+		t.Errorf("values detail finding = %+v, want %+v", r.Findings[0], want)
+	}
+
+	r, err = Extract(context.Background(), strings.NewReader(text), Options{Emails: true, Domains: true, Detail: DetailCounts})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[finding.Type]int{finding.TypeEmail: 2, finding.TypeDomain: 2}; r.Findings != nil || !reflect.DeepEqual(r.Counts, want) {
+		t.Errorf("counts detail = %+v, want counts %v", r, want)
+	}
+
+	if _, err := Extract(context.Background(), strings.NewReader(text), Options{}); err == nil {
+		t.Error("Extract() without extractors succeeded")
+	}
+}
+
+func TestExtractWith(t *testing.T) {
+	in, err := scope.Parse(strings.NewReader("*.target.com\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	detector, err := redirect.NewRedirectDetector("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings := &Settings{
+		Tags:       []classify.TagRule{{Pattern: regexp.MustCompile(`/login`), Tag: "auth"}},
+		Transforms: []transform.Rule{{Lowercase: true}},
+		Scope:      in,
+		Redirects:  detector,
+	}
+	text := "https://App.target.com/login?next=https://evil.com https://cdn.partner.com/login"
+	r, err := ExtractWith(context.Background(), strings.NewReader(text), Options{URLs: true, Detail: DetailFull}, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Findings) != 1 || r.Findings[0].Value != "https://app.target.com/login?next=https://evil.com" ||
+		!r.Findings[0].HasTag("auth") || !r.Findings[0].HasTag(RedirectTag) {
+		t.Errorf("ExtractWith() = %+v, want the in-scope URL lowercased and tagged", r.Findings)
+	}
+}
+
+func TestExtract_Page(t *testing.T) {
+	text := "a@target.com b@target.com c@target.com d@target.com e@target.com"
+	all, err := Extract(context.Background(), strings.NewReader(text), Options{Emails: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if all.Total != 5 || len(all.Findings) != 5 || all.Truncated != 0 {
+		t.Fatalf("Extract() = %+v, want 5 findings", all)
+	}
+
+	tests := []struct {
+		offset, limit int
+		want          []finding.Finding
+		truncated     int
+	}{
+		{0, 2, all.Findings[:2], 3},
+		{2, 2, all.Findings[2:4], 1},
+		{4, 2, all.Findings[4:], 0},
+		{3, 0, all.Findings[3:], 0},
+		{9, 2, []finding.Finding{}, 0},
+	}
+	for _, tt := range tests {
+		r, err := Extract(context.Background(), strings.NewReader(text), Options{Emails: true, Offset: tt.offset, Limit: tt.limit})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(r.Findings, tt.want) || r.Truncated != tt.truncated || r.Total != 5 || r.Counts[finding.TypeEmail] != 5 {
+			t.Errorf("Extract() offset %d limit %d = %+v, want findings %v, %d truncated", tt.offset, tt.limit, r, tt.want, tt.truncated)
+		}
+	}
+
+	// Counts are not paged
+	r, err := Extract(context.Background(), strings.NewReader(text), Options{Emails: true, Detail: DetailCounts, Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Findings != nil || r.Total != 5 || r.Truncated != 0 {
+		t.Errorf("counts detail with a limit = %+v", r)
+	}
+}
@@ -0,0 +1,69 @@
+// Package apk turns the entries of Android application packages and other zipped app
+// bundles into text the extractors can read.
+package apk
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/decode"
+)
+
+// MaxEntrySize is the largest uncompressed entry Walk reads; larger entries are skipped
+const MaxEntrySize = 256 << 20
+
+// skipped lists the extensions of entries without useful text, such as images and fonts
+var skipped = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".webp": true,
+	".ttf": true, ".otf": true, ".ogg": true, ".mp3": true, ".mp4": true,
+}
+
+// Walk calls fn with the name and text of each entry of the zip archive in data, in archive
+// order. Binary XML, such as AndroidManifest.xml and compiled layouts, and the resources.arsc
+// resource table are reduced to their string pools; other binary entries, such as DEX files
+// and native libraries, to their printable strings. Text entries are passed as stored.
+// Directories, media files and entries larger than MaxEntrySize are skipped.
+func Walk(data []byte, fn func(name string, text []byte) error) error {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return fmt.Errorf("error opening archive: %w", err)
+	}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || f.UncompressedSize64 > MaxEntrySize || skipped[strings.ToLower(path.Ext(f.Name))] {
+			continue
+		}
+		content, err := readEntry(f)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", f.Name, err)
+		}
+		if err := fn(f.Name, Text(content)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readEntry(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(io.LimitReader(rc, MaxEntrySize))
+}
+
+// Text returns the text of an archive entry: the string pool of binary XML and resource
+// tables, one string per line, the printable strings of other binary data and text as is
+func Text(data []byte) []byte {
+	if pool, err := Strings(data); err == nil {
+		return []byte(strings.Join(pool, "\n") + "\n")
+	}
+	if decode.Binary(data) {
+		return decode.Strings(data, decode.MinStringLength)
+	}
+	return data
+}
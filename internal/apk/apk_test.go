@@ -0,0 +1,131 @@
+package apk
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+	"unicode/utf16"
+)
+
+// binaryXML builds a minimal compiled XML document whose string pool holds strs
+func binaryXML(strs []string, utf8 bool) []byte {
+	var data []byte
+	var offsets []uint32
+	for _, s := range strs {
+		offsets = append(offsets, uint32(len(data)))
+		if utf8 {
+			data = append(data, byte(len([]rune(s))), byte(len(s)))
+			data = append(data, s...)
+			data = append(data, 0)
+			continue
+		}
+		units := utf16.Encode([]rune(s))
+		data = binary.LittleEndian.AppendUint16(data, uint16(len(units)))
+		for _, u := range units {
+			data = binary.LittleEndian.AppendUint16(data, u)
+		}
+		data = append(data, 0, 0)
+	}
+	for len(data)%4 != 0 {
+		data = append(data, 0)
+	}
+
+	var flags uint32
+	if utf8 {
+		flags = utf8Pool
+	}
+	start := 28 + 4*len(strs)
+	pool := binary.LittleEndian.AppendUint16(nil, chunkStringPool)
+	pool = binary.LittleEndian.AppendUint16(pool, 28)
+	pool = binary.LittleEndian.AppendUint32(pool, uint32(start+len(data)))
+	pool = binary.LittleEndian.AppendUint32(pool, uint32(len(strs)))
+	pool = binary.LittleEndian.AppendUint32(pool, 0)
+	pool = binary.LittleEndian.AppendUint32(pool, flags)
+	pool = binary.LittleEndian.AppendUint32(pool, uint32(start))
+	pool = binary.LittleEndian.AppendUint32(pool, 0)
+	for _, o := range offsets {
+		pool = binary.LittleEndian.AppendUint32(pool, o)
+	}
+	pool = append(pool, data...)
+
+	doc := binary.LittleEndian.AppendUint16(nil, chunkXML)
+	doc = binary.LittleEndian.AppendUint16(doc, 8)
+	doc = binary.LittleEndian.AppendUint32(doc, uint32(8+len(pool)))
+	return append(doc, pool...)
+}
+
+func TestStrings(t *testing.T) {
+	strs := []string{"manifest", "package", "com.target.app", "https://api.target.com/v2", "café"}
+	for _, utf8 := range []bool{false, true} {
+		got, err := Strings(binaryXML(strs, utf8))
+		if err != nil {
+			t.Fatalf("utf8=%v: Strings() error = %v", utf8, err)
+		}
+		if !reflect.DeepEqual(got, strs) {
+			t.Errorf("utf8=%v: Strings() = %q, want %q", utf8, got, strs)
+		}
+	}
+
+	doc := binaryXML(strs, false)
+	for _, bad := range [][]byte{nil, []byte("<manifest/>"), doc[:40]} {
+		if _, err := Strings(bad); err == nil {
+			t.Errorf("Strings(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestWalk(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	entries := []struct {
+		name string
+		data []byte
+	}{
+		{"AndroidManifest.xml", binaryXML([]string{"manifest", "https://api.target.com/"}, false)},
+		{"classes.dex", []byte("dex\n035\x00\x00\x01\x02dev@target.com\x00\x05ab\x00")},
+		{"res/drawable/icon.png", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")},
+		{"assets/config.json", []byte(`{"api": "https://cdn.target.com"}`)},
+		{"res/", nil},
+	}
+	for _, e := range entries {
+		w, err := zw.Create(e.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(e.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]string{}
+	var names []string
+	err := Walk(buf.Bytes(), func(name string, text []byte) error {
+		names = append(names, name)
+		got[name] = string(text)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk() error = %v", err)
+	}
+
+	want := map[string]string{
+		"AndroidManifest.xml": "manifest\nhttps://api.target.com/\n",
+		"classes.dex":         "dev@target.com\n",
+		"assets/config.json":  `{"api": "https://cdn.target.com"}`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Walk() = %q, want %q", got, want)
+	}
+	if wantNames := []string{"AndroidManifest.xml", "classes.dex", "assets/config.json"}; !reflect.DeepEqual(names, wantNames) {
+		t.Errorf("Walk() order = %q, want %q", names, wantNames)
+	}
+
+	if err := Walk([]byte("not a zip"), func(string, []byte) error { return nil }); err == nil {
+		t.Error("Walk() on a non-zip input succeeded, want an error")
+	}
+}
@@ -0,0 +1,130 @@
+package apk
+
+import (
+	"encoding/binary"
+	"errors"
+	"unicode/utf16"
+)
+
+// Chunk types of Android's compiled resource format
+const (
+	chunkStringPool = 0x0001
+	chunkTable      = 0x0002
+	chunkXML        = 0x0003
+)
+
+// utf8Pool marks string pools that store UTF-8 rather than UTF-16 strings
+const utf8Pool = 1 << 8
+
+var errNotResource = errors.New("not a binary XML document or resource table")
+
+// Strings returns the strings of the first string pool of a binary XML document or
+// resources.arsc table: element and attribute names, string attribute values and, for
+// resource tables, the values of string resources
+func Strings(data []byte) ([]string, error) {
+	if len(data) < 8 {
+		return nil, errNotResource
+	}
+	switch binary.LittleEndian.Uint16(data) {
+	case chunkXML, chunkTable:
+	default:
+		return nil, errNotResource
+	}
+	offset := int(binary.LittleEndian.Uint16(data[2:]))
+	if offset < 8 || offset+8 > len(data) || binary.LittleEndian.Uint16(data[offset:]) != chunkStringPool {
+		return nil, errors.New("no string pool")
+	}
+	return stringPool(data[offset:])
+}
+
+// stringPool decodes a ResStringPool chunk
+func stringPool(chunk []byte) ([]string, error) {
+	errTruncated := errors.New("truncated string pool")
+	if len(chunk) < 28 {
+		return nil, errTruncated
+	}
+	size := int(binary.LittleEndian.Uint32(chunk[4:]))
+	if size < 28 || size > len(chunk) {
+		return nil, errTruncated
+	}
+	chunk = chunk[:size]
+	headerSize := int(binary.LittleEndian.Uint16(chunk[2:]))
+	count := int(binary.LittleEndian.Uint32(chunk[8:]))
+	flags := binary.LittleEndian.Uint32(chunk[16:])
+	start := int(binary.LittleEndian.Uint32(chunk[20:]))
+	if count < 0 || headerSize < 28 || count > (size-headerSize)/4 || start > size {
+		return nil, errTruncated
+	}
+
+	strs := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		at := start + int(binary.LittleEndian.Uint32(chunk[headerSize+4*i:]))
+		if at < start || at >= size {
+			return nil, errTruncated
+		}
+		var s string
+		var ok bool
+		if flags&utf8Pool != 0 {
+			s, ok = utf8String(chunk[at:])
+		} else {
+			s, ok = utf16String(chunk[at:])
+		}
+		if !ok {
+			return nil, errTruncated
+		}
+		strs = append(strs, s)
+	}
+	return strs, nil
+}
+
+// utf8String decodes a string of a UTF-8 pool: its length in UTF-16 units and in bytes,
+// each stored in one or two bytes, followed by the bytes
+func utf8String(b []byte) (string, bool) {
+	_, b, ok := length8(b)
+	if !ok {
+		return "", false
+	}
+	n, b, ok := length8(b)
+	if !ok || n > len(b) {
+		return "", false
+	}
+	return string(b[:n]), true
+}
+
+func length8(b []byte) (int, []byte, bool) {
+	if len(b) < 1 {
+		return 0, nil, false
+	}
+	if b[0]&0x80 == 0 {
+		return int(b[0]), b[1:], true
+	}
+	if len(b) < 2 {
+		return 0, nil, false
+	}
+	return int(b[0]&0x7F)<<8 | int(b[1]), b[2:], true
+}
+
+// utf16String decodes a string of a UTF-16 pool: its length in units, stored in one or two
+// units, followed by the units
+func utf16String(b []byte) (string, bool) {
+	if len(b) < 2 {
+		return "", false
+	}
+	n := int(binary.LittleEndian.Uint16(b))
+	b = b[2:]
+	if n&0x8000 != 0 {
+		if len(b) < 2 {
+			return "", false
+		}
+		n = (n&0x7FFF)<<16 | int(binary.LittleEndian.Uint16(b))
+		b = b[2:]
+	}
+	if n > len(b)/2 {
+		return "", false
+	}
+	units := make([]uint16, n)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(b[2*i:])
+	}
+	return string(utf16.Decode(units)), true
+}
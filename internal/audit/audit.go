@@ -0,0 +1,298 @@
+// Package audit appends findings to a newline-delimited JSON log shared by every run, for
+// continuous recon programs that must show what was found and when. Each entry records the
+// SHA-256 of the line before it, so editing, removing or reordering entries breaks the chain
+// and is reported by Verify. Removing the last entries leaves a valid chain, so the number
+// of entries and the SHA-256 of the last line are also recorded in a head file beside the
+// log, which VerifyFile checks the log against. Whoever can rewrite both files can still
+// truncate the log undetected, unless the head is copied somewhere they cannot reach.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/tempdir"
+)
+
+// Entry is a line of the audit log
+type Entry struct {
+	// RunID identifies the run that reported the finding
+	RunID string `json:"run_id"`
+	// Time is when the run reported the finding
+	Time time.Time `json:"time"`
+	// Finding is the reported finding
+	Finding finding.Finding `json:"finding"`
+	// Prev is the hex SHA-256 of the previous line without its newline, or "" for the first
+	Prev string `json:"prev"`
+}
+
+// head is the content of the head file of a log
+type head struct {
+	// Entries is the number of entries of the log
+	Entries int `json:"entries"`
+	// Last is the hex SHA-256 of the last line of the log without its newline
+	Last string `json:"last"`
+}
+
+// HeadPath returns the path of the head file of the log at path
+func HeadPath(path string) string {
+	return path + ".head"
+}
+
+// appendMu serializes appends within the process; lockFile serializes them between processes
+var appendMu sync.Mutex
+
+// NewRunID returns a random identifier for a run
+func NewRunID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		// crypto/rand does not fail on supported platforms
+		panic(err)
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// Append adds an entry for each finding to the log at path, creating it if needed, and
+// updates its head file. The entries are ordered with finding.Sort and written with a single
+// append, chained to the last line already in the log. The log is locked while it is read
+// and appended to, so concurrent runs do not chain entries to the same line. A log shorter
+// than its head records is not appended to.
+func Append(path, runID string, at time.Time, findings []finding.Finding) error {
+	if len(findings) == 0 {
+		return nil
+	}
+	appendMu.Lock()
+	defer appendMu.Unlock()
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	unlock, err := lockFile(f)
+	if err != nil {
+		return fmt.Errorf("%s: locking: %w", path, err)
+	}
+	defer unlock()
+
+	last, err := lastLine(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	prev := ""
+	if last != nil {
+		prev = digest(last)
+	}
+	h, err := readHead(path)
+	if err != nil {
+		return err
+	}
+	// The head is behind the log when a run stopped between writing the two, and the log
+	// behind the head when it was truncated; either way the log is verified against it
+	if h == nil || h.Last != prev {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		n, err := verifyHead(f, h)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		h = &head{Entries: n, Last: prev}
+	}
+
+	sorted := make([]finding.Finding, len(findings))
+	copy(sorted, findings)
+	finding.Sort(sorted)
+	var buf bytes.Buffer
+	for _, fnd := range sorted {
+		line, err := json.Marshal(Entry{RunID: runID, Time: at.UTC(), Finding: fnd, Prev: prev})
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+		prev = digest(line)
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	return writeHead(path, head{Entries: h.Entries + len(sorted), Last: prev})
+}
+
+// Verify reads a log and checks that every entry is valid JSON chained to the line before
+// it. It returns the number of entries read and the first break in the chain. Removing the
+// last entries is not detected; VerifyFile also checks the log against its head file.
+func Verify(r io.Reader) (int, error) {
+	return verify(r, nil)
+}
+
+// VerifyFile verifies the log at path like Verify and checks that it holds at least the
+// entries recorded in its head file, ending at the last of them unless it was appended to
+// since. A log without a head file, written before head files were kept, is only verified.
+func VerifyFile(path string) (int, error) {
+	h, err := readHead(path)
+	if err != nil {
+		return 0, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	n, err := verifyHead(f, h)
+	if err != nil {
+		return n, fmt.Errorf("%s: %w", path, err)
+	}
+	return n, nil
+}
+
+// verifyHead verifies the log read from r and, unless h is nil, that entry h.Entries is the
+// line recorded in h
+func verifyHead(r io.Reader, h *head) (int, error) {
+	if h == nil || h.Entries == 0 {
+		return verify(r, nil)
+	}
+	marked := ""
+	n, err := verify(r, func(entry int, sum string) {
+		if entry == h.Entries {
+			marked = sum
+		}
+	})
+	if err != nil {
+		return n, err
+	}
+	if n < h.Entries {
+		return n, fmt.Errorf("truncated: the head records %d entries", h.Entries)
+	}
+	if marked != h.Last {
+		return n, fmt.Errorf("entry %d is not the last entry recorded in the head", h.Entries)
+	}
+	return n, nil
+}
+
+// verify checks the chain of the log read from r, calling fn, unless nil, with the number
+// and the digest of each entry
+func verify(r io.Reader, fn func(entry int, sum string)) (int, error) {
+	br := bufio.NewReader(r)
+	prev := ""
+	n := 0
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			if line[len(line)-1] != '\n' {
+				return n, fmt.Errorf("line %d: incomplete entry", n+1)
+			}
+			line = line[:len(line)-1]
+			var e Entry
+			if err := json.Unmarshal(line, &e); err != nil {
+				return n, fmt.Errorf("line %d: %w", n+1, err)
+			}
+			if e.Prev != prev {
+				return n, fmt.Errorf("line %d: chain broken, the previous entry was modified or removed", n+1)
+			}
+			prev = digest(line)
+			n++
+			if fn != nil {
+				fn(n, prev)
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+	}
+}
+
+// readHead reads the head file of the log at path, or returns nil if there is none
+func readHead(path string) (*head, error) {
+	data, err := os.ReadFile(HeadPath(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var h head
+	if err := json.Unmarshal(data, &h); err != nil {
+		return nil, fmt.Errorf("%s: %w", HeadPath(path), err)
+	}
+	return &h, nil
+}
+
+// writeHead replaces the head file of the log at path, through a temporary file so that it
+// is never seen half written
+func writeHead(path string, h head) error {
+	data, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(HeadPath(path))+"-*")
+	if err != nil {
+		return err
+	}
+	defer tempdir.Track(tmp.Name())()
+	_, err = tmp.Write(append(data, '\n'))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), HeadPath(path))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// lastLine returns the last line of f without its newline, or nil for an empty file. A
+// file not ending with a newline holds an entry cut short, which is an error.
+func lastLine(f *os.File) ([]byte, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := info.Size()
+	if size == 0 {
+		return nil, nil
+	}
+
+	const block = 4096
+	var tail []byte
+	for end := size; end > 0; {
+		start := max(end-block, 0)
+		buf := make([]byte, end-start)
+		if _, err := f.ReadAt(buf, start); err != nil {
+			return nil, err
+		}
+		tail = append(buf, tail...)
+		if tail[len(tail)-1] != '\n' {
+			return nil, errors.New("the last entry is incomplete")
+		}
+		if i := bytes.LastIndexByte(tail[:len(tail)-1], '\n'); i >= 0 {
+			return tail[i+1 : len(tail)-1], nil
+		}
+		end = start
+	}
+	return tail[:len(tail)-1], nil
+}
+
+func digest(line []byte) string {
+	sum := sha256.Sum256(line)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,147 @@
+package audit
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+func TestAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.ndjson")
+	at := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	first := []finding.Finding{
+		{Type: finding.TypeDomain, Value: "api.target.com"},
+		{Type: finding.TypeEmail, Value: "dev@target.com"},
+	}
+	if err := Append(path, "run-1", at, first); err != nil {
+		t.Fatal(err)
+	}
+	if err := Append(path, "run-2", at.Add(time.Hour), []finding.Finding{{Type: finding.TypeDomain, Value: "new.target.com"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := Verify(bytes.NewReader(data)); err != nil || n != 3 {
+		t.Fatalf("Verify() = %d, %v, want 3 entries", n, err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if !strings.Contains(lines[2], `"run_id":"run-2"`) || !strings.Contains(lines[2], `"time":"2024-05-01T11:00:00Z"`) {
+		t.Errorf("last entry = %s", lines[2])
+	}
+
+	tampered := strings.Replace(string(data), "api.target.com", "www.target.com", 1)
+	if _, err := Verify(strings.NewReader(tampered)); err == nil || !strings.Contains(err.Error(), "line 3: chain broken") {
+		t.Errorf("Verify() of an edited log error = %v, want a broken chain at line 3", err)
+	}
+	removed := lines[0] + "\n" + lines[2] + "\n"
+	if _, err := Verify(strings.NewReader(removed)); err == nil {
+		t.Error("Verify() of a log with a removed entry succeeded")
+	}
+}
+
+func TestAppend_IncompleteEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.ndjson")
+	if err := os.WriteFile(path, []byte(`{"run_id":"run-1"`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	err := Append(path, "run-2", time.Now(), []finding.Finding{{Type: finding.TypeDomain, Value: "target.com"}})
+	if err == nil || !strings.Contains(err.Error(), "incomplete") {
+		t.Errorf("Append() error = %v, want an incomplete entry error", err)
+	}
+}
+
+func TestLastLine_LongEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.ndjson")
+	long := []finding.Finding{{Type: finding.TypeURL, Value: "https://target.com/?q=" + strings.Repeat("a", 10000)}}
+	for i := 0; i < 3; i++ {
+		if err := Append(path, "run", time.Now(), long); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := Verify(bytes.NewReader(data)); err != nil || n != 3 {
+		t.Errorf("Verify() = %d, %v, want 3 entries", n, err)
+	}
+}
+
+func TestVerifyFile_Truncated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.ndjson")
+	for i, value := range []string{"a.target.com", "b.target.com", "c.target.com"} {
+		if err := Append(path, "run", time.Now(), []finding.Finding{{Type: finding.TypeDomain, Value: value}}); err != nil {
+			t.Fatalf("Append() %d error = %v", i, err)
+		}
+	}
+	if n, err := VerifyFile(path); err != nil || n != 3 {
+		t.Fatalf("VerifyFile() = %d, %v, want 3 entries", n, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The chain of the first two entries is valid, but the head records three
+	lines := strings.SplitAfter(string(data), "\n")
+	if err := os.WriteFile(path, []byte(lines[0]+lines[1]), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := Verify(strings.NewReader(lines[0] + lines[1])); err != nil || n != 2 {
+		t.Fatalf("Verify() of the truncated log = %d, %v", n, err)
+	}
+	if _, err := VerifyFile(path); err == nil || !strings.Contains(err.Error(), "truncated") {
+		t.Errorf("VerifyFile() of a truncated log error = %v, want truncated", err)
+	}
+	if err := Append(path, "run", time.Now(), []finding.Finding{{Type: finding.TypeDomain, Value: "d.target.com"}}); err == nil {
+		t.Error("Append() continued a truncated log")
+	}
+
+	// A head behind the log, left by a run stopped between writing them, is caught up
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeHead(path, head{Entries: 2, Last: digest([]byte(strings.TrimSuffix(lines[1], "\n")))}); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := VerifyFile(path); err != nil || n != 3 {
+		t.Errorf("VerifyFile() with the head behind = %d, %v, want 3 entries", n, err)
+	}
+	if err := Append(path, "run", time.Now(), []finding.Finding{{Type: finding.TypeDomain, Value: "d.target.com"}}); err != nil {
+		t.Fatal(err)
+	}
+	if h, err := readHead(path); err != nil || h.Entries != 4 {
+		t.Errorf("head after catching up = %+v, %v, want 4 entries", h, err)
+	}
+}
+
+func TestAppend_Concurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.ndjson")
+	const runs = 20
+	errs := make(chan error, runs)
+	for i := 0; i < runs; i++ {
+		go func(i int) {
+			errs <- Append(path, NewRunID(), time.Now(), []finding.Finding{
+				{Type: finding.TypeDomain, Value: fmt.Sprintf("a%d.target.com", i)},
+				{Type: finding.TypeDomain, Value: fmt.Sprintf("b%d.target.com", i)},
+			})
+		}(i)
+	}
+	for i := 0; i < runs; i++ {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n, err := VerifyFile(path); err != nil || n != 2*runs {
+		t.Errorf("VerifyFile() = %d, %v, want %d entries", n, err, 2*runs)
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !unix && !windows
+
+package audit
+
+import (
+	"errors"
+	"os"
+)
+
+// Locking reports whether logs can be locked against other processes on this platform;
+// where they cannot, Append fails rather than risk two runs chaining to the same line
+const Locking = false
+
+// lockFile fails where files cannot be locked
+func lockFile(f *os.File) (func(), error) {
+	return nil, errors.New("file locking is not supported on this platform")
+}
@@ -0,0 +1,20 @@
+//go:build unix
+
+package audit
+
+import (
+	"os"
+	"syscall"
+)
+
+// Locking reports whether logs can be locked against other processes on this platform;
+// where they cannot, Append fails rather than risk two runs chaining to the same line
+const Locking = true
+
+// lockFile takes an exclusive lock on f, held until the returned function is called
+func lockFile(f *os.File) (func(), error) {
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		return nil, err
+	}
+	return func() { syscall.Flock(int(f.Fd()), syscall.LOCK_UN) }, nil
+}
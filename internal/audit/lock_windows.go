@@ -0,0 +1,39 @@
+//go:build windows
+
+package audit
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// Locking reports whether logs can be locked against other processes on this platform;
+// where they cannot, Append fails rather than risk two runs chaining to the same line
+const Locking = true
+
+var (
+	kernel32         = syscall.NewLazyDLL("kernel32.dll")
+	procLockFileEx   = kernel32.NewProc("LockFileEx")
+	procUnlockFileEx = kernel32.NewProc("UnlockFileEx")
+)
+
+// lockfileExclusiveLock is the LOCKFILE_EXCLUSIVE_LOCK flag of LockFileEx
+const lockfileExclusiveLock = 0x2
+
+// lockRange returns the byte range locked: a single byte far past the end of any log, since
+// Windows locks are mandatory and locking the log's own bytes would keep other processes
+// from verifying it
+func lockRange() *syscall.Overlapped {
+	return &syscall.Overlapped{Offset: ^uint32(0), OffsetHigh: 0x7fffffff}
+}
+
+// lockFile takes an exclusive lock on f, held until the returned function is called
+func lockFile(f *os.File) (func(), error) {
+	h := f.Fd()
+	r, _, err := procLockFileEx.Call(h, lockfileExclusiveLock, 0, 1, 0, uintptr(unsafe.Pointer(lockRange())))
+	if r == 0 {
+		return nil, err
+	}
+	return func() { procUnlockFileEx.Call(h, 0, 1, 0, uintptr(unsafe.Pointer(lockRange()))) }, nil
+}
@@ -0,0 +1,50 @@
+// Package avatars links avatar hashes, such as the MD5 and SHA-256 email hashes in Gravatar
+// and Libravatar URLs, back to the email addresses they were computed from.
+package avatars
+
+import (
+	"crypto/md5" // #nosec G501 -- Gravatar identifies avatars by the MD5 of the email address
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+// Hashes returns the MD5 and SHA-256 avatar hashes of email, computed as Gravatar does
+// from the trimmed, lowercased address
+func Hashes(email string) (md5Hex, sha256Hex string) {
+	normalized := []byte(strings.ToLower(strings.TrimSpace(email)))
+	m := md5.Sum(normalized) // #nosec G401 -- matching an existing hash, not protecting data
+	s := sha256.Sum256(normalized)
+	return hex.EncodeToString(m[:]), hex.EncodeToString(s[:])
+}
+
+// Correlate hashes every email finding and records the address on the avatar hash findings
+// it matches, in the "email" metadata, raising them to high confidence. It returns the number
+// of avatar hashes matched.
+func Correlate(findings []finding.Finding) int {
+	emails := make(map[string]string)
+	for _, f := range findings {
+		if f.Type != finding.TypeEmail {
+			continue
+		}
+		m, s := Hashes(f.Value)
+		emails[m] = f.Value
+		emails[s] = f.Value
+	}
+
+	matched := 0
+	for i := range findings {
+		f := &findings[i]
+		if f.Type != finding.TypeAvatarHash {
+			continue
+		}
+		if email, ok := emails[strings.ToLower(f.Value)]; ok {
+			f.SetMeta("email", email)
+			f.Confidence = finding.ConfidenceHigh
+			matched++
+		}
+	}
+	return matched
+}
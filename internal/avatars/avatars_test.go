@@ -0,0 +1,39 @@
+package avatars
+
+import (
+	"testing"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+func TestHashes(t *testing.T) {
+	// The example from the Gravatar documentation
+	m, s := Hashes(" MyEmailAddress@example.com ")
+	if m != "0bc83cb571cd1c50ba6f3e8a78ef1346" {
+		t.Errorf("MD5 = %s", m)
+	}
+	if s != "84059b07d4be67b806386c0aad8070a23f18836bbaae342275dc0a83414c32ee" {
+		t.Errorf("SHA-256 = %s", s)
+	}
+}
+
+func TestCorrelate(t *testing.T) {
+	findings := []finding.Finding{
+		{Type: finding.TypeEmail, Value: "myemailaddress@example.com"},
+		{Type: finding.TypeAvatarHash, Value: "0bc83cb571cd1c50ba6f3e8a78ef1346", Confidence: finding.ConfidenceMedium},
+		{Type: finding.TypeAvatarHash, Value: "84059B07D4BE67B806386C0AAD8070A23F18836BBAAE342275DC0A83414C32EE"},
+		{Type: finding.TypeAvatarHash, Value: "00000000000000000000000000000000", Confidence: finding.ConfidenceMedium},
+	}
+
+	if n := Correlate(findings); n != 2 {
+		t.Errorf("Correlate() = %d, want 2", n)
+	}
+	for _, f := range findings[1:3] {
+		if f.Metadata["email"] != "myemailaddress@example.com" || f.Confidence != finding.ConfidenceHigh {
+			t.Errorf("%s = %v (%s), want the matching email with high confidence", f.Value, f.Metadata, f.Confidence)
+		}
+	}
+	if _, ok := findings[3].Metadata["email"]; ok || findings[3].Confidence != finding.ConfidenceMedium {
+		t.Errorf("unmatched hash was changed: %+v", findings[3])
+	}
+}
@@ -0,0 +1,62 @@
+// Package bloom implements a Bloom filter: a set of fixed size that may wrongly report a key
+// it has not seen, at a rate chosen when it is created, but never misses one it has. It
+// deduplicates findings in -low-memory mode, where keeping every finding seen is too costly.
+package bloom
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+)
+
+// Filter is a Bloom filter. It is not safe for concurrent use.
+type Filter struct {
+	bits []uint64
+	// m is the number of bits, k the number of bits set per key
+	m uint64
+	k int
+}
+
+// New returns a filter holding n keys with a false positive rate of p. The rate grows as
+// more keys are added. p must be between 0 and 1 exclusive, otherwise 1% is used.
+func New(n int, p float64) *Filter {
+	if n < 1 {
+		n = 1
+	}
+	if p <= 0 || p >= 1 {
+		p = 0.01
+	}
+	m := math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2))
+	k := max(1, int(math.Round(m/float64(n)*math.Ln2)))
+	words := (uint64(m) + 63) / 64
+	return &Filter{bits: make([]uint64, words), m: words * 64, k: k}
+}
+
+// Add adds key and reports whether it may have been added before
+func (f *Filter) Add(key string) bool {
+	h1, h2 := hashes(key)
+	seen := true
+	for i := 0; i < f.k; i++ {
+		bit := (h1 + uint64(i)*h2) % f.m
+		word, mask := bit/64, uint64(1)<<(bit%64)
+		if f.bits[word]&mask == 0 {
+			seen = false
+			f.bits[word] |= mask
+		}
+	}
+	return seen
+}
+
+// Size returns the memory used by the bits of the filter, in bytes
+func (f *Filter) Size() int {
+	return len(f.bits) * 8
+}
+
+// hashes returns the two halves of the 128-bit FNV-1a hash of key, from which the k bit
+// positions are derived. The second is odd so that the positions do not repeat.
+func hashes(key string) (uint64, uint64) {
+	h := fnv.New128a()
+	h.Write([]byte(key))
+	sum := h.Sum(nil)
+	return binary.BigEndian.Uint64(sum[:8]), binary.BigEndian.Uint64(sum[8:]) | 1
+}
@@ -0,0 +1,38 @@
+package bloom
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	const n = 10000
+	f := New(n, 0.01)
+	falsePositives := 0
+	for i := 0; i < n; i++ {
+		if f.Add("https://target.com/item/" + strconv.Itoa(i)) {
+			falsePositives++
+		}
+	}
+	// Keys added while the filter fills up see a lower rate than the final 1%
+	if falsePositives > n/100 {
+		t.Errorf("%d false positives among %d new keys, want at most %d", falsePositives, n, n/100)
+	}
+	for i := 0; i < n; i++ {
+		if key := "https://target.com/item/" + strconv.Itoa(i); !f.Add(key) {
+			t.Fatalf("Add(%q) = false for a key added before", key)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	// About 9.6 bits per key for a 1% rate
+	if got := New(1<<20, 0.01).Size(); got < 1<<20 || got > 5<<19 {
+		t.Errorf("Size() = %d bytes for a million keys at 1%%", got)
+	}
+	// Invalid parameters fall back to a usable filter
+	f := New(0, 2)
+	if f.Add("a") || !f.Add("a") {
+		t.Error("filter created with invalid parameters does not work")
+	}
+}
@@ -0,0 +1,115 @@
+// Package cache stores the results of network lookups on disk so that repeated runs against
+// overlapping scopes do not redo identical requests. Entries expire after a TTL.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/PeteJStewart/urlsluice/internal/tempdir"
+)
+
+// DefaultTTL is how long entries are used when no TTL is configured
+const DefaultTTL = 24 * time.Hour
+
+// Cache is an on-disk store of JSON encoded lookup results, grouped by kind (e.g. "probe" or
+// "dns") and keyed by URL or host. A nil *Cache is valid and caches nothing, so callers can
+// leave caching disabled without checks. It is safe for concurrent use.
+type Cache struct {
+	dir     string
+	ttl     time.Duration
+	variant string
+	now     func() time.Time
+}
+
+// entry is the file format of a cached value
+type entry struct {
+	Key    string          `json:"key"`
+	Stored time.Time       `json:"stored"`
+	Value  json.RawMessage `json:"value"`
+}
+
+// DefaultDir returns the urlsluice directory under the user's cache directory
+func DefaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "urlsluice"), nil
+}
+
+// New opens the cache in dir, creating it if needed. Entries older than ttl are ignored
+// (DefaultTTL when zero). variant is mixed into every key so that results fetched with
+// different settings, such as request headers, are kept apart.
+func New(dir string, ttl time.Duration, variant string) (*Cache, error) {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("creating cache directory: %w", err)
+	}
+	return &Cache{dir: dir, ttl: ttl, variant: variant, now: time.Now}, nil
+}
+
+// Get decodes the unexpired value stored for key into v and reports whether there was one
+func (c *Cache) Get(kind, key string, v any) bool {
+	if c == nil {
+		return false
+	}
+	data, err := os.ReadFile(c.path(kind, key))
+	if err != nil {
+		return false
+	}
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil || e.Key != key || c.now().Sub(e.Stored) > c.ttl {
+		return false
+	}
+	return json.Unmarshal(e.Value, v) == nil
+}
+
+// Put stores v for key. Failures are ignored: the cache only saves work, so a read-only or
+// full disk must not fail the run.
+func (c *Cache) Put(kind, key string, v any) {
+	if c == nil {
+		return
+	}
+	value, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	data, err := json.Marshal(entry{Key: key, Stored: c.now(), Value: value})
+	if err != nil {
+		return
+	}
+	path := c.path(kind, key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return
+	}
+	// Write to a temporary file first so concurrent readers never see a partial entry
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return
+	}
+	defer tempdir.Track(tmp.Name())()
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// path returns the file holding key, named by a hash so any key is a valid file name
+func (c *Cache) path(kind, key string) string {
+	sum := sha256.Sum256([]byte(c.variant + "\x00" + key))
+	return filepath.Join(c.dir, kind, hex.EncodeToString(sum[:])+".json")
+}
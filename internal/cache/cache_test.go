@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCache_GetPut(t *testing.T) {
+	c, err := New(t.TempDir(), time.Hour, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type result struct{ Status int }
+	var got result
+	if c.Get("probe", "https://target.com/", &got) {
+		t.Fatal("Get() found a value in an empty cache")
+	}
+	c.Put("probe", "https://target.com/", result{Status: 200})
+	if !c.Get("probe", "https://target.com/", &got) || got.Status != 200 {
+		t.Errorf("Get() = %+v, want the stored value", got)
+	}
+	if c.Get("enrich", "https://target.com/", &got) {
+		t.Error("Get() found a value stored under another kind")
+	}
+}
+
+func TestCache_Expiry(t *testing.T) {
+	c, err := New(t.TempDir(), time.Minute, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	c.now = func() time.Time { return now }
+	c.Put("ct", "target.com", []string{"www.target.com"})
+
+	var names []string
+	if !c.Get("ct", "target.com", &names) {
+		t.Fatal("Get() missed a fresh entry")
+	}
+	now = now.Add(2 * time.Minute)
+	if c.Get("ct", "target.com", &names) {
+		t.Errorf("Get() = %v from an expired entry", names)
+	}
+}
+
+func TestCache_Variant(t *testing.T) {
+	dir := t.TempDir()
+	anonymous, _ := New(dir, time.Hour, "")
+	authenticated, _ := New(dir, time.Hour, "Cookie: session=1")
+
+	anonymous.Put("probe", "https://target.com/admin", 401)
+	var status int
+	if authenticated.Get("probe", "https://target.com/admin", &status) {
+		t.Error("a result fetched with other headers was reused")
+	}
+}
+
+func TestCache_Nil(t *testing.T) {
+	var c *Cache
+	c.Put("probe", "key", 1)
+	var v int
+	if c.Get("probe", "key", &v) {
+		t.Error("nil cache returned a value")
+	}
+}
+
+// countingResolver answers every CNAME lookup with itself and every host lookup with not found
+type countingResolver struct {
+	lookups int
+}
+
+func (r *countingResolver) LookupCNAME(_ context.Context, host string) (string, error) {
+	r.lookups++
+	return host + ".", nil
+}
+
+func (r *countingResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	r.lookups++
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func TestResolver(t *testing.T) {
+	c, err := New(filepath.Join(t.TempDir(), "cache"), time.Hour, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	upstream := &countingResolver{}
+	r := &Resolver{Resolver: upstream, Cache: c}
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		cname, err := r.LookupCNAME(ctx, "www.target.com")
+		if err != nil || cname != "www.target.com." {
+			t.Errorf("LookupCNAME() = %q, %v", cname, err)
+		}
+		_, err = r.LookupHost(ctx, "gone.target.com")
+		var dnsErr *net.DNSError
+		if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+			t.Errorf("LookupHost() error = %v, want not found", err)
+		}
+	}
+	if upstream.lookups != 2 {
+		t.Errorf("upstream saw %d lookups, want 2", upstream.lookups)
+	}
+}
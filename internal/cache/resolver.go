@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"net"
+)
+
+// DNSResolver looks up DNS records; it is satisfied by *net.Resolver
+type DNSResolver interface {
+	LookupCNAME(ctx context.Context, host string) (string, error)
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// Resolver caches the answers of a DNS resolver, including answers that a name does not
+// exist. Failed lookups are not cached.
+type Resolver struct {
+	// Resolver performs the lookups that are not cached; net.DefaultResolver is used when nil
+	Resolver DNSResolver
+	// Cache stores the answers; when nil every lookup is passed through
+	Cache *Cache
+}
+
+// dnsAnswer is the cached form of a lookup result
+type dnsAnswer struct {
+	Values   []string `json:"values,omitempty"`
+	NotFound bool     `json:"not_found,omitempty"`
+}
+
+// LookupCNAME returns the canonical name of host
+func (r *Resolver) LookupCNAME(ctx context.Context, host string) (string, error) {
+	answer, err := r.lookup("dns-cname", host, func() ([]string, error) {
+		cname, err := r.resolver().LookupCNAME(ctx, host)
+		return []string{cname}, err
+	})
+	if err != nil || len(answer) == 0 {
+		return "", err
+	}
+	return answer[0], nil
+}
+
+// LookupHost returns the addresses of host
+func (r *Resolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	return r.lookup("dns-host", host, func() ([]string, error) {
+		return r.resolver().LookupHost(ctx, host)
+	})
+}
+
+func (r *Resolver) lookup(kind, host string, fetch func() ([]string, error)) ([]string, error) {
+	var answer dnsAnswer
+	if r.Cache.Get(kind, host, &answer) {
+		if answer.NotFound {
+			return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+		}
+		return answer.Values, nil
+	}
+
+	values, err := fetch()
+	var dnsErr *net.DNSError
+	switch {
+	case err == nil:
+		r.Cache.Put(kind, host, dnsAnswer{Values: values})
+	case errors.As(err, &dnsErr) && dnsErr.IsNotFound:
+		r.Cache.Put(kind, host, dnsAnswer{NotFound: true})
+	}
+	return values, err
+}
+
+func (r *Resolver) resolver() DNSResolver {
+	if r.Resolver != nil {
+		return r.Resolver
+	}
+	return net.DefaultResolver
+}
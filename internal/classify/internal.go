@@ -0,0 +1,88 @@
+// Package classify tags findings according to what their values look like.
+package classify
+
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+// internalTLDs are top level domains that are not delegated in the public DNS and are
+// commonly used on private networks
+var internalTLDs = map[string]bool{
+	"corp":        true,
+	"home":        true,
+	"internal":    true,
+	"intranet":    true,
+	"lan":         true,
+	"local":       true,
+	"localdomain": true,
+	"localhost":   true,
+	"priv":        true,
+	"private":     true,
+}
+
+// internalLabels are subdomain labels that usually name an internal network zone
+var internalLabels = map[string]bool{
+	"corp":     true,
+	"internal": true,
+	"intra":    true,
+	"intranet": true,
+	"lan":      true,
+	"local":    true,
+	"private":  true,
+}
+
+// IsInternalHost reports whether host is likely only reachable from a private network:
+// private, loopback and link-local IPs, single-label names, non-public TLDs such as .local or
+// .corp, and names with an internal zone label such as jira.corp.acme.com
+func IsInternalHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if host == "" {
+		return false
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsUnspecified()
+	}
+	labels := strings.Split(host, ".")
+	if len(labels) == 1 {
+		return true
+	}
+	if internalTLDs[labels[len(labels)-1]] || strings.HasSuffix(host, ".home.arpa") {
+		return true
+	}
+	// The registrable name itself (e.g. corp.com) is public; only subdomain labels count
+	for _, label := range labels[:len(labels)-2] {
+		if internalLabels[label] {
+			return true
+		}
+	}
+	return false
+}
+
+// Internal tags domain, IP and URL findings whose host is internal with finding.TagInternal
+func Internal(findings []finding.Finding) {
+	for i := range findings {
+		if host := HostOf(findings[i]); host != "" && IsInternalHost(host) {
+			findings[i].AddTag(finding.TagInternal)
+		}
+	}
+}
+
+// HostOf returns the host a finding refers to, or "" for findings that do not name a host
+func HostOf(f finding.Finding) string {
+	switch f.Type {
+	case finding.TypeDomain, finding.TypeIP:
+		return f.Value
+	case finding.TypeURL:
+		if u, err := url.Parse(f.Value); err == nil {
+			return u.Hostname()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,59 @@
+package classify
+
+import (
+	"testing"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+func TestIsInternalHost(t *testing.T) {
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"intranet", true},
+		{"jenkins.local", true},
+		{"db.prod.internal", true},
+		{"jira.corp.acme.com", true},
+		{"wiki.intranet.acme.com", true},
+		{"router.home.arpa", true},
+		{"10.0.0.5", true},
+		{"172.20.1.1", true},
+		{"192.168.1.1:8080", true},
+		{"127.0.0.1", true},
+		{"169.254.169.254", true},
+		{"corp.com", false},
+		{"internal.io", false},
+		{"www.acme.com", false},
+		{"8.8.8.8", false},
+		{"172.32.0.1", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			if got := IsInternalHost(tt.host); got != tt.want {
+				t.Errorf("IsInternalHost(%q) = %v, want %v", tt.host, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInternal(t *testing.T) {
+	findings := []finding.Finding{
+		{Type: finding.TypeDomain, Value: "jenkins.corp.acme.com"},
+		{Type: finding.TypeDomain, Value: "www.acme.com"},
+		{Type: finding.TypeIP, Value: "10.1.2.3"},
+		{Type: finding.TypeURL, Value: "http://192.168.0.10:8080/admin"},
+		{Type: finding.TypeEmail, Value: "ops@corp.local"},
+	}
+
+	Internal(findings)
+
+	want := []bool{true, false, true, true, false}
+	for i, f := range findings {
+		if got := f.HasTag(finding.TagInternal); got != want[i] {
+			t.Errorf("%s %q internal = %v, want %v", f.Type, f.Value, got, want[i])
+		}
+	}
+}
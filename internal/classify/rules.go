@@ -0,0 +1,43 @@
+package classify
+
+import (
+	"regexp"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+// TagRule attaches Tag to findings whose value matches Pattern.
+// When Types is non-empty the rule only applies to findings of those types.
+type TagRule struct {
+	Pattern *regexp.Regexp
+	Tag     string
+	Types   []finding.Type
+}
+
+// Applies reports whether the rule matches f
+func (r TagRule) Applies(f finding.Finding) bool {
+	if len(r.Types) > 0 {
+		found := false
+		for _, t := range r.Types {
+			if t == f.Type {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return r.Pattern.MatchString(f.Value)
+}
+
+// Tag applies every rule to every finding, adding the tags of the rules that match
+func Tag(findings []finding.Finding, rules []TagRule) {
+	for i := range findings {
+		for _, rule := range rules {
+			if rule.Applies(findings[i]) {
+				findings[i].AddTag(rule.Tag)
+			}
+		}
+	}
+}
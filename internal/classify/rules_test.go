@@ -0,0 +1,30 @@
+package classify
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+func TestTag(t *testing.T) {
+	rules := []TagRule{
+		{Pattern: regexp.MustCompile(`staging`), Tag: "staging"},
+		{Pattern: regexp.MustCompile(`^admin\.`), Tag: "admin", Types: []finding.Type{finding.TypeDomain}},
+	}
+	findings := []finding.Finding{
+		{Type: finding.TypeDomain, Value: "admin.staging.target.com"},
+		{Type: finding.TypeURL, Value: "https://admin.target.com/"},
+		{Type: finding.TypeDomain, Value: "www.target.com", Tags: []string{finding.TagInternal}},
+	}
+
+	Tag(findings, rules)
+
+	want := [][]string{{"staging", "admin"}, nil, {finding.TagInternal}}
+	for i, f := range findings {
+		if !reflect.DeepEqual(f.Tags, want[i]) {
+			t.Errorf("%q tags = %v, want %v", f.Value, f.Tags, want[i])
+		}
+	}
+}
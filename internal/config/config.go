@@ -0,0 +1,292 @@
+// Package config loads the urlsluice YAML configuration file.
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/classify"
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/plugin"
+	"github.com/PeteJStewart/urlsluice/internal/regexcache"
+	"github.com/PeteJStewart/urlsluice/internal/regexlint"
+	"github.com/PeteJStewart/urlsluice/internal/remediation"
+	"github.com/PeteJStewart/urlsluice/internal/tracking"
+	"github.com/PeteJStewart/urlsluice/internal/transform"
+	"github.com/PeteJStewart/urlsluice/internal/wordlist"
+	"github.com/PeteJStewart/urlsluice/internal/yamlcheck"
+)
+
+// Config represents the YAML configuration file
+type Config struct {
+	// SHA256 is the checksum of the file the configuration was loaded from
+	SHA256 string `yaml:"-"`
+	// Tags are the custom tagging rules applied to every finding
+	Tags []TagRule `yaml:"tags"`
+	// FileTypes limit the extractors run on inputs by file extension
+	FileTypes []FileTypeRule `yaml:"file_types"`
+	// Profiles are named sets of flags selected with -profile
+	Profiles map[string]Profile `yaml:"profiles"`
+	// TrackingParams extend the built-in list of tracking parameters; a trailing * matches
+	// any name with that prefix
+	TrackingParams []string `yaml:"tracking_params"`
+	// Transforms rewrite the values of findings before they are reported
+	Transforms []TransformRule `yaml:"transforms"`
+	// Plugins are external extractors run on every input
+	Plugins []PluginConfig `yaml:"plugins"`
+	// Remediation overrides the built-in guidance attached to security findings, keyed by
+	// finding type or open_redirect
+	Remediation map[string]RemediationConfig `yaml:"remediation"`
+	// WordlistMutations replace the built-in prefixes and suffixes of -wl-mutate
+	WordlistMutations MutationsConfig `yaml:"wordlist_mutations"`
+}
+
+// TagRule tags findings whose value matches a regular expression
+type TagRule struct {
+	// Pattern is the regular expression matched against finding values
+	Pattern string `yaml:"pattern"`
+	// Tag is the label attached to matching findings
+	Tag string `yaml:"tag"`
+	// Types optionally limits the rule to the named finding types
+	Types []string `yaml:"types"`
+}
+
+// TransformRule rewrites the values of findings, see transform.Rule
+type TransformRule struct {
+	// Types optionally limits the rule to the named finding types
+	Types []string `yaml:"types"`
+	// Lowercase converts values to lower case
+	Lowercase bool `yaml:"lowercase"`
+	// Pattern is a regular expression whose matches are replaced by Replace
+	Pattern string `yaml:"pattern"`
+	// Replace is the replacement of Pattern, which can refer to submatches as $1
+	Replace string `yaml:"replace"`
+	// Prefix is added to values that do not start with it
+	Prefix string `yaml:"prefix"`
+	// Suffix is added to values that do not end with it
+	Suffix string `yaml:"suffix"`
+}
+
+// MutationsConfig lists the prefixes and suffixes added to wordlist words; each list that
+// is set replaces the built-in one
+type MutationsConfig struct {
+	// Prefixes are added in front of words, e.g. dev-
+	Prefixes []string `yaml:"prefixes"`
+	// Suffixes are added after words, e.g. .bak
+	Suffixes []string `yaml:"suffixes"`
+}
+
+// PluginConfig declares an extractor plugin, see the plugin package for its protocol
+type PluginConfig struct {
+	// Name identifies the plugin in errors and in the "plugin" metadata of its findings
+	Name string `yaml:"name"`
+	// Command is the program to run followed by its arguments
+	Command []string `yaml:"command"`
+	// WASM is the path of a WebAssembly module run instead of a command
+	WASM string `yaml:"wasm"`
+}
+
+// RemediationConfig overrides the guidance for a finding type, see the remediation package
+type RemediationConfig struct {
+	// Hint is a one-sentence fix; when empty the built-in hint is kept
+	Hint string `yaml:"hint"`
+	// Reference is the URL of further guidance, such as an internal wiki page
+	Reference string `yaml:"reference"`
+}
+
+// Load reads and validates the configuration file at path. Unknown keys are errors, and
+// errors name the line and column they refer to.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+	root, err := yamlcheck.Decode(data, &config)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, yamlcheck.Locate(root, err))
+	}
+	sum := sha256.Sum256(data)
+	config.SHA256 = hex.EncodeToString(sum[:])
+
+	return &config, nil
+}
+
+// validate checks every section of the configuration
+func (c *Config) validate() error {
+	regexcache.Warm(c.patterns())
+	if _, err := c.TagRules(); err != nil {
+		return err
+	}
+	if err := c.validateFileTypes(); err != nil {
+		return err
+	}
+	if err := c.validateProfiles(); err != nil {
+		return err
+	}
+	if err := tracking.Validate(c.TrackingParams); err != nil {
+		return err
+	}
+	if _, err := c.TransformRules(); err != nil {
+		return err
+	}
+	if _, err := c.ExtractorPlugins(); err != nil {
+		return err
+	}
+	if err := wordlist.ValidateMutations(c.Mutations()); err != nil {
+		return err
+	}
+	_, err := c.RemediationCatalog()
+	return err
+}
+
+// patterns returns the regular expressions of the tag and transform rules
+func (c *Config) patterns() []string {
+	var patterns []string
+	for _, r := range c.Tags {
+		patterns = append(patterns, r.Pattern)
+	}
+	for _, r := range c.Transforms {
+		if r.Pattern != "" {
+			patterns = append(patterns, r.Pattern)
+		}
+	}
+	return patterns
+}
+
+// PatternWarnings returns the problems regexlint finds in the patterns of the tag and
+// transform rules, prefixed with the rule they belong to
+func (c *Config) PatternWarnings() []string {
+	var warnings []string
+	for i, r := range c.Tags {
+		for _, w := range regexlint.Check(r.Pattern, regexlint.Value) {
+			warnings = append(warnings, fmt.Sprintf("tags[%d]: pattern %q %s", i, r.Pattern, w))
+		}
+	}
+	for i, r := range c.Transforms {
+		if r.Pattern == "" {
+			continue
+		}
+		for _, w := range regexlint.Check(r.Pattern, regexlint.Replace) {
+			warnings = append(warnings, fmt.Sprintf("transforms[%d]: pattern %q %s", i, r.Pattern, w))
+		}
+	}
+	return warnings
+}
+
+// TagRules compiles the tag rules of the configuration
+func (c *Config) TagRules() ([]classify.TagRule, error) {
+	rules := make([]classify.TagRule, 0, len(c.Tags))
+	for i, r := range c.Tags {
+		tag := strings.TrimPrefix(strings.TrimSpace(r.Tag), "tag:")
+		if tag == "" {
+			return nil, fmt.Errorf("tags[%d]: tag is required", i)
+		}
+		if r.Pattern == "" {
+			return nil, fmt.Errorf("tags[%d]: pattern is required", i)
+		}
+		pattern, err := regexcache.Compile(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("tags[%d]: invalid pattern: %w", i, err)
+		}
+		rule := classify.TagRule{Pattern: pattern, Tag: tag}
+		for _, name := range r.Types {
+			t, err := finding.ParseType(name)
+			if err != nil {
+				return nil, fmt.Errorf("tags[%d]: %w", i, err)
+			}
+			rule.Types = append(rule.Types, t)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// TransformRules compiles the transform rules of the configuration
+func (c *Config) TransformRules() ([]transform.Rule, error) {
+	rules := make([]transform.Rule, 0, len(c.Transforms))
+	for i, r := range c.Transforms {
+		if r.Replace != "" && r.Pattern == "" {
+			return nil, fmt.Errorf("transforms[%d]: replace requires a pattern", i)
+		}
+		if !r.Lowercase && r.Pattern == "" && r.Prefix == "" && r.Suffix == "" {
+			return nil, fmt.Errorf("transforms[%d]: lowercase, pattern, prefix or suffix is required", i)
+		}
+		rule := transform.Rule{Lowercase: r.Lowercase, Replace: r.Replace, Prefix: r.Prefix, Suffix: r.Suffix}
+		if r.Pattern != "" {
+			pattern, err := regexcache.Compile(r.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("transforms[%d]: invalid pattern: %w", i, err)
+			}
+			rule.Pattern = pattern
+		}
+		for _, name := range r.Types {
+			t, err := finding.ParseType(name)
+			if err != nil {
+				return nil, fmt.Errorf("transforms[%d]: %w", i, err)
+			}
+			rule.Types = append(rule.Types, t)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// RemediationCatalog returns the built-in remediation guidance with the overrides of the
+// configuration applied
+func (c *Config) RemediationCatalog() (*remediation.Catalog, error) {
+	keys := make([]string, 0, len(c.Remediation))
+	for key := range c.Remediation {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	overrides := make(map[string]finding.Remediation, len(keys))
+	for _, key := range keys {
+		r := finding.Remediation{Hint: strings.TrimSpace(c.Remediation[key].Hint), Reference: strings.TrimSpace(c.Remediation[key].Reference)}
+		if err := remediation.Validate(key, r); err != nil {
+			return nil, fmt.Errorf("remediation.%s: %w", key, err)
+		}
+		overrides[key] = r
+	}
+	return remediation.Default().With(overrides), nil
+}
+
+// Mutations returns the prefixes and suffixes set in the wordlist_mutations section
+func (c *Config) Mutations() wordlist.Mutations {
+	return wordlist.Mutations{Prefixes: c.WordlistMutations.Prefixes, Suffixes: c.WordlistMutations.Suffixes}
+}
+
+// ExtractorPlugins returns the plugins declared in the configuration
+func (c *Config) ExtractorPlugins() ([]*plugin.Plugin, error) {
+	plugins := make([]*plugin.Plugin, 0, len(c.Plugins))
+	seen := make(map[string]bool)
+	for i, p := range c.Plugins {
+		name := strings.TrimSpace(p.Name)
+		if name == "" {
+			return nil, fmt.Errorf("plugins[%d]: name is required", i)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("plugins[%d]: duplicate plugin %q", i, name)
+		}
+		seen[name] = true
+		switch {
+		case p.WASM != "" && len(p.Command) > 0:
+			return nil, fmt.Errorf("plugins[%d]: command and wasm cannot both be set", i)
+		case p.WASM != "":
+			plugins = append(plugins, plugin.WASM(name, p.WASM))
+			continue
+		case len(p.Command) == 0 || strings.TrimSpace(p.Command[0]) == "":
+			return nil, fmt.Errorf("plugins[%d]: command or wasm is required", i)
+		}
+		plugins = append(plugins, &plugin.Plugin{Name: name, Command: p.Command})
+	}
+	return plugins, nil
+}
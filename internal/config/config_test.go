@@ -0,0 +1,121 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "urlsluice.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	path := writeConfig(t, `tags:
+  - pattern: staging
+    tag: staging
+  - pattern: '^admin\.'
+    tag: "tag:admin"
+    types: [domain, url]
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	rules, err := cfg.TagRules()
+	if err != nil {
+		t.Fatalf("TagRules() error = %v", err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("got %d rules, want 2", len(rules))
+	}
+	if rules[1].Tag != "admin" {
+		t.Errorf("rules[1].Tag = %q, want admin", rules[1].Tag)
+	}
+	if len(rules[1].Types) != 2 || rules[1].Types[0] != finding.TypeDomain {
+		t.Errorf("rules[1].Types = %v, want [domain url]", rules[1].Types)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"invalid yaml", "tags: [", "yaml"},
+		{"missing tag", "tags:\n  - pattern: x\n", "tags[0]: tag is required"},
+		{"missing pattern", "tags:\n  - tag: x\n", "tags[0]: pattern is required"},
+		{"invalid pattern", "tags:\n  - pattern: '('\n    tag: x\n", "invalid pattern"},
+		{"unknown type", "tags:\n  - pattern: x\n    tag: x\n    types: [hostname]\n", "unknown finding type"},
+		{"file type without extensions", "file_types:\n  - include: [url]\n", "file_types[0]: extensions are required"},
+		{"file type without extractors", "file_types:\n  - extensions: [.js]\n", "file_types[0]: include or exclude is required"},
+		{"unknown extractor", "file_types:\n  - extensions: [.js]\n    exclude: [forms]\n", "unknown finding type"},
+		{"tracking param wildcard", "tracking_params: ['a*b']\n", "tracking_params[0]: \"a*b\": * is only allowed at the end"},
+		{"transform without step", "transforms:\n  - types: [domain]\n", "transforms[0]: lowercase, pattern, prefix or suffix is required"},
+		{"transform replace without pattern", "transforms:\n  - replace: x\n", "transforms[0]: replace requires a pattern"},
+		{"transform invalid pattern", "transforms:\n  - pattern: '('\n", "transforms[0]: invalid pattern"},
+		{"transform unknown type", "transforms:\n  - lowercase: true\n    types: [host]\n", "transforms[0]: unknown finding type"},
+		{"plugin without name", "plugins:\n  - command: [jwt.py]\n", "plugins[0]: name is required"},
+		{"plugin without command", "plugins:\n  - name: jwt\n", "plugins[0]: command or wasm is required"},
+		{"plugin with command and wasm", "plugins:\n  - name: jwt\n    command: [jwt.py]\n    wasm: jwt.wasm\n", "plugins[0]: command and wasm cannot both be set"},
+		{"duplicate plugin", "plugins:\n  - name: jwt\n    command: [a]\n  - name: jwt\n    command: [b]\n", "plugins[1]: duplicate plugin \"jwt\""},
+		{"unknown remediation type", "remediation:\n  secrets:\n    hint: rotate\n", "remediation.secrets: unknown finding type \"secrets\""},
+		{"remediation without hint", "remediation:\n  email:\n    reference: https://wiki.corp/email\n", "remediation.email: hint is required"},
+		{"relative remediation reference", "remediation:\n  cors:\n    reference: wiki/cors\n", "must be an absolute URL"},
+		{"empty mutation", "wordlist_mutations:\n  suffixes: [.bak, '']\n", "line 2, column 20: wordlist_mutations.suffixes[1]: value is required"},
+		{"mutation with a slash", "wordlist_mutations:\n  prefixes: [old/]\n", "wordlist_mutations.prefixes[0]: \"old/\": spaces and / are not allowed"},
+		{"unknown key", "tags:\n  - pattern: x\n    tga: y\n", "line 3, column 5: unknown key \"tga\" in tags[0], did you mean \"tag\"?"},
+		{"located error", "transforms:\n  - lowercase: true\n  - types: [domain]\n", "line 3, column 5: transforms[1]: lowercase, pattern, prefix or suffix is required"},
+		{"nested profile value", "profiles:\n  recon:\n    only: {domains: true}\n", "profiles.recon: only: unsupported value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeConfig(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Load() of a missing file error = nil, want error")
+	}
+}
+
+func TestProfile_Args(t *testing.T) {
+	path := writeConfig(t, `profiles:
+  recon:
+    domains: true
+    urls: true
+    min-confidence: medium
+    -max-per-category: 500
+    grep: [api, auth]
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	args, err := cfg.Profiles["recon"].Args()
+	if err != nil {
+		t.Fatalf("Args() error = %v", err)
+	}
+	want := []string{"-max-per-category=500", "-domains=true", "-grep=api", "-grep=auth", "-min-confidence=medium", "-urls=true"}
+	if strings.Join(args, " ") != strings.Join(want, " ") {
+		t.Errorf("Args() = %q, want %q", args, want)
+	}
+	if names := cfg.ProfileNames(); len(names) != 1 || names[0] != "recon" {
+		t.Errorf("ProfileNames() = %v, want [recon]", names)
+	}
+}
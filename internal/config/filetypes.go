@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+// FileTypeRule selects the extractors run on inputs with one of the given extensions.
+// Extractors are named after the finding type they produce, e.g. "url" or "cloud_config".
+type FileTypeRule struct {
+	// Extensions are the file extensions the rule applies to, e.g. ".js"
+	Extensions []string `yaml:"extensions"`
+	// Include optionally limits the extractors to the named ones
+	Include []string `yaml:"include"`
+	// Exclude names extractors that are never run
+	Exclude []string `yaml:"exclude"`
+}
+
+// validateFileTypes checks that every file type rule has extensions and names known extractors
+func (c *Config) validateFileTypes() error {
+	for i, r := range c.FileTypes {
+		if len(r.Extensions) == 0 {
+			return fmt.Errorf("file_types[%d]: extensions are required", i)
+		}
+		if len(r.Include) == 0 && len(r.Exclude) == 0 {
+			return fmt.Errorf("file_types[%d]: include or exclude is required", i)
+		}
+		for _, name := range append(append([]string{}, r.Include...), r.Exclude...) {
+			if _, err := finding.ParseType(name); err != nil {
+				return fmt.Errorf("file_types[%d]: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Extractors returns whether the extractor producing each finding type may run on the input
+// at name, a file path or URL path, according to the first file type rule matching its
+// extension. It returns nil when no rule matches, in which case every extractor runs.
+func (c *Config) Extractors(name string) func(finding.Type) bool {
+	ext := strings.ToLower(path.Ext(strings.ReplaceAll(name, "\\", "/")))
+	if ext == "" {
+		return nil
+	}
+	for _, r := range c.FileTypes {
+		if !matchesExtension(r.Extensions, ext) {
+			continue
+		}
+		include := typeSet(r.Include)
+		exclude := typeSet(r.Exclude)
+		return func(t finding.Type) bool {
+			return (len(include) == 0 || include[t]) && !exclude[t]
+		}
+	}
+	return nil
+}
+
+// matchesExtension reports whether ext is one of extensions, which may omit the leading dot
+func matchesExtension(extensions []string, ext string) bool {
+	for _, e := range extensions {
+		e = strings.ToLower(strings.TrimSpace(e))
+		if !strings.HasPrefix(e, ".") {
+			e = "." + e
+		}
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
+
+func typeSet(names []string) map[finding.Type]bool {
+	set := make(map[finding.Type]bool, len(names))
+	for _, name := range names {
+		if t, err := finding.ParseType(name); err == nil {
+			set[t] = true
+		}
+	}
+	return set
+}
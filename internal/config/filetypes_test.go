@@ -0,0 +1,45 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+func TestConfig_Extractors(t *testing.T) {
+	cfg, err := Load(writeConfig(t, `file_types:
+  - extensions: [.js, mjs]
+    include: [url, domain, cloud_config]
+  - extensions: [.env]
+    exclude: [token]
+`))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		path  string
+		typ   finding.Type
+		want  bool
+		match bool
+	}{
+		{"static/app.js", finding.TypeURL, true, true},
+		{"static/APP.JS", finding.TypeCloudConfig, true, true},
+		{"/assets/main.mjs", finding.TypeEmail, false, true},
+		{"deploy/.env", finding.TypeToken, false, true},
+		{"deploy/.env", finding.TypeConfigSecret, true, true},
+		{"index.html", finding.TypeURL, true, false},
+		{"README", finding.TypeURL, true, false},
+	}
+
+	for _, tt := range tests {
+		allowed := cfg.Extractors(tt.path)
+		if (allowed != nil) != tt.match {
+			t.Errorf("Extractors(%q) matched = %v, want %v", tt.path, allowed != nil, tt.match)
+			continue
+		}
+		if allowed != nil && allowed(tt.typ) != tt.want {
+			t.Errorf("Extractors(%q)(%s) = %v, want %v", tt.path, tt.typ, allowed(tt.typ), tt.want)
+		}
+	}
+}
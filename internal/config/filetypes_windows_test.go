@@ -0,0 +1,46 @@
+//go:build windows
+
+package config
+
+import (
+	"testing"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+func TestConfig_Extractors_Windows(t *testing.T) {
+	cfg, err := Load(writeConfig(t, `file_types:
+  - extensions: [.js]
+    include: [url]
+  - extensions: [.env]
+    exclude: [token]
+`))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		path string
+		typ  finding.Type
+		want bool
+	}{
+		{`C:\site\static\APP.JS`, finding.TypeURL, true},
+		{`C:\site\static\app.js`, finding.TypeEmail, false},
+		{`deploy\.env`, finding.TypeToken, false},
+		{`\\fileserver\share\deploy\.env`, finding.TypeConfigSecret, true},
+	}
+	for _, tt := range tests {
+		allowed := cfg.Extractors(tt.path)
+		if allowed == nil {
+			t.Errorf("Extractors(%q) matched no rule", tt.path)
+			continue
+		}
+		if allowed(tt.typ) != tt.want {
+			t.Errorf("Extractors(%q)(%s) = %v, want %v", tt.path, tt.typ, allowed(tt.typ), tt.want)
+		}
+	}
+	// A dot in a directory name is not an extension
+	if allowed := cfg.Extractors(`C:\site.js\README`); allowed != nil {
+		t.Error(`Extractors("C:\site.js\README") matched a rule`)
+	}
+}
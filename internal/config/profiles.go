@@ -0,0 +1,74 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Profile is a named set of command line flags, keyed by flag name without the leading dash.
+// Values are booleans, strings or numbers; a list gives a repeatable flag once per element.
+type Profile map[string]interface{}
+
+// validateProfiles checks that every profile can be turned into flags
+func (c *Config) validateProfiles() error {
+	for name, p := range c.Profiles {
+		if _, err := p.Args(); err != nil {
+			return fmt.Errorf("profiles.%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// ProfileNames returns the names of the profiles in the configuration, sorted
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Args returns the profile as command line arguments of the form "-name=value", ordered by
+// key so a profile always expands the same way
+func (p Profile) Args() ([]string, error) {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var args []string
+	for _, name := range names {
+		flagName := strings.TrimLeft(name, "-")
+		if flagName == "" {
+			return nil, fmt.Errorf("empty flag name")
+		}
+		values, ok := p[name].([]interface{})
+		if !ok {
+			values = []interface{}{p[name]}
+		}
+		for _, v := range values {
+			value, err := flagValue(v)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", flagName, err)
+			}
+			args = append(args, "-"+flagName+"="+value)
+		}
+	}
+	return args, nil
+}
+
+// flagValue formats a scalar YAML value as a flag value
+func flagValue(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case bool, int, float64:
+		return fmt.Sprint(v), nil
+	case nil:
+		return "", fmt.Errorf("value is required")
+	}
+	return "", fmt.Errorf("unsupported value %v: must be a boolean, string, number or list of them", v)
+}
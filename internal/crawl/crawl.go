@@ -0,0 +1,179 @@
+// Package crawl performs a shallow, breadth-first fetch of discovered URLs.
+// Fetched bodies are handed back to the caller, which extracts findings from them and
+// returns the URLs to follow on the next level.
+package crawl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PeteJStewart/urlsluice/internal/httpclient"
+)
+
+const (
+	// defaultConcurrency is the number of parallel fetches when none is configured
+	defaultConcurrency = 4
+	// maxBodySize caps how much of each response body is read (5MB)
+	maxBodySize = 5 * 1024 * 1024
+	// defaultTimeout bounds each request made by the default client
+	defaultTimeout = 10 * time.Second
+)
+
+// Page is a fetched document
+type Page struct {
+	URL    string
+	Depth  int
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// VisitFunc processes a fetched page and returns the URLs discovered in it.
+// It is never called concurrently.
+type VisitFunc func(Page) []string
+
+// Crawler fetches URLs level by level up to a maximum depth
+type Crawler struct {
+	// Client performs the requests; an http.Client with a 10s timeout is used when nil
+	Client httpclient.Doer
+	// Depth is the number of levels to fetch; 1 fetches only the seed URLs
+	Depth int
+	// Concurrency bounds the number of requests in flight
+	Concurrency int
+	// Delay is the minimum interval between the start of two requests
+	Delay time.Duration
+	// InScope decides whether a URL may be fetched; nil limits the crawl to the seed hosts
+	InScope func(*url.URL) bool
+}
+
+// Crawl fetches seeds and, recursively up to c.Depth levels, the in-scope URLs returned by visit.
+// Each URL is fetched at most once. Fetch errors are skipped; only context cancellation is returned.
+func (c *Crawler) Crawl(ctx context.Context, seeds []string, visit VisitFunc) error {
+	if c.Depth <= 0 {
+		return nil
+	}
+
+	var client httpclient.Doer = &http.Client{Timeout: defaultTimeout}
+	if c.Client != nil {
+		client = c.Client
+	}
+	concurrency := c.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	inScope := c.InScope
+	if inScope == nil {
+		inScope = hostScope(seeds)
+	}
+
+	var tick <-chan time.Time
+	if c.Delay > 0 {
+		ticker := time.NewTicker(c.Delay)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	seen := make(map[string]bool)
+	level := c.filter(seeds, seen, inScope)
+
+	for depth := 1; depth <= c.Depth && len(level) > 0; depth++ {
+		var (
+			mu   sync.Mutex
+			next []string
+			wg   sync.WaitGroup
+		)
+		sem := make(chan struct{}, concurrency)
+
+		for i, u := range level {
+			if i > 0 && tick != nil {
+				select {
+				case <-tick:
+				case <-ctx.Done():
+				}
+			}
+			if ctx.Err() != nil {
+				break
+			}
+
+			sem <- struct{}{}
+			wg.Add(1)
+			go func(u string) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+
+				page, err := fetch(ctx, client, u)
+				if err != nil {
+					return
+				}
+				page.Depth = depth
+
+				mu.Lock()
+				defer mu.Unlock()
+				next = append(next, visit(page)...)
+			}(u)
+		}
+		wg.Wait()
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		level = c.filter(next, seen, inScope)
+	}
+	return nil
+}
+
+// filter returns the unseen, in-scope HTTP(S) URLs from urls and marks them as seen
+func (c *Crawler) filter(urls []string, seen map[string]bool, inScope func(*url.URL) bool) []string {
+	var out []string
+	for _, raw := range urls {
+		if seen[raw] {
+			continue
+		}
+		seen[raw] = true
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || !inScope(u) {
+			continue
+		}
+		out = append(out, raw)
+	}
+	return out
+}
+
+func fetch(ctx context.Context, client httpclient.Doer, u string) (Page, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return Page{}, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return Page{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return Page{}, fmt.Errorf("reading %s: %w", u, err)
+	}
+	return Page{URL: u, Status: resp.StatusCode, Header: resp.Header, Body: body}, nil
+}
+
+// hostScope accepts URLs whose host matches the host of one of the seeds
+func hostScope(seeds []string) func(*url.URL) bool {
+	hosts := make(map[string]bool)
+	for _, s := range seeds {
+		if u, err := url.Parse(s); err == nil && u.Host != "" {
+			hosts[strings.ToLower(u.Hostname())] = true
+		}
+	}
+	return func(u *url.URL) bool {
+		return hosts[strings.ToLower(u.Hostname())]
+	}
+}
@@ -0,0 +1,77 @@
+package crawl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/PeteJStewart/urlsluice/internal/patterns"
+)
+
+func newSite(t *testing.T) *httptest.Server {
+	t.Helper()
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprintf(w, `<a href="%s/a">a</a> <a href="http://elsewhere.invalid/x">x</a>`, srv.URL)
+		case "/a":
+			fmt.Fprintf(w, `<a href="%s/b">b</a> <a href="%s/">home</a>`, srv.URL, srv.URL)
+		case "/b":
+			fmt.Fprintf(w, `<a href="%s/c">c</a>`, srv.URL)
+		default:
+			fmt.Fprint(w, "leaf")
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestCrawler_Crawl(t *testing.T) {
+	srv := newSite(t)
+
+	tests := []struct {
+		name  string
+		depth int
+		want  []string
+	}{
+		{"depth zero fetches nothing", 0, nil},
+		{"depth one fetches seeds only", 1, []string{"/"}},
+		{"depth two follows links", 2, []string{"/", "/a"}},
+		{"depth three", 3, []string{"/", "/a", "/b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fetched []string
+			c := &Crawler{Depth: tt.depth, Concurrency: 2}
+			err := c.Crawl(context.Background(), []string{srv.URL + "/"}, func(p Page) []string {
+				fetched = append(fetched, p.URL[len(srv.URL):])
+				return patterns.URLRegex.FindAllString(string(p.Body), -1)
+			})
+			if err != nil {
+				t.Fatalf("Crawl() error = %v", err)
+			}
+			sort.Strings(fetched)
+			if !reflect.DeepEqual(fetched, tt.want) {
+				t.Errorf("fetched = %v, want %v", fetched, tt.want)
+			}
+		})
+	}
+}
+
+func TestCrawler_Cancelled(t *testing.T) {
+	srv := newSite(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	c := &Crawler{Depth: 2}
+	err := c.Crawl(ctx, []string{srv.URL + "/"}, func(p Page) []string { return nil })
+	if err == nil {
+		t.Error("Crawl() expected context error, got nil")
+	}
+}
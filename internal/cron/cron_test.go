@@ -0,0 +1,56 @@
+package cron
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	// A Wednesday
+	from := time.Date(2026, 5, 6, 10, 17, 30, 0, time.UTC)
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 5, 6, 10, 18, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 5, 6, 10, 30, 0, 0, time.UTC)},
+		{"0 */6 * * *", time.Date(2026, 5, 6, 12, 0, 0, 0, time.UTC)},
+		{"30 9 * * mon-fri", time.Date(2026, 5, 7, 9, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 5, 10, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan *", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 15 * 1", time.Date(2026, 5, 11, 12, 0, 0, 0, time.UTC)},
+		{"5,10 10 6 5 *", time.Date(2027, 5, 6, 10, 5, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 5, 7, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 5, 6, 11, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got := s.Next(from); !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := map[string]string{
+		"* * * *":      "want 5 fields, got 4",
+		"60 * * * *":   "invalid minute \"60\": must be 0-59",
+		"* * * 13 *":   "invalid month \"13\"",
+		"* * * * fun":  "invalid day of week \"fun\"",
+		"*/0 * * * *":  "invalid step \"0\" in minute",
+		"* 5-1 * * *":  "invalid range \"5-1\" in hour",
+		"@fortnightly": "want 5 fields, got 1",
+	}
+	for expr, want := range tests {
+		if _, err := Parse(expr); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Parse(%q) error = %v, want %q", expr, err, want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// Package ct discovers hostnames from Certificate Transparency logs via crt.sh.
+package ct
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/cache"
+	"github.com/PeteJStewart/urlsluice/internal/httpclient"
+)
+
+// DefaultBaseURL is the crt.sh endpoint queried when no base URL is configured
+const DefaultBaseURL = "https://crt.sh/"
+
+// maxResponseSize caps the size of a crt.sh response (50MB)
+const maxResponseSize = 50 * 1024 * 1024
+
+// Client queries a crt.sh compatible CT search service
+type Client struct {
+	// HTTP queries the search service
+	HTTP httpclient.Doer
+	// BaseURL is the search endpoint (default DefaultBaseURL)
+	BaseURL string
+	// Cache keeps the results of earlier lookups; domains found in it are not queried again
+	Cache *cache.Cache
+}
+
+// entry is a single certificate record returned by crt.sh
+type entry struct {
+	CommonName string `json:"common_name"`
+	NameValue  string `json:"name_value"`
+}
+
+// Hostnames returns the sorted, unique hostnames under domain found in certificates
+// logged for domain and its subdomains. Wildcard prefixes are stripped.
+func (c *Client) Hostnames(ctx context.Context, domain string) ([]string, error) {
+	domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+	if domain == "" {
+		return nil, fmt.Errorf("domain is required")
+	}
+
+	base := c.BaseURL
+	if base == "" {
+		base = DefaultBaseURL
+	}
+	endpoint, err := url.Parse(base)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CT base URL: %w", err)
+	}
+	query := endpoint.Query()
+	query.Set("q", "%."+domain)
+	query.Set("output", "json")
+	endpoint.RawQuery = query.Encode()
+
+	var names []string
+	if c.Cache.Get("ct", endpoint.String(), &names) {
+		return names, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpclient.Do(c.HTTP, req)
+	if err != nil {
+		return nil, fmt.Errorf("querying CT logs: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("querying CT logs: unexpected status %s", resp.Status)
+	}
+
+	var entries []entry
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("decoding CT response: %w", err)
+	}
+
+	names = hostnames(entries, domain)
+	c.Cache.Put("ct", endpoint.String(), names)
+	return names, nil
+}
+
+// hostnames collects the names from entries that belong to domain
+func hostnames(entries []entry, domain string) []string {
+	seen := make(map[string]bool)
+	for _, e := range entries {
+		names := strings.Split(e.NameValue, "\n")
+		names = append(names, e.CommonName)
+		for _, name := range names {
+			name = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(name)), "*.")
+			if name == domain || strings.HasSuffix(name, "."+domain) {
+				seen[name] = true
+			}
+		}
+	}
+
+	out := make([]string, 0, len(seen))
+	for name := range seen {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
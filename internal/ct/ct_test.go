@@ -0,0 +1,50 @@
+package ct
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestClient_Hostnames(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("q") != "%.target.com" || r.URL.Query().Get("output") != "json" {
+			t.Errorf("unexpected query %q", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, `[
+			{"common_name": "target.com", "name_value": "target.com\nwww.target.com"},
+			{"common_name": "*.api.target.com", "name_value": "*.api.target.com"},
+			{"common_name": "mail.TARGET.com", "name_value": "mail.target.com\nunrelated.org"}
+		]`)
+	}))
+	defer srv.Close()
+
+	c := &Client{BaseURL: srv.URL}
+	got, err := c.Hostnames(context.Background(), "Target.com")
+	if err != nil {
+		t.Fatalf("Hostnames() error = %v", err)
+	}
+
+	want := []string{"api.target.com", "mail.target.com", "target.com", "www.target.com"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Hostnames() = %v, want %v", got, want)
+	}
+}
+
+func TestClient_HostnamesErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	c := &Client{BaseURL: srv.URL}
+	if _, err := c.Hostnames(context.Background(), "target.com"); err == nil {
+		t.Error("expected error for bad gateway")
+	}
+	if _, err := c.Hostnames(context.Background(), ""); err == nil {
+		t.Error("expected error for empty domain")
+	}
+}
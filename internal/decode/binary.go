@@ -0,0 +1,49 @@
+package decode
+
+import "bytes"
+
+// MinStringLength is the shortest printable sequence Strings keeps, as in strings(1)
+const MinStringLength = 4
+
+// Binary reports whether data looks like a binary file, such as an executable, image or
+// archive, rather than text. Like git, it looks for a NUL byte near the start of the input;
+// UTF-16 text, in which NULs are expected, is not binary.
+func Binary(data []byte) bool {
+	sample := data
+	if len(sample) > sampleSize {
+		sample = sample[:sampleSize]
+	}
+	if bytes.IndexByte(sample, 0) < 0 {
+		return false
+	}
+	switch Detect(data) {
+	case UTF16LE, UTF16BE:
+		return false
+	}
+	return true
+}
+
+// Strings returns the runs of at least min printable ASCII characters in data, one per line,
+// like strings(1). Tabs are kept within a run; any other control or non-ASCII byte ends it.
+func Strings(data []byte, min int) []byte {
+	var out []byte
+	start := -1
+	flush := func(end int) {
+		if start >= 0 && end-start >= min {
+			out = append(out, data[start:end]...)
+			out = append(out, '\n')
+		}
+		start = -1
+	}
+	for i, b := range data {
+		if b == '\t' || (b >= 0x20 && b < 0x7F) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		flush(i)
+	}
+	flush(len(data))
+	return out
+}
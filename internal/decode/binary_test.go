@@ -0,0 +1,45 @@
+package decode
+
+import "testing"
+
+func TestBinary(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []byte
+		want  bool
+	}{
+		{"text", []byte("https://example.com/\n"), false},
+		{"latin-1", []byte("caf\xe9 https://example.com/"), false},
+		{"elf", []byte("\x7fELF\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00https://example.com/"), true},
+		{"png", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), true},
+		{"utf-16le", []byte("h\x00t\x00t\x00p\x00s\x00:\x00/\x00/\x00"), false},
+		{"empty", nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := Binary(tt.input); got != tt.want {
+			t.Errorf("%s: Binary() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStrings(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []byte
+		want  string
+	}{
+		{"elf", []byte("\x7fELF\x02\x01\x00\x00https://api.example.com/v1\x00\x00key=abc\x00\x01"), "https://api.example.com/v1\nkey=abc\n"},
+		{"short runs dropped", []byte("ab\x00abc\x00abcd"), "abcd\n"},
+		{"tabs kept", []byte("\x00a\tb\tc\x00"), "a\tb\tc\n"},
+		{"newlines split", []byte("line one\nline two"), "line one\nline two\n"},
+		{"non-ascii splits", []byte("caf\xe9 dev@example.com"), " dev@example.com\n"},
+		{"empty", nil, ""},
+	}
+
+	for _, tt := range tests {
+		if got := string(Strings(tt.input, MinStringLength)); got != tt.want {
+			t.Errorf("%s: Strings() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+// Package decode converts raw input into the UTF-8 text the extractors work on.
+package decode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Encoding names a character encoding of the input
+type Encoding string
+
+const (
+	// Auto detects the encoding with Detect
+	Auto Encoding = "auto"
+	// UTF8 is UTF-8, with or without a byte order mark
+	UTF8 Encoding = "utf-8"
+	// UTF16LE is little-endian UTF-16, as written by Windows tools and PowerShell
+	UTF16LE Encoding = "utf-16le"
+	// UTF16BE is big-endian UTF-16
+	UTF16BE Encoding = "utf-16be"
+	// Latin1 is ISO-8859-1
+	Latin1 Encoding = "latin-1"
+	// Windows1252 is the Windows superset of ISO-8859-1 used by many Western European exports
+	Windows1252 Encoding = "windows-1252"
+)
+
+// Encodings lists the supported encodings
+var Encodings = []Encoding{Auto, UTF8, UTF16LE, UTF16BE, Latin1, Windows1252}
+
+// aliases maps alternative spellings to the supported encodings
+var aliases = map[string]Encoding{
+	"utf8":        UTF8,
+	"utf16le":     UTF16LE,
+	"utf-16":      UTF16LE,
+	"utf16":       UTF16LE,
+	"utf16be":     UTF16BE,
+	"latin1":      Latin1,
+	"iso-8859-1":  Latin1,
+	"iso8859-1":   Latin1,
+	"cp1252":      Windows1252,
+	"windows1252": Windows1252,
+}
+
+// ParseEncoding converts a name such as "utf-16le" or "latin1" into an Encoding
+func ParseEncoding(s string) (Encoding, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	if e, ok := aliases[name]; ok {
+		return e, nil
+	}
+	for _, e := range Encodings {
+		if string(e) == name {
+			return e, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported encoding %q", s)
+}
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// sampleSize is how much of the input Detect inspects for UTF-16 without a byte order mark
+const sampleSize = 4096
+
+// Detect guesses the encoding of data. Byte order marks are trusted; without one, text in
+// which nearly every other byte is NUL is read as UTF-16, valid UTF-8 as UTF-8 and anything
+// else as Windows-1252, which decodes every byte.
+func Detect(data []byte) Encoding {
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		return UTF8
+	case bytes.HasPrefix(data, bomUTF16LE):
+		return UTF16LE
+	case bytes.HasPrefix(data, bomUTF16BE):
+		return UTF16BE
+	}
+
+	sample := data
+	if len(sample) > sampleSize {
+		sample = sample[:sampleSize]
+	}
+	if len(sample) >= 4 {
+		var even, odd int
+		for i, b := range sample {
+			if b != 0 {
+				continue
+			}
+			if i%2 == 0 {
+				even++
+			} else {
+				odd++
+			}
+		}
+		// ASCII text in UTF-16 has a NUL in every code unit; binary data has NULs in both positions
+		half := len(sample) / 2
+		switch {
+		case odd*10 >= half*9 && even*20 < half:
+			return UTF16LE
+		case even*10 >= half*9 && odd*20 < half:
+			return UTF16BE
+		}
+	}
+
+	if utf8.Valid(data) {
+		return UTF8
+	}
+	return Windows1252
+}
+
+// Text returns data as UTF-8 text, converting it from the detected encoding. A UTF-8 byte
+// order mark is removed and input starting with a UTF-16 byte order mark, as written by
+// many Windows tools, is converted to UTF-8.
+func Text(data []byte) []byte {
+	return Convert(data, Auto)
+}
+
+// Convert returns data, in encoding enc, as UTF-8 text without a byte order mark.
+// Auto detects the encoding with Detect.
+func Convert(data []byte, enc Encoding) []byte {
+	if enc == Auto || enc == "" {
+		enc = Detect(data)
+	}
+	switch enc {
+	case UTF16LE:
+		return utf16ToUTF8(bytes.TrimPrefix(data, bomUTF16LE), binary.LittleEndian)
+	case UTF16BE:
+		return utf16ToUTF8(bytes.TrimPrefix(data, bomUTF16BE), binary.BigEndian)
+	case Latin1:
+		return singleByteToUTF8(data, nil)
+	case Windows1252:
+		return singleByteToUTF8(data, &windows1252)
+	}
+	return bytes.TrimPrefix(data, bomUTF8)
+}
+
+func utf16ToUTF8(data []byte, order binary.ByteOrder) []byte {
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = order.Uint16(data[2*i:])
+	}
+	out := make([]byte, 0, len(units))
+	for _, r := range utf16.Decode(units) {
+		out = utf8.AppendRune(out, r)
+	}
+	return out
+}
+
+// singleByteToUTF8 decodes a single byte encoding in which bytes map to the Unicode code
+// point of the same value, except for 0x80-0x9F which are looked up in high when set
+func singleByteToUTF8(data []byte, high *[32]rune) []byte {
+	out := make([]byte, 0, len(data))
+	for _, b := range data {
+		r := rune(b)
+		if high != nil && b >= 0x80 && b < 0xA0 {
+			r = high[b-0x80]
+		}
+		out = utf8.AppendRune(out, r)
+	}
+	return out
+}
+
+// windows1252 maps bytes 0x80-0x9F of Windows-1252; unassigned bytes keep their C1 code point
+var windows1252 = [32]rune{
+	'€', 0x81, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0x8D, 'Ž', 0x8F,
+	0x90, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0x9D, 'ž', 'Ÿ',
+}
@@ -0,0 +1,76 @@
+package decode
+
+import "testing"
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []byte
+		want  string
+	}{
+		{"plain", []byte("https://example.com/"), "https://example.com/"},
+		{"utf-8 bom", []byte("\xEF\xBB\xBFhttps://example.com/"), "https://example.com/"},
+		{"utf-16le", []byte("\xFF\xFEh\x00i\x00 \x00\xe9\x00\n\x00"), "hi é\n"},
+		{"utf-16be", []byte("\xFE\xFF\x00h\x00i\xd8\x3d\xde\x00"), "hi😀"},
+		{"empty", nil, ""},
+	}
+
+	for _, tt := range tests {
+		if got := string(Text(tt.input)); got != tt.want {
+			t.Errorf("%s: Text() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []byte
+		want  Encoding
+	}{
+		{"ascii", []byte("https://example.com/\n"), UTF8},
+		{"utf-8", []byte("café https://example.com/"), UTF8},
+		{"utf-16le without bom", []byte("h\x00t\x00t\x00p\x00s\x00:\x00/\x00/\x00"), UTF16LE},
+		{"utf-16be without bom", []byte("\x00h\x00t\x00t\x00p\x00s\x00:\x00/\x00/"), UTF16BE},
+		{"latin-1", []byte("caf\xe9 https://example.com/"), Windows1252},
+		{"binary with nuls is not utf-16", []byte("\x7fELF\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00"), UTF8},
+	}
+
+	for _, tt := range tests {
+		if got := Detect(tt.input); got != tt.want {
+			t.Errorf("%s: Detect() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []byte
+		enc   Encoding
+		want  string
+	}{
+		{"latin-1", []byte("caf\xe9 \x80"), Latin1, "café \u0080"},
+		{"windows-1252", []byte("caf\xe9 \x80 \x93q\x94"), Windows1252, "café € “q”"},
+		{"forced utf-16le", []byte("h\x00i\x00"), UTF16LE, "hi"},
+		{"auto utf-16le without bom", []byte("u\x00s\x00e\x00r\x00@\x00x\x00.\x00i\x00o\x00"), Auto, "user@x.io"},
+		{"utf-8 keeps invalid bytes", []byte("a\xffb"), UTF8, "a\xffb"},
+	}
+
+	for _, tt := range tests {
+		if got := string(Convert(tt.input, tt.enc)); got != tt.want {
+			t.Errorf("%s: Convert() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseEncoding(t *testing.T) {
+	for name, want := range map[string]Encoding{"auto": Auto, "UTF8": UTF8, "utf-16le": UTF16LE, "latin1": Latin1, "cp1252": Windows1252} {
+		if got, err := ParseEncoding(name); err != nil || got != want {
+			t.Errorf("ParseEncoding(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseEncoding("ebcdic"); err == nil {
+		t.Error("ParseEncoding accepted an unsupported encoding")
+	}
+}
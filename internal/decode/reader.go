@@ -0,0 +1,132 @@
+package decode
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"unicode/utf8"
+)
+
+// readerSample is how much of the input NewReader inspects to detect its encoding
+const readerSample = 64 * 1024
+
+// readerBlock is how much input a converting reader reads at a time
+const readerBlock = 32 * 1024
+
+// NewReader returns a reader of the text of r, in encoding enc, as UTF-8 without a byte
+// order mark, converting it a block at a time so that long inputs need not be read whole.
+// Auto detects the encoding with Detect from the first 64KB of r.
+func NewReader(r io.Reader, enc Encoding) io.Reader {
+	br := bufio.NewReaderSize(r, readerSample)
+	if enc == Auto || enc == "" {
+		sample, _ := br.Peek(readerSample)
+		if len(sample) == readerSample {
+			sample = trimPartialRune(sample)
+		}
+		enc = Detect(sample)
+	}
+	switch enc {
+	case UTF16LE:
+		return &convertReader{r: br, bom: bomUTF16LE, convert: utf16Converter(binary.LittleEndian)}
+	case UTF16BE:
+		return &convertReader{r: br, bom: bomUTF16BE, convert: utf16Converter(binary.BigEndian)}
+	case Latin1:
+		return &convertReader{r: br, convert: singleByteConverter(nil)}
+	case Windows1252:
+		return &convertReader{r: br, convert: singleByteConverter(&windows1252)}
+	}
+	if bom, _ := br.Peek(len(bomUTF8)); bytes.Equal(bom, bomUTF8) {
+		br.Discard(len(bomUTF8))
+	}
+	return br
+}
+
+// trimPartialRune drops the start of a UTF-8 character cut off at the end of data, so that
+// a sample of valid UTF-8 is seen as such
+func trimPartialRune(data []byte) []byte {
+	for i := 1; i <= utf8.UTFMax && i <= len(data); i++ {
+		if utf8.RuneStart(data[len(data)-i]) {
+			if !utf8.FullRune(data[len(data)-i:]) {
+				return data[:len(data)-i]
+			}
+			break
+		}
+	}
+	return data
+}
+
+// convertReader converts the text read from r to UTF-8 a block at a time
+type convertReader struct {
+	r io.Reader
+	// bom is removed from the start of the input
+	bom []byte
+	// convert returns the text of the start of data and the number of bytes it used; bytes
+	// of an incomplete character are left for the next call unless eof is set
+	convert func(data []byte, eof bool) ([]byte, int)
+	in      []byte
+	text    []byte
+	started bool
+	err     error
+}
+
+func (c *convertReader) Read(p []byte) (int, error) {
+	for len(c.text) == 0 {
+		if c.err != nil {
+			return 0, c.err
+		}
+		c.fill()
+	}
+	n := copy(p, c.text)
+	c.text = c.text[n:]
+	return n, nil
+}
+
+// fill reads the next block of input and converts it
+func (c *convertReader) fill() {
+	if c.in == nil {
+		c.in = make([]byte, 0, readerBlock+utf8.UTFMax)
+	}
+	n, err := c.r.Read(c.in[len(c.in):cap(c.in)])
+	c.in = c.in[:len(c.in)+n]
+	if err != nil {
+		c.err = err
+	}
+	if !c.started {
+		if len(c.in) < len(c.bom) && c.err == nil {
+			return
+		}
+		c.started = true
+		if bytes.HasPrefix(c.in, c.bom) {
+			c.in = c.in[:copy(c.in, c.in[len(c.bom):])]
+		}
+	}
+	text, used := c.convert(c.in, c.err != nil)
+	c.text = text
+	c.in = c.in[:copy(c.in, c.in[used:])]
+}
+
+// utf16Converter converts UTF-16 in byte order order, holding back an odd byte or a high
+// surrogate until the rest of its character is read
+func utf16Converter(order binary.ByteOrder) func([]byte, bool) ([]byte, int) {
+	return func(data []byte, eof bool) ([]byte, int) {
+		used := len(data) &^ 1
+		if !eof && used >= 2 {
+			if last := rune(order.Uint16(data[used-2:])); last >= 0xD800 && last < 0xDC00 {
+				used -= 2
+			}
+		}
+		if eof {
+			// A trailing odd byte is not a character; drop it as Convert does
+			return utf16ToUTF8(data[:used], order), len(data)
+		}
+		return utf16ToUTF8(data[:used], order), used
+	}
+}
+
+// singleByteConverter converts a single byte encoding with singleByteToUTF8
+func singleByteConverter(high *[32]rune) func([]byte, bool) ([]byte, int) {
+	return func(data []byte, eof bool) ([]byte, int) {
+		return singleByteToUTF8(data, high), len(data)
+	}
+}
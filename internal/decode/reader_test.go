@@ -0,0 +1,66 @@
+package decode
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestNewReader(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []byte
+		enc   Encoding
+		want  string
+	}{
+		{"plain", []byte("https://example.com/"), Auto, "https://example.com/"},
+		{"utf-8 bom", []byte("\xEF\xBB\xBFhttps://example.com/"), Auto, "https://example.com/"},
+		{"utf-16le", []byte("\xFF\xFEh\x00i\x00 \x00\xe9\x00\n\x00"), Auto, "hi é\n"},
+		{"utf-16be surrogate pair", []byte("\xFE\xFF\x00h\x00i\xd8\x3d\xde\x00"), Auto, "hi😀"},
+		{"utf-16le odd trailing byte", []byte("h\x00i\x00!"), UTF16LE, "hi"},
+		{"windows-1252", []byte("caf\xe9 \x80"), Windows1252, "café €"},
+		{"latin-1", []byte("caf\xe9"), Latin1, "café"},
+		{"empty", nil, Auto, ""},
+	}
+
+	for _, tt := range tests {
+		// Reading a byte at a time splits every character between reads
+		got, err := io.ReadAll(NewReader(iotest.OneByteReader(bytes.NewReader(tt.input)), tt.enc))
+		if err != nil {
+			t.Errorf("%s: error = %v", tt.name, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s: NewReader() read %q, want %q", tt.name, got, tt.want)
+		}
+		if want := string(Convert(tt.input, tt.enc)); string(got) != want {
+			t.Errorf("%s: NewReader() read %q, Convert() = %q", tt.name, got, want)
+		}
+	}
+}
+
+func TestNewReader_LongInput(t *testing.T) {
+	// A character cut off at the end of the sample does not make the input Windows-1252
+	text := strings.Repeat("a", readerSample-1) + "é" + strings.Repeat("line\n", 10000)
+	got, err := io.ReadAll(NewReader(strings.NewReader(text), Auto))
+	if err != nil {
+		t.Fatalf("error = %v", err)
+	}
+	if string(got) != text {
+		t.Errorf("NewReader() read %d bytes, want the %d bytes of the UTF-8 input", len(got), len(text))
+	}
+
+	var utf16 []byte
+	for _, r := range text {
+		utf16 = append(utf16, byte(r), byte(r>>8))
+	}
+	got, err = io.ReadAll(NewReader(bytes.NewReader(utf16), Auto))
+	if err != nil {
+		t.Fatalf("error = %v", err)
+	}
+	if string(got) != text {
+		t.Errorf("NewReader() read %d bytes of UTF-16LE, want %d", len(got), len(text))
+	}
+}
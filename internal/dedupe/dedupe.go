@@ -0,0 +1,83 @@
+// Package dedupe builds deduplication keys that treat trivially different findings as the
+// same finding, such as emails that differ only in case or URLs that differ only by a
+// trailing slash or their locale.
+package dedupe
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/locale"
+)
+
+// Parameter dedup modes
+const (
+	// ParamsFull treats parameters as duplicates only when key and value match
+	ParamsFull = "full"
+	// ParamsKeysOnly treats parameters with the same key as duplicates, whatever their value
+	ParamsKeysOnly = "keys-only"
+)
+
+// Options selects the normalizations applied before findings are compared.
+// The zero value compares values exactly.
+type Options struct {
+	// IgnoreCase lists the types whose values are compared case-insensitively
+	IgnoreCase []finding.Type
+	// ParamKeysOnly compares parameters by key alone
+	ParamKeysOnly bool
+	// IgnoreTrailingSlash compares URLs without the trailing slash of their path
+	IgnoreTrailingSlash bool
+	// IgnoreLocale compares URLs without the locale segment of their path, such as "/fr-fr/"
+	IgnoreLocale bool
+}
+
+// ParseParamsMode validates a parameter dedup mode and reports whether it compares keys only
+func ParseParamsMode(s string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", ParamsFull:
+		return false, nil
+	case ParamsKeysOnly:
+		return true, nil
+	}
+	return false, fmt.Errorf("invalid parameter dedup mode %q: must be %s or %s", s, ParamsFull, ParamsKeysOnly)
+}
+
+// Enabled reports whether any normalization is selected
+func (o Options) Enabled() bool {
+	return len(o.IgnoreCase) > 0 || o.ParamKeysOnly || o.IgnoreTrailingSlash || o.IgnoreLocale
+}
+
+// KeyFunc returns the deduplication key for the options, for use as finding.Set.KeyFunc
+func (o Options) KeyFunc() func(finding.Finding) string {
+	ignoreCase := make(map[finding.Type]bool, len(o.IgnoreCase))
+	for _, t := range o.IgnoreCase {
+		ignoreCase[t] = true
+	}
+	return func(f finding.Finding) string {
+		value := f.Value
+		if f.Type == finding.TypeParam && o.ParamKeysOnly {
+			value, _, _ = strings.Cut(value, "=")
+		}
+		if f.Type == finding.TypeURL && o.IgnoreLocale {
+			value = locale.StripURL(value)
+		}
+		if f.Type == finding.TypeURL && o.IgnoreTrailingSlash {
+			value = trimTrailingSlash(value)
+		}
+		if ignoreCase[f.Type] {
+			value = strings.ToLower(value)
+		}
+		return string(f.Type) + "\x00" + value
+	}
+}
+
+// trimTrailingSlash removes the trailing slashes of the path of rawURL, keeping its query and
+// fragment
+func trimTrailingSlash(rawURL string) string {
+	end := len(rawURL)
+	if i := strings.IndexAny(rawURL, "?#"); i >= 0 {
+		end = i
+	}
+	return strings.TrimRight(rawURL[:end], "/") + rawURL[end:]
+}
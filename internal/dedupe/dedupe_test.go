@@ -0,0 +1,53 @@
+package dedupe
+
+import (
+	"testing"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+func TestOptions_KeyFunc(t *testing.T) {
+	opts := Options{
+		IgnoreCase:          []finding.Type{finding.TypeEmail, finding.TypeDomain},
+		ParamKeysOnly:       true,
+		IgnoreTrailingSlash: true,
+		IgnoreLocale:        true,
+	}
+	key := opts.KeyFunc()
+
+	tests := []struct {
+		name string
+		a, b finding.Finding
+		same bool
+	}{
+		{"email case", finding.Finding{Type: finding.TypeEmail, Value: "Admin@Target.com"}, finding.Finding{Type: finding.TypeEmail, Value: "admin@target.com"}, true},
+		{"domain case", finding.Finding{Type: finding.TypeDomain, Value: "WWW.target.com"}, finding.Finding{Type: finding.TypeDomain, Value: "www.target.com"}, true},
+		{"token case kept", finding.Finding{Type: finding.TypeToken, Value: "AbC123xyz"}, finding.Finding{Type: finding.TypeToken, Value: "abc123xyz"}, false},
+		{"param values", finding.Finding{Type: finding.TypeParam, Value: "id=1"}, finding.Finding{Type: finding.TypeParam, Value: "id=2"}, true},
+		{"param keys", finding.Finding{Type: finding.TypeParam, Value: "id=1"}, finding.Finding{Type: finding.TypeParam, Value: "uid=1"}, false},
+		{"trailing slash", finding.Finding{Type: finding.TypeURL, Value: "https://target.com/docs/"}, finding.Finding{Type: finding.TypeURL, Value: "https://target.com/docs"}, true},
+		{"trailing slash before query", finding.Finding{Type: finding.TypeURL, Value: "https://target.com/api/?v=1"}, finding.Finding{Type: finding.TypeURL, Value: "https://target.com/api?v=1"}, true},
+		{"slash inside path", finding.Finding{Type: finding.TypeURL, Value: "https://target.com/a/b"}, finding.Finding{Type: finding.TypeURL, Value: "https://target.com/ab"}, false},
+		{"locale", finding.Finding{Type: finding.TypeURL, Value: "https://target.com/fr-fr/pricing/"}, finding.Finding{Type: finding.TypeURL, Value: "https://target.com/pricing"}, true},
+		{"locales", finding.Finding{Type: finding.TypeURL, Value: "https://target.com/de/pricing"}, finding.Finding{Type: finding.TypeURL, Value: "https://target.com/en-us/pricing"}, true},
+		{"other segment kept", finding.Finding{Type: finding.TypeURL, Value: "https://target.com/js/app"}, finding.Finding{Type: finding.TypeURL, Value: "https://target.com/app"}, false},
+		{"types kept apart", finding.Finding{Type: finding.TypeEmail, Value: "a@target.com"}, finding.Finding{Type: finding.TypeUsername, Value: "a@target.com"}, false},
+	}
+	for _, tt := range tests {
+		if got := key(tt.a) == key(tt.b); got != tt.same {
+			t.Errorf("%s: same key = %v, want %v", tt.name, got, tt.same)
+		}
+	}
+}
+
+func TestParseParamsMode(t *testing.T) {
+	if keysOnly, err := ParseParamsMode("keys-only"); err != nil || !keysOnly {
+		t.Errorf("ParseParamsMode(keys-only) = %v, %v", keysOnly, err)
+	}
+	if keysOnly, err := ParseParamsMode("full"); err != nil || keysOnly {
+		t.Errorf("ParseParamsMode(full) = %v, %v", keysOnly, err)
+	}
+	if _, err := ParseParamsMode("values"); err == nil {
+		t.Error("ParseParamsMode(values) succeeded")
+	}
+}
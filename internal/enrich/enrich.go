@@ -0,0 +1,201 @@
+// Package enrich fetches the root page of discovered hosts and records triage details
+// (HTTP status, page title, server header and favicon hash) on their findings.
+package enrich
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/PeteJStewart/urlsluice/internal/cache"
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/httpclient"
+)
+
+const (
+	// defaultConcurrency is the number of hosts enriched in parallel when none is configured
+	defaultConcurrency = 4
+	// maxPageSize caps how much of the root page is read (1MB)
+	maxPageSize = 1024 * 1024
+	// maxIconSize caps how much of a favicon is read (256KB)
+	maxIconSize = 256 * 1024
+)
+
+var (
+	titleRegex = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	iconRegex  = regexp.MustCompile(`(?is)<link[^>]+rel=["']?(?:shortcut )?icon["']?[^>]*>`)
+	hrefRegex  = regexp.MustCompile(`(?is)href=["']?([^"'\s>]+)`)
+	spaceRegex = regexp.MustCompile(`\s+`)
+)
+
+// HostInfo is the triage information gathered for a host
+type HostInfo struct {
+	URL         string
+	Status      int
+	Title       string
+	Server      string
+	FaviconHash *int32
+}
+
+// Enricher fetches host details
+type Enricher struct {
+	// Client fetches the root pages of hosts
+	Client httpclient.Doer
+	// Concurrency bounds the number of hosts fetched in parallel
+	Concurrency int
+	// Cache keeps the results of earlier runs; hosts found in it are not fetched again
+	Cache *cache.Cache
+}
+
+// Enrich fetches every domain finding's root page and records the result in its metadata
+// under "url", "status", "title", "server" and "favicon_mmh3". Unreachable hosts are left unchanged.
+func (e *Enricher) Enrich(ctx context.Context, findings []finding.Finding) {
+	concurrency := e.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for i := range findings {
+		if findings[i].Type != finding.TypeDomain {
+			continue
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(f *finding.Finding) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			info := new(HostInfo)
+			if !e.Cache.Get("enrich", f.Value, info) {
+				var err error
+				if info, err = e.Host(ctx, f.Value); err != nil {
+					return
+				}
+				e.Cache.Put("enrich", f.Value, info)
+			}
+			f.SetMeta("url", info.URL)
+			f.SetMeta("status", strconv.Itoa(info.Status))
+			if info.Title != "" {
+				f.SetMeta("title", info.Title)
+			}
+			if info.Server != "" {
+				f.SetMeta("server", info.Server)
+			}
+			if info.FaviconHash != nil {
+				f.SetMeta("favicon_mmh3", strconv.Itoa(int(*info.FaviconHash)))
+			}
+		}(&findings[i])
+	}
+	wg.Wait()
+}
+
+// Host fetches the root page of host over HTTPS, falling back to HTTP
+func (e *Enricher) Host(ctx context.Context, host string) (*HostInfo, error) {
+	var lastErr error
+	for _, scheme := range []string{"https", "http"} {
+		info, err := e.fetch(ctx, scheme+"://"+host+"/")
+		if err == nil {
+			return info, nil
+		}
+		lastErr = err
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, lastErr
+}
+
+func (e *Enricher) fetch(ctx context.Context, rootURL string) (*HostInfo, error) {
+	resp, err := e.get(ctx, rootURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPageSize))
+	if err != nil {
+		return nil, err
+	}
+
+	info := &HostInfo{
+		URL:    resp.Request.URL.String(),
+		Status: resp.StatusCode,
+		Title:  pageTitle(body),
+		Server: resp.Header.Get("Server"),
+	}
+
+	if iconURL := faviconURL(resp.Request.URL, body); iconURL != "" {
+		if icon, err := e.favicon(ctx, iconURL); err == nil {
+			hash := FaviconHash(icon)
+			info.FaviconHash = &hash
+		}
+	}
+	return info, nil
+}
+
+func (e *Enricher) favicon(ctx context.Context, iconURL string) ([]byte, error) {
+	resp, err := e.get(ctx, iconURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching favicon %s: unexpected status %s", iconURL, resp.Status)
+	}
+	icon, err := io.ReadAll(io.LimitReader(resp.Body, maxIconSize))
+	if err != nil {
+		return nil, err
+	}
+	if len(icon) == 0 {
+		return nil, fmt.Errorf("fetching favicon %s: empty body", iconURL)
+	}
+	return icon, nil
+}
+
+func (e *Enricher) get(ctx context.Context, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	return httpclient.Do(e.Client, req)
+}
+
+// pageTitle returns the normalized contents of the first <title> element
+func pageTitle(body []byte) string {
+	m := titleRegex.FindSubmatch(body)
+	if m == nil {
+		return ""
+	}
+	return strings.TrimSpace(spaceRegex.ReplaceAllString(html.UnescapeString(string(m[1])), " "))
+}
+
+// faviconURL resolves the icon declared by the page, defaulting to /favicon.ico
+func faviconURL(base *url.URL, body []byte) string {
+	ref := "/favicon.ico"
+	if link := iconRegex.Find(body); link != nil {
+		if m := hrefRegex.FindSubmatch(link); m != nil {
+			ref = html.UnescapeString(string(m[1]))
+		}
+	}
+	u, err := base.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	return u.String()
+}
@@ -0,0 +1,64 @@
+package enrich
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+func TestEnricher_Enrich(t *testing.T) {
+	icon := []byte("\x00\x00\x01\x00fake-icon")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Header().Set("Server", "nginx/1.25")
+			fmt.Fprint(w, `<html><head><title>
+				Admin &amp; Login </title><link rel="icon" href="/static/icon.png"></head></html>`)
+		case "/static/icon.png":
+			w.Write(icon)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	host := strings.TrimPrefix(srv.URL, "http://")
+	findings := []finding.Finding{
+		{Type: finding.TypeDomain, Value: host},
+		{Type: finding.TypeEmail, Value: "dev@" + host},
+	}
+
+	e := &Enricher{}
+	e.Enrich(context.Background(), findings)
+
+	meta := findings[0].Metadata
+	want := map[string]string{
+		"url":          srv.URL + "/",
+		"status":       "200",
+		"title":        "Admin & Login",
+		"server":       "nginx/1.25",
+		"favicon_mmh3": strconv.Itoa(int(FaviconHash(icon))),
+	}
+	for k, v := range want {
+		if meta[k] != v {
+			t.Errorf("metadata[%q] = %q, want %q", k, meta[k], v)
+		}
+	}
+	if findings[1].Metadata != nil {
+		t.Errorf("email finding should not be enriched, got %v", findings[1].Metadata)
+	}
+}
+
+func TestEnricher_Unreachable(t *testing.T) {
+	findings := []finding.Finding{{Type: finding.TypeDomain, Value: "127.0.0.1:1"}}
+	(&Enricher{}).Enrich(context.Background(), findings)
+	if findings[0].Metadata != nil {
+		t.Errorf("unreachable host should not be enriched, got %v", findings[0].Metadata)
+	}
+}
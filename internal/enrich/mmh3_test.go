@@ -0,0 +1,20 @@
+package enrich
+
+import "testing"
+
+func TestMmh3(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int32
+	}{
+		{"", 0},
+		{"hello", 613153351},
+		{"The quick brown fox jumps over the lazy dog", 776992547},
+	}
+
+	for _, tt := range tests {
+		if got := mmh3([]byte(tt.input)); got != tt.want {
+			t.Errorf("mmh3(%q) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+}
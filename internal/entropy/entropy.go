@@ -0,0 +1,32 @@
+// Package entropy measures how random a string looks.
+// It is used to separate generated values such as session tokens and keys from ordinary words.
+package entropy
+
+import "math"
+
+// Shannon returns the Shannon entropy of s in bits per character.
+// An empty string has an entropy of 0.
+func Shannon(s string) float64 {
+	if s == "" {
+		return 0
+	}
+
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+
+	var h float64
+	for _, c := range counts {
+		p := float64(c) / float64(total)
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+// IsRandom reports whether s has an entropy of at least min bits per character
+func IsRandom(s string, min float64) bool {
+	return Shannon(s) >= min
+}
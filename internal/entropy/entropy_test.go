@@ -0,0 +1,34 @@
+package entropy
+
+import (
+	"math"
+	"testing"
+)
+
+func TestShannon(t *testing.T) {
+	tests := []struct {
+		input string
+		want  float64
+	}{
+		{"", 0},
+		{"aaaa", 0},
+		{"ab", 1},
+		{"abcd", 2},
+		{"0123456789abcdef", 4},
+	}
+
+	for _, tt := range tests {
+		if got := Shannon(tt.input); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Shannon(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestIsRandom(t *testing.T) {
+	if IsRandom("passwordpassword", 4.0) {
+		t.Error("IsRandom(passwordpassword) = true, want false")
+	}
+	if !IsRandom("q8Xv2LmZ7pRt0KwN4sYb", 4.0) {
+		t.Error("IsRandom(random token) = false, want true")
+	}
+}
@@ -0,0 +1,30 @@
+package extractor
+
+import (
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/patterns"
+)
+
+// matchAvatarHashes reports the email hashes of avatars: the MD5 or SHA-256 in Gravatar and
+// Libravatar URLs with high confidence, and hex strings next to keys such as "avatar" or
+// "emailHash" with medium confidence. Hashes are lowercased and record their "algorithm".
+func matchAvatarHashes(line string, emit func(finding.Finding)) {
+	report := func(hash string, confidence finding.Confidence) {
+		hash = strings.ToLower(hash)
+		f := finding.Finding{Type: finding.TypeAvatarHash, Value: hash, Confidence: confidence}
+		if len(hash) == 64 {
+			f.SetMeta("algorithm", "sha256")
+		} else {
+			f.SetMeta("algorithm", "md5")
+		}
+		emit(f)
+	}
+	for _, match := range patterns.AvatarURLRegex.FindAllStringSubmatch(line, -1) {
+		report(match[1], finding.ConfidenceHigh)
+	}
+	for _, match := range patterns.AvatarHashRegex.FindAllStringSubmatch(line, -1) {
+		report(match[1], finding.ConfidenceMedium)
+	}
+}
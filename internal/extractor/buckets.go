@@ -0,0 +1,78 @@
+package extractor
+
+import (
+	"net"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/patterns"
+)
+
+// matchBuckets reports storage bucket names, recording the "provider" in the metadata:
+// S3 buckets from virtual-hosted and path-style endpoints, s3:// URIs and ARNs (with the
+// "region" when the endpoint names it), Google Cloud Storage buckets from endpoints and gs://
+// URIs as "gcs:bucket", and Azure Blob Storage containers as "azure:account/container"
+func matchBuckets(line string, emit func(finding.Finding)) {
+	report := func(bucket, region string) {
+		bucket = strings.ToLower(bucket)
+		if !validBucket(bucket) {
+			return
+		}
+		f := finding.Finding{Type: finding.TypeBucket, Value: bucket, Confidence: finding.ConfidenceHigh}
+		f.SetMeta("provider", "s3")
+		if region != "" {
+			f.SetMeta("region", strings.ToLower(region))
+		}
+		emit(f)
+	}
+	for _, match := range patterns.S3HostRegex.FindAllStringSubmatch(line, -1) {
+		report(match[1], match[2])
+	}
+	for _, match := range patterns.S3PathRegex.FindAllStringSubmatch(line, -1) {
+		report(match[2], match[1])
+	}
+	for _, match := range patterns.S3URIRegex.FindAllStringSubmatch(line, -1) {
+		report(match[1], "")
+	}
+
+	reportGCS := func(bucket string) {
+		bucket = strings.ToLower(bucket)
+		if !validGCSBucket(bucket) {
+			return
+		}
+		f := finding.Finding{Type: finding.TypeBucket, Value: "gcs:" + bucket, Confidence: finding.ConfidenceHigh}
+		f.SetMeta("provider", "gcs")
+		emit(f)
+	}
+	for _, match := range patterns.GCSHostRegex.FindAllStringSubmatch(line, -1) {
+		reportGCS(match[1])
+	}
+	for _, match := range patterns.GCSPathRegex.FindAllStringSubmatch(line, -1) {
+		reportGCS(match[1])
+	}
+
+	for _, match := range patterns.AzureBlobRegex.FindAllStringSubmatch(line, -1) {
+		value := strings.ToLower(match[1])
+		if match[2] != "" {
+			if strings.Contains(match[2], "--") {
+				continue
+			}
+			value += "/" + strings.ToLower(match[2])
+		}
+		f := finding.Finding{Type: finding.TypeBucket, Value: "azure:" + value, Confidence: finding.ConfidenceHigh}
+		f.SetMeta("provider", "azure")
+		emit(f)
+	}
+}
+
+// validBucket applies the S3 naming rules the patterns cannot express
+func validBucket(name string) bool {
+	return !strings.Contains(name, "..") && !strings.Contains(name, ".-") && !strings.Contains(name, "-.") &&
+		net.ParseIP(name) == nil && !strings.HasPrefix(name, "xn--") && !strings.HasSuffix(name, "-s3alias")
+}
+
+// validGCSBucket applies the Google Cloud Storage naming rules the patterns cannot express
+func validGCSBucket(name string) bool {
+	return !strings.Contains(name, "..") && net.ParseIP(name) == nil && !strings.HasPrefix(name, "goog") &&
+		!strings.Contains(name, "google")
+}
@@ -0,0 +1,86 @@
+package extractor
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/patterns"
+)
+
+// firebaseKeys are the property names of a Firebase web config object
+var firebaseKeys = []string{"apiKey", "authDomain", "databaseURL", "projectId", "storageBucket", "messagingSenderId", "appId", "measurementId"}
+
+// firebaseProjectRegex captures the project ID from a Firebase config on the same line
+var firebaseProjectRegex = regexp.MustCompile(`projectId["']?\s*:\s*["']([a-z0-9-]+)["']`)
+
+// matchCloudConfigs reports API keys and DSNs from embedded Firebase, Google Maps, Sentry,
+// Segment and Amplitude configs. Each finding records the service and the key the value was
+// assigned to; Firebase keys also list the config keys found alongside them as evidence.
+func matchCloudConfigs(line string, emit func(finding.Finding)) {
+	newFinding := func(service, value string, start int, confidence finding.Confidence) finding.Finding {
+		f := finding.Finding{Type: finding.TypeCloudConfig, Value: value, Confidence: confidence}
+		f.SetMeta("service", service)
+		if key := assignedKey(line, start); key != "" {
+			f.SetMeta("key", key)
+		}
+		return f
+	}
+
+	for _, loc := range patterns.GoogleAPIKeyRegex.FindAllStringIndex(line, -1) {
+		evidence := presentKeys(line, firebaseKeys)
+		isFirebase := len(evidence) > 1 || strings.Contains(line, "firebaseapp.com") || strings.Contains(line, "firebaseio.com")
+		service := "google_api"
+		if isFirebase {
+			service = "firebase"
+		} else if strings.Contains(line, "maps.googleapis.com") || strings.Contains(strings.ToLower(assignedKey(line, loc[0])), "map") {
+			service = "google_maps"
+		}
+		f := newFinding(service, line[loc[0]:loc[1]], loc[0], finding.ConfidenceHigh)
+		if isFirebase {
+			if len(evidence) > 0 {
+				f.SetMeta("evidence", strings.Join(evidence, ","))
+			}
+			if m := firebaseProjectRegex.FindStringSubmatch(line); m != nil {
+				f.SetMeta("project_id", m[1])
+			}
+		}
+		emit(f)
+	}
+
+	for _, loc := range patterns.SentryDSNRegex.FindAllStringIndex(line, -1) {
+		emit(newFinding("sentry", line[loc[0]:loc[1]], loc[0], finding.ConfidenceHigh))
+	}
+
+	for _, m := range patterns.SegmentWriteKeyRegex.FindAllStringSubmatchIndex(line, -1) {
+		emit(newFinding("segment", line[m[2]:m[3]], m[2], finding.ConfidenceMedium))
+	}
+
+	for _, m := range patterns.AmplitudeKeyRegex.FindAllStringSubmatchIndex(line, -1) {
+		emit(newFinding("amplitude", line[m[2]:m[3]], m[2], finding.ConfidenceMedium))
+	}
+}
+
+// assignedKey returns the name of the key or variable the value starting at start is assigned to,
+// e.g. "apiKey" for `apiKey: "AIza..."`, or "" if the value is not part of an assignment
+func assignedKey(line string, start int) string {
+	prefix := line[:start]
+	if len(prefix) > 128 {
+		prefix = prefix[len(prefix)-128:]
+	}
+	if m := patterns.ConfigKeyRegex.FindStringSubmatch(prefix); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// presentKeys returns the keys that appear as property names on the line
+func presentKeys(line string, keys []string) []string {
+	var present []string
+	for _, key := range keys {
+		if strings.Contains(line, key+":") || strings.Contains(line, key+`":`) || strings.Contains(line, key+" :") {
+			present = append(present, key)
+		}
+	}
+	return present
+}
@@ -0,0 +1,113 @@
+package extractor
+
+import (
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+const (
+	// maxCommentLength is the longest comment searched for; an opener without a close within
+	// it is more likely a path or glob such as "/api/*" than a comment
+	maxCommentLength = 8 * 1024
+	// maxCommentValue caps the reported text of a comment
+	maxCommentValue = 500
+)
+
+// extractComments reports the HTML comments (<!-- -->) and JavaScript block comments (/* */)
+// of a document, with whitespace collapsed and the kind ("html" or "js") in the metadata.
+// With keywords, only comments containing one of them, ignoring case, are reported and the
+// keywords found are recorded in "keywords". Each comment is reported at its first line.
+func extractComments(text string, keywords []string) []finding.Finding {
+	var findings []finding.Finding
+	line := 1
+	for i := 0; i < len(text); {
+		kind, open, close := "", "", ""
+		switch {
+		case strings.HasPrefix(text[i:], "<!--"):
+			kind, open, close = "html", "<!--", "-->"
+		case strings.HasPrefix(text[i:], "/*") && jsCommentStart(text, i):
+			kind, open, close = "js", "/*", "*/"
+		default:
+			if text[i] == '\n' {
+				line++
+			}
+			i++
+			continue
+		}
+		body := text[i+len(open):]
+		if len(body) > maxCommentLength {
+			body = body[:maxCommentLength]
+		}
+		end := strings.Index(body, close)
+		if end < 0 {
+			i++
+			continue
+		}
+		if f, ok := newComment(kind, body[:end], keywords); ok {
+			f.Line = line
+			findings = append(findings, f)
+		}
+		consumed := text[i : i+len(open)+end+len(close)]
+		line += strings.Count(consumed, "\n")
+		i += len(consumed)
+	}
+	return findings
+}
+
+// jsCommentStart reports whether the "/*" at i starts a comment rather than continuing a
+// word, path or glob such as "text/*"; minified license banners ("/*!") are skipped
+func jsCommentStart(text string, i int) bool {
+	if strings.HasPrefix(text[i:], "/*!") {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+	return strings.IndexByte(" \t\r\n;{}(),=>", text[i-1]) >= 0
+}
+
+// newComment returns the finding for the comment text, unless it is empty, a conditional
+// comment such as "[if IE]" or does not contain one of the keywords. The "*" that
+// decorates the lines of block comments is removed.
+func newComment(kind, text string, keywords []string) (finding.Finding, bool) {
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		lines[i] = strings.Trim(strings.TrimSpace(l), "*")
+	}
+	text = strings.Join(strings.Fields(strings.Join(lines, " ")), " ")
+	if text == "" || strings.HasPrefix(text, "[if ") || strings.HasPrefix(text, "<![endif]") {
+		return finding.Finding{}, false
+	}
+	var found []string
+	lower := strings.ToLower(text)
+	for _, k := range keywords {
+		if strings.Contains(lower, strings.ToLower(k)) {
+			found = append(found, k)
+		}
+	}
+	if len(keywords) > 0 && len(found) == 0 {
+		return finding.Finding{}, false
+	}
+	if len(text) > maxCommentValue {
+		text = truncateUTF8(text, maxCommentValue) + "..."
+	}
+	f := finding.Finding{Type: finding.TypeComment, Value: text, Confidence: finding.ConfidenceHigh}
+	if kind == "js" {
+		// An unquoted "/*" can still be inside a string or regular expression
+		f.Confidence = finding.ConfidenceMedium
+	}
+	f.SetMeta("kind", kind)
+	if len(found) > 0 {
+		f.SetMeta("keywords", strings.Join(found, ","))
+	}
+	return f, true
+}
+
+// truncateUTF8 shortens s to at most n bytes without splitting a character
+func truncateUTF8(s string, n int) string {
+	for n > 0 && n < len(s) && s[n]&0xC0 == 0x80 {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,189 @@
+package extractor
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/patterns"
+)
+
+// base58Alphabet is the Bitcoin base58 alphabet, also used by Monero
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// bech32Charset maps 5-bit values to bech32 characters
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+const (
+	bech32Const  = 1
+	bech32mConst = 0x2bc830a3
+)
+
+// matchCrypto reports Bitcoin, Ethereum and Monero addresses.
+// Addresses with a checksum are only reported when it verifies; all-lowercase or all-uppercase
+// Ethereum addresses carry no checksum and are reported with medium confidence.
+func matchCrypto(line string, emit func(finding.Finding)) {
+	for currency, regex := range patterns.CryptoRegexMap {
+		for _, addr := range regex.FindAllString(line, -1) {
+			confidence, ok := cryptoConfidence(currency, addr)
+			if !ok {
+				continue
+			}
+			f := finding.Finding{Type: finding.TypeCrypto, Value: addr, Confidence: confidence}
+			f.SetMeta("currency", currency)
+			emit(f)
+		}
+	}
+}
+
+// cryptoConfidence validates addr for currency, returning false when its checksum is wrong
+func cryptoConfidence(currency, addr string) (finding.Confidence, bool) {
+	switch currency {
+	case "bitcoin":
+		if strings.HasPrefix(strings.ToLower(addr), "bc1") {
+			return finding.ConfidenceHigh, validBech32Address(addr)
+		}
+		return finding.ConfidenceHigh, validBase58Check(addr)
+	case "ethereum":
+		hexPart := addr[2:]
+		if hexPart == strings.ToLower(hexPart) || hexPart == strings.ToUpper(hexPart) {
+			return finding.ConfidenceMedium, true
+		}
+		return finding.ConfidenceHigh, validEIP55(addr)
+	case "monero":
+		return finding.ConfidenceHigh, validMoneroAddress(addr)
+	}
+	return "", false
+}
+
+// validBase58Check verifies a legacy Bitcoin address: 25 bytes ending in a double SHA-256 checksum
+func validBase58Check(addr string) bool {
+	n := new(big.Int)
+	for _, r := range addr {
+		idx := strings.IndexRune(base58Alphabet, r)
+		if idx < 0 {
+			return false
+		}
+		n.Mul(n, big.NewInt(58))
+		n.Add(n, big.NewInt(int64(idx)))
+	}
+	decoded := n.Bytes()
+	for i := 0; i < len(addr) && addr[i] == '1'; i++ {
+		decoded = append([]byte{0}, decoded...)
+	}
+	if len(decoded) != 25 {
+		return false
+	}
+	first := sha256.Sum256(decoded[:21])
+	second := sha256.Sum256(first[:])
+	return bytes.Equal(second[:4], decoded[21:])
+}
+
+// validBech32Address verifies a segwit address (BIP 173 for version 0, BIP 350 for later versions)
+func validBech32Address(addr string) bool {
+	if addr != strings.ToLower(addr) && addr != strings.ToUpper(addr) {
+		return false
+	}
+	addr = strings.ToLower(addr)
+	sep := strings.LastIndexByte(addr, '1')
+	if sep < 1 || len(addr)-sep-1 < 7 {
+		return false
+	}
+	hrp, rest := addr[:sep], addr[sep+1:]
+
+	values := make([]int, 0, len(hrp)*2+1+len(rest))
+	for _, c := range hrp {
+		values = append(values, int(c)>>5)
+	}
+	values = append(values, 0)
+	for _, c := range hrp {
+		values = append(values, int(c)&31)
+	}
+	for _, c := range rest {
+		v := strings.IndexRune(bech32Charset, c)
+		if v < 0 {
+			return false
+		}
+		values = append(values, v)
+	}
+
+	want := bech32Const
+	if version := values[len(hrp)*2+1]; version > 16 {
+		return false
+	} else if version > 0 {
+		want = bech32mConst
+	}
+	return bech32Polymod(values) == want
+}
+
+func bech32Polymod(values []int) int {
+	generator := [5]int{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := 1
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ v
+		for i := 0; i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+// validEIP55 verifies the mixed-case checksum of an Ethereum address
+func validEIP55(addr string) bool {
+	hexPart := addr[2:]
+	hash := keccak256([]byte(strings.ToLower(hexPart)))
+	hashHex := hex.EncodeToString(hash[:])
+	for i, c := range hexPart {
+		if c >= '0' && c <= '9' {
+			continue
+		}
+		upper := hashHex[i] >= '8'
+		if upper != (c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// moneroBlockSizes maps the length of a final partial base58 block to its decoded byte count
+var moneroBlockSizes = map[int]int{2: 1, 3: 2, 5: 3, 6: 4, 7: 5, 9: 6, 10: 7, 11: 8}
+
+// validMoneroAddress decodes Monero's block-wise base58 and verifies the Keccak checksum
+func validMoneroAddress(addr string) bool {
+	var decoded []byte
+	for off := 0; off < len(addr); off += 11 {
+		end := off + 11
+		if end > len(addr) {
+			end = len(addr)
+		}
+		size, ok := moneroBlockSizes[end-off]
+		if !ok {
+			return false
+		}
+		n := new(big.Int)
+		for _, r := range addr[off:end] {
+			idx := strings.IndexRune(base58Alphabet, r)
+			if idx < 0 {
+				return false
+			}
+			n.Mul(n, big.NewInt(58))
+			n.Add(n, big.NewInt(int64(idx)))
+		}
+		if n.BitLen() > size*8 {
+			return false
+		}
+		decoded = append(decoded, n.FillBytes(make([]byte, size))...)
+	}
+	if len(decoded) < 5 {
+		return false
+	}
+	body, checksum := decoded[:len(decoded)-4], decoded[len(decoded)-4:]
+	hash := keccak256(body)
+	return bytes.Equal(hash[:4], checksum)
+}
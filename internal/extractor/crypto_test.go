@@ -0,0 +1,94 @@
+package extractor
+
+import (
+	"context"
+	"encoding/hex"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+func TestKeccak256(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"", "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470"},
+		{"abc", "4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45"},
+	}
+
+	for _, tt := range tests {
+		got := keccak256([]byte(tt.input))
+		if hex.EncodeToString(got[:]) != tt.want {
+			t.Errorf("keccak256(%q) = %x, want %s", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestCryptoConfidence(t *testing.T) {
+	tests := []struct {
+		currency string
+		addr     string
+		want     finding.Confidence
+		wantOK   bool
+	}{
+		{"bitcoin", "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", finding.ConfidenceHigh, true},
+		{"bitcoin", "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNb", finding.ConfidenceHigh, false},
+		{"bitcoin", "3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy", finding.ConfidenceHigh, true},
+		{"bitcoin", "BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4", finding.ConfidenceHigh, true},
+		{"bitcoin", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t5", finding.ConfidenceHigh, false},
+		{"bitcoin", "bc1QW508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", finding.ConfidenceHigh, false},
+		{"bitcoin", "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0", finding.ConfidenceHigh, true},
+		{"ethereum", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", finding.ConfidenceHigh, true},
+		{"ethereum", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD", finding.ConfidenceHigh, false},
+		{"ethereum", "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", finding.ConfidenceMedium, true},
+		{"monero", "44AFFq5kSiGBoZ4NMDwYtN18obc8AemS33DBLWs3H7otXft3XjrpDtQGv7SqSsaBYBb98uNbr2VBBEt7f2wfn3RVGQBEP3A", finding.ConfidenceHigh, true},
+		{"monero", "44AFFq5kSiGBoZ4NMDwYtN18obc8AemS33DBLWs3H7otXft3XjrpDtQGv7SqSsaBYBb98uNbr2VBBEt7f2wfn3RVGQBEP3B", finding.ConfidenceHigh, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			got, ok := cryptoConfidence(tt.currency, tt.addr)
+			if ok != tt.wantOK {
+				t.Fatalf("cryptoConfidence(%q) ok = %v, want %v", tt.addr, ok, tt.wantOK)
+			}
+			if ok && got != tt.want {
+				t.Errorf("cryptoConfidence(%q) = %q, want %q", tt.addr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractor_Crypto(t *testing.T) {
+	input := `Donate BTC: 1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa or bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq
+ETH: 0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed (typo: 0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD)
+not an address: 1111111111111111111111111111111111`
+
+	ext, err := New(Config{ExtractCrypto: true})
+	if err != nil {
+		t.Fatalf("Failed to create extractor: %v", err)
+	}
+
+	got, err := ext.Extract(context.Background(), strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+
+	want := map[finding.Type][]string{
+		finding.TypeCrypto: {
+			"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+			"1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa",
+			"bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq",
+		},
+	}
+	if !reflect.DeepEqual(findingValues(got), want) {
+		t.Errorf("Extract() = %v, want %v", findingValues(got), want)
+	}
+	for _, f := range got.Findings {
+		if f.Metadata["currency"] == "" {
+			t.Errorf("%q is missing currency metadata", f.Value)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package extractor
+
+import (
+	"html"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/patterns"
+)
+
+// Kinds of obfuscation recorded in the "obfuscation" metadata of deobfuscated emails
+const (
+	obfuscationEntities = "html-entities"
+	obfuscationBrackets = "brackets"
+	obfuscationWords    = "words"
+)
+
+// matchObfuscatedEmails reports addresses hidden from plain email matching, such as
+// "user [at] example [dot] com", "user(at)example.com", "user at example dot com" and
+// HTML entity encoded forms like "user&#64;example&#46;com", normalized to "user@example.com"
+func matchObfuscatedEmails(line string, emit func(finding.Finding)) {
+	text := line
+	if strings.Contains(line, "&") {
+		text = html.UnescapeString(line)
+		if text != line {
+			plain := make(map[string]bool)
+			for _, email := range patterns.EmailRegex.FindAllString(line, -1) {
+				plain[email] = true
+			}
+			for _, email := range patterns.EmailRegex.FindAllString(text, -1) {
+				if !plain[email] {
+					emitObfuscatedEmail(email, obfuscationEntities, emailConfidence(email), emit)
+				}
+			}
+		}
+	}
+
+	for _, match := range patterns.ObfuscatedEmailRegex.FindAllStringSubmatch(text, -1) {
+		local, at, domain := match[1], match[2], match[3]
+		kind := obfuscationBrackets
+		if strings.EqualFold(strings.TrimSpace(at), "at") {
+			// "available at docs.example.com" is prose; a spelled out "at" needs a spelled out dot
+			if !patterns.ObfuscatedDotRegex.MatchString(domain) {
+				continue
+			}
+			kind = obfuscationWords
+		}
+		email := deobfuscate(local) + "@" + deobfuscate(domain)
+		if patterns.EmailRegex.FindString(email) != email {
+			continue
+		}
+		confidence := emailConfidence(email)
+		if kind == obfuscationWords && confidence == finding.ConfidenceHigh {
+			confidence = finding.ConfidenceMedium
+		}
+		emitObfuscatedEmail(email, kind, confidence, emit)
+	}
+}
+
+func emitObfuscatedEmail(email, kind string, confidence finding.Confidence, emit func(finding.Finding)) {
+	f := finding.Finding{Type: finding.TypeEmail, Value: email, Confidence: confidence}
+	f.SetMeta("obfuscation", kind)
+	emit(f)
+}
+
+// deobfuscate replaces the "[dot]" style separators of s with "."
+func deobfuscate(s string) string {
+	return patterns.ObfuscatedDotRegex.ReplaceAllString(s, ".")
+}
@@ -0,0 +1,67 @@
+package extractor
+
+import (
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/multimatch"
+)
+
+// errorSignature is text that a framework only writes into verbose error pages and stack traces
+type errorSignature struct {
+	framework  string
+	pattern    string
+	confidence finding.Confidence
+}
+
+// errorSignatures lists the signatures matched by matchVerboseErrors. Debug pages and stack
+// frames are rated high; generic messages that also appear in documentation are lower.
+var errorSignatures = []errorSignature{
+	{"ASP.NET", `Server Error in '[^']*' Application|\[HttpException[^\]]*\]|System\.Web\.HttpUnhandledException|ASP\.NET is configured to show verbose error messages`, finding.ConfidenceHigh},
+	{".NET", `\bat [\w.` + "`" + `<>]+\([^)]*\) in [^\n]+:line \d+`, finding.ConfidenceHigh},
+	{"Django", `You(?:'|&#39;|&#x27;)re seeing this error because you have <code>DEBUG = True</code>|\bdjango\.(?:core|db|urls|template)\.[\w.]*(?:Exception|Error|DoesNotExist|NoReverseMatch)\b`, finding.ConfidenceHigh},
+	{"Werkzeug", `Werkzeug Debugger|The debugger caught an exception in your WSGI application`, finding.ConfidenceHigh},
+	{"Python", `Traceback \(most recent call last\)|File "[^"]+\.py", line \d+, in \w+`, finding.ConfidenceMedium},
+	{"Laravel", `Whoops! There was an error|Illuminate\\[\w\\]+Exception|vendor/laravel/framework/src`, finding.ConfidenceHigh},
+	{"PHP", `(?:<b>)?(?:Fatal error|Parse error|Warning|Notice|Deprecated)(?:</b>)?:\s+.{1,300}? in (?:<b>)?(?:/|[A-Za-z]:\\)[^<\s]+\.php(?:</b>)? on line (?:<b>)?\d+|PHP (?:Fatal error|Parse error|Warning|Notice):`, finding.ConfidenceHigh},
+	{"Java", `\bat (?:[\w$]+\.)+[\w$<>]+\([\w$]+\.java:\d+\)|\bjava\.(?:lang|io|sql|util)\.\w+(?:Exception|Error)\b|Apache Tomcat/[\d.]+ - Error report`, finding.ConfidenceHigh},
+	{"Spring Boot", `Whitelabel Error Page`, finding.ConfidenceMedium},
+	{"Ruby on Rails", `\bAction(?:Controller|View|Dispatch)::\w+Error\b|\bActiveRecord::\w+(?:Error|Invalid|NotFound)\b|app/(?:controllers|models|views)/[\w/]+\.(?:rb|erb):\d+:in`, finding.ConfidenceHigh},
+	{"Node.js", `\bat (?:[\w$.<>]+ )?\((?:/|[A-Za-z]:\\)[^)\s]+\.(?:js|mjs|cjs|ts):\d+:\d+\)|Error: Cannot find module '[^']+'`, finding.ConfidenceHigh},
+	{"MySQL", `You have an error in your SQL syntax|mysqli?_(?:fetch|query|num_rows)\w*\(\)`, finding.ConfidenceHigh},
+	{"PostgreSQL", `ERROR:\s+syntax error at or near|pg_query\(\): Query failed`, finding.ConfidenceHigh},
+	{"Oracle", `\bORA-\d{5}: `, finding.ConfidenceMedium},
+	{"SQL Server", `Unclosed quotation mark after the character string|System\.Data\.SqlClient\.SqlException`, finding.ConfidenceHigh},
+}
+
+// errorSignatureSet matches every signature in one pass over the line where the hyperscan
+// backend is built in, since most lines of a large input match none of them
+var errorSignatureSet = func() *multimatch.Set {
+	patterns := make([]string, len(errorSignatures))
+	for i, sig := range errorSignatures {
+		patterns[i] = sig.pattern
+	}
+	return multimatch.MustNew(patterns...)
+}()
+
+// maxEvidenceLength caps the matched text recorded as evidence, since PHP messages run long
+const maxEvidenceLength = 200
+
+// matchVerboseErrors reports the frameworks whose verbose error pages or stack traces appear
+// in the line, with the matched text in the "evidence" metadata. The value is the framework;
+// the pipeline prefixes it with the page the error was found on.
+func matchVerboseErrors(line string, emit func(finding.Finding)) {
+	errorSignatureSet.Each(line, func(i int, evidence string) {
+		if evidence == "" {
+			return
+		}
+		sig := errorSignatures[i]
+		if len(evidence) > maxEvidenceLength {
+			evidence = evidence[:maxEvidenceLength]
+		}
+		f := finding.Finding{Type: finding.TypeVerboseError, Value: sig.framework, Confidence: sig.confidence}
+		f.SetMeta("framework", sig.framework)
+		f.SetMeta("evidence", strings.TrimSpace(evidence))
+		emit(f)
+	})
+}
@@ -1,19 +1,21 @@
 // Package extractor provides functionality for extracting and validating various patterns from text input.
 // It supports concurrent processing of large files while maintaining memory efficiency through chunked processing.
-// Supported patterns include UUIDs, email addresses, domain names, IP addresses, and URL query parameters.
+// Supported patterns include UUIDs, email addresses, domain names, IP addresses, URL query parameters,
+// URLs, high entropy tokens, social media handles, cryptocurrency addresses, cloud service keys, config file secrets and timestamped identifiers.
 package extractor
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"fmt"
 	"io"
-	"net"
 	"os"
 	"strings"
 	"sync"
 
-	"github.com/PeteJStewart/urlsluice/internal/patterns"
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/structured"
 )
 
 // ExtractorError represents an error that occurred during extraction
@@ -40,140 +42,279 @@ type Extractor interface {
 }
 
 // Results contains all patterns found during extraction.
-// Each field is a map using the pattern as key and a boolean as value to ensure uniqueness.
+// Findings are unique by type and value and ordered with finding.Sort.
 type Results struct {
-	// UUIDs stores unique Universal Unique Identifiers
-	UUIDs map[string]bool
-	// Emails stores unique email addresses
-	Emails map[string]bool
-	// Domains stores unique domain names extracted from URLs
-	Domains map[string]bool
-	// IPs stores unique IPv4 addresses
-	IPs map[string]bool
-	// Params stores unique URL query parameters in "key=value" format
-	Params map[string]bool
+	// Findings stores every unique match found in the input
+	Findings []finding.Finding
+}
+
+// ByType returns the findings of the given type
+func (r Results) ByType(t finding.Type) []finding.Finding {
+	var out []finding.Finding
+	for _, f := range r.Findings {
+		if f.Type == t {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// Values returns the values of the findings of the given type in output order
+func (r Results) Values(t finding.Type) []string {
+	var out []string
+	for _, f := range r.Findings {
+		if f.Type == t {
+			out = append(out, f.Value)
+		}
+	}
+	return out
 }
 
 // Config defines the configuration for pattern extraction
 type Config struct {
-	UUIDVersion    int  // Version of UUIDs to extract (1-5)
-	ExtractEmails  bool // Whether to extract email addresses
-	ExtractDomains bool // Whether to extract domain names
-	ExtractIPs     bool // Whether to extract IP addresses
-	ExtractParams  bool // Whether to extract query parameters
+	UUIDVersion       int      // Version of UUIDs to extract (1-5)
+	UUIDAll           bool     // Whether to extract UUIDs of every version, recording each version (overrides UUIDVersion)
+	ExtractEmails     bool     // Whether to extract email addresses
+	DeobfuscateEmails bool     // Whether to also extract emails written as "user [at] example [dot] com" or with HTML entities
+	ExtractDomains    bool     // Whether to extract domain names
+	ExtractIPs        bool     // Whether to extract IP addresses
+	ExtractParams     bool     // Whether to extract query parameters
+	DecodeParams      bool     // Whether percent-encoded parameter keys and values are decoded
+	ExtractURLs       bool     // Whether to extract absolute HTTP(S) URLs
+	ExtractHandles    bool     // Whether to extract social media and developer platform handles
+	ExtractCrypto     bool     // Whether to extract cryptocurrency addresses
+	ExtractCloud      bool     // Whether to extract keys from embedded Firebase, Sentry and analytics configs
+	ExtractSecrets    bool     // Whether to extract credentials from KEY=VALUE style config files
+	ExtractUsernames  bool     // Whether to derive usernames from email local parts, account URL paths and author metadata
+	ExtractAvatars    bool     // Whether to extract the email hashes of Gravatar style avatars
+	ExtractBuckets    bool     // Whether to extract S3 bucket names
+	ExtractJSONP      bool     // Whether to report URLs with function names in callback parameters as JSONP endpoints
+	ExtractErrors     bool     // Whether to report framework stack traces and verbose error pages
+	ExtractComments   bool     // Whether to report HTML comments and JavaScript block comments
+	CommentKeywords   []string // Limits reported comments to those containing one of these words, ignoring case
+	ExtractLocales    bool     // Whether to report locale paths and i18n translation files of URLs
+	ExtractTimes      bool     // Whether to decode timestamps embedded in UUIDs, ULIDs, snowflakes and epoch values
+	EntropyMin        float64  // Minimum Shannon entropy of reported tokens (0 disables)
+	ParseURLs         bool     // Whether lines holding a single URL are parsed with net/url instead of the domain, IP, parameter and URL regexes
+	Structured        bool     // Whether YAML and JSON documents are walked value by value, recording the path of each finding
+	MaxLineLength     int      // Lines longer than this many bytes are matched in overlapping windows (0 uses DefaultMaxLineLength)
+	Workers           int      // Number of chunks of input matched in parallel (0 uses 4)
+	ChunkSize         int      // Bytes of whole lines in each chunk handed to a worker (0 uses 1MB)
+}
+
+// Types returns the finding types produced by the enabled extractors, in output order
+func (c Config) Types() []finding.Type {
+	enabled := map[finding.Type]bool{
+		finding.TypeUUID:         c.UUIDAll || c.UUIDVersion > 0,
+		finding.TypeEmail:        c.ExtractEmails,
+		finding.TypeDomain:       c.ExtractDomains,
+		finding.TypeIP:           c.ExtractIPs,
+		finding.TypeParam:        c.ExtractParams,
+		finding.TypeURL:          c.ExtractURLs,
+		finding.TypeToken:        c.EntropyMin > 0,
+		finding.TypeHandle:       c.ExtractHandles,
+		finding.TypeCrypto:       c.ExtractCrypto,
+		finding.TypeCloudConfig:  c.ExtractCloud,
+		finding.TypeConfigSecret: c.ExtractSecrets,
+		finding.TypeTimestamp:    c.ExtractTimes,
+		finding.TypeUsername:     c.ExtractUsernames,
+		finding.TypeAvatarHash:   c.ExtractAvatars,
+		finding.TypeBucket:       c.ExtractBuckets,
+		finding.TypeJSONP:        c.ExtractJSONP,
+		finding.TypeVerboseError: c.ExtractErrors,
+		finding.TypeComment:      c.ExtractComments,
+		finding.TypeLocale:       c.ExtractLocales,
+	}
+	var types []finding.Type
+	for _, t := range finding.Types {
+		if enabled[t] {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// Only returns a copy of the configuration with every extractor disabled whose finding
+// type is not allowed
+func (c Config) Only(allowed func(finding.Type) bool) Config {
+	if !allowed(finding.TypeUUID) {
+		c.UUIDVersion, c.UUIDAll = 0, false
+	}
+	c.ExtractEmails = c.ExtractEmails && allowed(finding.TypeEmail)
+	c.ExtractDomains = c.ExtractDomains && allowed(finding.TypeDomain)
+	c.ExtractIPs = c.ExtractIPs && allowed(finding.TypeIP)
+	c.ExtractParams = c.ExtractParams && allowed(finding.TypeParam)
+	c.ExtractURLs = c.ExtractURLs && allowed(finding.TypeURL)
+	c.ExtractHandles = c.ExtractHandles && allowed(finding.TypeHandle)
+	c.ExtractCrypto = c.ExtractCrypto && allowed(finding.TypeCrypto)
+	c.ExtractCloud = c.ExtractCloud && allowed(finding.TypeCloudConfig)
+	c.ExtractSecrets = c.ExtractSecrets && allowed(finding.TypeConfigSecret)
+	c.ExtractTimes = c.ExtractTimes && allowed(finding.TypeTimestamp)
+	c.ExtractUsernames = c.ExtractUsernames && allowed(finding.TypeUsername)
+	c.ExtractAvatars = c.ExtractAvatars && allowed(finding.TypeAvatarHash)
+	c.ExtractBuckets = c.ExtractBuckets && allowed(finding.TypeBucket)
+	c.ExtractJSONP = c.ExtractJSONP && allowed(finding.TypeJSONP)
+	c.ExtractErrors = c.ExtractErrors && allowed(finding.TypeVerboseError)
+	c.ExtractComments = c.ExtractComments && allowed(finding.TypeComment)
+	c.ExtractLocales = c.ExtractLocales && allowed(finding.TypeLocale)
+	if !allowed(finding.TypeToken) {
+		c.EntropyMin = 0
+	}
+	return c
 }
 
 const (
 	// maxFileSize defines the maximum allowed file size (100MB) to prevent memory exhaustion
 	maxFileSize = 100 * 1024 * 1024
-	// chunkSize defines the size of each processing chunk (1MB) for optimal performance
+	// chunkSize defines the default size of each processing chunk (1MB) for optimal performance
 	chunkSize = 1 * 1024 * 1024
-	// maxGoroutines defines the maximum number of concurrent workers
+	// maxGoroutines defines the default number of concurrent workers
 	maxGoroutines = 4
 )
 
 type chunk struct {
 	data string
+	line int // line number of the first line in data
 	err  error
 }
 
 type extractor struct {
-	config Config
+	config   Config
+	matchers []gatedMatcher
+	// urlMatchers run on URL lines when ParseURLs is set, alongside matchParsedURL
+	urlMatchers []gatedMatcher
 }
 
 // New creates a new Extractor with the given configuration.
 // It validates the configuration and returns an error if:
 // - UUID version is not between 0 and 5 (0 disables UUID extraction)
+// - Entropy threshold is negative (0 disables token extraction)
+// - Maximum line length is below MinMaxLineLength (0 uses DefaultMaxLineLength)
 // Returns an initialized Extractor and nil error if configuration is valid.
 func New(config Config) (Extractor, error) {
 	if config.UUIDVersion < 0 || config.UUIDVersion > 5 {
 		return nil, &ExtractorError{Op: "New", Err: fmt.Errorf("invalid UUID version: must be between 0 and 5")}
 	}
-	return &extractor{
-		config: config,
-	}, nil
+	if config.EntropyMin < 0 {
+		return nil, &ExtractorError{Op: "New", Err: fmt.Errorf("invalid entropy threshold: must not be negative")}
+	}
+	if config.MaxLineLength != 0 && config.MaxLineLength < MinMaxLineLength {
+		return nil, &ExtractorError{Op: "New", Err: fmt.Errorf("invalid maximum line length: must be at least %d bytes", MinMaxLineLength)}
+	}
+	e := &extractor{
+		config:   config,
+		matchers: newMatchers(config),
+	}
+	if config.ParseURLs {
+		textOnly := config
+		textOnly.ExtractDomains, textOnly.ExtractIPs, textOnly.ExtractParams, textOnly.ExtractURLs = false, false, false, false
+		e.urlMatchers = newMatchers(textOnly)
+	}
+	return e, nil
 }
 
 func (e *extractor) newResults() Results {
 	return Results{}
 }
 
-func (e *extractor) processChunk(ctx context.Context, data string) Results {
+func (e *extractor) processChunk(ctx context.Context, c chunk) *finding.Set {
+	results := &finding.Set{}
+
 	select {
 	case <-ctx.Done():
-		return Results{}
+		return results
 	default:
 	}
 
-	results := Results{}
-	scanner := bufio.NewScanner(strings.NewReader(data))
+	maxLine := e.config.MaxLineLength
+	if maxLine == 0 {
+		maxLine = DefaultMaxLineLength
+	}
+	eachLine(c.data, func(offset int, line string) {
+		emit := func(f finding.Finding) {
+			f.Line = c.line + offset
+			results.Add(f)
+		}
+		eachWindow(line, maxLine, func(window string, seen int) {
+			if seen == 0 {
+				e.matchLine(window, emit)
+				return
+			}
+			// Matches lying wholly in the overlap with the previous window were reported
+			// with it; leave them out so that counted findings such as usernames are
+			// counted once per occurrence
+			reported := make(map[string]int)
+			e.matchLine(window[:seen], func(f finding.Finding) {
+				reported[f.Key()]++
+			})
+			e.matchLine(window, func(f finding.Finding) {
+				if reported[f.Key()] > 0 {
+					reported[f.Key()]--
+					return
+				}
+				emit(f)
+			})
+		})
+	})
 
-	for scanner.Scan() {
-		line := scanner.Text()
+	return results
+}
 
-		if e.config.UUIDVersion > 0 {
-			if regex, ok := patterns.UUIDRegexMap[e.config.UUIDVersion]; ok {
-				matches := regex.FindAllString(line, -1)
-				if len(matches) > 0 {
-					if results.UUIDs == nil {
-						results.UUIDs = make(map[string]bool)
-					}
-					for _, uuid := range matches {
-						results.UUIDs[uuid] = true
-					}
-				}
-			}
+// matchLine runs the matchers on a line, or a window of an oversized line
+func (e *extractor) matchLine(line string, emit func(finding.Finding)) {
+	matchers := e.matchers
+	if e.config.ParseURLs {
+		if u, ok := parseURLLine(line); ok {
+			matchParsedURL(e.config, line, u, emit)
+			matchers = e.urlMatchers
 		}
+	}
+	runMatchers(matchers, line, emit)
+}
 
-		if e.config.ExtractEmails {
-			matches := patterns.EmailRegex.FindAllString(line, -1)
-			if len(matches) > 0 {
-				if results.Emails == nil {
-					results.Emails = make(map[string]bool)
-				}
-				for _, email := range matches {
-					results.Emails[email] = true
-				}
-			}
+// readChunks splits the input into chunks of whole lines of roughly size bytes
+// so that no line is split between workers and line numbers can be tracked.
+func readChunks(ctx context.Context, reader io.Reader, size int, chunks chan<- chunk) {
+	defer close(chunks)
+	br := bufio.NewReaderSize(reader, size)
+	var buf strings.Builder
+	line, start := 1, 1
+
+	flush := func() {
+		if buf.Len() > 0 {
+			chunks <- chunk{data: buf.String(), line: start}
+			buf.Reset()
 		}
+		start = line
+	}
 
-		if e.config.ExtractDomains {
-			matches := patterns.DomainRegex.FindAllStringSubmatch(line, -1)
-			for _, match := range matches {
-				if len(match) > 1 && !strings.HasPrefix(match[1], ".") && !strings.HasSuffix(match[1], ".") {
-					if results.Domains == nil {
-						results.Domains = make(map[string]bool)
-					}
-					results.Domains[match[1]] = true
-				}
-			}
+	for {
+		select {
+		case <-ctx.Done():
+			chunks <- chunk{err: ctx.Err()} // Send context error through chunks
+			return
+		default:
 		}
 
-		if e.config.ExtractIPs {
-			for _, ip := range patterns.IPRegex.FindAllString(line, -1) {
-				if net.ParseIP(ip) != nil {
-					if results.IPs == nil {
-						results.IPs = make(map[string]bool)
-					}
-					results.IPs[ip] = true
-				}
-			}
+		text, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			chunks <- chunk{err: err}
+			return
 		}
-
-		if e.config.ExtractParams {
-			matches := patterns.QueryParamRegex.FindAllStringSubmatch(line, -1)
-			for _, match := range matches {
-				if len(match) > 2 {
-					if results.Params == nil {
-						results.Params = make(map[string]bool)
-					}
-					results.Params[match[1]+"="+match[2]] = true
-				}
+		if len(text) > 0 {
+			buf.WriteString(text)
+			if strings.HasSuffix(text, "\n") {
+				line++
+			}
+			if buf.Len() >= size {
+				flush()
 			}
 		}
+		if err == io.EOF {
+			flush()
+			return
+		}
 	}
-
-	return results
 }
 
 func (e *extractor) Extract(ctx context.Context, reader io.Reader) (Results, error) {
@@ -197,14 +338,47 @@ func (e *extractor) Extract(ctx context.Context, reader io.Reader) (Results, err
 		}
 	}
 
-	chunks := make(chan chunk, maxGoroutines)
-	results := make(chan Results, maxGoroutines)
+	// Structured documents and comments, which span lines, need the whole input
+	final := &finding.Set{}
+	if e.config.Structured || e.config.ExtractComments {
+		data, err := io.ReadAll(io.LimitReader(reader, maxFileSize+1))
+		if err != nil {
+			return e.newResults(), &ExtractorError{Op: "Extract", Err: err}
+		}
+		if len(data) > maxFileSize {
+			return e.newResults(), &ExtractorError{Op: "Extract", Err: fmt.Errorf("file too large: maximum size is 100MB")}
+		}
+		if e.config.ExtractComments {
+			for _, f := range extractComments(string(data), e.config.CommentKeywords) {
+				final.Add(f)
+			}
+		}
+		if e.config.Structured {
+			if values, err := structured.Walk(data); err == nil {
+				final.Merge(e.extractStructured(values))
+				return Results{Findings: final.Findings()}, nil
+			}
+		}
+		// Anything but a YAML or JSON document is read line by line
+		reader = bytes.NewReader(data)
+	}
+
+	workers := e.config.Workers
+	if workers <= 0 {
+		workers = maxGoroutines
+	}
+	size := e.config.ChunkSize
+	if size <= 0 {
+		size = chunkSize
+	}
+	chunks := make(chan chunk, workers)
+	results := make(chan *finding.Set, workers)
 	errors := make(chan error, 1)
 
 	var wg sync.WaitGroup
 
 	// Start worker goroutines
-	for i := 0; i < maxGoroutines; i++ {
+	for i := 0; i < workers; i++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
@@ -224,36 +398,14 @@ func (e *extractor) Extract(ctx context.Context, reader io.Reader) (Results, err
 						}
 						return
 					}
-					results <- e.processChunk(ctx, c.data)
+					results <- e.processChunk(ctx, c)
 				}
 			}
 		}()
 	}
 
 	// Read chunks
-	go func() {
-		defer close(chunks)
-		buffer := make([]byte, chunkSize)
-		for {
-			select {
-			case <-ctx.Done():
-				chunks <- chunk{err: ctx.Err()} // Send context error through chunks
-				return
-			default:
-				n, err := reader.Read(buffer)
-				if err != nil && err != io.EOF {
-					chunks <- chunk{err: err}
-					return
-				}
-				if n > 0 {
-					chunks <- chunk{data: string(buffer[:n])}
-				}
-				if err == io.EOF {
-					return
-				}
-			}
-		}
-	}()
+	go readChunks(ctx, reader, size, chunks)
 
 	// Close results after workers finish
 	go func() {
@@ -262,8 +414,6 @@ func (e *extractor) Extract(ctx context.Context, reader io.Reader) (Results, err
 		close(errors)
 	}()
 
-	finalResults := e.newResults()
-
 	// Process results and errors
 	for {
 		select {
@@ -273,49 +423,13 @@ func (e *extractor) Extract(ctx context.Context, reader io.Reader) (Results, err
 			}
 		case r, ok := <-results:
 			if !ok {
-				return finalResults, nil
-			}
-			// Merge results
-			if r.UUIDs != nil && len(r.UUIDs) > 0 {
-				if finalResults.UUIDs == nil {
-					finalResults.UUIDs = make(map[string]bool)
-				}
-				for k, v := range r.UUIDs {
-					finalResults.UUIDs[k] = v
-				}
-			}
-			if r.Emails != nil && len(r.Emails) > 0 {
-				if finalResults.Emails == nil {
-					finalResults.Emails = make(map[string]bool)
-				}
-				for k, v := range r.Emails {
-					finalResults.Emails[k] = v
-				}
-			}
-			if r.Domains != nil && len(r.Domains) > 0 {
-				if finalResults.Domains == nil {
-					finalResults.Domains = make(map[string]bool)
-				}
-				for k, v := range r.Domains {
-					finalResults.Domains[k] = v
-				}
-			}
-			if r.IPs != nil && len(r.IPs) > 0 {
-				if finalResults.IPs == nil {
-					finalResults.IPs = make(map[string]bool)
-				}
-				for k, v := range r.IPs {
-					finalResults.IPs[k] = v
-				}
-			}
-			if r.Params != nil && len(r.Params) > 0 {
-				if finalResults.Params == nil {
-					finalResults.Params = make(map[string]bool)
-				}
-				for k, v := range r.Params {
-					finalResults.Params[k] = v
+				// A worker may have failed just before the results channel closed
+				if err := <-errors; err != nil {
+					return e.newResults(), &ExtractorError{Op: "Extract", Err: err}
 				}
+				return Results{Findings: final.Findings()}, nil
 			}
+			final.Merge(r)
 		case <-ctx.Done():
 			return e.newResults(), &ExtractorError{Op: "Extract", Err: ctx.Err()}
 		}
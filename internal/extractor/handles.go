@@ -0,0 +1,43 @@
+package extractor
+
+import (
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/patterns"
+)
+
+// reservedHandles lists first path segments that are site pages rather than accounts
+var reservedHandles = map[string]map[string]bool{
+	"github": setOf("about", "apps", "collections", "contact", "enterprise", "explore", "features", "join",
+		"login", "marketplace", "new", "notifications", "orgs", "organizations", "pricing", "pulls", "issues",
+		"repos", "search", "security", "settings", "site", "sponsors", "team", "topics", "trending"),
+	"gitlab": setOf("-", "admin", "api", "dashboard", "explore", "groups", "help", "projects", "search", "users"),
+	"twitter": setOf("compose", "explore", "hashtag", "home", "i", "intent", "login", "messages",
+		"notifications", "privacy", "search", "settings", "share", "signup", "tos"),
+}
+
+func setOf(items ...string) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		set[item] = true
+	}
+	return set
+}
+
+// matchHandles reports accounts, organisations and repositories on social media and developer
+// platforms. Values are prefixed with the platform ("github:org/repo") so output groups by platform.
+func matchHandles(line string, emit func(finding.Finding)) {
+	for platform, regex := range patterns.HandleRegexMap {
+		for _, match := range regex.FindAllStringSubmatch(line, -1) {
+			handle := strings.TrimSuffix(strings.TrimRight(match[1], "."), ".git")
+			owner, _, _ := strings.Cut(handle, "/")
+			if handle == "" || reservedHandles[platform][strings.ToLower(owner)] {
+				continue
+			}
+			f := finding.Finding{Type: finding.TypeHandle, Value: platform + ":" + handle, Confidence: finding.ConfidenceHigh}
+			f.SetMeta("platform", platform)
+			emit(f)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package extractor
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/patterns"
+)
+
+// jsonpParams maps the parameter names JSONP endpoints read their callback from to the
+// confidence of a match; short names are also used for unrelated values
+var jsonpParams = map[string]finding.Confidence{
+	"callback":       finding.ConfidenceHigh,
+	"jsonp":          finding.ConfidenceHigh,
+	"jsonpcallback":  finding.ConfidenceHigh,
+	"jsonp_callback": finding.ConfidenceHigh,
+	"jsoncallback":   finding.ConfidenceHigh,
+	"json_callback":  finding.ConfidenceHigh,
+	"cb":             finding.ConfidenceMedium,
+	"call":           finding.ConfidenceLow,
+}
+
+// matchJSONP reports URLs passing a function name such as "handleData" or "jQuery.cb" in a
+// callback parameter as potential JSONP endpoints, recording the parameter in "param" and
+// its value in "callback"
+func matchJSONP(line string, emit func(finding.Finding)) {
+	for _, raw := range patterns.URLRegex.FindAllString(line, -1) {
+		raw = strings.TrimRight(raw, ".,;:!?'")
+		u, err := url.Parse(raw)
+		if err != nil || u.RawQuery == "" {
+			continue
+		}
+		query := u.Query()
+		names := make([]string, 0, len(query))
+		for name := range query {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var best *finding.Finding
+		for _, name := range names {
+			confidence, ok := jsonpParams[strings.ToLower(name)]
+			callback := query.Get(name)
+			if !ok || !patterns.JSONPCallbackRegex.MatchString(callback) {
+				continue
+			}
+			if best == nil || !best.Confidence.AtLeast(confidence) {
+				best = &finding.Finding{Type: finding.TypeJSONP, Value: raw, Confidence: confidence}
+				best.SetMeta("param", name)
+				best.SetMeta("callback", callback)
+			}
+		}
+		if best != nil {
+			emit(*best)
+		}
+	}
+}
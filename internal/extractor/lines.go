@@ -0,0 +1,178 @@
+package extractor
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+)
+
+const (
+	// DefaultMaxLineLength is the longest line matched as a whole (1MB) when
+	// Config.MaxLineLength is not set
+	DefaultMaxLineLength = 1024 * 1024
+	// MinMaxLineLength is the smallest Config.MaxLineLength accepted
+	MinMaxLineLength = 1024
+	// maxWindowOverlap is how much consecutive windows of an oversized line overlap, so that
+	// a match up to this long crossing the end of one window is found whole in the next
+	maxWindowOverlap = 4 * 1024
+)
+
+// windowBreaks are the characters oversized lines are split at, since values do not span
+// them; splitting elsewhere could report the two halves of a value
+const windowBreaks = " \t\"'`<>"
+
+// eachLine calls fn with every line of data and its offset from the first line, without
+// the line break. Unlike bufio.Scanner it has no limit on the length of a line.
+func eachLine(data string, fn func(offset int, line string)) {
+	for offset := 0; data != ""; offset++ {
+		var line string
+		line, data, _ = strings.Cut(data, "\n")
+		fn(offset, strings.TrimSuffix(line, "\r"))
+	}
+}
+
+// eachWindow calls fn with line or, when it is longer than max bytes, with overlapping
+// windows of at most max bytes covering it, such as the single line of minified JavaScript.
+// Windows start and end at windowBreaks characters, so values are not cut in two, and overlap
+// by up to maxWindowOverlap bytes so that matches spanning breaks, such as error messages,
+// are found whole in one of them; seen is the length of the start of the window that the
+// previous window ended with. A run of more than max bytes without a break cannot be matched
+// whole and is skipped.
+func eachWindow(line string, max int, fn func(window string, seen int)) {
+	seen := 0
+	overlap := min(maxWindowOverlap, max/4)
+	for len(line) > max {
+		end := strings.LastIndexAny(line[:max], windowBreaks)
+		if end < 0 {
+			next := strings.IndexAny(line[max:], windowBreaks)
+			if next < 0 {
+				return
+			}
+			line = line[max+next+1:]
+			seen = 0
+			continue
+		}
+		if end > 0 {
+			fn(line[:end], seen)
+		}
+
+		start := end + 1
+		if from := end - overlap; from > 0 {
+			if i := strings.IndexAny(line[from:end], windowBreaks); i >= 0 {
+				start = from + i + 1
+			}
+		}
+		seen = 0
+		if start < end {
+			seen = end - start
+		}
+		line = line[start:]
+	}
+	fn(line, seen)
+}
+
+// Chunks reads r a chunk of whole lines of about size bytes at a time, calling fn with each
+// chunk and the number of its first line, so that an input can be matched without holding
+// it whole. A line longer than size is passed in pieces split like the windows of
+// eachWindow, each numbered by the line; pieces overlap, so a match in the overlap is
+// passed twice. fn may keep chunk.
+func Chunks(r io.Reader, size int, fn func(chunk []byte, line int) error) error {
+	br := bufio.NewReaderSize(r, size)
+	overlap := min(maxWindowOverlap, size/4)
+	var (
+		chunk       []byte
+		start, line = 1, 1
+		// long holds the start of an oversized line not passed yet
+		long   []byte
+		inLong bool
+		// skipping is set while dropping a run of more than size bytes without a break
+		skipping bool
+	)
+	flush := func() error {
+		if len(chunk) > 0 {
+			if err := fn(chunk, start); err != nil {
+				return err
+			}
+			chunk = nil
+		}
+		start = line
+		return nil
+	}
+	// cut passes the pieces of long while it is longer than size
+	cut := func() error {
+		for len(long) > size {
+			end := bytes.LastIndexAny(long[:size], windowBreaks)
+			if end < 0 {
+				next := bytes.IndexAny(long[size:], windowBreaks)
+				if next < 0 {
+					long, skipping = long[:0], true
+					return nil
+				}
+				long = append(long[:0], long[size+next+1:]...)
+				continue
+			}
+			if end > 0 {
+				if err := fn(bytes.Clone(long[:end]), line); err != nil {
+					return err
+				}
+			}
+			from := end + 1
+			if o := end - overlap; o > 0 {
+				if i := bytes.IndexAny(long[o:end], windowBreaks); i >= 0 {
+					from = o + i + 1
+				}
+			}
+			long = append(long[:0], long[from:]...)
+		}
+		return nil
+	}
+
+	for {
+		part, err := br.ReadSlice('\n')
+		if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+			return err
+		}
+		ended := err != bufio.ErrBufferFull
+		newline := len(part) > 0 && part[len(part)-1] == '\n'
+		if skipping {
+			if i := bytes.IndexAny(part, windowBreaks); i >= 0 {
+				part, skipping = part[i+1:], false
+			} else if ended {
+				part, skipping = part[len(part):], false
+			} else {
+				continue
+			}
+		}
+		switch {
+		case inLong || !ended:
+			if !inLong {
+				// Pass the lines before the oversized one on their own
+				if err := flush(); err != nil {
+					return err
+				}
+				inLong = true
+			}
+			long = append(long, part...)
+			if err := cut(); err != nil {
+				return err
+			}
+			if ended {
+				chunk, long, inLong = long, nil, false
+			}
+		default:
+			chunk = append(chunk, part...)
+		}
+		if newline {
+			line++
+		}
+		if len(chunk) >= size || err == io.EOF {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package extractor
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/locale"
+	"github.com/PeteJStewart/urlsluice/internal/patterns"
+)
+
+// matchLocales reports the locale paths of URLs, such as "https://target.com/fr-fr/", and
+// their i18n translation files, such as "https://target.com/locales/fr/common.json", with
+// the kind ("path" or "resource") and the locale tag, if any, in the metadata. Query strings
+// are dropped, since bundles are usually requested with a cache-busting version.
+func matchLocales(line string, emit func(finding.Finding)) {
+	for _, raw := range patterns.URLRegex.FindAllString(line, -1) {
+		u, err := url.Parse(strings.TrimRight(raw, ".,;:!?'"))
+		if err != nil || u.Host == "" {
+			continue
+		}
+		base := u.Scheme + "://" + u.Host
+		if tag, ok := locale.Resource(u.Path); ok {
+			f := finding.Finding{Type: finding.TypeLocale, Value: base + u.EscapedPath(), Confidence: finding.ConfidenceHigh}
+			f.SetMeta("kind", "resource")
+			if tag != "" {
+				f.SetMeta("locale", tag)
+			}
+			emit(f)
+			continue
+		}
+		i, tag := locale.PathLocale(u.Path)
+		if i < 0 {
+			continue
+		}
+		segments := strings.Split(strings.Trim(u.EscapedPath(), "/"), "/")
+		f := finding.Finding{Type: finding.TypeLocale, Value: base + "/" + strings.Join(segments[:i+1], "/") + "/", Confidence: finding.ConfidenceHigh}
+		if !strings.Contains(tag, "-") {
+			// Bare language codes such as "it" are also ordinary path names
+			f.Confidence = finding.ConfidenceMedium
+		}
+		f.SetMeta("kind", "path")
+		f.SetMeta("locale", tag)
+		emit(f)
+	}
+}
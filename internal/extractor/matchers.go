@@ -0,0 +1,144 @@
+package extractor
+
+import (
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/entropy"
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/patterns"
+)
+
+// matcher finds one category of patterns in a single line and reports each match through emit.
+// The extractor fills in the line number, so matchers only set type, value, confidence and metadata.
+type matcher func(line string, emit func(finding.Finding))
+
+// newMatchers returns the matchers enabled by config in output order, gated by the prefilter
+// literals their matches contain
+func newMatchers(config Config) []gatedMatcher {
+	var matchers []gatedMatcher
+	if config.UUIDAll {
+		matchers = append(matchers, gatedMatcher{ungated, matchAllUUIDs})
+	} else if config.UUIDVersion > 0 {
+		if regex, ok := patterns.UUIDRegexMap[config.UUIDVersion]; ok {
+			matchers = append(matchers, gatedMatcher{ungated, func(line string, emit func(finding.Finding)) {
+				for _, uuid := range regex.FindAllString(line, -1) {
+					emit(finding.Finding{Type: finding.TypeUUID, Value: uuid, Confidence: finding.ConfidenceHigh})
+				}
+			}})
+		}
+	}
+	if config.ExtractEmails {
+		matchers = append(matchers, gatedMatcher{literalEmail, matchEmails})
+		if config.DeobfuscateEmails {
+			matchers = append(matchers, gatedMatcher{ungated, matchObfuscatedEmails})
+		}
+	}
+	if config.ExtractDomains {
+		matchers = append(matchers, gatedMatcher{literalURL, matchDomains})
+	}
+	if config.ExtractIPs {
+		matchers = append(matchers, gatedMatcher{ungated, matchIPs})
+	}
+	if config.ExtractParams {
+		decode := config.DecodeParams
+		matchers = append(matchers, gatedMatcher{literalQuery, func(line string, emit func(finding.Finding)) {
+			matchParams(line, decode, emit)
+		}})
+	}
+	if config.ExtractURLs {
+		matchers = append(matchers, gatedMatcher{literalURL, matchURLs})
+	}
+	if config.EntropyMin > 0 {
+		min := config.EntropyMin
+		matchers = append(matchers, gatedMatcher{ungated, func(line string, emit func(finding.Finding)) {
+			matchTokens(line, min, emit)
+		}})
+	}
+	if config.ExtractHandles {
+		matchers = append(matchers, gatedMatcher{literalHandle, matchHandles})
+	}
+	if config.ExtractCrypto {
+		matchers = append(matchers, gatedMatcher{ungated, matchCrypto})
+	}
+	if config.ExtractCloud {
+		matchers = append(matchers, gatedMatcher{literalCloudKey, matchCloudConfigs})
+	}
+	if config.ExtractSecrets {
+		matchers = append(matchers, gatedMatcher{ungated, matchConfigSecrets})
+	}
+	if config.ExtractTimes {
+		matchers = append(matchers, gatedMatcher{ungated, matchTimestamps})
+	}
+	if config.ExtractUsernames {
+		matchers = append(matchers, gatedMatcher{ungated, matchUsernames})
+	}
+	if config.ExtractAvatars {
+		matchers = append(matchers, gatedMatcher{literalAvatar, matchAvatarHashes})
+	}
+	if config.ExtractBuckets {
+		matchers = append(matchers, gatedMatcher{literalBucket, matchBuckets})
+	}
+	if config.ExtractJSONP {
+		matchers = append(matchers, gatedMatcher{literalURL, matchJSONP})
+	}
+	if config.ExtractErrors {
+		matchers = append(matchers, gatedMatcher{ungated, matchVerboseErrors})
+	}
+	if config.ExtractLocales {
+		matchers = append(matchers, gatedMatcher{literalURL, matchLocales})
+	}
+	return matchers
+}
+
+// matchAllUUIDs reports UUIDs of any version with the version recorded in metadata
+func matchAllUUIDs(line string, emit func(finding.Finding)) {
+	for _, uuid := range patterns.UUIDAnyRegex.FindAllString(line, -1) {
+		f := finding.Finding{Type: finding.TypeUUID, Value: uuid, Confidence: finding.ConfidenceHigh}
+		f.SetMeta("version", uuid[14:15])
+		emit(f)
+	}
+}
+
+func matchEmails(line string, emit func(finding.Finding)) {
+	for _, email := range patterns.EmailRegex.FindAllString(line, -1) {
+		emit(finding.Finding{Type: finding.TypeEmail, Value: email, Confidence: emailConfidence(email)})
+	}
+}
+
+func matchDomains(line string, emit func(finding.Finding)) {
+	for _, match := range patterns.DomainRegex.FindAllStringSubmatch(line, -1) {
+		if len(match) > 1 && !strings.HasPrefix(match[1], ".") && !strings.HasSuffix(match[1], ".") {
+			emit(finding.Finding{Type: finding.TypeDomain, Value: match[1], Confidence: domainConfidence(match[1])})
+		}
+	}
+}
+
+func matchIPs(line string, emit func(finding.Finding)) {
+	for _, ip := range patterns.IPRegex.FindAllString(line, -1) {
+		if net.ParseIP(ip) != nil {
+			emit(finding.Finding{Type: finding.TypeIP, Value: ip, Confidence: finding.ConfidenceHigh})
+		}
+	}
+}
+
+func matchURLs(line string, emit func(finding.Finding)) {
+	for _, u := range patterns.URLRegex.FindAllString(line, -1) {
+		u = strings.TrimRight(u, ".,;:!?'")
+		emit(finding.Finding{Type: finding.TypeURL, Value: u, Confidence: urlConfidence(u)})
+	}
+}
+
+// matchTokens reports random-looking strings whose Shannon entropy is at least min
+func matchTokens(line string, min float64, emit func(finding.Finding)) {
+	for _, token := range patterns.TokenRegex.FindAllString(line, -1) {
+		h := entropy.Shannon(token)
+		if h < min {
+			continue
+		}
+		f := finding.Finding{Type: finding.TypeToken, Value: token, Confidence: tokenConfidence(token)}
+		f.SetMeta("entropy", strconv.FormatFloat(h, 'f', 2, 64))
+		emit(f)
+	}
+}
@@ -0,0 +1,136 @@
+package extractor
+
+import (
+	"net/url"
+	"strings"
+	"unicode"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/patterns"
+)
+
+// queryParam is a "key=value" pair from a query string or URL fragment
+type queryParam struct {
+	Key   string
+	Value string
+	// Fragment is set for parameters after '#', e.g. "#/route?x=1", which browsers do not send to the server
+	Fragment bool
+	// Array is set for PHP and Rails style keys such as "ids[]" or "user[name]"
+	Array bool
+	// Repeated is set when a non-array key occurs more than once in the same query
+	Repeated bool
+}
+
+// queryParams splits the query strings and fragments in line into parameters. Pairs are
+// separated by '&' or ';', values keep any '=' they contain, and each '?' or '#' starts a
+// new section so single-page app routes such as "#/route?x=1" are handled.
+func queryParams(line string) []queryParam {
+	var params []queryParam
+	for {
+		loc := patterns.QueryStringRegex.FindStringIndex(line)
+		if loc == nil {
+			return params
+		}
+		n := queryLength(line[loc[0]:loc[1]])
+		params = parseQuery(line[loc[0]:loc[0]+n], params)
+		line = line[loc[0]+n:]
+	}
+}
+
+// queryLength returns how much of run, which starts with a separator, belongs to the query.
+// Keys may contain spaces, but whitespace in a value or a key without '=' ends the query.
+func queryLength(run string) int {
+	start := 0
+	for i := 1; i <= len(run); i++ {
+		if i < len(run) && !strings.ContainsRune(querySeparators, rune(run[i])) {
+			continue
+		}
+		segment := run[start+1 : i]
+		if ws := strings.IndexFunc(segment, unicode.IsSpace); ws >= 0 {
+			if key, _, ok := strings.Cut(segment, "="); !ok || ws > len(key) {
+				return start + 1 + ws
+			}
+		}
+		start = i
+	}
+	return len(run)
+}
+
+// querySeparators delimit parameters and sections within a query string
+const querySeparators = "?#&;"
+
+// parseQuery appends the parameters of query, which starts with a separator, to params
+func parseQuery(query string, params []queryParam) []queryParam {
+	run := strings.TrimRight(query, ".,;:!?)")
+	fragment := false
+	section := len(params)
+	start := 0
+	for i := 1; i <= len(run); i++ {
+		if i < len(run) && !strings.ContainsRune(querySeparators, rune(run[i])) {
+			continue
+		}
+		if run[start] == '?' || run[start] == '#' {
+			markRepeated(params[section:])
+			section = len(params)
+			fragment = fragment || run[start] == '#'
+		}
+		if key, value, ok := strings.Cut(run[start+1:i], "="); ok && key != "" {
+			params = append(params, queryParam{Key: key, Value: value, Fragment: fragment, Array: isArrayKey(key)})
+		}
+		start = i
+	}
+	markRepeated(params[section:])
+	return params
+}
+
+// isArrayKey reports whether key uses bracket notation, literally or percent-encoded
+func isArrayKey(key string) bool {
+	return strings.HasSuffix(key, "]") && strings.Contains(key, "[") ||
+		strings.HasSuffix(strings.ToUpper(key), "%5D") && strings.Contains(strings.ToUpper(key), "%5B")
+}
+
+// markRepeated flags the parameters of one section whose key occurs more than once
+func markRepeated(params []queryParam) {
+	counts := make(map[string]int, len(params))
+	for _, p := range params {
+		counts[p.Key]++
+	}
+	for i := range params {
+		params[i].Repeated = counts[params[i].Key] > 1 && !params[i].Array
+	}
+}
+
+func matchParams(line string, decode bool, emit func(finding.Finding)) {
+	for _, p := range queryParams(line) {
+		emitParam(p, decode, emit)
+	}
+}
+
+// emitParam reports p as a "key=value" finding, percent-decoding the key and value first when
+// decode is set
+func emitParam(p queryParam, decode bool, emit func(finding.Finding)) {
+	key, value := p.Key, p.Value
+	if decode {
+		key, value = unescapeQuery(key), unescapeQuery(value)
+	}
+	f := finding.Finding{Type: finding.TypeParam, Value: key + "=" + value, Confidence: paramConfidence(key)}
+	if p.Fragment {
+		f.SetMeta("location", "fragment")
+	}
+	if p.Array {
+		f.SetMeta("array", "true")
+	}
+	if p.Repeated {
+		f.SetMeta("repeated", "true")
+	}
+	emit(f)
+}
+
+// unescapeQuery decodes a query string component, including '+' as a space, and returns s
+// unchanged when it is not validly encoded
+func unescapeQuery(s string) string {
+	if decoded, err := url.QueryUnescape(s); err == nil {
+		return decoded
+	}
+	return s
+}
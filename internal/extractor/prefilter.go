@@ -0,0 +1,64 @@
+package extractor
+
+import (
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/multimatch"
+)
+
+// Literals of the prefilter. Every match of a gated matcher contains its literal, so lines
+// without it are skipped without running the matcher's expressions.
+const (
+	literalURL = iota
+	literalEmail
+	literalQuery
+	literalCloudKey
+	literalBucket
+	literalAvatar
+	literalHandle
+	// ungated marks matchers that run on every line
+	ungated = -1
+)
+
+// prefilterPatterns are the literals by index; they are as case sensitive as the
+// expressions of the matchers they gate
+var prefilterPatterns = []string{
+	literalURL:      `https?://`,
+	literalEmail:    `@`,
+	literalQuery:    `[?#&]`,
+	literalCloudKey: `AIza|https?://|analytics\.load|writeKey|amplitude`,
+	literalBucket:   `(?i)amazonaws\.com|s3://|:s3:::|storage\.googleapis\.com|storage\.cloud\.google\.com|gs://|\.blob\.core\.windows\.net`,
+	literalAvatar:   `(?i)avatar|email_?hash|email_?md5`,
+	literalHandle:   `(?i)github\.com|gitlab\.com|twitter\.com|x\.com|linkedin\.com|discord`,
+}
+
+// prefilter finds the literals of a line in one pass where the hyperscan backend is built
+// in, so that matchers only run on the lines that can hold their patterns
+var prefilter = multimatch.MustNew(prefilterPatterns...)
+
+// gatedMatcher is a matcher with the prefilter literal each of its matches contains
+type gatedMatcher struct {
+	// literal indexes prefilterPatterns, or is ungated
+	literal int
+	match   matcher
+}
+
+// runMatchers runs matchers on line, skipping those whose literal the line lacks. The line
+// is only scanned for literals when a gated matcher is enabled.
+func runMatchers(matchers []gatedMatcher, line string, emit func(finding.Finding)) {
+	var found uint64
+	scanned := false
+	for _, m := range matchers {
+		if m.literal != ungated {
+			if !scanned {
+				prefilter.Each(line, func(i int, _ string) {
+					found |= 1 << i
+				})
+				scanned = true
+			}
+			if found&(1<<m.literal) == 0 {
+				continue
+			}
+		}
+		m.match(line, emit)
+	}
+}
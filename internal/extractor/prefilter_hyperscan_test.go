@@ -0,0 +1,17 @@
+//go:build hyperscan && cgo
+
+package extractor
+
+import "testing"
+
+// TestPrefilter_Hyperscan runs the prefilter cases of TestPrefilter through the Hyperscan
+// database, which must compile, since those of the regexp backend run without the tag
+func TestPrefilter_Hyperscan(t *testing.T) {
+	if !prefilter.Accelerated() {
+		t.Fatal("Hyperscan could not compile the prefilter literals")
+	}
+	if !errorSignatureSet.Accelerated() {
+		t.Fatal("Hyperscan could not compile the error signatures")
+	}
+	checkPrefilterCases(t)
+}
@@ -0,0 +1,52 @@
+package extractor
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/patterns"
+)
+
+// placeholderRegex matches values that are templates or examples rather than real secrets
+var placeholderRegex = regexp.MustCompile(`(?i)^(?:changeme|change_me|example|placeholder|password|secret|todo|x{3,}|\*+|<.*>|your[_-].*|\$\{.*\}|\$[A-Z_]+|%\(.*\)s|\{\{.*\}\})$`)
+
+// matchConfigSecrets reports dotenv, ini and YAML assignments whose key names suggest a credential,
+// such as DB_PASSWORD=... or api_token: .... Assignments must start the line so that code and
+// prose mentioning a password are not reported. Values are reported as "KEY=VALUE".
+func matchConfigSecrets(line string, emit func(finding.Finding)) {
+	m := patterns.ConfigAssignmentRegex.FindStringSubmatch(line)
+	if m == nil || !patterns.SensitiveKeyRegex.MatchString(m[1]) {
+		return
+	}
+	value := configValue(m[2])
+	if value == "" {
+		return
+	}
+	confidence := finding.ConfidenceHigh
+	if placeholderRegex.MatchString(value) {
+		confidence = finding.ConfidenceLow
+	}
+	f := finding.Finding{Type: finding.TypeConfigSecret, Value: m[1] + "=" + value, Confidence: confidence}
+	f.SetMeta("key", m[1])
+	emit(f)
+}
+
+// configValue strips quotes, trailing commas and inline comments from an assigned value.
+// YAML block indicators and empty values yield "".
+func configValue(raw string) string {
+	raw = strings.TrimSuffix(strings.TrimSpace(raw), ",")
+	if len(raw) >= 2 && (raw[0] == '"' || raw[0] == '\'') {
+		if end := strings.IndexByte(raw[1:], raw[0]); end >= 0 {
+			return raw[1 : end+1]
+		}
+	}
+	if i := strings.Index(raw, " #"); i >= 0 {
+		raw = strings.TrimSpace(raw[:i])
+	}
+	switch raw {
+	case "|", ">", "~", "null", "None", "''", `""`:
+		return ""
+	}
+	return raw
+}
@@ -0,0 +1,79 @@
+package extractor
+
+import (
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/structured"
+)
+
+// hostKeyRegex matches keys whose values are often bare hostnames, such as DB_HOST, server or registry
+var hostKeyRegex = regexp.MustCompile(`(?i)(host(name)?|server|domain|endpoint|address|addr|registry|url|uri)s?$`)
+
+// imageKeyRegex matches keys holding container image references
+var imageKeyRegex = regexp.MustCompile(`(?i)image$`)
+
+// extractStructured runs the matchers on each value of a structured document, recording the
+// path to the value and the Kubernetes object it belongs to. Values of credential keys are
+// matched as "key: value" assignments so config secrets are found, and hostnames in host
+// and image values are reported even without a URL scheme.
+func (e *extractor) extractStructured(values []structured.Value) *finding.Set {
+	results := &finding.Set{}
+	for _, v := range values {
+		emit := func(f finding.Finding) {
+			f.Line = v.Line
+			f.SetMeta("yaml_path", v.Path)
+			if v.Resource != "" {
+				f.SetMeta("resource", v.Resource)
+			}
+			results.Add(f)
+		}
+
+		text := strings.TrimSpace(v.Text)
+		switch {
+		case imageKeyRegex.MatchString(v.Key):
+			e.matchHost(imageRegistry(text), emit)
+		case hostKeyRegex.MatchString(v.Key):
+			e.matchHost(text, emit)
+		}
+
+		line := v.Text
+		if v.Key != "" {
+			line = v.Key + ": " + text
+		}
+		runMatchers(e.matchers, line, emit)
+	}
+	return results
+}
+
+// matchHost reports host, optionally followed by a port, as a domain or IP
+func (e *extractor) matchHost(host string, emit func(finding.Finding)) {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		if ip.To4() != nil && e.config.ExtractIPs {
+			emit(finding.Finding{Type: finding.TypeIP, Value: host, Confidence: finding.ConfidenceHigh})
+		}
+		return
+	}
+	if e.config.ExtractDomains && strings.Contains(host, ".") && validHostname(host) {
+		emit(finding.Finding{Type: finding.TypeDomain, Value: strings.ToLower(host), Confidence: finding.ConfidenceHigh})
+	}
+}
+
+// imageRegistry returns the registry host of a container image reference such as
+// "registry.corp.example:5000/team/api:1.4", or "" for Docker Hub images such as "nginx:1.25"
+func imageRegistry(ref string) string {
+	i := strings.IndexByte(ref, '/')
+	if i < 0 {
+		return ""
+	}
+	registry := ref[:i]
+	if !strings.ContainsAny(registry, ".:") && registry != "localhost" {
+		return ""
+	}
+	return registry
+}
@@ -0,0 +1,63 @@
+package extractor
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/patterns"
+	"github.com/PeteJStewart/urlsluice/internal/timestamps"
+)
+
+// matchTimestamps reports identifiers with an embedded creation time: time-based UUIDs,
+// ULIDs, snowflake IDs and epoch-like query parameter values. The decoded time is stored
+// in the "time" metadata and the identifier format in "kind".
+func matchTimestamps(line string, emit func(finding.Finding)) {
+	report := func(value, kind string, t time.Time, confidence finding.Confidence) finding.Finding {
+		f := finding.Finding{Type: finding.TypeTimestamp, Value: value, Confidence: confidence}
+		f.SetMeta("kind", kind)
+		f.SetMeta("time", t.Format(time.RFC3339Nano))
+		return f
+	}
+
+	for _, uuid := range patterns.UUIDAnyRegex.FindAllString(line, -1) {
+		if t, kind, ok := timestamps.UUID(uuid); ok {
+			emit(report(uuid, kind, t, finding.ConfidenceHigh))
+		}
+	}
+
+	for _, ulid := range patterns.ULIDRegex.FindAllString(line, -1) {
+		if t, ok := timestamps.ULID(ulid); ok && timestamps.Plausible(t) {
+			emit(report(ulid, timestamps.KindULID, t, finding.ConfidenceMedium))
+		}
+	}
+
+	for _, s := range patterns.SnowflakeRegex.FindAllString(line, -1) {
+		id, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			continue
+		}
+		t := timestamps.Snowflake(id, timestamps.TwitterEpoch)
+		if !timestamps.Plausible(t) {
+			continue
+		}
+		// The same ID decodes to a different time under Discord's epoch; record both
+		// when plausible since the generator cannot be told from the number alone
+		f := report(s, timestamps.KindSnowflake, t, finding.ConfidenceLow)
+		if d := timestamps.Snowflake(id, timestamps.DiscordEpoch); timestamps.Plausible(d) {
+			f.SetMeta("time_discord", d.Format(time.RFC3339Nano))
+		}
+		emit(f)
+	}
+
+	for _, p := range queryParams(line) {
+		if !patterns.EpochRegex.MatchString(p.Value) {
+			continue
+		}
+		if t, kind, ok := timestamps.Epoch(p.Value); ok && timestamps.Plausible(t) {
+			f := report(p.Value, kind, t, finding.ConfidenceMedium)
+			f.SetMeta("param", p.Key)
+			emit(f)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package extractor
+
+import (
+	"net"
+	"net/url"
+	"strings"
+	"unicode"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+// maxURLDepth limits how deeply URLs embedded in parameter values are parsed
+const maxURLDepth = 3
+
+// parseURLLine parses line as a single absolute HTTP(S) URL, ignoring surrounding whitespace.
+// Lines with anything else on them are left to the regex matchers.
+func parseURLLine(line string) (*url.URL, bool) {
+	line = strings.TrimSpace(line)
+	if strings.IndexFunc(line, unicode.IsSpace) >= 0 {
+		return nil, false
+	}
+	u, err := url.Parse(line)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return nil, false
+	}
+	return u, true
+}
+
+// matchParsedURL reports the host, parameters and URL of a parsed URL line instead of the
+// domain, IP, parameter and URL regexes. Hosts are taken after any userinfo and without the
+// port, and parameter values that are themselves URLs, such as redirect targets, are parsed too.
+func matchParsedURL(config Config, line string, u *url.URL, emit func(finding.Finding)) {
+	matchURLParts(config, strings.TrimSpace(line), u, emit, 0)
+}
+
+// matchURLParts reports the parts of u, which was parsed from raw
+func matchURLParts(config Config, raw string, u *url.URL, emit func(finding.Finding), depth int) {
+	host := u.Hostname()
+	ip := net.ParseIP(host)
+	switch {
+	case ip != nil && ip.To4() != nil:
+		if config.ExtractIPs {
+			emit(finding.Finding{Type: finding.TypeIP, Value: host, Confidence: finding.ConfidenceHigh})
+		}
+	case ip == nil:
+		if config.ExtractDomains {
+			emit(finding.Finding{Type: finding.TypeDomain, Value: host, Confidence: domainConfidence(host)})
+		}
+	}
+	if config.ExtractIPs {
+		// IPs in the path or query, e.g. "?target=10.0.0.5"
+		matchIPs(u.RequestURI(), emit)
+	}
+
+	if config.ExtractURLs {
+		f := finding.Finding{Type: finding.TypeURL, Value: raw, Confidence: urlConfidence(raw)}
+		f.SetMeta("scheme", u.Scheme)
+		f.SetMeta("host", host)
+		if port := u.Port(); port != "" {
+			f.SetMeta("port", port)
+		}
+		if path := u.EscapedPath(); path != "" {
+			f.SetMeta("path", path)
+		}
+		emit(f)
+	}
+
+	var params []queryParam
+	if u.RawQuery != "" || u.ForceQuery {
+		params = parseQuery("?"+u.RawQuery, params)
+	}
+	if u.Fragment != "" {
+		params = parseQuery("#"+u.EscapedFragment(), params)
+	}
+	for _, p := range params {
+		if config.ExtractParams {
+			emitParam(p, config.DecodeParams, emit)
+		}
+		if depth+1 < maxURLDepth {
+			if value, err := url.QueryUnescape(p.Value); err == nil {
+				if embedded, ok := parseURLLine(value); ok {
+					matchURLParts(config, value, embedded, emit, depth+1)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package extractor
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/patterns"
+)
+
+// Origins recorded in the "origin" metadata of usernames
+const (
+	originEmail  = "email"
+	originPath   = "path"
+	originAuthor = "author"
+)
+
+// roleMailboxes are shared and system mailboxes whose local part is not a person's username
+var roleMailboxes = setOf("abuse", "billing", "careers", "contact", "do-not-reply", "donotreply", "feedback",
+	"hello", "help", "hostmaster", "hr", "info", "jobs", "legal", "mail", "mailer-daemon", "marketing",
+	"media", "newsletter", "no-reply", "noreply", "office", "postmaster", "press", "privacy", "sales",
+	"security", "support", "team", "webmaster")
+
+// reservedUserPaths are path segments after /users/ and similar prefixes that are pages, not accounts
+var reservedUserPaths = setOf("all", "edit", "index", "list", "login", "logout", "me", "new", "profile",
+	"register", "search", "self", "settings", "sign_in", "sign_up", "signup", "current")
+
+// digitsRegex matches numeric IDs, which identify accounts but are not usernames
+var digitsRegex = regexp.MustCompile(`^\d+$`)
+
+// matchUsernames reports account names derived from email local parts, account URL paths such
+// as /users/jdoe or /@jdoe, and author metadata. Each occurrence is counted so the most
+// frequent names can be listed first; email and path usernames are lowercased.
+func matchUsernames(line string, emit func(finding.Finding)) {
+	report := func(name, origin string) {
+		f := finding.Finding{Type: finding.TypeUsername, Value: name, Confidence: finding.ConfidenceMedium, Count: 1}
+		f.SetMeta("origin", origin)
+		emit(f)
+	}
+
+	for _, email := range patterns.EmailRegex.FindAllString(line, -1) {
+		local, _, _ := strings.Cut(email, "@")
+		local, _, _ = strings.Cut(strings.ToLower(local), "+")
+		if local != "" && !roleMailboxes[local] {
+			report(local, originEmail)
+		}
+	}
+
+	for _, match := range patterns.UserPathRegex.FindAllStringSubmatch(line, -1) {
+		name := match[1]
+		if name == "" {
+			name = match[2]
+		}
+		name = strings.ToLower(strings.TrimRight(name, "._-"))
+		if name == "" || reservedUserPaths[name] || digitsRegex.MatchString(name) || strings.Contains(name, "..") {
+			continue
+		}
+		report(name, originPath)
+	}
+
+	for _, regex := range patterns.AuthorRegexes {
+		for _, match := range regex.FindAllStringSubmatch(line, -1) {
+			if author := cleanAuthor(match[1]); author != "" {
+				report(author, originAuthor)
+			}
+		}
+	}
+}
+
+// cleanAuthor normalizes the whitespace of an author name, dropping a trailing email or URL,
+// and returns "" for values that are not a name or handle
+func cleanAuthor(author string) string {
+	author = strings.Join(strings.Fields(author), " ")
+	if i := strings.IndexAny(author, "(@"); i > 0 {
+		author = strings.TrimSpace(author[:i])
+	}
+	words := strings.Fields(author)
+	if len(words) == 0 || len(words) > 4 || len(author) > 64 || strings.Contains(author, "://") {
+		return ""
+	}
+	return author
+}
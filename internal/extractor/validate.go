@@ -0,0 +1,94 @@
+package extractor
+
+import (
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+var (
+	// labelRegex matches a single DNS label
+	labelRegex = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+	// tldRegex matches an alphabetic top level domain (or its punycode form)
+	tldRegex = regexp.MustCompile(`^([a-zA-Z]{2,63}|xn--[a-zA-Z0-9-]{1,59})$`)
+	// paramKeyRegex matches query parameter names that look intentional
+	paramKeyRegex = regexp.MustCompile(`^[a-zA-Z0-9_.\-\[\]]+$`)
+)
+
+// validHostname reports whether host is a syntactically valid multi-label hostname
+func validHostname(host string) bool {
+	labels := strings.Split(host, ".")
+	if len(labels) < 2 {
+		return false
+	}
+	for _, label := range labels {
+		if !labelRegex.MatchString(label) {
+			return false
+		}
+	}
+	return tldRegex.MatchString(labels[len(labels)-1])
+}
+
+// emailConfidence rates an email matched by regex; addresses that parse and have a valid domain are high
+func emailConfidence(email string) finding.Confidence {
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return finding.ConfidenceMedium
+	}
+	at := strings.LastIndex(email, "@")
+	local := email[:at]
+	if strings.HasPrefix(local, ".") || strings.HasSuffix(local, ".") || strings.Contains(local, "..") {
+		return finding.ConfidenceMedium
+	}
+	if !validHostname(email[at+1:]) {
+		return finding.ConfidenceMedium
+	}
+	return finding.ConfidenceHigh
+}
+
+// domainConfidence rates a host extracted from a URL
+func domainConfidence(host string) finding.Confidence {
+	if validHostname(host) {
+		return finding.ConfidenceHigh
+	}
+	if labelRegex.MatchString(host) {
+		// Single label hosts such as localhost or intranet names
+		return finding.ConfidenceMedium
+	}
+	return finding.ConfidenceLow
+}
+
+// urlConfidence rates a URL by whether it parses and has a valid host
+func urlConfidence(raw string) finding.Confidence {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return finding.ConfidenceLow
+	}
+	if net.ParseIP(u.Hostname()) != nil {
+		return finding.ConfidenceHigh
+	}
+	return domainConfidence(u.Hostname())
+}
+
+// paramConfidence rates a query parameter by the shape of its key
+func paramConfidence(key string) finding.Confidence {
+	if paramKeyRegex.MatchString(key) {
+		return finding.ConfidenceHigh
+	}
+	return finding.ConfidenceMedium
+}
+
+// tokenConfidence rates a high entropy token; mixing letters and digits is typical of generated values
+func tokenConfidence(token string) finding.Confidence {
+	hasLetter := strings.IndexFunc(token, unicode.IsLetter) >= 0
+	hasDigit := strings.IndexFunc(token, unicode.IsDigit) >= 0
+	if hasLetter && hasDigit {
+		return finding.ConfidenceMedium
+	}
+	return finding.ConfidenceLow
+}
@@ -0,0 +1,79 @@
+// Package filter selects which findings are kept for output.
+package filter
+
+import "github.com/PeteJStewart/urlsluice/internal/finding"
+
+// Func reports whether a finding should be kept
+type Func func(finding.Finding) bool
+
+// Apply returns the findings accepted by every filter, preserving their order
+func Apply(findings []finding.Finding, filters ...Func) []finding.Finding {
+	if len(filters) == 0 {
+		return findings
+	}
+	kept := make([]finding.Finding, 0, len(findings))
+	for _, f := range findings {
+		if keep(f, filters) {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+func keep(f finding.Finding, filters []Func) bool {
+	for _, filter := range filters {
+		if !filter(f) {
+			return false
+		}
+	}
+	return true
+}
+
+// MinConfidence keeps findings rated at least min
+func MinConfidence(min finding.Confidence) Func {
+	return func(f finding.Finding) bool {
+		return f.Confidence.AtLeast(min)
+	}
+}
+
+// ExcludeTypes drops findings of the given types
+func ExcludeTypes(types ...finding.Type) Func {
+	excluded := make(map[finding.Type]bool, len(types))
+	for _, t := range types {
+		excluded[t] = true
+	}
+	return func(f finding.Finding) bool {
+		return !excluded[f.Type]
+	}
+}
+
+// Types keeps findings of the given types
+func Types(types ...finding.Type) Func {
+	kept := make(map[finding.Type]bool, len(types))
+	for _, t := range types {
+		kept[t] = true
+	}
+	return func(f finding.Finding) bool {
+		return kept[f.Type]
+	}
+}
+
+// OnlyAlive drops URL findings that were probed and found dead or unreachable.
+// Findings of other types are kept unchanged.
+func OnlyAlive() Func {
+	return func(f finding.Finding) bool {
+		return f.Type != finding.TypeURL || f.Metadata["alive"] == "true"
+	}
+}
+
+// Tagged keeps findings carrying at least one of the given tags
+func Tagged(tags ...string) Func {
+	return func(f finding.Finding) bool {
+		for _, tag := range tags {
+			if f.HasTag(tag) {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,85 @@
+package filter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+func values(findings []finding.Finding) []string {
+	out := make([]string, 0, len(findings))
+	for _, f := range findings {
+		out = append(out, f.Value)
+	}
+	return out
+}
+
+func TestMinConfidence(t *testing.T) {
+	findings := []finding.Finding{
+		{Type: finding.TypeEmail, Value: "a@example.com", Confidence: finding.ConfidenceHigh},
+		{Type: finding.TypeEmail, Value: "b@example", Confidence: finding.ConfidenceMedium},
+		{Type: finding.TypeDomain, Value: "x..y", Confidence: finding.ConfidenceLow},
+	}
+
+	tests := []struct {
+		name string
+		min  finding.Confidence
+		want []string
+	}{
+		{"low keeps everything", finding.ConfidenceLow, []string{"a@example.com", "b@example", "x..y"}},
+		{"medium drops low", finding.ConfidenceMedium, []string{"a@example.com", "b@example"}},
+		{"high keeps validated only", finding.ConfidenceHigh, []string{"a@example.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := values(Apply(findings, MinConfidence(tt.min)))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Apply(MinConfidence(%s)) = %v, want %v", tt.min, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTypes(t *testing.T) {
+	findings := []finding.Finding{
+		{Type: finding.TypeEmail, Value: "a@target.com"},
+		{Type: finding.TypeDomain, Value: "target.com"},
+		{Type: finding.TypeIP, Value: "10.0.0.1"},
+	}
+
+	got := values(Apply(findings, Types(finding.TypeDomain, finding.TypeIP)))
+	want := []string{"target.com", "10.0.0.1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Apply(Types) = %v, want %v", got, want)
+	}
+}
+
+func TestOnlyAlive(t *testing.T) {
+	findings := []finding.Finding{
+		{Type: finding.TypeURL, Value: "https://target.com/up", Metadata: map[string]string{"alive": "true"}},
+		{Type: finding.TypeURL, Value: "https://target.com/down", Metadata: map[string]string{"alive": "false"}},
+		{Type: finding.TypeDomain, Value: "target.com"},
+	}
+
+	got := values(Apply(findings, OnlyAlive()))
+	want := []string{"https://target.com/up", "target.com"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Apply(OnlyAlive) = %v, want %v", got, want)
+	}
+}
+
+func TestTagged(t *testing.T) {
+	findings := []finding.Finding{
+		{Type: finding.TypeDomain, Value: "jira.corp.target.com", Tags: []string{finding.TagInternal}},
+		{Type: finding.TypeDomain, Value: "www.target.com"},
+		{Type: finding.TypeURL, Value: "https://staging.target.com/", Tags: []string{"staging"}},
+	}
+
+	got := values(Apply(findings, Tagged(finding.TagInternal, "staging")))
+	want := []string{"jira.corp.target.com", "https://staging.target.com/"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Apply(Tagged) = %v, want %v", got, want)
+	}
+}
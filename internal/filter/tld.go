@@ -0,0 +1,73 @@
+package filter
+
+import (
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+// reservedTLDs are the top level domains reserved by RFC 2606
+var reservedTLDs = map[string]bool{
+	"test":      true,
+	"example":   true,
+	"invalid":   true,
+	"localhost": true,
+}
+
+// reservedDomains are the second level domains reserved by RFC 2606
+var reservedDomains = []string{"example.com", "example.net", "example.org"}
+
+// IsReserved reports whether host falls under a domain reserved by RFC 2606
+func IsReserved(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if reservedTLDs[tld(host)] {
+		return true
+	}
+	for _, d := range reservedDomains {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// TLDs filters domain findings by top level domain.
+// When allow is non-empty only domains with one of those TLDs are kept; domains with a TLD
+// in deny are always dropped. RFC 2606 reserved domains are dropped unless includeReserved is set.
+// Findings of other types are kept unchanged.
+func TLDs(allow, deny []string, includeReserved bool) Func {
+	allowed := toSet(allow)
+	denied := toSet(deny)
+	return func(f finding.Finding) bool {
+		if f.Type != finding.TypeDomain {
+			return true
+		}
+		if !includeReserved && IsReserved(f.Value) {
+			return false
+		}
+		t := tld(strings.ToLower(f.Value))
+		if denied[t] {
+			return false
+		}
+		return len(allowed) == 0 || allowed[t]
+	}
+}
+
+// tld returns the last label of host
+func tld(host string) string {
+	if i := strings.LastIndex(host, "."); i >= 0 {
+		return host[i+1:]
+	}
+	return host
+}
+
+func toSet(tlds []string) map[string]bool {
+	set := make(map[string]bool, len(tlds))
+	for _, t := range tlds {
+		t = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(t)), ".")
+		if t != "" {
+			set[t] = true
+		}
+	}
+	return set
+}
@@ -0,0 +1,71 @@
+package filter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+func TestTLDs(t *testing.T) {
+	findings := []finding.Finding{
+		{Type: finding.TypeDomain, Value: "api.target.com"},
+		{Type: finding.TypeDomain, Value: "cdn.target.io"},
+		{Type: finding.TypeDomain, Value: "build.corp.local"},
+		{Type: finding.TypeDomain, Value: "www.example.com"},
+		{Type: finding.TypeDomain, Value: "app.test"},
+		{Type: finding.TypeEmail, Value: "dev@example.com"},
+	}
+
+	tests := []struct {
+		name            string
+		allow, deny     []string
+		includeReserved bool
+		want            []string
+	}{
+		{
+			name: "reserved domains suppressed by default",
+			want: []string{"api.target.com", "cdn.target.io", "build.corp.local", "dev@example.com"},
+		},
+		{
+			name:            "include reserved",
+			includeReserved: true,
+			want:            []string{"api.target.com", "cdn.target.io", "build.corp.local", "www.example.com", "app.test", "dev@example.com"},
+		},
+		{
+			name:  "allowlist",
+			allow: []string{"com", ".io"},
+			want:  []string{"api.target.com", "cdn.target.io", "dev@example.com"},
+		},
+		{
+			name: "denylist",
+			deny: []string{"local"},
+			want: []string{"api.target.com", "cdn.target.io", "dev@example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := values(Apply(findings, TLDs(tt.allow, tt.deny, tt.includeReserved)))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Apply(TLDs) = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsReserved(t *testing.T) {
+	tests := map[string]bool{
+		"example.com":     true,
+		"api.example.org": true,
+		"localhost":       true,
+		"foo.invalid":     true,
+		"myexample.com":   false,
+		"example.co":      false,
+	}
+	for host, want := range tests {
+		if got := IsReserved(host); got != want {
+			t.Errorf("IsReserved(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
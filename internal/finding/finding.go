@@ -0,0 +1,287 @@
+// Package finding defines the core data model shared by every extractor and output writer.
+// A Finding describes a single pattern match together with where it was found and any
+// additional context an extractor or enrichment step attached to it.
+package finding
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Type identifies the category of a finding
+type Type string
+
+const (
+	// TypeUUID is a Universal Unique Identifier
+	TypeUUID Type = "uuid"
+	// TypeEmail is an email address
+	TypeEmail Type = "email"
+	// TypeDomain is a domain name extracted from a URL
+	TypeDomain Type = "domain"
+	// TypeIP is an IPv4 address
+	TypeIP Type = "ip"
+	// TypeParam is a URL query parameter in "key=value" format
+	TypeParam Type = "param"
+	// TypeURL is an absolute HTTP or HTTPS URL
+	TypeURL Type = "url"
+	// TypeToken is a random-looking string such as a session token or key
+	TypeToken Type = "token"
+	// TypeHandle is a social media or developer platform account, e.g. "github:org/repo"
+	TypeHandle Type = "handle"
+	// TypeCrypto is a cryptocurrency wallet address
+	TypeCrypto Type = "crypto"
+	// TypeCloudConfig is an API key or DSN from an embedded cloud service config
+	TypeCloudConfig Type = "cloud_config"
+	// TypeConfigSecret is a credential assigned in a dotenv, ini or YAML style config, as "KEY=VALUE"
+	TypeConfigSecret Type = "config_secret"
+	// TypeTimestamp is an identifier with an embedded creation time, such as a v1 UUID or a ULID
+	TypeTimestamp Type = "timestamp"
+	// TypeHeaderIssue is an informational note about a weak CSP, CORS or HSTS response header
+	TypeHeaderIssue Type = "header_issue"
+	// TypeUsername is an account name derived from an email address, profile URL or author metadata
+	TypeUsername Type = "username"
+	// TypeAvatarHash is the MD5 or SHA-256 email hash identifying an avatar, e.g. in a Gravatar URL
+	TypeAvatarHash Type = "avatar_hash"
+	// TypeBucket is a cloud storage bucket name, with the provider in the "provider" metadata;
+	// S3 buckets are bare names while other providers are prefixed, e.g. "gcs:assets"
+	TypeBucket Type = "bucket"
+	// TypeTakeover is a host or bucket that could be claimed, such as a dangling CNAME
+	TypeTakeover Type = "takeover"
+	// TypeJSONP is a URL passing a function name in a callback parameter, a potential JSONP
+	// endpoint; the parameter is recorded in the "param" metadata
+	TypeJSONP Type = "jsonp"
+	// TypeCORS is a response whose CORS headers let other sites read it, such as one echoing
+	// the request Origin; the header values are recorded in the metadata
+	TypeCORS Type = "cors"
+	// TypeVerboseError is a stack trace or debug error page, named "<page> <framework>";
+	// the framework and the matched text are recorded in the metadata
+	TypeVerboseError Type = "verbose_error"
+	// TypeComment is the text of an HTML or JavaScript block comment, with the kind recorded
+	// in the "kind" metadata
+	TypeComment Type = "comment"
+	// TypeLocale is a localized path such as "https://target.com/fr-fr/" or an i18n
+	// translation file, with the kind and the locale tag recorded in the metadata
+	TypeLocale Type = "locale"
+	// TypeTrackingParam is a query parameter in "key=value" format whose key is a campaign or
+	// click identifier such as utm_source, kept apart from the parameters worth fuzzing
+	TypeTrackingParam Type = "tracking_param"
+	// TypePlugin is a match reported by an extractor plugin, with the plugin recorded in the
+	// "plugin" metadata
+	TypePlugin Type = "plugin"
+)
+
+// TagInternal marks findings whose host is likely only reachable from a private network
+const TagInternal = "internal"
+
+// Types lists the known finding types in their canonical output order
+var Types = []Type{TypeUUID, TypeEmail, TypeDomain, TypeIP, TypeParam, TypeURL, TypeToken, TypeHandle, TypeCrypto, TypeCloudConfig, TypeConfigSecret, TypeTimestamp, TypeHeaderIssue, TypeUsername, TypeAvatarHash, TypeBucket, TypeTakeover, TypeJSONP, TypeCORS, TypeVerboseError, TypeComment, TypeLocale, TypeTrackingParam, TypePlugin}
+
+// ParseType converts a type name such as "domain" into a Type
+func ParseType(s string) (Type, error) {
+	name := Type(strings.ToLower(strings.TrimSpace(s)))
+	for _, t := range Types {
+		if t == name {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("unknown finding type %q", s)
+}
+
+// Confidence describes how likely a finding is to be a true positive
+type Confidence string
+
+const (
+	// ConfidenceLow marks matches that are frequently false positives
+	ConfidenceLow Confidence = "low"
+	// ConfidenceMedium marks matches found by pattern alone
+	ConfidenceMedium Confidence = "medium"
+	// ConfidenceHigh marks matches that passed additional validation
+	ConfidenceHigh Confidence = "high"
+)
+
+// ParseConfidence converts a level name into a Confidence
+func ParseConfidence(s string) (Confidence, error) {
+	switch c := Confidence(strings.ToLower(strings.TrimSpace(s))); c {
+	case ConfidenceLow, ConfidenceMedium, ConfidenceHigh:
+		return c, nil
+	}
+	return "", fmt.Errorf("invalid confidence level %q: must be low, medium or high", s)
+}
+
+// rank orders confidence levels; findings without a level rank lowest
+func (c Confidence) rank() int {
+	switch c {
+	case ConfidenceHigh:
+		return 3
+	case ConfidenceMedium:
+		return 2
+	case ConfidenceLow:
+		return 1
+	}
+	return 0
+}
+
+// AtLeast reports whether c is greater than or equal to min
+func (c Confidence) AtLeast(min Confidence) bool {
+	return c.rank() >= min.rank()
+}
+
+// Finding represents a single unique match produced by an extractor
+type Finding struct {
+	// Type is the category of the finding
+	Type Type `json:"type"`
+	// Value is the matched text
+	Value string `json:"value"`
+	// Source identifies the input the finding came from, e.g. a file path or URL
+	Source string `json:"source,omitempty"`
+	// Line is the 1-based line number of the first occurrence within Source
+	Line int `json:"line,omitempty"`
+	// Tags are free-form labels attached by classification rules
+	Tags []string `json:"tags,omitempty"`
+	// Confidence is the likelihood that the finding is a true positive
+	Confidence Confidence `json:"confidence,omitempty"`
+	// Metadata holds extractor or enrichment specific details
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// Count is the number of occurrences, for types whose frequency matters such as usernames;
+	// it is zero for types that are not counted
+	Count int `json:"count,omitempty"`
+	// Remediation is guidance for fixing security findings, attached to structured output
+	Remediation *Remediation `json:"remediation,omitempty"`
+}
+
+// Remediation is a short hint on fixing a finding and a page explaining it in depth
+type Remediation struct {
+	// Hint is a one-sentence fix
+	Hint string `json:"hint"`
+	// Reference is the URL of further guidance, such as an OWASP cheat sheet or internal wiki page
+	Reference string `json:"reference,omitempty"`
+}
+
+// Key returns the identity of the finding used for deduplication
+func (f Finding) Key() string {
+	return string(f.Type) + "\x00" + f.Value
+}
+
+// HasTag reports whether the finding carries the given tag
+func (f Finding) HasTag(tag string) bool {
+	for _, t := range f.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// AddTag attaches tag to the finding unless it is already present
+func (f *Finding) AddTag(tag string) {
+	if !f.HasTag(tag) {
+		f.Tags = append(f.Tags, tag)
+	}
+}
+
+// SetMeta stores a metadata value, allocating the map on first use
+func (f *Finding) SetMeta(key, value string) {
+	if f.Metadata == nil {
+		f.Metadata = make(map[string]string)
+	}
+	f.Metadata[key] = value
+}
+
+// Sort orders findings by type (in the order of Types) and then by value.
+// Counted findings of the same type are ordered by descending count first.
+func Sort(findings []Finding) {
+	rank := make(map[Type]int, len(Types))
+	for i, t := range Types {
+		rank[t] = i
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		ri, iok := rank[findings[i].Type]
+		rj, jok := rank[findings[j].Type]
+		if !iok {
+			ri = len(Types)
+		}
+		if !jok {
+			rj = len(Types)
+		}
+		if ri != rj {
+			return ri < rj
+		}
+		if findings[i].Type != findings[j].Type {
+			return findings[i].Type < findings[j].Type
+		}
+		if findings[i].Count != findings[j].Count {
+			return findings[i].Count > findings[j].Count
+		}
+		return findings[i].Value < findings[j].Value
+	})
+}
+
+// Set accumulates unique findings keyed by Finding.Key.
+// When the same finding is added more than once, the earliest occurrence is kept, tags are
+// merged and counts are added up.
+// The zero value is ready to use.
+type Set struct {
+	// KeyFunc, when set, replaces Finding.Key as the identity used for deduplication, e.g. to
+	// treat values that differ only in case as the same finding
+	KeyFunc func(Finding) string
+
+	items map[string]Finding
+}
+
+// Add inserts f into the set
+func (s *Set) Add(f Finding) {
+	if s.items == nil {
+		s.items = make(map[string]Finding)
+	}
+	key := f.Key()
+	if s.KeyFunc != nil {
+		key = s.KeyFunc(f)
+	}
+	existing, ok := s.items[key]
+	if !ok {
+		// Later merges add tags and metadata to the copy in the set, which must not write
+		// through to the finding passed in
+		f.Tags = slices.Clone(f.Tags)
+		f.Metadata = maps.Clone(f.Metadata)
+		s.items[key] = f
+		return
+	}
+	if existing.Source == f.Source && f.Line > 0 && (existing.Line == 0 || f.Line < existing.Line) {
+		existing.Line = f.Line
+	}
+	existing.Count += f.Count
+	for _, tag := range f.Tags {
+		existing.AddTag(tag)
+	}
+	for k, v := range f.Metadata {
+		if _, ok := existing.Metadata[k]; !ok {
+			existing.SetMeta(k, v)
+		}
+	}
+	s.items[key] = existing
+}
+
+// Merge adds every finding from other into the set
+func (s *Set) Merge(other *Set) {
+	for _, f := range other.items {
+		s.Add(f)
+	}
+}
+
+// Len returns the number of unique findings in the set
+func (s *Set) Len() int {
+	return len(s.items)
+}
+
+// Findings returns the contents of the set ordered with Sort
+func (s *Set) Findings() []Finding {
+	findings := make([]Finding, 0, len(s.items))
+	for _, f := range s.items {
+		findings = append(findings, f)
+	}
+	Sort(findings)
+	return findings
+}
@@ -0,0 +1,131 @@
+package finding
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSet_Add(t *testing.T) {
+	var s Set
+	s.Add(Finding{Type: TypeEmail, Value: "b@example.com", Line: 7})
+	s.Add(Finding{Type: TypeEmail, Value: "b@example.com", Line: 3, Tags: []string{"staging"}})
+	s.Add(Finding{Type: TypeDomain, Value: "example.com", Line: 1})
+	s.Add(Finding{Type: TypeUUID, Value: "550e8400-e29b-41d4-a716-446655440000", Line: 2})
+
+	if s.Len() != 3 {
+		t.Fatalf("Len() = %d, want 3", s.Len())
+	}
+
+	got := s.Findings()
+	wantOrder := []Type{TypeUUID, TypeEmail, TypeDomain}
+	for i, f := range got {
+		if f.Type != wantOrder[i] {
+			t.Errorf("Findings()[%d].Type = %s, want %s", i, f.Type, wantOrder[i])
+		}
+	}
+
+	email := got[1]
+	if email.Line != 3 {
+		t.Errorf("email Line = %d, want earliest line 3", email.Line)
+	}
+	if !reflect.DeepEqual(email.Tags, []string{"staging"}) {
+		t.Errorf("email Tags = %v, want [staging]", email.Tags)
+	}
+}
+
+func TestSet_AddDoesNotModifyFindings(t *testing.T) {
+	first := Finding{Type: TypeDomain, Value: "example.com", Tags: make([]string, 1, 4), Metadata: map[string]string{"source": "crawl"}}
+	first.Tags[0] = "internal"
+	var s Set
+	s.Add(first)
+	s.Add(Finding{Type: TypeDomain, Value: "example.com", Tags: []string{"staging"}, Metadata: map[string]string{"resolved": "10.0.0.1"}})
+
+	// The spare capacity of Tags must not receive the merged tag either
+	if !reflect.DeepEqual(first.Tags[:2], []string{"internal", ""}) {
+		t.Errorf("first finding Tags = %v, changed by the merge", first.Tags[:2])
+	}
+	if !reflect.DeepEqual(first.Metadata, map[string]string{"source": "crawl"}) {
+		t.Errorf("first finding Metadata = %v, changed by the merge", first.Metadata)
+	}
+	merged := s.Findings()[0]
+	if !reflect.DeepEqual(merged.Tags, []string{"internal", "staging"}) || !reflect.DeepEqual(merged.Metadata, map[string]string{"source": "crawl", "resolved": "10.0.0.1"}) {
+		t.Errorf("merged finding = %+v", merged)
+	}
+}
+
+func TestSet_Count(t *testing.T) {
+	var s Set
+	s.Add(Finding{Type: TypeUsername, Value: "asmith", Count: 1})
+	s.Add(Finding{Type: TypeUsername, Value: "jdoe", Count: 1})
+	s.Add(Finding{Type: TypeUsername, Value: "jdoe", Count: 2})
+
+	got := s.Findings()
+	if got[0].Value != "jdoe" || got[0].Count != 3 {
+		t.Errorf("Findings()[0] = %s (%d), want jdoe counted 3 times first", got[0].Value, got[0].Count)
+	}
+	if got[1].Value != "asmith" || got[1].Count != 1 {
+		t.Errorf("Findings()[1] = %s (%d), want asmith counted once", got[1].Value, got[1].Count)
+	}
+}
+
+func TestSet_KeyFunc(t *testing.T) {
+	s := Set{KeyFunc: func(f Finding) string { return string(f.Type) + "\x00" + strings.ToLower(f.Value) }}
+	s.Add(Finding{Type: TypeEmail, Value: "Admin@Target.com", Source: "a.html"})
+	s.Add(Finding{Type: TypeEmail, Value: "admin@target.com", Source: "b.html", Tags: []string{"internal"}})
+
+	got := s.Findings()
+	if len(got) != 1 || got[0].Value != "Admin@Target.com" || !got[0].HasTag("internal") {
+		t.Errorf("Findings() = %+v, want the first spelling with the tags of both", got)
+	}
+}
+
+func TestFinding_Tags(t *testing.T) {
+	f := Finding{Type: TypeDomain, Value: "example.com"}
+	f.AddTag("internal")
+	f.AddTag("internal")
+	if !f.HasTag("internal") || len(f.Tags) != 1 {
+		t.Errorf("Tags = %v, want [internal]", f.Tags)
+	}
+
+	f.SetMeta("status", "200")
+	if f.Metadata["status"] != "200" {
+		t.Errorf("Metadata = %v, want status=200", f.Metadata)
+	}
+}
+
+func TestConfidence_AtLeast(t *testing.T) {
+	tests := []struct {
+		c, min Confidence
+		want   bool
+	}{
+		{ConfidenceHigh, ConfidenceMedium, true},
+		{ConfidenceMedium, ConfidenceMedium, true},
+		{ConfidenceLow, ConfidenceMedium, false},
+		{"", ConfidenceLow, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.c.AtLeast(tt.min); got != tt.want {
+			t.Errorf("%q.AtLeast(%q) = %v, want %v", tt.c, tt.min, got, tt.want)
+		}
+	}
+}
+
+func TestParseConfidence(t *testing.T) {
+	if c, err := ParseConfidence("High"); err != nil || c != ConfidenceHigh {
+		t.Errorf("ParseConfidence(High) = %q, %v", c, err)
+	}
+	if _, err := ParseConfidence("certain"); err == nil {
+		t.Error("ParseConfidence(certain) expected error")
+	}
+}
+
+func TestParseType(t *testing.T) {
+	if got, err := ParseType(" Domain "); err != nil || got != TypeDomain {
+		t.Errorf("ParseType(Domain) = %q, %v, want domain", got, err)
+	}
+	if _, err := ParseType("hostname"); err == nil {
+		t.Error("ParseType(hostname) error = nil, want error")
+	}
+}
@@ -0,0 +1,193 @@
+// Package graphql reads GraphQL introspection results and lists the types, fields and
+// arguments of the schema.
+package graphql
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Schema is the schema described by an introspection result
+type Schema struct {
+	// QueryType, MutationType and SubscriptionType name the root operation types
+	QueryType        string
+	MutationType     string
+	SubscriptionType string
+	// Types are the types of the schema, without the introspection types
+	Types []Type
+}
+
+// Type is an object, interface, input, enum, union or scalar type
+type Type struct {
+	Kind   string
+	Name   string
+	Fields []Field
+	// InputFields are the fields of an input object type
+	InputFields []Arg
+}
+
+// Field is a field of an object or interface type
+type Field struct {
+	Name string
+	Args []Arg
+	// Type is the field's type in GraphQL notation, e.g. "[User!]!"
+	Type string
+}
+
+// Arg is a field argument or input field
+type Arg struct {
+	Name string
+	// Type is the argument's type in GraphQL notation, e.g. "ID!"
+	Type string
+}
+
+// Operation is a field of a root operation type, such as a query or mutation
+type Operation struct {
+	// Kind is "query", "mutation" or "subscription"
+	Kind  string
+	Field Field
+}
+
+// builtinScalars are the scalar types every schema has
+var builtinScalars = map[string]bool{"String": true, "Int": true, "Float": true, "Boolean": true, "ID": true}
+
+type introspection struct {
+	Data *struct {
+		Schema *schema `json:"__schema"`
+	} `json:"data"`
+	Schema *schema `json:"__schema"`
+}
+
+type schema struct {
+	QueryType        *struct{ Name string } `json:"queryType"`
+	MutationType     *struct{ Name string } `json:"mutationType"`
+	SubscriptionType *struct{ Name string } `json:"subscriptionType"`
+	Types            []struct {
+		Kind   string `json:"kind"`
+		Name   string `json:"name"`
+		Fields []struct {
+			Name string     `json:"name"`
+			Args []inputVal `json:"args"`
+			Type *typeRef   `json:"type"`
+		} `json:"fields"`
+		InputFields []inputVal `json:"inputFields"`
+	} `json:"types"`
+}
+
+type inputVal struct {
+	Name string   `json:"name"`
+	Type *typeRef `json:"type"`
+}
+
+type typeRef struct {
+	Kind   string   `json:"kind"`
+	Name   string   `json:"name"`
+	OfType *typeRef `json:"ofType"`
+}
+
+// String renders the reference in GraphQL notation
+func (t *typeRef) String() string {
+	if t == nil {
+		return ""
+	}
+	switch t.Kind {
+	case "NON_NULL":
+		return t.OfType.String() + "!"
+	case "LIST":
+		return "[" + t.OfType.String() + "]"
+	}
+	return t.Name
+}
+
+// Parse reads an introspection result, with or without the "data" envelope of the response
+func Parse(data []byte) (*Schema, error) {
+	var r introspection
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("error parsing introspection result: %w", err)
+	}
+	s := r.Schema
+	if r.Data != nil && r.Data.Schema != nil {
+		s = r.Data.Schema
+	}
+	if s == nil {
+		return nil, errors.New("not a GraphQL introspection result: no __schema")
+	}
+
+	out := &Schema{}
+	if s.QueryType != nil {
+		out.QueryType = s.QueryType.Name
+	}
+	if s.MutationType != nil {
+		out.MutationType = s.MutationType.Name
+	}
+	if s.SubscriptionType != nil {
+		out.SubscriptionType = s.SubscriptionType.Name
+	}
+	for _, t := range s.Types {
+		if strings.HasPrefix(t.Name, "__") {
+			continue
+		}
+		typ := Type{Kind: t.Kind, Name: t.Name}
+		for _, f := range t.Fields {
+			field := Field{Name: f.Name, Type: f.Type.String()}
+			for _, a := range f.Args {
+				field.Args = append(field.Args, Arg{Name: a.Name, Type: a.Type.String()})
+			}
+			typ.Fields = append(typ.Fields, field)
+		}
+		for _, a := range t.InputFields {
+			typ.InputFields = append(typ.InputFields, Arg{Name: a.Name, Type: a.Type.String()})
+		}
+		out.Types = append(out.Types, typ)
+	}
+	return out, nil
+}
+
+// Operations returns the fields of the query, mutation and subscription types
+func (s *Schema) Operations() []Operation {
+	var ops []Operation
+	for _, root := range []struct{ kind, name string }{
+		{"query", s.QueryType},
+		{"mutation", s.MutationType},
+		{"subscription", s.SubscriptionType},
+	} {
+		for _, t := range s.Types {
+			if root.name != "" && t.Name == root.name {
+				for _, f := range t.Fields {
+					ops = append(ops, Operation{Kind: root.kind, Field: f})
+				}
+			}
+		}
+	}
+	return ops
+}
+
+// Names returns the type, field, argument and input field names of the schema in schema
+// order, without duplicates and without the built-in scalars
+func (s *Schema) Names() []string {
+	seen := make(map[string]bool)
+	var names []string
+	add := func(name string) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	for _, t := range s.Types {
+		if !builtinScalars[t.Name] {
+			add(t.Name)
+		}
+		for _, f := range t.Fields {
+			add(f.Name)
+			for _, a := range f.Args {
+				add(a.Name)
+			}
+		}
+		for _, a := range t.InputFields {
+			add(a.Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,67 @@
+package graphql
+
+import (
+	"reflect"
+	"testing"
+)
+
+const testIntrospection = `{"data": {"__schema": {
+  "queryType": {"name": "Query"},
+  "mutationType": {"name": "Mutation"},
+  "subscriptionType": null,
+  "types": [
+    {"kind": "OBJECT", "name": "Query", "fields": [
+      {"name": "user", "args": [{"name": "id", "type": {"kind": "NON_NULL", "name": null, "ofType": {"kind": "SCALAR", "name": "ID", "ofType": null}}}],
+       "type": {"kind": "OBJECT", "name": "User", "ofType": null}}
+    ]},
+    {"kind": "OBJECT", "name": "Mutation", "fields": [
+      {"name": "updateUser", "args": [{"name": "input", "type": {"kind": "NON_NULL", "name": null, "ofType": {"kind": "INPUT_OBJECT", "name": "UserInput", "ofType": null}}}],
+       "type": {"kind": "LIST", "name": null, "ofType": {"kind": "NON_NULL", "name": null, "ofType": {"kind": "OBJECT", "name": "User", "ofType": null}}}}
+    ]},
+    {"kind": "OBJECT", "name": "User", "fields": [
+      {"name": "id", "args": [], "type": {"kind": "SCALAR", "name": "ID", "ofType": null}},
+      {"name": "isAdmin", "args": [], "type": {"kind": "SCALAR", "name": "Boolean", "ofType": null}}
+    ]},
+    {"kind": "INPUT_OBJECT", "name": "UserInput", "fields": null, "inputFields": [
+      {"name": "email", "type": {"kind": "SCALAR", "name": "String", "ofType": null}}
+    ]},
+    {"kind": "SCALAR", "name": "ID"},
+    {"kind": "OBJECT", "name": "__Type", "fields": [{"name": "kind", "args": []}]}
+  ]
+}}}`
+
+func TestParse(t *testing.T) {
+	s, err := Parse([]byte(testIntrospection))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	wantOps := []Operation{
+		{Kind: "query", Field: Field{Name: "user", Args: []Arg{{Name: "id", Type: "ID!"}}, Type: "User"}},
+		{Kind: "mutation", Field: Field{Name: "updateUser", Args: []Arg{{Name: "input", Type: "UserInput!"}}, Type: "[User!]"}},
+	}
+	if got := s.Operations(); !reflect.DeepEqual(got, wantOps) {
+		t.Errorf("Operations() = %+v, want %+v", got, wantOps)
+	}
+
+	wantNames := []string{"Query", "user", "id", "Mutation", "updateUser", "input", "User", "isAdmin", "UserInput", "email"}
+	if got := s.Names(); !reflect.DeepEqual(got, wantNames) {
+		t.Errorf("Names() = %v, want %v", got, wantNames)
+	}
+}
+
+func TestParse_WithoutEnvelope(t *testing.T) {
+	s, err := Parse([]byte(`{"__schema": {"queryType": {"name": "Q"}, "types": [{"kind": "OBJECT", "name": "Q", "fields": [{"name": "ping", "args": []}]}]}}`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if ops := s.Operations(); len(ops) != 1 || ops[0].Field.Name != "ping" {
+		t.Errorf("Operations() = %+v", ops)
+	}
+
+	for _, input := range []string{"", "{}", `{"data": {}}`, "not json"} {
+		if _, err := Parse([]byte(input)); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", input)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// Package grep selects input lines by regular expression before extraction, so inputs can be
+// narrowed to a host or path without piping them through an external grep first.
+package grep
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+
+	"github.com/PeteJStewart/urlsluice/internal/regexcache"
+)
+
+// Filter keeps lines matching at least one include pattern, or every line when there are
+// none, and drops lines matching any exclude pattern. A nil Filter keeps every line.
+type Filter struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// New compiles the include and exclude patterns; it returns nil when both are empty
+func New(include, exclude []string) (*Filter, error) {
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil, nil
+	}
+	f := &Filter{}
+	var err error
+	if f.include, err = compile(include); err != nil {
+		return nil, err
+	}
+	if f.exclude, err = compile(exclude); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func compile(patterns []string) ([]*regexp.Regexp, error) {
+	regexes := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexcache.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+		regexes = append(regexes, re)
+	}
+	return regexes, nil
+}
+
+// Match reports whether line is kept
+func (f *Filter) Match(line []byte) bool {
+	if f == nil {
+		return true
+	}
+	for _, re := range f.exclude {
+		if re.Match(line) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, re := range f.include {
+		if re.Match(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// Lines returns the kept lines
+func (f *Filter) Lines(lines []string) []string {
+	if f == nil {
+		return lines
+	}
+	kept := lines[:0:0]
+	for _, line := range lines {
+		if f.Match([]byte(line)) {
+			kept = append(kept, line)
+		}
+	}
+	return kept
+}
+
+// Blank returns data with the content of every dropped line removed. Line breaks are kept so
+// the line numbers of findings still refer to the original input.
+func (f *Filter) Blank(data []byte) []byte {
+	if f == nil {
+		return data
+	}
+	out := make([]byte, 0, len(data))
+	for len(data) > 0 {
+		line, rest, found := bytes.Cut(data, []byte("\n"))
+		if f.Match(bytes.TrimSuffix(line, []byte("\r"))) {
+			out = append(out, line...)
+		}
+		if found {
+			out = append(out, '\n')
+		}
+		data = rest
+	}
+	return out
+}
@@ -0,0 +1,59 @@
+package grep
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	input := "https://api.target.com/v1/users\nhttps://cdn.target.com/app.js\nhttps://api.target.com/health\nother text\n"
+
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		want    string
+	}{
+		{"include", []string{`api\.target\.com`}, nil, "https://api.target.com/v1/users\n\nhttps://api.target.com/health\n\n"},
+		{"exclude", nil, []string{`/health$`, `\.js$`}, "https://api.target.com/v1/users\n\n\nother text\n"},
+		{"both", []string{`target\.com`}, []string{`cdn\.`}, "https://api.target.com/v1/users\n\nhttps://api.target.com/health\n\n"},
+		{"any include", []string{`users`, `^other`}, nil, "https://api.target.com/v1/users\n\n\nother text\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := New(tt.include, tt.exclude)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if got := string(f.Blank([]byte(input))); got != tt.want {
+				t.Errorf("Blank() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilter_Lines(t *testing.T) {
+	f, err := New(nil, []string{`^#`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := f.Lines([]string{"# comment", "https://target.com/", ""})
+	if want := []string{"https://target.com/", ""}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Lines() = %q, want %q", got, want)
+	}
+
+	var none *Filter
+	if got := none.Blank([]byte("a\nb")); string(got) != "a\nb" {
+		t.Errorf("nil Filter changed the input: %q", got)
+	}
+}
+
+func TestNew_InvalidPattern(t *testing.T) {
+	if _, err := New([]string{"("}, nil); err == nil {
+		t.Error("New() accepted an invalid pattern")
+	}
+	if f, err := New(nil, nil); f != nil || err != nil {
+		t.Errorf("New(nil, nil) = %v, %v; want nil, nil", f, err)
+	}
+}
@@ -0,0 +1,298 @@
+// Package headers analyses the security headers of HTTP responses. The hosts a
+// Content-Security-Policy allows are reported as domains, weak CSP, CORS and HSTS
+// settings as informational header issues, and CORS policies that let other sites read
+// responses as CORS misconfiguration candidates.
+package headers
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+// minHSTSAge is the shortest HSTS max-age, 180 days in seconds, that is not reported as weak
+const minHSTSAge = 180 * 24 * 60 * 60
+
+// Issue codes recorded in the "issue" metadata of header issue findings
+const (
+	IssueUnsafeInline = "csp-unsafe-inline"
+	IssueUnsafeEval   = "csp-unsafe-eval"
+	IssueWildcard     = "csp-wildcard"
+	IssueCORSWildcard = "cors-wildcard-origin"
+	IssueCORSNull     = "cors-null-origin"
+	IssueHSTSShort    = "hsts-short-max-age"
+)
+
+// Issue codes recorded in the "issue" metadata of CORS findings
+const (
+	CORSReflectedOrigin     = "cors-reflected-origin"
+	CORSWildcardCredentials = "cors-wildcard-credentials"
+)
+
+const (
+	cspHeader        = "Content-Security-Policy"
+	cspReportOnly    = "Content-Security-Policy-Report-Only"
+	corsOriginHeader = "Access-Control-Allow-Origin"
+	corsCredsHeader  = "Access-Control-Allow-Credentials"
+	originHeader     = "Origin"
+	hstsHeader       = "Strict-Transport-Security"
+)
+
+// Hosts returns a domain finding for every host named in the Content-Security-Policy and
+// Content-Security-Policy-Report-Only headers of h, recording the header and directive.
+// Wildcard sources such as "*.cdn.example.com" are reported without the "*." prefix.
+func Hosts(h http.Header) []finding.Finding {
+	var findings []finding.Finding
+	seen := make(map[string]bool)
+	for _, name := range []string{cspHeader, cspReportOnly} {
+		for _, policy := range policies(h.Values(name)) {
+			for _, d := range policy {
+				for _, src := range d.sources {
+					host, wildcard := sourceHost(src)
+					if host == "" || seen[host] {
+						continue
+					}
+					seen[host] = true
+					f := finding.Finding{Type: finding.TypeDomain, Value: host, Confidence: finding.ConfidenceHigh}
+					f.SetMeta("header", name)
+					f.SetMeta("directive", d.name)
+					if wildcard {
+						f.SetMeta("wildcard", "true")
+					}
+					findings = append(findings, f)
+				}
+			}
+		}
+	}
+	return findings
+}
+
+// Issues returns a header issue finding for every weak setting in the enforced
+// Content-Security-Policy, CORS and HSTS headers of the response to pageURL.
+// Each issue is reported once per origin, e.g. "https://app.example.com".
+func Issues(pageURL string, h http.Header) []finding.Finding {
+	u, err := url.Parse(pageURL)
+	if err != nil || u.Host == "" {
+		return nil
+	}
+	origin := u.Scheme + "://" + strings.ToLower(u.Host)
+
+	var findings []finding.Finding
+	add := func(code, header, message, value string) {
+		f := finding.Finding{Type: finding.TypeHeaderIssue, Value: origin + " " + message, Confidence: finding.ConfidenceHigh}
+		f.SetMeta("issue", code)
+		f.SetMeta("header", header)
+		f.SetMeta("header_value", value)
+		f.SetMeta("url", pageURL)
+		findings = append(findings, f)
+	}
+
+	for _, raw := range h.Values(cspHeader) {
+		for _, policy := range policies([]string{raw}) {
+			script := policy.sources("script-src", "default-src")
+			if script == nil {
+				continue
+			}
+			if contains(script, "'unsafe-inline'") && !hasNonceOrHash(script) {
+				add(IssueUnsafeInline, cspHeader, "CSP script-src allows 'unsafe-inline'", raw)
+			}
+			if contains(script, "'unsafe-eval'") {
+				add(IssueUnsafeEval, cspHeader, "CSP script-src allows 'unsafe-eval'", raw)
+			}
+			if src := wildcardSource(script); src != "" {
+				add(IssueWildcard, cspHeader, "CSP script-src allows any host with "+src, raw)
+			}
+		}
+	}
+
+	switch allowed := strings.TrimSpace(h.Get(corsOriginHeader)); allowed {
+	case "*":
+		message := "CORS allows any origin"
+		if strings.EqualFold(strings.TrimSpace(h.Get(corsCredsHeader)), "true") {
+			message += " with credentials"
+		}
+		add(IssueCORSWildcard, corsOriginHeader, message, allowed)
+	case "null":
+		add(IssueCORSNull, corsOriginHeader, "CORS allows the null origin", allowed)
+	}
+
+	// Browsers ignore HSTS received over plain HTTP
+	if hsts := h.Get(hstsHeader); hsts != "" && u.Scheme == "https" {
+		if age, ok := maxAge(hsts); ok && age < minHSTSAge {
+			add(IssueHSTSShort, hstsHeader, "HSTS max-age is "+strconv.Itoa(age)+" seconds", hsts)
+		}
+	}
+	return findings
+}
+
+// CORS returns a CORS finding when the response to pageURL, with headers h, answers a
+// cross-origin request whose headers are request by echoing its Origin in
+// Access-Control-Allow-Origin, or allows any origin together with credentials. Reflected
+// origins are rated high with credentials and medium without; the request and response
+// header values are recorded in the metadata.
+func CORS(pageURL string, request, h http.Header) []finding.Finding {
+	u, err := url.Parse(pageURL)
+	if err != nil || u.Host == "" {
+		return nil
+	}
+	origin := u.Scheme + "://" + strings.ToLower(u.Host)
+	requestOrigin := strings.TrimSpace(request.Get(originHeader))
+	allowed := strings.TrimSpace(h.Get(corsOriginHeader))
+	creds := strings.TrimSpace(h.Get(corsCredsHeader))
+	withCreds := strings.EqualFold(creds, "true")
+
+	var f finding.Finding
+	switch {
+	case allowed == "*" && withCreds:
+		f = finding.Finding{Type: finding.TypeCORS, Value: origin + " CORS allows any origin with credentials", Confidence: finding.ConfidenceMedium}
+		f.SetMeta("issue", CORSWildcardCredentials)
+	case allowed != "" && allowed != "*" && allowed != "null" && strings.EqualFold(allowed, requestOrigin) && !strings.EqualFold(requestOrigin, origin):
+		f = finding.Finding{Type: finding.TypeCORS, Value: origin + " CORS reflects origin " + requestOrigin, Confidence: finding.ConfidenceMedium}
+		if withCreds {
+			f.Value += " with credentials"
+			f.Confidence = finding.ConfidenceHigh
+		}
+		f.SetMeta("issue", CORSReflectedOrigin)
+		f.SetMeta("origin", requestOrigin)
+	default:
+		return nil
+	}
+	f.SetMeta("allow_origin", allowed)
+	if creds != "" {
+		f.SetMeta("allow_credentials", creds)
+	}
+	f.SetMeta("url", pageURL)
+	return []finding.Finding{f}
+}
+
+// directive is one directive of a policy, e.g. script-src with its source list
+type directive struct {
+	name    string
+	sources []string
+}
+
+// policy is the list of directives of one serialized policy
+type policy []directive
+
+// sources returns the source list of the first of names present in the policy, or nil
+// when none is, so script-src falls back to default-src as it does in browsers
+func (p policy) sources(names ...string) []string {
+	for _, name := range names {
+		for _, d := range p {
+			if d.name == name {
+				if d.sources == nil {
+					return []string{}
+				}
+				return d.sources
+			}
+		}
+	}
+	return nil
+}
+
+// policies parses header values into policies. A value may hold several comma-separated
+// policies; only the first occurrence of each directive within a policy takes effect.
+func policies(values []string) []policy {
+	var out []policy
+	for _, value := range values {
+		for _, serialized := range strings.Split(value, ",") {
+			var p policy
+			seen := make(map[string]bool)
+			for _, part := range strings.Split(serialized, ";") {
+				fields := strings.Fields(part)
+				if len(fields) == 0 {
+					continue
+				}
+				name := strings.ToLower(fields[0])
+				if seen[name] {
+					continue
+				}
+				seen[name] = true
+				p = append(p, directive{name: name, sources: fields[1:]})
+			}
+			if len(p) > 0 {
+				out = append(out, p)
+			}
+		}
+	}
+	return out
+}
+
+// sourceHost returns the host of a host-source such as "https://*.example.com:443/js/",
+// and whether it was a wildcard. Keywords, schemes, nonces, hashes and paths yield "".
+func sourceHost(src string) (string, bool) {
+	if strings.HasPrefix(src, "'") || strings.HasPrefix(src, "/") || strings.HasSuffix(src, ":") {
+		return "", false
+	}
+	if i := strings.Index(src, "://"); i >= 0 {
+		src = src[i+3:]
+	}
+	if i := strings.IndexAny(src, "/?#"); i >= 0 {
+		src = src[:i]
+	}
+	if host, _, err := net.SplitHostPort(src); err == nil {
+		src = host
+	}
+	host := strings.ToLower(strings.TrimSuffix(src, "."))
+	wildcard := strings.HasPrefix(host, "*.")
+	host = strings.TrimPrefix(host, "*.")
+	if !strings.Contains(host, ".") || strings.ContainsAny(host, "*'\"") || net.ParseIP(host) != nil {
+		return "", false
+	}
+	return host, wildcard
+}
+
+// wildcardSource returns the first source in sources that allows scripts from any host:
+// "*" or a bare scheme such as "https:" or "data:"
+func wildcardSource(sources []string) string {
+	for _, src := range sources {
+		switch strings.ToLower(src) {
+		case "*", "http:", "https:", "data:", "blob:":
+			return src
+		}
+	}
+	return ""
+}
+
+// hasNonceOrHash reports whether sources hold a nonce or hash, which makes browsers
+// supporting CSP level 2 ignore 'unsafe-inline'
+func hasNonceOrHash(sources []string) bool {
+	for _, src := range sources {
+		s := strings.ToLower(src)
+		if strings.HasPrefix(s, "'nonce-") || strings.HasPrefix(s, "'sha256-") ||
+			strings.HasPrefix(s, "'sha384-") || strings.HasPrefix(s, "'sha512-") {
+			return true
+		}
+	}
+	return false
+}
+
+// contains reports whether sources hold keyword, ignoring case
+func contains(sources []string, keyword string) bool {
+	for _, src := range sources {
+		if strings.EqualFold(src, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// maxAge returns the max-age directive of a Strict-Transport-Security value
+func maxAge(hsts string) (int, bool) {
+	for _, part := range strings.Split(hsts, ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok || !strings.EqualFold(strings.TrimSpace(name), "max-age") {
+			continue
+		}
+		age, err := strconv.Atoi(strings.Trim(strings.TrimSpace(value), `"`))
+		if err != nil {
+			return 0, false
+		}
+		return age, true
+	}
+	return 0, false
+}
@@ -0,0 +1,202 @@
+package headers
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+func header(pairs ...string) http.Header {
+	h := make(http.Header)
+	for i := 0; i < len(pairs); i += 2 {
+		h.Add(pairs[i], pairs[i+1])
+	}
+	return h
+}
+
+func TestHosts(t *testing.T) {
+	h := header(
+		"Content-Security-Policy", "default-src 'self'; script-src 'self' https://*.cdn.target.com cdn.jsdelivr.net:443/npm/ 'nonce-abc'; connect-src api.target.com wss://ws.target.com; report-uri /csp",
+		"Content-Security-Policy-Report-Only", "img-src data: https://images.target.com",
+	)
+	got := make(map[string]map[string]string)
+	for _, f := range Hosts(h) {
+		got[f.Value] = f.Metadata
+	}
+
+	want := map[string]map[string]string{
+		"cdn.target.com":    {"header": "Content-Security-Policy", "directive": "script-src", "wildcard": "true"},
+		"cdn.jsdelivr.net":  {"header": "Content-Security-Policy", "directive": "script-src"},
+		"api.target.com":    {"header": "Content-Security-Policy", "directive": "connect-src"},
+		"ws.target.com":     {"header": "Content-Security-Policy", "directive": "connect-src"},
+		"images.target.com": {"header": "Content-Security-Policy-Report-Only", "directive": "img-src"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Hosts() = %v, want %v", got, want)
+	}
+}
+
+func TestIssues(t *testing.T) {
+	tests := []struct {
+		name   string
+		url    string
+		header http.Header
+		want   []string
+	}{
+		{
+			name:   "unsafe inline and eval",
+			url:    "https://app.target.com/login",
+			header: header("Content-Security-Policy", "script-src 'self' 'unsafe-inline' 'unsafe-eval'"),
+			want:   []string{IssueUnsafeEval, IssueUnsafeInline},
+		},
+		{
+			name:   "unsafe inline ignored with a nonce",
+			url:    "https://app.target.com/",
+			header: header("Content-Security-Policy", "script-src 'unsafe-inline' 'nonce-r4nd0m'"),
+		},
+		{
+			name:   "default-src fallback with wildcard",
+			url:    "https://app.target.com/",
+			header: header("Content-Security-Policy", "default-src *; style-src 'self'"),
+			want:   []string{IssueWildcard},
+		},
+		{
+			name:   "script-src overrides default-src",
+			url:    "https://app.target.com/",
+			header: header("Content-Security-Policy", "default-src * 'unsafe-inline'; script-src 'self'"),
+		},
+		{
+			name:   "report-only policy is not enforced",
+			url:    "https://app.target.com/",
+			header: header("Content-Security-Policy-Report-Only", "script-src 'unsafe-inline'"),
+		},
+		{
+			name:   "CORS wildcard origin",
+			url:    "https://api.target.com/v1",
+			header: header("Access-Control-Allow-Origin", "*"),
+			want:   []string{IssueCORSWildcard},
+		},
+		{
+			name:   "CORS null origin",
+			url:    "https://api.target.com/v1",
+			header: header("Access-Control-Allow-Origin", "null"),
+			want:   []string{IssueCORSNull},
+		},
+		{
+			name:   "CORS specific origin",
+			url:    "https://api.target.com/v1",
+			header: header("Access-Control-Allow-Origin", "https://app.target.com", "Access-Control-Allow-Credentials", "true"),
+		},
+		{
+			name:   "short HSTS",
+			url:    "https://app.target.com/",
+			header: header("Strict-Transport-Security", "max-age=86400; includeSubDomains"),
+			want:   []string{IssueHSTSShort},
+		},
+		{
+			name:   "long HSTS",
+			url:    "https://app.target.com/",
+			header: header("Strict-Transport-Security", "max-age=31536000"),
+		},
+		{
+			name:   "HSTS over plain HTTP is ignored",
+			url:    "http://app.target.com/",
+			header: header("Strict-Transport-Security", "max-age=0"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, f := range Issues(tt.url, tt.header) {
+				got = append(got, f.Metadata["issue"])
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Issues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIssues_Value(t *testing.T) {
+	h := header("Access-Control-Allow-Origin", "*", "Access-Control-Allow-Credentials", "true")
+	issues := Issues("https://API.target.com/v1/users", h)
+	if len(issues) != 1 {
+		t.Fatalf("Issues() = %v, want one issue", issues)
+	}
+	if want := "https://api.target.com CORS allows any origin with credentials"; issues[0].Value != want {
+		t.Errorf("Value = %q, want %q", issues[0].Value, want)
+	}
+	if issues[0].Metadata["url"] != "https://API.target.com/v1/users" {
+		t.Errorf("url metadata = %q", issues[0].Metadata["url"])
+	}
+}
+
+func TestCORS(t *testing.T) {
+	tests := []struct {
+		name    string
+		request http.Header
+		header  http.Header
+		want    string
+		wantCon finding.Confidence
+	}{
+		{
+			name:    "reflected origin with credentials",
+			request: header("Origin", "https://evil.com"),
+			header:  header("Access-Control-Allow-Origin", "https://evil.com", "Access-Control-Allow-Credentials", "true"),
+			want:    "https://api.target.com CORS reflects origin https://evil.com with credentials",
+			wantCon: finding.ConfidenceHigh,
+		},
+		{
+			name:    "reflected origin",
+			request: header("Origin", "https://evil.com"),
+			header:  header("Access-Control-Allow-Origin", "https://evil.com"),
+			want:    "https://api.target.com CORS reflects origin https://evil.com",
+			wantCon: finding.ConfidenceMedium,
+		},
+		{
+			name:    "wildcard with credentials",
+			request: http.Header{},
+			header:  header("Access-Control-Allow-Origin", "*", "Access-Control-Allow-Credentials", "true"),
+			want:    "https://api.target.com CORS allows any origin with credentials",
+			wantCon: finding.ConfidenceMedium,
+		},
+		{
+			name:    "wildcard without credentials",
+			request: header("Origin", "https://evil.com"),
+			header:  header("Access-Control-Allow-Origin", "*"),
+		},
+		{
+			name:    "same origin",
+			request: header("Origin", "https://api.target.com"),
+			header:  header("Access-Control-Allow-Origin", "https://api.target.com", "Access-Control-Allow-Credentials", "true"),
+		},
+		{
+			name:    "fixed allowed origin",
+			request: header("Origin", "https://evil.com"),
+			header:  header("Access-Control-Allow-Origin", "https://app.target.com"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CORS("https://api.target.com/v1/me", tt.request, tt.header)
+			if tt.want == "" {
+				if len(got) != 0 {
+					t.Errorf("CORS() = %v, want none", got)
+				}
+				return
+			}
+			if len(got) != 1 || got[0].Value != tt.want || got[0].Confidence != tt.wantCon || got[0].Type != finding.TypeCORS {
+				t.Fatalf("CORS() = %v, want %q (%s)", got, tt.want, tt.wantCon)
+			}
+			if got[0].Metadata["allow_origin"] != tt.header.Get("Access-Control-Allow-Origin") {
+				t.Errorf("allow_origin metadata = %q", got[0].Metadata["allow_origin"])
+			}
+		})
+	}
+}
@@ -0,0 +1,295 @@
+// Package httpclient provides the HTTP client shared by every feature that makes network requests.
+// It adds per-host delays and rate limiting, optional robots.txt compliance, retries with exponential
+// backoff, proxy support and custom request headers on top of net/http.
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultUserAgent identifies urlsluice to the servers it contacts
+	DefaultUserAgent = "urlsluice"
+	// defaultTimeout bounds each request when no timeout is configured
+	defaultTimeout = 10 * time.Second
+	// defaultBackoff is the delay before the first retry
+	defaultBackoff = 500 * time.Millisecond
+	// defaultMaxRedirects is the number of redirects followed when no limit is configured
+	defaultMaxRedirects = 10
+)
+
+// ErrDisallowed is returned when robots.txt forbids fetching a URL
+var ErrDisallowed = errors.New("disallowed by robots.txt")
+
+// Doer sends HTTP requests; it is satisfied by *http.Client and *Client. Packages making
+// requests take a Doer so that callers choose how requests are sent, and send them with Do,
+// which falls back to http.DefaultClient when none is given.
+type Doer interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+// Do sends req with client, or with http.DefaultClient when client is nil
+func Do(client Doer, req *http.Request) (*http.Response, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return client.Do(req)
+}
+
+// Options configures a Client
+type Options struct {
+	// Timeout bounds each request attempt (default 10s)
+	Timeout time.Duration
+	// UserAgent is sent with every request (default "urlsluice")
+	UserAgent string
+	// Headers are added to every request
+	Headers http.Header
+	// Proxy is an http://, https:// or socks5:// proxy URL. When empty the standard
+	// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables are honored.
+	Proxy string
+	// Insecure disables TLS certificate verification, e.g. for intercepting proxies
+	Insecure bool
+	// Retries is the number of additional attempts for failed requests
+	Retries int
+	// Backoff is the delay before the first retry; it doubles on every attempt (default 500ms)
+	Backoff time.Duration
+	// HostDelay is the minimum interval between two requests to the same host
+	HostDelay time.Duration
+	// RateLimiter, when set, limits the request rate overall and per host; share one
+	// RateLimiter between Clients to apply a single limit to all of them
+	RateLimiter *RateLimiter
+	// RespectRobots skips URLs disallowed by the host's robots.txt
+	RespectRobots bool
+	// MaxBodySize cuts response bodies off after this many bytes (0 means no limit)
+	MaxBodySize int64
+	// MaxRedirects is the number of redirects followed before a request fails (default 10);
+	// when negative, redirects are not followed and the redirect response is returned
+	MaxRedirects int
+	// DialContext, when set, opens the connections of requests instead of a net.Dialer, e.g.
+	// to refuse connections to some addresses
+	DialContext func(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+// Client performs HTTP requests according to Options.
+// It is safe for concurrent use.
+type Client struct {
+	http    *http.Client
+	opts    Options
+	limiter *hostLimiter
+	robots  *robotsCache
+}
+
+// New creates a Client, returning an error if the proxy URL is invalid
+func New(opts Options) (*Client, error) {
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultTimeout
+	}
+	if opts.UserAgent == "" {
+		opts.UserAgent = DefaultUserAgent
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = defaultBackoff
+	}
+	if opts.Retries < 0 {
+		return nil, fmt.Errorf("retries must not be negative")
+	}
+	if opts.MaxBodySize < 0 {
+		return nil, fmt.Errorf("max body size must not be negative")
+	}
+	if opts.MaxRedirects == 0 {
+		opts.MaxRedirects = defaultMaxRedirects
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	if opts.DialContext != nil {
+		transport.DialContext = opts.DialContext
+	}
+	if opts.Insecure {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} // #nosec G402 -- explicit opt-in via -insecure
+	}
+	if opts.Proxy != "" {
+		proxyURL, err := url.Parse(opts.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		switch proxyURL.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return nil, fmt.Errorf("unsupported proxy scheme %q: must be http, https or socks5", proxyURL.Scheme)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	c := &Client{
+		http:    &http.Client{Timeout: opts.Timeout, Transport: transport, CheckRedirect: checkRedirect(opts.MaxRedirects)},
+		opts:    opts,
+		limiter: newHostLimiter(opts.HostDelay),
+	}
+	if opts.RespectRobots {
+		c.robots = newRobotsCache(opts.UserAgent)
+	}
+	return c, nil
+}
+
+// Get issues a GET request for rawURL
+func (c *Client) Get(ctx context.Context, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(req)
+}
+
+// Do sends req, applying headers, robots.txt rules, rate limiting and retries.
+// Requests are retried on network errors, 429 and 5xx responses when they have no body
+// or the body can be replayed.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	if c.robots != nil {
+		allowed, err := c.robots.allowed(ctx, c, req.URL)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, fmt.Errorf("%s: %w", req.URL, ErrDisallowed)
+		}
+	}
+
+	for name, values := range c.opts.Headers {
+		for _, v := range values {
+			req.Header.Add(name, v)
+		}
+	}
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", c.opts.UserAgent)
+	}
+
+	backoff := c.opts.Backoff
+	for attempt := 0; ; attempt++ {
+		if err := c.limiter.wait(ctx, req.URL.Host); err != nil {
+			return nil, err
+		}
+		if c.opts.RateLimiter != nil {
+			if err := c.opts.RateLimiter.Wait(ctx, req.URL.Host); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := c.http.Do(req)
+		if attempt >= c.opts.Retries || !retryable(resp, err) || !replayable(req) {
+			if resp != nil && c.opts.MaxBodySize > 0 {
+				resp.Body = limitedBody{io.LimitReader(resp.Body, c.opts.MaxBodySize), resp.Body}
+			}
+			return resp, err
+		}
+
+		delay := backoff
+		if resp != nil {
+			if after := retryAfter(resp); after > 0 {
+				delay = after
+			}
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+		backoff *= 2
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+	}
+}
+
+// checkRedirect stops following redirects after max of them, or at once when max is negative
+func checkRedirect(max int) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if max < 0 {
+			return http.ErrUseLastResponse
+		}
+		if len(via) > max {
+			return fmt.Errorf("stopped after %d redirects", max)
+		}
+		return nil
+	}
+}
+
+// limitedBody is a response body cut off after a size limit, so hostile or misbehaving
+// servers cannot exhaust memory; closing it closes the underlying body
+type limitedBody struct {
+	io.Reader
+	io.Closer
+}
+
+// retryable reports whether a request outcome is worth retrying
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// replayable reports whether the request body can be sent again
+func replayable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// retryAfter parses a Retry-After header given in seconds
+func retryAfter(resp *http.Response) time.Duration {
+	secs, err := strconv.Atoi(strings.TrimSpace(resp.Header.Get("Retry-After")))
+	if err != nil || secs <= 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
+
+// ParseSize parses a size in bytes given as a number with an optional KB, MB or GB suffix
+// (powers of 1024), such as "5MB"
+func ParseSize(s string) (int64, error) {
+	number := strings.ToUpper(strings.TrimSpace(s))
+	unit := int64(1)
+	for _, suffix := range []struct {
+		name string
+		size int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(number, suffix.name) {
+			number, unit = strings.TrimSpace(strings.TrimSuffix(number, suffix.name)), suffix.size
+			break
+		}
+	}
+	n, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q: must be a number of bytes with an optional KB, MB or GB suffix", s)
+	}
+	return n * unit, nil
+}
+
+// ParseHeader splits a "Name: value" header specification
+func ParseHeader(spec string) (string, string, error) {
+	name, value, ok := strings.Cut(spec, ":")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return "", "", fmt.Errorf("invalid header %q: must be in \"Name: value\" format", spec)
+	}
+	return name, strings.TrimSpace(value), nil
+}
@@ -0,0 +1,278 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_HeadersAndUserAgent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s|%s", r.Header.Get("User-Agent"), r.Header.Get("X-Api-Key"))
+	}))
+	defer srv.Close()
+
+	c, err := New(Options{UserAgent: "custom/1.0", Headers: http.Header{"X-Api-Key": {"secret"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := c.Get(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var body [64]byte
+	n, _ := resp.Body.Read(body[:])
+	if got := string(body[:n]); got != "custom/1.0|secret" {
+		t.Errorf("server saw %q, want %q", got, "custom/1.0|secret")
+	}
+}
+
+func TestClient_Retries(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name       string
+		retries    int
+		wantStatus int
+		wantCalls  int32
+	}{
+		{"no retries", 0, http.StatusServiceUnavailable, 1},
+		{"retries until success", 3, http.StatusOK, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&calls, 0)
+			c, err := New(Options{Retries: tt.retries, Backoff: time.Millisecond})
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := c.Get(context.Background(), srv.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got := atomic.LoadInt32(&calls); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestClient_Robots(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			fmt.Fprint(w, "User-agent: *\nDisallow: /private\n")
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c, err := New(Options{RespectRobots: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := c.Get(context.Background(), srv.URL+"/public")
+	if err != nil {
+		t.Fatalf("public path: unexpected error %v", err)
+	}
+	resp.Body.Close()
+
+	if _, err := c.Get(context.Background(), srv.URL+"/private/page"); !errors.Is(err, ErrDisallowed) {
+		t.Errorf("private path: error = %v, want ErrDisallowed", err)
+	}
+}
+
+func TestClient_HostDelay(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	c, err := New(Options{HostDelay: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		resp, err := c.Get(context.Background(), srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("3 requests took %v, want at least 100ms", elapsed)
+	}
+}
+
+func TestNew_InvalidProxy(t *testing.T) {
+	if _, err := New(Options{Proxy: "ftp://127.0.0.1:21"}); err == nil {
+		t.Error("New() expected error for ftp proxy")
+	}
+	if _, err := New(Options{Proxy: "socks5://127.0.0.1:9050"}); err != nil {
+		t.Errorf("New() unexpected error for socks5 proxy: %v", err)
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	name, value, err := ParseHeader("Authorization: Bearer abc")
+	if err != nil || name != "Authorization" || value != "Bearer abc" {
+		t.Errorf("ParseHeader() = %q, %q, %v", name, value, err)
+	}
+	if _, _, err := ParseHeader("no-colon"); err == nil {
+		t.Error("ParseHeader(no-colon) expected error")
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := map[string]int64{"512": 512, "64KB": 64 << 10, " 5mb ": 5 << 20, "2GB": 2 << 30, "0": 0}
+	for s, want := range tests {
+		if got, err := ParseSize(s); err != nil || got != want {
+			t.Errorf("ParseSize(%q) = %d, %v, want %d", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"", "-1", "5TB", "lots"} {
+		if _, err := ParseSize(s); err == nil {
+			t.Errorf("ParseSize(%q) expected error", s)
+		}
+	}
+}
+
+func TestClient_Proxy(t *testing.T) {
+	var proxied int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A forward proxy receives the absolute target URL
+		if r.URL.Host == "target.invalid" {
+			atomic.AddInt32(&proxied, 1)
+		}
+		fmt.Fprint(w, "via proxy")
+	}))
+	defer proxy.Close()
+
+	c, err := New(Options{Proxy: proxy.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := c.Get(context.Background(), "http://target.invalid/page")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if atomic.LoadInt32(&proxied) != 1 {
+		t.Error("request was not routed through the proxy")
+	}
+}
+
+func TestClient_Insecure(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	strict, err := New(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := strict.Get(context.Background(), srv.URL); err == nil {
+		t.Error("expected certificate error without Insecure")
+	}
+
+	insecure, err := New(Options{Insecure: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := insecure.Get(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("Insecure client: unexpected error %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestClient_MaxBodySize(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, strings.Repeat("a", 1000))
+	}))
+	defer srv.Close()
+
+	c, err := New(Options{MaxBodySize: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.Get(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(body) != 100 {
+		t.Errorf("read %d bytes, want 100", len(body))
+	}
+}
+
+func TestClient_MaxRedirects(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/"))
+		if n < 3 {
+			http.Redirect(w, r, "/"+strconv.Itoa(n+1), http.StatusFound)
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name         string
+		maxRedirects int
+		wantStatus   int
+		wantErr      bool
+	}{
+		{name: "default", maxRedirects: 0, wantStatus: http.StatusOK},
+		{name: "exceeded", maxRedirects: 2, wantErr: true},
+		{name: "not followed", maxRedirects: -1, wantStatus: http.StatusFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := New(Options{MaxRedirects: tt.maxRedirects})
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := c.Get(context.Background(), srv.URL+"/0")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// hostLimiter enforces a minimum interval between requests to the same host
+type hostLimiter struct {
+	delay time.Duration
+	mu    sync.Mutex
+	next  map[string]time.Time
+}
+
+func newHostLimiter(delay time.Duration) *hostLimiter {
+	return &hostLimiter{delay: delay, next: make(map[string]time.Time)}
+}
+
+// wait blocks until a request to host may be sent or ctx is done
+func (l *hostLimiter) wait(ctx context.Context, host string) error {
+	if l.delay <= 0 {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next[host]
+	if at.Before(now) {
+		at = now
+	}
+	l.next[host] = at.Add(l.delay)
+	l.mu.Unlock()
+
+	if d := time.Until(at); d > 0 {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return ctx.Err()
+}
+
+// Rate is a number of requests allowed per interval; the zero value means no limit
+type Rate struct {
+	Requests int
+	Per      time.Duration
+}
+
+// ParseRate parses a rate such as "10/s", "100/m", "1/2s" or "5" (per second)
+func ParseRate(s string) (Rate, error) {
+	count, per, found := strings.Cut(strings.TrimSpace(s), "/")
+	requests, err := strconv.Atoi(strings.TrimSpace(count))
+	if err != nil || requests <= 0 {
+		return Rate{}, fmt.Errorf("invalid rate %q: must be a positive number of requests, e.g. 10/s", s)
+	}
+	rate := Rate{Requests: requests, Per: time.Second}
+	if !found {
+		return rate, nil
+	}
+	switch per = strings.TrimSpace(per); per {
+	case "s":
+	case "m":
+		rate.Per = time.Minute
+	case "h":
+		rate.Per = time.Hour
+	default:
+		if rate.Per, err = time.ParseDuration(per); err != nil || rate.Per <= 0 {
+			return Rate{}, fmt.Errorf("invalid rate %q: the interval must be s, m, h or a duration such as 2s", s)
+		}
+	}
+	return rate, nil
+}
+
+// String formats the rate as accepted by ParseRate
+func (r Rate) String() string {
+	if r.Requests <= 0 {
+		return ""
+	}
+	switch r.Per {
+	case time.Second:
+		return fmt.Sprintf("%d/s", r.Requests)
+	case time.Minute:
+		return fmt.Sprintf("%d/m", r.Requests)
+	case time.Hour:
+		return fmt.Sprintf("%d/h", r.Requests)
+	}
+	return fmt.Sprintf("%d/%s", r.Requests, r.Per)
+}
+
+// RateLimiter spreads requests with token buckets: one shared by every host and one for each
+// host. Each bucket holds up to one interval's worth of requests, so short bursts are allowed
+// while the average stays within the rate. A RateLimiter may be shared by several Clients so
+// that one limit governs all of them.
+type RateLimiter struct {
+	global  *tokenBucket
+	perHost Rate
+	mu      sync.Mutex
+	hosts   map[string]*tokenBucket
+}
+
+// NewRateLimiter creates a RateLimiter; either rate may be zero to leave it unlimited
+func NewRateLimiter(global, perHost Rate) *RateLimiter {
+	return &RateLimiter{global: newTokenBucket(global), perHost: perHost, hosts: make(map[string]*tokenBucket)}
+}
+
+// Wait blocks until a request to host is allowed by both buckets or ctx is done
+func (l *RateLimiter) Wait(ctx context.Context, host string) error {
+	l.mu.Lock()
+	now := time.Now()
+	delay := l.global.reserve(now)
+	if l.perHost.Requests > 0 {
+		bucket, ok := l.hosts[host]
+		if !ok {
+			bucket = newTokenBucket(l.perHost)
+			l.hosts[host] = bucket
+		}
+		if d := bucket.reserve(now); d > delay {
+			delay = d
+		}
+	}
+	l.mu.Unlock()
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return ctx.Err()
+}
+
+// tokenBucket refills at a fixed rate up to its capacity; a nil bucket never limits
+type tokenBucket struct {
+	capacity float64
+	perSec   float64
+	tokens   float64
+	last     time.Time
+}
+
+func newTokenBucket(rate Rate) *tokenBucket {
+	if rate.Requests <= 0 || rate.Per <= 0 {
+		return nil
+	}
+	capacity := float64(rate.Requests)
+	return &tokenBucket{capacity: capacity, perSec: capacity / rate.Per.Seconds(), tokens: capacity}
+}
+
+// reserve takes a token and returns how long to wait until it is available. Tokens taken in
+// advance leave the bucket negative, so waiting callers are spaced out in order.
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	if b == nil {
+		return 0
+	}
+	if !b.last.IsZero() {
+		b.tokens += now.Sub(b.last).Seconds() * b.perSec
+		if b.tokens > b.capacity {
+			b.tokens = b.capacity
+		}
+	}
+	b.last = now
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.perSec * float64(time.Second))
+}
@@ -0,0 +1,70 @@
+package httpclient
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Rate
+		wantErr bool
+	}{
+		{in: "10/s", want: Rate{Requests: 10, Per: time.Second}},
+		{in: "100/m", want: Rate{Requests: 100, Per: time.Minute}},
+		{in: "1/2s", want: Rate{Requests: 1, Per: 2 * time.Second}},
+		{in: "5", want: Rate{Requests: 5, Per: time.Second}},
+		{in: "0/s", wantErr: true},
+		{in: "ten/s", wantErr: true},
+		{in: "10/fortnight", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRate(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRate(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseRate(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTokenBucket_Reserve(t *testing.T) {
+	b := newTokenBucket(Rate{Requests: 2, Per: time.Second})
+	now := time.Now()
+
+	// The full bucket allows a burst of two, then spaces requests by the refill interval
+	want := []time.Duration{0, 0, 500 * time.Millisecond, time.Second}
+	for i, w := range want {
+		if got := b.reserve(now); got != w {
+			t.Errorf("reserve #%d = %v, want %v", i+1, got, w)
+		}
+	}
+	// After the backlog has drained and the bucket refilled, a burst is allowed again
+	later := now.Add(3 * time.Second)
+	if got := b.reserve(later); got != 0 {
+		t.Errorf("reserve after refill = %v, want 0", got)
+	}
+}
+
+func TestRateLimiter_PerHost(t *testing.T) {
+	l := NewRateLimiter(Rate{}, Rate{Requests: 1, Per: time.Hour})
+	ctx := context.Background()
+
+	if err := l.Wait(ctx, "a.target.com"); err != nil {
+		t.Fatalf("first request to a.target.com: %v", err)
+	}
+	if err := l.Wait(ctx, "b.target.com"); err != nil {
+		t.Fatalf("first request to b.target.com: %v", err)
+	}
+
+	// The second request to the same host would wait an hour
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx, "a.target.com"); err == nil {
+		t.Error("second request to a.target.com was not limited")
+	}
+}
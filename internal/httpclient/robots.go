@@ -0,0 +1,142 @@
+package httpclient
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// maxRobotsSize caps how much of a robots.txt file is read (512KB)
+const maxRobotsSize = 512 * 1024
+
+// robotsRules holds the Allow and Disallow path prefixes that apply to our user agent
+type robotsRules struct {
+	allow    []string
+	disallow []string
+}
+
+// allowed applies the longest matching rule; Allow wins ties
+func (r *robotsRules) allowed(path string) bool {
+	best, allow := -1, true
+	for _, p := range r.disallow {
+		if strings.HasPrefix(path, p) && len(p) > best {
+			best, allow = len(p), false
+		}
+	}
+	for _, p := range r.allow {
+		if strings.HasPrefix(path, p) && len(p) >= best {
+			best, allow = len(p), true
+		}
+	}
+	return allow
+}
+
+// robotsCache fetches and caches robots.txt rules per scheme and host
+type robotsCache struct {
+	agent string
+	mu    sync.Mutex
+	rules map[string]*robotsRules
+}
+
+func newRobotsCache(userAgent string) *robotsCache {
+	agent := strings.ToLower(strings.SplitN(userAgent, "/", 2)[0])
+	return &robotsCache{agent: agent, rules: make(map[string]*robotsRules)}
+}
+
+// allowed reports whether u may be fetched. A missing or unreadable robots.txt allows everything.
+func (c *robotsCache) allowed(ctx context.Context, client *Client, u *url.URL) (bool, error) {
+	if u.Path == "/robots.txt" {
+		return true, nil
+	}
+	key := u.Scheme + "://" + u.Host
+
+	c.mu.Lock()
+	rules, ok := c.rules[key]
+	c.mu.Unlock()
+
+	if !ok {
+		rules = &robotsRules{}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, key+"/robots.txt", nil)
+		if err != nil {
+			return false, err
+		}
+		resp, err := client.Do(req)
+		if err != nil && ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+		if err == nil {
+			if resp.StatusCode == http.StatusOK {
+				rules = parseRobots(io.LimitReader(resp.Body, maxRobotsSize), c.agent)
+			}
+			resp.Body.Close()
+		}
+
+		c.mu.Lock()
+		c.rules[key] = rules
+		c.mu.Unlock()
+	}
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	return rules.allowed(path), nil
+}
+
+// parseRobots extracts the rules of the group matching agent, falling back to the "*" group
+func parseRobots(r io.Reader, agent string) *robotsRules {
+	groups := make(map[string]*robotsRules)
+	var current []string
+	inRules := false
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		field, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		field = strings.ToLower(strings.TrimSpace(field))
+		value = strings.TrimSpace(value)
+
+		switch field {
+		case "user-agent":
+			if inRules {
+				current = nil
+				inRules = false
+			}
+			ua := strings.ToLower(value)
+			current = append(current, ua)
+			if groups[ua] == nil {
+				groups[ua] = &robotsRules{}
+			}
+		case "allow", "disallow":
+			inRules = true
+			if value == "" {
+				continue
+			}
+			for _, ua := range current {
+				if field == "allow" {
+					groups[ua].allow = append(groups[ua].allow, value)
+				} else {
+					groups[ua].disallow = append(groups[ua].disallow, value)
+				}
+			}
+		}
+	}
+
+	if rules, ok := groups[agent]; ok {
+		return rules
+	}
+	if rules, ok := groups["*"]; ok {
+		return rules
+	}
+	return &robotsRules{}
+}
@@ -0,0 +1,35 @@
+package httpclient
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseRobots(t *testing.T) {
+	robots := `# comment
+User-agent: urlsluice
+Disallow: /admin
+Allow: /admin/public
+
+User-agent: *
+Disallow: /
+`
+
+	tests := []struct {
+		agent string
+		path  string
+		want  bool
+	}{
+		{"urlsluice", "/", true},
+		{"urlsluice", "/admin/users", false},
+		{"urlsluice", "/admin/public/docs", true},
+		{"otherbot", "/anything", false},
+	}
+
+	for _, tt := range tests {
+		rules := parseRobots(strings.NewReader(robots), tt.agent)
+		if got := rules.allowed(tt.path); got != tt.want {
+			t.Errorf("agent %q allowed(%q) = %v, want %v", tt.agent, tt.path, got, tt.want)
+		}
+	}
+}
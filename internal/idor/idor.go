@@ -0,0 +1,106 @@
+// Package idor generates candidate URLs for insecure direct object reference testing by
+// replacing numeric ID query parameters with neighbouring and commonly used IDs.
+// Candidates are only guesses: whether a response exposes another user's object has to be
+// judged manually, replaying each request with a session that should not have access.
+package idor
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Offsets are added to an ID to reach objects created just before or after it
+var Offsets = []int64{-1, 1, -10, 10}
+
+// CommonIDs are IDs that usually belong to the first, administrative or test objects
+var CommonIDs = []int64{0, 1, 2, 100, 1000}
+
+// maxIDLength limits numeric IDs to 12 digits; longer numbers are usually timestamps or snowflakes
+const maxIDLength = 12
+
+// idNames are parameter names that refer to an object without containing "id"
+var idNames = map[string]bool{
+	"account": true, "acct": true, "customer": true, "doc": true, "document": true,
+	"file": true, "invoice": true, "item": true, "no": true, "num": true, "number": true,
+	"order": true, "product": true, "profile": true, "ref": true, "uid": true, "user": true,
+}
+
+// Candidate is a URL with one numeric ID parameter replaced
+type Candidate struct {
+	URL      string
+	Param    string
+	Original string
+	Value    string
+}
+
+// IsNumericID reports whether a query parameter looks like a sequential numeric object ID:
+// an id-like name such as "id", "user_id", "orderId" or "account" and a decimal value
+// without leading zeros
+func IsNumericID(name, value string) bool {
+	if value == "" || len(value) > maxIDLength || (len(value) > 1 && value[0] == '0') {
+		return false
+	}
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	lower := strings.ToLower(name)
+	if idNames[lower] || lower == "id" || lower == "ids" {
+		return true
+	}
+	// Suffixes are matched case-sensitively on camelCase names so words like "paid" or "valid" are not IDs
+	return strings.HasSuffix(lower, "_id") || strings.HasSuffix(lower, "-id") ||
+		strings.HasSuffix(name, "Id") || strings.HasSuffix(name, "ID")
+}
+
+// Candidates returns a candidate URL for every numeric ID parameter of rawURL, combined
+// with each offset and common ID. Negative IDs and the original value are skipped.
+// The order of the remaining query parameters is preserved.
+func Candidates(rawURL string) []Candidate {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.RawQuery == "" {
+		return nil
+	}
+
+	var candidates []Candidate
+	pairs := strings.Split(u.RawQuery, "&")
+	for i, pair := range pairs {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || !IsNumericID(name, value) {
+			continue
+		}
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		seen := map[int64]bool{id: true}
+		var values []int64
+		for _, offset := range Offsets {
+			values = append(values, id+offset)
+		}
+		values = append(values, CommonIDs...)
+		for _, v := range values {
+			if v < 0 || seen[v] {
+				continue
+			}
+			seen[v] = true
+
+			mutated := make([]string, len(pairs))
+			copy(mutated, pairs)
+			mutated[i] = name + "=" + strconv.FormatInt(v, 10)
+			c := *u
+			c.RawQuery = strings.Join(mutated, "&")
+			candidates = append(candidates, Candidate{
+				URL:      c.String(),
+				Param:    name,
+				Original: value,
+				Value:    strconv.FormatInt(v, 10),
+			})
+		}
+	}
+	return candidates
+}
@@ -0,0 +1,64 @@
+package idor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIsNumericID(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  bool
+	}{
+		{"id", "42", true},
+		{"ID", "42", true},
+		{"user_id", "1337", true},
+		{"orderId", "7", true},
+		{"accountID", "7", true},
+		{"invoice", "1001", true},
+		{"id", "abc", false},
+		{"id", "042", false},
+		{"id", "", false},
+		{"id", "1700000000123", false},
+		{"page", "2", false},
+		{"paid", "1", false},
+		{"valid", "1", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsNumericID(tt.name, tt.value); got != tt.want {
+			t.Errorf("IsNumericID(%q, %q) = %v, want %v", tt.name, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestCandidates(t *testing.T) {
+	got := Candidates("https://shop.example.com/orders?sort=asc&order_id=2&page=3")
+	var urls []string
+	for _, c := range got {
+		if c.Param != "order_id" || c.Original != "2" {
+			t.Errorf("candidate %+v has wrong parameter", c)
+		}
+		urls = append(urls, c.URL)
+	}
+	want := []string{
+		"https://shop.example.com/orders?sort=asc&order_id=1&page=3",
+		"https://shop.example.com/orders?sort=asc&order_id=3&page=3",
+		"https://shop.example.com/orders?sort=asc&order_id=12&page=3",
+		"https://shop.example.com/orders?sort=asc&order_id=0&page=3",
+		"https://shop.example.com/orders?sort=asc&order_id=100&page=3",
+		"https://shop.example.com/orders?sort=asc&order_id=1000&page=3",
+	}
+	if !reflect.DeepEqual(urls, want) {
+		t.Errorf("Candidates() = %v, want %v", urls, want)
+	}
+}
+
+func TestCandidates_NoIDs(t *testing.T) {
+	for _, raw := range []string{"https://example.com/", "https://example.com/?q=shoes", "://bad"} {
+		if got := Candidates(raw); len(got) != 0 {
+			t.Errorf("Candidates(%q) = %v, want none", raw, got)
+		}
+	}
+}
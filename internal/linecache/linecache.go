@@ -0,0 +1,77 @@
+// Package linecache skips input lines identical to a line seen recently, so the extractors do
+// not run again and again on the repeated lines that make up much of crawl dumps and archive
+// exports such as wayback URL lists.
+//
+// Lines are remembered by a 64-bit hash rather than their text, so memory stays bounded by
+// the capacity whatever the length of the lines.
+package linecache
+
+import (
+	"bytes"
+	"container/list"
+	"hash/maphash"
+)
+
+// Cache remembers the most recently seen distinct lines, up to its capacity
+type Cache struct {
+	capacity int
+	seed     maphash.Seed
+	order    *list.List // of uint64 hashes, most recently seen first
+	lines    map[uint64]*list.Element
+
+	seen, skipped int
+}
+
+// New returns a Cache remembering up to capacity lines
+func New(capacity int) *Cache {
+	return &Cache{
+		capacity: capacity,
+		seed:     maphash.MakeSeed(),
+		order:    list.New(),
+		lines:    make(map[uint64]*list.Element, capacity),
+	}
+}
+
+// Seen records line and reports whether it is one of the lines remembered. Lines that are
+// blank are never remembered.
+func (c *Cache) Seen(line []byte) bool {
+	if len(bytes.TrimSpace(line)) == 0 {
+		return false
+	}
+	c.seen++
+	h := maphash.Bytes(c.seed, line)
+	if e, ok := c.lines[h]; ok {
+		c.order.MoveToFront(e)
+		c.skipped++
+		return true
+	}
+	c.lines[h] = c.order.PushFront(h)
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.lines, oldest.Value.(uint64))
+	}
+	return false
+}
+
+// Blank returns data with the content of every line seen recently removed. Line breaks are
+// kept so the line numbers of findings still refer to the original input.
+func (c *Cache) Blank(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	for len(data) > 0 {
+		line, rest, found := bytes.Cut(data, []byte("\n"))
+		if !c.Seen(bytes.TrimSuffix(line, []byte("\r"))) {
+			out = append(out, line...)
+		}
+		if found {
+			out = append(out, '\n')
+		}
+		data = rest
+	}
+	return out
+}
+
+// Counts returns the number of non-blank lines seen and how many of them were skipped
+func (c *Cache) Counts() (seen, skipped int) {
+	return c.seen, c.skipped
+}
@@ -0,0 +1,20 @@
+package linecache
+
+import "testing"
+
+func TestCache_Blank(t *testing.T) {
+	c := New(2)
+	input := "https://a.target.com/\nhttps://b.target.com/\r\nhttps://a.target.com/\n\n\nhttps://c.target.com/\nhttps://b.target.com/\nhttps://a.target.com/"
+	// Seeing a again makes it more recent than b, so c pushes b out of the two remembered lines
+	want := "https://a.target.com/\nhttps://b.target.com/\r\n\n\n\nhttps://c.target.com/\nhttps://b.target.com/\nhttps://a.target.com/"
+	if got := string(c.Blank([]byte(input))); got != want {
+		t.Errorf("Blank() = %q, want %q", got, want)
+	}
+	// Lines are remembered across inputs
+	if got := string(c.Blank([]byte("https://a.target.com/\n"))); got != "\n" {
+		t.Errorf("Blank() of a line from the previous input = %q", got)
+	}
+	if seen, skipped := c.Counts(); seen != 7 || skipped != 2 {
+		t.Errorf("Counts() = %d, %d, want 7, 2", seen, skipped)
+	}
+}
@@ -0,0 +1,182 @@
+// Package locale recognizes locale tags such as "en-US" or "fr" in URL paths and the
+// translation files of i18n frameworks, so localized copies of a page can be told apart
+// from different pages.
+package locale
+
+import (
+	"net/url"
+	"path"
+	"strings"
+)
+
+// languages are the ISO 639-1 codes of the languages sites are commonly translated into.
+// A bare two-letter path segment is only read as a locale when it is one of them, since
+// segments such as "js", "id" or "go" are common path names.
+var languages = map[string]bool{
+	"ar": true, "bg": true, "bn": true, "ca": true, "cs": true, "cy": true, "da": true,
+	"de": true, "el": true, "en": true, "es": true, "et": true, "eu": true, "fa": true,
+	"fi": true, "fr": true, "ga": true, "gl": true, "he": true, "hi": true, "hr": true,
+	"hu": true, "hy": true, "is": true, "it": true, "ja": true, "ka": true, "kk": true,
+	"ko": true, "lt": true, "lv": true, "mk": true, "mn": true, "nb": true, "nl": true,
+	"nn": true, "pl": true, "pt": true, "ro": true, "ru": true, "sk": true, "sl": true,
+	"sq": true, "sr": true, "sv": true, "sw": true, "ta": true, "th": true, "tr": true,
+	"uk": true, "ur": true, "uz": true, "vi": true, "zh": true,
+}
+
+// regionalLanguages are further languages that are recognized with a region or script, as
+// in "id-ID" or "fil-PH", where the bare code would too often be a path name
+var regionalLanguages = map[string]bool{
+	"af": true, "az": true, "bs": true, "fil": true, "gu": true, "id": true, "km": true,
+	"kn": true, "lo": true, "ml": true, "mr": true, "ms": true, "ne": true, "pa": true,
+	"si": true, "te": true, "tl": true, "yue": true, "zu": true,
+}
+
+// scripts are the ISO 15924 scripts that appear in locale tags, as in "zh-Hant"
+var scripts = map[string]bool{"arab": true, "cyrl": true, "hans": true, "hant": true, "latn": true}
+
+// resourceDirs are directory names that hold translation files
+var resourceDirs = map[string]bool{
+	"i18n": true, "l10n": true, "lang": true, "langs": true, "languages": true,
+	"locale": true, "locales": true, "translations": true, "messages": true,
+}
+
+// resourceExts are the extensions of translation catalogs, which are i18n resources
+// wherever they are found
+var resourceExts = map[string]bool{
+	".po": true, ".pot": true, ".mo": true, ".xlf": true, ".xliff": true, ".ftl": true, ".arb": true,
+}
+
+// dataExts are the extensions of translation bundles named after their locale, such as
+// "fr.json" or "messages_de.properties"
+var dataExts = map[string]bool{
+	".json": true, ".js": true, ".yml": true, ".yaml": true, ".properties": true, ".resx": true, ".strings": true,
+}
+
+// Tag returns the normalized locale tag, such as "en-us" or "fr", of a path segment like
+// "en-US", "en_us" or "fr". Language and region must be separated by "-" or "_"; scripts
+// ("zh-Hant") and numeric regions ("es-419") are accepted.
+func Tag(segment string) (string, bool) {
+	s := strings.ToLower(segment)
+	lang, region, hasRegion := strings.Cut(strings.ReplaceAll(s, "_", "-"), "-")
+	if !hasRegion {
+		if !languages[s] {
+			return "", false
+		}
+		return s, true
+	}
+	if !languages[lang] && !regionalLanguages[lang] {
+		return "", false
+	}
+	switch {
+	case len(region) == 2 && isLetters(region):
+	case len(region) == 3 && isDigits(region):
+	case scripts[region]:
+	default:
+		return "", false
+	}
+	return lang + "-" + region, true
+}
+
+// PathLocale returns the index and tag of the first locale segment of the URL path p, as
+// in "/en-us/pricing", or -1 when there is none. Only directories are considered, so a
+// page named "/de" is not mistaken for one, unless it is the whole path.
+func PathLocale(p string) (int, string) {
+	segments := strings.Split(strings.Trim(p, "/"), "/")
+	for i, s := range segments {
+		if i == len(segments)-1 && len(segments) > 1 && !strings.HasSuffix(p, "/") {
+			break
+		}
+		if tag, ok := Tag(s); ok {
+			return i, tag
+		}
+	}
+	return -1, ""
+}
+
+// Resource reports whether the URL path p is a translation file: a catalog such as
+// "messages.po", or a JSON, YAML, JavaScript or properties bundle that is named after a
+// locale ("/static/fr.json", "messages_de.properties") or kept in a directory such as
+// "/locales/" or "/i18n/". The locale, when the path names one, is returned as well.
+func Resource(p string) (string, bool) {
+	ext := strings.ToLower(path.Ext(p))
+	if !resourceExts[ext] && !dataExts[ext] {
+		return "", false
+	}
+	dir, file := path.Split(p)
+	stem := strings.TrimSuffix(file, path.Ext(file))
+	tag, named := stemLocale(stem)
+	if !named {
+		// "/locales/fr/translation.json"
+		if _, t := PathLocale(dir); t != "" {
+			tag = t
+		}
+	}
+	if resourceExts[ext] {
+		return tag, true
+	}
+	inDir := false
+	for _, s := range strings.Split(strings.ToLower(dir), "/") {
+		if resourceDirs[s] {
+			inDir = true
+			break
+		}
+	}
+	// A file named after a locale is a bundle, except for script files, which are just as
+	// often libraries ("/js/de.js" could be anything without an i18n directory)
+	if inDir || (named && ext != ".js") {
+		return tag, true
+	}
+	return "", false
+}
+
+// stemLocale finds the locale of a file name such as "fr", "en-US" or "messages_de"
+func stemLocale(stem string) (string, bool) {
+	if tag, ok := Tag(stem); ok {
+		return tag, true
+	}
+	if i := strings.LastIndexAny(stem, "_."); i > 0 {
+		if tag, ok := Tag(stem[i+1:]); ok {
+			return tag, true
+		}
+	}
+	return "", false
+}
+
+// StripURL removes the first locale segment from the path of rawURL, so
+// "https://target.com/fr-fr/pricing" becomes "https://target.com/pricing". URLs without a
+// locale segment are returned unchanged.
+func StripURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Path == "" {
+		return rawURL
+	}
+	i, _ := PathLocale(u.Path)
+	if i < 0 {
+		return rawURL
+	}
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	stripped := "/" + strings.Join(append(segments[:i:i], segments[i+1:]...), "/")
+	if strings.HasSuffix(u.Path, "/") && stripped != "/" {
+		stripped += "/"
+	}
+	u.Path, u.RawPath = stripped, ""
+	return u.String()
+}
+
+func isLetters(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 'a' || s[i] > 'z' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,91 @@
+package locale
+
+import "testing"
+
+func TestTag(t *testing.T) {
+	tests := []struct {
+		segment string
+		want    string
+		ok      bool
+	}{
+		{"en-US", "en-us", true},
+		{"pt_BR", "pt-br", true},
+		{"fr", "fr", true},
+		{"DE", "de", true},
+		{"es-419", "es-419", true},
+		{"zh-Hant", "zh-hant", true},
+		{"id-ID", "id-id", true},
+		{"fil-PH", "fil-ph", true},
+		{"id", "", false},
+		{"js", "", false},
+		{"to-do", "", false},
+		{"en-user", "", false},
+		{"docs", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := Tag(tt.segment)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Tag(%q) = %q, %v, want %q, %v", tt.segment, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestPathLocale(t *testing.T) {
+	tests := []struct {
+		path      string
+		wantIndex int
+		wantTag   string
+	}{
+		{"/en-us/pricing", 0, "en-us"},
+		{"/shop/fr/cart/", 1, "fr"},
+		{"/de", 0, "de"},
+		{"/docs/de", -1, ""},
+		{"/api/v1/users", -1, ""},
+		{"/", -1, ""},
+	}
+	for _, tt := range tests {
+		i, tag := PathLocale(tt.path)
+		if i != tt.wantIndex || tag != tt.wantTag {
+			t.Errorf("PathLocale(%q) = %d, %q, want %d, %q", tt.path, i, tag, tt.wantIndex, tt.wantTag)
+		}
+	}
+}
+
+func TestResource(t *testing.T) {
+	tests := []struct {
+		path    string
+		wantTag string
+		ok      bool
+	}{
+		{"/locales/fr/translation.json", "fr", true},
+		{"/static/i18n/messages.json", "", true},
+		{"/assets/en-GB.json", "en-gb", true},
+		{"/WEB-INF/classes/messages_de.properties", "de", true},
+		{"/lang/ja.js", "ja", true},
+		{"/locale/es/LC_MESSAGES/django.po", "es", true},
+		{"/js/de.js", "", false},
+		{"/package.json", "", false},
+		{"/api/users.json", "", false},
+	}
+	for _, tt := range tests {
+		tag, ok := Resource(tt.path)
+		if tag != tt.wantTag || ok != tt.ok {
+			t.Errorf("Resource(%q) = %q, %v, want %q, %v", tt.path, tag, ok, tt.wantTag, tt.ok)
+		}
+	}
+}
+
+func TestStripURL(t *testing.T) {
+	tests := map[string]string{
+		"https://target.com/fr-fr/pricing?plan=pro": "https://target.com/pricing?plan=pro",
+		"https://target.com/shop/de/cart/":          "https://target.com/shop/cart/",
+		"https://target.com/en/":                    "https://target.com/",
+		"https://target.com/docs/de":                "https://target.com/docs/de",
+		"https://target.com/api/v1":                 "https://target.com/api/v1",
+	}
+	for in, want := range tests {
+		if got := StripURL(in); got != want {
+			t.Errorf("StripURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
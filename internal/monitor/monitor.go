@@ -0,0 +1,240 @@
+// Package monitor supports running urlsluice as a monitoring service: it reads the jobs of a
+// monitor configuration, keeps a baseline of the findings each job has reported, and
+// notifies a webhook about findings that are not in the baseline yet.
+//
+// Baselines are -json documents rather than a SQLite database, which would need cgo or a
+// pure Go port of SQLite; the documents can be read by "urlsluice query" and merged like
+// any other results.
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/PeteJStewart/urlsluice/internal/cron"
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/httpclient"
+	"github.com/PeteJStewart/urlsluice/internal/output"
+	"github.com/PeteJStewart/urlsluice/internal/tempdir"
+	"github.com/PeteJStewart/urlsluice/internal/yamlcheck"
+)
+
+// Config is a monitor configuration file
+type Config struct {
+	// BaselineDir holds one baseline document per job, named after the job
+	BaselineDir string `yaml:"baseline_dir"`
+	// Webhook receives the new findings of jobs that do not set their own
+	Webhook string `yaml:"webhook"`
+	// Jobs lists the scans to run
+	Jobs []Job `yaml:"jobs"`
+}
+
+// Job is a scan run on a schedule
+type Job struct {
+	// Name identifies the job in logs, notifications and its baseline file name
+	Name string `yaml:"name"`
+	// Schedule is a cron expression, see cron.Parse
+	Schedule string `yaml:"schedule"`
+	// Args are the urlsluice flags of the scan, e.g. ["-url", "https://target.com/", "-domains"]
+	Args []string `yaml:"args"`
+	// Webhook receives the new findings of the job, overriding the configuration default
+	Webhook string `yaml:"webhook"`
+
+	schedule *cron.Schedule
+}
+
+// jobNameRegex restricts job names to characters that are safe in file names
+var jobNameRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// Load reads and validates a monitor configuration. A relative baseline_dir is resolved
+// against the directory of the file, and defaults to "baselines" next to it.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config Config
+	if _, err := yamlcheck.Decode(data, &config); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(config.Jobs) == 0 {
+		return nil, fmt.Errorf("%s: no jobs configured", path)
+	}
+	if config.BaselineDir == "" {
+		config.BaselineDir = "baselines"
+	}
+	if !filepath.IsAbs(config.BaselineDir) {
+		config.BaselineDir = filepath.Join(filepath.Dir(path), config.BaselineDir)
+	}
+	seen := make(map[string]bool)
+	for i := range config.Jobs {
+		job := &config.Jobs[i]
+		if !jobNameRegex.MatchString(job.Name) {
+			return nil, fmt.Errorf("%s: jobs[%d]: name %q must be letters, digits, '.', '_' or '-'", path, i, job.Name)
+		}
+		if seen[job.Name] {
+			return nil, fmt.Errorf("%s: duplicate job %q", path, job.Name)
+		}
+		seen[job.Name] = true
+		if job.schedule, err = cron.Parse(job.Schedule); err != nil {
+			return nil, fmt.Errorf("%s: job %s: %w", path, job.Name, err)
+		}
+		if len(job.Args) == 0 {
+			return nil, fmt.Errorf("%s: job %s: args are required", path, job.Name)
+		}
+		if job.Webhook == "" {
+			job.Webhook = config.Webhook
+		}
+	}
+	return &config, nil
+}
+
+// Diff describes the differences between two configurations, one line per change, such as
+// "job app: schedule changed from "@daily" to "@hourly"". Webhook URLs often contain
+// credentials, so only the fact that a webhook changed is reported.
+func Diff(old, new *Config) []string {
+	var changes []string
+	if old.BaselineDir != new.BaselineDir {
+		changes = append(changes, fmt.Sprintf("baseline_dir changed from %s to %s", old.BaselineDir, new.BaselineDir))
+	}
+	if old.Webhook != new.Webhook {
+		changes = append(changes, "default webhook changed")
+	}
+	oldJobs := make(map[string]*Job, len(old.Jobs))
+	for i := range old.Jobs {
+		oldJobs[old.Jobs[i].Name] = &old.Jobs[i]
+	}
+	newJobs := make(map[string]bool, len(new.Jobs))
+	for i := range new.Jobs {
+		job := &new.Jobs[i]
+		newJobs[job.Name] = true
+		prev, ok := oldJobs[job.Name]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("job %s added with schedule %q", job.Name, job.Schedule))
+			continue
+		}
+		if prev.Schedule != job.Schedule {
+			changes = append(changes, fmt.Sprintf("job %s: schedule changed from %q to %q", job.Name, prev.Schedule, job.Schedule))
+		}
+		if strings.Join(prev.Args, "\x00") != strings.Join(job.Args, "\x00") {
+			changes = append(changes, fmt.Sprintf("job %s: args changed from %q to %q", job.Name, prev.Args, job.Args))
+		}
+		if prev.Webhook != job.Webhook {
+			changes = append(changes, fmt.Sprintf("job %s: webhook changed", job.Name))
+		}
+	}
+	for _, job := range old.Jobs {
+		if !newJobs[job.Name] {
+			changes = append(changes, fmt.Sprintf("job %s removed", job.Name))
+		}
+	}
+	return changes
+}
+
+// Next returns when the job runs next after t
+func (j *Job) Next(t time.Time) time.Time {
+	return j.schedule.Next(t)
+}
+
+// BaselinePath returns the file the job's baseline is kept in
+func (c *Config) BaselinePath(job *Job) string {
+	return filepath.Join(c.BaselineDir, job.Name+".json")
+}
+
+// UpdateBaseline merges the findings of doc into the baseline document at path and returns
+// the findings that were not in it. created reports that there was no baseline yet, in which
+// case every finding is new.
+func UpdateBaseline(path string, doc output.Document) (added []finding.Finding, created bool, err error) {
+	var docs []output.Document
+	known := make(map[string]bool)
+	f, err := os.Open(path)
+	switch {
+	case os.IsNotExist(err):
+		created = true
+	case err != nil:
+		return nil, false, err
+	default:
+		baseline, err := output.ReadDocument(f)
+		f.Close()
+		if err != nil {
+			return nil, false, fmt.Errorf("baseline %s: %w", path, err)
+		}
+		for _, f := range baseline.Findings {
+			known[f.Key()] = true
+		}
+		docs = append(docs, baseline)
+	}
+	for _, f := range doc.Findings {
+		if !known[f.Key()] {
+			known[f.Key()] = true
+			added = append(added, f)
+		}
+	}
+	merged := output.MergeDocuments(append(docs, doc))
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, false, err
+	}
+	// Write a temporary file first so an interrupted write never truncates the baseline
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return nil, false, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tempdir.Track(tmp.Name())()
+	if err := output.WriteJSON(tmp, doc.Run, merged); err != nil {
+		tmp.Close()
+		return nil, false, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, false, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, false, err
+	}
+	return added, created, nil
+}
+
+// Notification is the JSON body posted to a webhook
+type Notification struct {
+	// Job is the name of the job that found the findings
+	Job string `json:"job"`
+	// Time is when the scan ran
+	Time time.Time `json:"time"`
+	// Text summarizes the notification for chat webhooks such as Slack's
+	Text string `json:"text"`
+	// Findings are the findings that were not in the baseline
+	Findings []finding.Finding `json:"findings"`
+}
+
+// Notify posts n as JSON to webhook, failing on responses other than 2xx
+func Notify(ctx context.Context, client *httpclient.Client, webhook string, n Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
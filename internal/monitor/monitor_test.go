@@ -0,0 +1,174 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/httpclient"
+	"github.com/PeteJStewart/urlsluice/internal/output"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "monitor.yaml")
+	config := `webhook: https://hooks.example.com/default
+jobs:
+  - name: app-js
+    schedule: "0 */6 * * *"
+    args: ["-url", "https://target.com/app.js", "-domains"]
+  - name: nightly
+    schedule: "@daily"
+    args: ["-file", "urls.txt", "-urls"]
+    webhook: https://hooks.example.com/nightly
+`
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if c.BaselineDir != filepath.Join(dir, "baselines") {
+		t.Errorf("BaselineDir = %q", c.BaselineDir)
+	}
+	if c.Jobs[0].Webhook != "https://hooks.example.com/default" || c.Jobs[1].Webhook != "https://hooks.example.com/nightly" {
+		t.Errorf("webhooks = %q, %q", c.Jobs[0].Webhook, c.Jobs[1].Webhook)
+	}
+	if got, want := c.Jobs[0].Next(time.Date(2026, 5, 6, 7, 0, 0, 0, time.UTC)), time.Date(2026, 5, 6, 12, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Next() = %v, want %v", got, want)
+	}
+	if got := c.BaselinePath(&c.Jobs[1]); got != filepath.Join(dir, "baselines", "nightly.json") {
+		t.Errorf("BaselinePath() = %q", got)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := map[string]string{
+		"jobs: []": "no jobs configured",
+		"jobs:\n  - name: ../x\n    schedule: '@daily'\n    args: [-urls]":                                                      `name "../x" must be`,
+		"jobs:\n  - name: a\n    schedule: '@daily'\n    args: [-urls]\n  - name: a\n    schedule: '@daily'\n    args: [-urls]": `duplicate job "a"`,
+		"jobs:\n  - name: a\n    schedule: often\n    args: [-urls]":                                                            "job a: invalid cron expression",
+		"jobs:\n  - name: a\n    schedule: '@daily'":                                                                            "job a: args are required",
+	}
+	for config, want := range tests {
+		path := filepath.Join(t.TempDir(), "monitor.yaml")
+		if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(path); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Load(%q) error = %v, want %q", config, err, want)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	old := &Config{
+		BaselineDir: "/var/lib/urlsluice",
+		Webhook:     "https://hooks.example.com/a",
+		Jobs: []Job{
+			{Name: "app", Schedule: "@daily", Args: []string{"-file", "urls.txt", "-domains"}},
+			{Name: "js", Schedule: "@hourly", Args: []string{"-file", "app.js", "-urls"}},
+			{Name: "old", Schedule: "@weekly", Args: []string{"-file", "old.txt"}},
+		},
+	}
+	new := &Config{
+		BaselineDir: "/var/lib/urlsluice",
+		Webhook:     "https://hooks.example.com/b",
+		Jobs: []Job{
+			{Name: "app", Schedule: "0 */6 * * *", Args: []string{"-file", "urls.txt", "-domains", "-ips"}},
+			{Name: "js", Schedule: "@hourly", Args: []string{"-file", "app.js", "-urls"}, Webhook: "https://hooks.example.com/js"},
+			{Name: "api", Schedule: "@daily", Args: []string{"-openapi", "api.yaml"}},
+		},
+	}
+	want := []string{
+		"default webhook changed",
+		`job app: schedule changed from "@daily" to "0 */6 * * *"`,
+		`job app: args changed from ["-file" "urls.txt" "-domains"] to ["-file" "urls.txt" "-domains" "-ips"]`,
+		"job js: webhook changed",
+		`job api added with schedule "@daily"`,
+		"job old removed",
+	}
+	if got := Diff(old, new); !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %q, want %q", got, want)
+	}
+	if got := Diff(new, new); len(got) != 0 {
+		t.Errorf("Diff() of equal configurations = %q", got)
+	}
+}
+
+func TestUpdateBaseline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baselines", "job.json")
+	doc := func(day int, values ...string) output.Document {
+		d := output.Document{Run: &output.Run{StartedAt: time.Date(2026, 5, day, 0, 0, 0, 0, time.UTC)}}
+		for _, v := range values {
+			d.Findings = append(d.Findings, finding.Finding{Type: finding.TypeDomain, Value: v})
+		}
+		return d
+	}
+
+	added, created, err := UpdateBaseline(path, doc(1, "a.target.com", "b.target.com"))
+	if err != nil || !created || len(added) != 2 {
+		t.Fatalf("first UpdateBaseline() = %v, %v, %v", added, created, err)
+	}
+	added, created, err = UpdateBaseline(path, doc(2, "b.target.com", "c.target.com"))
+	if err != nil || created || len(added) != 1 || added[0].Value != "c.target.com" {
+		t.Fatalf("second UpdateBaseline() = %v, %v, %v", added, created, err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	baseline, err := output.ReadDocument(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	firstSeen := make(map[string]string)
+	for _, f := range baseline.Findings {
+		firstSeen[f.Value] = f.Metadata[output.FirstSeenKey]
+	}
+	if len(firstSeen) != 3 || firstSeen["b.target.com"] != "2026-05-01T00:00:00Z" || firstSeen["c.target.com"] != "2026-05-02T00:00:00Z" {
+		t.Errorf("baseline first_seen = %v", firstSeen)
+	}
+}
+
+func TestNotify(t *testing.T) {
+	var got Notification
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("request = %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+	client, err := httpclient.New(httpclient.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n := Notification{Job: "app-js", Text: "1 new finding", Findings: []finding.Finding{{Type: finding.TypeDomain, Value: "c.target.com"}}}
+	if err := Notify(context.Background(), client, server.URL+"/hook", n); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if got.Job != "app-js" || len(got.Findings) != 1 || got.Findings[0].Value != "c.target.com" {
+		t.Errorf("posted %+v", got)
+	}
+	if err := Notify(context.Background(), client, server.URL+"/fail", n); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Notify() error = %v, want the 403 status", err)
+	}
+}
@@ -0,0 +1,38 @@
+//go:build windows
+
+package monitor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoad_WindowsBaselineDir(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		baselineDir string
+		want        string
+	}{
+		{`D:\recon\baselines`, `D:\recon\baselines`},
+		{`state\baselines`, filepath.Join(dir, "state", "baselines")},
+		{`state/baselines`, filepath.Join(dir, "state", "baselines")},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(dir, "monitor.yaml")
+		config := "baseline_dir: '" + tt.baselineDir + "'\njobs:\n  - name: nightly\n    schedule: \"@daily\"\n    args: [\"-file\", \"urls.txt\", \"-urls\"]\n"
+		if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		c, err := Load(path)
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if c.BaselineDir != tt.want {
+			t.Errorf("baseline_dir %q: BaselineDir = %q, want %q", tt.baselineDir, c.BaselineDir, tt.want)
+		}
+		if got, want := c.BaselinePath(&c.Jobs[0]), filepath.Join(tt.want, "nightly.json"); got != want {
+			t.Errorf("BaselinePath() = %q, want %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+//go:build hyperscan && cgo
+
+package multimatch
+
+/*
+#cgo pkg-config: libhs
+#include <stdlib.h>
+#include <hs.h>
+
+// on_match records the expression that matched in the flags passed as context. Expressions
+// are compiled with HS_FLAG_SINGLEMATCH, so it runs at most once per expression.
+static int on_match(unsigned int id, unsigned long long from, unsigned long long to,
+		unsigned int flags, void *context) {
+	((unsigned char *)context)[id] = 1;
+	return 0;
+}
+
+static hs_error_t scan(const hs_database_t *db, const char *data, unsigned int length,
+		hs_scratch_t *scratch, unsigned char *hits) {
+	return hs_scan(db, data, length, 0, scratch, on_match, hits);
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"unicode/utf8"
+	"unsafe"
+)
+
+// Backend names the engine used to find candidate expressions
+const Backend = "hyperscan"
+
+// engine is a Hyperscan block-mode database of a set's expressions, with a pool of the
+// scratch space each concurrent scan needs
+type engine struct {
+	db      *C.hs_database_t
+	n       int
+	scratch chan *C.hs_scratch_t
+}
+
+// newEngine compiles patterns into one database. Expressions are compiled in prefilter mode,
+// so constructs Hyperscan does not support are approximated by a superset that may report
+// false candidates, which the regexp confirmation drops; UTF-8 mode makes "." and bounded
+// repeats count characters as regexp does.
+func newEngine(patterns []string) (*engine, error) {
+	n := len(patterns)
+	exprs := make([]*C.char, n)
+	flags := make([]C.uint, n)
+	ids := make([]C.uint, n)
+	for i, p := range patterns {
+		exprs[i] = C.CString(p)
+		defer C.free(unsafe.Pointer(exprs[i]))
+		flags[i] = C.HS_FLAG_PREFILTER | C.HS_FLAG_SINGLEMATCH | C.HS_FLAG_UTF8 | C.HS_FLAG_ALLOWEMPTY
+		ids[i] = C.uint(i)
+	}
+
+	var db *C.hs_database_t
+	var cerr *C.hs_compile_error_t
+	if C.hs_compile_multi(&exprs[0], &flags[0], &ids[0], C.uint(n), C.HS_MODE_BLOCK, nil, &db, &cerr) != C.HS_SUCCESS {
+		err := fmt.Errorf("hyperscan: expression %d: %s", int(cerr.expression), C.GoString(cerr.message))
+		C.hs_free_compile_error(cerr)
+		return nil, err
+	}
+	e := &engine{db: db, n: n, scratch: make(chan *C.hs_scratch_t, runtime.GOMAXPROCS(0))}
+	s, err := e.get()
+	if err != nil {
+		C.hs_free_database(db)
+		return nil, err
+	}
+	e.put(s)
+	return e, nil
+}
+
+// get returns idle scratch space or allocates more
+func (e *engine) get() (*C.hs_scratch_t, error) {
+	select {
+	case s := <-e.scratch:
+		return s, nil
+	default:
+	}
+	var s *C.hs_scratch_t
+	if ret := C.hs_alloc_scratch(e.db, &s); ret != C.HS_SUCCESS {
+		return nil, fmt.Errorf("hyperscan: allocating scratch space: error %d", int(ret))
+	}
+	return s, nil
+}
+
+// put returns scratch space to the pool, freeing it when the pool is full
+func (e *engine) put(s *C.hs_scratch_t) {
+	select {
+	case e.scratch <- s:
+	default:
+		C.hs_free_scratch(s)
+	}
+}
+
+// candidates returns the expressions that may match text in pattern order. ok is false when
+// text cannot be scanned in UTF-8 mode, or the scan fails, and every expression has to be tried.
+func (e *engine) candidates(text string) ([]int, bool) {
+	if text == "" || len(text) > math.MaxUint32 || !utf8.ValidString(text) {
+		return nil, false
+	}
+	s, err := e.get()
+	if err != nil {
+		return nil, false
+	}
+	defer e.put(s)
+
+	hits := make([]byte, e.n)
+	data := (*C.char)(unsafe.Pointer(unsafe.StringData(text)))
+	if C.scan(e.db, data, C.uint(len(text)), s, (*C.uchar)(unsafe.Pointer(&hits[0]))) != C.HS_SUCCESS {
+		return nil, false
+	}
+	var candidates []int
+	for i, hit := range hits {
+		if hit != 0 {
+			candidates = append(candidates, i)
+		}
+	}
+	return candidates, true
+}
@@ -0,0 +1,73 @@
+// Package multimatch runs a fixed set of regular expressions over the same text, as the
+// verbose error signatures do on every line. By default each expression is tried in turn
+// with the standard library. Built with the hyperscan tag (and cgo, against the Hyperscan or
+// Vectorscan library), the set is also compiled into one Hyperscan database that scans a
+// line once to find the expressions that can match it, and only those run with regexp.
+//
+// Both backends report the same matches: Hyperscan only narrows the candidates, and a set
+// it cannot compile, or text it cannot scan, falls back to trying every expression.
+package multimatch
+
+import "regexp"
+
+// Set is a compiled set of regular expressions. It is safe for concurrent use.
+type Set struct {
+	regexes []*regexp.Regexp
+	// engine finds the candidate expressions of a text; nil tries every expression
+	engine *engine
+}
+
+// New compiles patterns with regexp.Compile and, when the hyperscan backend is built in,
+// into a Hyperscan database
+func New(patterns []string) (*Set, error) {
+	s := &Set{regexes: make([]*regexp.Regexp, len(patterns))}
+	for i, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, err
+		}
+		s.regexes[i] = re
+	}
+	if len(patterns) > 0 {
+		// A set Hyperscan rejects still works, only without acceleration
+		s.engine, _ = newEngine(patterns)
+	}
+	return s, nil
+}
+
+// MustNew is like New but panics if a pattern is invalid. It simplifies the initialization
+// of package variables holding built-in pattern sets.
+func MustNew(patterns ...string) *Set {
+	s, err := New(patterns)
+	if err != nil {
+		panic("multimatch: " + err.Error())
+	}
+	return s
+}
+
+// Accelerated reports whether the set is scanned with Hyperscan
+func (s *Set) Accelerated() bool {
+	return s.engine != nil
+}
+
+// Each calls fn, in pattern order, with the index of every expression matching text and its
+// leftmost match
+func (s *Set) Each(text string, fn func(i int, match string)) {
+	if s.engine != nil {
+		if candidates, ok := s.engine.candidates(text); ok {
+			for _, i := range candidates {
+				s.find(i, text, fn)
+			}
+			return
+		}
+	}
+	for i := range s.regexes {
+		s.find(i, text, fn)
+	}
+}
+
+func (s *Set) find(i int, text string, fn func(int, string)) {
+	if loc := s.regexes[i].FindStringIndex(text); loc != nil {
+		fn(i, text[loc[0]:loc[1]])
+	}
+}
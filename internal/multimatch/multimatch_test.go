@@ -0,0 +1,43 @@
+package multimatch
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSet_Each(t *testing.T) {
+	s := MustNew(`Whitelabel Error Page`, `\bORA-\d{5}: `, `java\.lang\.\w+Exception`)
+
+	type match struct {
+		i     int
+		match string
+	}
+	tests := []struct {
+		name string
+		text string
+		want []match
+	}{
+		{"none", "GET /index.html 200", nil},
+		{"one", "<h1>Whitelabel Error Page</h1>", []match{{0, "Whitelabel Error Page"}}},
+		{"pattern order", "java.lang.NullPointerException after ORA-00942: table missing", []match{{1, "ORA-00942: "}, {2, "java.lang.NullPointerException"}}},
+		{"invalid UTF-8", "\xff ORA-01017: denied", []match{{1, "ORA-01017: "}}},
+		{"empty", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []match
+			s.Each(tt.text, func(i int, m string) {
+				got = append(got, match{i, m})
+			})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Each() = %v, want %v (backend %s)", got, tt.want, Backend)
+			}
+		})
+	}
+}
+
+func TestNew_InvalidPattern(t *testing.T) {
+	if _, err := New([]string{`ok`, `(`}); err == nil {
+		t.Error("New() with an invalid pattern did not fail")
+	}
+}
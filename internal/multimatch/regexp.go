@@ -0,0 +1,17 @@
+//go:build !hyperscan || !cgo
+
+package multimatch
+
+// Backend names the engine used to find candidate expressions
+const Backend = "regexp"
+
+// engine is not available without the hyperscan build tag
+type engine struct{}
+
+func newEngine(patterns []string) (*engine, error) {
+	return nil, nil
+}
+
+func (e *engine) candidates(text string) ([]int, bool) {
+	return nil, false
+}
@@ -0,0 +1,284 @@
+// Package openapi reads OpenAPI 3 and Swagger 2 documents and lists the servers, endpoints
+// and parameters they describe.
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxRefDepth limits how many $ref indirections are followed, guarding against cycles
+const maxRefDepth = 10
+
+// methods lists the operations of a path item in the order they are reported
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Spec is the API described by a document
+type Spec struct {
+	// Servers are the base URLs of the API, with server variables set to their defaults
+	Servers []string
+	// Endpoints are the paths of the API in document order
+	Endpoints []Endpoint
+}
+
+// Endpoint is a path and the operations defined on it
+type Endpoint struct {
+	// Path is the path template, e.g. "/users/{id}"
+	Path string
+	// Methods are the HTTP methods of the operations, upper case
+	Methods []string
+	// Params are the parameters of every operation, including request body properties
+	Params []Param
+}
+
+// Param is a parameter of an operation
+type Param struct {
+	// Name is the parameter or body property name
+	Name string
+	// In is where the parameter goes: "query", "path", "header", "cookie", "formData" or "body"
+	In string
+	// Example is an example, default or enum value of the parameter, if the document has one
+	Example string
+}
+
+// Parse reads an OpenAPI 3 or Swagger 2 document in YAML or JSON
+func Parse(data []byte) (*Spec, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("error parsing OpenAPI document: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, errors.New("not an OpenAPI or Swagger document")
+	}
+	root := doc.Content[0]
+	p := parser{root: root}
+	spec := &Spec{}
+	switch {
+	case scalar(lookup(root, "openapi")) != "":
+		spec.Servers = p.servers3()
+	case scalar(lookup(root, "swagger")) != "":
+		spec.Servers = p.servers2()
+	default:
+		return nil, errors.New("not an OpenAPI or Swagger document")
+	}
+
+	paths := lookup(root, "paths")
+	for _, e := range pairs(paths) {
+		item := p.resolve(e.value)
+		endpoint := Endpoint{Path: e.key}
+		seen := map[string]bool{}
+		add := func(params []Param) {
+			for _, param := range params {
+				if key := param.In + "\x00" + param.Name; !seen[key] {
+					seen[key] = true
+					endpoint.Params = append(endpoint.Params, param)
+				}
+			}
+		}
+		add(p.params(lookup(item, "parameters")))
+		for _, method := range methods {
+			op := lookup(item, method)
+			if op == nil {
+				continue
+			}
+			endpoint.Methods = append(endpoint.Methods, strings.ToUpper(method))
+			add(p.params(lookup(op, "parameters")))
+			add(p.body(lookup(op, "requestBody")))
+		}
+		spec.Endpoints = append(spec.Endpoints, endpoint)
+	}
+	return spec, nil
+}
+
+// URL returns the endpoint's URL on server: path parameters are replaced by their examples
+// and query parameters are appended with their examples, or empty values
+func (e Endpoint) URL(server string) string {
+	path := e.Path
+	var query []string
+	for _, p := range e.Params {
+		switch p.In {
+		case "path":
+			if p.Example != "" {
+				path = strings.ReplaceAll(path, "{"+p.Name+"}", url.PathEscape(p.Example))
+			}
+		case "query":
+			query = append(query, url.QueryEscape(p.Name)+"="+url.QueryEscape(p.Example))
+		}
+	}
+	u := strings.TrimSuffix(server, "/") + path
+	if len(query) > 0 {
+		u += "?" + strings.Join(query, "&")
+	}
+	return u
+}
+
+type parser struct {
+	root *yaml.Node
+}
+
+// servers3 returns the servers of an OpenAPI 3 document
+func (p parser) servers3() []string {
+	var servers []string
+	for _, s := range items(lookup(p.root, "servers")) {
+		u := scalar(lookup(s, "url"))
+		if u == "" {
+			continue
+		}
+		for _, v := range pairs(lookup(s, "variables")) {
+			u = strings.ReplaceAll(u, "{"+v.key+"}", scalar(lookup(v.value, "default")))
+		}
+		servers = append(servers, u)
+	}
+	return servers
+}
+
+// servers2 returns the servers of a Swagger 2 document, one per scheme
+func (p parser) servers2() []string {
+	host := scalar(lookup(p.root, "host"))
+	basePath := scalar(lookup(p.root, "basePath"))
+	if host == "" {
+		if basePath == "" {
+			return nil
+		}
+		return []string{basePath}
+	}
+	var servers []string
+	for _, scheme := range items(lookup(p.root, "schemes")) {
+		servers = append(servers, scalar(scheme)+"://"+host+basePath)
+	}
+	if len(servers) == 0 {
+		servers = append(servers, "https://"+host+basePath)
+	}
+	return servers
+}
+
+// params returns a parameters list; Swagger 2 body parameters are expanded into the
+// properties of their schema
+func (p parser) params(list *yaml.Node) []Param {
+	var params []Param
+	for _, n := range items(list) {
+		n = p.resolve(n)
+		name, in := scalar(lookup(n, "name")), scalar(lookup(n, "in"))
+		if in == "body" {
+			params = append(params, p.properties(lookup(n, "schema"))...)
+			continue
+		}
+		if name == "" {
+			continue
+		}
+		params = append(params, Param{Name: name, In: in, Example: p.example(n)})
+	}
+	return params
+}
+
+// body returns the properties of an OpenAPI 3 request body
+func (p parser) body(n *yaml.Node) []Param {
+	n = p.resolve(n)
+	var params []Param
+	for _, media := range pairs(lookup(n, "content")) {
+		params = append(params, p.properties(lookup(media.value, "schema"))...)
+	}
+	return params
+}
+
+// properties returns the top-level properties of an object schema as body parameters
+func (p parser) properties(schema *yaml.Node) []Param {
+	schema = p.resolve(schema)
+	if elem := lookup(schema, "items"); elem != nil {
+		schema = p.resolve(elem)
+	}
+	var params []Param
+	for _, prop := range pairs(lookup(schema, "properties")) {
+		params = append(params, Param{Name: prop.key, In: "body", Example: p.example(prop.value)})
+	}
+	return params
+}
+
+// example returns the first scalar example, default or enum value of a parameter or schema
+func (p parser) example(n *yaml.Node) string {
+	n = p.resolve(n)
+	for _, key := range []string{"example", "x-example", "default"} {
+		if v := scalar(lookup(n, key)); v != "" {
+			return v
+		}
+	}
+	examples := pairs(lookup(n, "examples"))
+	sort.Slice(examples, func(i, j int) bool { return examples[i].key < examples[j].key })
+	for _, e := range examples {
+		if v := scalar(lookup(p.resolve(e.value), "value")); v != "" {
+			return v
+		}
+	}
+	if enum := items(lookup(n, "enum")); len(enum) > 0 {
+		if v := scalar(enum[0]); v != "" {
+			return v
+		}
+	}
+	if schema := lookup(n, "schema"); schema != nil {
+		return p.example(schema)
+	}
+	return ""
+}
+
+// resolve follows local $ref pointers such as "#/components/parameters/limit"
+func (p parser) resolve(n *yaml.Node) *yaml.Node {
+	for i := 0; i < maxRefDepth && n != nil; i++ {
+		ref := scalar(lookup(n, "$ref"))
+		if !strings.HasPrefix(ref, "#/") {
+			return n
+		}
+		target := p.root
+		for _, token := range strings.Split(ref[2:], "/") {
+			token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+			target = lookup(target, token)
+		}
+		n = target
+	}
+	return n
+}
+
+type pair struct {
+	key   string
+	value *yaml.Node
+}
+
+// pairs returns the entries of a mapping in document order
+func pairs(n *yaml.Node) []pair {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	var out []pair
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		out = append(out, pair{key: n.Content[i].Value, value: n.Content[i+1]})
+	}
+	return out
+}
+
+// items returns the elements of a sequence
+func items(n *yaml.Node) []*yaml.Node {
+	if n == nil || n.Kind != yaml.SequenceNode {
+		return nil
+	}
+	return n.Content
+}
+
+func lookup(n *yaml.Node, key string) *yaml.Node {
+	for _, p := range pairs(n) {
+		if p.key == key {
+			return p.value
+		}
+	}
+	return nil
+}
+
+func scalar(n *yaml.Node) string {
+	if n == nil || n.Kind != yaml.ScalarNode || n.Tag == "!!null" {
+		return ""
+	}
+	return n.Value
+}
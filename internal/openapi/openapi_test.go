@@ -0,0 +1,160 @@
+package openapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+const openAPI3 = `openapi: 3.0.3
+servers:
+  - url: https://{env}.api.target.com/v1
+    variables:
+      env:
+        default: prod
+paths:
+  /users/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema:
+          type: integer
+          example: 42
+    get:
+      parameters:
+        - $ref: '#/components/parameters/fields'
+        - name: X-Request-ID
+          in: header
+    put:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/User'
+  /search:
+    get:
+      parameters:
+        - name: q
+          in: query
+          examples:
+            simple:
+              value: admin
+components:
+  parameters:
+    fields:
+      name: fields
+      in: query
+      schema:
+        type: string
+        enum: [name, email]
+  schemas:
+    User:
+      type: object
+      properties:
+        email:
+          type: string
+          example: dev@target.com
+        role:
+          type: string
+          default: user
+`
+
+const swagger2 = `{
+  "swagger": "2.0",
+  "host": "legacy.target.com",
+  "basePath": "/api",
+  "schemes": ["http", "https"],
+  "paths": {
+    "/login": {
+      "post": {
+        "parameters": [
+          {"name": "username", "in": "formData", "x-example": "admin"},
+          {"name": "body", "in": "body", "schema": {"properties": {"remember": {"type": "boolean"}}}}
+        ]
+      }
+    }
+  }
+}`
+
+func TestParse_OpenAPI3(t *testing.T) {
+	spec, err := Parse([]byte(openAPI3))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if want := []string{"https://prod.api.target.com/v1"}; !reflect.DeepEqual(spec.Servers, want) {
+		t.Errorf("Servers = %v, want %v", spec.Servers, want)
+	}
+	want := []Endpoint{
+		{
+			Path:    "/users/{id}",
+			Methods: []string{"GET", "PUT"},
+			Params: []Param{
+				{Name: "id", In: "path", Example: "42"},
+				{Name: "fields", In: "query", Example: "name"},
+				{Name: "X-Request-ID", In: "header"},
+				{Name: "email", In: "body", Example: "dev@target.com"},
+				{Name: "role", In: "body", Example: "user"},
+			},
+		},
+		{
+			Path:    "/search",
+			Methods: []string{"GET"},
+			Params:  []Param{{Name: "q", In: "query", Example: "admin"}},
+		},
+	}
+	if !reflect.DeepEqual(spec.Endpoints, want) {
+		t.Errorf("Endpoints = %+v, want %+v", spec.Endpoints, want)
+	}
+
+	if got, want := spec.Endpoints[0].URL(spec.Servers[0]), "https://prod.api.target.com/v1/users/42?fields=name"; got != want {
+		t.Errorf("URL() = %q, want %q", got, want)
+	}
+}
+
+func TestParse_Swagger2(t *testing.T) {
+	spec, err := Parse([]byte(swagger2))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if want := []string{"http://legacy.target.com/api", "https://legacy.target.com/api"}; !reflect.DeepEqual(spec.Servers, want) {
+		t.Errorf("Servers = %v, want %v", spec.Servers, want)
+	}
+	want := []Endpoint{{
+		Path:    "/login",
+		Methods: []string{"POST"},
+		Params: []Param{
+			{Name: "username", In: "formData", Example: "admin"},
+			{Name: "remember", In: "body"},
+		},
+	}}
+	if !reflect.DeepEqual(spec.Endpoints, want) {
+		t.Errorf("Endpoints = %+v, want %+v", spec.Endpoints, want)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, input := range []string{"", "just text", "{not: [valid", "kind: Deployment"} {
+		if _, err := Parse([]byte(input)); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", input)
+		}
+	}
+}
+
+func TestParse_RefCycle(t *testing.T) {
+	input := `openapi: 3.1.0
+paths:
+  /a:
+    get:
+      parameters:
+        - $ref: '#/components/parameters/loop'
+components:
+  parameters:
+    loop:
+      $ref: '#/components/parameters/loop'
+`
+	if _, err := Parse([]byte(input)); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+}
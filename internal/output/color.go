@@ -0,0 +1,65 @@
+package output
+
+import (
+	"os"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+// ANSI escape sequences used by Palette
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiRed    = "\x1b[31m"
+	ansiYellow = "\x1b[33m"
+	ansiCyan   = "\x1b[36m"
+	ansiDim    = "\x1b[2m"
+)
+
+// Palette highlights parts of the text output with ANSI colors.
+// The zero value is disabled and returns text unchanged.
+type Palette struct {
+	// Enabled turns the colors on
+	Enabled bool
+}
+
+func (p Palette) wrap(code, s string) string {
+	if !p.Enabled || s == "" {
+		return s
+	}
+	return code + s + ansiReset
+}
+
+// Title formats a section title
+func (p Palette) Title(s string) string { return p.wrap(ansiBold+ansiCyan, s) }
+
+// Risk formats a value that needs attention, such as a leaked credential
+func (p Palette) Risk(s string) string { return p.wrap(ansiBold+ansiRed, s) }
+
+// Highlight formats a value of interest, such as a known redirect parameter
+func (p Palette) Highlight(s string) string { return p.wrap(ansiYellow, s) }
+
+// Detail formats secondary information such as annotations
+func (p Palette) Detail(s string) string { return p.wrap(ansiDim, s) }
+
+// HighRisk reports whether f is a credential or key that should stand out in the output
+func HighRisk(f finding.Finding) bool {
+	switch f.Type {
+	case finding.TypeConfigSecret, finding.TypeCloudConfig, finding.TypeToken:
+		return true
+	}
+	return false
+}
+
+// ColorTerminal reports whether colors should be written to file: it must be a terminal,
+// NO_COLOR (see no-color.org) must be unset and TERM must not be "dumb"
+func ColorTerminal(file *os.File) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,178 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/classify"
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/redirect"
+)
+
+// noHost heads the findings that could not be attributed to a host
+const noHost = "(no host)"
+
+// HostGroup is the findings attributed to one host
+type HostGroup struct {
+	// Host is the host name or IP, or "" for findings without a host
+	Host string
+	// Findings are the findings about the host, in their original order
+	Findings []finding.Finding
+}
+
+// lineKey identifies an input line
+type lineKey struct {
+	source string
+	line   int
+}
+
+// GroupByHost attributes every finding to a host and groups them, ordered by host with the
+// findings without a host last. Domains, IPs, URLs and emails name their host. Other
+// findings, such as parameters and secrets, take the host of the first URL or domain found
+// on the same input line, or else the host of their source when it is a fetched URL. Since
+// findings are deduplicated, a parameter seen under several hosts is listed under the host
+// of its first occurrence only.
+func GroupByHost(findings []finding.Finding) []HostGroup {
+	// URLs are attributed first, since a domain on the same line may be a parameter value
+	lineHosts := make(map[lineKey]string)
+	for _, t := range []finding.Type{finding.TypeURL, finding.TypeDomain} {
+		for _, f := range findings {
+			key := lineKey{f.Source, f.Line}
+			if f.Type != t || f.Line == 0 || lineHosts[key] != "" {
+				continue
+			}
+			lineHosts[key] = strings.ToLower(classify.HostOf(f))
+		}
+	}
+
+	byHost := make(map[string][]finding.Finding)
+	var hosts []string
+	for _, f := range findings {
+		host := ownHost(f)
+		if host == "" && f.Line > 0 {
+			host = lineHosts[lineKey{f.Source, f.Line}]
+		}
+		if host == "" {
+			host = urlHost(f.Source)
+		}
+		if _, ok := byHost[host]; !ok {
+			hosts = append(hosts, host)
+		}
+		byHost[host] = append(byHost[host], f)
+	}
+	sort.Slice(hosts, func(i, j int) bool {
+		if (hosts[i] == "") != (hosts[j] == "") {
+			return hosts[j] == ""
+		}
+		return hosts[i] < hosts[j]
+	})
+
+	groups := make([]HostGroup, len(hosts))
+	for i, host := range hosts {
+		groups[i] = HostGroup{Host: host, Findings: byHost[host]}
+	}
+	return groups
+}
+
+// WithHosts returns a copy of findings with the host GroupByHost attributes them to
+// recorded in the "host" metadata, so structured output can be pivoted by host. The
+// metadata of the given findings is left untouched.
+func WithHosts(findings []finding.Finding) []finding.Finding {
+	annotated := make([]finding.Finding, 0, len(findings))
+	for _, g := range GroupByHost(findings) {
+		for _, f := range g.Findings {
+			if g.Host != "" && f.Metadata["host"] == "" {
+				meta := make(map[string]string, len(f.Metadata)+1)
+				for k, v := range f.Metadata {
+					meta[k] = v
+				}
+				meta["host"] = g.Host
+				f.Metadata = meta
+			}
+			annotated = append(annotated, f)
+		}
+	}
+	return annotated
+}
+
+// WriteTextByHost writes findings like WriteTextWith in one block per host, as grouped by
+// GroupByHost. Each block is headed by the host unless opts.Silent is set.
+func WriteTextByHost(w io.Writer, findings []finding.Finding, opts TextOptions) error {
+	for _, g := range GroupByHost(findings) {
+		if err := writeHostTitle(w, g.Host, opts); err != nil {
+			return err
+		}
+		if err := WriteTextWith(w, g.Findings, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteRedirectsByHost writes the results of WriteRedirects in one block per host of the
+// scanned URLs, ordered by host
+func WriteRedirectsByHost(w io.Writer, results []redirect.RedirectResult, opts TextOptions) error {
+	byHost := make(map[string][]redirect.RedirectResult)
+	var hosts []string
+	for _, result := range results {
+		if !result.IsVulnerable {
+			continue
+		}
+		host := urlHost(result.URL)
+		if _, ok := byHost[host]; !ok {
+			hosts = append(hosts, host)
+		}
+		byHost[host] = append(byHost[host], result)
+	}
+	sort.Strings(hosts)
+
+	for _, host := range hosts {
+		if err := writeHostTitle(w, host, opts); err != nil {
+			return err
+		}
+		if err := WriteRedirects(w, byHost[host], opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeHostTitle(w io.Writer, host string, opts TextOptions) error {
+	if opts.Silent {
+		return nil
+	}
+	if host == "" {
+		host = noHost
+	}
+	_, err := fmt.Fprintf(w, "\n%s\n", opts.Colors.Title("== "+host+" =="))
+	return err
+}
+
+// ownHost returns the host a finding names itself
+func ownHost(f finding.Finding) string {
+	host := classify.HostOf(f)
+	switch {
+	case host != "":
+	case f.Type == finding.TypeEmail:
+		if i := strings.LastIndex(f.Value, "@"); i >= 0 {
+			host = f.Value[i+1:]
+		}
+	case f.Metadata["host"] != "":
+		host = f.Metadata["host"]
+	case f.Metadata["url"] != "":
+		host = urlHost(f.Metadata["url"])
+	}
+	return strings.ToLower(host)
+}
+
+// urlHost returns the host of an HTTP(S) URL, or "" for anything else such as a file path
+func urlHost(s string) string {
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
@@ -0,0 +1,64 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/xref"
+)
+
+// Document is the structured output written by WriteJSON
+type Document struct {
+	// SchemaVersion is the version of the output format, see Schema
+	SchemaVersion string `json:"schema_version"`
+	// Run describes the invocation that produced the document
+	Run *Run `json:"run,omitempty"`
+	// Findings lists every reported finding ordered with finding.Sort
+	Findings []finding.Finding `json:"findings"`
+	// Values lists each distinct value with the categories it was found in, see UniqueValues
+	Values []UniqueValue `json:"values,omitempty"`
+	// Xref cross-references the findings, e.g. domains found in parameter values
+	Xref *xref.Report `json:"xref,omitempty"`
+}
+
+// WriteJSON writes findings as an indented JSON document.
+// When run is non-nil it is included as the document header.
+func WriteJSON(w io.Writer, run *Run, findings []finding.Finding) error {
+	return WriteDocument(w, Document{Run: run, Findings: findings})
+}
+
+// WriteDocument writes doc as indented JSON, setting its schema version and ordering its
+// findings with finding.Sort. Parameters carry their key and value in the "key" and "value"
+// metadata so consumers need not split "key=value" themselves; parameters that already carry
+// either, such as the keys or values listed by -params-mode, are written unchanged.
+func WriteDocument(w io.Writer, doc Document) error {
+	sorted := make([]finding.Finding, len(doc.Findings))
+	copy(sorted, doc.Findings)
+	finding.Sort(sorted)
+	for i, f := range sorted {
+		if f.Type == finding.TypeParam && f.Metadata["key"] == "" && f.Metadata["value"] == "" {
+			sorted[i] = splitParam(f)
+		}
+	}
+	doc.SchemaVersion = SchemaVersion
+	doc.Findings = sorted
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// splitParam returns a copy of the parameter finding f with its key and value recorded as
+// metadata; the metadata of f itself is left untouched
+func splitParam(f finding.Finding) finding.Finding {
+	key, value, _ := strings.Cut(f.Value, "=")
+	meta := make(map[string]string, len(f.Metadata)+2)
+	for k, v := range f.Metadata {
+		meta[k] = v
+	}
+	meta["key"], meta["value"] = key, value
+	f.Metadata = meta
+	return f
+}
@@ -0,0 +1,52 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+// Limit keeps at most max findings of each type, choosing the first ones in Sort order so
+// repeated runs keep the same findings, and returns how many of each type were dropped.
+// A max of 0 or less keeps everything.
+func Limit(findings []finding.Finding, max int) ([]finding.Finding, map[finding.Type]int) {
+	if max <= 0 {
+		return findings, nil
+	}
+	sorted := make([]finding.Finding, len(findings))
+	copy(sorted, findings)
+	finding.Sort(sorted)
+
+	kept := sorted[:0]
+	counts := make(map[finding.Type]int)
+	truncated := make(map[finding.Type]int)
+	for _, f := range sorted {
+		if counts[f.Type] >= max {
+			truncated[f.Type]++
+			continue
+		}
+		counts[f.Type]++
+		kept = append(kept, f)
+	}
+	if len(truncated) == 0 {
+		truncated = nil
+	}
+	return kept, truncated
+}
+
+// WriteTruncated summarizes the findings left out by Limit, one line per type in the order of finding.Types
+func WriteTruncated(w io.Writer, truncated map[finding.Type]int, max int) error {
+	if len(truncated) == 0 {
+		return nil
+	}
+	var parts []string
+	for _, t := range finding.Types {
+		if n := truncated[t]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d more %s", n, label(t)))
+		}
+	}
+	_, err := fmt.Fprintf(w, "\nTruncated to %d per category: %s not shown\n", max, strings.Join(parts, ", "))
+	return err
+}
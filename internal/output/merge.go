@@ -0,0 +1,80 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+// FirstSeenKey is the metadata key recording when a merged finding was first reported
+const FirstSeenKey = "first_seen"
+
+// ReadDocument parses a document written by WriteDocument. Documents without a schema
+// version, or with a different major version than SchemaVersion, are rejected.
+func ReadDocument(r io.Reader) (Document, error) {
+	var doc Document
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return Document{}, fmt.Errorf("invalid JSON document: %w", err)
+	}
+	if doc.SchemaVersion == "" {
+		return Document{}, fmt.Errorf("not a urlsluice JSON document: schema_version is missing")
+	}
+	major, _, _ := strings.Cut(doc.SchemaVersion, ".")
+	if want, _, _ := strings.Cut(SchemaVersion, "."); major != want {
+		return Document{}, fmt.Errorf("unsupported schema version %s (want %s.x)", doc.SchemaVersion, want)
+	}
+	return doc, nil
+}
+
+// MergeDocuments unions the findings of docs, keeping one finding per Finding.Key as
+// finding.Set does. Every finding records when it was first seen in the "first_seen"
+// metadata: the time it already carries from an earlier merge, or else the start of the
+// run that produced its document. The earliest occurrence of a finding is kept, so its
+// first_seen, source and line come from the oldest run that reported it.
+func MergeDocuments(docs []Document) []finding.Finding {
+	type seen struct {
+		f  finding.Finding
+		at string
+	}
+	var all []seen
+	for _, doc := range docs {
+		started := ""
+		if doc.Run != nil && !doc.Run.StartedAt.IsZero() {
+			started = doc.Run.StartedAt.UTC().Format(time.RFC3339)
+		}
+		for _, f := range doc.Findings {
+			at := f.Metadata[FirstSeenKey]
+			if at == "" {
+				at = started
+			}
+			all = append(all, seen{f: f, at: at})
+		}
+	}
+	// RFC 3339 times in UTC sort as strings; findings without a time sort last
+	sort.SliceStable(all, func(i, j int) bool {
+		if (all[i].at == "") != (all[j].at == "") {
+			return all[j].at == ""
+		}
+		return all[i].at < all[j].at
+	})
+
+	var set finding.Set
+	for _, s := range all {
+		f := s.f
+		if s.at != "" && f.Metadata[FirstSeenKey] == "" {
+			meta := make(map[string]string, len(f.Metadata)+1)
+			for k, v := range f.Metadata {
+				meta[k] = v
+			}
+			meta[FirstSeenKey] = s.at
+			f.Metadata = meta
+		}
+		set.Add(f)
+	}
+	return set.Findings()
+}
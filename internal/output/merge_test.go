@@ -0,0 +1,74 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+func TestReadDocument(t *testing.T) {
+	var buf bytes.Buffer
+	run := &Run{Tool: "urlsluice", StartedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}
+	if err := WriteJSON(&buf, run, testFindings); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := ReadDocument(&buf)
+	if err != nil {
+		t.Fatalf("ReadDocument() error = %v", err)
+	}
+	if doc.SchemaVersion != SchemaVersion || len(doc.Findings) != len(testFindings) || !doc.Run.StartedAt.Equal(run.StartedAt) {
+		t.Errorf("ReadDocument() = %+v", doc)
+	}
+
+	for input, wantErr := range map[string]string{
+		`{"findings": []}`:                          "schema_version is missing",
+		`{"schema_version": "2.0", "findings": []}`: "unsupported schema version 2.0",
+		`not json`: "invalid JSON document",
+	} {
+		if _, err := ReadDocument(strings.NewReader(input)); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("ReadDocument(%q) error = %v, want %q", input, err, wantErr)
+		}
+	}
+}
+
+func TestMergeDocuments(t *testing.T) {
+	older := Document{
+		Run: &Run{StartedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		Findings: []finding.Finding{
+			{Type: finding.TypeDomain, Value: "a.target.com", Source: "old.txt", Line: 4},
+			{Type: finding.TypeEmail, Value: "dev@target.com", Source: "old.txt", Tags: []string{"staff"}},
+		},
+	}
+	newer := Document{
+		Run: &Run{StartedAt: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+		Findings: []finding.Finding{
+			{Type: finding.TypeDomain, Value: "a.target.com", Source: "new.txt", Line: 1},
+			{Type: finding.TypeDomain, Value: "b.target.com", Source: "new.txt"},
+			{Type: finding.TypeEmail, Value: "dev@target.com", Source: "new.txt", Metadata: map[string]string{FirstSeenKey: "2025-12-01T00:00:00Z"}},
+		},
+	}
+
+	got := MergeDocuments([]Document{newer, older})
+	want := map[string]string{
+		"a.target.com":   "old.txt 2026-01-01T00:00:00Z",
+		"b.target.com":   "new.txt 2026-03-01T00:00:00Z",
+		"dev@target.com": "new.txt 2025-12-01T00:00:00Z",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("MergeDocuments() = %v, want %d findings", got, len(want))
+	}
+	for _, f := range got {
+		if s := f.Source + " " + f.Metadata[FirstSeenKey]; s != want[f.Value] {
+			t.Errorf("%s = %q, want %q", f.Value, s, want[f.Value])
+		}
+		if f.Value == "dev@target.com" && !f.HasTag("staff") {
+			t.Errorf("dev@target.com lost the tags of the older run: %v", f.Tags)
+		}
+	}
+	if newer.Findings[0].Metadata != nil {
+		t.Errorf("MergeDocuments() modified its input: %v", newer.Findings[0].Metadata)
+	}
+}
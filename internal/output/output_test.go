@@ -0,0 +1,419 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/redirect"
+	"github.com/PeteJStewart/urlsluice/internal/xss"
+)
+
+var testFindings = []finding.Finding{
+	{Type: finding.TypeDomain, Value: "b.target.com", Confidence: finding.ConfidenceHigh},
+	{Type: finding.TypeEmail, Value: "dev@target.com", Line: 3, Source: "input.txt"},
+	{Type: finding.TypeDomain, Value: "a.target.com", Metadata: map[string]string{"status": "200"}},
+}
+
+func TestWriteText(t *testing.T) {
+	tests := []struct {
+		name   string
+		silent bool
+		want   string
+	}{
+		{
+			name: "with titles",
+			want: "\nExtracted Emails:\ndev@target.com\n\nExtracted Domains:\na.target.com [200]\nb.target.com\n",
+		},
+		{
+			name:   "silent",
+			silent: true,
+			want:   "dev@target.com\na.target.com\nb.target.com\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteText(&buf, testFindings, tt.silent); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("WriteText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteText_InternalHosts(t *testing.T) {
+	findings := []finding.Finding{
+		{Type: finding.TypeDomain, Value: "www.target.com"},
+		{Type: finding.TypeDomain, Value: "jira.corp.target.com", Tags: []string{finding.TagInternal}},
+		{Type: finding.TypeIP, Value: "10.0.0.5", Tags: []string{finding.TagInternal}},
+		{Type: finding.TypeURL, Value: "http://10.0.0.5/admin", Tags: []string{finding.TagInternal}},
+	}
+
+	// By default internal hosts stay in the sections of their types
+	var buf bytes.Buffer
+	if err := WriteText(&buf, findings, false); err != nil {
+		t.Fatal(err)
+	}
+	want := "\nExtracted Domains:\njira.corp.target.com\nwww.target.com\n\nExtracted IP Addresses:\n10.0.0.5\n\nExtracted URLs:\nhttp://10.0.0.5/admin\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteText() = %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	if err := WriteTextWith(&buf, findings, TextOptions{InternalSection: true}); err != nil {
+		t.Fatal(err)
+	}
+	want = "\nExtracted Domains:\nwww.target.com\n\nExtracted URLs:\nhttp://10.0.0.5/admin\n" +
+		"\nExtracted Internal Hosts:\njira.corp.target.com\n10.0.0.5\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteTextWith() with an internal section = %q, want %q", got, want)
+	}
+}
+
+func TestWriteText_Location(t *testing.T) {
+	findings := []finding.Finding{
+		{Type: finding.TypeDomain, Value: "registry.target.com", Metadata: map[string]string{"yaml_path": "spec.containers[0].image", "resource": "Pod/api"}},
+		{Type: finding.TypeConfigSecret, Value: "password=hunter2", Metadata: map[string]string{"yaml_path": "db.password"}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteText(&buf, findings, false); err != nil {
+		t.Fatal(err)
+	}
+	want := "\nExtracted Domains:\nregistry.target.com (Pod/api spec.containers[0].image)\n" +
+		"\nExtracted Config Secrets:\npassword=hunter2 (db.password)\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteText() = %q, want %q", got, want)
+	}
+}
+
+func TestWriteTextBySource(t *testing.T) {
+	findings := []finding.Finding{
+		{Type: finding.TypeURL, Value: "https://api.target.com/", Source: "app.apk!classes.dex"},
+		{Type: finding.TypeDomain, Value: "api.target.com", Source: "app.apk!classes.dex"},
+		{Type: finding.TypeDomain, Value: "target.com", Source: "app.apk!AndroidManifest.xml"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTextBySource(&buf, findings, TextOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	want := "\n== app.apk!AndroidManifest.xml ==\n\nExtracted Domains:\ntarget.com\n" +
+		"\n== app.apk!classes.dex ==\n\nExtracted Domains:\napi.target.com\n\nExtracted URLs:\nhttps://api.target.com/\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteTextBySource() = %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	if err := WriteTextBySource(&buf, findings, TextOptions{Silent: true}); err != nil {
+		t.Fatal(err)
+	}
+	want = "target.com\napi.target.com\nhttps://api.target.com/\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteTextBySource() silent = %q, want %q", got, want)
+	}
+}
+
+func TestWriteFinding(t *testing.T) {
+	findings := []finding.Finding{
+		{Type: finding.TypeEmail, Value: "admin@target.com"},
+		{Type: finding.TypeDomain, Value: "jira.corp.target.com", Tags: []string{finding.TagInternal}},
+		{Type: finding.TypeConfigSecret, Value: "password=hunter2", Metadata: map[string]string{"yaml_path": "db.password"}},
+	}
+
+	var buf, silent bytes.Buffer
+	for _, f := range findings {
+		if err := WriteFinding(&buf, f, TextOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		if err := WriteFinding(&silent, f, TextOptions{Silent: true}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := "Emails: admin@target.com\nDomains: jira.corp.target.com\nConfig Secrets: password=hunter2 (db.password)\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteFinding() = %q, want %q", got, want)
+	}
+	buf.Reset()
+	if err := WriteFinding(&buf, findings[1], TextOptions{InternalSection: true}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "Internal Hosts: jira.corp.target.com\n"; got != want {
+		t.Errorf("WriteFinding() with an internal section = %q, want %q", got, want)
+	}
+	want = "admin@target.com\njira.corp.target.com\npassword=hunter2\n"
+	if got := silent.String(); got != want {
+		t.Errorf("WriteFinding() silent = %q, want %q", got, want)
+	}
+}
+
+func TestWriteTextByHost(t *testing.T) {
+	findings := []finding.Finding{
+		{Type: finding.TypeURL, Value: "https://api.target.com/v1/users?id=5", Source: "urls.txt", Line: 1},
+		{Type: finding.TypeParam, Value: "id=5", Source: "urls.txt", Line: 1},
+		{Type: finding.TypeConfigSecret, Value: "DB_PASSWORD=hunter2", Source: ".env", Line: 3},
+		{Type: finding.TypeParam, Value: "q=test", Source: "https://www.target.com/search", Line: 12},
+		{Type: finding.TypeEmail, Value: "dev@Target.com", Source: "urls.txt", Line: 2},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTextByHost(&buf, findings, TextOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	want := "\n== api.target.com ==\n\nExtracted Query Parameters:\nid=5\n\nExtracted URLs:\nhttps://api.target.com/v1/users?id=5\n" +
+		"\n== target.com ==\n\nExtracted Emails:\ndev@Target.com\n" +
+		"\n== www.target.com ==\n\nExtracted Query Parameters:\nq=test\n" +
+		"\n== (no host) ==\n\nExtracted Config Secrets:\nDB_PASSWORD=hunter2\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteTextByHost() = %q, want %q", got, want)
+	}
+
+	annotated := WithHosts(findings)
+	hosts := make(map[string]string)
+	for _, f := range annotated {
+		hosts[f.Value] = f.Metadata["host"]
+	}
+	if hosts["id=5"] != "api.target.com" || hosts["DB_PASSWORD=hunter2"] != "" {
+		t.Errorf("WithHosts() hosts = %v", hosts)
+	}
+	if findings[1].Metadata != nil {
+		t.Error("WithHosts() modified its input")
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, nil, testFindings); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc Document
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+
+	want := []finding.Finding{testFindings[1], testFindings[2], testFindings[0]}
+	if !reflect.DeepEqual(doc.Findings, want) {
+		t.Errorf("WriteJSON() findings = %+v, want %+v", doc.Findings, want)
+	}
+	if doc.Run != nil {
+		t.Errorf("WriteJSON() run = %+v, want none", doc.Run)
+	}
+}
+
+func TestWriteJSON_Params(t *testing.T) {
+	param := finding.Finding{Type: finding.TypeParam, Value: "redirect=/a=b", Metadata: map[string]string{"location": "fragment"}}
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, nil, []finding.Finding{param, {Type: finding.TypeParam, Value: "empty="}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc Document
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	want := []map[string]string{
+		{"key": "empty", "value": ""},
+		{"key": "redirect", "value": "/a=b", "location": "fragment"},
+	}
+	for i, f := range doc.Findings {
+		if !reflect.DeepEqual(f.Metadata, want[i]) {
+			t.Errorf("param %q metadata = %v, want %v", f.Value, f.Metadata, want[i])
+		}
+	}
+	if len(param.Metadata) != 1 {
+		t.Errorf("WriteJSON() modified the metadata of its input: %v", param.Metadata)
+	}
+}
+
+func TestUniqueValues(t *testing.T) {
+	findings := []finding.Finding{
+		{Type: finding.TypeParam, Value: "next=api.target.com"},
+		{Type: finding.TypeDomain, Value: "api.target.com"},
+		{Type: finding.TypeURL, Value: "https://api.target.com/"},
+		{Type: finding.TypeParam, Value: "debug="},
+		{Type: finding.TypeParam, Value: "id", Metadata: map[string]string{"key": "id"}},
+		{Type: finding.TypeParam, Value: "7", Metadata: map[string]string{"value": "7"}},
+	}
+	want := []UniqueValue{
+		{Value: "7", Types: []finding.Type{finding.TypeParam}},
+		{Value: "api.target.com", Types: []finding.Type{finding.TypeDomain, finding.TypeParam}},
+		{Value: "https://api.target.com/", Types: []finding.Type{finding.TypeURL}},
+		{Value: "id", Types: []finding.Type{finding.TypeParam}},
+	}
+	values := UniqueValues(findings)
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("UniqueValues() = %+v, want %+v", values, want)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteUniqueValues(&buf, values[:2], TextOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "\nExtracted Unique Values:\n7 [param]\napi.target.com [domain, param]\n"; buf.String() != want {
+		t.Errorf("WriteUniqueValues() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWriteJSON_Run(t *testing.T) {
+	run := &Run{
+		Tool:       "urlsluice",
+		Version:    "v1.2.3",
+		Command:    []string{"urlsluice", "-json"},
+		StartedAt:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		FinishedAt: time.Date(2024, 1, 2, 3, 4, 6, 0, time.UTC),
+	}
+	run.AddInput("input.txt", []byte("abc"))
+
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, run, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc Document
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if !reflect.DeepEqual(doc.Run, run) {
+		t.Errorf("WriteJSON() run = %+v, want %+v", doc.Run, run)
+	}
+	want := "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+	if doc.Run.Inputs[0].SHA256 != want || doc.Run.Inputs[0].Size != 3 {
+		t.Errorf("input = %+v, want sha256 %s and size 3", doc.Run.Inputs[0], want)
+	}
+}
+
+func TestLimit(t *testing.T) {
+	kept, truncated := Limit(testFindings, 1)
+	var values []string
+	for _, f := range kept {
+		values = append(values, f.Value)
+	}
+	if want := []string{"dev@target.com", "a.target.com"}; !reflect.DeepEqual(values, want) {
+		t.Errorf("Limit() kept %v, want %v", values, want)
+	}
+	if want := map[finding.Type]int{finding.TypeDomain: 1}; !reflect.DeepEqual(truncated, want) {
+		t.Errorf("Limit() truncated %v, want %v", truncated, want)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTruncated(&buf, truncated, 1); err != nil {
+		t.Fatal(err)
+	}
+	if want := "\nTruncated to 1 per category: 1 more Domains not shown\n"; buf.String() != want {
+		t.Errorf("WriteTruncated() = %q, want %q", buf.String(), want)
+	}
+
+	if kept, truncated := Limit(testFindings, 0); len(kept) != len(testFindings) || truncated != nil {
+		t.Errorf("Limit(0) = %d findings, %v truncated; want everything kept", len(kept), truncated)
+	}
+}
+
+func TestWriteTextWith_Colors(t *testing.T) {
+	findings := []finding.Finding{
+		{Type: finding.TypeDomain, Value: "a.target.com", Metadata: map[string]string{"status": "200"}},
+		{Type: finding.TypeConfigSecret, Value: "DB_PASSWORD=hunter2"},
+	}
+	colors := Palette{Enabled: true}
+
+	var buf bytes.Buffer
+	if err := WriteTextWith(&buf, findings, TextOptions{Colors: colors}); err != nil {
+		t.Fatal(err)
+	}
+	want := "\n" + colors.Title("Extracted Domains:") + "\na.target.com" + colors.Detail(" [200]") + "\n" +
+		"\n" + colors.Title("Extracted Config Secrets:") + "\n" + colors.Risk("DB_PASSWORD=hunter2") + "\n"
+	if buf.String() != want {
+		t.Errorf("WriteTextWith() = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := WriteTextWith(&buf, findings, TextOptions{Silent: true, Colors: colors}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "a.target.com\nDB_PASSWORD=hunter2\n"; buf.String() != want {
+		t.Errorf("silent WriteTextWith() = %q, want %q without colors", buf.String(), want)
+	}
+}
+
+func TestWriteRedirects(t *testing.T) {
+	results := []redirect.RedirectResult{
+		{URL: "https://target.com/login?next=https://evil.com", IsVulnerable: true, MatchedParams: []redirect.MatchedParameter{
+			{Name: "next", Value: "https://evil.com", IsKnown: true, Confidence: finding.ConfidenceHigh, Heuristic: "known-param-url"},
+		}},
+		{URL: "https://target.com/", IsVulnerable: false},
+	}
+	colors := Palette{Enabled: true}
+
+	var buf bytes.Buffer
+	if err := WriteRedirects(&buf, results, TextOptions{Colors: colors}); err != nil {
+		t.Fatal(err)
+	}
+	want := "\n" + colors.Title("Potential Open Redirects:") + "\nhttps://target.com/login?next=https://evil.com\n" +
+		colors.Highlight("  Parameter: next = https://evil.com (Known: true, Confidence: high, Rule: known-param-url)") + "\n\n"
+	if buf.String() != want {
+		t.Errorf("WriteRedirects() = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := WriteRedirects(&buf, results, TextOptions{Silent: true, Colors: colors}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "https://target.com/login?next=https://evil.com\n"; buf.String() != want {
+		t.Errorf("silent WriteRedirects() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWriteRemediation(t *testing.T) {
+	r := finding.Remediation{Hint: "Redirect only to relative paths", Reference: "https://wiki.corp.example/redirects"}
+	var buf bytes.Buffer
+	if err := WriteRemediation(&buf, r, TextOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "Remediation: Redirect only to relative paths\nReference: https://wiki.corp.example/redirects\n"; buf.String() != want {
+		t.Errorf("WriteRemediation() = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := WriteRemediation(&buf, r, TextOptions{Silent: true}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("silent WriteRemediation() = %q, want nothing", buf.String())
+	}
+}
+
+func TestWriteXSSParams(t *testing.T) {
+	results := []xss.XSSResult{
+		{URL: "https://target.com/?q=<b>&callback=cb", IsVulnerable: true, MatchedParams: []xss.MatchedParameter{
+			{Name: "q", Value: "<b>", Reason: xss.ReasonMarkup},
+			{Name: "callback", Value: "cb", Reason: xss.ReasonSink},
+		}},
+		{URL: "https://target.com/", IsVulnerable: false},
+	}
+	colors := Palette{Enabled: true}
+
+	var buf bytes.Buffer
+	if err := WriteXSSParams(&buf, results, TextOptions{Colors: colors}); err != nil {
+		t.Fatal(err)
+	}
+	want := "\n" + colors.Title("Potential XSS Parameters:") + "\nhttps://target.com/?q=<b>&callback=cb\n" +
+		colors.Highlight("  Parameter: q = <b> (Reason: markup)") + "\n  Parameter: callback = cb (Reason: sink)\n\n"
+	if buf.String() != want {
+		t.Errorf("WriteXSSParams() = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := WriteXSSParams(&buf, results, TextOptions{Silent: true, Colors: colors}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "https://target.com/?q=<b>&callback=cb\n"; buf.String() != want {
+		t.Errorf("silent WriteXSSParams() = %q, want %q", buf.String(), want)
+	}
+}
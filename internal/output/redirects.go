@@ -0,0 +1,70 @@
+package output
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/redirect"
+)
+
+// WriteRedirects writes the URLs with potential open redirects followed by the parameters
+// that matched, most confident first, with the heuristic that matched each one; parameters
+// from the known redirect list are highlighted. In silent mode only the URLs are written.
+func WriteRedirects(w io.Writer, results []redirect.RedirectResult, opts TextOptions) error {
+	colors := opts.Colors
+	if opts.Silent {
+		colors = Palette{}
+	} else if _, err := fmt.Fprintf(w, "\n%s\n", colors.Title("Potential Open Redirects:")); err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		if !result.IsVulnerable {
+			continue
+		}
+		if _, err := fmt.Fprintln(w, result.URL); err != nil {
+			return err
+		}
+		if opts.Silent {
+			continue
+		}
+		for _, param := range result.MatchedParams {
+			detail := fmt.Sprintf("Known: %v", param.IsKnown)
+			if param.Confidence != "" {
+				detail += fmt.Sprintf(", Confidence: %s", param.Confidence)
+			}
+			if param.Heuristic != "" {
+				detail += fmt.Sprintf(", Rule: %s", param.Heuristic)
+			}
+			line := fmt.Sprintf("  Parameter: %s = %s (%s)", param.Name, param.Value, detail)
+			if param.IsKnown {
+				line = colors.Highlight(line)
+			}
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteRemediation writes the guidance for the findings above it. Nothing is written in
+// silent mode, which only lists values.
+func WriteRemediation(w io.Writer, r finding.Remediation, opts TextOptions) error {
+	if opts.Silent {
+		return nil
+	}
+	if _, err := fmt.Fprintf(w, "Remediation: %s\n", r.Hint); err != nil {
+		return err
+	}
+	if r.Reference != "" {
+		if _, err := fmt.Fprintf(w, "Reference: %s\n", r.Reference); err != nil {
+			return err
+		}
+	}
+	return nil
+}
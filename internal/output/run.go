@@ -0,0 +1,63 @@
+package output
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+// Run records the provenance of a document so findings can be reproduced and audited
+type Run struct {
+	// Tool is the name of the program that produced the document
+	Tool string `json:"tool"`
+	// Version is the version of the tool
+	Version string `json:"version"`
+	// Build describes how the binary was built
+	Build *Build `json:"build,omitempty"`
+	// Command is the command line, with header values redacted
+	Command []string `json:"command"`
+	// Inputs lists the files that were scanned
+	Inputs []Input `json:"inputs,omitempty"`
+	// ConfigSHA256 is the checksum of the configuration file, if one was used
+	ConfigSHA256 string `json:"config_sha256,omitempty"`
+	// Truncated counts the findings of each type left out by a per-category limit
+	Truncated map[finding.Type]int `json:"truncated,omitempty"`
+	// StartedAt is when the run started
+	StartedAt time.Time `json:"started_at"`
+	// FinishedAt is when the document was written
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+// Build identifies the source and toolchain a binary was built from
+type Build struct {
+	// Commit is the VCS revision, with a "-dirty" suffix for modified trees
+	Commit string `json:"commit,omitempty"`
+	// Date is when the binary was built, or the commit time when unknown
+	Date string `json:"date,omitempty"`
+	// GoVersion is the Go toolchain version
+	GoVersion string `json:"go_version"`
+	// Platform is the target as "os/arch"
+	Platform string `json:"platform"`
+	// Features lists optional capabilities compiled into the binary
+	Features []string `json:"features,omitempty"`
+}
+
+// Input identifies a scanned file by path and content hash
+type Input struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+// AddInput records a scanned file and the checksum of its contents
+func (r *Run) AddInput(path string, data []byte) {
+	sum := sha256.Sum256(data)
+	r.Inputs = append(r.Inputs, Input{Path: path, SHA256: hex.EncodeToString(sum[:]), Size: int64(len(data))})
+}
+
+// AddInputSum records a scanned file read as a stream, given the SHA-256 and size of its contents
+func (r *Run) AddInputSum(path string, sum []byte, size int64) {
+	r.Inputs = append(r.Inputs, Input{Path: path, SHA256: hex.EncodeToString(sum), Size: size})
+}
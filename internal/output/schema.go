@@ -0,0 +1,13 @@
+package output
+
+import _ "embed"
+
+// SchemaVersion is the version of the JSON document format written by WriteJSON.
+// The minor version is bumped when optional fields or finding types are added and
+// the major version when existing fields change meaning or are removed.
+const SchemaVersion = "1.18"
+
+// Schema is the JSON Schema describing documents written by WriteJSON
+//
+//go:embed schema.json
+var Schema []byte
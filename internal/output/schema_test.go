@@ -0,0 +1,182 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+// validate checks value against the subset of JSON Schema used by schema.json:
+// $ref to $defs, type, required, properties, additionalProperties, items, enum,
+// pattern, minimum and the date-time format
+func validate(root, schema map[string]interface{}, value interface{}, path string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/$defs/")
+		def, ok := root["$defs"].(map[string]interface{})[name].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: unknown $ref %q", path, ref)
+		}
+		return validate(root, def, value, path)
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			if reflect.DeepEqual(e, value) {
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s: %v is not one of %v", path, value, enum)
+		}
+	}
+
+	switch schema["type"] {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: want object, got %T", path, value)
+		}
+		required, _ := schema["required"].([]interface{})
+		for _, r := range required {
+			if _, ok := obj[r.(string)]; !ok {
+				return fmt.Errorf("%s: missing required property %q", path, r)
+			}
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		for key, v := range obj {
+			sub, ok := properties[key].(map[string]interface{})
+			if !ok {
+				switch extra := schema["additionalProperties"].(type) {
+				case bool:
+					if !extra {
+						return fmt.Errorf("%s: unexpected property %q", path, key)
+					}
+					continue
+				case map[string]interface{}:
+					sub = extra
+				default:
+					continue
+				}
+			}
+			if err := validate(root, sub, v, path+"."+key); err != nil {
+				return err
+			}
+		}
+	case "array":
+		arr, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: want array, got %T", path, value)
+		}
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, v := range arr {
+				if err := validate(root, items, v, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s: want string, got %T", path, value)
+		}
+		if pattern, ok := schema["pattern"].(string); ok && !regexp.MustCompile(pattern).MatchString(s) {
+			return fmt.Errorf("%s: %q does not match %s", path, s, pattern)
+		}
+		if schema["format"] == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, s); err != nil {
+				return fmt.Errorf("%s: %q is not a date-time", path, s)
+			}
+		}
+	case "integer":
+		n, ok := value.(float64)
+		if !ok || n != float64(int64(n)) {
+			return fmt.Errorf("%s: want integer, got %v", path, value)
+		}
+		if min, ok := schema["minimum"].(float64); ok && n < min {
+			return fmt.Errorf("%s: %v is less than %v", path, n, min)
+		}
+	}
+	return nil
+}
+
+func loadSchema(t *testing.T) map[string]interface{} {
+	t.Helper()
+	var schema map[string]interface{}
+	if err := json.Unmarshal(Schema, &schema); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+	return schema
+}
+
+func TestSchema_ValidatesOutput(t *testing.T) {
+	schema := loadSchema(t)
+
+	run := &Run{
+		Tool:         "urlsluice",
+		Version:      "dev",
+		Build:        &Build{Commit: "abc123", GoVersion: "go1.21.0", Platform: "linux/amd64", Features: []string{"signed-updates"}},
+		Command:      []string{"urlsluice", "-json"},
+		ConfigSHA256: strings.Repeat("a", 64),
+		Truncated:    map[finding.Type]int{finding.TypeURL: 12},
+		StartedAt:    time.Now().UTC(),
+		FinishedAt:   time.Now().UTC(),
+	}
+	run.AddInput("input.txt", []byte("data"))
+	findings := append([]finding.Finding{{
+		Type:       finding.TypeURL,
+		Value:      "https://target.com/",
+		Source:     "input.txt",
+		Line:       4,
+		Tags:       []string{finding.TagInternal},
+		Confidence: finding.ConfidenceMedium,
+		Metadata:   map[string]string{"status": "200"},
+	}}, testFindings...)
+
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, run, findings); err != nil {
+		t.Fatal(err)
+	}
+	var doc interface{}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if err := validate(schema, schema, doc, "$"); err != nil {
+		t.Errorf("output does not match schema: %v", err)
+	}
+
+	var invalid interface{}
+	json.Unmarshal([]byte(`{"schema_version": "1.0", "findings": [{"type": "bogus", "value": "x"}]}`), &invalid)
+	if err := validate(schema, schema, invalid, "$"); err == nil {
+		t.Error("document with an unknown finding type passed validation")
+	}
+}
+
+func TestSchema_CoversFindingTypes(t *testing.T) {
+	schema := loadSchema(t)
+	props := schema["$defs"].(map[string]interface{})["finding"].(map[string]interface{})["properties"].(map[string]interface{})
+	var enum []string
+	for _, e := range props["type"].(map[string]interface{})["enum"].([]interface{}) {
+		enum = append(enum, e.(string))
+	}
+
+	var types []string
+	for _, t := range finding.Types {
+		types = append(types, string(t))
+	}
+	if !reflect.DeepEqual(enum, types) {
+		t.Errorf("schema finding types = %v, want finding.Types %v; update schema.json and bump SchemaVersion", enum, types)
+	}
+
+	version := schema["properties"].(map[string]interface{})["schema_version"].(map[string]interface{})["pattern"].(string)
+	if !regexp.MustCompile(version).MatchString(SchemaVersion) {
+		t.Errorf("SchemaVersion %q does not match schema pattern %s", SchemaVersion, version)
+	}
+}
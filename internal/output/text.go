@@ -0,0 +1,246 @@
+// Package output renders findings for terminals and downstream tools.
+package output
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+// Labels maps finding types to the section titles used in text output
+var Labels = map[finding.Type]string{
+	finding.TypeUUID:          "UUIDs",
+	finding.TypeEmail:         "Emails",
+	finding.TypeDomain:        "Domains",
+	finding.TypeIP:            "IP Addresses",
+	finding.TypeParam:         "Query Parameters",
+	finding.TypeURL:           "URLs",
+	finding.TypeToken:         "High Entropy Strings",
+	finding.TypeHandle:        "Handles",
+	finding.TypeCrypto:        "Cryptocurrency Addresses",
+	finding.TypeCloudConfig:   "Cloud Service Keys",
+	finding.TypeConfigSecret:  "Config Secrets",
+	finding.TypeTimestamp:     "Timestamps",
+	finding.TypeHeaderIssue:   "Security Header Issues",
+	finding.TypeUsername:      "Usernames",
+	finding.TypeAvatarHash:    "Avatar Hashes",
+	finding.TypeBucket:        "Storage Buckets",
+	finding.TypeTakeover:      "Takeover Candidates",
+	finding.TypeJSONP:         "JSONP Endpoints",
+	finding.TypeCORS:          "CORS Misconfigurations",
+	finding.TypeVerboseError:  "Verbose Errors",
+	finding.TypeComment:       "Comments",
+	finding.TypeLocale:        "Locales and i18n Resources",
+	finding.TypeTrackingParam: "Tracking Parameters",
+	finding.TypePlugin:        "Plugin Findings",
+}
+
+// internalHostsLabel titles the section listing hosts tagged as internal
+const internalHostsLabel = "Internal Hosts"
+
+// TextOptions controls how WriteTextWith renders findings
+type TextOptions struct {
+	// Silent omits section titles and annotations so the output can be piped to other tools
+	Silent bool
+	// Colors highlights titles and high-risk findings; it is ignored in silent mode
+	Colors Palette
+	// InternalSection lists the domains and IPs tagged as internal in a final "Internal
+	// Hosts" section instead of the sections of their types
+	InternalSection bool
+}
+
+// WriteText writes findings grouped into one section per type.
+// Sections follow the order of finding.Types and values within a section are sorted.
+// Findings checked over HTTP are annotated with their status and content length.
+// In silent mode the section titles and annotations are omitted so the output can be piped to other tools.
+func WriteText(w io.Writer, findings []finding.Finding, silent bool) error {
+	return WriteTextWith(w, findings, TextOptions{Silent: silent})
+}
+
+// WriteTextWith writes findings like WriteText with the given options. With
+// opts.InternalSection, domains and IPs tagged as internal are listed in a final "Internal
+// Hosts" section instead.
+func WriteTextWith(w io.Writer, findings []finding.Finding, opts TextOptions) error {
+	silent := opts.Silent
+	colors := opts.Colors
+	if silent {
+		colors = Palette{}
+	}
+
+	sorted := make([]finding.Finding, len(findings))
+	copy(sorted, findings)
+	finding.Sort(sorted)
+
+	var regular, internal []finding.Finding
+	for _, f := range sorted {
+		if opts.InternalSection && isInternalHost(f) {
+			internal = append(internal, f)
+		} else {
+			regular = append(regular, f)
+		}
+	}
+
+	var current string
+	for _, f := range regular {
+		if l := label(f.Type); l != current {
+			current = l
+			if err := writeTitle(w, l, silent, colors); err != nil {
+				return err
+			}
+		}
+		if err := writeFinding(w, f, silent, colors); err != nil {
+			return err
+		}
+	}
+
+	if len(internal) > 0 {
+		if err := writeTitle(w, internalHostsLabel, silent, colors); err != nil {
+			return err
+		}
+		for _, f := range internal {
+			if err := writeFinding(w, f, silent, colors); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WriteTextBySource writes findings like WriteTextWith in one block per source, ordered by
+// source name. Each block is headed by the source unless opts.Silent is set.
+func WriteTextBySource(w io.Writer, findings []finding.Finding, opts TextOptions) error {
+	bySource := make(map[string][]finding.Finding)
+	var sources []string
+	for _, f := range findings {
+		if _, ok := bySource[f.Source]; !ok {
+			sources = append(sources, f.Source)
+		}
+		bySource[f.Source] = append(bySource[f.Source], f)
+	}
+	sort.Strings(sources)
+
+	for _, source := range sources {
+		if !opts.Silent {
+			if _, err := fmt.Fprintf(w, "\n%s\n", opts.Colors.Title("== "+source+" ==")); err != nil {
+				return err
+			}
+		}
+		if err := WriteTextWith(w, bySource[source], opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteFinding writes a single finding on a line of its own, for output streamed while the
+// input is still being read. Findings of every type are interleaved, so unless opts.Silent
+// is set each line starts with the section title of its type, such as "Emails: ".
+func WriteFinding(w io.Writer, f finding.Finding, opts TextOptions) error {
+	if opts.Silent {
+		return writeFinding(w, f, true, Palette{})
+	}
+	title := label(f.Type)
+	if opts.InternalSection && isInternalHost(f) {
+		title = internalHostsLabel
+	}
+	if _, err := fmt.Fprint(w, opts.Colors.Title(title+":")+" "); err != nil {
+		return err
+	}
+	return writeFinding(w, f, false, opts.Colors)
+}
+
+func writeTitle(w io.Writer, title string, silent bool, colors Palette) error {
+	if silent {
+		return nil
+	}
+	_, err := fmt.Fprintf(w, "\n%s\n", colors.Title("Extracted "+title+":"))
+	return err
+}
+
+func writeFinding(w io.Writer, f finding.Finding, silent bool, colors Palette) error {
+	line := f.Value
+	if HighRisk(f) {
+		line = colors.Risk(line)
+	}
+	if !silent {
+		line += colors.Detail(annotation(f) + location(f))
+	}
+	_, err := fmt.Fprintln(w, line)
+	return err
+}
+
+// isInternalHost reports whether f belongs in the internal hosts section, when there is one
+func isInternalHost(f finding.Finding) bool {
+	return (f.Type == finding.TypeDomain || f.Type == finding.TypeIP) && f.HasTag(finding.TagInternal)
+}
+
+func label(t finding.Type) string {
+	if l, ok := Labels[t]; ok {
+		return l
+	}
+	return string(t)
+}
+
+// location names the place in a structured document where f was found
+func location(f finding.Finding) string {
+	path, ok := f.Metadata["yaml_path"]
+	if !ok {
+		return ""
+	}
+	if resource := f.Metadata["resource"]; resource != "" {
+		return " (" + resource + " " + path + ")"
+	}
+	return " (" + path + ")"
+}
+
+// annotation summarizes the decoded time of timestamp findings, the email behind correlated
+// avatar hashes, the access level of probed buckets, why takeover candidates were reported,
+// the callback parameter of JSONP endpoints, the keywords found in comments, the plugin that
+// reported plugin findings, the number of occurrences of counted findings and the HTTP details
+// recorded by probing or enrichment
+func annotation(f finding.Finding) string {
+	if f.Type == finding.TypeTimestamp {
+		if t, ok := f.Metadata["time"]; ok {
+			return " [" + f.Metadata["kind"] + ", " + t + "]"
+		}
+	}
+	if email := f.Metadata["email"]; f.Type == finding.TypeAvatarHash && email != "" {
+		return " [" + email + "]"
+	}
+	if access := f.Metadata["s3_access"]; f.Type == finding.TypeBucket && access != "" {
+		return " [" + access + "]"
+	}
+	if reason := f.Metadata["reason"]; f.Type == finding.TypeTakeover && reason != "" {
+		if service := f.Metadata["service"]; service != "" {
+			reason = service + ": " + reason
+		}
+		return " [" + reason + "]"
+	}
+	if param := f.Metadata["param"]; f.Type == finding.TypeJSONP && param != "" {
+		return " [" + param + "]"
+	}
+	if keywords := f.Metadata["keywords"]; f.Type == finding.TypeComment && keywords != "" {
+		return " [" + keywords + "]"
+	}
+	if name := f.Metadata["plugin"]; f.Type == finding.TypePlugin && name != "" {
+		return " [" + name + "]"
+	}
+	if f.Count > 1 {
+		return fmt.Sprintf(" [seen %d times]", f.Count)
+	}
+	status, ok := f.Metadata["status"]
+	if !ok {
+		return ""
+	}
+	a := " [" + status
+	if length, ok := f.Metadata["content_length"]; ok {
+		a += ", " + length + " bytes"
+	}
+	a += "]"
+	if title := f.Metadata["title"]; title != "" {
+		a += " " + title
+	}
+	return a
+}
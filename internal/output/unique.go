@@ -0,0 +1,93 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+// UniqueValue is a distinct value together with every category it was found in
+type UniqueValue struct {
+	// Value is the matched text; parameters contribute their value rather than "key=value"
+	Value string `json:"value"`
+	// Types are the categories the value was found in, in the order of finding.Types
+	Types []finding.Type `json:"types"`
+}
+
+// UniqueValues merges findings with the same value across categories, e.g. a domain that is
+// also a parameter value, into one entry per value. Parameters without a value are left out.
+// The entries are sorted by value.
+func UniqueValues(findings []finding.Finding) []UniqueValue {
+	rank := make(map[finding.Type]int, len(finding.Types))
+	for i, t := range finding.Types {
+		rank[t] = i
+	}
+	types := make(map[string]map[finding.Type]bool)
+	for _, f := range findings {
+		value := f.Value
+		if f.Type == finding.TypeParam {
+			value = paramValue(f)
+		}
+		if value == "" {
+			continue
+		}
+		if types[value] == nil {
+			types[value] = make(map[finding.Type]bool)
+		}
+		types[value][f.Type] = true
+	}
+
+	values := make([]UniqueValue, 0, len(types))
+	for value, set := range types {
+		v := UniqueValue{Value: value}
+		for t := range set {
+			v.Types = append(v.Types, t)
+		}
+		sort.Slice(v.Types, func(i, j int) bool { return rank[v.Types[i]] < rank[v.Types[j]] })
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i].Value < values[j].Value })
+	return values
+}
+
+// paramValue returns the value of a parameter finding. Parameters reduced to their key or
+// value by -params-mode record it in the metadata; otherwise the value follows the first '='.
+func paramValue(f finding.Finding) string {
+	if value, ok := f.Metadata["value"]; ok {
+		return value
+	}
+	if _, ok := f.Metadata["key"]; ok {
+		return f.Value
+	}
+	_, value, _ := strings.Cut(f.Value, "=")
+	return value
+}
+
+// WriteUniqueValues writes one line per value, annotated with its categories unless
+// opts.Silent is set
+func WriteUniqueValues(w io.Writer, values []UniqueValue, opts TextOptions) error {
+	colors := opts.Colors
+	if opts.Silent {
+		colors = Palette{}
+	}
+	if err := writeTitle(w, "Unique Values", opts.Silent, colors); err != nil {
+		return err
+	}
+	for _, v := range values {
+		line := v.Value
+		if !opts.Silent {
+			names := make([]string, len(v.Types))
+			for i, t := range v.Types {
+				names[i] = string(t)
+			}
+			line += colors.Detail(" [" + strings.Join(names, ", ") + "]")
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
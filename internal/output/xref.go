@@ -0,0 +1,35 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/xref"
+)
+
+// WriteXref writes the cross-references of report, one section per kind, listing each
+// value with the findings referring to it
+func WriteXref(w io.Writer, report xref.Report, opts TextOptions) error {
+	sections := []struct {
+		title string
+		refs  []xref.Ref
+	}{
+		{"Domains in Parameter Values", report.ParamDomains},
+		{"Out-of-Scope Email Domains", report.ExternalEmails},
+	}
+	for _, s := range sections {
+		if len(s.refs) == 0 {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "\n%s\n", opts.Colors.Title(s.title+":")); err != nil {
+			return err
+		}
+		for _, r := range s.refs {
+			if _, err := fmt.Fprintf(w, "%s%s\n", r.Value, opts.Colors.Detail(" <- "+strings.Join(r.In, ", "))); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
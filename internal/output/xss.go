@@ -0,0 +1,44 @@
+package output
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/PeteJStewart/urlsluice/internal/xss"
+)
+
+// WriteXSSParams writes the URLs with XSS-prone parameters followed by the parameters that
+// matched; values carrying markup are highlighted. In silent mode only the URLs are written.
+func WriteXSSParams(w io.Writer, results []xss.XSSResult, opts TextOptions) error {
+	colors := opts.Colors
+	if opts.Silent {
+		colors = Palette{}
+	} else if _, err := fmt.Fprintf(w, "\n%s\n", colors.Title("Potential XSS Parameters:")); err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		if !result.IsVulnerable {
+			continue
+		}
+		if _, err := fmt.Fprintln(w, result.URL); err != nil {
+			return err
+		}
+		if opts.Silent {
+			continue
+		}
+		for _, param := range result.MatchedParams {
+			line := fmt.Sprintf("  Parameter: %s = %s (Reason: %s)", param.Name, param.Value, param.Reason)
+			if param.Reason == xss.ReasonMarkup {
+				line = colors.Highlight(line)
+			}
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
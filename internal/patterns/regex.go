@@ -2,6 +2,9 @@ package patterns
 
 import "regexp"
 
+// obfuscatedDot is a "." in an obfuscated email address, written plainly or as "[dot]", "(.)" or " dot "
+const obfuscatedDot = `(?:\s*[\[({<]\s*(?:dot|\.)\s*[\])}>]\s*|\s+dot\s+|\.)`
+
 var (
 	UUIDRegexMap = map[int]*regexp.Regexp{
 		1: regexp.MustCompile(`[a-f0-9]{8}-[a-f0-9]{4}-1[a-f0-9]{3}-[89ab][a-f0-9]{3}-[a-f0-9]{12}`),
@@ -11,8 +14,99 @@ var (
 		5: regexp.MustCompile(`[a-f0-9]{8}-[a-f0-9]{4}-5[a-f0-9]{3}-[89ab][a-f0-9]{3}-[a-f0-9]{12}`),
 	}
 
-	EmailRegex      = regexp.MustCompile(`[\w._%+-]+@[\w.-]+\.[a-zA-Z]{2,}`)
-	DomainRegex     = regexp.MustCompile(`https?://([a-zA-Z0-9.-]+)/?`)
-	IPRegex         = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
-	QueryParamRegex = regexp.MustCompile(`[?&]([^&=]+)=([^&=]*)`)
+	// UUIDAnyRegex matches RFC 4122 and RFC 9562 UUIDs of every version
+	UUIDAnyRegex = regexp.MustCompile(`[a-f0-9]{8}-[a-f0-9]{4}-[1-8][a-f0-9]{3}-[89ab][a-f0-9]{3}-[a-f0-9]{12}`)
+
+	EmailRegex = regexp.MustCompile(`[\w._%+-]+@[\w.-]+\.[a-zA-Z]{2,}`)
+	// ObfuscatedEmailRegex matches addresses written as "user [at] example [dot] com",
+	// "user(at)example.com" or "user at example dot com"; the submatches are the local part,
+	// the "at" and the domain
+	ObfuscatedEmailRegex = regexp.MustCompile(`(?i)\b([\w%+-]+(?:` + obfuscatedDot + `[\w%+-]+)*)(\s*[\[({<]\s*(?:at|@)\s*[\])}>]\s*|\s+at\s+)([\w-]+(?:` + obfuscatedDot + `[\w-]+)*` + obfuscatedDot + `[a-z]{2,})\b`)
+	// ObfuscatedDotRegex matches the "[dot]", "(.)" and " dot " separators of obfuscated addresses
+	ObfuscatedDotRegex = regexp.MustCompile(`(?i)\s*[\[({<]\s*(?:dot|\.)\s*[\])}>]\s*|\s+dot\s+`)
+	DomainRegex        = regexp.MustCompile(`https?://([a-zA-Z0-9.-]+)/?`)
+	IPRegex            = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+	// QueryStringRegex matches a query string or fragment up to the next quote or line break;
+	// parameters are split from it on '&' and ';', and '?' or '#' start a new section
+	QueryStringRegex = regexp.MustCompile(`[?#&][^"'<>` + "`" + `\r\n]+`)
+	URLRegex         = regexp.MustCompile(`https?://[^\s"'<>()\[\]{}\\^` + "`" + `]+`)
+	TokenRegex       = regexp.MustCompile(`[A-Za-z0-9+/_\-]{16,}={0,2}`)
+
+	// HandleRegexMap matches profile, organisation and invite URLs keyed by platform.
+	// The first submatch is the handle; hosts must not be a subdomain of another name.
+	HandleRegexMap = map[string]*regexp.Regexp{
+		"github":   regexp.MustCompile(`(?i)(?:^|[^\w.-])(?:www\.)?github\.com/([A-Za-z0-9][A-Za-z0-9-]{0,38}(?:/[A-Za-z0-9._-]+)?)`),
+		"gitlab":   regexp.MustCompile(`(?i)(?:^|[^\w.-])(?:www\.)?gitlab\.com/([A-Za-z0-9_.-]+(?:/[A-Za-z0-9_.-]+)?)`),
+		"twitter":  regexp.MustCompile(`(?i)(?:^|[^\w.-])(?:www\.|mobile\.)?(?:twitter|x)\.com/(?:#!/)?@?([A-Za-z0-9_]{1,15})\b`),
+		"linkedin": regexp.MustCompile(`(?i)(?:^|[^\w.-])(?:[a-z]{2,3}\.)?linkedin\.com/company/([A-Za-z0-9_-]+)`),
+		"discord":  regexp.MustCompile(`(?i)(?:^|[^\w.-])(?:discord\.gg|discord(?:app)?\.com/invite)/([A-Za-z0-9-]+)`),
+	}
+
+	// GoogleAPIKeyRegex matches Google API keys as embedded in Firebase and Maps configs
+	GoogleAPIKeyRegex = regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`)
+	// SentryDSNRegex matches Sentry DSNs with an optional legacy secret key
+	SentryDSNRegex = regexp.MustCompile(`https?://[0-9a-f]{32}(?::[0-9a-f]{32})?@[a-zA-Z0-9.-]+(?::\d+)?/\d+`)
+	// SegmentWriteKeyRegex matches Segment write keys passed to analytics.load or set as writeKey
+	SegmentWriteKeyRegex = regexp.MustCompile(`(?:analytics\.load\(\s*|["']?writeKey["']?\s*[:=]\s*)["']([A-Za-z0-9]{16,64})["']`)
+	// AmplitudeKeyRegex matches Amplitude API keys passed to the SDK init call
+	AmplitudeKeyRegex = regexp.MustCompile(`amplitude(?:\.getInstance\([^)]*\))?\.init\(\s*["']([0-9a-f]{32})["']`)
+	// ConfigKeyRegex captures the key a value is assigned to, anchored at the end of the preceding text
+	ConfigKeyRegex = regexp.MustCompile(`([A-Za-z_$][\w$-]*)["']?\s*[:=]\s*["'\x60]?$`)
+
+	// ConfigAssignmentRegex matches a dotenv, ini or YAML style assignment occupying a whole line
+	ConfigAssignmentRegex = regexp.MustCompile(`^\s*(?:export\s+)?["']?([A-Za-z_][A-Za-z0-9_.-]*)["']?\s*[=:]\s*(.*?)\s*$`)
+	// SensitiveKeyRegex matches configuration key names that usually hold credentials
+	SensitiveKeyRegex = regexp.MustCompile(`(?i)(passw(?:or)?d|passwd|pwd|secret|token|dsn|api_?key|access_?key|private_?key|credentials?)`)
+
+	// UserPathRegex matches account pages such as "/users/jdoe", "/u/jdoe", "/@jdoe" or "/~jdoe";
+	// the first or second submatch is the username
+	UserPathRegex = regexp.MustCompile(`(?i)/(?:users?|u|profiles?|members?|people|authors?|accounts?)/([A-Za-z0-9][A-Za-z0-9._-]{0,38})|/[@~]([A-Za-z0-9][A-Za-z0-9._-]{0,38})`)
+	// AuthorRegexes match author metadata: HTML meta tags, JSON and package manifests, JSDoc
+	// @author tags and "Author:" lines of commits and file headers; the first submatch is the author
+	AuthorRegexes = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)<meta\s+name=["']author["']\s+content=["']([^"'<>]+)["']`),
+		regexp.MustCompile(`(?i)["']author["']\s*:\s*(?:\{\s*["']name["']\s*:\s*)?["']([^"'<>]+)`),
+		regexp.MustCompile(`(?i)(?:@author|^\s*(?:#|//|\*)?\s*author:)\s+([^<>\r\n*]+?)\s*(?:<|\*/|$)`),
+	}
+
+	// AvatarURLRegex matches Gravatar and Libravatar avatar URLs; the submatch is the MD5 or SHA-256 email hash
+	AvatarURLRegex = regexp.MustCompile(`(?i)(?:gravatar\.com|libravatar\.org)/avatar/([0-9a-f]{64}|[0-9a-f]{32})\b`)
+	// AvatarHashRegex matches a 32 or 64 digit hex string shortly after an avatar or email hash
+	// key such as "avatar", "gravatar_id" or "emailHash"; the submatch is the hash
+	AvatarHashRegex = regexp.MustCompile(`(?i)(?:avatar|email_?hash|email_?md5)[\w"'\s:=/.-]{0,20}?\b([0-9a-f]{64}|[0-9a-f]{32})\b`)
+
+	// JSONPCallbackRegex matches a JavaScript function name or member path such as "jQuery.cb"
+	JSONPCallbackRegex = regexp.MustCompile(`^[A-Za-z_$][\w$]*(?:\.[A-Za-z_$][\w$]*)*$`)
+
+	// S3HostRegex matches virtual-hosted S3 endpoints such as "bucket.s3.us-west-2.amazonaws.com";
+	// the submatches are the bucket and the region, if any
+	S3HostRegex = regexp.MustCompile(`(?i)\b([a-z0-9][a-z0-9.-]{1,61}[a-z0-9])\.s3(?:-website)?(?:[.-](?:dualstack\.)?([a-z]{2}(?:-gov)?-[a-z]+-\d))?\.amazonaws\.com\b`)
+	// S3PathRegex matches path-style S3 URLs such as "https://s3.amazonaws.com/bucket/key";
+	// the submatches are the region, if any, and the bucket
+	S3PathRegex = regexp.MustCompile(`(?i)(?:^|[^\w.-])s3(?:[.-](?:dualstack\.)?([a-z]{2}(?:-gov)?-[a-z]+-\d))?\.amazonaws\.com/([a-z0-9][a-z0-9.-]{1,61}[a-z0-9])`)
+	// S3URIRegex matches "s3://bucket" URIs and "arn:aws:s3:::bucket" ARNs; the submatch is the bucket
+	S3URIRegex = regexp.MustCompile(`(?i)(?:\bs3://|\barn:aws[\w-]*:s3:::)([a-z0-9][a-z0-9.-]{1,61}[a-z0-9])`)
+	// GCSHostRegex matches virtual-hosted Google Cloud Storage endpoints such as
+	// "bucket.storage.googleapis.com"; the submatch is the bucket
+	GCSHostRegex = regexp.MustCompile(`(?i)\b([a-z0-9][a-z0-9._-]{1,61}[a-z0-9])\.storage\.googleapis\.com\b`)
+	// GCSPathRegex matches path-style Google Cloud Storage URLs, including JSON API URLs such as
+	// "storage.googleapis.com/storage/v1/b/bucket/o", and "gs://bucket" URIs; the submatch is the bucket
+	GCSPathRegex = regexp.MustCompile(`(?i)(?:(?:^|[^\w.-])storage\.(?:googleapis|cloud\.google)\.com/(?:(?:upload/)?storage/v1/b/)?|\bgs://)([a-z0-9][a-z0-9._-]{1,61}[a-z0-9])`)
+	// AzureBlobRegex matches Azure Blob Storage endpoints such as
+	// "account.blob.core.windows.net/container"; the submatches are the account and the container, if any
+	AzureBlobRegex = regexp.MustCompile(`(?i)\b([a-z0-9]{3,24})\.blob\.core\.windows\.net\b(?:/([a-z0-9](?:[a-z0-9-]{1,61}[a-z0-9])?)\b)?`)
+
+	// ULIDRegex matches ULIDs; the first character is at most 7 because the timestamp is 48 bits
+	ULIDRegex = regexp.MustCompile(`\b[0-7][0-9A-HJKMNP-TV-Z]{25}\b`)
+	// SnowflakeRegex matches 64-bit snowflake IDs as generated by Twitter and Discord
+	SnowflakeRegex = regexp.MustCompile(`\b[1-9]\d{16,18}\b`)
+	// EpochRegex matches a value of 10 (seconds) or 13 (milliseconds) digits
+	EpochRegex = regexp.MustCompile(`^(?:\d{10}|\d{13})$`)
+
+	// CryptoRegexMap matches cryptocurrency address shapes keyed by currency; checksums are verified separately
+	CryptoRegexMap = map[string]*regexp.Regexp{
+		"bitcoin":  regexp.MustCompile(`\b(?:[13][1-9A-HJ-NP-Za-km-z]{25,34}|(?i:bc1[ac-hj-np-z02-9]{11,71}))\b`),
+		"ethereum": regexp.MustCompile(`\b0x[0-9a-fA-F]{40}\b`),
+		"monero":   regexp.MustCompile(`\b[48][1-9A-HJ-NP-Za-km-z]{94}(?:[1-9A-HJ-NP-Za-km-z]{11})?\b`),
+	}
 )
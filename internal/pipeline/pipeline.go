@@ -0,0 +1,230 @@
+// Package pipeline runs the processing of inputs as a chain of concurrent stages connected by
+// bounded channels. Each stage works on the next batch while later stages are still busy with
+// earlier ones, and a full channel blocks the stage feeding it, so a slow stage such as network
+// enrichment throttles extraction instead of letting batches pile up in memory.
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+// DefaultBuffer is the default number of batches queued between two stages
+const DefaultBuffer = 8
+
+// Batch is the unit of work passed between stages: the raw data of one input, such as a file
+// or a crawled page, and the findings extracted from it
+type Batch struct {
+	// Source identifies the input, e.g. a file path or URL
+	Source string
+	// Data is the raw input; the extract stage releases it once findings are extracted
+	Data []byte
+	// Decoded reports that Data is already UTF-8 text, such as a chunk of an input read a
+	// chunk at a time
+	Decoded bool
+	// Line is the number of the first line of Data in the input when Data is a chunk of it,
+	// and 0 when Data is the whole input
+	Line int
+	// Findings are the findings extracted from Data
+	Findings []finding.Finding
+}
+
+// Emit passes a batch to the next stage, blocking while its queue is full.
+// It returns an error when the pipeline is cancelled.
+type Emit func(Batch) error
+
+// Source produces the batches entering the pipeline
+type Source func(ctx context.Context, emit Emit) error
+
+// Stage processes the batches of the previous stage, emitting any number of batches to the next
+type Stage struct {
+	// Name identifies the stage in statistics
+	Name string
+	// Timeout limits the time the stage may take from receiving its first batch (0 means no
+	// limit). Once it expires the context passed to Process is cancelled with a *TimeoutError
+	// cause; a stage that can do without its remaining work checks TimedOut and passes later
+	// batches on, otherwise the error it returns fails the pipeline.
+	Timeout time.Duration
+	// Process handles one batch; stages process their batches one at a time and in order
+	Process func(ctx context.Context, b Batch, emit Emit) error
+}
+
+// TimeoutError reports that a stage exceeded its timeout
+type TimeoutError struct {
+	Stage   string
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s stage timed out after %s", e.Stage, e.Timeout)
+}
+
+// Unwrap allows errors.Is(err, context.DeadlineExceeded)
+func (e *TimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// TimedOut reports whether ctx, a stage context, was cancelled because the stage exceeded its timeout
+func TimedOut(ctx context.Context) bool {
+	var timeout *TimeoutError
+	return errors.As(context.Cause(ctx), &timeout)
+}
+
+// Sink consumes the batches leaving the last stage
+type Sink func(Batch) error
+
+// Pipeline connects a source, stages and a sink
+type Pipeline struct {
+	// Buffer is the number of batches queued between stages (default DefaultBuffer)
+	Buffer int
+	// ReadTimeout limits the time the source may take (0 means no limit)
+	ReadTimeout time.Duration
+	// Stats, when not nil, receives the timings and counts of every stage
+	Stats *Stats
+}
+
+// Run feeds the batches produced by source ("read" in statistics) through stages into sink
+// ("output"). All stages run concurrently. The first error returned by the source, a stage or
+// the sink cancels the pipeline and is returned once every stage has stopped.
+func (p *Pipeline) Run(ctx context.Context, source Source, stages []Stage, sink Sink) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	buffer := p.Buffer
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+	stats := p.Stats
+	if stats == nil {
+		stats = &Stats{}
+	}
+
+	var (
+		once     sync.Once
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	read := stats.Stage("read")
+	out := make(chan Batch, buffer)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(out)
+		start := time.Now()
+		emit := emitter(ctx, out, read)
+		counted := func(b Batch) error {
+			read.Batches++
+			return emit(b)
+		}
+		sourceCtx, cancelSource := withStageTimeout(ctx, "read", p.ReadTimeout)
+		defer cancelSource()
+		if err := source(sourceCtx, counted); err != nil {
+			fail(stageError(sourceCtx, err))
+		}
+		read.TimedOut = TimedOut(sourceCtx)
+		read.Wall = time.Since(start)
+		read.Busy = read.Wall - read.Blocked
+	}()
+
+	in := out
+	for _, stage := range stages {
+		out := make(chan Batch, buffer)
+		wg.Add(1)
+		go func(stage Stage, in <-chan Batch, out chan Batch, st *StageStats) {
+			defer wg.Done()
+			defer close(out)
+			run(ctx, in, st, stage.Timeout, func(stageCtx context.Context, b Batch) error {
+				return stage.Process(stageCtx, b, emitter(ctx, out, st))
+			}, fail)
+		}(stage, in, out, stats.Stage(stage.Name))
+		in = out
+	}
+
+	run(ctx, in, stats.Stage("output"), 0, func(_ context.Context, b Batch) error {
+		return sink(b)
+	}, fail)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// run processes every batch from in with process, recording statistics in st. The stage
+// context passed to process expires timeout after the first batch. After the pipeline is
+// cancelled the remaining batches are drained so upstream stages can exit.
+func run(ctx context.Context, in <-chan Batch, st *StageStats, timeout time.Duration, process func(context.Context, Batch) error, fail func(error)) {
+	var (
+		start       time.Time
+		stageCtx    = ctx
+		cancelStage = func() {}
+	)
+	defer func() { cancelStage() }()
+
+	for b := range in {
+		if ctx.Err() != nil {
+			continue
+		}
+		if start.IsZero() {
+			start = time.Now()
+			stageCtx, cancelStage = withStageTimeout(ctx, st.Name, timeout)
+		}
+		st.Batches++
+		st.In += len(b.Findings)
+		blocked := st.Blocked
+		begin := time.Now()
+		if err := process(stageCtx, b); err != nil {
+			fail(stageError(stageCtx, err))
+		}
+		st.Busy += time.Since(begin) - (st.Blocked - blocked)
+	}
+	if !start.IsZero() {
+		st.Wall = time.Since(start)
+	}
+	st.TimedOut = TimedOut(stageCtx)
+}
+
+// withStageTimeout derives the context of a stage, cancelled with a *TimeoutError cause
+// after timeout; a zero timeout means no limit
+func withStageTimeout(ctx context.Context, stage string, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeoutCause(ctx, timeout, &TimeoutError{Stage: stage, Timeout: timeout})
+}
+
+// stageError replaces the error of a stage that ran out of time with its *TimeoutError
+func stageError(stageCtx context.Context, err error) error {
+	if TimedOut(stageCtx) {
+		return context.Cause(stageCtx)
+	}
+	return err
+}
+
+// emitter returns an Emit sending batches to out, recording the time spent waiting for room
+func emitter(ctx context.Context, out chan<- Batch, st *StageStats) Emit {
+	return func(b Batch) error {
+		begin := time.Now()
+		defer func() { st.Blocked += time.Since(begin) }()
+		select {
+		case out <- b:
+			st.Out += len(b.Findings)
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+// numbers emits n batches with one finding each
+func numbers(n int) Source {
+	return func(ctx context.Context, emit Emit) error {
+		for i := 0; i < n; i++ {
+			b := Batch{Source: strconv.Itoa(i), Findings: []finding.Finding{{Type: finding.TypeParam, Value: strconv.Itoa(i)}}}
+			if err := emit(b); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func TestPipeline_Run(t *testing.T) {
+	double := Stage{Name: "double", Process: func(ctx context.Context, b Batch, emit Emit) error {
+		if err := emit(b); err != nil {
+			return err
+		}
+		b.Source += "'"
+		return emit(b)
+	}}
+	drop := Stage{Name: "drop", Process: func(ctx context.Context, b Batch, emit Emit) error {
+		if b.Source == "1'" {
+			b.Findings = nil
+		}
+		return emit(b)
+	}}
+
+	stats := &Stats{}
+	var got []string
+	err := (&Pipeline{Buffer: 1, Stats: stats}).Run(context.Background(), numbers(3), []Stage{double, drop}, func(b Batch) error {
+		got = append(got, b.Source+":"+strconv.Itoa(len(b.Findings)))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if want := "0:1 0':1 1:1 1':0 2:1 2':1"; strings.Join(got, " ") != want {
+		t.Errorf("sink received %v, want %s", got, want)
+	}
+
+	var names []string
+	for _, st := range stats.Stages() {
+		names = append(names, st.Name+"/"+strconv.Itoa(st.Batches)+"/"+strconv.Itoa(st.In)+"/"+strconv.Itoa(st.Out))
+	}
+	if want := "read/3/0/3 double/3/3/6 drop/6/6/5 output/6/5/0"; strings.Join(names, " ") != want {
+		t.Errorf("stats = %v, want %s", names, want)
+	}
+
+	var buf bytes.Buffer
+	if err := stats.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "STAGE") || !strings.Contains(buf.String(), "\ndouble ") {
+		t.Errorf("Write() = %q", buf.String())
+	}
+}
+
+func TestPipeline_Backpressure(t *testing.T) {
+	var emitted atomic.Int32
+	source := func(ctx context.Context, emit Emit) error {
+		for i := 0; i < 100; i++ {
+			if err := emit(Batch{}); err != nil {
+				return err
+			}
+			emitted.Add(1)
+		}
+		return nil
+	}
+	release := make(chan struct{})
+	slow := Stage{Name: "slow", Process: func(ctx context.Context, b Batch, emit Emit) error {
+		<-release
+		return emit(b)
+	}}
+
+	done := make(chan error)
+	go func() {
+		done <- (&Pipeline{Buffer: 2}).Run(context.Background(), source, []Stage{slow}, func(Batch) error { return nil })
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	// The stage holds one batch and its input queue two more
+	if n := emitted.Load(); n > 3 {
+		t.Errorf("source emitted %d batches while the stage was blocked, want at most 3", n)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if n := emitted.Load(); n != 100 {
+		t.Errorf("source emitted %d batches, want 100", n)
+	}
+}
+
+func TestPipeline_Error(t *testing.T) {
+	errBoom := errors.New("boom")
+	fail := Stage{Name: "fail", Process: func(ctx context.Context, b Batch, emit Emit) error {
+		if b.Source == "5" {
+			return errBoom
+		}
+		return emit(b)
+	}}
+
+	var received int
+	err := (&Pipeline{Buffer: 1}).Run(context.Background(), numbers(1000), []Stage{fail}, func(Batch) error {
+		received++
+		return nil
+	})
+	if !errors.Is(err, errBoom) {
+		t.Errorf("Run() error = %v, want %v", err, errBoom)
+	}
+	if received > 5 {
+		t.Errorf("sink received %d batches, want at most 5", received)
+	}
+}
+
+func TestPipeline_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := (&Pipeline{}).Run(ctx, numbers(10), nil, func(Batch) error { return nil })
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestPipeline_StageTimeout(t *testing.T) {
+	block := Stage{Name: "extract", Timeout: 20 * time.Millisecond, Process: func(ctx context.Context, b Batch, emit Emit) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+	stats := &Stats{}
+	err := (&Pipeline{Stats: stats}).Run(context.Background(), numbers(1), []Stage{block}, func(Batch) error { return nil })
+	var timeout *TimeoutError
+	if !errors.As(err, &timeout) || timeout.Stage != "extract" || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Run() error = %v, want extract stage timeout", err)
+	}
+	if got := err.Error(); got != "extract stage timed out after 20ms" {
+		t.Errorf("error = %q", got)
+	}
+	if !stats.Stage("extract").TimedOut {
+		t.Error("stats do not record the timeout")
+	}
+}
+
+func TestPipeline_StageTimeoutTolerated(t *testing.T) {
+	var skipped int
+	optional := Stage{Name: "enrich", Timeout: 20 * time.Millisecond, Process: func(ctx context.Context, b Batch, emit Emit) error {
+		if b.Source == "0" {
+			<-ctx.Done()
+		}
+		if TimedOut(ctx) {
+			skipped++
+		}
+		return emit(b)
+	}}
+
+	var received int
+	err := (&Pipeline{}).Run(context.Background(), numbers(3), []Stage{optional}, func(Batch) error {
+		received++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if received != 3 || skipped != 3 {
+		t.Errorf("received %d batches with %d skipped, want 3 and 3", received, skipped)
+	}
+}
+
+func TestPipeline_ReadTimeout(t *testing.T) {
+	source := func(ctx context.Context, emit Emit) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	err := (&Pipeline{ReadTimeout: 10 * time.Millisecond}).Run(context.Background(), source, nil, func(Batch) error { return nil })
+	if err == nil || err.Error() != "read stage timed out after 10ms" {
+		t.Errorf("Run() error = %v, want read stage timeout", err)
+	}
+}
@@ -0,0 +1,97 @@
+package pipeline
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// StageStats holds the counts and timings of one stage
+type StageStats struct {
+	// Name identifies the stage
+	Name string
+	// Batches is the number of batches the stage received
+	Batches int
+	// In and Out are the number of findings received and emitted
+	In, Out int
+	// Busy is the time spent processing, excluding the time blocked on a full queue
+	Busy time.Duration
+	// Blocked is the time spent waiting for the next stage to accept a batch
+	Blocked time.Duration
+	// Wall is the time from the first batch received to the last one finished
+	Wall time.Duration
+	// TimedOut reports whether the stage exceeded its timeout
+	TimedOut bool
+}
+
+// Stats collects the statistics of the stages of a pipeline run.
+// Statistics are updated by the stage goroutines and must only be read after Run returns.
+type Stats struct {
+	mu     sync.Mutex
+	stages []*StageStats
+}
+
+// Stage returns the statistics of the named stage, adding it on first use
+func (s *Stats) Stage(name string) *StageStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, st := range s.stages {
+		if st.Name == name {
+			return st
+		}
+	}
+	st := &StageStats{Name: name}
+	s.stages = append(s.stages, st)
+	return st
+}
+
+// Measure runs fn and adds its duration to the busy and wall time of the named stage.
+// It is used for work done after the pipeline, such as writing the final output.
+func (s *Stats) Measure(name string, fn func() error) error {
+	st := s.Stage(name)
+	begin := time.Now()
+	err := fn()
+	d := time.Since(begin)
+	st.Busy += d
+	st.Wall += d
+	return err
+}
+
+// Stages returns the statistics of every stage in pipeline order
+func (s *Stats) Stages() []StageStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stages := make([]StageStats, len(s.stages))
+	for i, st := range s.stages {
+		stages[i] = *st
+	}
+	return stages
+}
+
+// Write prints the statistics as a table
+func (s *Stats) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STAGE\tBATCHES\tIN\tOUT\tBUSY\tBLOCKED\tWALL")
+	for _, st := range s.Stages() {
+		wall := round(st.Wall).String()
+		if st.TimedOut {
+			wall += " (timed out)"
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%s\t%s\t%s\n", st.Name, st.Batches, st.In, st.Out,
+			round(st.Busy), round(st.Blocked), wall)
+	}
+	return tw.Flush()
+}
+
+// round shortens durations to a readable precision
+func round(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond)
+	}
+	return d.Round(time.Microsecond)
+}
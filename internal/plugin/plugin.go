@@ -0,0 +1,212 @@
+// Package plugin runs extractor plugins: external programs that read the input lines as JSON
+// on standard input and write their findings as JSON to standard output, so extractors can be
+// written in any language without rebuilding urlsluice.
+//
+// A plugin is started once per input. It receives one object per non-empty line,
+// {"line": 12, "text": "..."}, and standard input is closed after the last one. It writes
+// one object per finding, {"type": "...", "value": "...", "line": 12, "confidence": "high",
+// "metadata": {...}}, and exits with status 0. Only value is required: type defaults to
+// plugin and confidence to medium.
+//
+// Plugins can also be WebAssembly modules, run in-process by an embedded runtime without
+// access to files, the network or the environment. A module exports:
+//
+//   - memory, its linear memory
+//   - alloc(size i32) i32, returning the address of size bytes the host may write to
+//   - process_line(ptr i32, len i32, line i32) i64, called for each non-empty line with the
+//     UTF-8 text at ptr, without its line break, and its 1-based line number. It returns the
+//     address of its findings in the high 32 bits and their length in the low 32 bits, or 0
+//     when there are none. Findings are written in the format of command plugins, one JSON
+//     object per line; line defaults to the line processed. The host copies them before
+//     making any other call.
+//   - optionally free(ptr i32, size i32), called with the input buffer once processed
+//
+// The module is instantiated afresh for each input. Modules compiled for WASI may import
+// wasi_snapshot_preview1, which provides no files, arguments or environment; anything
+// written to standard error is included in the error when a call fails.
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/PeteJStewart/urlsluice/internal/extractor"
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/tempdir"
+	"github.com/tetratelabs/wazero"
+)
+
+// Plugin is an external extractor program
+type Plugin struct {
+	// Name identifies the plugin in errors and in the "plugin" metadata of its findings
+	Name string
+	// Command is the program to run followed by its arguments
+	Command []string
+	// WASM is the path of a WebAssembly module run instead of Command
+	WASM string
+
+	// The module is compiled on first use
+	load    sync.Once
+	loadErr error
+	runtime wazero.Runtime
+	module  wazero.CompiledModule
+}
+
+// WASM returns a plugin running the WebAssembly module at path
+func WASM(name, path string) *Plugin {
+	return &Plugin{Name: name, WASM: path}
+}
+
+// request is the message written for each input line
+type request struct {
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+// Extract runs the plugin over the lines of reader, implementing extractor.Extractor
+func (p *Plugin) Extract(ctx context.Context, reader io.Reader) (extractor.Results, error) {
+	if err := ctx.Err(); err != nil {
+		return extractor.Results{}, p.error(err)
+	}
+	if p.WASM != "" {
+		return p.extractWASM(ctx, reader)
+	}
+	if len(p.Command) == 0 {
+		return extractor.Results{}, p.error(fmt.Errorf("no command"))
+	}
+	cmd := exec.CommandContext(ctx, p.Command[0], p.Command[1:]...)
+	// Files the plugin leaves in its temporary directory are removed with the run's
+	if dir, err := tempdir.Dir(); err == nil {
+		cmd.Env = append(os.Environ(), "TMPDIR="+dir, "TMP="+dir, "TEMP="+dir)
+	}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return extractor.Results{}, p.error(err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return extractor.Results{}, p.error(err)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return extractor.Results{}, p.error(err)
+	}
+
+	// Lines are written while findings are read, so neither side blocks on a full pipe
+	written := make(chan error, 1)
+	go func() {
+		written <- writeLines(stdin, reader)
+	}()
+	findings, readErr := p.readFindings(stdout)
+	if readErr != nil {
+		cmd.Process.Kill()
+		io.Copy(io.Discard, stdout)
+	}
+	inputErr := <-written
+	if err := cmd.Wait(); err != nil && readErr == nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = fmt.Errorf("%w: %s", err, msg)
+		}
+		return extractor.Results{}, p.error(err)
+	}
+	if readErr != nil {
+		return extractor.Results{}, p.error(readErr)
+	}
+	if inputErr != nil {
+		return extractor.Results{}, p.error(inputErr)
+	}
+	return extractor.Results{Findings: findings.Findings()}, nil
+}
+
+// writeLines writes a request for each non-empty line of reader to w and closes it. Only
+// errors reading the input are returned: a plugin may stop reading early, and its exit
+// status tells whether that was a failure.
+func writeLines(w io.WriteCloser, reader io.Reader) error {
+	defer w.Close()
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	br := bufio.NewReader(reader)
+	for lineNo := 1; ; lineNo++ {
+		text, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if line := strings.TrimRight(text, "\r\n"); strings.TrimSpace(line) != "" {
+			if enc.Encode(request{Line: lineNo, Text: line}) != nil {
+				return nil
+			}
+		}
+		if err == io.EOF {
+			bw.Flush()
+			return nil
+		}
+	}
+}
+
+// readFindings reads the findings written by the plugin, one JSON object per line
+func (p *Plugin) readFindings(r io.Reader) (*finding.Set, error) {
+	findings := &finding.Set{}
+	br := bufio.NewReader(r)
+	for n := 1; ; n++ {
+		data, err := br.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if data = bytes.TrimSpace(data); len(data) > 0 {
+			f, ferr := p.parseFinding(data)
+			if ferr != nil {
+				return nil, fmt.Errorf("output line %d: %w", n, ferr)
+			}
+			findings.Add(f)
+		}
+		if err == io.EOF {
+			return findings, nil
+		}
+	}
+}
+
+// parseFinding decodes and validates one finding written by the plugin
+func (p *Plugin) parseFinding(data []byte) (finding.Finding, error) {
+	var f finding.Finding
+	if err := json.Unmarshal(data, &f); err != nil {
+		return f, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if f.Value = strings.TrimSpace(f.Value); f.Value == "" {
+		return f, fmt.Errorf("value is required")
+	}
+	if f.Type == "" {
+		f.Type = finding.TypePlugin
+	} else {
+		t, err := finding.ParseType(string(f.Type))
+		if err != nil {
+			return f, err
+		}
+		f.Type = t
+	}
+	if f.Confidence == "" {
+		f.Confidence = finding.ConfidenceMedium
+	} else {
+		c, err := finding.ParseConfidence(string(f.Confidence))
+		if err != nil {
+			return f, err
+		}
+		f.Confidence = c
+	}
+	// The source is filled in by the caller, like for the built-in extractors
+	f.Source = ""
+	f.SetMeta("plugin", p.Name)
+	return f, nil
+}
+
+func (p *Plugin) error(err error) error {
+	return &extractor.ExtractorError{Op: "plugin " + p.Name, Err: err}
+}
@@ -0,0 +1,78 @@
+;; A WebAssembly extractor plugin reporting the space-separated words of a line that start
+;; with "eyJ", like JWTs, following the ABI described in the plugin package. Assembled into
+;; jwt.wasm with: wat2wasm jwt.wat
+;;
+;; The input line is written at 1024 and the findings right after it. Words are not escaped
+;; for JSON, which is fine for the base64url alphabet of JWTs.
+(module
+  (memory (export "memory") 1)
+  (data (i32.const 0) "{\"value\":\"")
+  (data (i32.const 16) "\",\"confidence\":\"high\"}\n")
+
+  ;; reserve grows the memory so that addresses below end are valid
+  (func $reserve (param $end i32)
+    (local $pages i32)
+    (local.set $pages
+      (i32.sub (i32.shr_u (i32.add (local.get $end) (i32.const 65535)) (i32.const 16)) (memory.size)))
+    (if (i32.gt_s (local.get $pages) (i32.const 0))
+      (then
+        (if (i32.eq (memory.grow (local.get $pages)) (i32.const -1))
+          (then unreachable)))))
+
+  (func (export "alloc") (param $size i32) (result i32)
+    (call $reserve (i32.add (i32.const 1024) (local.get $size)))
+    (i32.const 1024))
+
+  ;; copy copies n bytes from src to dst and returns the end of the copy
+  (func $copy (param $dst i32) (param $src i32) (param $n i32) (result i32)
+    (block $done
+      (loop $next
+        (br_if $done (i32.eqz (local.get $n)))
+        (i32.store8 (local.get $dst) (i32.load8_u (local.get $src)))
+        (local.set $dst (i32.add (local.get $dst) (i32.const 1)))
+        (local.set $src (i32.add (local.get $src) (i32.const 1)))
+        (local.set $n (i32.sub (local.get $n) (i32.const 1)))
+        (br $next)))
+    (local.get $dst))
+
+  (func (export "process_line") (param $ptr i32) (param $len i32) (param $line i32) (result i64)
+    (local $end i32) (local $i i32) (local $start i32) (local $out i32) (local $o i32)
+    (local.set $end (i32.add (local.get $ptr) (local.get $len)))
+    (local.set $out (local.get $end))
+    (local.set $o (local.get $out))
+    (local.set $i (local.get $ptr))
+    (block $done
+      (loop $word
+        (block $skipped
+          (loop $skip
+            (br_if $skipped (i32.ge_u (local.get $i) (local.get $end)))
+            (br_if $skipped (i32.ne (i32.load8_u (local.get $i)) (i32.const 32)))
+            (local.set $i (i32.add (local.get $i) (i32.const 1)))
+            (br $skip)))
+        (br_if $done (i32.ge_u (local.get $i) (local.get $end)))
+        (local.set $start (local.get $i))
+        (block $ended
+          (loop $scan
+            (br_if $ended (i32.ge_u (local.get $i) (local.get $end)))
+            (br_if $ended (i32.eq (i32.load8_u (local.get $i)) (i32.const 32)))
+            (local.set $i (i32.add (local.get $i) (i32.const 1)))
+            (br $scan)))
+        ;; "ey" is 0x7965 in little-endian order, "J" is 0x4a
+        (if (i32.and
+              (i32.ge_u (i32.sub (local.get $i) (local.get $start)) (i32.const 3))
+              (i32.and
+                (i32.eq (i32.load16_u (local.get $start)) (i32.const 0x7965))
+                (i32.eq (i32.load8_u offset=2 (local.get $start)) (i32.const 0x4a))))
+          (then
+            (call $reserve
+              (i32.add (local.get $o) (i32.add (i32.sub (local.get $i) (local.get $start)) (i32.const 33))))
+            (local.set $o (call $copy (local.get $o) (i32.const 0) (i32.const 10)))
+            (local.set $o (call $copy (local.get $o) (local.get $start) (i32.sub (local.get $i) (local.get $start))))
+            (local.set $o (call $copy (local.get $o) (i32.const 16) (i32.const 23)))))
+        (br $word)))
+    (if (result i64) (i32.eq (local.get $o) (local.get $out))
+      (then (i64.const 0))
+      (else
+        (i64.or
+          (i64.shl (i64.extend_i32_u (local.get $out)) (i64.const 32))
+          (i64.extend_i32_u (i32.sub (local.get $o) (local.get $out))))))))
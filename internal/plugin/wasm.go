@@ -0,0 +1,196 @@
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/extractor"
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+// wasmMemoryPages caps the memory of a WebAssembly plugin at 256MB, in pages of 64KB
+const wasmMemoryPages = 4096
+
+// wasmExports are the functions a WebAssembly plugin must export, with their signatures
+var wasmExports = []struct {
+	name    string
+	params  []api.ValueType
+	results []api.ValueType
+}{
+	{"alloc", []api.ValueType{api.ValueTypeI32}, []api.ValueType{api.ValueTypeI32}},
+	{"process_line", []api.ValueType{api.ValueTypeI32, api.ValueTypeI32, api.ValueTypeI32}, []api.ValueType{api.ValueTypeI64}},
+}
+
+// compile compiles the module on first use and checks that it implements the plugin ABI.
+// The runtime lives as long as the plugin, so later inputs only instantiate the module.
+func (p *Plugin) compile() error {
+	p.load.Do(func() {
+		code, err := os.ReadFile(p.WASM)
+		if err != nil {
+			p.loadErr = err
+			return
+		}
+		ctx := context.Background()
+		// Closing on cancellation stops a module stuck in a loop when the run is interrupted
+		config := wazero.NewRuntimeConfig().WithMemoryLimitPages(wasmMemoryPages).WithCloseOnContextDone(true)
+		runtime := wazero.NewRuntimeWithConfig(ctx, config)
+		if _, err := wasi_snapshot_preview1.Instantiate(ctx, runtime); err != nil {
+			runtime.Close(ctx)
+			p.loadErr = err
+			return
+		}
+		module, err := runtime.CompileModule(ctx, code)
+		if err == nil {
+			err = checkExports(module)
+		}
+		if err != nil {
+			runtime.Close(ctx)
+			p.loadErr = fmt.Errorf("%s: %w", p.WASM, err)
+			return
+		}
+		p.runtime, p.module = runtime, module
+	})
+	return p.loadErr
+}
+
+// checkExports reports the first function or memory of the plugin ABI that module lacks
+func checkExports(module wazero.CompiledModule) error {
+	if _, ok := module.ExportedMemories()["memory"]; !ok {
+		return fmt.Errorf("memory is not exported")
+	}
+	functions := module.ExportedFunctions()
+	for _, want := range wasmExports {
+		f, ok := functions[want.name]
+		if !ok {
+			return fmt.Errorf("function %s is not exported", want.name)
+		}
+		if !bytes.Equal(f.ParamTypes(), want.params) || !bytes.Equal(f.ResultTypes(), want.results) {
+			return fmt.Errorf("function %s has signature %v -> %v, want %v -> %v", want.name,
+				typeNames(f.ParamTypes()), typeNames(f.ResultTypes()), typeNames(want.params), typeNames(want.results))
+		}
+	}
+	return nil
+}
+
+func typeNames(types []api.ValueType) []string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = api.ValueTypeName(t)
+	}
+	return names
+}
+
+// extractWASM runs the WebAssembly module of the plugin over the lines of reader
+func (p *Plugin) extractWASM(ctx context.Context, reader io.Reader) (extractor.Results, error) {
+	if err := p.compile(); err != nil {
+		return extractor.Results{}, p.error(err)
+	}
+	var stderr bytes.Buffer
+	// A fresh instance per input keeps state left by one input out of the next
+	config := wazero.NewModuleConfig().WithName("").WithStartFunctions("_initialize").WithStderr(&stderr)
+	module, err := p.runtime.InstantiateModule(ctx, p.module, config)
+	if err != nil {
+		return extractor.Results{}, p.error(withStderr(err, &stderr))
+	}
+	defer module.Close(ctx)
+	instance := &wasmInstance{
+		memory:  module.ExportedMemory("memory"),
+		alloc:   module.ExportedFunction("alloc"),
+		process: module.ExportedFunction("process_line"),
+		free:    module.ExportedFunction("free"),
+	}
+
+	findings := &finding.Set{}
+	br := bufio.NewReader(reader)
+	for lineNo := 1; ; lineNo++ {
+		text, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return extractor.Results{}, p.error(err)
+		}
+		if line := strings.TrimRight(text, "\r\n"); strings.TrimSpace(line) != "" {
+			out, callErr := instance.processLine(ctx, line, lineNo)
+			if callErr != nil {
+				return extractor.Results{}, p.error(fmt.Errorf("line %d: %w", lineNo, withStderr(callErr, &stderr)))
+			}
+			if parseErr := p.addFindings(findings, out, lineNo); parseErr != nil {
+				return extractor.Results{}, p.error(fmt.Errorf("line %d: %w", lineNo, parseErr))
+			}
+		}
+		if err == io.EOF {
+			return extractor.Results{Findings: findings.Findings()}, nil
+		}
+	}
+}
+
+// addFindings parses the findings a module returned for line, one JSON object per line
+func (p *Plugin) addFindings(findings *finding.Set, out []byte, line int) error {
+	for n, data := range bytes.Split(out, []byte("\n")) {
+		if data = bytes.TrimSpace(data); len(data) == 0 {
+			continue
+		}
+		f, err := p.parseFinding(data)
+		if err != nil {
+			return fmt.Errorf("output line %d: %w", n+1, err)
+		}
+		if f.Line == 0 {
+			f.Line = line
+		}
+		findings.Add(f)
+	}
+	return nil
+}
+
+// wasmInstance is an instance of a plugin module and its ABI functions
+type wasmInstance struct {
+	memory  api.Memory
+	alloc   api.Function
+	process api.Function
+	// free is nil when the module does not export it
+	free api.Function
+}
+
+// processLine passes line to process_line and returns a copy of the findings it wrote
+func (in *wasmInstance) processLine(ctx context.Context, line string, lineNo int) ([]byte, error) {
+	res, err := in.alloc.Call(ctx, uint64(len(line)))
+	if err != nil {
+		return nil, fmt.Errorf("alloc: %w", err)
+	}
+	ptr := api.DecodeU32(res[0])
+	if !in.memory.WriteString(ptr, line) {
+		return nil, fmt.Errorf("alloc returned %d bytes at %d, outside the module memory", len(line), ptr)
+	}
+	if res, err = in.process.Call(ctx, uint64(ptr), uint64(len(line)), uint64(lineNo)); err != nil {
+		return nil, fmt.Errorf("process_line: %w", err)
+	}
+	var out []byte
+	if res[0] != 0 {
+		outPtr, outLen := uint32(res[0]>>32), uint32(res[0])
+		view, ok := in.memory.Read(outPtr, outLen)
+		if !ok {
+			return nil, fmt.Errorf("process_line returned %d bytes at %d, outside the module memory", outLen, outPtr)
+		}
+		out = bytes.Clone(view)
+	}
+	if in.free != nil {
+		if _, err := in.free.Call(ctx, uint64(ptr), uint64(len(line))); err != nil {
+			return nil, fmt.Errorf("free: %w", err)
+		}
+	}
+	return out, nil
+}
+
+// withStderr adds what the module wrote to standard error to err
+func withStderr(err error, stderr *bytes.Buffer) error {
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		return fmt.Errorf("%w: %s", err, msg)
+	}
+	return err
+}
@@ -0,0 +1,307 @@
+// Package postman reads Postman collections, environments and globals exports and lists
+// the requests, variables and scripts they contain.
+package postman
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Document is a parsed collection, environment or globals export
+type Document struct {
+	// Name is the collection or environment name
+	Name string
+	// Environment reports whether the document is an environment or globals export
+	Environment bool
+	// Requests are the requests of a collection, including those in folders, in collection order
+	Requests []Request
+	// Variables are the collection variables or environment values
+	Variables []Variable
+	// Auth holds the collection and folder level authorization settings, e.g. "bearer.token"
+	Auth []Pair
+	// Scripts are the pre-request and test scripts of the collection and its folders
+	Scripts []string
+}
+
+// Request is a request of a collection
+type Request struct {
+	// Name is the request name, prefixed with its folders, e.g. "Admin/Delete user"
+	Name    string
+	Method  string
+	URL     string
+	Headers []Pair
+	// Body is the raw, URL-encoded, form or GraphQL body as text
+	Body string
+	// Auth holds the request's authorization settings, e.g. "apikey.value"
+	Auth []Pair
+	// Scripts are the request's pre-request and test scripts
+	Scripts []string
+}
+
+// Variable is a collection variable or an environment or globals value
+type Variable struct {
+	Key   string
+	Value string
+	// Secret reports whether Postman marks the value as secret
+	Secret bool
+}
+
+// Pair is a key and value such as a header, form field or authorization setting
+type Pair struct {
+	Key   string
+	Value string
+}
+
+// variableRegex matches {{name}} references
+var variableRegex = regexp.MustCompile(`\{\{([^{}]+)\}\}`)
+
+// Resolve replaces {{name}} references in s with the values of vars; unknown references are kept
+func Resolve(s string, vars map[string]string) string {
+	for i := 0; i < 5 && strings.Contains(s, "{{"); i++ {
+		resolved := variableRegex.ReplaceAllStringFunc(s, func(ref string) string {
+			if v, ok := vars[strings.TrimSpace(ref[2:len(ref)-2])]; ok {
+				return v
+			}
+			return ref
+		})
+		if resolved == s {
+			break
+		}
+		s = resolved
+	}
+	return s
+}
+
+type export struct {
+	Info     *struct{ Name string } `json:"info"`
+	Name     string                 `json:"name"`
+	Item     []item                 `json:"item"`
+	Variable []variable             `json:"variable"`
+	Values   []variable             `json:"values"`
+	Auth     json.RawMessage        `json:"auth"`
+	Event    []event                `json:"event"`
+}
+
+type item struct {
+	Name    string          `json:"name"`
+	Item    []item          `json:"item"`
+	Request json.RawMessage `json:"request"`
+	Auth    json.RawMessage `json:"auth"`
+	Event   []event         `json:"event"`
+}
+
+type request struct {
+	Method string          `json:"method"`
+	URL    json.RawMessage `json:"url"`
+	Header json.RawMessage `json:"header"`
+	Body   *struct {
+		Mode       string          `json:"mode"`
+		Raw        string          `json:"raw"`
+		URLEncoded []variable      `json:"urlencoded"`
+		FormData   []variable      `json:"formdata"`
+		GraphQL    json.RawMessage `json:"graphql"`
+	} `json:"body"`
+	Auth json.RawMessage `json:"auth"`
+}
+
+type variable struct {
+	Key      string          `json:"key"`
+	Value    json.RawMessage `json:"value"`
+	Type     string          `json:"type"`
+	Disabled bool            `json:"disabled"`
+	Enabled  *bool           `json:"enabled"`
+}
+
+type event struct {
+	Listen string `json:"listen"`
+	Script struct {
+		Exec json.RawMessage `json:"exec"`
+	} `json:"script"`
+}
+
+// Parse reads a Postman collection (format v2.0 or v2.1), environment or globals export
+func Parse(data []byte) (*Document, error) {
+	var e export
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, fmt.Errorf("error parsing Postman export: %w", err)
+	}
+	doc := &Document{Name: e.Name}
+	switch {
+	case e.Info != nil:
+		doc.Name = e.Info.Name
+		doc.Variables = variables(e.Variable)
+		doc.Auth = auth(e.Auth)
+		doc.Scripts = scripts(e.Event)
+		doc.walk(e.Item, "")
+	case e.Values != nil:
+		doc.Environment = true
+		doc.Variables = variables(e.Values)
+	default:
+		return nil, errors.New("not a Postman collection or environment")
+	}
+	return doc, nil
+}
+
+func (d *Document) walk(items []item, folder string) {
+	for _, it := range items {
+		name := it.Name
+		if folder != "" {
+			name = folder + "/" + name
+		}
+		if it.Item != nil || len(it.Request) == 0 {
+			d.Auth = append(d.Auth, auth(it.Auth)...)
+			d.Scripts = append(d.Scripts, scripts(it.Event)...)
+			d.walk(it.Item, name)
+			continue
+		}
+		req := Request{Name: name, Scripts: scripts(it.Event)}
+		var r request
+		if err := json.Unmarshal(it.Request, &r); err != nil {
+			// A request may be given as just its URL
+			req.URL = text(it.Request)
+		} else {
+			req.Method = r.Method
+			req.URL = requestURL(r.URL)
+			req.Headers = headers(r.Header)
+			req.Auth = auth(r.Auth)
+			if b := r.Body; b != nil {
+				switch {
+				case b.Raw != "":
+					req.Body = b.Raw
+				case len(b.URLEncoded) > 0:
+					req.Body = joinPairs(pairs(b.URLEncoded), "=", "&")
+				case len(b.FormData) > 0:
+					req.Body = joinPairs(pairs(b.FormData), "=", "\n")
+				case len(b.GraphQL) > 0:
+					var gql struct{ Query, Variables string }
+					if json.Unmarshal(b.GraphQL, &gql) == nil {
+						req.Body = strings.TrimSpace(gql.Query + "\n" + gql.Variables)
+					}
+				}
+			}
+		}
+		d.Requests = append(d.Requests, req)
+	}
+}
+
+// requestURL returns the raw URL of a request, which is a string or an object
+func requestURL(raw json.RawMessage) string {
+	var u struct {
+		Raw string `json:"raw"`
+	}
+	if json.Unmarshal(raw, &u) == nil && u.Raw != "" {
+		return u.Raw
+	}
+	return text(raw)
+}
+
+// headers returns the enabled headers, which are a list or a "Name: value" string
+func headers(raw json.RawMessage) []Pair {
+	var list []variable
+	if json.Unmarshal(raw, &list) == nil {
+		return pairs(list)
+	}
+	var out []Pair
+	for _, line := range strings.Split(text(raw), "\n") {
+		if k, v, ok := strings.Cut(line, ":"); ok {
+			out = append(out, Pair{Key: strings.TrimSpace(k), Value: strings.TrimSpace(v)})
+		}
+	}
+	return out
+}
+
+// auth returns the settings of the selected authorization type, e.g. "bearer.token"
+func auth(raw json.RawMessage) []Pair {
+	var a map[string]json.RawMessage
+	if len(raw) == 0 || json.Unmarshal(raw, &a) != nil {
+		return nil
+	}
+	typ := text(a["type"])
+	if typ == "" || typ == "noauth" {
+		return nil
+	}
+	var list []variable
+	if json.Unmarshal(a[typ], &list) == nil {
+		var out []Pair
+		for _, p := range pairs(list) {
+			out = append(out, Pair{Key: typ + "." + p.Key, Value: p.Value})
+		}
+		return out
+	}
+	// Format v2.0 stores the settings as an object
+	var settings map[string]json.RawMessage
+	if json.Unmarshal(a[typ], &settings) != nil {
+		return nil
+	}
+	keys := make([]string, 0, len(settings))
+	for k := range settings {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var out []Pair
+	for _, k := range keys {
+		out = append(out, Pair{Key: typ + "." + k, Value: text(settings[k])})
+	}
+	return out
+}
+
+func variables(list []variable) []Variable {
+	var out []Variable
+	for _, v := range list {
+		if v.Disabled || (v.Enabled != nil && !*v.Enabled) {
+			continue
+		}
+		out = append(out, Variable{Key: v.Key, Value: text(v.Value), Secret: v.Type == "secret"})
+	}
+	return out
+}
+
+func pairs(list []variable) []Pair {
+	var out []Pair
+	for _, v := range list {
+		if !v.Disabled && v.Key != "" {
+			out = append(out, Pair{Key: v.Key, Value: text(v.Value)})
+		}
+	}
+	return out
+}
+
+func joinPairs(ps []Pair, sep, between string) string {
+	parts := make([]string, len(ps))
+	for i, p := range ps {
+		parts[i] = p.Key + sep + p.Value
+	}
+	return strings.Join(parts, between)
+}
+
+// scripts returns the source of each event script, which is a list of lines or a string
+func scripts(events []event) []string {
+	var out []string
+	for _, e := range events {
+		var lines []string
+		if json.Unmarshal(e.Script.Exec, &lines) == nil {
+			if s := strings.Join(lines, "\n"); strings.TrimSpace(s) != "" {
+				out = append(out, s)
+			}
+		} else if s := text(e.Script.Exec); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// text returns a JSON string, or the JSON text of any other value
+func text(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	return string(raw)
+}
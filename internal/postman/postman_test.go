@@ -0,0 +1,128 @@
+package postman
+
+import (
+	"reflect"
+	"testing"
+)
+
+const testCollection = `{
+  "info": {"name": "Target API", "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"},
+  "auth": {"type": "bearer", "bearer": [{"key": "token", "value": "{{accessToken}}", "type": "string"}]},
+  "variable": [{"key": "baseUrl", "value": "https://api.target.com"}],
+  "item": [
+    {
+      "name": "Admin",
+      "event": [{"listen": "prerequest", "script": {"exec": ["pm.environment.set('ts', Date.now());"]}}],
+      "item": [
+        {
+          "name": "Delete user",
+          "request": {
+            "method": "DELETE",
+            "url": {"raw": "{{baseUrl}}/admin/users/7", "host": ["{{baseUrl}}"], "path": ["admin", "users", "7"]},
+            "header": [
+              {"key": "X-Api-Key", "value": "{{apiKey}}"},
+              {"key": "X-Debug", "value": "1", "disabled": true}
+            ],
+            "body": {"mode": "urlencoded", "urlencoded": [{"key": "reason", "value": "spam"}]}
+          }
+        }
+      ]
+    },
+    {"name": "Health", "request": "https://status.target.com/health"},
+    {
+      "name": "Login",
+      "event": [{"listen": "test", "script": {"exec": "pm.test('ok', () => {});"}}],
+      "request": {
+        "method": "POST",
+        "url": "{{baseUrl}}/login",
+        "header": "Content-Type: application/json\nX-Client: web",
+        "body": {"mode": "raw", "raw": "{\"user\": \"admin@target.com\"}"},
+        "auth": {"type": "apikey", "apikey": {"key": "X-Key", "value": "abc"}}
+      }
+    }
+  ]
+}`
+
+const testEnvironment = `{
+  "name": "Production",
+  "_postman_variable_scope": "environment",
+  "values": [
+    {"key": "apiKey", "value": "sk_live_123", "type": "secret", "enabled": true},
+    {"key": "accessToken", "value": "eyJhbGciOi", "type": "default", "enabled": true},
+    {"key": "oldKey", "value": "unused", "enabled": false}
+  ]
+}`
+
+func TestParse_Collection(t *testing.T) {
+	doc, err := Parse([]byte(testCollection))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := &Document{
+		Name: "Target API",
+		Requests: []Request{
+			{
+				Name:    "Admin/Delete user",
+				Method:  "DELETE",
+				URL:     "{{baseUrl}}/admin/users/7",
+				Headers: []Pair{{Key: "X-Api-Key", Value: "{{apiKey}}"}},
+				Body:    "reason=spam",
+			},
+			{Name: "Health", URL: "https://status.target.com/health"},
+			{
+				Name:    "Login",
+				Method:  "POST",
+				URL:     "{{baseUrl}}/login",
+				Headers: []Pair{{Key: "Content-Type", Value: "application/json"}, {Key: "X-Client", Value: "web"}},
+				Body:    `{"user": "admin@target.com"}`,
+				Auth:    []Pair{{Key: "apikey.key", Value: "X-Key"}, {Key: "apikey.value", Value: "abc"}},
+				Scripts: []string{"pm.test('ok', () => {});"},
+			},
+		},
+		Variables: []Variable{{Key: "baseUrl", Value: "https://api.target.com"}},
+		Auth:      []Pair{{Key: "bearer.token", Value: "{{accessToken}}"}},
+		Scripts:   []string{"pm.environment.set('ts', Date.now());"},
+	}
+	if !reflect.DeepEqual(doc, want) {
+		t.Errorf("Parse() =\n%+v\nwant\n%+v", doc, want)
+	}
+}
+
+func TestParse_Environment(t *testing.T) {
+	doc, err := Parse([]byte(testEnvironment))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	want := []Variable{
+		{Key: "apiKey", Value: "sk_live_123", Secret: true},
+		{Key: "accessToken", Value: "eyJhbGciOi"},
+	}
+	if doc.Name != "Production" || !doc.Environment || !reflect.DeepEqual(doc.Variables, want) {
+		t.Errorf("Parse() = %+v, want variables %+v", doc, want)
+	}
+
+	for _, input := range []string{"", "[]", `{"foo": 1}`, "not json"} {
+		if _, err := Parse([]byte(input)); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", input)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	vars := map[string]string{"baseUrl": "https://{{host}}/v1", "host": "api.target.com", "loop": "{{loop}}"}
+	tests := []struct {
+		in, want string
+	}{
+		{"{{baseUrl}}/users", "https://api.target.com/v1/users"},
+		{"{{ host }}", "api.target.com"},
+		{"{{unknown}}/x", "{{unknown}}/x"},
+		{"{{loop}}", "{{loop}}"},
+		{"plain", "plain"},
+	}
+	for _, tt := range tests {
+		if got := Resolve(tt.in, vars); got != tt.want {
+			t.Errorf("Resolve(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+// Package probe checks whether discovered URLs are alive.
+package probe
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/PeteJStewart/urlsluice/internal/cache"
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/httpclient"
+)
+
+const (
+	// defaultConcurrency is the number of URLs probed in parallel when none is configured
+	defaultConcurrency = 8
+	// maxBodySize caps how much of a GET response is read to measure its length (10MB)
+	maxBodySize = 10 * 1024 * 1024
+)
+
+// Result is the outcome of probing a URL
+type Result struct {
+	Status        int
+	ContentLength int64
+}
+
+// Alive reports whether the URL responded with a success, a redirect, or an
+// authorization challenge (which means the resource exists but is protected)
+func (r Result) Alive() bool {
+	return (r.Status >= 200 && r.Status < 400) ||
+		r.Status == http.StatusUnauthorized || r.Status == http.StatusForbidden
+}
+
+// Prober checks URLs with HEAD requests, falling back to GET when HEAD is not supported
+type Prober struct {
+	// Client sends the probes
+	Client httpclient.Doer
+	// Concurrency bounds the number of requests in flight
+	Concurrency int
+	// Cache keeps the results of earlier runs; URLs found in it are not requested again
+	Cache *cache.Cache
+}
+
+// Probe checks every URL finding and records "status", "content_length" and "alive"
+// in its metadata. URLs that cannot be reached are marked with alive=false only.
+func (p *Prober) Probe(ctx context.Context, findings []finding.Finding) {
+	concurrency := p.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for i := range findings {
+		if findings[i].Type != finding.TypeURL {
+			continue
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(f *finding.Finding) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			var result Result
+			if !p.Cache.Get("probe", f.Value, &result) {
+				var err error
+				if result, err = p.URL(ctx, f.Value); err != nil {
+					f.SetMeta("alive", "false")
+					return
+				}
+				p.Cache.Put("probe", f.Value, result)
+			}
+			f.SetMeta("status", strconv.Itoa(result.Status))
+			if result.ContentLength >= 0 {
+				f.SetMeta("content_length", strconv.FormatInt(result.ContentLength, 10))
+			}
+			f.SetMeta("alive", strconv.FormatBool(result.Alive()))
+		}(&findings[i])
+	}
+	wg.Wait()
+}
+
+// URL probes a single URL. ContentLength is -1 when the length is unknown.
+func (p *Prober) URL(ctx context.Context, rawURL string) (Result, error) {
+	resp, err := p.do(ctx, http.MethodHead, rawURL)
+	if err == nil {
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusMethodNotAllowed && resp.StatusCode != http.StatusNotImplemented {
+			return Result{Status: resp.StatusCode, ContentLength: resp.ContentLength}, nil
+		}
+	} else if ctx.Err() != nil {
+		return Result{}, err
+	}
+
+	resp, err = p.do(ctx, http.MethodGet, rawURL)
+	if err != nil {
+		return Result{}, err
+	}
+	defer resp.Body.Close()
+
+	length := resp.ContentLength
+	if length < 0 {
+		n, err := io.Copy(io.Discard, io.LimitReader(resp.Body, maxBodySize))
+		if err == nil && n < maxBodySize {
+			length = n
+		}
+	}
+	return Result{Status: resp.StatusCode, ContentLength: length}, nil
+}
+
+func (p *Prober) do(ctx context.Context, method, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	return httpclient.Do(p.Client, req)
+}
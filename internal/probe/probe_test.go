@@ -0,0 +1,64 @@
+package probe
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+func TestProber_Probe(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			fmt.Fprint(w, "hello")
+		case "/nohead":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			w.Header().Set("Content-Length", "7")
+			fmt.Fprint(w, "no head")
+		case "/admin":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	findings := []finding.Finding{
+		{Type: finding.TypeURL, Value: srv.URL + "/ok"},
+		{Type: finding.TypeURL, Value: srv.URL + "/nohead"},
+		{Type: finding.TypeURL, Value: srv.URL + "/admin"},
+		{Type: finding.TypeURL, Value: srv.URL + "/missing"},
+		{Type: finding.TypeURL, Value: "http://127.0.0.1:1/down"},
+		{Type: finding.TypeDomain, Value: "target.com"},
+	}
+
+	(&Prober{Concurrency: 2}).Probe(context.Background(), findings)
+
+	tests := []struct {
+		index                 int
+		status, length, alive string
+	}{
+		{0, "200", "5", "true"},
+		{1, "200", "7", "true"},
+		{2, "403", "", "true"},
+		{3, "404", "19", "false"},
+		{4, "", "", "false"},
+	}
+	for _, tt := range tests {
+		meta := findings[tt.index].Metadata
+		if meta["status"] != tt.status || meta["content_length"] != tt.length || meta["alive"] != tt.alive {
+			t.Errorf("%s metadata = %v, want status=%s content_length=%s alive=%s",
+				findings[tt.index].Value, meta, tt.status, tt.length, tt.alive)
+		}
+	}
+	if findings[5].Metadata != nil {
+		t.Errorf("domain finding should not be probed, got %v", findings[5].Metadata)
+	}
+}
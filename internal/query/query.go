@@ -0,0 +1,414 @@
+// Package query implements a small expression language for selecting findings from saved
+// results, e.g. `type == domain && value endswith ".dev"`.
+//
+// An expression compares a field with a value and can be combined with && (and), || (or),
+// ! (not) and parentheses. Fields are type, value, source, line, count, confidence, tags
+// and meta.KEY for a metadata entry. The operators are ==, !=, contains, startswith,
+// endswith and matches (a regular expression) for text, and <, <=, > and >= for line,
+// count and confidence, which compares levels (low < medium < high). A comparison with
+// tags holds when any tag matches. Values are double-quoted strings or bare words.
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/regexcache"
+)
+
+// Query is a compiled expression
+type Query struct {
+	root node
+}
+
+// Parse compiles expr
+func Parse(expr string) (*Query, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %s at offset %d", t, t.pos)
+	}
+	return &Query{root: root}, nil
+}
+
+// Match reports whether f satisfies the expression
+func (q *Query) Match(f finding.Finding) bool {
+	return q.root.match(f)
+}
+
+// Filter returns the findings that satisfy the expression, in their original order
+func (q *Query) Filter(findings []finding.Finding) []finding.Finding {
+	var matched []finding.Finding
+	for _, f := range findings {
+		if q.Match(f) {
+			matched = append(matched, f)
+		}
+	}
+	return matched
+}
+
+type node interface {
+	match(f finding.Finding) bool
+}
+
+type andNode struct{ left, right node }
+
+func (n andNode) match(f finding.Finding) bool { return n.left.match(f) && n.right.match(f) }
+
+type orNode struct{ left, right node }
+
+func (n orNode) match(f finding.Finding) bool { return n.left.match(f) || n.right.match(f) }
+
+type notNode struct{ operand node }
+
+func (n notNode) match(f finding.Finding) bool { return !n.operand.match(f) }
+
+// comparison compares one field of a finding with a value
+type comparison struct {
+	field string
+	op    string
+	value string
+	re    *regexp.Regexp
+	num   int
+}
+
+// textOps and orderOps list the operators accepted for text and ordered fields
+var (
+	textOps  = map[string]bool{"==": true, "!=": true, "contains": true, "startswith": true, "endswith": true, "matches": true}
+	orderOps = map[string]bool{"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true}
+)
+
+// newComparison validates a comparison and prepares its value
+func newComparison(field, op, value string) (*comparison, error) {
+	c := &comparison{field: strings.ToLower(field), op: strings.ToLower(op), value: value}
+	switch {
+	case c.field == "line" || c.field == "count":
+		if !orderOps[c.op] {
+			return nil, fmt.Errorf("operator %s cannot be used with %s", op, field)
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be compared with a number, not %q", field, value)
+		}
+		c.num = n
+	case c.field == "confidence":
+		if !orderOps[c.op] && !textOps[c.op] {
+			return nil, fmt.Errorf("operator %s cannot be used with %s", op, field)
+		}
+		if orderOps[c.op] {
+			level, err := finding.ParseConfidence(value)
+			if err != nil {
+				return nil, err
+			}
+			c.value = string(level)
+		}
+	case c.field == "type" || c.field == "value" || c.field == "source" || c.field == "tags" || strings.HasPrefix(c.field, "meta."):
+		if !textOps[c.op] {
+			return nil, fmt.Errorf("operator %s cannot be used with %s", op, field)
+		}
+		if c.field == "meta." {
+			return nil, fmt.Errorf("meta. needs a metadata key, e.g. meta.status")
+		}
+		if strings.HasPrefix(c.field, "meta.") {
+			// Metadata keys are case-sensitive
+			c.field = "meta." + field[len("meta."):]
+		}
+	default:
+		return nil, fmt.Errorf("unknown field %q", field)
+	}
+	if c.op == "matches" {
+		re, err := regexcache.Compile(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q: %w", value, err)
+		}
+		c.re = re
+	}
+	return c, nil
+}
+
+func (c *comparison) match(f finding.Finding) bool {
+	switch c.field {
+	case "line":
+		return compareInts(f.Line, c.op, c.num)
+	case "count":
+		return compareInts(f.Count, c.op, c.num)
+	case "confidence":
+		if !textOps[c.op] {
+			return compareLevels(f.Confidence, c.op, finding.Confidence(c.value))
+		}
+		return c.text(string(f.Confidence))
+	case "type":
+		return c.text(string(f.Type))
+	case "value":
+		return c.text(f.Value)
+	case "source":
+		return c.text(f.Source)
+	case "tags":
+		if c.op == "!=" {
+			for _, tag := range f.Tags {
+				if strings.EqualFold(tag, c.value) {
+					return false
+				}
+			}
+			return true
+		}
+		for _, tag := range f.Tags {
+			if c.text(tag) {
+				return true
+			}
+		}
+		return false
+	}
+	return c.text(f.Metadata[strings.TrimPrefix(c.field, "meta.")])
+}
+
+// text applies a text operator to s
+func (c *comparison) text(s string) bool {
+	switch c.op {
+	case "==":
+		return strings.EqualFold(s, c.value)
+	case "!=":
+		return !strings.EqualFold(s, c.value)
+	case "contains":
+		return strings.Contains(strings.ToLower(s), strings.ToLower(c.value))
+	case "startswith":
+		return strings.HasPrefix(strings.ToLower(s), strings.ToLower(c.value))
+	case "endswith":
+		return strings.HasSuffix(strings.ToLower(s), strings.ToLower(c.value))
+	case "matches":
+		return c.re.MatchString(s)
+	}
+	return false
+}
+
+func compareInts(a int, op string, b int) bool {
+	switch op {
+	case "==":
+		return a == b
+	case "!=":
+		return a != b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	}
+	return false
+}
+
+func compareLevels(a finding.Confidence, op string, b finding.Confidence) bool {
+	switch op {
+	case "<":
+		return !a.AtLeast(b)
+	case "<=":
+		return b.AtLeast(a)
+	case ">":
+		return !b.AtLeast(a)
+	case ">=":
+		return a.AtLeast(b)
+	}
+	return false
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenOp
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenLParen
+	tokenRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of expression"
+	}
+	return strconv.Quote(t.text)
+}
+
+// tokenize splits expr into words, quoted strings, operators and parentheses
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{tokenLParen, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{tokenRParen, ")", i})
+			i++
+		case strings.HasPrefix(expr[i:], "&&"):
+			tokens = append(tokens, token{tokenAnd, "&&", i})
+			i += 2
+		case strings.HasPrefix(expr[i:], "||"):
+			tokens = append(tokens, token{tokenOr, "||", i})
+			i += 2
+		case strings.HasPrefix(expr[i:], "==") || strings.HasPrefix(expr[i:], "!=") ||
+			strings.HasPrefix(expr[i:], "<=") || strings.HasPrefix(expr[i:], ">="):
+			tokens = append(tokens, token{tokenOp, expr[i : i+2], i})
+			i += 2
+		case c == '<' || c == '>':
+			tokens = append(tokens, token{tokenOp, expr[i : i+1], i})
+			i++
+		case c == '!':
+			tokens = append(tokens, token{tokenNot, "!", i})
+			i++
+		case c == '"':
+			s, n, err := readString(expr[i:])
+			if err != nil {
+				return nil, fmt.Errorf("%w at offset %d", err, i)
+			}
+			tokens = append(tokens, token{tokenString, s, i})
+			i += n
+		default:
+			start := i
+			for i < len(expr) && isWordByte(expr[i]) {
+				i++
+			}
+			if i == start {
+				return nil, fmt.Errorf("unexpected character %q at offset %d", c, i)
+			}
+			tokens = append(tokens, token{tokenWord, expr[start:i], start})
+		}
+	}
+	return append(tokens, token{tokenEOF, "", len(expr)}), nil
+}
+
+// readString reads a double-quoted string with backslash escapes from the start of s,
+// returning its value and the number of bytes consumed
+func readString(s string) (string, int, error) {
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				b.WriteByte(s[i])
+			}
+		case '"':
+			return b.String(), i + 1, nil
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+// isWordByte reports whether c can appear in a field name, operator word or bare value
+func isWordByte(c byte) bool {
+	return c >= 0x80 || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c)) || strings.IndexByte("._-:/@*+$%", c) >= 0
+}
+
+// parser is a recursive descent parser over the tokens of an expression
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) or() (node, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenOr {
+		p.next()
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) and() (node, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenAnd {
+		p.next()
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) unary() (node, error) {
+	switch t := p.next(); t.kind {
+	case tokenNot:
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand}, nil
+	case tokenLParen:
+		n, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, fmt.Errorf("expected ) at offset %d, found %s", closing.pos, closing)
+		}
+		return n, nil
+	case tokenWord:
+		op := p.next()
+		if op.kind != tokenOp && (op.kind != tokenWord || !textOps[strings.ToLower(op.text)]) {
+			return nil, fmt.Errorf("expected an operator after %s at offset %d, found %s", t.text, op.pos, op)
+		}
+		value := p.next()
+		if value.kind != tokenWord && value.kind != tokenString {
+			return nil, fmt.Errorf("expected a value after %s at offset %d, found %s", op.text, value.pos, value)
+		}
+		c, err := newComparison(t.text, op.text, value.text)
+		if err != nil {
+			return nil, fmt.Errorf("%w at offset %d", err, t.pos)
+		}
+		return c, nil
+	default:
+		return nil, fmt.Errorf("expected a comparison at offset %d, found %s", t.pos, t)
+	}
+}
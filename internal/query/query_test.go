@@ -0,0 +1,76 @@
+package query
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+var findings = []finding.Finding{
+	{Type: finding.TypeDomain, Value: "api.target.dev", Source: "a.txt", Line: 3, Confidence: finding.ConfidenceHigh, Tags: []string{"internal"}},
+	{Type: finding.TypeDomain, Value: "www.target.com", Source: "b.txt", Line: 10, Confidence: finding.ConfidenceMedium, Metadata: map[string]string{"status": "200"}},
+	{Type: finding.TypeEmail, Value: "dev@target.dev", Source: "a.txt", Line: 7, Confidence: finding.ConfidenceLow},
+	{Type: finding.TypeUsername, Value: "jdoe", Count: 5},
+}
+
+func TestQuery(t *testing.T) {
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{`type==domain && value endswith ".dev"`, []string{"api.target.dev"}},
+		{`type == domain || type == email`, []string{"api.target.dev", "www.target.com", "dev@target.dev"}},
+		{`value contains target && !(type == email)`, []string{"api.target.dev", "www.target.com"}},
+		{`value matches "^[a-z]+\\.target\\.(com|dev)$"`, []string{"api.target.dev", "www.target.com"}},
+		{`confidence >= medium`, []string{"api.target.dev", "www.target.com"}},
+		{`confidence < medium && source == a.txt`, []string{"dev@target.dev"}},
+		{`line > 5 && line <= 10`, []string{"www.target.com", "dev@target.dev"}},
+		{`count >= 2`, []string{"jdoe"}},
+		{`tags == INTERNAL`, []string{"api.target.dev"}},
+		{`tags != internal && type == domain`, []string{"www.target.com"}},
+		{`meta.status == 200`, []string{"www.target.com"}},
+		{`value startswith "www." || meta.status != ""`, []string{"www.target.com"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			q, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			var got []string
+			for _, f := range q.Filter(findings) {
+				got = append(got, f.Value)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Filter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := map[string]string{
+		`type ==`:                   "expected a value after == at offset 7",
+		`type = domain`:             `unexpected character '=' at offset 5`,
+		`colour == red`:             `unknown field "colour" at offset 0`,
+		`line contains 3`:           "operator contains cannot be used with line",
+		`line > three`:              `line must be compared with a number, not "three"`,
+		`confidence > certain`:      "invalid confidence level",
+		`value matches "("`:         "invalid regular expression",
+		`(type == domain`:           "expected ) at offset 15",
+		`type == domain domain`:     `unexpected "domain" at offset 15`,
+		`value == "unterminated`:    "unterminated string at offset 9",
+		`value startswith && x`:     "expected a value after startswith",
+		`meta. == x`:                "meta. needs a metadata key",
+		`&& type == domain`:         `expected a comparison at offset 0, found "&&"`,
+		`type < domain`:             "operator < cannot be used with type",
+		``:                          "expected a comparison at offset 0, found end of expression",
+		`type == domain && (value)`: "expected an operator after value",
+	}
+	for expr, want := range tests {
+		if _, err := Parse(expr); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Parse(%q) error = %v, want %q", expr, err, want)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+// Package redact masks secrets, email addresses and payment card numbers in findings so
+// reports can be shared outside the team. Every masked value is replaced by a tag holding a
+// prefix of its SHA-256, which stays the same across runs, so a redacted report can still be
+// matched against the unredacted output or audit log it came from.
+package redact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/patterns"
+	"github.com/PeteJStewart/urlsluice/internal/xref"
+)
+
+// hashLen is the number of hex digits of the SHA-256 kept in a tag
+const hashLen = 12
+
+// cardRegex matches 13 to 19 digits, optionally grouped by spaces or dashes, starting like
+// an American Express, Visa, Mastercard or Discover card. Millisecond timestamps also have
+// 13 digits but start with 1.
+var cardRegex = regexp.MustCompile(`\b[2-6](?:[ -]?\d){12,18}\b`)
+
+// Hash returns the hash identifying value in redacted output: the first 12 hex digits of
+// its SHA-256
+func Hash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])[:hashLen]
+}
+
+// tag returns the text replacing the sensitive value s
+func tag(s string) string {
+	return "[redacted:" + Hash(s) + "]"
+}
+
+// Findings returns copies of findings with sensitive values masked. Tokens and cloud keys
+// are masked whole, and config secrets keep their key. Email addresses keep their domain,
+// and card numbers are masked wherever they appear, including in metadata and sources.
+// Usernames taken from the local part of an email address are masked whole. Each tag holds
+// the hash of the whole finding value, email address or card number it hides. The given
+// findings are left untouched.
+func Findings(findings []finding.Finding) []finding.Finding {
+	redacted := make([]finding.Finding, len(findings))
+	for i, f := range findings {
+		f.Value = Value(f.Type, f.Value)
+		if f.Type == finding.TypeUsername && f.Metadata["origin"] == "email" {
+			// The local part would reveal the addresses masked in the email findings
+			f.Value = tag(f.Value)
+		}
+		f.Source = Text(f.Source)
+		if f.Metadata != nil {
+			meta := make(map[string]string, len(f.Metadata))
+			for k, v := range f.Metadata {
+				meta[k] = Text(v)
+			}
+			f.Metadata = meta
+		}
+		redacted[i] = f
+	}
+	return redacted
+}
+
+// Report returns a copy of the cross-reference report r with the email addresses and card
+// numbers in the referring findings masked. The cross-referenced domains are kept.
+func Report(r xref.Report) xref.Report {
+	return xref.Report{ParamDomains: refs(r.ParamDomains), ExternalEmails: refs(r.ExternalEmails)}
+}
+
+func refs(in []xref.Ref) []xref.Ref {
+	if in == nil {
+		return nil
+	}
+	out := make([]xref.Ref, len(in))
+	for i, ref := range in {
+		masked := make([]string, len(ref.In))
+		for j, v := range ref.In {
+			masked[j] = Text(v)
+		}
+		sort.Strings(masked)
+		out[i] = xref.Ref{Value: ref.Value, In: masked}
+	}
+	return out
+}
+
+// Value returns the value of a finding of type t with its sensitive parts masked
+func Value(t finding.Type, value string) string {
+	switch t {
+	case finding.TypeToken, finding.TypeCloudConfig:
+		return tag(value)
+	case finding.TypeConfigSecret:
+		// Config secrets are KEY=value; the key tells the reader what leaked
+		if i := strings.IndexByte(value, '='); i > 0 {
+			return value[:i+1] + tag(value)
+		}
+		return tag(value)
+	}
+	return Text(value)
+}
+
+// Text masks the local part of email addresses and the card numbers in s
+func Text(s string) string {
+	s = patterns.EmailRegex.ReplaceAllStringFunc(s, func(email string) string {
+		at := strings.LastIndexByte(email, '@')
+		return tag(email) + email[at:]
+	})
+	return cardRegex.ReplaceAllStringFunc(s, func(match string) string {
+		digits := strings.NewReplacer(" ", "", "-", "").Replace(match)
+		if !luhn(digits) {
+			return match
+		}
+		return tag(digits)
+	})
+}
+
+// luhn reports whether digits pass the Luhn checksum of card numbers
+func luhn(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
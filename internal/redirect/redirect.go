@@ -1,21 +1,108 @@
 package redirect
 
 import (
+	"fmt"
 	"net/url"
 	"os"
+	"regexp"
+	"sort"
 	"strings"
 
-	"gopkg.in/yaml.v3"
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/regexcache"
+	"github.com/PeteJStewart/urlsluice/internal/yamlcheck"
 )
 
 // RedirectDetector holds configuration for redirect detection
 type RedirectDetector struct {
 	redirectParams []string
+	heuristics     []Heuristic
 }
 
 // Config represents the YAML configuration structure
 type Config struct {
 	RedirectParams []string `yaml:"redirect_params"`
+	// Heuristics replace the default rules deciding which parameter values look like
+	// redirect targets
+	Heuristics []Heuristic `yaml:"heuristics"`
+}
+
+// Heuristic is a rule matching parameter values that look like redirect targets. A value
+// matches when every condition that is set holds.
+type Heuristic struct {
+	// Name identifies the rule in results
+	Name string `yaml:"name"`
+	// Prefixes optionally require the value to start with one of them, e.g. "https://"
+	Prefixes []string `yaml:"prefixes"`
+	// IgnoreCase matches Prefixes regardless of case, so "https://" also matches "HTTPS://"
+	IgnoreCase bool `yaml:"ignore_case"`
+	// MinLength is the minimum length of the value
+	MinLength int `yaml:"min_length"`
+	// Pattern is an optional regular expression the value must match
+	Pattern string `yaml:"pattern"`
+	// KnownParamsOnly limits the rule to the redirect parameters
+	KnownParamsOnly bool `yaml:"known_params_only"`
+	// Confidence is reported for values matched by the rule (default medium)
+	Confidence finding.Confidence `yaml:"confidence"`
+
+	pattern *regexp.Regexp
+}
+
+// urlPrefixes start absolute and scheme-relative URLs
+var urlPrefixes = []string{"http://", "https://", "//"}
+
+// defaultHeuristics report URL values of redirect parameters with high confidence and URL
+// values of any other parameter that are long enough to name a host with medium confidence
+var defaultHeuristics = []Heuristic{
+	{Name: "known-param-url", Prefixes: urlPrefixes, KnownParamsOnly: true, Confidence: finding.ConfidenceHigh},
+	{Name: "url-value", Prefixes: urlPrefixes, MinLength: 4, Confidence: finding.ConfidenceMedium},
+}
+
+// compile validates the rule and fills in defaults
+func (h *Heuristic) compile() error {
+	if h.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(h.Prefixes) == 0 && h.MinLength == 0 && h.Pattern == "" {
+		return fmt.Errorf("%s: prefixes, min_length or pattern is required", h.Name)
+	}
+	if h.Pattern != "" {
+		re, err := regexcache.Compile(h.Pattern)
+		if err != nil {
+			return fmt.Errorf("%s: invalid pattern: %w", h.Name, err)
+		}
+		h.pattern = re
+	}
+	if h.Confidence == "" {
+		h.Confidence = finding.ConfidenceMedium
+	}
+	c, err := finding.ParseConfidence(string(h.Confidence))
+	if err != nil {
+		return fmt.Errorf("%s: %w", h.Name, err)
+	}
+	h.Confidence = c
+	return nil
+}
+
+// matches reports whether the rule matches value of a parameter; known is set for the
+// redirect parameters
+func (h *Heuristic) matches(value string, known bool) bool {
+	if h.KnownParamsOnly && !known || len(value) < h.MinLength {
+		return false
+	}
+	if len(h.Prefixes) > 0 {
+		prefixed := false
+		for _, p := range h.Prefixes {
+			if strings.HasPrefix(value, p) || h.IgnoreCase && len(value) >= len(p) && strings.EqualFold(value[:len(p)], p) {
+				prefixed = true
+				break
+			}
+		}
+		if !prefixed {
+			return false
+		}
+	}
+	return h.pattern == nil || h.pattern.MatchString(value)
 }
 
 // Default redirect parameters if no config is provided
@@ -32,6 +119,7 @@ var defaultRedirectParams = []string{
 // NewRedirectDetector creates a new detector with optional configuration
 func NewRedirectDetector(configPath string) (*RedirectDetector, error) {
 	params := defaultRedirectParams
+	heuristics := defaultHeuristics
 
 	if configPath != "" {
 		config, err := loadConfig(configPath)
@@ -41,13 +129,45 @@ func NewRedirectDetector(configPath string) (*RedirectDetector, error) {
 		if len(config.RedirectParams) > 0 {
 			params = config.RedirectParams
 		}
+		if len(config.Heuristics) > 0 {
+			heuristics = config.Heuristics
+			for i := range heuristics {
+				if err := heuristics[i].compile(); err != nil {
+					return nil, fmt.Errorf("%s: heuristics[%d]: %w", configPath, i, err)
+				}
+			}
+		}
 	}
 
 	return &RedirectDetector{
 		redirectParams: params,
+		heuristics:     heuristics,
 	}, nil
 }
 
+// match returns the highest confidence heuristic matching value, the first one listed
+// winning ties
+func (d *RedirectDetector) match(value string, known bool) (Heuristic, bool) {
+	var best Heuristic
+	found := false
+	for _, h := range d.heuristics {
+		if h.matches(value, known) && (!found || !best.Confidence.AtLeast(h.Confidence)) {
+			best, found = h, true
+		}
+	}
+	return best, found
+}
+
+// isKnownParam reports whether param is one of the redirect parameters
+func (d *RedirectDetector) isKnownParam(param string) bool {
+	for _, redirectParam := range d.redirectParams {
+		if strings.EqualFold(param, redirectParam) {
+			return true
+		}
+	}
+	return false
+}
+
 func loadConfig(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -58,8 +178,8 @@ func loadConfig(path string) (*Config, error) {
 	}
 
 	var config Config
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, err
+	if _, err := yamlcheck.Decode(data, &config); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
 	}
 
 	return &config, nil
@@ -67,57 +187,7 @@ func loadConfig(path string) (*Config, error) {
 
 // DetectRedirectParams analyzes a URL for potential open redirect parameters
 func (d *RedirectDetector) DetectRedirectParams(urlStr string) bool {
-	u, err := url.Parse(urlStr)
-	if err != nil {
-		return false
-	}
-
-	query := u.Query()
-	for param, values := range query {
-		// First check known redirect parameters
-		isKnownParam := false
-		for _, redirectParam := range d.redirectParams {
-			if strings.EqualFold(param, redirectParam) {
-				isKnownParam = true
-				break
-			}
-		}
-
-		// Check parameter values regardless of parameter name
-		for _, value := range values {
-			if isURLLike(value) {
-				// If it's a known redirect parameter, or if the value is URL-like,
-				// consider it a potential redirect
-				if isKnownParam || !isNumericOrShort(value) {
-					return true
-				}
-			}
-		}
-	}
-
-	return false
-}
-
-// isURLLike checks if a string looks like a URL
-func isURLLike(value string) bool {
-	return strings.HasPrefix(value, "http://") ||
-		strings.HasPrefix(value, "https://") ||
-		strings.HasPrefix(value, "//")
-}
-
-// isNumericOrShort returns true if the string is numeric or too short to be a URL
-func isNumericOrShort(value string) bool {
-	if len(value) < 4 { // too short to be a URL
-		return true
-	}
-
-	// Check if it's numeric
-	for _, r := range value {
-		if r < '0' || r > '9' {
-			return false
-		}
-	}
-	return true
+	return d.ScanURL(urlStr).IsVulnerable
 }
 
 // RedirectResult represents the result of scanning a URL for open redirects
@@ -129,9 +199,11 @@ type RedirectResult struct {
 
 // MatchedParameter contains details about a matched redirect parameter
 type MatchedParameter struct {
-	Name    string
-	Value   string
-	IsKnown bool // Whether it's a known redirect parameter
+	Name       string
+	Value      string
+	IsKnown    bool               // Whether it's a known redirect parameter
+	Confidence finding.Confidence // Confidence of the heuristic that matched the value
+	Heuristic  string             // Name of the heuristic that matched the value
 }
 
 // ScanURLs analyzes multiple URLs for potential open redirects
@@ -168,28 +240,28 @@ func (d *RedirectDetector) ScanURL(urlStr string) RedirectResult {
 
 	query := u.Query()
 	for param, values := range query {
-		// Check if it's a known redirect parameter
-		isKnown := false
-		for _, redirectParam := range d.redirectParams {
-			if strings.EqualFold(param, redirectParam) {
-				isKnown = true
-				break
-			}
-		}
-
+		isKnown := d.isKnownParam(param)
 		for _, value := range values {
-			if isURLLike(value) {
-				if isKnown || !isNumericOrShort(value) {
-					result.IsVulnerable = true
-					result.MatchedParams = append(result.MatchedParams, MatchedParameter{
-						Name:    param,
-						Value:   value,
-						IsKnown: isKnown,
-					})
-				}
+			if h, ok := d.match(value, isKnown); ok {
+				result.IsVulnerable = true
+				result.MatchedParams = append(result.MatchedParams, MatchedParameter{
+					Name:       param,
+					Value:      value,
+					IsKnown:    isKnown,
+					Confidence: h.Confidence,
+					Heuristic:  h.Name,
+				})
 			}
 		}
 	}
 
+	// Rank the most likely redirects first
+	sort.SliceStable(result.MatchedParams, func(i, j int) bool {
+		a, b := result.MatchedParams[i], result.MatchedParams[j]
+		if a.Confidence != b.Confidence {
+			return a.Confidence.AtLeast(b.Confidence)
+		}
+		return a.Name < b.Name
+	})
 	return result
 }
@@ -2,8 +2,12 @@ package redirect
 
 import (
 	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
 )
 
 func TestDetectRedirectParams(t *testing.T) {
@@ -146,6 +150,91 @@ func TestLoadConfig(t *testing.T) {
 	}
 }
 
+func TestHeuristics(t *testing.T) {
+	config := `redirect_params:
+  - next
+heuristics:
+  - name: internal-path
+    pattern: ^/[a-z]
+    known_params_only: true
+    confidence: low
+  - name: long-url
+    prefixes: [https://]
+    min_length: 20
+    confidence: high
+  - name: any-url
+    prefixes: [http://, https://]
+`
+	path := filepath.Join(t.TempDir(), "redirect.yaml")
+	if err := os.WriteFile(path, []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	detector, err := NewRedirectDetector(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := detector.ScanURL("https://example.com/?a=http://x.io&next=/account&b=https://evil.example.com/login&c=//evil.com&d=/home")
+	want := []MatchedParameter{
+		{Name: "b", Value: "https://evil.example.com/login", Confidence: finding.ConfidenceHigh, Heuristic: "long-url"},
+		{Name: "a", Value: "http://x.io", Confidence: finding.ConfidenceMedium, Heuristic: "any-url"},
+		{Name: "next", Value: "/account", IsKnown: true, Confidence: finding.ConfidenceLow, Heuristic: "internal-path"},
+	}
+	if !result.IsVulnerable || !reflect.DeepEqual(result.MatchedParams, want) {
+		t.Errorf("ScanURL() = %+v, want %+v", result.MatchedParams, want)
+	}
+}
+
+func TestHeuristics_Case(t *testing.T) {
+	detector, err := NewRedirectDetector("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := detector.ScanURL("https://example.com/?next=HTTPS://evil.com"); result.IsVulnerable {
+		t.Errorf("default heuristics matched an upper case prefix: %+v", result.MatchedParams)
+	}
+
+	path := filepath.Join(t.TempDir(), "redirect.yaml")
+	config := "heuristics:\n  - name: any-url\n    prefixes: [https://]\n    ignore_case: true\n"
+	if err := os.WriteFile(path, []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if detector, err = NewRedirectDetector(path); err != nil {
+		t.Fatal(err)
+	}
+	if !detector.DetectRedirectParams("https://example.com/?next=HTTPS://evil.com") {
+		t.Error("ignore_case heuristic did not match an upper case prefix")
+	}
+	if detector.DetectRedirectParams("https://example.com/?next=http") {
+		t.Error("ignore_case heuristic matched a value shorter than its prefix")
+	}
+}
+
+func TestHeuristics_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{"missing name", "heuristics:\n  - prefixes: [https://]\n", "name is required"},
+		{"no conditions", "heuristics:\n  - name: empty\n", "empty: prefixes, min_length or pattern is required"},
+		{"invalid pattern", "heuristics:\n  - name: bad\n    pattern: '('\n", "bad: invalid pattern"},
+		{"invalid confidence", "heuristics:\n  - name: bad\n    min_length: 5\n    confidence: certain\n", "invalid confidence level"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "redirect.yaml")
+			if err := os.WriteFile(path, []byte(tt.config), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := NewRedirectDetector(path)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("NewRedirectDetector() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestScanURLs(t *testing.T) {
 	detector, err := NewRedirectDetector("")
 	if err != nil {
@@ -170,9 +259,11 @@ func TestScanURLs(t *testing.T) {
 					IsVulnerable: true,
 					MatchedParams: []MatchedParameter{
 						{
-							Name:    "next",
-							Value:   "https://evil.com",
-							IsKnown: true,
+							Name:       "next",
+							Value:      "https://evil.com",
+							IsKnown:    true,
+							Confidence: finding.ConfidenceHigh,
+							Heuristic:  "known-param-url",
 						},
 					},
 				},
@@ -181,9 +272,11 @@ func TestScanURLs(t *testing.T) {
 					IsVulnerable: true,
 					MatchedParams: []MatchedParameter{
 						{
-							Name:    "random",
-							Value:   "//evil.com",
-							IsKnown: false,
+							Name:       "random",
+							Value:      "//evil.com",
+							IsKnown:    false,
+							Confidence: finding.ConfidenceMedium,
+							Heuristic:  "url-value",
 						},
 					},
 				},
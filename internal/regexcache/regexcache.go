@@ -0,0 +1,77 @@
+// Package regexcache compiles the user-supplied regular expressions of tag rules, transforms,
+// line filters and detector configurations once per process. Compiled programs are keyed by
+// the SHA-256 of their pattern and shared by every caller, so the daemon's jobs and pipeline
+// workers that load the same patterns again and again reuse them instead of recompiling.
+//
+// A *regexp.Regexp is safe for concurrent use, so sharing it is transparent to callers as
+// long as none of them calls its Longest method.
+package regexcache
+
+import (
+	"crypto/sha256"
+	"regexp"
+	"runtime"
+	"sync"
+)
+
+// maxEntries bounds the number of patterns remembered. When it is reached the cache starts
+// over, which only costs recompiling the patterns still in use.
+const maxEntries = 4096
+
+// entry is a pattern compiled at most once, however many callers ask for it concurrently
+type entry struct {
+	once sync.Once
+	re   *regexp.Regexp
+	err  error
+}
+
+var (
+	mu      sync.Mutex
+	entries = make(map[[sha256.Size]byte]*entry)
+)
+
+// Compile returns the compiled pattern, compiling it with regexp.Compile the first time it is
+// requested. Errors are cached too, so an invalid pattern is reported the same way every time.
+func Compile(pattern string) (*regexp.Regexp, error) {
+	key := sha256.Sum256([]byte(pattern))
+	mu.Lock()
+	e, ok := entries[key]
+	if !ok {
+		if len(entries) >= maxEntries {
+			entries = make(map[[sha256.Size]byte]*entry)
+		}
+		e = &entry{}
+		entries[key] = e
+	}
+	mu.Unlock()
+
+	e.once.Do(func() {
+		e.re, e.err = regexp.Compile(pattern)
+	})
+	return e.re, e.err
+}
+
+// Warm compiles patterns in parallel, so that a configuration with dozens of custom patterns
+// is compiled on every CPU rather than one pattern after the other. Errors are left for the
+// Compile calls that use the patterns to report.
+func Warm(patterns []string) {
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+	var wg sync.WaitGroup
+	for _, p := range patterns {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(p string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			Compile(p)
+		}(p)
+	}
+	wg.Wait()
+}
+
+// Len returns the number of patterns cached
+func Len() int {
+	mu.Lock()
+	defer mu.Unlock()
+	return len(entries)
+}
@@ -0,0 +1,74 @@
+package regexcache
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestCompile(t *testing.T) {
+	a, err := Compile(`^/api/v\d+/`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Compile(`^/api/v\d+/`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a != b {
+		t.Error("Compile() of the same pattern returned different programs")
+	}
+	if !a.MatchString("/api/v2/users") {
+		t.Error("compiled pattern does not match")
+	}
+
+	_, err1 := Compile(`(`)
+	_, err2 := Compile(`(`)
+	if err1 == nil || err2 == nil || err1.Error() != err2.Error() {
+		t.Errorf("Compile() of an invalid pattern = %v, %v", err1, err2)
+	}
+}
+
+func TestCompile_Concurrent(t *testing.T) {
+	patterns := make([]string, 20)
+	for i := range patterns {
+		patterns[i] = fmt.Sprintf(`^tenant-%d\.target\.com$`, i)
+	}
+	Warm(patterns)
+
+	var wg sync.WaitGroup
+	got := make([][]interface{}, 8)
+	for w := range got {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for _, p := range patterns {
+				re, err := Compile(p)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				got[w] = append(got[w], re)
+			}
+		}(w)
+	}
+	wg.Wait()
+	for w := range got {
+		for i := range patterns {
+			if got[w][i] != got[0][i] {
+				t.Fatalf("worker %d got a different program for %q", w, patterns[i])
+			}
+		}
+	}
+}
+
+func TestCompile_Bounded(t *testing.T) {
+	for i := 0; i <= maxEntries; i++ {
+		if _, err := Compile(fmt.Sprintf(`^bounded-%d$`, i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := Len(); n > maxEntries {
+		t.Errorf("Len() = %d, want at most %d", n, maxEntries)
+	}
+}
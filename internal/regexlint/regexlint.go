@@ -0,0 +1,303 @@
+// Package regexlint looks for mistakes in user-supplied regular expressions before a run
+// starts: patterns that match everything, repetitions that stretch a match across a whole
+// input line, constructs that are slow or would backtrack catastrophically if the pattern is
+// reused with a PCRE-style engine, and anchors or dots that do not mean what they seem to.
+//
+// Go's RE2 engine runs in linear time, so none of these make a run hang; the warnings point
+// at patterns that are slower or match more than their author meant.
+package regexlint
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Kind is what a pattern is matched against, which decides the checks that apply
+type Kind int
+
+const (
+	// Line patterns select input lines, such as -grep and -vgrep
+	Line Kind = iota
+	// Value patterns match finding values, such as tag rules
+	Value
+	// Replace patterns rewrite finding values, such as transform rules, where leading and
+	// trailing repetitions change what is replaced
+	Replace
+)
+
+// maxRepeat is the largest counted repetition not warned about. Each repetition compiles
+// to a copy of its subexpression, and Go rejects counts above 1000.
+const maxRepeat = 100
+
+// Warning is a problem found in a pattern with a suggested fix
+type Warning struct {
+	// Message describes the problem, worded to follow the pattern, e.g. "matches ..."
+	Message string
+	// Fix suggests how to rewrite the pattern
+	Fix string
+}
+
+func (w Warning) String() string {
+	return w.Message + "; " + w.Fix
+}
+
+// hostnameRegex matches literals that look like a host name, which a Value pattern should
+// anchor at the end
+var hostnameRegex = regexp.MustCompile(`(?i)^[a-z0-9-]+(?:\.[a-z0-9-]+)*\.[a-z]{2,}$`)
+
+// Check returns the warnings for pattern. Invalid patterns have no warnings; compiling them
+// reports the error.
+func Check(pattern string, kind Kind) []Warning {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil
+	}
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil
+	}
+
+	var warnings []Warning
+	// A pattern matching both the empty string and an unrelated character matches anything;
+	// ^$ matches the empty string too, but only blank text
+	if compiled.MatchString("") && compiled.MatchString("\x00") {
+		warnings = append(warnings, Warning{
+			Message: "matches an empty string, so it matches everything",
+			Fix:     "remove empty alternatives and use + instead of * where at least one character is needed",
+		})
+	}
+	if kind != Replace {
+		warnings = append(warnings, checkEnds(re, kind)...)
+		warnings = append(warnings, checkAlternateAnchors(re)...)
+	}
+	if kind == Value && re.Op == syntax.OpLiteral && re.Flags&syntax.FoldCase == 0 && hostnameRegex.MatchString(string(re.Rune)) {
+		host := string(re.Rune)
+		warnings = append(warnings, Warning{
+			Message: fmt.Sprintf("matches anywhere in a value, such as in %s", "not"+host+".evil.example"),
+			Fix:     fmt.Sprintf("anchor it, e.g. (?:^|\\.)%s$", regexp.QuoteMeta(host)),
+		})
+	}
+	walk(re, func(re *syntax.Regexp) {
+		warnings = append(warnings, checkNode(re)...)
+	})
+	return warnings
+}
+
+// checkEnds warns about unbounded "any character" repetitions at either end of an
+// unanchored pattern, which only stretch the match, and in the middle of Line patterns,
+// where a match can run across a line of any length
+func checkEnds(re *syntax.Regexp, kind Kind) []Warning {
+	var warnings []Warning
+	subs := []*syntax.Regexp{re}
+	if re.Op == syntax.OpConcat {
+		subs = re.Sub
+	}
+	for i, sub := range subs {
+		if !anyRepeat(sub) {
+			continue
+		}
+		switch {
+		case len(subs) == 1:
+			// The whole pattern is .* or .+, which the empty match check or the pattern's
+			// obvious intent covers
+		case i == 0:
+			warnings = append(warnings, Warning{
+				Message: fmt.Sprintf("starts with %s, which only stretches each match to the start of the text", source(sub)),
+				Fix:     fmt.Sprintf("remove the leading %s; patterns match anywhere unless anchored with ^", source(sub)),
+			})
+		case i == len(subs)-1:
+			warnings = append(warnings, Warning{
+				Message: fmt.Sprintf("ends with %s, which only stretches each match to the end of the text", source(sub)),
+				Fix:     fmt.Sprintf("remove the trailing %s; patterns match anywhere unless anchored with $", source(sub)),
+			})
+		case kind == Line:
+			warnings = append(warnings, Warning{
+				Message: fmt.Sprintf("contains %s, which can span a whole line of any length", source(sub)),
+				Fix:     "bound it, e.g. .{0,200}, or use a negated class such as [^\"'\\s]*",
+			})
+		}
+	}
+	return warnings
+}
+
+// checkAlternateAnchors warns about ^a|b and a|b$, where the anchor applies to one
+// alternative only
+func checkAlternateAnchors(re *syntax.Regexp) []Warning {
+	if re.Op != syntax.OpAlternate {
+		return nil
+	}
+	first, last := re.Sub[0], re.Sub[len(re.Sub)-1]
+	var warnings []Warning
+	if startsWithAnchor(first) && !startsWithAnchor(last) {
+		warnings = append(warnings, Warning{
+			Message: "anchors only its first alternative with ^",
+			Fix:     "group the alternatives, e.g. ^(?:a|b)",
+		})
+	}
+	if endsWithAnchor(last) && !endsWithAnchor(first) {
+		warnings = append(warnings, Warning{
+			Message: "anchors only its last alternative with $",
+			Fix:     "group the alternatives, e.g. (?:a|b)$",
+		})
+	}
+	return warnings
+}
+
+// checkNode warns about ambiguous nested repetitions, large counted repetitions and dots
+// between letters in a single node of the syntax tree
+func checkNode(re *syntax.Regexp) []Warning {
+	var warnings []Warning
+	switch re.Op {
+	case syntax.OpStar, syntax.OpPlus, syntax.OpRepeat:
+		if re.Op == syntax.OpRepeat && (re.Min > maxRepeat || re.Max > maxRepeat) {
+			warnings = append(warnings, Warning{
+				Message: fmt.Sprintf("repeats %s more than %d times, which compiles to as many copies of it", source(re), maxRepeat),
+				Fix:     "use a smaller count, or + if any length will do",
+			})
+		}
+		if unbounded(re) && ambiguous(re.Sub[0]) {
+			warnings = append(warnings, Warning{
+				Message: fmt.Sprintf("nests unbounded repetitions in %s, which is slow here and backtracks catastrophically in PCRE-style engines", source(re)),
+				Fix:     "remove the inner quantifier or make the repeated parts unable to match the same text",
+			})
+		}
+	case syntax.OpConcat:
+		for i := 1; i+1 < len(re.Sub); i++ {
+			if isDot(re.Sub[i]) && endsWithLetter(re.Sub[i-1]) && startsWithLetter(re.Sub[i+1]) {
+				warnings = append(warnings, Warning{
+					Message: fmt.Sprintf("uses . in %s, where it matches any character and not only a dot", source(re)),
+					Fix:     "escape it as \\.",
+				})
+				break
+			}
+		}
+	}
+	return warnings
+}
+
+// source returns re as a pattern for messages. The syntax tree prints every . with the
+// (?s:) or (?-s:) flag group deciding whether it matches a line break; those are left out.
+func source(re *syntax.Regexp) string {
+	s := re.String()
+	var b strings.Builder
+	var dropped []bool // whether each open group's closing parenthesis is left out
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s):
+			b.WriteString(s[i : i+2])
+			i++
+		case c == '[':
+			// Copy character classes as they are, since parentheses in them are literal
+			j := i + 1
+			for j < len(s) && s[j] != ']' {
+				if s[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			b.WriteString(s[i:min(j+1, len(s))])
+			i = j
+		case strings.HasPrefix(s[i:], "(?"):
+			end := strings.IndexByte(s[i:], ':')
+			if end < 0 || strings.Trim(s[i+2:i+end], "imsU-") != "" {
+				dropped = append(dropped, false)
+				b.WriteByte(c)
+				continue
+			}
+			flags := strings.TrimSuffix(strings.NewReplacer("-s", "-", "s", "").Replace(s[i+2:i+end]), "-")
+			dropped = append(dropped, flags == "")
+			if flags != "" {
+				b.WriteString("(?" + flags + ":")
+			}
+			i += end
+		case c == '(':
+			dropped = append(dropped, false)
+			b.WriteByte(c)
+		case c == ')' && len(dropped) > 0:
+			if !dropped[len(dropped)-1] {
+				b.WriteByte(c)
+			}
+			dropped = dropped[:len(dropped)-1]
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// walk calls fn for re and every node below it
+func walk(re *syntax.Regexp, fn func(*syntax.Regexp)) {
+	fn(re)
+	for _, sub := range re.Sub {
+		walk(sub, fn)
+	}
+}
+
+// anyRepeat reports whether re is an unbounded repetition of any character, such as .* or .+
+func anyRepeat(re *syntax.Regexp) bool {
+	return unbounded(re) && isDot(re.Sub[0])
+}
+
+// isDot reports whether re is ., with or without the s flag
+func isDot(re *syntax.Regexp) bool {
+	return re.Op == syntax.OpAnyCharNotNL || re.Op == syntax.OpAnyChar
+}
+
+// unbounded reports whether re repeats its subexpression without an upper bound
+func unbounded(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpStar, syntax.OpPlus:
+		return true
+	case syntax.OpRepeat:
+		return re.Max == -1
+	}
+	return false
+}
+
+// ambiguous reports whether the body of a repetition is itself repeated, as in (a+)+, or
+// made only of repeated or optional parts, as in (\w+\s*)+, so that the outer repetition can
+// split the same text many ways
+func ambiguous(re *syntax.Regexp) bool {
+	for re.Op == syntax.OpCapture {
+		re = re.Sub[0]
+	}
+	if re.Op != syntax.OpConcat {
+		return unbounded(re)
+	}
+	for _, sub := range re.Sub {
+		if !unbounded(sub) && sub.Op != syntax.OpQuest {
+			return false
+		}
+	}
+	return true
+}
+
+func startsWithAnchor(re *syntax.Regexp) bool {
+	for re.Op == syntax.OpConcat || re.Op == syntax.OpCapture {
+		re = re.Sub[0]
+	}
+	return re.Op == syntax.OpBeginText || re.Op == syntax.OpBeginLine
+}
+
+func endsWithAnchor(re *syntax.Regexp) bool {
+	for re.Op == syntax.OpConcat || re.Op == syntax.OpCapture {
+		re = re.Sub[len(re.Sub)-1]
+	}
+	return re.Op == syntax.OpEndText || re.Op == syntax.OpEndLine
+}
+
+func startsWithLetter(re *syntax.Regexp) bool {
+	return re.Op == syntax.OpLiteral && isAlnum(re.Rune[0])
+}
+
+func endsWithLetter(re *syntax.Regexp) bool {
+	return re.Op == syntax.OpLiteral && isAlnum(re.Rune[len(re.Rune)-1])
+}
+
+func isAlnum(r rune) bool {
+	return r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
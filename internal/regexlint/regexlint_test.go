@@ -0,0 +1,52 @@
+package regexlint
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		kind    Kind
+		want    []string // substrings of the warnings, in order
+	}{
+		{"clean", `^https://api\.target\.com/`, Line, nil},
+		{"tag word", `staging`, Value, nil},
+		{"blank lines", `^$`, Line, nil},
+		{"invalid", `(`, Line, nil},
+		{"empty alternative", `admin|`, Value, []string{"matches an empty string"}},
+		{"star only", `x*`, Line, []string{"matches an empty string"}},
+		{"leading dot star", `.*admin`, Value, []string{"starts with .*"}},
+		{"trailing dot plus", `/api/.+`, Line, []string{"ends with .+"}},
+		{"middle of a line", `token=.*&`, Line, []string{"contains .*, which can span a whole line"}},
+		{"middle of a value", `token=.*&`, Value, nil},
+		{"transform", `^(.*)/index\.html$`, Replace, nil},
+		{"transform suffix", `\?.*`, Replace, nil},
+		{"nested", `(a+)+b`, Value, []string{"nests unbounded repetitions in (a+)+"}},
+		{"nested dots", `(.+ *)+x`, Value, []string{`nests unbounded repetitions in (.+ *)+,`}},
+		{"nested words", `^(\w+\s*)+$`, Line, []string{"nests unbounded repetitions"}},
+		{"separated repetition", `^(\w+\.)+com$`, Value, nil},
+		{"large repeat", `[a-f0-9]{512}`, Value, []string{"more than 100 times"}},
+		{"first alternative anchored", `^admin|manage`, Value, []string{"anchors only its first alternative"}},
+		{"last alternative anchored", `admin|manage$`, Value, []string{"anchors only its last alternative"}},
+		{"grouped alternatives", `^(?:admin|manage)$`, Value, nil},
+		{"unescaped dot", `admin.target.com`, Line, []string{"uses . in admin.target.com"}},
+		{"unanchored host", `target\.com`, Value, []string{`matches anywhere in a value, such as in nottarget.com.evil.example; anchor it, e.g. (?:^|\.)target\.com$`}},
+		{"unanchored host on a line", `target\.com`, Line, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Check(tt.pattern, tt.kind)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Check(%q) = %v, want %d warnings", tt.pattern, got, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(got[i].String(), want) {
+					t.Errorf("Check(%q) warning = %q, want it to contain %q", tt.pattern, got[i], want)
+				}
+			}
+		})
+	}
+}
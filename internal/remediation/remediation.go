@@ -0,0 +1,136 @@
+// Package remediation holds short fixes and reference links for security findings, such as
+// leaked secrets, takeover candidates and open redirects, so reports tell the reader what to
+// do next. The built-in guidance links to public references; teams can point it at their own
+// wiki pages in the configuration file.
+package remediation
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+// OpenRedirect is the key of the guidance for -detect-redirects results, which are not findings
+const OpenRedirect = "open_redirect"
+
+// defaults is the built-in guidance keyed by finding type or OpenRedirect
+var defaults = map[string]finding.Remediation{
+	string(finding.TypeToken): {
+		Hint:      "Check whether the token is a live credential; if so, revoke it and load it from a secret store at runtime",
+		Reference: "https://cheatsheetseries.owasp.org/cheatsheets/Secrets_Management_Cheat_Sheet.html",
+	},
+	string(finding.TypeCloudConfig): {
+		Hint:      "Restrict the key to the referrers, APIs and quotas the client needs; keys shipped to browsers cannot be kept secret",
+		Reference: "https://cloud.google.com/docs/authentication/api-keys",
+	},
+	string(finding.TypeConfigSecret): {
+		Hint:      "Rotate the secret and remove it from files served or committed; load it from a secret store at runtime",
+		Reference: "https://cheatsheetseries.owasp.org/cheatsheets/Secrets_Management_Cheat_Sheet.html",
+	},
+	string(finding.TypeHeaderIssue): {
+		Hint:      "Tighten the header: drop unsafe-inline and wildcard sources from CSP and send HSTS with a max-age of at least a year",
+		Reference: "https://cheatsheetseries.owasp.org/cheatsheets/HTTP_Headers_Cheat_Sheet.html",
+	},
+	string(finding.TypeTakeover): {
+		Hint:      "Remove the DNS record pointing at the unclaimed resource, or claim the resource before someone else does",
+		Reference: "https://developer.mozilla.org/en-US/docs/Web/Security/Subdomain_takeovers",
+	},
+	string(finding.TypeJSONP): {
+		Hint:      "Replace the JSONP endpoint with CORS, or restrict callback names to a fixed allowlist",
+		Reference: "https://cheatsheetseries.owasp.org/cheatsheets/AJAX_Security_Cheat_Sheet.html",
+	},
+	string(finding.TypeCORS): {
+		Hint:      "Compare the Origin against an allowlist of exact origins instead of reflecting it, and never allow credentials for *",
+		Reference: "https://portswigger.net/web-security/cors",
+	},
+	string(finding.TypeVerboseError): {
+		Hint:      "Disable debug mode in production and return generic error pages; log the details server-side",
+		Reference: "https://cheatsheetseries.owasp.org/cheatsheets/Error_Handling_Cheat_Sheet.html",
+	},
+	OpenRedirect: {
+		Hint:      "Redirect only to relative paths or to hosts on an allowlist, and reject scheme-relative //host targets",
+		Reference: "https://cheatsheetseries.owasp.org/cheatsheets/Unvalidated_Redirects_and_Forwards_Cheat_Sheet.html",
+	},
+}
+
+// Catalog maps finding types, and OpenRedirect, to their guidance
+type Catalog struct {
+	guidance map[string]finding.Remediation
+}
+
+// Default returns the built-in guidance
+func Default() *Catalog {
+	return &Catalog{guidance: defaults}
+}
+
+// ValidKey checks that key is a finding type name or OpenRedirect
+func ValidKey(key string) error {
+	if key == OpenRedirect {
+		return nil
+	}
+	if _, err := finding.ParseType(key); err != nil || strings.ToLower(strings.TrimSpace(key)) != key {
+		return fmt.Errorf("unknown finding type %q: must be a finding type such as config_secret, or %s", key, OpenRedirect)
+	}
+	return nil
+}
+
+// Validate checks an override: its key, its hint, which only finding types with built-in
+// guidance may leave out, and its reference, which must be an absolute URL
+func Validate(key string, r finding.Remediation) error {
+	if err := ValidKey(key); err != nil {
+		return err
+	}
+	if r.Hint == "" && r.Reference == "" {
+		return fmt.Errorf("hint or reference is required")
+	}
+	if _, ok := defaults[key]; !ok && r.Hint == "" {
+		return fmt.Errorf("hint is required, since %s has no built-in guidance", key)
+	}
+	if r.Reference != "" {
+		if u, err := url.Parse(r.Reference); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("reference %q must be an absolute URL", r.Reference)
+		}
+	}
+	return nil
+}
+
+// With returns a copy of the catalog with overrides, validated with Validate. An override
+// without a hint keeps the built-in hint, so a team can point a finding type at its own wiki
+// page while keeping the default text.
+func (c *Catalog) With(overrides map[string]finding.Remediation) *Catalog {
+	merged := make(map[string]finding.Remediation, len(c.guidance)+len(overrides))
+	for key, r := range c.guidance {
+		merged[key] = r
+	}
+	for key, r := range overrides {
+		base := merged[key]
+		if r.Hint != "" {
+			base.Hint = r.Hint
+		}
+		if r.Reference != "" {
+			base.Reference = r.Reference
+		}
+		merged[key] = base
+	}
+	return &Catalog{guidance: merged}
+}
+
+// Lookup returns the guidance for key, a finding type or OpenRedirect
+func (c *Catalog) Lookup(key string) (finding.Remediation, bool) {
+	r, ok := c.guidance[key]
+	return r, ok && r.Hint != ""
+}
+
+// Apply returns a copy of findings with the guidance for their type attached
+func (c *Catalog) Apply(findings []finding.Finding) []finding.Finding {
+	annotated := make([]finding.Finding, len(findings))
+	for i, f := range findings {
+		if r, ok := c.Lookup(string(f.Type)); ok {
+			f.Remediation = &r
+		}
+		annotated[i] = f
+	}
+	return annotated
+}
@@ -0,0 +1,66 @@
+package remediation
+
+import (
+	"testing"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+func TestCatalog_With(t *testing.T) {
+	catalog := Default().With(map[string]finding.Remediation{
+		string(finding.TypeConfigSecret): {Reference: "https://wiki.corp.example/secrets"},
+		string(finding.TypeBucket):       {Hint: "Make sure the bucket is private"},
+	})
+
+	secret, ok := catalog.Lookup(string(finding.TypeConfigSecret))
+	if !ok || secret.Hint != defaults[string(finding.TypeConfigSecret)].Hint || secret.Reference != "https://wiki.corp.example/secrets" {
+		t.Errorf("Lookup(config_secret) = %+v, %v, want the built-in hint with the wiki reference", secret, ok)
+	}
+	if bucket, ok := catalog.Lookup(string(finding.TypeBucket)); !ok || bucket.Hint != "Make sure the bucket is private" || bucket.Reference != "" {
+		t.Errorf("Lookup(bucket) = %+v, %v", bucket, ok)
+	}
+	if _, ok := Default().Lookup(string(finding.TypeBucket)); ok {
+		t.Error("With() changed the built-in guidance")
+	}
+	if _, ok := catalog.Lookup(string(finding.TypeEmail)); ok {
+		t.Error("Lookup(email) found guidance for a type without any")
+	}
+}
+
+func TestCatalog_Apply(t *testing.T) {
+	findings := []finding.Finding{
+		{Type: finding.TypeTakeover, Value: "s3://gone-assets"},
+		{Type: finding.TypeDomain, Value: "www.target.com"},
+	}
+	got := Default().Apply(findings)
+	if got[0].Remediation == nil || got[0].Remediation.Reference == "" {
+		t.Errorf("takeover remediation = %+v", got[0].Remediation)
+	}
+	if got[1].Remediation != nil {
+		t.Errorf("domain remediation = %+v, want none", got[1].Remediation)
+	}
+	if findings[0].Remediation != nil {
+		t.Error("Apply() modified its input")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		key     string
+		r       finding.Remediation
+		wantErr bool
+	}{
+		{OpenRedirect, finding.Remediation{Reference: "https://wiki.corp.example/redirects"}, false},
+		{"cors", finding.Remediation{Hint: "Use an allowlist"}, false},
+		{"CORS", finding.Remediation{Hint: "Use an allowlist"}, true},
+		{"redirects", finding.Remediation{Hint: "Use an allowlist"}, true},
+		{"cors", finding.Remediation{}, true},
+		{"email", finding.Remediation{Reference: "https://wiki.corp.example/email"}, true},
+		{"cors", finding.Remediation{Reference: "/wiki/cors"}, true},
+	}
+	for _, tt := range tests {
+		if err := Validate(tt.key, tt.r); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%q, %+v) error = %v, wantErr %v", tt.key, tt.r, err, tt.wantErr)
+		}
+	}
+}
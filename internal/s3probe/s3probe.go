@@ -0,0 +1,180 @@
+// Package s3probe checks whether S3 buckets allow anonymous access, using the unauthenticated
+// ListObjects and GetBucketAcl requests an attacker would send.
+package s3probe
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/httpclient"
+)
+
+const (
+	// defaultConcurrency is the number of buckets checked in parallel when none is configured
+	defaultConcurrency = 4
+	// maxBodySize caps how much of an error response is read to find its code
+	maxBodySize = 64 * 1024
+)
+
+// Access levels recorded in the "s3_access" metadata of bucket findings
+const (
+	// PublicListable buckets list their objects to anyone
+	PublicListable = "public-listable"
+	// PublicReadable buckets do not list their objects but show their ACL to anyone
+	PublicReadable = "public-readable"
+	// Denied buckets exist but refuse anonymous requests
+	Denied = "denied"
+	// Missing buckets do not exist, so anyone could register the name
+	Missing = "missing"
+)
+
+// Prober checks bucket findings against the S3 API
+type Prober struct {
+	// Client sends the S3 API requests
+	Client httpclient.Doer
+	// Concurrency bounds the number of buckets checked in parallel
+	Concurrency int
+	// Delay is the minimum interval between the start of two bucket checks
+	Delay time.Duration
+	// Endpoint is the base URL requests are sent to in path style, e.g. for S3 compatible
+	// services or tests. When empty, virtual-hosted requests are sent to s3.amazonaws.com.
+	Endpoint string
+}
+
+// Probe checks every S3 bucket finding and records its access level in "s3_access", and the
+// bucket's region in "region" when S3 redirects to it. Buckets that cannot be reached are left
+// unchanged.
+func (p *Prober) Probe(ctx context.Context, findings []finding.Finding) {
+	concurrency := p.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	var tick <-chan time.Time
+	if p.Delay > 0 {
+		ticker := time.NewTicker(p.Delay)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	started := false
+	for i := range findings {
+		f := &findings[i]
+		if f.Type != finding.TypeBucket || f.Metadata["provider"] != "s3" {
+			continue
+		}
+		if started && tick != nil {
+			select {
+			case <-tick:
+			case <-ctx.Done():
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		started = true
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(f *finding.Finding) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			access, region, err := p.Bucket(ctx, f.Value, f.Metadata["region"])
+			if err != nil {
+				return
+			}
+			f.SetMeta("s3_access", access)
+			if region != "" {
+				f.SetMeta("region", region)
+			}
+		}(f)
+	}
+	wg.Wait()
+}
+
+// Bucket returns the access level of bucket. A non-empty region is used for the first request;
+// the region S3 redirects to, if any, is returned.
+func (p *Prober) Bucket(ctx context.Context, bucket, region string) (access, newRegion string, err error) {
+	status, code, redirect, err := p.request(ctx, bucket, region, "list-type=2&max-keys=1")
+	if err != nil {
+		return "", "", err
+	}
+	if status == http.StatusMovedPermanently && redirect != "" && redirect != region && p.Endpoint == "" {
+		newRegion = redirect
+		if status, code, _, err = p.request(ctx, bucket, newRegion, "list-type=2&max-keys=1"); err != nil {
+			return "", "", err
+		}
+		region = newRegion
+	}
+	switch {
+	case status == http.StatusOK:
+		return PublicListable, newRegion, nil
+	case code == "NoSuchBucket":
+		return Missing, newRegion, nil
+	}
+
+	status, _, _, err = p.request(ctx, bucket, region, "acl")
+	if err != nil {
+		return "", "", err
+	}
+	if status == http.StatusOK {
+		return PublicReadable, newRegion, nil
+	}
+	return Denied, newRegion, nil
+}
+
+// request sends an anonymous GET for the bucket sub-resource in query and returns the status,
+// the S3 error code and the region from the x-amz-bucket-region header
+func (p *Prober) request(ctx context.Context, bucket, region, query string) (int, string, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.bucketURL(bucket, region)+"?"+query, nil)
+	if err != nil {
+		return 0, "", "", err
+	}
+	resp, err := httpclient.Do(p.Client, req)
+	if err != nil {
+		return 0, "", "", err
+	}
+	defer resp.Body.Close()
+
+	var code string
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+		code = errorCode(string(body))
+	}
+	return resp.StatusCode, code, resp.Header.Get("X-Amz-Bucket-Region"), nil
+}
+
+// bucketURL returns the URL of bucket: path style on a custom endpoint or for names with dots,
+// which do not match the certificate of virtual-hosted requests, and virtual-hosted otherwise
+func (p *Prober) bucketURL(bucket, region string) string {
+	if p.Endpoint != "" {
+		return strings.TrimSuffix(p.Endpoint, "/") + "/" + url.PathEscape(bucket) + "/"
+	}
+	host := "s3.amazonaws.com"
+	if region != "" && region != "us-east-1" {
+		host = "s3." + region + ".amazonaws.com"
+	}
+	if strings.Contains(bucket, ".") {
+		return "https://" + host + "/" + url.PathEscape(bucket) + "/"
+	}
+	return "https://" + bucket + "." + host + "/"
+}
+
+// errorCode returns the <Code> of an S3 XML error document
+func errorCode(body string) string {
+	_, rest, ok := strings.Cut(body, "<Code>")
+	if !ok {
+		return ""
+	}
+	code, _, _ := strings.Cut(rest, "</Code>")
+	return code
+}
@@ -0,0 +1,74 @@
+package s3probe
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+func TestProber_Probe(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bucket := strings.Trim(r.URL.Path, "/")
+		_, acl := r.URL.Query()["acl"]
+		switch {
+		case bucket == "open":
+			fmt.Fprint(w, `<ListBucketResult><Name>open</Name></ListBucketResult>`)
+		case bucket == "acl-only" && acl:
+			fmt.Fprint(w, `<AccessControlPolicy></AccessControlPolicy>`)
+		case bucket == "gone":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `<Error><Code>NoSuchBucket</Code><Message>The specified bucket does not exist</Message></Error>`)
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`)
+		}
+	}))
+	defer srv.Close()
+
+	bucket := func(name string) finding.Finding {
+		f := finding.Finding{Type: finding.TypeBucket, Value: name}
+		f.SetMeta("provider", "s3")
+		return f
+	}
+	findings := []finding.Finding{
+		bucket("open"),
+		bucket("acl-only"),
+		bucket("locked"),
+		bucket("gone"),
+		{Type: finding.TypeDomain, Value: "open"},
+	}
+
+	p := &Prober{Client: srv.Client(), Endpoint: srv.URL}
+	p.Probe(context.Background(), findings)
+
+	want := []string{PublicListable, PublicReadable, Denied, Missing}
+	for i, access := range want {
+		if got := findings[i].Metadata["s3_access"]; got != access {
+			t.Errorf("%s s3_access = %q, want %q", findings[i].Value, got, access)
+		}
+	}
+	if findings[4].Metadata != nil {
+		t.Errorf("non-bucket finding was probed: %v", findings[4].Metadata)
+	}
+}
+
+func TestProber_BucketURL(t *testing.T) {
+	p := &Prober{}
+	tests := []struct {
+		bucket, region, want string
+	}{
+		{"assets", "", "https://assets.s3.amazonaws.com/"},
+		{"assets", "eu-west-1", "https://assets.s3.eu-west-1.amazonaws.com/"},
+		{"media.target.com", "", "https://s3.amazonaws.com/media.target.com/"},
+	}
+	for _, tt := range tests {
+		if got := p.bucketURL(tt.bucket, tt.region); got != tt.want {
+			t.Errorf("bucketURL(%q, %q) = %q, want %q", tt.bucket, tt.region, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// Package scope restricts findings to the targets of an engagement.
+// A scope file lists one target per line: an exact host such as "example.com", a wildcard
+// such as "*.example.com" covering every subdomain, an IP address or a CIDR range.
+// Blank lines and lines starting with "#" are ignored.
+package scope
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/classify"
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+// Scope is a parsed set of in-scope targets
+type Scope struct {
+	hosts    map[string]bool
+	suffixes []string
+	networks []*net.IPNet
+}
+
+// Load reads a scope file
+func Load(path string) (*Scope, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse reads scope entries from r
+func Parse(r io.Reader) (*Scope, error) {
+	s := &Scope{hosts: make(map[string]bool)}
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		entry := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		if err := s.add(entry); err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Scope) add(entry string) error {
+	switch {
+	case strings.Contains(entry, "/"):
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return fmt.Errorf("invalid CIDR %q", entry)
+		}
+		s.networks = append(s.networks, network)
+	case strings.HasPrefix(entry, "*."):
+		s.suffixes = append(s.suffixes, entry[1:])
+	case strings.Contains(entry, "*"):
+		return fmt.Errorf("invalid wildcard %q: only a leading \"*.\" is supported", entry)
+	default:
+		s.hosts[strings.TrimSuffix(entry, ".")] = true
+	}
+	return nil
+}
+
+// Host reports whether host is in scope
+func (s *Scope) Host(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if s.hosts[host] {
+		return true
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		for _, network := range s.networks {
+			if network.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, suffix := range s.suffixes {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// URL reports whether the host of u is in scope
+func (s *Scope) URL(u *url.URL) bool {
+	return s.Host(u.Hostname())
+}
+
+// Contains reports whether a finding is in scope. Domains, IPs, URLs and the domains of
+// email addresses are checked against the scope; findings that do not name a host are
+// always in scope. Contains can be used as a filter.Func.
+func (s *Scope) Contains(f finding.Finding) bool {
+	host := classify.HostOf(f)
+	if f.Type == finding.TypeEmail {
+		if i := strings.LastIndex(f.Value, "@"); i >= 0 {
+			host = f.Value[i+1:]
+		}
+	}
+	return host == "" || s.Host(host)
+}
+
+// Split separates findings into those in scope and those that are not, preserving their order
+func (s *Scope) Split(findings []finding.Finding) (in, out []finding.Finding) {
+	for _, f := range findings {
+		if s.Contains(f) {
+			in = append(in, f)
+		} else {
+			out = append(out, f)
+		}
+	}
+	return in, out
+}
@@ -0,0 +1,84 @@
+package scope
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+const testScope = `# engagement scope
+example.com
+*.target.com
+10.0.0.0/24
+192.0.2.7
+`
+
+func TestScope_Host(t *testing.T) {
+	s, err := Parse(strings.NewReader(testScope))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"example.com", true},
+		{"EXAMPLE.COM.", true},
+		{"www.example.com", false},
+		{"api.target.com", true},
+		{"a.b.target.com", true},
+		{"target.com", false},
+		{"nottarget.com", false},
+		{"10.0.0.42", true},
+		{"10.0.1.1", false},
+		{"192.0.2.7", true},
+		{"192.0.2.8", false},
+	}
+
+	for _, tt := range tests {
+		if got := s.Host(tt.host); got != tt.want {
+			t.Errorf("Host(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestScope_Split(t *testing.T) {
+	s, err := Parse(strings.NewReader(testScope))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	findings := []finding.Finding{
+		{Type: finding.TypeDomain, Value: "api.target.com"},
+		{Type: finding.TypeDomain, Value: "cdn.other.net"},
+		{Type: finding.TypeURL, Value: "https://10.0.0.5:8443/admin"},
+		{Type: finding.TypeURL, Value: "https://other.net/"},
+		{Type: finding.TypeEmail, Value: "admin@example.com"},
+		{Type: finding.TypeEmail, Value: "someone@gmail.com"},
+		{Type: finding.TypeParam, Value: "id=1"},
+	}
+
+	in, out := s.Split(findings)
+	var gotIn, gotOut []string
+	for _, f := range in {
+		gotIn = append(gotIn, f.Value)
+	}
+	for _, f := range out {
+		gotOut = append(gotOut, f.Value)
+	}
+	if want := "api.target.com https://10.0.0.5:8443/admin admin@example.com id=1"; strings.Join(gotIn, " ") != want {
+		t.Errorf("in scope = %v, want %s", gotIn, want)
+	}
+	if want := "cdn.other.net https://other.net/ someone@gmail.com"; strings.Join(gotOut, " ") != want {
+		t.Errorf("out of scope = %v, want %s", gotOut, want)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, input := range []string{"10.0.0.0/33", "api.*.example.com"} {
+		if _, err := Parse(strings.NewReader("example.com\n" + input)); err == nil || !strings.Contains(err.Error(), "line 2") {
+			t.Errorf("Parse(%q) error = %v, want line 2 error", input, err)
+		}
+	}
+}
@@ -0,0 +1,454 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/PeteJStewart/urlsluice/internal/api"
+)
+
+// Job states
+const (
+	JobQueued   = "queued"
+	JobRunning  = "running"
+	JobDone     = "done"
+	JobFailed   = "failed"
+	JobCanceled = "canceled"
+)
+
+// JobStatus is the JSON document describing a job, returned when it is submitted or polled
+// and posted to its webhook when it finishes
+type JobStatus struct {
+	ID       string      `json:"id"`
+	Status   string      `json:"status"`
+	Created  time.Time   `json:"created"`
+	Finished *time.Time  `json:"finished,omitempty"`
+	Progress Progress    `json:"progress"`
+	Result   *api.Result `json:"result,omitempty"`
+	Error    string      `json:"error,omitempty"`
+}
+
+// Progress is how much of a job's text has been read
+type Progress struct {
+	Read  int64 `json:"read"`
+	Total int64 `json:"total"`
+}
+
+// job is a submission processed in the background
+type job struct {
+	id      string
+	client  string
+	opts    api.Options
+	webhook string
+	path    string
+	total   int64
+	read    atomic.Int64
+	cancel  context.CancelFunc
+
+	mu       sync.Mutex
+	status   string
+	created  time.Time
+	finished time.Time
+	result   *api.Result
+	err      error
+}
+
+// snapshot returns the current status of j
+func (j *job) snapshot() JobStatus {
+	return j.page(j.opts.Offset, j.opts.Limit)
+}
+
+// page returns the status of j like snapshot, with the findings of its result from offset,
+// at most limit of them unless limit is 0
+func (j *job) page(offset, limit int) JobStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	st := JobStatus{
+		ID:       j.id,
+		Status:   j.status,
+		Created:  j.created,
+		Progress: Progress{Read: j.read.Load(), Total: j.total},
+	}
+	if j.result != nil {
+		st.Result = j.result.Page(offset, limit)
+	}
+	if !j.finished.IsZero() {
+		finished := j.finished
+		st.Finished = &finished
+	}
+	if j.err != nil {
+		st.Error = j.err.Error()
+	}
+	return st
+}
+
+// finish records the outcome of j, unless it already finished, and reports whether it did
+func (j *job) finish(status string, result *api.Result, err error) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if !j.finished.IsZero() {
+		return false
+	}
+	j.status, j.result, j.err, j.finished = status, result, err, time.Now().UTC()
+	return true
+}
+
+// jobs holds the submitted jobs and the workers processing them
+type jobs struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	queue  chan *job
+	wg     sync.WaitGroup
+	opts   Options
+	// settings returns the settings of jobs starting now
+	settings func() *api.Settings
+
+	mu   sync.Mutex
+	byID map[string]*job
+	// active counts the jobs of each client that are being submitted, queued or running
+	active map[string]int
+}
+
+func newJobs(opts Options, settings func() *api.Settings) *jobs {
+	ctx, cancel := context.WithCancel(context.Background())
+	js := &jobs{
+		ctx:      ctx,
+		cancel:   cancel,
+		queue:    make(chan *job, opts.MaxQueuedJobs),
+		opts:     opts,
+		settings: settings,
+		byID:     make(map[string]*job),
+		active:   make(map[string]int),
+	}
+	for i := 0; i < opts.Workers; i++ {
+		js.wg.Add(1)
+		go js.work()
+	}
+	return js
+}
+
+// submit spools the text of sub to a file and queues a job for it. A client with as many
+// jobs as its limit allows is refused before its text is read.
+func (js *jobs) submit(c client, sub *submission) (*job, int, error) {
+	js.expire(time.Now())
+	if err := js.reserve(c); err != nil {
+		return nil, http.StatusTooManyRequests, err
+	}
+	queued := false
+	defer func() {
+		if !queued {
+			js.release(c.name)
+		}
+	}()
+	f, err := os.CreateTemp(js.opts.JobDir, "urlsluice-job-*")
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	total, err := io.Copy(f, sub.text)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, http.StatusRequestEntityTooLarge, bodyTooLarge(tooLarge.Limit)
+		}
+		return nil, http.StatusInternalServerError, fmt.Errorf("error reading the request: %w", err)
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		os.Remove(f.Name())
+		return nil, http.StatusInternalServerError, err
+	}
+	j := &job{
+		id:      hex.EncodeToString(id),
+		client:  c.name,
+		opts:    sub.opts,
+		webhook: sub.webhook,
+		path:    f.Name(),
+		total:   total,
+		status:  JobQueued,
+		created: time.Now().UTC(),
+	}
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	select {
+	case js.queue <- j:
+	default:
+		os.Remove(j.path)
+		return nil, http.StatusServiceUnavailable, fmt.Errorf("too many queued jobs, try again later")
+	}
+	js.byID[j.id] = j
+	queued = true
+	return j, http.StatusAccepted, nil
+}
+
+// reserve counts a job being submitted by c against its limit, unless the limit is reached
+func (js *jobs) reserve(c client) error {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	if c.maxJobs > 0 && js.active[c.name] >= c.maxJobs {
+		return fmt.Errorf("%d jobs already queued or running, the most allowed for this client", js.active[c.name])
+	}
+	js.active[c.name]++
+	return nil
+}
+
+// release stops counting a job of client once it was refused or has finished
+func (js *jobs) release(client string) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	if js.active[client]--; js.active[client] <= 0 {
+		delete(js.active, client)
+	}
+}
+
+// get returns the job id of client; jobs of other clients are not found
+func (js *jobs) get(client, id string) *job {
+	js.expire(time.Now())
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	if j := js.byID[id]; j != nil && j.client == client {
+		return j
+	}
+	return nil
+}
+
+// remove cancels j if it is still queued or running and forgets it
+func (js *jobs) remove(j *job) {
+	js.mu.Lock()
+	delete(js.byID, j.id)
+	js.mu.Unlock()
+	if j.finish(JobCanceled, nil, nil) {
+		js.release(j.client)
+	}
+	j.mu.Lock()
+	if j.cancel != nil {
+		j.cancel()
+	}
+	j.mu.Unlock()
+}
+
+// expire forgets the jobs that finished more than JobTTL ago
+func (js *jobs) expire(now time.Time) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	for id, j := range js.byID {
+		j.mu.Lock()
+		expired := !j.finished.IsZero() && now.Sub(j.finished) > js.opts.JobTTL
+		j.mu.Unlock()
+		if expired {
+			delete(js.byID, id)
+		}
+	}
+}
+
+// close cancels the queued and running jobs and waits for the workers to stop
+func (js *jobs) close() {
+	js.cancel()
+	js.wg.Wait()
+	for {
+		select {
+		case j := <-js.queue:
+			os.Remove(j.path)
+		default:
+			return
+		}
+	}
+}
+
+func (js *jobs) work() {
+	defer js.wg.Done()
+	for {
+		select {
+		case <-js.ctx.Done():
+			return
+		case j := <-js.queue:
+			js.run(j)
+		}
+	}
+}
+
+// run extracts the findings of j and notifies its webhook
+func (js *jobs) run(j *job) {
+	defer os.Remove(j.path)
+	ctx, cancel := context.WithCancel(js.ctx)
+	defer cancel()
+	j.mu.Lock()
+	if !j.finished.IsZero() {
+		// Canceled while queued
+		j.mu.Unlock()
+		return
+	}
+	j.status, j.cancel = JobRunning, cancel
+	j.mu.Unlock()
+
+	result, err := js.extract(ctx, j)
+	status := JobDone
+	if err != nil {
+		status = JobFailed
+	}
+	if !j.finish(status, result, err) {
+		return
+	}
+	js.release(j.client)
+	if j.webhook == "" {
+		return
+	}
+	if err := js.notify(j); err != nil {
+		j.mu.Lock()
+		j.err = fmt.Errorf("error notifying webhook: %w", err)
+		j.mu.Unlock()
+	}
+}
+
+func (js *jobs) extract(ctx context.Context, j *job) (*api.Result, error) {
+	f, err := os.Open(j.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	// The whole result is kept, so that it can be polled a page at a time
+	opts := j.opts
+	opts.Offset, opts.Limit = 0, 0
+	return api.ExtractWith(ctx, &countingReader{r: f, n: &j.read}, opts, js.settings())
+}
+
+// notify posts the status of a finished job to its webhook
+func (js *jobs) notify(j *job) error {
+	if js.opts.Client == nil {
+		return fmt.Errorf("no HTTP client configured")
+	}
+	body, err := json.Marshal(j.snapshot())
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(js.ctx, time.Minute)
+	defer cancel()
+	// The host may resolve differently since the job was submitted, and when the request
+	// goes through a proxy the dialer of the client only sees the proxy
+	if err := js.opts.Webhooks.Check(ctx, j.webhook); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, j.webhook, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := js.opts.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n *atomic.Int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n.Add(int64(n))
+	return n, err
+}
+
+// validWebhook checks that a webhook is an absolute HTTP(S) URL whose host policy allows
+func validWebhook(ctx context.Context, webhook string, policy *WebhookPolicy) error {
+	u, err := url.Parse(webhook)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid webhook %q: must be an http or https URL", webhook)
+	}
+	if err := policy.Check(ctx, webhook); err != nil {
+		return fmt.Errorf("invalid webhook %q: %w", webhook, err)
+	}
+	return nil
+}
+
+func (s *Server) handleJobs(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	sub, status, err := readSubmission(r)
+	if err == nil && sub.webhook != "" {
+		status, err = http.StatusBadRequest, validWebhook(r.Context(), sub.webhook, s.opts.Webhooks)
+	}
+	if err != nil {
+		writeError(w, status, err)
+		return
+	}
+	j, status, err := s.jobs.submit(clientOf(r), sub)
+	if err != nil {
+		writeError(w, status, err)
+		return
+	}
+	w.Header().Set("Location", "/v1/jobs/"+j.id)
+	writeJSON(w, http.StatusAccepted, j.snapshot())
+}
+
+func (s *Server) handleJob(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/v1/jobs/")
+	j := s.jobs.get(clientOf(r).name, id)
+	if j == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("job %q not found", id))
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		offset, limit, err := pageParams(r.URL.Query(), j.opts)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, j.page(offset, limit))
+	case http.MethodDelete:
+		s.jobs.remove(j)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, DELETE")
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	}
+}
+
+// pageParams returns the page of a job's findings selected by the offset and limit query
+// parameters, which default to the options of the job
+func pageParams(query url.Values, opts api.Options) (offset, limit int, err error) {
+	offset, limit = opts.Offset, opts.Limit
+	for _, p := range []struct {
+		name string
+		v    *int
+	}{{"offset", &offset}, {"limit", &limit}} {
+		value := query.Get(p.name)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return 0, 0, fmt.Errorf("invalid %s %q: must be a non-negative integer", p.name, value)
+		}
+		*p.v = n
+	}
+	return offset, limit, nil
+}
@@ -0,0 +1,209 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/httpclient"
+)
+
+func TestServer_Jobs(t *testing.T) {
+	notified := make(chan JobStatus, 1)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var st JobStatus
+		if err := json.NewDecoder(r.Body).Decode(&st); err != nil {
+			t.Error(err)
+		}
+		notified <- st
+	}))
+	defer hook.Close()
+	// The hook listens on loopback, which webhooks only reach when allowed
+	webhooks, err := NewWebhookPolicy([]string{"127.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := httpclient.New(httpclient.Options{Timeout: 5 * time.Second, MaxRedirects: -1, DialContext: webhooks.DialContext})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	srv := New(Options{JobDir: dir, Client: client, Webhooks: webhooks})
+	defer srv.Close()
+
+	do := func(method, target, contentType, body, from string, v interface{}) *httptest.ResponseRecorder {
+		t.Helper()
+		r := httptest.NewRequest(method, target, strings.NewReader(body))
+		r.Header.Set("Content-Type", contentType)
+		r.RemoteAddr = from + ":1234"
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, r)
+		if v != nil {
+			if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+				t.Fatalf("%s %s = %d %s: %v", method, target, w.Code, w.Body.String(), err)
+			}
+		}
+		return w
+	}
+	poll := func(id, from string) JobStatus {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			var st JobStatus
+			if w := do(http.MethodGet, "/v1/jobs/"+id, "", "", from, &st); w.Code != http.StatusOK {
+				t.Fatalf("GET /v1/jobs/%s = %d %s", id, w.Code, w.Body.String())
+			}
+			if st.Status != JobQueued && st.Status != JobRunning || time.Now().After(deadline) {
+				return st
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	// A JSON submission with a webhook
+	var st JobStatus
+	body := `{"text": "admin@target.com\nops@target.com\n", "options": {"emails": true}, "webhook": "` + hook.URL + `"}`
+	w := do(http.MethodPost, "/v1/jobs", "application/json", body, "10.0.0.1", &st)
+	if w.Code != http.StatusAccepted || w.Header().Get("Location") != "/v1/jobs/"+st.ID || len(st.ID) != 32 {
+		t.Fatalf("POST /v1/jobs = %d %s, Location %q", w.Code, w.Body.String(), w.Header().Get("Location"))
+	}
+	st = poll(st.ID, "10.0.0.1")
+	if st.Status != JobDone || st.Finished == nil || st.Result == nil || st.Result.Counts[finding.TypeEmail] != 2 || st.Progress != (Progress{Read: 32, Total: 32}) {
+		t.Errorf("finished job = %+v", st)
+	}
+	select {
+	case n := <-notified:
+		if n.ID != st.ID || n.Status != JobDone || len(n.Result.Findings) != 2 {
+			t.Errorf("webhook got %+v", n)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("webhook not notified")
+	}
+
+	// The result of a job is polled a page at a time
+	var page JobStatus
+	if w := do(http.MethodGet, "/v1/jobs/"+st.ID+"?offset=1&limit=1", "", "", "10.0.0.1", &page); w.Code != http.StatusOK || page.Result == nil ||
+		!reflect.DeepEqual(page.Result.Findings, st.Result.Findings[1:]) || page.Result.Total != 2 || page.Result.Truncated != 0 {
+		t.Errorf("GET page of a job = %d %+v", w.Code, page.Result)
+	}
+	if w := do(http.MethodGet, "/v1/jobs/"+st.ID+"?limit=1", "", "", "10.0.0.1", &page); w.Code != http.StatusOK ||
+		!reflect.DeepEqual(page.Result.Findings, st.Result.Findings[:1]) || page.Result.Truncated != 1 {
+		t.Errorf("GET first page of a job = %d %+v", w.Code, page.Result)
+	}
+	var e struct{ Error string }
+	if w := do(http.MethodGet, "/v1/jobs/"+st.ID+"?limit=-1", "", "", "10.0.0.1", &e); w.Code != http.StatusBadRequest || !strings.HasPrefix(e.Error, "invalid limit") {
+		t.Errorf("GET with an invalid limit = %d %q", w.Code, e.Error)
+	}
+
+	// Jobs belong to the client that submitted them
+	if w := do(http.MethodGet, "/v1/jobs/"+st.ID, "", "", "10.0.0.2", nil); w.Code != http.StatusNotFound {
+		t.Errorf("GET of another client's job = %d", w.Code)
+	}
+	if w := do(http.MethodDelete, "/v1/jobs/"+st.ID, "", "", "10.0.0.1", nil); w.Code != http.StatusNoContent {
+		t.Errorf("DELETE = %d", w.Code)
+	}
+	if w := do(http.MethodGet, "/v1/jobs/"+st.ID, "", "", "10.0.0.1", nil); w.Code != http.StatusNotFound {
+		t.Errorf("GET of a deleted job = %d", w.Code)
+	}
+
+	// A raw text submission with the options in the query
+	target := "/v1/jobs?options=" + url.QueryEscape(`{"domains": true, "detail": "values"}`)
+	if w := do(http.MethodPost, target, "text/plain; charset=utf-8", "see https://api.target.com/v1\n", "10.0.0.1", &st); w.Code != http.StatusAccepted {
+		t.Fatalf("POST /v1/jobs with text = %d %s", w.Code, w.Body.String())
+	}
+	st = poll(st.ID, "10.0.0.1")
+	if st.Status != JobDone || !reflect.DeepEqual(st.Result.Findings, []finding.Finding{{Type: finding.TypeDomain, Value: "api.target.com"}}) {
+		t.Errorf("finished text job = %+v", st)
+	}
+
+	if w := do(http.MethodPost, "/v1/jobs", "application/json", `{"text": "x", "options": {"emails": true}, "webhook": "file:///etc/passwd"}`, "10.0.0.1", &e); w.Code != http.StatusBadRequest || !strings.HasPrefix(e.Error, "invalid webhook") {
+		t.Errorf("job with an invalid webhook = %d %q", w.Code, e.Error)
+	}
+	if w := do(http.MethodPost, "/v1/jobs", "application/json", `{"text": "x", "options": {"emails": true}, "webhook": "http://169.254.169.254/latest/meta-data/"}`, "10.0.0.1", &e); w.Code != http.StatusBadRequest || !strings.Contains(e.Error, "internal address") {
+		t.Errorf("job with a link-local webhook = %d %q", w.Code, e.Error)
+	}
+	if w := do(http.MethodPost, "/v1/extract", "application/json", `{"text": "x", "options": {"emails": true}, "webhook": "https://hooks.target.com/"}`, "10.0.0.1", &e); w.Code != http.StatusBadRequest || !strings.Contains(e.Error, "only sent for jobs") {
+		t.Errorf("extract with a webhook = %d %q", w.Code, e.Error)
+	}
+
+	// The spooled text of finished jobs is removed
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("job directory holds %d files", len(entries))
+	}
+}
+
+func TestServer_ClientJobLimit(t *testing.T) {
+	file := tokensFile{Tokens: []Token{
+		{Name: "dashboard", Token: "dash-token"},
+		{Name: "ci", Token: "ci-token", MaxJobs: 2},
+	}}
+	if err := file.validate(); err != nil {
+		t.Fatal(err)
+	}
+	srv := New(Options{Tokens: file.Tokens, MaxClientJobs: 1, JobDir: t.TempDir()})
+	defer srv.Close()
+	// Stop the workers, so that submitted jobs stay queued
+	srv.jobs.cancel()
+	srv.jobs.wg.Wait()
+
+	submit := func(token string) *httptest.ResponseRecorder {
+		t.Helper()
+		r := httptest.NewRequest(http.MethodPost, "/v1/jobs", strings.NewReader(`{"text": "x", "options": {"emails": true}}`))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, r)
+		return w
+	}
+	w := submit("dash-token")
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("first job = %d %s", w.Code, w.Body.String())
+	}
+	if w := submit("dash-token"); w.Code != http.StatusTooManyRequests || !strings.Contains(w.Body.String(), "1 jobs already queued or running") {
+		t.Errorf("job over the client's limit = %d %s", w.Code, w.Body.String())
+	}
+	// Other clients are not affected, and a token can set its own limit
+	for i := 0; i < 2; i++ {
+		if w := submit("ci-token"); w.Code != http.StatusAccepted {
+			t.Errorf("job %d of another client = %d %s", i, w.Code, w.Body.String())
+		}
+	}
+	if w := submit("ci-token"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("job over the token's limit = %d %s", w.Code, w.Body.String())
+	}
+
+	// Canceling a job frees its place
+	r := httptest.NewRequest(http.MethodDelete, w.Header().Get("Location"), nil)
+	r.Header.Set("Authorization", "Bearer dash-token")
+	srv.ServeHTTP(httptest.NewRecorder(), r)
+	if w := submit("dash-token"); w.Code != http.StatusAccepted {
+		t.Errorf("job after canceling one = %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestServer_JobLimits(t *testing.T) {
+	srv := New(Options{MaxBody: 16, JobTTL: time.Millisecond})
+	defer srv.Close()
+	r := httptest.NewRequest(http.MethodPost, "/v1/jobs?options=%7B%22emails%22%3Atrue%7D", strings.NewReader(strings.Repeat("a", 32)))
+	r.Header.Set("Content-Type", "application/octet-stream")
+	r.ContentLength = -1
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, r)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("job over the size limit = %d %s", w.Code, w.Body.String())
+	}
+
+	// Finished jobs are forgotten after JobTTL
+	j := &job{id: "old", client: "192.0.2.1", status: JobDone, finished: time.Now().Add(-time.Second)}
+	srv.jobs.byID[j.id] = j
+	if srv.jobs.get("192.0.2.1", "old") != nil {
+		t.Error("expired job was found")
+	}
+}
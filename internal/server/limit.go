@@ -0,0 +1,67 @@
+package server
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/PeteJStewart/urlsluice/internal/httpclient"
+)
+
+// maxIdleBuckets is the number of clients above which the buckets of clients that have not
+// used their rate for a while are dropped
+const maxIdleBuckets = 10000
+
+// limiter enforces a request rate for each client with token buckets. Unlike the limiter of
+// the HTTP client, which makes requests wait, it rejects requests over the rate.
+type limiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// bucket holds up to one interval's worth of requests of a client
+type bucket struct {
+	rate   httpclient.Rate
+	tokens float64
+	last   time.Time
+}
+
+func newLimiter() *limiter {
+	return &limiter{buckets: make(map[string]*bucket)}
+}
+
+// allow takes a request of client from its bucket. When the bucket is empty the request is
+// not counted, and allow returns how long until it would be allowed.
+func (l *limiter) allow(client string, rate httpclient.Rate, now time.Time) (bool, time.Duration) {
+	if rate.Requests <= 0 || rate.Per <= 0 {
+		return true, 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	perSec := float64(rate.Requests) / rate.Per.Seconds()
+	b, ok := l.buckets[client]
+	if !ok || b.rate != rate {
+		// A client whose rate changed on reload starts with a full bucket at the new rate
+		if len(l.buckets) >= maxIdleBuckets {
+			l.prune(now)
+		}
+		b = &bucket{rate: rate, tokens: float64(rate.Requests), last: now}
+		l.buckets[client] = b
+	}
+	b.tokens = math.Min(float64(rate.Requests), b.tokens+now.Sub(b.last).Seconds()*perSec)
+	b.last = now
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / perSec * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// prune drops the buckets that have refilled completely, which behave like new ones
+func (l *limiter) prune(now time.Time) {
+	for client, b := range l.buckets {
+		if now.Sub(b.last) >= b.rate.Per {
+			delete(l.buckets, client)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/PeteJStewart/urlsluice/internal/httpclient"
+)
+
+func TestLimiter_Allow(t *testing.T) {
+	l := newLimiter()
+	rate := httpclient.Rate{Requests: 2, Per: time.Second}
+	now := time.Date(2026, 5, 6, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.allow("a", rate, now); !ok {
+			t.Fatalf("request %d of a burst within the rate was rejected", i+1)
+		}
+	}
+	if ok, wait := l.allow("a", rate, now); ok || wait != 500*time.Millisecond {
+		t.Errorf("request over the rate = %v, %v, want rejected for 500ms", ok, wait)
+	}
+	// Rejected requests are not counted against the client
+	if ok, _ := l.allow("a", rate, now.Add(500*time.Millisecond)); !ok {
+		t.Error("request after the bucket refilled was rejected")
+	}
+	if ok, _ := l.allow("b", rate, now); !ok {
+		t.Error("another client was limited by the first one")
+	}
+	if ok, _ := l.allow("a", httpclient.Rate{}, now); !ok {
+		t.Error("request without a rate was rejected")
+	}
+}
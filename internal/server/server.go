@@ -0,0 +1,370 @@
+// Package server is the HTTP API of "urlsluice serve". Clients post text with api.Options
+// selecting the extractors, scope and detail level of each request, so one server instance
+// can serve callers with different needs.
+//
+// Endpoints:
+//
+//	POST   /v1/extract    {"text": "...", "options": {"emails": true}} -> api.Result
+//	POST   /v1/jobs       the same, plus an optional "webhook" -> 202 JobStatus
+//	GET    /v1/jobs/{id}  JobStatus, with the result once the job is done, paged by the
+//	                      offset and limit query parameters
+//	DELETE /v1/jobs/{id}  cancels and forgets a job
+//	GET    /healthz       {"status": "ok"}
+//
+// Jobs are processed in the background, for payloads too large to extract while the client
+// waits behind a proxy. Their status is polled or posted to the webhook when they finish.
+//
+// Errors are returned as {"error": "..."} with a 4xx or 5xx status.
+//
+// With tokens, every endpoint but /healthz requires an "Authorization: Bearer <token>"
+// header. Each client, identified by its token or else by its IP address, has its own
+// request rate, maximum request size and number of jobs, so one client cannot starve the
+// others.
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PeteJStewart/urlsluice/internal/api"
+	"github.com/PeteJStewart/urlsluice/internal/httpclient"
+)
+
+// Options configures a Server
+type Options struct {
+	// Tokens are the API tokens accepted; without tokens no authentication is required
+	Tokens []Token
+	// Rate limits the requests of each client whose token does not set its own rate; the
+	// zero value means no limit
+	Rate httpclient.Rate
+	// MaxBody limits the size of request bodies of clients whose token does not set its own
+	// limit (0 means no limit)
+	MaxBody int64
+	// Settings are applied to every request, on top of its options
+	Settings *api.Settings
+
+	// Workers is the number of jobs processed at the same time (default 2)
+	Workers int
+	// MaxQueuedJobs is the number of jobs that may wait for a worker (default 100)
+	MaxQueuedJobs int
+	// MaxClientJobs is the number of jobs each client whose token does not set its own limit
+	// may have queued or running at once (default 10)
+	MaxClientJobs int
+	// JobTTL is how long finished jobs can be polled (default 1h)
+	JobTTL time.Duration
+	// JobDir holds the text of jobs while they wait and run (default the system temporary
+	// directory)
+	JobDir string
+	// Client posts to job webhooks; without it, jobs with a webhook fail to notify it. It
+	// should not follow redirects and should connect with Webhooks.DialContext, so that
+	// webhooks cannot reach addresses the policy refuses.
+	Client *httpclient.Client
+	// Webhooks restricts the addresses job webhooks are sent to (default a policy refusing
+	// every internal address)
+	Webhooks *WebhookPolicy
+}
+
+// Defaults of Options
+const (
+	defaultWorkers       = 2
+	defaultMaxQueuedJobs = 100
+	defaultMaxClientJobs = 10
+	defaultJobTTL        = time.Hour
+)
+
+// Server handles API requests
+type Server struct {
+	mux     *http.ServeMux
+	opts    Options
+	auth    bool
+	limiter *limiter
+	jobs    *jobs
+
+	mu       sync.RWMutex
+	tokens   []Token
+	settings *api.Settings
+}
+
+// New returns a Server, starting the workers of its jobs. Call Close to stop them.
+func New(opts Options) *Server {
+	if opts.Workers <= 0 {
+		opts.Workers = defaultWorkers
+	}
+	if opts.MaxQueuedJobs <= 0 {
+		opts.MaxQueuedJobs = defaultMaxQueuedJobs
+	}
+	if opts.MaxClientJobs <= 0 {
+		opts.MaxClientJobs = defaultMaxClientJobs
+	}
+	if opts.JobTTL <= 0 {
+		opts.JobTTL = defaultJobTTL
+	}
+	if opts.Webhooks == nil {
+		opts.Webhooks, _ = NewWebhookPolicy(nil)
+	}
+	s := &Server{
+		mux:      http.NewServeMux(),
+		opts:     opts,
+		auth:     len(opts.Tokens) > 0,
+		limiter:  newLimiter(),
+		tokens:   opts.Tokens,
+		settings: opts.Settings,
+	}
+	s.jobs = newJobs(opts, s.currentSettings)
+	s.mux.HandleFunc("/v1/extract", s.handleExtract)
+	s.mux.HandleFunc("/v1/jobs", s.handleJobs)
+	s.mux.HandleFunc("/v1/jobs/", s.handleJob)
+	s.mux.HandleFunc("/healthz", s.handleHealth)
+	return s
+}
+
+// Close cancels the jobs that are queued or running and waits for the workers to stop
+func (s *Server) Close() {
+	s.jobs.close()
+}
+
+// SetTokens replaces the accepted tokens, such as after the tokens file changed. Requests in
+// progress are not affected.
+func (s *Server) SetTokens(tokens []Token) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens = tokens
+}
+
+// SetSettings replaces the settings applied to every request, such as after the files they
+// were loaded from changed. Requests and jobs in progress keep the settings they started
+// with.
+func (s *Server) SetSettings(settings *api.Settings) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.settings = settings
+}
+
+// currentSettings returns the settings applied to requests starting now
+func (s *Server) currentSettings() *api.Settings {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.settings
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/healthz" {
+		s.mux.ServeHTTP(w, r)
+		return
+	}
+	client, ok := s.authenticate(r)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="urlsluice"`)
+		writeError(w, http.StatusUnauthorized, fmt.Errorf("a valid bearer token is required"))
+		return
+	}
+	if allowed, wait := s.limiter.allow(client.name, client.rate, time.Now()); !allowed {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		writeError(w, http.StatusTooManyRequests, fmt.Errorf("rate limit of %s exceeded", client.rate))
+		return
+	}
+	if client.maxBody > 0 {
+		if r.ContentLength > client.maxBody {
+			writeError(w, http.StatusRequestEntityTooLarge, bodyTooLarge(client.maxBody))
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, client.maxBody)
+	}
+	s.mux.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientKey{}, client)))
+}
+
+// clientKey is the context key of the client of a request
+type clientKey struct{}
+
+// clientOf returns the client of r, which owns the jobs it submits
+func clientOf(r *http.Request) client {
+	c, _ := r.Context().Value(clientKey{}).(client)
+	return c
+}
+
+// client is the caller of a request and its limits
+type client struct {
+	name    string
+	rate    httpclient.Rate
+	maxBody int64
+	maxJobs int
+}
+
+// authenticate identifies the client of r: the owner of its bearer token when tokens are
+// configured, or else its IP address
+func (s *Server) authenticate(r *http.Request) (client, bool) {
+	c := client{rate: s.opts.Rate, maxBody: s.opts.MaxBody, maxJobs: s.opts.MaxClientJobs}
+	if !s.auth {
+		c.name, _, _ = net.SplitHostPort(r.RemoteAddr)
+		return c, true
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return c, false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for i := range s.tokens {
+		t := &s.tokens[i]
+		if !t.matches(token) {
+			continue
+		}
+		c.name = "token:" + t.Name
+		if t.rate != nil {
+			c.rate = *t.rate
+		}
+		if t.maxBody != nil {
+			c.maxBody = *t.maxBody
+		}
+		if t.MaxJobs > 0 {
+			c.maxJobs = t.MaxJobs
+		}
+		return c, true
+	}
+	return c, false
+}
+
+// bodyTooLarge is the error of a request body over the limit of its client
+func bodyTooLarge(limit int64) error {
+	return fmt.Errorf("request body exceeds the limit of %d bytes", limit)
+}
+
+// extractRequest is the JSON body of POST /v1/extract and POST /v1/jobs
+type extractRequest struct {
+	Text    string          `json:"text"`
+	Options json.RawMessage `json:"options"`
+	// Webhook is only accepted by POST /v1/jobs
+	Webhook string `json:"webhook"`
+}
+
+// submission is the text and options of a request
+type submission struct {
+	text    io.Reader
+	opts    api.Options
+	webhook string
+}
+
+// readSubmission reads the text and options of r. A JSON body is an extractRequest; with a
+// text/plain or application/octet-stream body, the body is the text itself, streamed
+// rather than decoded, and the options and webhook are given by the query parameters of the
+// same names. Errors come with the status to answer them with.
+func readSubmission(r *http.Request) (*submission, int, error) {
+	var sub submission
+	var options []byte
+	switch mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType {
+	case "text/plain", "application/octet-stream":
+		query := r.URL.Query()
+		sub.text = r.Body
+		options = []byte(query.Get("options"))
+		sub.webhook = query.Get("webhook")
+	default:
+		var req extractRequest
+		if err := decodeBody(r, &req); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				return nil, http.StatusRequestEntityTooLarge, bodyTooLarge(tooLarge.Limit)
+			}
+			return nil, http.StatusBadRequest, fmt.Errorf("invalid request: %w", err)
+		}
+		sub.text = strings.NewReader(req.Text)
+		options = req.Options
+		sub.webhook = req.Webhook
+	}
+	var err error
+	if sub.opts, err = api.DecodeOptions(options); err == nil {
+		err = sub.opts.Validate()
+	}
+	if err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("invalid options: %w", err)
+	}
+	return &sub, http.StatusOK, nil
+}
+
+func (s *Server) handleExtract(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	sub, status, err := readSubmission(r)
+	if err != nil {
+		writeError(w, status, err)
+		return
+	}
+	if sub.webhook != "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request: webhooks are only sent for jobs, see /v1/jobs"))
+		return
+	}
+	result, err := api.ExtractWith(r.Context(), sub.text, sub.opts, s.currentSettings())
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, bodyTooLarge(tooLarge.Limit))
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// decodeBody decodes the JSON object in the body of r into v, rejecting unknown fields and
+// anything after the object
+func decodeBody(r *http.Request, v interface{}) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		if err == nil {
+			err = errors.New("unexpected data after the JSON object")
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// allowMethod reports whether r uses method, answering 405 Method Not Allowed if it does not
+func allowMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method == method {
+		return true
+	}
+	w.Header().Set("Allow", method)
+	writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	return false
+}
+
+// writeJSON writes v as the JSON body of a response with status
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		http.Error(w, `{"error": "encoding the response failed"}`, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
+
+// writeError writes err as an {"error": "..."} response with status
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/PeteJStewart/urlsluice/internal/api"
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+func TestServer_Extract(t *testing.T) {
+	srv := httptest.NewServer(New(Options{}))
+	defer srv.Close()
+
+	post := func(body string, v interface{}) int {
+		t.Helper()
+		resp, err := http.Post(srv.URL+"/v1/extract", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode
+	}
+
+	// Two requests with different options are served by the same instance
+	var result api.Result
+	status := post(`{"text": "admin@target.com https://api.target.com/ https://cdn.partner.com/", "options": {"domains": true, "scope": ["*.target.com"]}}`, &result)
+	if status != http.StatusOK || len(result.Findings) != 1 || result.Findings[0].Value != "api.target.com" {
+		t.Errorf("extract with a scope = %d %+v", status, result)
+	}
+	result = api.Result{}
+	status = post(`{"text": "admin@target.com ops@target.com", "options": {"emails": true, "detail": "counts"}}`, &result)
+	if status != http.StatusOK || result.Findings != nil || result.Counts[finding.TypeEmail] != 2 {
+		t.Errorf("extract with counts detail = %d %+v", status, result)
+	}
+
+	// Large results are paged with offset and limit
+	result = api.Result{}
+	status = post(`{"text": "a@target.com b@target.com c@target.com", "options": {"emails": true, "offset": 1, "limit": 1}}`, &result)
+	if status != http.StatusOK || len(result.Findings) != 1 || result.Findings[0].Value != "b@target.com" || result.Total != 3 || result.Truncated != 1 {
+		t.Errorf("extract with offset and limit = %d %+v", status, result)
+	}
+
+	tests := map[string]string{
+		`{"text": "x", "options": {"limit": -1, "emails": true}}`: "invalid options: limit: must not be negative",
+		`{"text": "x", "options": {"emial": true}}`:               `invalid options: json: unknown field "emial", did you mean "emails"?`,
+		`{"text": "x", "options": {}}`:                            "invalid options: no extractors enabled",
+		`{"txt": "x"}`:                                            `invalid request: json: unknown field "txt"`,
+		`not json`:                                                "invalid request: ",
+		`{"text": "x"} {"text": "y"}`:                             "invalid request: unexpected data after the JSON object",
+	}
+	for body, want := range tests {
+		var got struct{ Error string }
+		if status := post(body, &got); status != http.StatusBadRequest || !strings.HasPrefix(got.Error, want) {
+			t.Errorf("extract %s = %d %q, want 400 %q", body, status, got.Error, want)
+		}
+	}
+
+	resp, err := http.Get(srv.URL + "/v1/extract")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed || resp.Header.Get("Allow") != http.MethodPost {
+		t.Errorf("GET /v1/extract = %d, Allow %q", resp.StatusCode, resp.Header.Get("Allow"))
+	}
+}
+
+func TestServer_Health(t *testing.T) {
+	w := httptest.NewRecorder()
+	New(Options{}).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != `{"status":"ok"}` {
+		t.Errorf("GET /healthz = %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestServer_Limits(t *testing.T) {
+	tokens := []Token{
+		{Name: "dashboard", Token: "dash-token", Rate: "1/h"},
+		{Name: "ci", Token: "ci-token", MaxBody: "64"},
+	}
+	file := tokensFile{Tokens: tokens}
+	if err := file.validate(); err != nil {
+		t.Fatal(err)
+	}
+	srv := New(Options{Tokens: file.Tokens, MaxBody: 1 << 20})
+
+	request := func(token, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/v1/extract", strings.NewReader(body))
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, r)
+		return w
+	}
+	body := `{"text": "admin@target.com", "options": {"emails": true}}`
+
+	if w := request("", body); w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") == "" {
+		t.Errorf("request without a token = %d %s", w.Code, w.Body.String())
+	}
+	if w := request("guess", body); w.Code != http.StatusUnauthorized {
+		t.Errorf("request with an unknown token = %d", w.Code)
+	}
+	if w := request("dash-token", body); w.Code != http.StatusOK {
+		t.Errorf("first dashboard request = %d %s", w.Code, w.Body.String())
+	}
+	if w := request("dash-token", body); w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "3600" {
+		t.Errorf("second dashboard request = %d, Retry-After %q", w.Code, w.Header().Get("Retry-After"))
+	}
+	// The rate of one client does not hold up the others, but their own size limit does
+	if w := request("ci-token", `{"text": "x", "options": {"emails": true}}`); w.Code != http.StatusOK {
+		t.Errorf("small ci request = %d %s", w.Code, w.Body.String())
+	}
+	if w := request("ci-token", body+strings.Repeat(" ", 64)); w.Code != http.StatusRequestEntityTooLarge || !strings.Contains(w.Body.String(), "limit of 64 bytes") {
+		t.Errorf("large ci request = %d %s", w.Code, w.Body.String())
+	}
+
+	// Bodies without a length are cut off while they are read
+	r := httptest.NewRequest(http.MethodPost, "/v1/extract", io.MultiReader(strings.NewReader(body), strings.NewReader(strings.Repeat(" ", 64))))
+	r.ContentLength = -1
+	r.Header.Set("Authorization", "Bearer ci-token")
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, r)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("large ci request without a length = %d %s", w.Code, w.Body.String())
+	}
+
+	srv.SetTokens(file.Tokens[1:])
+	if w := request("dash-token", body); w.Code != http.StatusUnauthorized {
+		t.Errorf("request with a removed token = %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("GET /healthz without a token = %d", w.Code)
+	}
+}
@@ -0,0 +1,168 @@
+package server
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/PeteJStewart/urlsluice/internal/httpclient"
+	"github.com/PeteJStewart/urlsluice/internal/yamlcheck"
+)
+
+// Token is an API token and the limits of the client using it
+type Token struct {
+	// Name identifies the client in logs and errors; it is not a secret
+	Name string `yaml:"name"`
+	// Token is the bearer token itself
+	Token string `yaml:"token"`
+	// SHA256 is the hex SHA-256 digest of the token, so the file need not hold the token
+	SHA256 string `yaml:"sha256"`
+	// Rate limits the requests of the client, such as "60/m", overriding Options.Rate
+	Rate string `yaml:"rate"`
+	// MaxBody limits the size of the client's request bodies, such as "100MB", overriding
+	// Options.MaxBody
+	MaxBody string `yaml:"max_body"`
+	// MaxJobs limits the jobs the client may have queued or running at once, overriding
+	// Options.MaxClientJobs
+	MaxJobs int `yaml:"max_jobs"`
+
+	digest  [sha256.Size]byte
+	rate    *httpclient.Rate
+	maxBody *int64
+}
+
+// tokensFile is the file read by LoadTokens
+type tokensFile struct {
+	Tokens []Token `yaml:"tokens"`
+}
+
+// tokenNameRegex restricts token names to characters that are safe in logs
+var tokenNameRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.@-]*$`)
+
+// LoadTokens reads and validates a tokens file:
+//
+//	tokens:
+//	  - name: dashboard
+//	    sha256: 9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08
+//	    rate: 600/m
+//	    max_body: 100MB
+//	    max_jobs: 20
+//	  - name: ci
+//	    token: s3cr3t
+func LoadTokens(path string) ([]Token, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file tokensFile
+	root, err := yamlcheck.Decode(data, &file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := file.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, yamlcheck.Locate(root, err))
+	}
+	return file.Tokens, nil
+}
+
+func (f *tokensFile) validate() error {
+	if len(f.Tokens) == 0 {
+		return fmt.Errorf("no tokens configured")
+	}
+	names := make(map[string]bool)
+	digests := make(map[[sha256.Size]byte]bool)
+	for i := range f.Tokens {
+		t := &f.Tokens[i]
+		if !tokenNameRegex.MatchString(t.Name) {
+			return fmt.Errorf("tokens[%d]: name %q must be letters, digits, '.', '_', '@' or '-'", i, t.Name)
+		}
+		if names[t.Name] {
+			return fmt.Errorf("tokens[%d]: duplicate name %q", i, t.Name)
+		}
+		names[t.Name] = true
+		switch {
+		case t.Token != "" && t.SHA256 != "":
+			return fmt.Errorf("tokens[%d]: token and sha256 cannot both be set", i)
+		case t.Token != "":
+			t.digest = sha256.Sum256([]byte(t.Token))
+		case t.SHA256 != "":
+			digest, err := hex.DecodeString(t.SHA256)
+			if err != nil || len(digest) != sha256.Size {
+				return fmt.Errorf("tokens[%d]: sha256 must be 64 hexadecimal digits", i)
+			}
+			copy(t.digest[:], digest)
+		default:
+			return fmt.Errorf("tokens[%d]: token or sha256 is required", i)
+		}
+		if digests[t.digest] {
+			return fmt.Errorf("tokens[%d]: the token of %s is used by another client", i, t.Name)
+		}
+		digests[t.digest] = true
+		if t.Rate != "" {
+			rate, err := httpclient.ParseRate(t.Rate)
+			if err != nil {
+				return fmt.Errorf("tokens[%d]: %w", i, err)
+			}
+			t.rate = &rate
+		}
+		if t.MaxBody != "" {
+			n, err := httpclient.ParseSize(t.MaxBody)
+			if err != nil {
+				return fmt.Errorf("tokens[%d]: %w", i, err)
+			}
+			t.maxBody = &n
+		}
+		if t.MaxJobs < 0 {
+			return fmt.Errorf("tokens[%d]: max_jobs must not be negative", i)
+		}
+	}
+	return nil
+}
+
+// DiffTokens describes the differences between two sets of tokens, one line per change, such
+// as "token ci added". Tokens themselves are never included.
+func DiffTokens(old, new []Token) []string {
+	var changes []string
+	prev := make(map[string]*Token, len(old))
+	for i := range old {
+		prev[old[i].Name] = &old[i]
+	}
+	names := make(map[string]bool, len(new))
+	for i := range new {
+		t := &new[i]
+		names[t.Name] = true
+		p, ok := prev[t.Name]
+		switch {
+		case !ok:
+			changes = append(changes, fmt.Sprintf("token %s added", t.Name))
+			continue
+		case p.digest != t.digest:
+			changes = append(changes, fmt.Sprintf("token %s: token changed", t.Name))
+		}
+		if p.Rate != t.Rate {
+			changes = append(changes, fmt.Sprintf("token %s: rate changed from %q to %q", t.Name, p.Rate, t.Rate))
+		}
+		if p.MaxBody != t.MaxBody {
+			changes = append(changes, fmt.Sprintf("token %s: max_body changed from %q to %q", t.Name, p.MaxBody, t.MaxBody))
+		}
+		if p.MaxJobs != t.MaxJobs {
+			changes = append(changes, fmt.Sprintf("token %s: max_jobs changed from %d to %d", t.Name, p.MaxJobs, t.MaxJobs))
+		}
+	}
+	for _, t := range old {
+		if !names[t.Name] {
+			changes = append(changes, fmt.Sprintf("token %s removed", t.Name))
+		}
+	}
+	return changes
+}
+
+// matches reports whether token is this client's token, taking the same time for every
+// token of the same length
+func (t *Token) matches(token string) bool {
+	digest := sha256.Sum256([]byte(token))
+	return subtle.ConstantTimeCompare(digest[:], t.digest[:]) == 1
+}
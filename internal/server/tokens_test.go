@@ -0,0 +1,88 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PeteJStewart/urlsluice/internal/httpclient"
+)
+
+func TestLoadTokens(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.yaml")
+	data := `tokens:
+  - name: dashboard
+    sha256: 9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08
+    rate: 600/m
+    max_body: 100MB
+  - name: ci
+    token: s3cr3t
+`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tokens, err := LoadTokens(path)
+	if err != nil {
+		t.Fatalf("LoadTokens() error = %v", err)
+	}
+	if len(tokens) != 2 || !tokens[0].matches("test") || tokens[0].matches("s3cr3t") || !tokens[1].matches("s3cr3t") {
+		t.Errorf("LoadTokens() tokens do not match their secrets: %+v", tokens)
+	}
+	if *tokens[0].rate != (httpclient.Rate{Requests: 600, Per: time.Minute}) || *tokens[0].maxBody != 100<<20 {
+		t.Errorf("dashboard limits = %v, %d", *tokens[0].rate, *tokens[0].maxBody)
+	}
+	if tokens[1].rate != nil || tokens[1].maxBody != nil {
+		t.Errorf("ci limits = %v, %v, want the defaults", tokens[1].rate, tokens[1].maxBody)
+	}
+}
+
+func TestLoadTokens_Errors(t *testing.T) {
+	tests := map[string]string{
+		"tokens: []\n":                                                    "no tokens configured",
+		"tokens:\n  - name: a\n    tokn: x\n":                             `unknown key "tokn" in tokens[0], did you mean "token"?`,
+		"tokens:\n  - name: a b\n    token: x\n":                          `line 2, column 5: tokens[0]: name "a b" must be`,
+		"tokens:\n  - name: a\n    token: x\n  - name: a\n    token: y\n": `tokens[1]: duplicate name "a"`,
+		"tokens:\n  - name: a\n":                                          "tokens[0]: token or sha256 is required",
+		"tokens:\n  - name: a\n    token: x\n    sha256: ab\n":            "tokens[0]: token and sha256 cannot both be set",
+		"tokens:\n  - name: a\n    sha256: xyz\n":                         "tokens[0]: sha256 must be 64 hexadecimal digits",
+		"tokens:\n  - name: a\n    token: x\n  - name: b\n    token: x\n": "tokens[1]: the token of b is used by another client",
+		"tokens:\n  - name: a\n    token: x\n    rate: fast\n":            `tokens[0]: invalid rate "fast"`,
+		"tokens:\n  - name: a\n    token: x\n    max_body: huge\n":        `tokens[0]: invalid size "huge"`,
+		"tokens:\n  - name: a\n    token: x\n    max_jobs: -1\n":          "tokens[0]: max_jobs must not be negative",
+	}
+	for data, want := range tests {
+		path := filepath.Join(t.TempDir(), "tokens.yaml")
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadTokens(path); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadTokens(%q) error = %v, want %q", data, err, want)
+		}
+	}
+}
+
+func TestDiffTokens(t *testing.T) {
+	load := func(tokens ...Token) []Token {
+		t.Helper()
+		file := tokensFile{Tokens: tokens}
+		if err := file.validate(); err != nil {
+			t.Fatal(err)
+		}
+		return file.Tokens
+	}
+	old := load(Token{Name: "dashboard", Token: "a", Rate: "60/m"}, Token{Name: "ci", Token: "b"}, Token{Name: "old", Token: "c"})
+	new := load(Token{Name: "dashboard", Token: "a", Rate: "600/m"}, Token{Name: "ci", Token: "rotated", MaxBody: "1GB"}, Token{Name: "new", Token: "d"})
+	want := []string{
+		`token dashboard: rate changed from "60/m" to "600/m"`,
+		"token ci: token changed",
+		`token ci: max_body changed from "" to "1GB"`,
+		"token new added",
+		"token old removed",
+	}
+	if got := DiffTokens(old, new); !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffTokens() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+)
+
+// WebhookPolicy restricts the addresses job webhooks are sent to. Loopback, link-local,
+// private and unspecified addresses are refused unless their host or network is allowed, so
+// that API clients cannot make the server call the services of its own network, such as
+// cloud metadata endpoints.
+type WebhookPolicy struct {
+	hosts    map[string]bool
+	networks []netip.Prefix
+	// lookup resolves host names (default net.DefaultResolver)
+	lookup func(ctx context.Context, host string) ([]netip.Addr, error)
+}
+
+// NewWebhookPolicy returns a policy that also allows the hosts and networks in allow, such
+// as "hooks.internal", "127.0.0.1" or "10.1.0.0/16"
+func NewWebhookPolicy(allow []string) (*WebhookPolicy, error) {
+	p := &WebhookPolicy{hosts: make(map[string]bool)}
+	for _, a := range allow {
+		a = strings.TrimSpace(a)
+		if a == "" {
+			return nil, fmt.Errorf("empty webhook host")
+		}
+		if strings.Contains(a, "/") {
+			prefix, err := netip.ParsePrefix(a)
+			if err != nil {
+				return nil, fmt.Errorf("invalid webhook network %q: %w", a, err)
+			}
+			p.networks = append(p.networks, prefix.Masked())
+			continue
+		}
+		if addr, err := netip.ParseAddr(a); err == nil {
+			p.networks = append(p.networks, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		p.hosts[strings.ToLower(a)] = true
+	}
+	return p, nil
+}
+
+// Check returns an error unless every address the host of webhook resolves to is allowed
+func (p *WebhookPolicy) Check(ctx context.Context, webhook string) error {
+	u, err := url.Parse(webhook)
+	if err != nil {
+		return err
+	}
+	_, err = p.resolve(ctx, u.Hostname())
+	return err
+}
+
+// DialContext connects to addr like a net.Dialer, refusing hosts that resolve to an address
+// that is not allowed. Checking again when connecting stops hosts that resolved to a public
+// address when a job was submitted and resolve to an internal one when its webhook is sent.
+func (p *WebhookPolicy) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := p.resolve(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	var d net.Dialer
+	var errs []error
+	for _, a := range addrs {
+		conn, err := d.DialContext(ctx, network, net.JoinHostPort(a.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		errs = append(errs, err)
+	}
+	return nil, errors.Join(errs...)
+}
+
+// resolve returns the addresses of host, failing if any of them is not allowed
+func (p *WebhookPolicy) resolve(ctx context.Context, host string) ([]netip.Addr, error) {
+	var addrs []netip.Addr
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addrs = []netip.Addr{addr}
+	} else {
+		lookup := p.lookup
+		if lookup == nil {
+			lookup = func(ctx context.Context, host string) ([]netip.Addr, error) {
+				return net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+			}
+		}
+		if addrs, err = lookup(ctx, host); err != nil {
+			return nil, err
+		}
+	}
+	if p.hosts[strings.ToLower(host)] {
+		return addrs, nil
+	}
+	for _, addr := range addrs {
+		if !p.allowed(addr.Unmap()) {
+			return nil, fmt.Errorf("%s is an internal address, which webhooks are not sent to unless allowed", addr)
+		}
+	}
+	return addrs, nil
+}
+
+// allowed reports whether addr is public or in an allowed network
+func (p *WebhookPolicy) allowed(addr netip.Addr) bool {
+	for _, n := range p.networks {
+		if n.Contains(addr) {
+			return true
+		}
+	}
+	return !addr.IsLoopback() && !addr.IsLinkLocalUnicast() && !addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() && !addr.IsPrivate() && !addr.IsUnspecified()
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+func TestWebhookPolicy(t *testing.T) {
+	lookup := func(ctx context.Context, host string) ([]netip.Addr, error) {
+		switch host {
+		case "hooks.target.com":
+			return []netip.Addr{netip.MustParseAddr("203.0.113.10")}, nil
+		case "intranet.target.com":
+			return []netip.Addr{netip.MustParseAddr("203.0.113.11"), netip.MustParseAddr("10.0.0.7")}, nil
+		}
+		return nil, fmt.Errorf("no such host %s", host)
+	}
+	tests := []struct {
+		name    string
+		allow   []string
+		webhook string
+		wantErr bool
+	}{
+		{"public", nil, "https://hooks.target.com/done", false},
+		{"loopback", nil, "http://127.0.0.1:8080/", true},
+		{"IPv6 loopback", nil, "http://[::1]/", true},
+		{"IPv4-mapped loopback", nil, "http://[::ffff:127.0.0.1]/", true},
+		{"metadata", nil, "http://169.254.169.254/latest/meta-data/", true},
+		{"private", nil, "http://10.0.0.5/", true},
+		{"unspecified", nil, "http://0.0.0.0/", true},
+		{"one private address", nil, "https://intranet.target.com/", true},
+		{"unresolved", nil, "https://nowhere.target.com/", true},
+		{"allowed address", []string{"127.0.0.1"}, "http://127.0.0.1:8080/", false},
+		{"allowed network", []string{"10.0.0.0/8"}, "http://10.0.0.5/", false},
+		{"allowed host", []string{"Intranet.target.com"}, "https://intranet.target.com/", false},
+		{"other network", []string{"10.1.0.0/16"}, "http://10.0.0.5/", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewWebhookPolicy(tt.allow)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p.lookup = lookup
+			if err := p.Check(context.Background(), tt.webhook); (err != nil) != tt.wantErr {
+				t.Errorf("Check(%q) error = %v, wantErr %v", tt.webhook, err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := NewWebhookPolicy([]string{"10.0.0.0/33"}); err == nil {
+		t.Error("NewWebhookPolicy() accepted an invalid network")
+	}
+}
+
+func TestWebhookPolicy_DialContext(t *testing.T) {
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer hook.Close()
+	addr := strings.TrimPrefix(hook.URL, "http://")
+
+	p, _ := NewWebhookPolicy(nil)
+	if conn, err := p.DialContext(context.Background(), "tcp", addr); err == nil {
+		conn.Close()
+		t.Errorf("DialContext(%q) connected to loopback", addr)
+	}
+	p, _ = NewWebhookPolicy([]string{"127.0.0.0/8"})
+	conn, err := p.DialContext(context.Background(), "tcp", addr)
+	if err != nil {
+		t.Fatalf("DialContext(%q) error = %v", addr, err)
+	}
+	conn.Close()
+}
+
+func TestServer_DefaultWebhookPolicy(t *testing.T) {
+	srv := New(Options{})
+	defer srv.Close()
+	r := httptest.NewRequest(http.MethodPost, "/v1/jobs", strings.NewReader(`{"text": "x", "options": {"emails": true}, "webhook": "http://localhost:8080/"}`))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "internal address") {
+		t.Errorf("job with a localhost webhook = %d %s", w.Code, w.Body.String())
+	}
+}
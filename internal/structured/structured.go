@@ -0,0 +1,176 @@
+// Package structured walks YAML and JSON documents, such as Kubernetes manifests, Helm values
+// and application configs, and lists their scalar values with the path leading to each.
+package structured
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Value is one line of a scalar value in a structured document
+type Value struct {
+	// Path locates the value, e.g. "spec.template.spec.containers[0].image"
+	Path string
+	// Key is the mapping key the value belongs to. For the value of a "name"/"value" pair,
+	// as in Kubernetes env lists, it is the name. Lines of multi-line values, which usually
+	// hold embedded files rather than a single value, have no key.
+	Key string
+	// Text is the value, or one line of a multi-line value
+	Text string
+	// Line is the 1-based line number of Text in the input
+	Line int
+	// Resource identifies the Kubernetes object the value belongs to, e.g. "Deployment/api"
+	Resource string
+}
+
+// ErrNotStructured is returned by Walk when the input is not a YAML or JSON document
+// holding a mapping or sequence
+var ErrNotStructured = errors.New("not a structured document")
+
+// Walk returns the scalar values of every document in data in document order. Multi-line
+// values, such as config files embedded in a ConfigMap, are split into one Value per line.
+func Walk(data []byte) ([]Value, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	var values []Value
+	structured := false
+	for {
+		var doc yaml.Node
+		err := dec.Decode(&doc)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrNotStructured, err)
+		}
+		if len(doc.Content) == 0 {
+			continue
+		}
+		root := doc.Content[0]
+		if root.Kind != yaml.MappingNode && root.Kind != yaml.SequenceNode {
+			return nil, ErrNotStructured
+		}
+		structured = true
+		w := walker{resource: resource(root)}
+		w.walk(root, "", "")
+		values = append(values, w.values...)
+	}
+	if !structured {
+		return nil, ErrNotStructured
+	}
+	return values, nil
+}
+
+type walker struct {
+	resource string
+	values   []Value
+}
+
+func (w *walker) walk(n *yaml.Node, path, key string) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		name := envName(n)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i].Value, n.Content[i+1]
+			childKey := k
+			if name != "" && k == "value" {
+				childKey = name
+			}
+			w.walk(v, join(path, k), childKey)
+		}
+	case yaml.SequenceNode:
+		for i, item := range n.Content {
+			w.walk(item, path+"["+strconv.Itoa(i)+"]", key)
+		}
+	case yaml.AliasNode:
+		// Anchored values are reported where they are defined
+	case yaml.ScalarNode:
+		w.scalar(n, path, key)
+	}
+}
+
+func (w *walker) scalar(n *yaml.Node, path, key string) {
+	if n.Tag == "!!null" || n.Value == "" {
+		return
+	}
+	line := n.Line
+	if n.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		// Block scalars start on the line after their indicator
+		line++
+	}
+	lines := strings.Split(strings.TrimRight(n.Value, "\n"), "\n")
+	if len(lines) > 1 {
+		key = ""
+	}
+	for i, text := range lines {
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		w.values = append(w.values, Value{Path: path, Key: key, Text: text, Line: line + i, Resource: w.resource})
+	}
+}
+
+// envName returns the name of a mapping holding a "name" and a "value" scalar
+func envName(n *yaml.Node) string {
+	var name string
+	hasValue := false
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		switch n.Content[i].Value {
+		case "name":
+			if n.Content[i+1].Kind == yaml.ScalarNode {
+				name = n.Content[i+1].Value
+			}
+		case "value":
+			hasValue = true
+		}
+	}
+	if !hasValue {
+		return ""
+	}
+	return name
+}
+
+// resource returns "Kind/name" for a Kubernetes object, or "" for other documents
+func resource(root *yaml.Node) string {
+	kind := lookup(root, "kind")
+	name := lookup(lookupNode(root, "metadata"), "name")
+	if kind == "" || name == "" {
+		return ""
+	}
+	return kind + "/" + name
+}
+
+func lookupNode(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func lookup(n *yaml.Node, key string) string {
+	if v := lookupNode(n, key); v != nil && v.Kind == yaml.ScalarNode {
+		return v.Value
+	}
+	return ""
+}
+
+// join appends key to path, quoting keys that contain path syntax
+func join(path, key string) string {
+	if strings.ContainsAny(key, ".[]\" ") || key == "" {
+		return path + "[" + strconv.Quote(key) + "]"
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
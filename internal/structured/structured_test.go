@@ -0,0 +1,79 @@
+package structured
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	input := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  template:
+    spec:
+      containers:
+        - name: api
+          image: registry.corp.target.com:5000/team/api:1.4
+          env:
+            - name: DB_PASSWORD
+              value: hunter2
+            - name: EMPTY
+      volumes: ~
+---
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  application.properties: |
+    db.url=jdbc:postgresql://db.target.com/app
+
+    db.password=s3cret
+`
+	got, err := Walk([]byte(input))
+	if err != nil {
+		t.Fatalf("Walk() error = %v", err)
+	}
+
+	want := []Value{
+		{Path: "apiVersion", Key: "apiVersion", Text: "apps/v1", Line: 1, Resource: "Deployment/api"},
+		{Path: "kind", Key: "kind", Text: "Deployment", Line: 2, Resource: "Deployment/api"},
+		{Path: "metadata.name", Key: "name", Text: "api", Line: 4, Resource: "Deployment/api"},
+		{Path: "spec.template.spec.containers[0].name", Key: "name", Text: "api", Line: 9, Resource: "Deployment/api"},
+		{Path: "spec.template.spec.containers[0].image", Key: "image", Text: "registry.corp.target.com:5000/team/api:1.4", Line: 10, Resource: "Deployment/api"},
+		{Path: "spec.template.spec.containers[0].env[0].name", Key: "name", Text: "DB_PASSWORD", Line: 12, Resource: "Deployment/api"},
+		{Path: "spec.template.spec.containers[0].env[0].value", Key: "DB_PASSWORD", Text: "hunter2", Line: 13, Resource: "Deployment/api"},
+		{Path: "spec.template.spec.containers[0].env[1].name", Key: "name", Text: "EMPTY", Line: 14, Resource: "Deployment/api"},
+		{Path: "kind", Key: "kind", Text: "ConfigMap", Line: 17, Resource: "ConfigMap/settings"},
+		{Path: "metadata.name", Key: "name", Text: "settings", Line: 19, Resource: "ConfigMap/settings"},
+		{Path: `data["application.properties"]`, Text: "db.url=jdbc:postgresql://db.target.com/app", Line: 22, Resource: "ConfigMap/settings"},
+		{Path: `data["application.properties"]`, Text: "db.password=s3cret", Line: 24, Resource: "ConfigMap/settings"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Walk() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestWalk_JSON(t *testing.T) {
+	got, err := Walk([]byte(`{"services": [{"url": "https://api.target.com", "port": 443}]}`))
+	if err != nil {
+		t.Fatalf("Walk() error = %v", err)
+	}
+	want := []Value{
+		{Path: "services[0].url", Key: "url", Text: "https://api.target.com", Line: 1},
+		{Path: "services[0].port", Key: "port", Text: "443", Line: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Walk() = %+v, want %+v", got, want)
+	}
+}
+
+func TestWalk_NotStructured(t *testing.T) {
+	for _, input := range []string{"", "just some text\nhttps://target.com/", "key: [unclosed", "- a\n---\n: b: c"} {
+		if _, err := Walk([]byte(input)); !errors.Is(err, ErrNotStructured) {
+			t.Errorf("Walk(%q) error = %v, want ErrNotStructured", input, err)
+		}
+	}
+}
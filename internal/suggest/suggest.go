@@ -0,0 +1,47 @@
+// Package suggest finds the name a user most likely meant when they misspelled one, for
+// "did you mean" hints in error messages.
+package suggest
+
+import "strings"
+
+// Closest returns the candidate closest to name by edit distance, ignoring case, or "" when
+// none is close enough to be a plausible typo: at most one edit per three characters,
+// rounded up
+func Closest(name string, candidates []string) string {
+	name = strings.ToLower(name)
+	limit := (len(name) + 2) / 3
+	best, bestDistance := "", limit+1
+	for _, c := range candidates {
+		if d := distance(name, strings.ToLower(c)); d < bestDistance {
+			best, bestDistance = c, d
+		}
+	}
+	return best
+}
+
+// distance returns the Damerau-Levenshtein distance between a and b, counting swapped
+// neighbouring characters as a single edit
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	// rows[0] and rows[1] are the two previous rows of the distance matrix
+	rows := [3][]int{make([]int, len(rb)+1), make([]int, len(rb)+1), make([]int, len(rb)+1)}
+	for j := range rows[1] {
+		rows[1][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur := rows[2]
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(rows[1][j]+1, cur[j-1]+1, rows[1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], rows[0][j-2]+1)
+			}
+		}
+		rows[0], rows[1], rows[2] = rows[1], cur, rows[0]
+	}
+	return rows[1][len(rb)]
+}
@@ -0,0 +1,23 @@
+package suggest
+
+import "testing"
+
+func TestClosest(t *testing.T) {
+	candidates := []string{"tags", "file_types", "profiles", "tracking_params", "transforms", "plugins"}
+	tests := map[string]string{
+		"tgas":           "tags",
+		"tag":            "tags",
+		"Profile":        "profiles",
+		"file-types":     "file_types",
+		"tracking_param": "tracking_params",
+		"transform":      "transforms",
+		"scope":          "",
+		"x":              "",
+		"":               "",
+	}
+	for name, want := range tests {
+		if got := Closest(name, candidates); got != want {
+			t.Errorf("Closest(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
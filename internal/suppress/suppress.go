@@ -0,0 +1,181 @@
+// Package suppress keeps accepted-risk findings out of reports across runs.
+// An allowlist file holds one entry per line: the SHA-256 fingerprint of a finding type and
+// value, optionally followed by "expires:YYYY-MM-DD" and a "# comment". Entries identify
+// findings by fingerprint only, so the allowlist can be shared without disclosing the values
+// it covers. Blank lines and lines starting with "#" are ignored.
+//
+// An allowlist can be signed, so that a shared file cannot be edited to hide findings: its
+// Ed25519 signature is kept beside it with SignatureSuffix appended to its name, and
+// LoadSigned refuses the file unless the signature is valid for the given public key.
+package suppress
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+const (
+	// hashPrefix names the fingerprint algorithm, leaving room for others
+	hashPrefix = "sha256:"
+	// expiresPrefix introduces the expiry date of an entry
+	expiresPrefix = "expires:"
+	// dateLayout is the format of expiry dates
+	dateLayout = "2006-01-02"
+	// SignatureSuffix is appended to the name of an allowlist to name its signature file
+	SignatureSuffix = ".sig"
+)
+
+// Entry is a single allowlist line
+type Entry struct {
+	// Hash is the fingerprint of the suppressed value, as returned by Hash
+	Hash string
+	// Expires is the last day the entry applies; the zero time never expires
+	Expires time.Time
+	// Comment records why the finding was accepted
+	Comment string
+}
+
+// Hash returns the fingerprint identifying findings of type t with value in an allowlist.
+// The type is part of it, so that accepting a value as one type, such as a public email
+// address, does not hide it where it turns up as another.
+func Hash(t finding.Type, value string) string {
+	sum := sha256.Sum256([]byte(string(t) + "\x00" + value))
+	return hashPrefix + hex.EncodeToString(sum[:])
+}
+
+// ParseDate parses an expiry date in YYYY-MM-DD format
+func ParseDate(s string) (time.Time, error) {
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid expiry date %q: must be YYYY-MM-DD", s)
+	}
+	return t, nil
+}
+
+// String formats the entry as an allowlist line
+func (e Entry) String() string {
+	line := e.Hash
+	if !e.Expires.IsZero() {
+		line += " " + expiresPrefix + e.Expires.Format(dateLayout)
+	}
+	if e.Comment != "" {
+		line += " # " + e.Comment
+	}
+	return line
+}
+
+// Expired reports whether the entry no longer applies at now. An entry applies until the end
+// of its expiry day.
+func (e Entry) Expired(now time.Time) bool {
+	return !e.Expires.IsZero() && !now.Before(e.Expires.AddDate(0, 0, 1))
+}
+
+// List is a parsed allowlist
+type List struct {
+	entries map[string]Entry
+	now     func() time.Time
+}
+
+// Load reads an allowlist file
+func Load(path string) (*List, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// LoadSigned reads an allowlist file like Load after checking that the signature file beside
+// it holds an Ed25519 signature of its content made with the private key of key
+func LoadSigned(path string, key ed25519.PublicKey) (*List, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := os.ReadFile(path + SignatureSuffix)
+	if err != nil {
+		return nil, fmt.Errorf("error reading signature: %w", err)
+	}
+	if !ed25519.Verify(key, data, signature) {
+		return nil, fmt.Errorf("invalid signature in %s", path+SignatureSuffix)
+	}
+	return Parse(bytes.NewReader(data))
+}
+
+// Parse reads allowlist entries from r
+func Parse(r io.Reader) (*List, error) {
+	l := &List{entries: make(map[string]Entry), now: time.Now}
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		e, err := parseEntry(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		l.entries[e.Hash] = e
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func parseEntry(line string) (Entry, error) {
+	var e Entry
+	line, comment, _ := strings.Cut(line, "#")
+	e.Comment = strings.TrimSpace(comment)
+
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return e, fmt.Errorf("missing fingerprint")
+	}
+	e.Hash = strings.ToLower(fields[0])
+	digest := strings.TrimPrefix(e.Hash, hashPrefix)
+	if !strings.HasPrefix(e.Hash, hashPrefix) || len(digest) != sha256.Size*2 || strings.Trim(digest, "0123456789abcdef") != "" {
+		return e, fmt.Errorf("invalid fingerprint %q: generate entries with \"urlsluice suppress\"", fields[0])
+	}
+	for _, field := range fields[1:] {
+		date, ok := strings.CutPrefix(field, expiresPrefix)
+		if !ok {
+			return e, fmt.Errorf("unexpected %q: comments must start with #", field)
+		}
+		expires, err := ParseDate(date)
+		if err != nil {
+			return e, err
+		}
+		e.Expires = expires
+	}
+	return e, nil
+}
+
+// Suppressed reports whether f is covered by an entry that has not expired
+func (l *List) Suppressed(f finding.Finding) bool {
+	e, ok := l.entries[Hash(f.Type, f.Value)]
+	return ok && !e.Expired(l.now())
+}
+
+// Expired returns the entries that no longer apply, so they can be reviewed
+func (l *List) Expired() []Entry {
+	var expired []Entry
+	now := l.now()
+	for _, e := range l.entries {
+		if e.Expired(now) {
+			expired = append(expired, e)
+		}
+	}
+	return expired
+}
@@ -0,0 +1,113 @@
+package suppress
+
+import (
+	"crypto/ed25519"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+func TestList_Suppressed(t *testing.T) {
+	input := "# accepted risks\n" +
+		Hash(finding.TypeEmail, "press@target.com") + " # intentionally public\n" +
+		"\n" +
+		Hash(finding.TypeEmail, "old@target.com") + " expires:2024-03-31 # until the migration\n" +
+		Hash(finding.TypeDomain, "staging.target.com") + " expires:2024-04-30\n"
+
+	l, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	l.now = func() time.Time { return time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		typ   finding.Type
+		value string
+		want  bool
+	}{
+		{finding.TypeEmail, "press@target.com", true},
+		{finding.TypeEmail, "old@target.com", false},
+		{finding.TypeDomain, "staging.target.com", true},
+		{finding.TypeEmail, "admin@target.com", false},
+		// Entries only cover the type they were made for
+		{finding.TypeUsername, "press@target.com", false},
+		{finding.TypeURL, "staging.target.com", false},
+	}
+	for _, tt := range tests {
+		if got := l.Suppressed(finding.Finding{Type: tt.typ, Value: tt.value}); got != tt.want {
+			t.Errorf("Suppressed(%s %q) = %v, want %v", tt.typ, tt.value, got, tt.want)
+		}
+	}
+
+	expired := l.Expired()
+	if len(expired) != 1 || expired[0].Comment != "until the migration" {
+		t.Errorf("Expired() = %+v, want the old@target.com entry", expired)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := map[string]string{
+		"press@target.com": "invalid fingerprint",
+		"sha256:abcd":      "invalid fingerprint",
+		Hash(finding.TypeDomain, "a") + " expires:31/12/2024":   "invalid expiry date",
+		Hash(finding.TypeDomain, "a") + " intentionally public": "comments must start with #",
+	}
+	for line, want := range tests {
+		_, err := Parse(strings.NewReader(line + "\n"))
+		if err == nil || !strings.Contains(err.Error(), want) || !strings.Contains(err.Error(), "line 1") {
+			t.Errorf("Parse(%q) error = %v, want %q", line, err, want)
+		}
+	}
+}
+
+func TestEntry_String(t *testing.T) {
+	expires, _ := ParseDate("2025-06-30")
+	e := Entry{Hash: Hash(finding.TypeEmail, "press@target.com"), Expires: expires, Comment: "public contact"}
+
+	l, err := Parse(strings.NewReader(e.String()))
+	if err != nil {
+		t.Fatalf("Parse(%q) error = %v", e.String(), err)
+	}
+	if got := l.entries[e.Hash]; got != e {
+		t.Errorf("round trip = %+v, want %+v", got, e)
+	}
+}
+
+func TestLoadSigned(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "allowlist.txt")
+	data := []byte(Hash(finding.TypeEmail, "press@target.com") + " # public contact\n")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadSigned(path, public); err == nil || !strings.Contains(err.Error(), "signature") {
+		t.Errorf("LoadSigned() without a signature error = %v", err)
+	}
+	if err := os.WriteFile(path+SignatureSuffix, ed25519.Sign(private, data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	l, err := LoadSigned(path, public)
+	if err != nil {
+		t.Fatalf("LoadSigned() error = %v", err)
+	}
+	if !l.Suppressed(finding.Finding{Type: finding.TypeEmail, Value: "press@target.com"}) {
+		t.Error("signed allowlist does not suppress its entry")
+	}
+
+	// An entry added after signing invalidates the signature
+	edited := append(data, Hash(finding.TypeEmail, "admin@target.com")+"\n"...)
+	if err := os.WriteFile(path, edited, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSigned(path, public); err == nil || !strings.Contains(err.Error(), "invalid signature") {
+		t.Errorf("LoadSigned() of an edited allowlist error = %v", err)
+	}
+}
@@ -0,0 +1,44 @@
+package takeover
+
+import "regexp"
+
+// service describes a hosting provider whose unclaimed resources can be registered by anyone
+type service struct {
+	// name identifies the provider in the "service" metadata
+	name string
+	// cname matches the CNAME targets that point at the provider
+	cname *regexp.Regexp
+	// fingerprint is page text served for hosts with no resource behind them; empty when the
+	// provider only signals an unclaimed resource by the target not resolving
+	fingerprint string
+}
+
+// services lists the providers known to allow takeovers through a dangling CNAME
+var services = []service{
+	{"aws-s3", regexp.MustCompile(`\.s3(?:-website)?[.-](?:[\w-]+\.)?amazonaws\.com$`), "NoSuchBucket"},
+	{"aws-elasticbeanstalk", regexp.MustCompile(`\.elasticbeanstalk\.com$`), ""},
+	{"azure", regexp.MustCompile(`\.(?:azurewebsites\.net|cloudapp\.net|cloudapp\.azure\.com|trafficmanager\.net|blob\.core\.windows\.net|azureedge\.net|azure-api\.net|azurefd\.net|azurecontainer\.io|azurehdinsight\.net|servicebus\.windows\.net)$`), ""},
+	{"bitbucket", regexp.MustCompile(`\.bitbucket\.io$`), "Repository not found"},
+	{"fastly", regexp.MustCompile(`\.fastly\.net$`), "Fastly error: unknown domain"},
+	{"gcs", regexp.MustCompile(`^c\.storage\.googleapis\.com$`), "NoSuchBucket"},
+	{"ghost", regexp.MustCompile(`\.ghost\.io$`), "Failed to resolve DNS path for this host"},
+	{"github-pages", regexp.MustCompile(`\.github\.io$`), "There isn't a GitHub Pages site here."},
+	{"helpscout", regexp.MustCompile(`\.helpscoutdocs\.com$`), "No settings were found for this company:"},
+	{"heroku", regexp.MustCompile(`\.(?:herokuapp|herokudns|herokussl)\.com$`), "No such app"},
+	{"pantheon", regexp.MustCompile(`\.pantheonsite\.io$`), "The gods are wise, but do not know of the site which you seek."},
+	{"readme", regexp.MustCompile(`\.readme\.io$`), "Project doesnt exist... yet!"},
+	{"shopify", regexp.MustCompile(`\.myshopify\.com$`), "Sorry, this shop is currently unavailable."},
+	{"surge", regexp.MustCompile(`\.surge\.sh$`), "project not found"},
+	{"wordpress", regexp.MustCompile(`\.wordpress\.com$`), "Do you want to register"},
+	{"zendesk", regexp.MustCompile(`\.zendesk\.com$`), "Help Center Closed"},
+}
+
+// lookupService returns the provider a CNAME target points at, or nil
+func lookupService(target string) *service {
+	for i := range services {
+		if services[i].cname.MatchString(target) {
+			return &services[i]
+		}
+	}
+	return nil
+}
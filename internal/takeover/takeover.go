@@ -0,0 +1,247 @@
+// Package takeover finds references to resources that anyone could claim: CNAME records
+// pointing at unclaimed hosting services and storage buckets that do not exist.
+package takeover
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/httpclient"
+	"github.com/PeteJStewart/urlsluice/internal/s3probe"
+)
+
+const (
+	// defaultConcurrency is the number of references checked in parallel when none is configured
+	defaultConcurrency = 4
+	// maxPageSize caps how much of a page is read when matching fingerprints (1MB)
+	maxPageSize = 1024 * 1024
+)
+
+// Reasons recorded in the "reason" metadata of takeover findings
+const (
+	// ReasonDanglingCNAME means the CNAME target does not resolve
+	ReasonDanglingCNAME = "CNAME target does not resolve"
+	// ReasonFingerprint means the host serves the provider's page for unclaimed resources
+	ReasonFingerprint = "unclaimed service fingerprint"
+	// ReasonMissingBucket means the bucket does not exist
+	ReasonMissingBucket = "bucket does not exist"
+	// ReasonMissingAccount means the Azure storage account does not exist
+	ReasonMissingAccount = "storage account does not exist"
+	// ReasonMissingContainer means the Azure container does not exist
+	ReasonMissingContainer = "container does not exist"
+)
+
+// Resolver looks up DNS records; it is satisfied by *net.Resolver
+type Resolver interface {
+	LookupCNAME(ctx context.Context, host string) (string, error)
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// Checker looks for takeover candidates among domain and bucket findings
+type Checker struct {
+	// Client fetches the pages of hosts whose CNAME points at a hosting service
+	Client httpclient.Doer
+	// Resolver performs the DNS lookups; net.DefaultResolver is used when nil
+	Resolver Resolver
+	// S3 checks whether S3 buckets exist; buckets already probed with it keep their result
+	S3 *s3probe.Prober
+	// Concurrency bounds the number of references checked in parallel
+	Concurrency int
+}
+
+// Check returns a takeover finding for every domain whose CNAME is dangling or points at an
+// unclaimed service, and for every storage bucket that does not exist. Each finding keeps the
+// source of the reference and records the "service", the "reason" and, for domains, the CNAME
+// "target". References that cannot be checked are skipped.
+func (c *Checker) Check(ctx context.Context, findings []finding.Finding) []finding.Finding {
+	concurrency := c.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+
+	var (
+		wg         sync.WaitGroup
+		mu         sync.Mutex
+		candidates []finding.Finding
+	)
+	sem := make(chan struct{}, concurrency)
+	for _, f := range findings {
+		if f.Type != finding.TypeDomain && f.Type != finding.TypeBucket {
+			continue
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(f finding.Finding) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			var candidate *finding.Finding
+			if f.Type == finding.TypeDomain {
+				candidate = c.domain(ctx, f.Value)
+			} else {
+				candidate = c.bucket(ctx, f)
+			}
+			if candidate == nil {
+				return
+			}
+			candidate.Source, candidate.Line = f.Source, f.Line
+			mu.Lock()
+			candidates = append(candidates, *candidate)
+			mu.Unlock()
+		}(f)
+	}
+	wg.Wait()
+	return candidates
+}
+
+// domain checks whether host is an alias for a resource that can be claimed
+func (c *Checker) domain(ctx context.Context, host string) *finding.Finding {
+	cname, err := c.resolver().LookupCNAME(ctx, host)
+	if err != nil {
+		return nil
+	}
+	target := strings.ToLower(strings.TrimSuffix(cname, "."))
+	if target == "" || target == strings.ToLower(host) {
+		return nil
+	}
+	svc := lookupService(target)
+
+	candidate := finding.Finding{Type: finding.TypeTakeover, Value: host}
+	candidate.SetMeta("target", target)
+	if svc != nil {
+		candidate.SetMeta("service", svc.name)
+	}
+
+	if _, err := c.resolver().LookupHost(ctx, target); notFound(err) {
+		// Any dangling alias is worth a look, but only known providers are known to be claimable
+		candidate.Confidence = finding.ConfidenceMedium
+		if svc != nil {
+			candidate.Confidence = finding.ConfidenceHigh
+		}
+		candidate.SetMeta("reason", ReasonDanglingCNAME)
+		return &candidate
+	}
+	if svc == nil || svc.fingerprint == "" {
+		return nil
+	}
+	for _, scheme := range []string{"https", "http"} {
+		body, _, err := c.get(ctx, scheme+"://"+host+"/")
+		if err != nil {
+			continue
+		}
+		if !strings.Contains(body, svc.fingerprint) {
+			return nil
+		}
+		candidate.Confidence = finding.ConfidenceHigh
+		candidate.SetMeta("reason", ReasonFingerprint)
+		return &candidate
+	}
+	return nil
+}
+
+// bucket checks whether the bucket in f does not exist
+func (c *Checker) bucket(ctx context.Context, f finding.Finding) *finding.Finding {
+	switch f.Metadata["provider"] {
+	case "s3":
+		access := f.Metadata["s3_access"]
+		if access == "" && c.S3 != nil {
+			var err error
+			if access, _, err = c.S3.Bucket(ctx, f.Value, f.Metadata["region"]); err != nil {
+				return nil
+			}
+		}
+		if access != s3probe.Missing {
+			return nil
+		}
+		return bucketCandidate("s3://"+f.Value, "aws-s3", ReasonMissingBucket, finding.ConfidenceHigh)
+
+	case "gcs":
+		bucket := strings.TrimPrefix(f.Value, "gcs:")
+		body, status, err := c.get(ctx, "https://storage.googleapis.com/"+bucket+"/?max-keys=1")
+		if err != nil || status != http.StatusNotFound || !strings.Contains(body, "<Code>NoSuchBucket</Code>") {
+			return nil
+		}
+		return bucketCandidate("gs://"+bucket, "gcs", ReasonMissingBucket, finding.ConfidenceHigh)
+
+	case "azure":
+		account, container, _ := strings.Cut(strings.TrimPrefix(f.Value, "azure:"), "/")
+		host := account + ".blob.core.windows.net"
+		if _, err := c.resolver().LookupHost(ctx, host); err != nil {
+			if !notFound(err) {
+				return nil
+			}
+			return bucketCandidate("https://"+host+"/", "azure", ReasonMissingAccount, finding.ConfidenceHigh)
+		}
+		if container == "" {
+			return nil
+		}
+		// Only the account owner can create the container, so a missing one is a dangling
+		// reference rather than a claimable name
+		containerURL := "https://" + host + "/" + container
+		resp, err := c.do(ctx, containerURL+"?restype=container")
+		if err != nil {
+			return nil
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound || resp.Header.Get("X-Ms-Error-Code") != "ContainerNotFound" {
+			return nil
+		}
+		return bucketCandidate(containerURL, "azure", ReasonMissingContainer, finding.ConfidenceMedium)
+	}
+	return nil
+}
+
+func bucketCandidate(value, service, reason string, confidence finding.Confidence) *finding.Finding {
+	candidate := finding.Finding{Type: finding.TypeTakeover, Value: value, Confidence: confidence}
+	candidate.SetMeta("service", service)
+	candidate.SetMeta("reason", reason)
+	return &candidate
+}
+
+// get fetches rawURL and returns the start of its body and the status code
+func (c *Checker) get(ctx context.Context, rawURL string) (string, int, error) {
+	resp, err := c.do(ctx, rawURL)
+	if err != nil {
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPageSize))
+	if err != nil {
+		return "", 0, err
+	}
+	return string(body), resp.StatusCode, nil
+}
+
+func (c *Checker) do(ctx context.Context, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	return httpclient.Do(c.Client, req)
+}
+
+func (c *Checker) resolver() Resolver {
+	if c.Resolver != nil {
+		return c.Resolver
+	}
+	return net.DefaultResolver
+}
+
+// notFound reports whether err is a DNS answer that the name does not exist, as opposed to a
+// lookup that failed
+func notFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
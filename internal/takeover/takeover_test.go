@@ -0,0 +1,157 @@
+package takeover
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+// fakeResolver answers from fixed CNAME and address tables; other names do not exist
+type fakeResolver struct {
+	cnames map[string]string
+	hosts  map[string]bool
+}
+
+func (r fakeResolver) LookupCNAME(_ context.Context, host string) (string, error) {
+	if cname, ok := r.cnames[host]; ok {
+		return cname + ".", nil
+	}
+	if r.hosts[host] {
+		return host + ".", nil
+	}
+	return "", &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func (r fakeResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	if r.hosts[host] {
+		return []string{"192.0.2.1"}, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+// redirectClient sends every request to a test server, keeping the original Host header
+type redirectClient struct {
+	target *url.URL
+}
+
+func (c redirectClient) Do(req *http.Request) (*http.Response, error) {
+	req.Host = req.URL.Host
+	req.URL.Scheme, req.URL.Host = c.target.Scheme, c.target.Host
+	return http.DefaultClient.Do(req)
+}
+
+func TestChecker_Check(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Host {
+		case "blog.target.com":
+			fmt.Fprint(w, "<h1>404</h1><p>There isn't a GitHub Pages site here.</p>")
+		case "docs.target.com":
+			fmt.Fprint(w, "<h1>Docs</h1>")
+		case "storage.googleapis.com":
+			if r.URL.Path == "/target-gone/" {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, "<Error><Code>NoSuchBucket</Code></Error>")
+				return
+			}
+			w.WriteHeader(http.StatusForbidden)
+		case "targetstore.blob.core.windows.net":
+			if r.URL.Path == "/old" {
+				w.Header().Set("X-Ms-Error-Code", "ContainerNotFound")
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("X-Ms-Error-Code", "ResourceNotFound")
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	target, _ := url.Parse(srv.URL)
+
+	resolver := fakeResolver{
+		cnames: map[string]string{
+			"shop.target.com":   "target-shop.herokuapp.com",
+			"legacy.target.com": "lb.oldvendor.net",
+			"blog.target.com":   "target.github.io",
+			"docs.target.com":   "target-docs.github.io",
+		},
+		hosts: map[string]bool{
+			"www.target.com":                    true,
+			"target.github.io":                  true,
+			"target-docs.github.io":             true,
+			"targetstore.blob.core.windows.net": true,
+		},
+	}
+	checker := &Checker{Client: redirectClient{target}, Resolver: resolver}
+
+	findings := []finding.Finding{
+		{Type: finding.TypeDomain, Value: "www.target.com", Source: "app.js", Line: 1},
+		{Type: finding.TypeDomain, Value: "shop.target.com", Source: "app.js", Line: 2},
+		{Type: finding.TypeDomain, Value: "legacy.target.com", Source: "app.js", Line: 3},
+		{Type: finding.TypeDomain, Value: "blog.target.com", Source: "app.js", Line: 4},
+		{Type: finding.TypeDomain, Value: "docs.target.com", Source: "app.js", Line: 5},
+		{Type: finding.TypeBucket, Value: "target-old", Metadata: map[string]string{"provider": "s3", "s3_access": "missing"}},
+		{Type: finding.TypeBucket, Value: "target-assets", Metadata: map[string]string{"provider": "s3", "s3_access": "denied"}},
+		{Type: finding.TypeBucket, Value: "gcs:target-gone", Metadata: map[string]string{"provider": "gcs"}},
+		{Type: finding.TypeBucket, Value: "gcs:target-live", Metadata: map[string]string{"provider": "gcs"}},
+		{Type: finding.TypeBucket, Value: "azure:targetbackup/db", Metadata: map[string]string{"provider": "azure"}},
+		{Type: finding.TypeBucket, Value: "azure:targetstore/old", Metadata: map[string]string{"provider": "azure"}},
+		{Type: finding.TypeBucket, Value: "azure:targetstore/private", Metadata: map[string]string{"provider": "azure"}},
+		{Type: finding.TypeURL, Value: "https://shop.target.com/"},
+	}
+
+	type result struct {
+		service, reason string
+		confidence      finding.Confidence
+	}
+	want := map[string]result{
+		"shop.target.com":   {"heroku", ReasonDanglingCNAME, finding.ConfidenceHigh},
+		"legacy.target.com": {"", ReasonDanglingCNAME, finding.ConfidenceMedium},
+		"blog.target.com":   {"github-pages", ReasonFingerprint, finding.ConfidenceHigh},
+		"s3://target-old":   {"aws-s3", ReasonMissingBucket, finding.ConfidenceHigh},
+		"gs://target-gone":  {"gcs", ReasonMissingBucket, finding.ConfidenceHigh},
+		"https://targetbackup.blob.core.windows.net/":   {"azure", ReasonMissingAccount, finding.ConfidenceHigh},
+		"https://targetstore.blob.core.windows.net/old": {"azure", ReasonMissingContainer, finding.ConfidenceMedium},
+	}
+
+	got := make(map[string]result)
+	for _, f := range checker.Check(context.Background(), findings) {
+		if f.Type != finding.TypeTakeover {
+			t.Errorf("%s type = %s, want takeover", f.Value, f.Type)
+		}
+		got[f.Value] = result{f.Metadata["service"], f.Metadata["reason"], f.Confidence}
+		if f.Value == "shop.target.com" && (f.Source != "app.js" || f.Line != 2 || f.Metadata["target"] != "target-shop.herokuapp.com") {
+			t.Errorf("shop.target.com = %+v, want the source, line and CNAME target of the reference", f)
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Check() = %v, want %v", got, want)
+	}
+}
+
+func TestLookupService(t *testing.T) {
+	tests := map[string]string{
+		"target.github.io":                                  "github-pages",
+		"assets.target.com.s3.amazonaws.com":                "aws-s3",
+		"www.target.com.s3-website-us-east-1.amazonaws.com": "aws-s3",
+		"target.azurewebsites.net":                          "azure",
+		"c.storage.googleapis.com":                          "gcs",
+		"target.cdn.cloudflare.net":                         "",
+		"github.io.attacker.com":                            "",
+	}
+	for target, want := range tests {
+		var got string
+		if svc := lookupService(target); svc != nil {
+			got = svc.name
+		}
+		if got != want {
+			t.Errorf("lookupService(%q) = %q, want %q", target, got, want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// Package tempdir manages the temporary files of a run. Files are created in a directory of
+// their own under the system temporary directory, or under the directory set with SetBase
+// when /tmp is too small, and Cleanup removes it when the run ends or is interrupted.
+// Temporary files that must live elsewhere, such as those renamed over the file they
+// replace, are registered with Track so an interrupted write does not leave them behind.
+package tempdir
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+var (
+	mu sync.Mutex
+	// base is the directory the run directory is created in, "" for os.TempDir
+	base string
+	// dir is the run directory, created on first use
+	dir string
+	// tracked are the temporary files outside dir that are still being written
+	tracked = make(map[string]int)
+)
+
+// SetBase sets the directory the run directory is created in, which must exist. It only
+// affects run directories created afterwards.
+func SetBase(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", path)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	base = path
+	return nil
+}
+
+// Dir returns the run directory, creating it on first use
+func Dir() (string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if dir == "" {
+		d, err := os.MkdirTemp(base, "urlsluice-*")
+		if err != nil {
+			return "", err
+		}
+		dir = d
+	}
+	return dir, nil
+}
+
+// CreateTemp creates a temporary file in the run directory, like os.CreateTemp
+func CreateTemp(pattern string) (*os.File, error) {
+	d, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	return os.CreateTemp(d, pattern)
+}
+
+// MkdirTemp creates a temporary directory in the run directory, like os.MkdirTemp
+func MkdirTemp(pattern string) (string, error) {
+	d, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return os.MkdirTemp(d, pattern)
+}
+
+// Track registers a temporary file created outside the run directory, so Cleanup removes
+// it if the run ends before the returned function is called. Call it once the file has
+// been renamed into place or removed.
+func Track(path string) (done func()) {
+	mu.Lock()
+	tracked[path]++
+	mu.Unlock()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			mu.Lock()
+			defer mu.Unlock()
+			if tracked[path]--; tracked[path] <= 0 {
+				delete(tracked, path)
+			}
+		})
+	}
+}
+
+// Cleanup removes the run directory and the tracked files. Later temporary files get a new
+// run directory.
+func Cleanup() error {
+	mu.Lock()
+	defer mu.Unlock()
+	var firstErr error
+	for path := range tracked {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) && firstErr == nil {
+			firstErr = err
+		}
+		delete(tracked, path)
+	}
+	if dir != "" {
+		if err := os.RemoveAll(dir); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		dir = ""
+	}
+	return firstErr
+}
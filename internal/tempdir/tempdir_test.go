@@ -0,0 +1,77 @@
+package tempdir
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCleanup(t *testing.T) {
+	work := t.TempDir()
+	if err := SetBase(work); err != nil {
+		t.Fatal(err)
+	}
+	defer SetBase(os.TempDir())
+
+	f, err := CreateTemp("spool-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if !strings.HasPrefix(f.Name(), work+string(filepath.Separator)) {
+		t.Errorf("CreateTemp() = %s, want a file under %s", f.Name(), work)
+	}
+	sub, err := MkdirTemp("unpack-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A file being written next to the one it replaces, and one already renamed into place
+	pending := filepath.Join(work, ".entry.tmp")
+	renamed := filepath.Join(work, ".done.tmp")
+	for _, path := range []string{pending, renamed} {
+		if err := os.WriteFile(path, []byte("{}"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	Track(pending)
+	done := Track(renamed)
+	done()
+	done()
+
+	if err := Cleanup(); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{f.Name(), sub, pending} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s still exists after Cleanup()", path)
+		}
+	}
+	if _, err := os.Stat(renamed); err != nil {
+		t.Errorf("Cleanup() removed a file no longer tracked: %v", err)
+	}
+
+	// A new run directory is created after a cleanup
+	d, err := Dir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(d); err != nil {
+		t.Error(err)
+	}
+	Cleanup()
+}
+
+func TestSetBase(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetBase(file); err == nil || !strings.Contains(err.Error(), "not a directory") {
+		t.Errorf("SetBase(file) error = %v", err)
+	}
+	if err := SetBase(filepath.Join(file, "missing")); err == nil {
+		t.Error("SetBase() of a missing directory error = nil")
+	}
+}
@@ -0,0 +1,138 @@
+// Package timestamps decodes creation times embedded in identifiers such as
+// time-based UUIDs, ULIDs, snowflake IDs and Unix epoch values.
+package timestamps
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/uuids"
+)
+
+// Kinds of identifiers a timestamp can be decoded from
+const (
+	KindUUIDv1       = "uuid_v1"
+	KindUUIDv6       = "uuid_v6"
+	KindUUIDv7       = "uuid_v7"
+	KindULID         = "ulid"
+	KindSnowflake    = "snowflake"
+	KindEpochSeconds = "epoch_s"
+	KindEpochMillis  = "epoch_ms"
+)
+
+// crockfordAlphabet is the base32 alphabet used by ULIDs
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+var (
+	// TwitterEpoch is the custom epoch of Twitter snowflake IDs
+	TwitterEpoch = time.UnixMilli(1288834974657).UTC()
+	// DiscordEpoch is the custom epoch of Discord snowflake IDs
+	DiscordEpoch = time.UnixMilli(1420070400000).UTC()
+	// earliest is the lower bound for plausible creation times of IDs without a version marker
+	earliest = time.Date(2005, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// Plausible reports whether t is a believable creation time for a snowflake, ULID or epoch
+// value. Those formats carry no marker, so random numbers decode to times far in the past
+// or future; anything before 2005 or more than a year from now is rejected.
+func Plausible(t time.Time) bool {
+	return !t.Before(earliest) && t.Before(time.Now().AddDate(1, 0, 0))
+}
+
+// UUID decodes the creation time of a version 1, 6 or 7 UUID
+func UUID(s string) (time.Time, string, bool) {
+	u, err := uuids.Parse(strings.ToLower(s))
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	t, ok := u.Time()
+	if !ok {
+		return time.Time{}, "", false
+	}
+	return t, "uuid_v" + strconv.Itoa(u.Version()), true
+}
+
+// ULID decodes the millisecond timestamp stored in the first 10 characters of a ULID
+func ULID(s string) (time.Time, bool) {
+	if len(s) != 26 {
+		return time.Time{}, false
+	}
+	var ms uint64
+	for _, c := range strings.ToUpper(s[:10]) {
+		i := strings.IndexRune(crockfordAlphabet, c)
+		if i < 0 {
+			return time.Time{}, false
+		}
+		ms = ms<<5 | uint64(i)
+	}
+	if ms >= 1<<48 {
+		return time.Time{}, false
+	}
+	return time.UnixMilli(int64(ms)).UTC(), true
+}
+
+// Snowflake decodes the millisecond offset stored in the top 42 bits of a snowflake ID
+func Snowflake(id uint64, epoch time.Time) time.Time {
+	return epoch.Add(time.Duration(id>>22) * time.Millisecond)
+}
+
+// Epoch decodes a 10 digit Unix time in seconds or a 13 digit Unix time in milliseconds
+func Epoch(s string) (time.Time, string, bool) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n <= 0 {
+		return time.Time{}, "", false
+	}
+	switch len(s) {
+	case 10:
+		return time.Unix(n, 0).UTC(), KindEpochSeconds, true
+	case 13:
+		return time.UnixMilli(n).UTC(), KindEpochMillis, true
+	}
+	return time.Time{}, "", false
+}
+
+// Range summarizes the creation times of the timestamp findings of one kind
+type Range struct {
+	Kind     string
+	Count    int
+	Earliest time.Time
+	Latest   time.Time
+}
+
+// Ranges returns the earliest and latest decoded time for each kind of timestamp finding,
+// ordered by kind
+func Ranges(findings []finding.Finding) []Range {
+	byKind := make(map[string]*Range)
+	for _, f := range findings {
+		if f.Type != finding.TypeTimestamp {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339Nano, f.Metadata["time"])
+		if err != nil {
+			continue
+		}
+		kind := f.Metadata["kind"]
+		r, ok := byKind[kind]
+		if !ok {
+			r = &Range{Kind: kind, Earliest: t, Latest: t}
+			byKind[kind] = r
+		}
+		r.Count++
+		if t.Before(r.Earliest) {
+			r.Earliest = t
+		}
+		if t.After(r.Latest) {
+			r.Latest = t
+		}
+	}
+
+	ranges := make([]Range, 0, len(byKind))
+	for _, r := range byKind {
+		ranges = append(ranges, *r)
+	}
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].Kind < ranges[j].Kind })
+	return ranges
+}
@@ -0,0 +1,118 @@
+package timestamps
+
+import (
+	"testing"
+	"time"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+func TestULID(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   time.Time
+		wantOK bool
+	}{
+		{"01ARZ3NDEKTSV4RRFFQ69G5FAV", time.UnixMilli(1469922850259).UTC(), true},
+		{"01arz3ndektsv4rrffq69g5fav", time.UnixMilli(1469922850259).UTC(), true},
+		{"01ARZ3NDEK", time.Time{}, false},
+		{"01ARZ3NDEUTSV4RRFFQ69G5FAV", time.Time{}, false},
+		{"81ARZ3NDEKTSV4RRFFQ69G5FAV", time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ULID(tt.input)
+		if ok != tt.wantOK || !got.Equal(tt.want) {
+			t.Errorf("ULID(%q) = %v, %v, want %v, %v", tt.input, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestSnowflake(t *testing.T) {
+	got := Snowflake(1212092628029698048, TwitterEpoch)
+	if want := time.Date(2019, 12, 31, 19, 26, 16, 771e6, time.UTC); !got.Equal(want) {
+		t.Errorf("Snowflake() = %v, want %v", got, want)
+	}
+	got = Snowflake(175928847299117063, DiscordEpoch)
+	if want := time.Date(2016, 4, 30, 11, 18, 25, 796e6, time.UTC); !got.Equal(want) {
+		t.Errorf("Snowflake() = %v, want %v", got, want)
+	}
+}
+
+func TestEpoch(t *testing.T) {
+	tests := []struct {
+		input    string
+		want     time.Time
+		wantKind string
+		wantOK   bool
+	}{
+		{"1700000000", time.Unix(1700000000, 0).UTC(), KindEpochSeconds, true},
+		{"1700000000123", time.UnixMilli(1700000000123).UTC(), KindEpochMillis, true},
+		{"170000000012", time.Time{}, "", false},
+		{"0000000000", time.Time{}, "", false},
+		{"17000000x0", time.Time{}, "", false},
+	}
+
+	for _, tt := range tests {
+		got, kind, ok := Epoch(tt.input)
+		if ok != tt.wantOK || kind != tt.wantKind || !got.Equal(tt.want) {
+			t.Errorf("Epoch(%q) = %v, %q, %v, want %v, %q, %v", tt.input, got, kind, ok, tt.want, tt.wantKind, tt.wantOK)
+		}
+	}
+}
+
+func TestUUID(t *testing.T) {
+	if _, kind, ok := UUID("6BA7B810-9DAD-11D1-80B4-00C04FD430C8"); !ok || kind != KindUUIDv1 {
+		t.Errorf("UUID(v1) = %q, %v, want %q, true", kind, ok, KindUUIDv1)
+	}
+	if _, _, ok := UUID("550e8400-e29b-41d4-a716-446655440000"); ok {
+		t.Error("UUID(v4) decoded a timestamp")
+	}
+}
+
+func TestPlausible(t *testing.T) {
+	tests := []struct {
+		input time.Time
+		want  bool
+	}{
+		{time.Date(2004, 12, 31, 0, 0, 0, 0, time.UTC), false},
+		{time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC), true},
+		{time.Now().AddDate(2, 0, 0), false},
+	}
+
+	for _, tt := range tests {
+		if got := Plausible(tt.input); got != tt.want {
+			t.Errorf("Plausible(%v) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestRanges(t *testing.T) {
+	ts := func(value, kind, at string) finding.Finding {
+		f := finding.Finding{Type: finding.TypeTimestamp, Value: value}
+		f.SetMeta("kind", kind)
+		f.SetMeta("time", at)
+		return f
+	}
+	findings := []finding.Finding{
+		ts("a", KindULID, "2021-05-01T00:00:00Z"),
+		ts("b", KindEpochSeconds, "2023-11-14T22:13:20Z"),
+		ts("c", KindULID, "2020-01-01T00:00:00Z"),
+		ts("d", KindULID, "2022-03-04T05:06:07.5Z"),
+		{Type: finding.TypeUUID, Value: "550e8400-e29b-41d4-a716-446655440000"},
+	}
+
+	got := Ranges(findings)
+	if len(got) != 2 {
+		t.Fatalf("Ranges() returned %d ranges, want 2: %+v", len(got), got)
+	}
+	if got[0].Kind != KindEpochSeconds || got[0].Count != 1 {
+		t.Errorf("Ranges()[0] = %+v, want 1 %s", got[0], KindEpochSeconds)
+	}
+	ulid := got[1]
+	if ulid.Kind != KindULID || ulid.Count != 3 ||
+		!ulid.Earliest.Equal(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)) ||
+		!ulid.Latest.Equal(time.Date(2022, 3, 4, 5, 6, 7, 5e8, time.UTC)) {
+		t.Errorf("Ranges()[1] = %+v", ulid)
+	}
+}
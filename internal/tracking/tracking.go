@@ -0,0 +1,127 @@
+// Package tracking recognizes the campaign and click identifiers that marketing and
+// analytics tools add to URLs, such as utm_source or gclid. They vary per visit without
+// changing the page, so they are stripped from URLs and reported apart from the
+// parameters worth fuzzing.
+package tracking
+
+import (
+	_ "embed"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+// defaultParams is the built-in list of tracking parameters, one name or prefix* per line
+//
+//go:embed params.txt
+var defaultParams string
+
+// List matches parameter names against exact names and prefixes, ignoring case
+type List struct {
+	names    map[string]bool
+	prefixes []string
+}
+
+// Default returns the built-in list of tracking parameters
+func Default() *List {
+	l := &List{names: make(map[string]bool)}
+	for _, line := range strings.Split(defaultParams, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			l.add(line)
+		}
+	}
+	return l
+}
+
+// Validate checks the names of a user list, which may only use * as their last character
+func Validate(names []string) error {
+	for i, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || name == "*" {
+			return fmt.Errorf("tracking_params[%d]: name is required", i)
+		}
+		if strings.Contains(strings.TrimSuffix(name, "*"), "*") {
+			return fmt.Errorf("tracking_params[%d]: %q: * is only allowed at the end", i, name)
+		}
+	}
+	return nil
+}
+
+// With returns a copy of the list extended with names, validated with Validate
+func (l *List) With(names []string) *List {
+	c := &List{names: make(map[string]bool, len(l.names)+len(names)), prefixes: append([]string(nil), l.prefixes...)}
+	for name := range l.names {
+		c.names[name] = true
+	}
+	for _, name := range names {
+		c.add(strings.TrimSpace(name))
+	}
+	return c
+}
+
+func (l *List) add(name string) {
+	name = strings.ToLower(name)
+	if prefix, ok := strings.CutSuffix(name, "*"); ok {
+		l.prefixes = append(l.prefixes, prefix)
+		return
+	}
+	l.names[name] = true
+}
+
+// Match reports whether name is a tracking parameter
+func (l *List) Match(name string) bool {
+	name = strings.ToLower(name)
+	if l.names[name] {
+		return true
+	}
+	for _, prefix := range l.prefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// StripURL removes the tracking parameters from the query of rawURL, keeping the order and
+// encoding of the others. The "?" is dropped when no parameter is left.
+func (l *List) StripURL(rawURL string) string {
+	base, rest, ok := strings.Cut(rawURL, "?")
+	if !ok {
+		return rawURL
+	}
+	query, fragment, hasFragment := strings.Cut(rest, "#")
+	var kept []string
+	for _, pair := range strings.Split(query, "&") {
+		key, _, _ := strings.Cut(pair, "=")
+		if name, err := url.QueryUnescape(key); err == nil && l.Match(name) {
+			continue
+		}
+		kept = append(kept, pair)
+	}
+	s := base
+	if len(kept) > 0 {
+		s += "?" + strings.Join(kept, "&")
+	}
+	if hasFragment {
+		s += "#" + fragment
+	}
+	return s
+}
+
+// Separate strips tracking parameters from URL findings and turns parameter findings with
+// a tracking name into tracking_param findings
+func (l *List) Separate(findings []finding.Finding) {
+	for i := range findings {
+		f := &findings[i]
+		switch f.Type {
+		case finding.TypeURL:
+			f.Value = l.StripURL(f.Value)
+		case finding.TypeParam:
+			if key, _, _ := strings.Cut(f.Value, "="); l.Match(key) {
+				f.Type = finding.TypeTrackingParam
+			}
+		}
+	}
+}
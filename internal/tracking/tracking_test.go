@@ -0,0 +1,78 @@
+package tracking
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+func TestList_Match(t *testing.T) {
+	l := Default().With([]string{"ref", "cx_*"})
+	tests := map[string]bool{
+		"utm_source":  true,
+		"UTM_Medium":  true,
+		"gclid":       true,
+		"fbclid":      true,
+		"_hsenc":      true,
+		"ref":         true,
+		"cx_campaign": true,
+		"id":          false,
+		"utm":         false,
+		"redirect":    false,
+	}
+	for name, want := range tests {
+		if got := l.Match(name); got != want {
+			t.Errorf("Match(%q) = %v, want %v", name, got, want)
+		}
+	}
+	if Default().Match("ref") {
+		t.Error("With() modified the default list")
+	}
+}
+
+func TestList_StripURL(t *testing.T) {
+	l := Default()
+	tests := map[string]string{
+		"https://target.com/a?utm_source=x&id=1&gclid=y#top": "https://target.com/a?id=1#top",
+		"https://target.com/a?utm_source=x":                  "https://target.com/a",
+		"https://target.com/a?%75tm_medium=x&q=a%20b":        "https://target.com/a?q=a%20b",
+		"https://target.com/a?b=2&a=1":                       "https://target.com/a?b=2&a=1",
+		"https://target.com/a":                               "https://target.com/a",
+	}
+	for in, want := range tests {
+		if got := l.StripURL(in); got != want {
+			t.Errorf("StripURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestList_Separate(t *testing.T) {
+	findings := []finding.Finding{
+		{Type: finding.TypeURL, Value: "https://target.com/?fbclid=1&page=2"},
+		{Type: finding.TypeParam, Value: "fbclid=1"},
+		{Type: finding.TypeParam, Value: "page=2"},
+		{Type: finding.TypeDomain, Value: "utm_source.target.com"},
+	}
+	Default().Separate(findings)
+	want := []finding.Finding{
+		{Type: finding.TypeURL, Value: "https://target.com/?page=2"},
+		{Type: finding.TypeTrackingParam, Value: "fbclid=1"},
+		{Type: finding.TypeParam, Value: "page=2"},
+		{Type: finding.TypeDomain, Value: "utm_source.target.com"},
+	}
+	if !reflect.DeepEqual(findings, want) {
+		t.Errorf("Separate() = %+v, want %+v", findings, want)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate([]string{"ref", "cx_*"}); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	for _, names := range [][]string{{""}, {"*"}, {"a*b"}} {
+		if err := Validate(names); err == nil {
+			t.Errorf("Validate(%q) succeeded", names)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+// Package traffic reads recorded HTTP traffic, such as HAR files exported by browsers and
+// items saved from Burp Suite, into the responses it contains.
+package traffic
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+// ErrUnknownFormat is returned for input that is neither a HAR file nor a Burp Suite export
+var ErrUnknownFormat = errors.New("not a HAR file or Burp Suite XML export")
+
+// Response is a recorded response together with the URL it was requested from
+type Response struct {
+	URL    string
+	Status int
+	Header http.Header
+	Body   []byte
+	// RequestHeader holds the headers of the request, when they were recorded
+	RequestHeader http.Header
+}
+
+// Parse reads the responses of a HAR file or a Burp Suite XML export, detected by
+// whether the document is JSON or XML. Entries without a response are skipped.
+func Parse(data []byte) ([]Response, error) {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		return parseHAR(trimmed)
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		return parseBurp(trimmed)
+	}
+	return nil, ErrUnknownFormat
+}
+
+type harFile struct {
+	Log *struct {
+		Entries []struct {
+			Request struct {
+				URL     string `json:"url"`
+				Headers []struct {
+					Name  string `json:"name"`
+					Value string `json:"value"`
+				} `json:"headers"`
+			} `json:"request"`
+			Response *struct {
+				Status  int `json:"status"`
+				Headers []struct {
+					Name  string `json:"name"`
+					Value string `json:"value"`
+				} `json:"headers"`
+				Content struct {
+					Text     string `json:"text"`
+					Encoding string `json:"encoding"`
+				} `json:"content"`
+			} `json:"response"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+func parseHAR(data []byte) ([]Response, error) {
+	var har harFile
+	if err := json.Unmarshal(data, &har); err != nil {
+		return nil, fmt.Errorf("invalid HAR file: %w", err)
+	}
+	if har.Log == nil {
+		return nil, ErrUnknownFormat
+	}
+	var responses []Response
+	for _, e := range har.Log.Entries {
+		if e.Response == nil {
+			continue
+		}
+		r := Response{URL: e.Request.URL, Status: e.Response.Status, Header: make(http.Header), RequestHeader: make(http.Header)}
+		for _, h := range e.Response.Headers {
+			r.Header.Add(h.Name, h.Value)
+		}
+		for _, h := range e.Request.Headers {
+			r.RequestHeader.Add(h.Name, h.Value)
+		}
+		r.Body = []byte(e.Response.Content.Text)
+		if e.Response.Content.Encoding == "base64" {
+			body, err := base64.StdEncoding.DecodeString(e.Response.Content.Text)
+			if err != nil {
+				return nil, fmt.Errorf("invalid HAR response body for %s: %w", r.URL, err)
+			}
+			r.Body = body
+		}
+		responses = append(responses, r)
+	}
+	return responses, nil
+}
+
+type burpItems struct {
+	XMLName xml.Name `xml:"items"`
+	Items   []struct {
+		URL     string `xml:"url"`
+		Status  string `xml:"status"`
+		Request struct {
+			Base64 bool   `xml:"base64,attr"`
+			Text   string `xml:",chardata"`
+		} `xml:"request"`
+		Response struct {
+			Base64 bool   `xml:"base64,attr"`
+			Text   string `xml:",chardata"`
+		} `xml:"response"`
+	} `xml:"item"`
+}
+
+func parseBurp(data []byte) ([]Response, error) {
+	var items burpItems
+	if err := xml.Unmarshal(data, &items); err != nil {
+		var unexpected xml.UnmarshalError
+		if errors.As(err, &unexpected) {
+			return nil, ErrUnknownFormat
+		}
+		return nil, fmt.Errorf("invalid Burp Suite export: %w", err)
+	}
+	var responses []Response
+	for _, item := range items.Items {
+		raw := []byte(item.Response.Text)
+		if item.Response.Base64 {
+			decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(item.Response.Text))
+			if err != nil {
+				return nil, fmt.Errorf("invalid Burp Suite response for %s: %w", item.URL, err)
+			}
+			raw = decoded
+		}
+		if len(bytes.TrimSpace(raw)) == 0 {
+			continue
+		}
+		r, err := parseRaw(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Burp Suite response for %s: %w", item.URL, err)
+		}
+		r.URL = strings.TrimSpace(item.URL)
+		if r.Status == 0 {
+			r.Status, _ = strconv.Atoi(strings.TrimSpace(item.Status))
+		}
+		r.RequestHeader = requestHeader(item.Request.Text, item.Request.Base64)
+		responses = append(responses, r)
+	}
+	return responses, nil
+}
+
+// parseRaw reads a response as it was sent on the wire: a status line such as
+// "HTTP/2 200", the headers and the body, which is de-chunked and decompressed
+// when the headers say so
+func parseRaw(raw []byte) (Response, error) {
+	br := bufio.NewReader(bytes.NewReader(raw))
+	tp := textproto.NewReader(br)
+	status, err := tp.ReadLine()
+	if err != nil {
+		return Response{}, err
+	}
+	if !strings.HasPrefix(status, "HTTP/") {
+		return Response{}, fmt.Errorf("malformed status line %q", status)
+	}
+	var r Response
+	if fields := strings.Fields(status); len(fields) > 1 {
+		r.Status, _ = strconv.Atoi(fields[1])
+	}
+	header, err := tp.ReadMIMEHeader()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return Response{}, err
+	}
+	r.Header = http.Header(header)
+	body, _ := io.ReadAll(br)
+	r.Body = decodeBody(body, r.Header)
+	return r, nil
+}
+
+// requestHeader reads the headers of a request recorded as it was sent on the wire, e.g.
+// "GET / HTTP/1.1" followed by the headers; requests that cannot be read have no headers
+func requestHeader(text string, base64Encoded bool) http.Header {
+	raw := []byte(text)
+	if base64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(text))
+		if err != nil {
+			return nil
+		}
+		raw = decoded
+	}
+	tp := textproto.NewReader(bufio.NewReader(bytes.NewReader(bytes.TrimLeft(raw, "\r\n"))))
+	if _, err := tp.ReadLine(); err != nil {
+		return nil
+	}
+	header, err := tp.ReadMIMEHeader()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil
+	}
+	return http.Header(header)
+}
+
+// decodeBody undoes chunked transfer encoding and gzip content encoding, keeping the body
+// as recorded when it cannot be decoded
+func decodeBody(body []byte, header http.Header) []byte {
+	if strings.EqualFold(header.Get("Transfer-Encoding"), "chunked") {
+		if decoded, err := dechunk(body); err == nil {
+			body = decoded
+		} else if decoded, err := dechunk(crlf(body)); err == nil {
+			// XML parsers turn the CRLF line endings of responses stored as text into LF
+			body = decoded
+		}
+	}
+	if strings.EqualFold(header.Get("Content-Encoding"), "gzip") {
+		if zr, err := gzip.NewReader(bytes.NewReader(body)); err == nil {
+			if decoded, err := io.ReadAll(zr); err == nil {
+				body = decoded
+			}
+		}
+	}
+	return body
+}
+
+func dechunk(body []byte) ([]byte, error) {
+	return io.ReadAll(httputil.NewChunkedReader(bytes.NewReader(body)))
+}
+
+// crlf restores CRLF line endings in data whose CRs were dropped
+func crlf(data []byte) []byte {
+	return bytes.ReplaceAll(bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n")), []byte("\n"), []byte("\r\n"))
+}
@@ -0,0 +1,105 @@
+package traffic
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func TestParse_HAR(t *testing.T) {
+	har := `{"log": {"version": "1.2", "entries": [
+	  {"request": {"method": "GET", "url": "https://app.target.com/", "headers": [{"name": "Origin", "value": "https://evil.com"}]},
+	   "response": {"status": 200,
+	     "headers": [{"name": "content-security-policy", "value": "script-src 'self'"}, {"name": "Set-Cookie", "value": "a=1"}, {"name": "Set-Cookie", "value": "b=2"}],
+	     "content": {"mimeType": "text/html", "text": "<a href=\"https://api.target.com/\">"}}},
+	  {"request": {"method": "GET", "url": "https://app.target.com/logo.png"},
+	   "response": {"status": 200, "headers": [], "content": {"text": "aGVsbG8=", "encoding": "base64"}}},
+	  {"request": {"method": "GET", "url": "https://app.target.com/pending"}}
+	]}}`
+
+	responses, err := Parse([]byte(har))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(responses) != 2 {
+		t.Fatalf("Parse() returned %d responses, want 2", len(responses))
+	}
+	r := responses[0]
+	if r.URL != "https://app.target.com/" || r.Status != 200 {
+		t.Errorf("response = %s %d", r.URL, r.Status)
+	}
+	if got := r.Header.Get("Content-Security-Policy"); got != "script-src 'self'" {
+		t.Errorf("CSP header = %q", got)
+	}
+	if got := r.RequestHeader.Get("Origin"); got != "https://evil.com" {
+		t.Errorf("request Origin header = %q", got)
+	}
+	if got := r.Header.Values("Set-Cookie"); len(got) != 2 {
+		t.Errorf("Set-Cookie = %v, want both values", got)
+	}
+	if got := string(responses[1].Body); got != "hello" {
+		t.Errorf("base64 body = %q, want hello", got)
+	}
+}
+
+func TestParse_Burp(t *testing.T) {
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte("var api = 'https://api.target.com';"))
+	zw.Close()
+	compressed := "HTTP/2 200 OK\r\nContent-Encoding: gzip\r\nAccess-Control-Allow-Origin: *\r\n\r\n" + gz.String()
+	chunked := "HTTP/1.1 404 Not Found\r\nTransfer-Encoding: chunked\r\n\r\n5\r\nhello\r\n0\r\n\r\n"
+
+	export := `<?xml version="1.0"?>
+<items burpVersion="2023.1">
+  <item>
+    <url><![CDATA[https://app.target.com/app.js]]></url>
+    <status>200</status>
+    <request base64="true"><![CDATA[` + base64.StdEncoding.EncodeToString([]byte("GET /app.js HTTP/2\r\nHost: app.target.com\r\nOrigin: https://evil.com\r\n\r\n")) + `]]></request>
+    <response base64="true"><![CDATA[` + base64.StdEncoding.EncodeToString([]byte(compressed)) + `]]></response>
+  </item>
+  <item>
+    <url><![CDATA[https://app.target.com/missing]]></url>
+    <status>404</status>
+    <response base64="false"><![CDATA[` + chunked + `]]></response>
+  </item>
+  <item>
+    <url><![CDATA[https://app.target.com/timeout]]></url>
+    <response base64="true"></response>
+  </item>
+</items>`
+
+	responses, err := Parse([]byte(export))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(responses) != 2 {
+		t.Fatalf("Parse() returned %d responses, want 2", len(responses))
+	}
+	r := responses[0]
+	if r.URL != "https://app.target.com/app.js" || r.Status != 200 {
+		t.Errorf("response = %s %d", r.URL, r.Status)
+	}
+	if got := r.Header.Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("CORS header = %q", got)
+	}
+	if got := r.RequestHeader.Get("Origin"); got != "https://evil.com" {
+		t.Errorf("request Origin header = %q", got)
+	}
+	if got := string(r.Body); got != "var api = 'https://api.target.com';" {
+		t.Errorf("gzip body = %q", got)
+	}
+	if got := string(responses[1].Body); responses[1].Status != 404 || got != "hello" {
+		t.Errorf("chunked response = %d %q", responses[1].Status, got)
+	}
+}
+
+func TestParse_UnknownFormat(t *testing.T) {
+	for _, input := range []string{"plain text", `{"openapi": "3.0.0"}`, "<html></html>"} {
+		if _, err := Parse([]byte(input)); !errors.Is(err, ErrUnknownFormat) {
+			t.Errorf("Parse(%q) error = %v, want ErrUnknownFormat", input, err)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// Package transform rewrites the values of findings before they are reported, following
+// the transforms rules of the configuration file, e.g. to strip "www." from domains.
+package transform
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+// Rule rewrites the values of findings. Its steps run in the order lowercase, replace,
+// prefix, suffix; steps that are not set are skipped.
+// When Types is non-empty the rule only applies to findings of those types.
+type Rule struct {
+	Types []finding.Type
+	// Lowercase converts the value to lower case
+	Lowercase bool
+	// Pattern, when set, is replaced by Replace, which can refer to submatches as $1
+	Pattern *regexp.Regexp
+	Replace string
+	// Prefix and Suffix are added to values that do not already start or end with them
+	Prefix string
+	Suffix string
+}
+
+// Applies reports whether the rule rewrites findings of type t
+func (r Rule) Applies(t finding.Type) bool {
+	if len(r.Types) == 0 {
+		return true
+	}
+	for _, rt := range r.Types {
+		if rt == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Value returns v rewritten by the rule
+func (r Rule) Value(v string) string {
+	if r.Lowercase {
+		v = strings.ToLower(v)
+	}
+	if r.Pattern != nil {
+		v = r.Pattern.ReplaceAllString(v, r.Replace)
+	}
+	if r.Prefix != "" && !strings.HasPrefix(v, r.Prefix) {
+		v = r.Prefix + v
+	}
+	if r.Suffix != "" && !strings.HasSuffix(v, r.Suffix) {
+		v += r.Suffix
+	}
+	return v
+}
+
+// Apply rewrites the findings with every rule in order. Findings whose value becomes empty
+// are dropped, and findings that become equal are merged like duplicates.
+func Apply(findings []finding.Finding, rules []Rule) []finding.Finding {
+	if len(rules) == 0 {
+		return findings
+	}
+	set := &finding.Set{}
+	for _, f := range findings {
+		for _, rule := range rules {
+			if rule.Applies(f.Type) {
+				f.Value = rule.Value(f.Value)
+			}
+		}
+		if f.Value != "" {
+			set.Add(f)
+		}
+	}
+	return set.Findings()
+}
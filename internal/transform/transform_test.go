@@ -0,0 +1,42 @@
+package transform
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+func TestApply(t *testing.T) {
+	rules := []Rule{
+		{Types: []finding.Type{finding.TypeDomain}, Lowercase: true, Pattern: regexp.MustCompile(`^www\.`)},
+		{Types: []finding.Type{finding.TypeDomain}, Prefix: "https://", Suffix: "/"},
+		{Types: []finding.Type{finding.TypeEmail}, Pattern: regexp.MustCompile(`^([^@]+)@`), Replace: "$1 at "},
+		{Types: []finding.Type{finding.TypeParam}, Pattern: regexp.MustCompile(`^debug=.*`)},
+	}
+	findings := []finding.Finding{
+		{Type: finding.TypeDomain, Value: "WWW.Target.com", Line: 1},
+		{Type: finding.TypeDomain, Value: "target.com", Line: 2, Tags: []string{"apex"}},
+		{Type: finding.TypeDomain, Value: "https://api.target.com/"},
+		{Type: finding.TypeEmail, Value: "dev@target.com"},
+		{Type: finding.TypeParam, Value: "debug=1"},
+		{Type: finding.TypeURL, Value: "https://WWW.target.com/"},
+	}
+	want := []finding.Finding{
+		{Type: finding.TypeEmail, Value: "dev at target.com"},
+		{Type: finding.TypeDomain, Value: "https://api.target.com/"},
+		{Type: finding.TypeDomain, Value: "https://target.com/", Line: 1, Tags: []string{"apex"}},
+		{Type: finding.TypeURL, Value: "https://WWW.target.com/"},
+	}
+	if got := Apply(findings, rules); !reflect.DeepEqual(got, want) {
+		t.Errorf("Apply() = %+v, want %+v", got, want)
+	}
+}
+
+func TestApply_NoRules(t *testing.T) {
+	findings := []finding.Finding{{Type: finding.TypeDomain, Value: "b.com"}, {Type: finding.TypeDomain, Value: "a.com"}}
+	if got := Apply(findings, nil); !reflect.DeepEqual(got, findings) {
+		t.Errorf("Apply() = %+v, want the findings unchanged", got)
+	}
+}
@@ -0,0 +1,283 @@
+// Package update replaces the running urlsluice binary with the latest GitHub release.
+//
+// Every release publishes one binary per platform named "urlsluice_<os>_<arch>" (with an
+// ".exe" suffix on Windows) and a "checksums.txt" file in sha256sum format. When the
+// updater has a public key, "checksums.txt.sig" must hold a valid Ed25519 signature of
+// the checksums, so a compromised download location cannot serve a modified binary.
+// Release builds carry the public key of the project's releases.
+package update
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/httpclient"
+	"github.com/PeteJStewart/urlsluice/internal/tempdir"
+)
+
+const (
+	// DefaultBaseURL is the GitHub API endpoint queried when no base URL is configured
+	DefaultBaseURL = "https://api.github.com"
+	// DefaultRepo is the repository releases are published to
+	DefaultRepo = "PeteJStewart/urlsluice"
+	// ChecksumsAsset is the release asset listing the SHA-256 of every binary
+	ChecksumsAsset = "checksums.txt"
+	// SignatureAsset is the release asset holding the Ed25519 signature of ChecksumsAsset
+	SignatureAsset = ChecksumsAsset + ".sig"
+	// maxAssetSize caps the size of a downloaded asset (100MB)
+	maxAssetSize = 100 * 1024 * 1024
+)
+
+// Release is a published GitHub release
+type Release struct {
+	TagName string  `json:"tag_name"`
+	HTMLURL string  `json:"html_url"`
+	Assets  []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a release
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// asset returns the download URL of the named asset
+func (r *Release) asset(name string) (string, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a.URL, true
+		}
+	}
+	return "", false
+}
+
+// Updater downloads and verifies releases
+type Updater struct {
+	// Client fetches the releases and their assets
+	Client httpclient.Doer
+	// BaseURL is the GitHub API endpoint (default DefaultBaseURL)
+	BaseURL string
+	// Repo is the "owner/name" repository (default DefaultRepo)
+	Repo string
+	// PublicKey, when set, is required to have signed the release checksums
+	PublicKey ed25519.PublicKey
+}
+
+// AssetName returns the name of the release binary for a platform
+func AssetName(goos, goarch string) string {
+	name := "urlsluice_" + goos + "_" + goarch
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// Latest returns the most recent non-prerelease release
+func (u *Updater) Latest(ctx context.Context) (*Release, error) {
+	base := u.BaseURL
+	if base == "" {
+		base = DefaultBaseURL
+	}
+	repo := u.Repo
+	if repo == "" {
+		repo = DefaultRepo
+	}
+
+	data, err := u.get(ctx, strings.TrimSuffix(base, "/")+"/repos/"+repo+"/releases/latest", "application/vnd.github+json")
+	if err != nil {
+		return nil, fmt.Errorf("error checking for releases: %w", err)
+	}
+	var release Release
+	if err := json.Unmarshal(data, &release); err != nil {
+		return nil, fmt.Errorf("error decoding release: %w", err)
+	}
+	if release.TagName == "" {
+		return nil, fmt.Errorf("error decoding release: missing tag name")
+	}
+	return &release, nil
+}
+
+// Download fetches the binary of release for the current platform and verifies it against
+// the release checksums, and the checksums against their signature when a public key is set
+func (u *Updater) Download(ctx context.Context, release *Release) ([]byte, error) {
+	name := AssetName(runtime.GOOS, runtime.GOARCH)
+	binaryURL, ok := release.asset(name)
+	if !ok {
+		return nil, fmt.Errorf("release %s has no binary for %s/%s", release.TagName, runtime.GOOS, runtime.GOARCH)
+	}
+	checksumsURL, ok := release.asset(ChecksumsAsset)
+	if !ok {
+		return nil, fmt.Errorf("release %s has no %s", release.TagName, ChecksumsAsset)
+	}
+
+	checksums, err := u.get(ctx, checksumsURL, "")
+	if err != nil {
+		return nil, fmt.Errorf("error downloading %s: %w", ChecksumsAsset, err)
+	}
+	if u.PublicKey != nil {
+		signatureURL, ok := release.asset(SignatureAsset)
+		if !ok {
+			return nil, fmt.Errorf("release %s is not signed", release.TagName)
+		}
+		signature, err := u.get(ctx, signatureURL, "")
+		if err != nil {
+			return nil, fmt.Errorf("error downloading %s: %w", SignatureAsset, err)
+		}
+		if !ed25519.Verify(u.PublicKey, checksums, signature) {
+			return nil, fmt.Errorf("invalid signature of %s", ChecksumsAsset)
+		}
+	}
+	want, err := checksum(checksums, name)
+	if err != nil {
+		return nil, err
+	}
+
+	binary, err := u.get(ctx, binaryURL, "")
+	if err != nil {
+		return nil, fmt.Errorf("error downloading %s: %w", name, err)
+	}
+	sum := sha256.Sum256(binary)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return nil, fmt.Errorf("checksum mismatch for %s: got %s, want %s", name, got, want)
+	}
+	return binary, nil
+}
+
+// checksum returns the SHA-256 listed for name in sha256sum formatted data
+func checksum(data []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("%s does not list %s", ChecksumsAsset, name)
+}
+
+func (u *Updater) get(ctx context.Context, rawURL, accept string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	resp, err := httpclient.Do(u.Client, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxAssetSize))
+}
+
+// rename is os.Rename; tests make it fail
+var rename = os.Rename
+
+// Replace atomically replaces the executable at path with binary, keeping its permissions.
+// The new binary is written next to the old one and renamed over it; the running process
+// keeps using the old file until it exits. Windows does not allow replacing a running
+// executable, so there the old file is moved aside to path+".old" first, and moved back
+// when the new binary cannot take its place.
+func Replace(path string, binary []byte) error {
+	return replace(path, binary, runtime.GOOS == "windows")
+}
+
+// replace implements Replace, moving the old file aside first when moveAside is set
+func replace(path string, binary []byte, moveAside bool) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".new-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tempdir.Track(tmp.Name())()
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()|0o111); err != nil {
+		return err
+	}
+
+	if !moveAside {
+		return rename(tmp.Name(), path)
+	}
+	old := path + ".old"
+	os.Remove(old)
+	if err := rename(path, old); err != nil {
+		return err
+	}
+	if err := rename(tmp.Name(), path); err != nil {
+		if rerr := rename(old, path); rerr != nil {
+			return fmt.Errorf("%w; the previous binary is left at %s: %v", err, old, rerr)
+		}
+		return err
+	}
+	return nil
+}
+
+// Newer reports whether release version latest is newer than current. Versions are compared
+// by their numeric "vMAJOR.MINOR.PATCH" prefix; a current version without one, such as a
+// "dev" build, is always considered older.
+func Newer(current, latest string) bool {
+	l, ok := parseVersion(latest)
+	if !ok {
+		return false
+	}
+	c, ok := parseVersion(current)
+	if !ok {
+		return true
+	}
+	for i := range l {
+		if l[i] != c[i] {
+			return l[i] > c[i]
+		}
+	}
+	return false
+}
+
+// parseVersion extracts the numeric parts of "v1.2.3", ignoring any suffix such as "-rc1"
+// or the "-4-gabc123" added by git describe
+func parseVersion(v string) ([3]int, bool) {
+	var parts [3]int
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	fields := strings.Split(v, ".")
+	if len(fields) == 0 || len(fields) > 3 {
+		return parts, false
+	}
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return parts, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
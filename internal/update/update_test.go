@@ -0,0 +1,200 @@
+package update
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestNewer(t *testing.T) {
+	tests := []struct {
+		current, latest string
+		want            bool
+	}{
+		{"v1.2.3", "v1.2.4", true},
+		{"v1.2.3", "v1.3.0", true},
+		{"v1.9.0", "v1.10.0", true},
+		{"v1.2.3", "v1.2.3", false},
+		{"v1.2.4", "v1.2.3", false},
+		{"v1.2.3-4-gabc123", "v1.2.3", false},
+		{"v1.2.3-dirty", "v1.2.4", true},
+		{"dev", "v0.1.0", true},
+		{"v1.2.3", "nightly", false},
+	}
+
+	for _, tt := range tests {
+		if got := Newer(tt.current, tt.latest); got != tt.want {
+			t.Errorf("Newer(%q, %q) = %v, want %v", tt.current, tt.latest, got, tt.want)
+		}
+	}
+}
+
+func TestAssetName(t *testing.T) {
+	if got := AssetName("linux", "amd64"); got != "urlsluice_linux_amd64" {
+		t.Errorf("AssetName(linux) = %q", got)
+	}
+	if got := AssetName("windows", "arm64"); got != "urlsluice_windows_arm64.exe" {
+		t.Errorf("AssetName(windows) = %q", got)
+	}
+}
+
+// releaseServer serves a release of binary with the given checksums and optional signature
+func releaseServer(t *testing.T, binary, checksums, signature []byte) *httptest.Server {
+	t.Helper()
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/" + DefaultRepo + "/releases/latest":
+			assets := fmt.Sprintf(`{"name": %q, "browser_download_url": "%s/binary"}, {"name": "checksums.txt", "browser_download_url": "%s/checksums"}`,
+				AssetName(runtime.GOOS, runtime.GOARCH), srv.URL, srv.URL)
+			if signature != nil {
+				assets += fmt.Sprintf(`, {"name": "checksums.txt.sig", "browser_download_url": "%s/signature"}`, srv.URL)
+			}
+			fmt.Fprintf(w, `{"tag_name": "v9.9.9", "assets": [%s]}`, assets)
+		case "/binary":
+			w.Write(binary)
+		case "/checksums":
+			w.Write(checksums)
+		case "/signature":
+			w.Write(signature)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func sha256Line(data []byte) string {
+	sum := sha256.Sum256(data)
+	return fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum[:]), AssetName(runtime.GOOS, runtime.GOARCH))
+}
+
+func TestUpdater_Download(t *testing.T) {
+	binary := []byte("new binary")
+	checksums := []byte("0000  urlsluice_plan9_mips\n" + sha256Line(binary))
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherPrivate, _ := ed25519.GenerateKey(nil)
+
+	tests := []struct {
+		name      string
+		served    []byte
+		checksums []byte
+		signature []byte
+		key       ed25519.PublicKey
+		wantErr   string
+	}{
+		{name: "checksum only", served: binary, checksums: checksums},
+		{name: "signed", served: binary, checksums: checksums, signature: ed25519.Sign(private, checksums), key: public},
+		{name: "modified binary", served: []byte("evil binary"), checksums: checksums, wantErr: "checksum mismatch"},
+		{name: "unlisted binary", served: binary, checksums: []byte("0000  other\n"), wantErr: "does not list"},
+		{name: "unsigned", served: binary, checksums: checksums, key: public, wantErr: "is not signed"},
+		{name: "wrong signature", served: binary, checksums: checksums, signature: ed25519.Sign(otherPrivate, checksums), key: public, wantErr: "invalid signature"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := releaseServer(t, tt.served, tt.checksums, tt.signature)
+			u := &Updater{BaseURL: srv.URL, PublicKey: tt.key}
+
+			release, err := u.Latest(context.Background())
+			if err != nil {
+				t.Fatalf("Latest() error = %v", err)
+			}
+			if release.TagName != "v9.9.9" {
+				t.Errorf("TagName = %q, want v9.9.9", release.TagName)
+			}
+
+			got, err := u.Download(context.Background(), release)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Download() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Download() error = %v", err)
+			}
+			if string(got) != string(binary) {
+				t.Errorf("Download() = %q, want %q", got, binary)
+			}
+		})
+	}
+}
+
+func TestReplace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "urlsluice")
+	if err := os.WriteFile(path, []byte("old"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Replace(path, []byte("new")); err != nil {
+		t.Fatalf("Replace() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "new" {
+		t.Errorf("binary = %q, want new", data)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0o711 {
+		t.Errorf("mode = %v, want 0711", info.Mode().Perm())
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("directory has %d entries, want only the binary", len(entries))
+	}
+}
+
+func TestReplace_MoveAside(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "urlsluice.exe")
+	if err := os.WriteFile(path, []byte("old"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := replace(path, []byte("new"), true); err != nil {
+		t.Fatalf("replace() error = %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "new" {
+		t.Errorf("binary = %q, want new", data)
+	}
+	if data, _ := os.ReadFile(path + ".old"); string(data) != "old" {
+		t.Errorf("moved aside binary = %q, want old", data)
+	}
+
+	// When the new binary cannot take the place of the old one, the old one is moved back
+	defer func() { rename = os.Rename }()
+	rename = func(from, to string) error {
+		if strings.Contains(from, ".new-") {
+			return errors.New("access denied")
+		}
+		return os.Rename(from, to)
+	}
+	if err := replace(path, []byte("newer"), true); err == nil || err.Error() != "access denied" {
+		t.Fatalf("replace() with a failing rename error = %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "new" {
+		t.Errorf("binary after a failed replace = %q, want new", data)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("directory has %d entries after a failed replace, want only the binary", len(entries))
+	}
+}
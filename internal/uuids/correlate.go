@@ -0,0 +1,67 @@
+package uuids
+
+import (
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+// TagPredictable marks UUIDs whose generating namespace and name were recovered
+const TagPredictable = "predictable"
+
+// Correlator recovers the namespace and name behind version 3 and 5 UUIDs by
+// generating the UUIDs of every candidate name in every namespace
+type Correlator struct {
+	generated map[UUID]match
+}
+
+type match struct {
+	namespace UUID
+	name      string
+}
+
+// NewCorrelator precomputes the version 3 and 5 UUIDs of names in namespaces
+func NewCorrelator(namespaces []UUID, names []string) *Correlator {
+	c := &Correlator{generated: make(map[UUID]match, 2*len(namespaces)*len(names))}
+	for _, ns := range namespaces {
+		for _, name := range names {
+			for _, version := range []int{3, 5} {
+				c.generated[NameBased(version, ns, name)] = match{namespace: ns, name: name}
+			}
+		}
+	}
+	return c
+}
+
+// Lookup returns the namespace and name that generate u, if they are among the candidates
+func (c *Correlator) Lookup(u UUID) (namespace UUID, name string, ok bool) {
+	m, ok := c.generated[u]
+	return m.namespace, m.name, ok
+}
+
+// Correlate annotates UUID findings generated from a candidate name with the
+// "namespace" and "name" metadata and the predictable tag
+func (c *Correlator) Correlate(findings []finding.Finding) {
+	for i, f := range findings {
+		if f.Type != finding.TypeUUID {
+			continue
+		}
+		u, err := Parse(f.Value)
+		if err != nil || (u.Version() != 3 && u.Version() != 5) {
+			continue
+		}
+		if ns, name, ok := c.Lookup(u); ok {
+			findings[i].SetMeta("namespace", namespaceLabel(ns))
+			findings[i].SetMeta("name", name)
+			findings[i].AddTag(TagPredictable)
+		}
+	}
+}
+
+// namespaceLabel returns the standard name of ns, or its UUID form
+func namespaceLabel(ns UUID) string {
+	for name, u := range namespaceNames {
+		if u == ns {
+			return name
+		}
+	}
+	return ns.String()
+}
@@ -0,0 +1,129 @@
+// Package uuids analyses extracted UUIDs: decoding their fields and recovering the
+// inputs of name-based (version 3 and 5) UUIDs.
+package uuids
+
+import (
+	"crypto/md5"  // #nosec G501 -- RFC 4122 version 3 UUIDs are defined in terms of MD5
+	"crypto/sha1" // #nosec G505 -- RFC 4122 version 5 UUIDs are defined in terms of SHA-1
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// UUID is a parsed 128-bit UUID
+type UUID [16]byte
+
+// Standard namespaces defined in RFC 4122 appendix C
+var (
+	NamespaceDNS  = MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+	NamespaceURL  = MustParse("6ba7b811-9dad-11d1-80b4-00c04fd430c8")
+	NamespaceOID  = MustParse("6ba7b812-9dad-11d1-80b4-00c04fd430c8")
+	NamespaceX500 = MustParse("6ba7b814-9dad-11d1-80b4-00c04fd430c8")
+)
+
+// namespaceNames maps the names accepted by ParseNamespace to the standard namespaces
+var namespaceNames = map[string]UUID{
+	"dns":  NamespaceDNS,
+	"url":  NamespaceURL,
+	"oid":  NamespaceOID,
+	"x500": NamespaceX500,
+}
+
+// Parse decodes a UUID in the canonical 8-4-4-4-12 form
+func Parse(s string) (UUID, error) {
+	var u UUID
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return u, fmt.Errorf("invalid UUID %q", s)
+	}
+	b, err := hex.DecodeString(strings.ReplaceAll(s, "-", ""))
+	if err != nil {
+		return u, fmt.Errorf("invalid UUID %q", s)
+	}
+	copy(u[:], b)
+	return u, nil
+}
+
+// MustParse is like Parse but panics on invalid input; it is meant for constants
+func MustParse(s string) UUID {
+	u, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+// ParseNamespace accepts a standard namespace name (dns, url, oid, x500) or a UUID
+func ParseNamespace(s string) (UUID, error) {
+	if u, ok := namespaceNames[strings.ToLower(strings.TrimSpace(s))]; ok {
+		return u, nil
+	}
+	u, err := Parse(strings.ToLower(strings.TrimSpace(s)))
+	if err != nil {
+		return u, fmt.Errorf("invalid UUID namespace %q: must be dns, url, oid, x500 or a UUID", s)
+	}
+	return u, nil
+}
+
+// String formats u in the canonical lowercase form
+func (u UUID) String() string {
+	h := hex.EncodeToString(u[:])
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
+
+// Version returns the version number stored in u
+func (u UUID) Version() int {
+	return int(u[6] >> 4)
+}
+
+// NameBased returns the version 3 (MD5) or version 5 (SHA-1) UUID for name in namespace
+func NameBased(version int, namespace UUID, name string) UUID {
+	var sum []byte
+	if version == 3 {
+		h := md5.New() // #nosec G401
+		h.Write(namespace[:])
+		h.Write([]byte(name))
+		sum = h.Sum(nil)
+	} else {
+		h := sha1.New() // #nosec G401
+		h.Write(namespace[:])
+		h.Write([]byte(name))
+		sum = h.Sum(nil)
+	}
+	var u UUID
+	copy(u[:], sum)
+	u[6] = u[6]&0x0f | byte(version)<<4
+	u[8] = u[8]&0x3f | 0x80
+	return u
+}
+
+// gregorianOffset is the number of 100ns intervals between the UUID epoch
+// (1582-10-15) and the Unix epoch
+const gregorianOffset = 0x01B21DD213814000
+
+// Time returns the creation time embedded in version 1, 6 and 7 UUIDs
+func (u UUID) Time() (time.Time, bool) {
+	switch u.Version() {
+	case 1:
+		ts := uint64(binary.BigEndian.Uint16(u[6:8])&0x0fff)<<48 |
+			uint64(binary.BigEndian.Uint16(u[4:6]))<<32 |
+			uint64(binary.BigEndian.Uint32(u[0:4]))
+		return gregorianTime(ts), true
+	case 6:
+		ts := uint64(binary.BigEndian.Uint32(u[0:4]))<<28 |
+			uint64(binary.BigEndian.Uint16(u[4:6]))<<12 |
+			uint64(binary.BigEndian.Uint16(u[6:8])&0x0fff)
+		return gregorianTime(ts), true
+	case 7:
+		ms := binary.BigEndian.Uint64(append([]byte{0, 0}, u[0:6]...))
+		return time.UnixMilli(int64(ms)).UTC(), true
+	}
+	return time.Time{}, false
+}
+
+// gregorianTime converts a count of 100ns intervals since 1582-10-15 to a time
+func gregorianTime(ts uint64) time.Time {
+	unix100ns := int64(ts) - gregorianOffset
+	return time.Unix(unix100ns/1e7, (unix100ns%1e7)*100).UTC()
+}
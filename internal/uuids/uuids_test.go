@@ -0,0 +1,109 @@
+package uuids
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+func TestNameBased(t *testing.T) {
+	tests := []struct {
+		version int
+		name    string
+		want    string
+	}{
+		{3, "www.example.com", "5df41881-3aed-3515-88a7-2f4a814cf09e"},
+		{5, "www.example.com", "2ed6657d-e927-568b-95e1-2665a8aea6a2"},
+	}
+
+	for _, tt := range tests {
+		if got := NameBased(tt.version, NamespaceDNS, tt.name).String(); got != tt.want {
+			t.Errorf("NameBased(%d, dns, %q) = %s, want %s", tt.version, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseNamespace(t *testing.T) {
+	if u, err := ParseNamespace("URL"); err != nil || u != NamespaceURL {
+		t.Errorf("ParseNamespace(URL) = %s, %v, want %s", u, err, NamespaceURL)
+	}
+	if u, err := ParseNamespace("6BA7B812-9DAD-11D1-80B4-00C04FD430C8"); err != nil || u != NamespaceOID {
+		t.Errorf("ParseNamespace(uppercase OID) = %s, %v, want %s", u, err, NamespaceOID)
+	}
+	if _, err := ParseNamespace("users"); err == nil {
+		t.Error("ParseNamespace(users) error = nil, want error")
+	}
+}
+
+func TestCorrelator_Correlate(t *testing.T) {
+	custom := MustParse("0f1e2d3c-4b5a-4697-8a8b-9c9d0e0f1a2b")
+	c := NewCorrelator([]UUID{NamespaceDNS, custom}, []string{"www.example.com", "alice"})
+
+	findings := []finding.Finding{
+		{Type: finding.TypeUUID, Value: "2ed6657d-e927-568b-95e1-2665a8aea6a2"},
+		{Type: finding.TypeUUID, Value: NameBased(3, custom, "alice").String()},
+		{Type: finding.TypeUUID, Value: "550e8400-e29b-41d4-a716-446655440000"},
+		{Type: finding.TypeUUID, Value: NameBased(5, NamespaceURL, "bob").String()},
+	}
+
+	c.Correlate(findings)
+
+	want := []struct{ namespace, name string }{
+		{"dns", "www.example.com"},
+		{custom.String(), "alice"},
+		{"", ""},
+		{"", ""},
+	}
+	for i, f := range findings {
+		if f.Metadata["namespace"] != want[i].namespace || f.Metadata["name"] != want[i].name {
+			t.Errorf("%s metadata = %v, want namespace %q name %q", f.Value, f.Metadata, want[i].namespace, want[i].name)
+		}
+		if f.HasTag(TagPredictable) != (want[i].name != "") {
+			t.Errorf("%s tags = %v", f.Value, f.Tags)
+		}
+	}
+}
+
+func TestVersions(t *testing.T) {
+	findings := []finding.Finding{
+		{Type: finding.TypeUUID, Value: "550e8400-e29b-41d4-a716-446655440000"},
+		{Type: finding.TypeUUID, Value: "550e8400-e29b-41d4-a716-446655440001"},
+		{Type: finding.TypeUUID, Value: "550e8400-e29b-41d4-a716-446655440002"},
+		{Type: finding.TypeUUID, Value: "6ba7b810-9dad-11d1-80b4-00c04fd430c8"},
+		{Type: finding.TypeEmail, Value: "dev@target.com"},
+	}
+
+	got := Versions(findings)
+	MarkTimeBased(findings)
+
+	want := []VersionCount{{Version: 4, Count: 3, Percent: 75}, {Version: 1, Count: 1, Percent: 25}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Versions() = %+v, want %+v", got, want)
+	}
+	if !findings[3].HasTag(TagTimeBased) || findings[0].HasTag(TagTimeBased) {
+		t.Errorf("only the v1 UUID should be tagged %s: %+v", TagTimeBased, findings)
+	}
+}
+
+func TestUUID_Time(t *testing.T) {
+	tests := []struct {
+		uuid string
+		want time.Time
+		ok   bool
+	}{
+		// RFC 9562 appendix A test vectors
+		{"c232ab00-9414-11ec-b3c8-9f6bdeced846", time.Date(2022, 2, 22, 19, 22, 22, 0, time.UTC), true},
+		{"1ec9414c-232a-6b00-b3c8-9f6bdeced846", time.Date(2022, 2, 22, 19, 22, 22, 0, time.UTC), true},
+		{"017f22e2-79b0-7cc3-98c4-dc0c0c07398f", time.Date(2022, 2, 22, 19, 22, 22, 0, time.UTC), true},
+		{"550e8400-e29b-41d4-a716-446655440000", time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := MustParse(tt.uuid).Time()
+		if ok != tt.ok || !got.Equal(tt.want) {
+			t.Errorf("Time(%s) = %v, %v, want %v, %v", tt.uuid, got, ok, tt.want, tt.ok)
+		}
+	}
+}
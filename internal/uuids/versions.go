@@ -0,0 +1,60 @@
+package uuids
+
+import (
+	"sort"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+// TagTimeBased marks version 1 UUIDs, which embed the generating host's MAC address
+// and the time they were created
+const TagTimeBased = "time-based"
+
+// VersionCount is the number of UUIDs of one version
+type VersionCount struct {
+	Version int
+	Count   int
+	// Percent is the share of all counted UUIDs, from 0 to 100
+	Percent float64
+}
+
+// MarkTimeBased tags version 1 UUID findings with TagTimeBased
+func MarkTimeBased(findings []finding.Finding) {
+	for i, f := range findings {
+		if f.Type != finding.TypeUUID {
+			continue
+		}
+		if u, err := Parse(f.Value); err == nil && u.Version() == 1 {
+			findings[i].AddTag(TagTimeBased)
+		}
+	}
+}
+
+// Versions returns the distribution of UUID versions among findings, most common first
+func Versions(findings []finding.Finding) []VersionCount {
+	counts := make(map[int]int)
+	total := 0
+	for _, f := range findings {
+		if f.Type != finding.TypeUUID {
+			continue
+		}
+		u, err := Parse(f.Value)
+		if err != nil {
+			continue
+		}
+		counts[u.Version()]++
+		total++
+	}
+
+	dist := make([]VersionCount, 0, len(counts))
+	for v, n := range counts {
+		dist = append(dist, VersionCount{Version: v, Count: n, Percent: 100 * float64(n) / float64(total)})
+	}
+	sort.Slice(dist, func(i, j int) bool {
+		if dist[i].Count != dist[j].Count {
+			return dist[i].Count > dist[j].Count
+		}
+		return dist[i].Version < dist[j].Version
+	})
+	return dist
+}
@@ -0,0 +1,54 @@
+// Package wasmapi is the API of the WebAssembly build, cmd/urlsluice-wasm, which lets browser
+// tools run the same extractors as the command line. Calls take and return JSON strings, so
+// the JavaScript glue does not need to convert values field by field.
+package wasmapi
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/api"
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+	"github.com/PeteJStewart/urlsluice/internal/wordlist"
+)
+
+// response is the JSON document returned to JavaScript: the result of a call or its error
+type response struct {
+	Findings []finding.Finding    `json:"findings,omitempty"`
+	Counts   map[finding.Type]int `json:"counts,omitempty"`
+	Words    []string             `json:"words,omitempty"`
+	Error    string               `json:"error,omitempty"`
+}
+
+// ExtractJSON extracts from text with options, a JSON object of api.Options, and returns
+// {"findings": [...], "counts": {...}} or {"error": "..."}
+func ExtractJSON(ctx context.Context, text, options string) string {
+	opts, err := api.DecodeOptions([]byte(options))
+	if err != nil {
+		return encode(response{Error: "invalid options: " + err.Error()})
+	}
+	result, err := api.Extract(ctx, strings.NewReader(text), opts)
+	if err != nil {
+		return encode(response{Error: err.Error()})
+	}
+	return encode(response{Findings: result.Findings, Counts: result.Counts})
+}
+
+// GenerateWordlistJSON generates the wordlist of urls, a JSON array of strings, and returns
+// {"words": [...]} or {"error": "..."}
+func GenerateWordlistJSON(urls string) string {
+	var list []string
+	if err := json.Unmarshal([]byte(urls), &list); err != nil {
+		return encode(response{Error: "invalid URL list: " + err.Error()})
+	}
+	return encode(response{Words: wordlist.GenerateWordlist(list)})
+}
+
+func encode(r response) string {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return `{"error": "encoding the response failed"}`
+	}
+	return string(data)
+}
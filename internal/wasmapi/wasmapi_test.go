@@ -0,0 +1,57 @@
+package wasmapi
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+func TestExtractJSON(t *testing.T) {
+	text := "admin@target.com wrote https://intranet.corp/page?id=7&utm_source=mail and https://example.com/"
+	got := ExtractJSON(context.Background(), text, `{"emails": true, "domains": true, "queryParams": true}`)
+
+	var resp struct {
+		Findings []finding.Finding `json:"findings"`
+	}
+	if err := json.Unmarshal([]byte(got), &resp); err != nil {
+		t.Fatalf("ExtractJSON() = %s: %v", got, err)
+	}
+	var values []string
+	for _, f := range resp.Findings {
+		values = append(values, string(f.Type)+":"+f.Value)
+	}
+	// Reserved domains are dropped and tracking parameters reported apart, as on the command line
+	want := []string{"email:admin@target.com", "domain:intranet.corp", "param:id=7", "tracking_param:utm_source=mail"}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("ExtractJSON() findings = %v, want %v", values, want)
+	}
+	if !resp.Findings[1].HasTag(finding.TagInternal) {
+		t.Errorf("intranet.corp tags = %v, want internal", resp.Findings[1].Tags)
+	}
+}
+
+func TestExtractJSON_Errors(t *testing.T) {
+	tests := map[string]string{
+		``:                  `{"error":"no extractors enabled"}`,
+		`{"emails": "yes"}`: `{"error":"invalid options: json: cannot unmarshal`,
+		`{"emails": true, "minConfidence": "sure"}`: `{"error":"minConfidence: invalid confidence level \"sure\": must be low, medium or high"}`,
+	}
+	for options, want := range tests {
+		if got := ExtractJSON(context.Background(), "admin@target.com", options); !strings.HasPrefix(got, want) {
+			t.Errorf("ExtractJSON(%q) = %s, want %s", options, got, want)
+		}
+	}
+}
+
+func TestGenerateWordlistJSON(t *testing.T) {
+	if got, want := GenerateWordlistJSON(`["https://target.com/admin/users?id=1"]`), `{"words":["admin","users"]}`; got != want {
+		t.Errorf("GenerateWordlistJSON() = %s, want %s", got, want)
+	}
+	if got := GenerateWordlistJSON(`"https://target.com/"`); !strings.HasPrefix(got, `{"error":"invalid URL list: `) {
+		t.Errorf("GenerateWordlistJSON() of a string = %s, want an error", got)
+	}
+}
@@ -0,0 +1,111 @@
+// Package watch notices changes to configuration files by comparing their contents between
+// polls, which works the same on every platform and file system, including network mounts
+// and files replaced by editors or deployment tools rather than written in place.
+package watch
+
+import (
+	"bytes"
+	"os"
+	"strings"
+)
+
+// Files tracks the contents of a set of files
+type Files struct {
+	contents map[string][]byte
+	order    []string
+}
+
+// Change is a file whose contents differ from the last poll. A file that does not exist or
+// cannot be read has nil contents.
+type Change struct {
+	Path     string
+	Old, New []byte
+}
+
+// New returns a Files tracking paths from their current contents
+func New(paths ...string) *Files {
+	f := &Files{}
+	f.Set(paths...)
+	return f
+}
+
+// Set replaces the tracked files with paths. Files that were already tracked keep the
+// contents they had at the last poll, so changes made meanwhile are still reported.
+func (f *Files) Set(paths ...string) {
+	contents := make(map[string][]byte, len(paths))
+	f.order = f.order[:0]
+	for _, path := range paths {
+		if _, ok := contents[path]; ok {
+			continue
+		}
+		data, ok := f.contents[path]
+		if !ok {
+			data = read(path)
+		}
+		contents[path] = data
+		f.order = append(f.order, path)
+	}
+	f.contents = contents
+}
+
+// Poll reads the tracked files and returns those that changed since the last poll, in the
+// order they were given
+func (f *Files) Poll() []Change {
+	var changes []Change
+	for _, path := range f.order {
+		data := read(path)
+		old := f.contents[path]
+		if bytes.Equal(old, data) && (old == nil) == (data == nil) {
+			continue
+		}
+		f.contents[path] = data
+		changes = append(changes, Change{Path: path, Old: old, New: data})
+	}
+	return changes
+}
+
+// read returns the contents of path, or nil when it cannot be read
+func read(path string) []byte {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	if data == nil {
+		data = []byte{}
+	}
+	return data
+}
+
+// Lines returns the lines added to and removed from a file by a change, ignoring blank
+// lines and lines that only moved. Lines that occur several times are counted.
+func (c Change) Lines() (added, removed []string) {
+	count := make(map[string]int)
+	for _, line := range lines(c.Old) {
+		count[line]++
+	}
+	for _, line := range lines(c.New) {
+		if count[line] > 0 {
+			count[line]--
+			continue
+		}
+		added = append(added, line)
+	}
+	for _, line := range lines(c.Old) {
+		if count[line] > 0 {
+			count[line]--
+			removed = append(removed, line)
+		}
+	}
+	return added, removed
+}
+
+// lines returns the non-blank lines of data without trailing white space
+func lines(data []byte) []string {
+	var out []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimRight(line, " \t\r"); strings.TrimSpace(line) != "" {
+			out = append(out, line)
+		}
+	}
+	return out
+}
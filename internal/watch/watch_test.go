@@ -0,0 +1,62 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFiles_Poll(t *testing.T) {
+	dir := t.TempDir()
+	scope := filepath.Join(dir, "scope.txt")
+	config := filepath.Join(dir, "urlsluice.yaml")
+	write := func(path, data string) {
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(scope, "*.target.com\n")
+
+	files := New(scope, config, scope)
+	if changes := files.Poll(); len(changes) != 0 {
+		t.Fatalf("Poll() without changes = %+v", changes)
+	}
+
+	write(scope, "*.target.com\n")
+	write(config, "tags: []\n")
+	changes := files.Poll()
+	if len(changes) != 1 || changes[0].Path != config || changes[0].Old != nil || string(changes[0].New) != "tags: []\n" {
+		t.Fatalf("Poll() after creating a file = %+v", changes)
+	}
+
+	// A file changed before Set keeps being compared with the contents of the last poll
+	write(scope, "*.target.com\n10.0.0.0/8\n")
+	files.Set(scope)
+	changes = files.Poll()
+	if len(changes) != 1 || changes[0].Path != scope {
+		t.Fatalf("Poll() after Set = %+v", changes)
+	}
+
+	if err := os.Remove(scope); err != nil {
+		t.Fatal(err)
+	}
+	changes = files.Poll()
+	if len(changes) != 1 || changes[0].New != nil {
+		t.Fatalf("Poll() after removing a file = %+v", changes)
+	}
+}
+
+func TestChange_Lines(t *testing.T) {
+	c := Change{
+		Old: []byte("a.target.com\nb.target.com\n\nc.target.com\nc.target.com\n"),
+		New: []byte("c.target.com\na.target.com\r\nd.target.com\n"),
+	}
+	added, removed := c.Lines()
+	if want := []string{"d.target.com"}; !reflect.DeepEqual(added, want) {
+		t.Errorf("added = %q, want %q", added, want)
+	}
+	if want := []string{"b.target.com", "c.target.com"}; !reflect.DeepEqual(removed, want) {
+		t.Errorf("removed = %q, want %q", removed, want)
+	}
+}
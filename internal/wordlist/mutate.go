@@ -0,0 +1,76 @@
+package wordlist
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Mutations are the prefixes and suffixes added to the words of a wordlist, turning the
+// target's own vocabulary into names of staging copies and forgotten backups
+type Mutations struct {
+	Prefixes []string
+	Suffixes []string
+}
+
+// defaultMutations are the environment and backup naming conventions most often found
+// next to production paths
+var defaultMutations = Mutations{
+	Prefixes: []string{"dev-", "test-", "staging-", "old-", "backup-", "_"},
+	Suffixes: []string{"-dev", "-test", "-staging", "-old", "-backup", "_old", "_bak", ".bak", ".old", ".orig", "~"},
+}
+
+// DefaultMutations returns the built-in prefixes and suffixes
+func DefaultMutations() Mutations {
+	return Mutations{
+		Prefixes: append([]string(nil), defaultMutations.Prefixes...),
+		Suffixes: append([]string(nil), defaultMutations.Suffixes...),
+	}
+}
+
+// ValidateMutations checks the prefixes and suffixes of the wordlist_mutations section of
+// the configuration file
+func ValidateMutations(m Mutations) error {
+	for _, list := range []struct {
+		name    string
+		affixes []string
+	}{{"prefixes", m.Prefixes}, {"suffixes", m.Suffixes}} {
+		for i, affix := range list.affixes {
+			if affix == "" {
+				return fmt.Errorf("wordlist_mutations.%s[%d]: value is required", list.name, i)
+			}
+			if strings.ContainsAny(affix, " \t/") {
+				return fmt.Errorf("wordlist_mutations.%s[%d]: %q: spaces and / are not allowed", list.name, i, affix)
+			}
+		}
+	}
+	return nil
+}
+
+// With returns a copy of m whose prefixes or suffixes are replaced by those of custom, for
+// each list custom sets
+func (m Mutations) With(custom Mutations) Mutations {
+	c := Mutations{Prefixes: m.Prefixes, Suffixes: m.Suffixes}
+	if len(custom.Prefixes) > 0 {
+		c.Prefixes = custom.Prefixes
+	}
+	if len(custom.Suffixes) > 0 {
+		c.Suffixes = custom.Suffixes
+	}
+	return c
+}
+
+// Apply returns words together with every word with each prefix and each suffix added,
+// sorted and without duplicates
+func (m Mutations) Apply(words []string) []string {
+	mutated := make([]string, 0, len(words)*(1+len(m.Prefixes)+len(m.Suffixes)))
+	for _, w := range words {
+		mutated = append(mutated, w)
+		for _, prefix := range m.Prefixes {
+			mutated = append(mutated, prefix+w)
+		}
+		for _, suffix := range m.Suffixes {
+			mutated = append(mutated, w+suffix)
+		}
+	}
+	return Merge(mutated)
+}
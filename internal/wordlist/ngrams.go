@@ -0,0 +1,48 @@
+package wordlist
+
+import (
+	"net/url"
+	"strings"
+)
+
+// MaxNGrams is the longest run of path tokens joined into a word
+const MaxNGrams = 4
+
+// ngramSeparators join adjacent tokens, following the common naming styles of directories
+// such as admin-panel and user_settings
+var ngramSeparators = []string{"-", "_"}
+
+// PathNGrams returns the runs of 2 up to n adjacent tokens in the path of a URL, in
+// lowercase and joined by each of - and _, so /admin/panel gives admin-panel and
+// admin_panel. Runs stop at the tokens keep rejects, such as numbers and stopwords, so
+// /users/42/settings gives no users-settings. Invalid URLs give none.
+func PathNGrams(urlStr string, n int, keep func(token string) bool) []string {
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return nil
+	}
+
+	var ngrams []string
+	var run []string
+	flush := func() {
+		for size := 2; size <= n; size++ {
+			for i := 0; i+size <= len(run); i++ {
+				for _, sep := range ngramSeparators {
+					ngrams = append(ngrams, strings.Join(run[i:i+size], sep))
+				}
+			}
+		}
+		run = run[:0]
+	}
+	for _, segment := range strings.Split(u.Path, "/") {
+		for _, token := range Tokenize(segment) {
+			if !keep(token) {
+				flush()
+				continue
+			}
+			run = append(run, strings.ToLower(token))
+		}
+	}
+	flush()
+	return ngrams
+}
@@ -0,0 +1,133 @@
+package wordlist
+
+import (
+	"sort"
+	"strings"
+)
+
+const (
+	// LanguageAuto selects the stopwords of the language detected in the tokens
+	LanguageAuto = "auto"
+	// LanguageNone keeps stopwords in the wordlist
+	LanguageNone = "none"
+	// LanguageDefault is used when too few stopwords are found to tell the language
+	LanguageDefault = "en"
+)
+
+// minStopwordHits is the number of stopword occurrences needed to detect a language
+const minStopwordHits = 3
+
+// stopwordLists are the articles, prepositions, conjunctions and pronouns of each language,
+// keyed by ISO 639-1 code. Words that are also common path names, such as "about" or "new",
+// are left out, since they are worth keeping in a wordlist whatever the language.
+var stopwordLists = map[string][]string{
+	"en": {
+		"a", "an", "and", "are", "as", "at", "be", "but", "by", "for", "from", "has", "have", "her",
+		"his", "how", "in", "into", "is", "it", "its", "of", "on", "or", "our", "that", "the", "their",
+		"them", "they", "this", "to", "was", "we", "were", "what", "when", "where", "which", "who",
+		"why", "will", "with", "you", "your",
+	},
+	"de": {
+		"aber", "als", "am", "an", "auch", "auf", "aus", "bei", "bis", "das", "dass", "dem", "den",
+		"der", "des", "die", "durch", "ein", "eine", "einem", "einen", "einer", "eines", "es", "für",
+		"gegen", "hat", "im", "in", "ist", "mit", "nach", "nicht", "noch", "oder", "ohne", "sich",
+		"sie", "sind", "über", "um", "und", "uns", "unter", "vom", "von", "vor", "war", "wie", "wir",
+		"zu", "zum", "zur",
+	},
+	"fr": {
+		"au", "aux", "avec", "ce", "ces", "cette", "dans", "de", "des", "du", "elle", "en", "est",
+		"et", "il", "ils", "je", "la", "le", "les", "leur", "leurs", "lui", "mais", "nos", "notre",
+		"nous", "ou", "où", "par", "pas", "pour", "qui", "que", "sa", "sans", "se", "ses", "son",
+		"sont", "sous", "sur", "un", "une", "vos", "votre", "vous",
+	},
+	"es": {
+		"al", "como", "con", "de", "del", "el", "ella", "ellos", "en", "entre", "es", "esta", "este",
+		"hasta", "la", "las", "lo", "los", "mas", "más", "nos", "nuestra", "nuestro", "o", "para",
+		"pero", "por", "que", "se", "sin", "sobre", "su", "sus", "tu", "un", "una", "uno", "unos",
+		"y", "ya",
+	},
+	"it": {
+		"al", "alla", "alle", "agli", "che", "chi", "con", "da", "dal", "dalla", "degli", "dei",
+		"del", "della", "delle", "di", "e", "è", "gli", "il", "in", "la", "le", "lo", "ma", "nei",
+		"nel", "nella", "non", "o", "per", "più", "questo", "sono", "su", "sul", "sulla", "tra",
+		"un", "una", "uno",
+	},
+	"pt": {
+		"ao", "aos", "as", "com", "como", "da", "das", "de", "do", "dos", "e", "é", "ela", "ele",
+		"em", "entre", "mais", "mas", "na", "nas", "no", "nos", "nossa", "nosso", "o", "os", "ou",
+		"para", "pela", "pelo", "por", "que", "se", "sem", "seu", "sua", "são", "um", "uma",
+	},
+	"nl": {
+		"aan", "als", "bij", "dat", "de", "deze", "die", "dit", "door", "een", "en", "er", "het",
+		"hij", "in", "is", "je", "met", "naar", "niet", "of", "om", "onder", "ons", "onze", "op",
+		"over", "te", "tot", "uit", "van", "voor", "was", "wat", "we", "wij", "zijn", "ze", "zich",
+	},
+}
+
+// stopwords indexes stopwordLists for lookups
+var stopwords = func() map[string]map[string]struct{} {
+	index := make(map[string]map[string]struct{}, len(stopwordLists))
+	for lang, words := range stopwordLists {
+		set := make(map[string]struct{}, len(words))
+		for _, w := range words {
+			set[w] = struct{}{}
+		}
+		index[lang] = set
+	}
+	return index
+}()
+
+// Languages returns the codes of the languages with a stopword list, sorted
+func Languages() []string {
+	langs := make([]string, 0, len(stopwordLists))
+	for lang := range stopwordLists {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// ValidLanguage reports whether lang is a language code from Languages, LanguageAuto or
+// LanguageNone
+func ValidLanguage(lang string) bool {
+	_, ok := stopwordLists[lang]
+	return ok || lang == LanguageAuto || lang == LanguageNone
+}
+
+// DetectLanguage returns the language whose stopwords occur most often among tokens, counting
+// the words of tokens that contain spaces separately. Ties go to LanguageDefault, then to the
+// language first in the order of Languages. When fewer than three stopwords are found,
+// LanguageDefault is returned.
+func DetectLanguage(tokens []string) string {
+	hits := make(map[string]int, len(stopwords))
+	for _, token := range tokens {
+		for _, word := range strings.FieldsFunc(strings.ToLower(token), isWordSeparator) {
+			for lang, set := range stopwords {
+				if _, ok := set[word]; ok {
+					hits[lang]++
+				}
+			}
+		}
+	}
+
+	best := LanguageDefault
+	for _, lang := range Languages() {
+		if hits[lang] > hits[best] {
+			best = lang
+		}
+	}
+	if hits[best] < minStopwordHits {
+		return LanguageDefault
+	}
+	return best
+}
+
+// IsStopword reports whether word, in lowercase, is a stopword of lang
+func IsStopword(word, lang string) bool {
+	_, ok := stopwords[lang][word]
+	return ok
+}
+
+func isWordSeparator(r rune) bool {
+	return r == ' ' || r == '+'
+}
@@ -7,15 +7,62 @@ import (
 )
 
 func GenerateWordlist(urls []string) []string {
-	wordSet := make(map[string]struct{})
+	words, _ := Generate(urls, Options{Language: LanguageNone})
+	return words
+}
+
+// Options tune Generate
+type Options struct {
+	// Language is a code from Languages whose stopwords are dropped, LanguageAuto to detect
+	// the language of the tokens, or LanguageNone to keep every word
+	Language string
+	// NGrams adds the runs of 2 up to NGrams adjacent path tokens, joined by common
+	// separators; 0 adds none
+	NGrams int
+	// Years keeps, strips or expands the years and dates of words
+	Years Years
+	// Mutations, when set, adds every word with each of its prefixes and suffixes
+	Mutations *Mutations
+}
+
+// keeps reports whether token becomes a word: a year or date unless they are stripped, or
+// else a useful token that is not a stopword of lang
+func (o Options) keeps(token, lang string) bool {
+	if IsYearToken(token) {
+		return !o.Years.Strip
+	}
+	return IsUsefulToken(token) && !IsStopword(strings.ToLower(token), lang)
+}
+
+// Generate returns the wordlist of GenerateWordlist without the stopwords of
+// opts.Language, with the years and dates of opts.Years, and with the path n-grams of
+// opts.NGrams and the mutations of opts.Mutations. It also returns the language whose
+// stopwords were removed.
+func Generate(urls []string, opts Options) ([]string, string) {
+	var tokens []string
 	for _, urlStr := range urls {
-		tokens, err := ExtractTokensFromURL(urlStr)
+		urlTokens, err := ExtractTokensFromURL(urlStr)
 		if err != nil {
 			continue
 		}
-		for _, token := range tokens {
-			if IsUsefulToken(token) {
-				wordSet[strings.ToLower(token)] = struct{}{}
+		tokens = append(tokens, urlTokens...)
+	}
+	lang := opts.Language
+	if lang == LanguageAuto {
+		lang = DetectLanguage(tokens)
+	}
+
+	wordSet := make(map[string]struct{})
+	keep := func(token string) bool { return opts.keeps(token, lang) }
+	for _, token := range tokens {
+		if keep(token) {
+			wordSet[strings.ToLower(token)] = struct{}{}
+		}
+	}
+	if opts.NGrams >= 2 {
+		for _, urlStr := range urls {
+			for _, ngram := range PathNGrams(urlStr, opts.NGrams, keep) {
+				wordSet[ngram] = struct{}{}
 			}
 		}
 	}
@@ -24,7 +71,11 @@ func GenerateWordlist(urls []string) []string {
 		words = append(words, w)
 	}
 	sort.Strings(words)
-	return words
+	words = opts.Years.Apply(words)
+	if opts.Mutations != nil {
+		words = opts.Mutations.Apply(words)
+	}
+	return words, lang
 }
 
 func ExtractTokensFromURL(urlStr string) ([]string, error) {
@@ -49,3 +100,48 @@ func ExtractTokensFromURL(urlStr string) ([]string, error) {
 	sort.Strings(tokens)
 	return tokens, nil
 }
+
+// GenerateFromNames returns the useful names among identifiers such as GraphQL type and
+// field names, sorted. Names are kept whole and in their original case, as APIs match them exactly.
+func GenerateFromNames(names []string) []string {
+	var words []string
+	for _, name := range names {
+		if IsUsefulToken(name) {
+			words = append(words, name)
+		}
+	}
+	return Merge(words)
+}
+
+// Merge combines wordlists into one sorted list without duplicates
+func Merge(lists ...[]string) []string {
+	wordSet := make(map[string]struct{})
+	for _, list := range lists {
+		for _, w := range list {
+			wordSet[w] = struct{}{}
+		}
+	}
+	words := make([]string, 0, len(wordSet))
+	for w := range wordSet {
+		words = append(words, w)
+	}
+	sort.Strings(words)
+	return words
+}
+
+// Subtract returns the words that are not in common, such as a generic wordlist, leaving
+// the vocabulary specific to the target. Words are compared ignoring case and the slashes
+// around directory entries such as /admin/.
+func Subtract(words, common []string) []string {
+	known := make(map[string]struct{}, len(common))
+	for _, w := range common {
+		known[strings.ToLower(strings.Trim(w, "/"))] = struct{}{}
+	}
+	kept := make([]string, 0, len(words))
+	for _, w := range words {
+		if _, ok := known[strings.ToLower(strings.Trim(w, "/"))]; !ok {
+			kept = append(kept, w)
+		}
+	}
+	return kept
+}
@@ -166,3 +166,165 @@ func TestExtractTokensFromURL(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateFromNames(t *testing.T) {
+	got := GenerateFromNames([]string{"updateUser", "id", "User", "isAdmin", "updateUser", "42"})
+	want := []string{"User", "isAdmin", "updateUser"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GenerateFromNames() = %v; want %v", got, want)
+	}
+
+	merged := Merge([]string{"api", "users"}, got, []string{"users"})
+	want = []string{"User", "api", "isAdmin", "updateUser", "users"}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("Merge() = %v; want %v", merged, want)
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name   string
+		tokens []string
+		want   string
+	}{
+		{"german", []string{"angebote", "für", "die", "ganze", "familie", "und", "das", "wochenende"}, "de"},
+		{"spanish", []string{"ofertas", "de", "verano", "para", "la", "familia", "y", "los", "amigos"}, "es"},
+		{"words of a query value", []string{"comment nous contacter pour les entreprises"}, "fr"},
+		{"too few stopwords", []string{"api", "v2", "users", "de"}, LanguageDefault},
+		{"no tokens", nil, LanguageDefault},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectLanguage(tt.tokens); got != tt.want {
+				t.Errorf("DetectLanguage(%q) = %q, want %q", tt.tokens, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	urls := []string{
+		"https://shop.example.de/angebote-fuer-die-ganze-familie",
+		"https://shop.example.de/hilfe/rueckgabe-und-umtausch-von-artikeln",
+		"https://shop.example.de/konto?ansicht=bestellungen-der-letzten-woche",
+	}
+	got, lang := Generate(urls, Options{Language: LanguageAuto})
+	want := []string{"angebote", "ansicht", "artikeln", "bestellungen", "familie", "fuer", "ganze", "hilfe", "konto", "letzten", "rueckgabe", "umtausch", "woche"}
+	if lang != "de" || !reflect.DeepEqual(got, want) {
+		t.Errorf("Generate(auto) = %v, %q; want %v, \"de\"", got, lang, want)
+	}
+
+	got, lang = Generate(urls, Options{Language: LanguageNone})
+	if lang != LanguageNone || !reflect.DeepEqual(got, Merge(want, []string{"der", "die", "und", "von"})) {
+		t.Errorf("Generate(none) = %v, %q", got, lang)
+	}
+
+	// An explicit language overrides detection
+	got, _ = Generate([]string{"https://example.com/the-art-of-war"}, Options{Language: "fr"})
+	if !reflect.DeepEqual(got, []string{"art", "the", "war"}) {
+		t.Errorf("Generate(fr) = %v", got)
+	}
+}
+
+func TestPathNGrams(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		n    int
+		want []string
+	}{
+		{"pairs", "https://example.com/Admin/panel", 2, []string{"admin-panel", "admin_panel"}},
+		{"within a segment", "https://example.com/user-settings/export", 2, []string{"user-settings", "user_settings", "settings-export", "settings_export"}},
+		{"triples", "https://example.com/api/admin/users", 3, []string{"api-admin", "api_admin", "admin-users", "admin_users", "api-admin-users", "api_admin_users"}},
+		{"broken by ids and stopwords", "https://example.com/users/42/settings/for-the-team", 2, nil},
+		{"query ignored", "https://example.com/login?next=home", 2, nil},
+		{"invalid URL", "://invalid-url", 2, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keep := func(token string) bool { return Options{}.keeps(token, "en") }
+			if got := PathNGrams(tt.url, tt.n, keep); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PathNGrams(%q, %d) = %v, want %v", tt.url, tt.n, got, tt.want)
+			}
+		})
+	}
+
+	got, _ := Generate([]string{"https://example.com/admin/panel"}, Options{Language: LanguageNone, NGrams: 2})
+	want := []string{"admin", "admin-panel", "admin_panel", "panel"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Generate() with n-grams = %v, want %v", got, want)
+	}
+}
+
+func TestMutations(t *testing.T) {
+	m := Mutations{Prefixes: []string{"dev-"}, Suffixes: []string{".bak", "2024"}}
+	got := m.Apply([]string{"admin", "api"})
+	want := []string{"admin", "admin.bak", "admin2024", "api", "api.bak", "api2024", "dev-admin", "dev-api"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Apply() = %v, want %v", got, want)
+	}
+
+	custom := DefaultMutations().With(Mutations{Suffixes: []string{"_old"}})
+	if !reflect.DeepEqual(custom.Prefixes, defaultMutations.Prefixes) || !reflect.DeepEqual(custom.Suffixes, []string{"_old"}) {
+		t.Errorf("With() = %+v, want the built-in prefixes and the custom suffixes", custom)
+	}
+
+	words, _ := Generate([]string{"https://example.com/admin"}, Options{Language: LanguageNone, Mutations: &m})
+	if !reflect.DeepEqual(words, []string{"admin", "admin.bak", "admin2024", "dev-admin"}) {
+		t.Errorf("Generate() with mutations = %v", words)
+	}
+}
+
+func TestParseYears(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Years
+		wantErr bool
+	}{
+		{"keep", Years{}, false},
+		{"strip", Years{Strip: true}, false},
+		{"2019-2025", Years{From: 2019, To: 2025}, false},
+		{"2024-2024", Years{From: 2024, To: 2024}, false},
+		{"2025-2019", Years{}, true},
+		{"1800-1850", Years{}, true},
+		{"1990-2099", Years{}, true},
+		{"2019", Years{}, true},
+		{"all", Years{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseYears(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseYears(%q) = %+v, %v", tt.in, got, err)
+		}
+	}
+}
+
+func TestYears(t *testing.T) {
+	urls := []string{"https://example.com/backups/db-2021.sql", "https://example.com/exports/report20230115.csv?page=2"}
+
+	got, _ := Generate(urls, Options{Language: LanguageNone})
+	want := []string{"2021", "backups", "csv", "exports", "page", "report20230115", "sql"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Generate() keeping years = %v, want %v", got, want)
+	}
+
+	got, _ = Generate(urls, Options{Language: LanguageNone, Years: Years{Strip: true}})
+	want = []string{"backups", "csv", "exports", "page", "report", "sql"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Generate() stripping years = %v, want %v", got, want)
+	}
+
+	got, _ = Generate([]string{"https://example.com/archive/site-2021"}, Options{Language: LanguageNone, NGrams: 2, Years: Years{From: 2020, To: 2021}})
+	want = []string{"2020", "2021", "archive", "archive-site", "archive_site", "site", "site-2020", "site-2021", "site_2020", "site_2021"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Generate() expanding years = %v, want %v", got, want)
+	}
+}
+
+func TestSubtract(t *testing.T) {
+	got := Subtract([]string{"admin", "billing-export", "isAdmin", "login", "tenantid"}, []string{"Admin", "/login/", "isadmin"})
+	want := []string{"billing-export", "tenantid"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Subtract() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,159 @@
+package wordlist
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// YearsKeep keeps years and dates in the wordlist as they are found
+	YearsKeep = "keep"
+	// YearsStrip drops years and dates, and removes them from the start and end of words
+	YearsStrip = "strip"
+)
+
+// minYear, maxYear bound the numbers taken for years
+const (
+	minYear = 1900
+	maxYear = 2099
+)
+
+// maxYearSpan is the largest number of years a range expands to
+const maxYearSpan = 50
+
+// digitsRegex matches the digit runs that may be years or dates
+var digitsRegex = regexp.MustCompile(`\d+`)
+
+// Years decides what happens to the years and dates in wordlist words, such as the 2021 of
+// backup-2021.zip or the 20240115 of export_20240115.csv. The zero value keeps them.
+type Years struct {
+	// Strip drops years and dates
+	Strip bool
+	// From and To, when set, replace every year with each year of the range
+	From, To int
+}
+
+// ParseYears parses YearsKeep, YearsStrip or a range of years such as 2019-2025
+func ParseYears(s string) (Years, error) {
+	switch s {
+	case YearsKeep:
+		return Years{}, nil
+	case YearsStrip:
+		return Years{Strip: true}, nil
+	}
+	from, to, ok := strings.Cut(s, "-")
+	start, err1 := strconv.Atoi(from)
+	end, err2 := strconv.Atoi(to)
+	if !ok || err1 != nil || err2 != nil || !isYear(from) || !isYear(to) {
+		return Years{}, errors.New("must be keep, strip or a range of years such as 2019-2025")
+	}
+	if start > end {
+		return Years{}, errors.New("the range starts after it ends")
+	}
+	if end-start >= maxYearSpan {
+		return Years{}, fmt.Errorf("the range spans more than %d years", maxYearSpan)
+	}
+	return Years{From: start, To: end}, nil
+}
+
+// IsYearToken reports whether token is a year between 1900 and 2099, or a date written as
+// YYYYMMDD with such a year
+func IsYearToken(token string) bool {
+	return isYear(token) || isDate(token)
+}
+
+// Apply returns words with their years and dates stripped or expanded, sorted and without
+// duplicates. Words that are only a year or a date are dropped when stripping; words left
+// shorter than three characters are dropped too.
+func (y Years) Apply(words []string) []string {
+	if !y.Strip && y.From == 0 {
+		return words
+	}
+	var result []string
+	for _, w := range words {
+		switch {
+		case y.Strip:
+			if stripped := stripYears(w); len(stripped) >= 3 {
+				result = append(result, stripped)
+			}
+		default:
+			result = append(result, w)
+			result = append(result, y.expand(w)...)
+		}
+	}
+	if y.From != 0 {
+		for year := y.From; year <= y.To; year++ {
+			result = append(result, strconv.Itoa(year))
+		}
+	}
+	return Merge(result)
+}
+
+// expand returns word with its years replaced by each year of the range, so report-2021
+// gives report-2019 to report-2025. Every year of a word is replaced by the same year.
+func (y Years) expand(word string) []string {
+	spans := yearSpans(word)
+	if len(spans) == 0 {
+		return nil
+	}
+	variants := make([]string, 0, y.To-y.From+1)
+	for year := y.From; year <= y.To; year++ {
+		var b strings.Builder
+		last := 0
+		for _, span := range spans {
+			b.WriteString(word[last:span[0]])
+			b.WriteString(strconv.Itoa(year))
+			last = span[0] + 4
+		}
+		b.WriteString(word[last:])
+		variants = append(variants, b.String())
+	}
+	return variants
+}
+
+// yearSpans returns the positions of the digit runs of word that are years or dates
+func yearSpans(word string) [][]int {
+	var spans [][]int
+	for _, loc := range digitsRegex.FindAllStringIndex(word, -1) {
+		if IsYearToken(word[loc[0]:loc[1]]) {
+			spans = append(spans, loc)
+		}
+	}
+	return spans
+}
+
+// stripYears removes the years and dates at the start and end of word, with the separator
+// joining them to the rest of it
+func stripYears(word string) string {
+	spans := yearSpans(word)
+	if len(spans) == 0 {
+		return word
+	}
+	if last := spans[len(spans)-1]; last[1] == len(word) {
+		word = strings.TrimRight(word[:last[0]], "-_.")
+	}
+	if first := spans[0]; first[0] == 0 && first[1] <= len(word) {
+		word = strings.TrimLeft(word[first[1]:], "-_.")
+	}
+	return word
+}
+
+func isYear(s string) bool {
+	if len(s) != 4 || !isNumeric(s) {
+		return false
+	}
+	year, _ := strconv.Atoi(s)
+	return year >= minYear && year <= maxYear
+}
+
+func isDate(s string) bool {
+	if len(s) != 8 || !isYear(s[:4]) || !isNumeric(s) {
+		return false
+	}
+	month, _ := strconv.Atoi(s[4:6])
+	day, _ := strconv.Atoi(s[6:])
+	return month >= 1 && month <= 12 && day >= 1 && day <= 31
+}
@@ -0,0 +1,151 @@
+// Package xref cross-references findings, such as the domains that appear inside parameter
+// values, which are potential open redirect or SSRF sinks, and the emails of other
+// organizations.
+package xref
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+// Ref is a value together with the findings it was found in or derived from
+type Ref struct {
+	// Value is the cross-referenced value, e.g. a domain
+	Value string `json:"value"`
+	// In are the values of the findings referring to it, sorted
+	In []string `json:"in"`
+}
+
+// Report holds the cross-references between findings
+type Report struct {
+	// ParamDomains are the domains found in parameter values; with a scope, only those out of scope
+	ParamDomains []Ref `json:"param_domains,omitempty"`
+	// ExternalEmails are the domains of emails that are out of scope, with the emails at each;
+	// they are only reported with a scope
+	ExternalEmails []Ref `json:"external_emails,omitempty"`
+}
+
+// Empty reports whether r holds no cross-references
+func (r Report) Empty() bool {
+	return len(r.ParamDomains) == 0 && len(r.ExternalEmails) == 0
+}
+
+// Index collects the findings of a run for cross-referencing. It is safe for concurrent use
+// and the zero value is ready to use.
+type Index struct {
+	mu     sync.Mutex
+	params map[string]bool
+	emails map[string]bool
+}
+
+// Add records the parameters and emails among findings
+func (x *Index) Add(findings []finding.Finding) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	for _, f := range findings {
+		switch f.Type {
+		case finding.TypeParam:
+			if x.params == nil {
+				x.params = make(map[string]bool)
+			}
+			x.params[f.Value] = true
+		case finding.TypeEmail:
+			if x.emails == nil {
+				x.emails = make(map[string]bool)
+			}
+			x.emails[f.Value] = true
+		}
+	}
+}
+
+// Report cross-references the collected findings. inScope reports whether a host belongs to
+// the target; when it is nil every domain in a parameter value is reported and emails are not
+// checked.
+func (x *Index) Report(inScope func(host string) bool) Report {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	paramDomains := make(map[string][]string)
+	for param := range x.params {
+		_, value, _ := strings.Cut(param, "=")
+		host := valueHost(value)
+		if host == "" || inScope != nil && inScope(host) {
+			continue
+		}
+		paramDomains[host] = append(paramDomains[host], param)
+	}
+
+	emailDomains := make(map[string][]string)
+	if inScope != nil {
+		for email := range x.emails {
+			i := strings.LastIndex(email, "@")
+			if i < 0 {
+				continue
+			}
+			domain := strings.ToLower(email[i+1:])
+			if !inScope(domain) {
+				emailDomains[domain] = append(emailDomains[domain], email)
+			}
+		}
+	}
+	return Report{ParamDomains: refs(paramDomains), ExternalEmails: refs(emailDomains)}
+}
+
+// refs converts a map of values to referring findings into sorted refs
+func refs(m map[string][]string) []Ref {
+	var out []Ref
+	for value, in := range m {
+		sort.Strings(in)
+		out = append(out, Ref{Value: value, In: in})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Value < out[j].Value })
+	return out
+}
+
+// hostnameRegex matches a bare domain name with an alphabetic TLD, e.g. "evil.com"
+var hostnameRegex = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)*\.[a-z]{2,}$`)
+
+// valueHost returns the domain a parameter value points to: the host of an absolute or
+// scheme-relative URL, or a bare domain optionally followed by a path. Values are
+// percent-decoded first; IP addresses and anything else yield "".
+func valueHost(value string) string {
+	if decoded, err := url.QueryUnescape(value); err == nil {
+		value = decoded
+	}
+	value = strings.ToLower(strings.TrimSpace(value))
+	if strings.HasPrefix(value, "//") {
+		value = "https:" + value
+	}
+	host := value
+	if strings.Contains(value, "://") {
+		u, err := url.Parse(value)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return ""
+		}
+		host = u.Hostname()
+	} else {
+		if i := strings.IndexAny(value, "/?#"); i >= 0 {
+			host = value[:i]
+		}
+		// Without a scheme, "report.pdf" looks like a domain too
+		if fileExtensions[host[strings.LastIndex(host, ".")+1:]] {
+			return ""
+		}
+	}
+	host = strings.TrimSuffix(host, ".")
+	if !hostnameRegex.MatchString(host) {
+		return ""
+	}
+	return host
+}
+
+// fileExtensions are common file name extensions that are not mistaken for TLDs
+var fileExtensions = map[string]bool{
+	"css": true, "csv": true, "gif": true, "htm": true, "html": true, "jpeg": true, "jpg": true, "js": true,
+	"json": true, "log": true, "pdf": true, "php": true, "png": true, "svg": true, "txt": true, "xml": true,
+}
@@ -0,0 +1,56 @@
+package xref
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PeteJStewart/urlsluice/internal/finding"
+)
+
+func TestValueHost(t *testing.T) {
+	tests := map[string]string{
+		"https://evil.com/login":      "evil.com",
+		"https%3A%2F%2FEvil.com%2F":   "evil.com",
+		"//cdn.evil.com/x.js":         "cdn.evil.com",
+		"partner.example.org/welcome": "partner.example.org",
+		"evil.com":                    "evil.com",
+		"/home":                       "",
+		"12":                          "",
+		"10.0.0.1":                    "",
+		"javascript://evil.com":       "",
+		"report.pdf":                  "",
+	}
+	for value, want := range tests {
+		if got := valueHost(value); got != want {
+			t.Errorf("valueHost(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestIndex_Report(t *testing.T) {
+	x := &Index{}
+	x.Add([]finding.Finding{
+		{Type: finding.TypeParam, Value: "next=https://evil.com/"},
+		{Type: finding.TypeParam, Value: "return=https%3A%2F%2Fevil.com%2Fx"},
+		{Type: finding.TypeParam, Value: "callback=https://sso.target.com/"},
+		{Type: finding.TypeParam, Value: "id=7"},
+		{Type: finding.TypeEmail, Value: "ops@target.com"},
+		{Type: finding.TypeEmail, Value: "dev@agency.io"},
+		{Type: finding.TypeDomain, Value: "evil.com"},
+	})
+	inScope := func(host string) bool { return host == "target.com" || strings.HasSuffix(host, ".target.com") }
+
+	want := Report{
+		ParamDomains:   []Ref{{Value: "evil.com", In: []string{"next=https://evil.com/", "return=https%3A%2F%2Fevil.com%2Fx"}}},
+		ExternalEmails: []Ref{{Value: "agency.io", In: []string{"dev@agency.io"}}},
+	}
+	if got := x.Report(inScope); !reflect.DeepEqual(got, want) {
+		t.Errorf("Report() = %+v, want %+v", got, want)
+	}
+
+	got := x.Report(nil)
+	if len(got.ParamDomains) != 2 || got.ParamDomains[1].Value != "sso.target.com" || got.ExternalEmails != nil {
+		t.Errorf("Report(nil) = %+v, want every parameter domain and no emails", got)
+	}
+}
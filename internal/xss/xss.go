@@ -0,0 +1,201 @@
+// Package xss flags URL parameters that are likely reflected into a page in a way that
+// could lead to cross-site scripting: values carrying HTML or JavaScript markup and
+// parameters named after common sinks such as JSONP callbacks.
+package xss
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/PeteJStewart/urlsluice/internal/regexcache"
+	"github.com/PeteJStewart/urlsluice/internal/yamlcheck"
+)
+
+// Reasons a parameter is reported
+const (
+	// ReasonMarkup marks values containing HTML or JavaScript markup
+	ReasonMarkup = "markup"
+	// ReasonSink marks parameters whose name or value is a common sink name
+	ReasonSink = "sink"
+)
+
+// XSSDetector holds configuration for XSS-prone parameter detection
+type XSSDetector struct {
+	sinkParams     []string
+	markupPatterns []*regexp.Regexp
+}
+
+// Config represents the YAML configuration structure
+type Config struct {
+	// SinkParams replace the default names of parameters commonly reflected into responses
+	SinkParams []string `yaml:"sink_params"`
+	// MarkupPatterns replace the default regular expressions matching markup in values
+	MarkupPatterns []string `yaml:"markup_patterns"`
+}
+
+// defaultSinkParams are parameter names that are commonly echoed into HTML or script
+var defaultSinkParams = []string{
+	"callback",
+	"jsonp",
+	"cb",
+	"html",
+	"template",
+	"message",
+	"msg",
+	"error",
+}
+
+// defaultMarkupPatterns match tags, event handlers, script URLs and attribute breakouts
+var defaultMarkupPatterns = []string{
+	`<\s*/?\s*[a-zA-Z!]`,
+	`(?i)\bon[a-z]+\s*=`,
+	`(?i)javascript\s*:`,
+	`["'][\s/]*>`,
+	`(?i)\b(alert|prompt|confirm|eval)\s*\(`,
+}
+
+// NewXSSDetector creates a detector from the YAML configuration at configPath; the built-in
+// sink names and markup patterns are used when configPath is empty or missing
+func NewXSSDetector(configPath string) (*XSSDetector, error) {
+	params := defaultSinkParams
+	patterns := defaultMarkupPatterns
+
+	if configPath != "" {
+		config, err := loadConfig(configPath)
+		if err != nil {
+			return nil, err
+		}
+		if len(config.SinkParams) > 0 {
+			params = config.SinkParams
+		}
+		if len(config.MarkupPatterns) > 0 {
+			patterns = config.MarkupPatterns
+		}
+	}
+
+	d := &XSSDetector{sinkParams: params}
+	for _, p := range patterns {
+		re, err := regexcache.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid markup pattern: %w", configPath, err)
+		}
+		d.markupPatterns = append(d.markupPatterns, re)
+	}
+	return d, nil
+}
+
+func loadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Config{}, nil
+		}
+		return nil, err
+	}
+
+	var config Config
+	if _, err := yamlcheck.Decode(data, &config); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return &config, nil
+}
+
+// XSSResult represents the result of scanning a URL for XSS-prone parameters
+type XSSResult struct {
+	URL           string
+	IsVulnerable  bool
+	MatchedParams []MatchedParameter
+}
+
+// MatchedParameter contains details about a flagged parameter
+type MatchedParameter struct {
+	Name   string
+	Value  string
+	Reason string // ReasonMarkup or ReasonSink
+}
+
+// ScanURLs analyzes multiple URLs for XSS-prone parameters, skipping repeated URLs
+func (d *XSSDetector) ScanURLs(urls []string) []XSSResult {
+	seen := make(map[string]bool)
+	results := make([]XSSResult, 0, len(urls))
+
+	for _, u := range urls {
+		if seen[u] {
+			continue
+		}
+		seen[u] = true
+		results = append(results, d.ScanURL(u))
+	}
+	return results
+}
+
+// ScanURL analyzes a single URL; parameters with markup in their values are listed before
+// sink parameters
+func (d *XSSDetector) ScanURL(urlStr string) XSSResult {
+	result := XSSResult{
+		URL:           urlStr,
+		MatchedParams: make([]MatchedParameter, 0),
+	}
+
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return result
+	}
+
+	for param, values := range u.Query() {
+		for _, value := range values {
+			reason := ""
+			switch {
+			case d.hasMarkup(value):
+				reason = ReasonMarkup
+			case d.isSink(param) || d.isSink(value):
+				reason = ReasonSink
+			default:
+				continue
+			}
+			result.IsVulnerable = true
+			result.MatchedParams = append(result.MatchedParams, MatchedParameter{
+				Name:   param,
+				Value:  value,
+				Reason: reason,
+			})
+		}
+	}
+
+	sort.SliceStable(result.MatchedParams, func(i, j int) bool {
+		a, b := result.MatchedParams[i], result.MatchedParams[j]
+		if a.Reason != b.Reason {
+			return a.Reason == ReasonMarkup
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Value < b.Value
+	})
+	return result
+}
+
+// hasMarkup reports whether value matches one of the markup patterns
+func (d *XSSDetector) hasMarkup(value string) bool {
+	for _, re := range d.markupPatterns {
+		if re.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSink reports whether s is one of the sink names
+func (d *XSSDetector) isSink(s string) bool {
+	for _, sink := range d.sinkParams {
+		if strings.EqualFold(s, sink) {
+			return true
+		}
+	}
+	return false
+}
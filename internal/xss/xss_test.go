@@ -0,0 +1,97 @@
+package xss
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScanURL(t *testing.T) {
+	detector, err := NewXSSDetector("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		url  string
+		want []MatchedParameter
+	}{
+		{
+			name: "script tag",
+			url:  "https://example.com/search?q=%3Cscript%3Ealert(1)%3C/script%3E&page=2",
+			want: []MatchedParameter{{Name: "q", Value: "<script>alert(1)</script>", Reason: ReasonMarkup}},
+		},
+		{
+			name: "attribute breakout",
+			url:  "https://example.com/?name=x%22%3E",
+			want: []MatchedParameter{{Name: "name", Value: `x">`, Reason: ReasonMarkup}},
+		},
+		{
+			name: "event handler",
+			url:  "https://example.com/?img=x%20onerror%3Dfoo",
+			want: []MatchedParameter{{Name: "img", Value: "x onerror=foo", Reason: ReasonMarkup}},
+		},
+		{
+			name: "javascript url",
+			url:  "https://example.com/?link=JavaScript:void(0)",
+			want: []MatchedParameter{{Name: "link", Value: "JavaScript:void(0)", Reason: ReasonMarkup}},
+		},
+		{
+			name: "sink names",
+			url:  "https://example.com/api?format=jsonp&callback=handle&q=%3Cb%3E",
+			want: []MatchedParameter{
+				{Name: "q", Value: "<b>", Reason: ReasonMarkup},
+				{Name: "callback", Value: "handle", Reason: ReasonSink},
+				{Name: "format", Value: "jsonp", Reason: ReasonSink},
+			},
+		},
+		{
+			name: "plain values",
+			url:  "https://example.com/?q=shoes&a=1%3C2",
+			want: []MatchedParameter{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := detector.ScanURL(tt.url)
+			if !reflect.DeepEqual(result.MatchedParams, tt.want) {
+				t.Errorf("ScanURL(%s) = %+v, want %+v", tt.url, result.MatchedParams, tt.want)
+			}
+			if result.IsVulnerable != (len(tt.want) > 0) {
+				t.Errorf("ScanURL(%s).IsVulnerable = %v", tt.url, result.IsVulnerable)
+			}
+		})
+	}
+}
+
+func TestNewXSSDetector_Config(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "xss.yaml")
+	config := "sink_params:\n  - echo\nmarkup_patterns:\n  - '\\{\\{'\n"
+	if err := os.WriteFile(path, []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	detector, err := NewXSSDetector(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := detector.ScanURL("https://example.com/?echo=hi&t=%7B%7B7*7%7D%7D&callback=x&q=%3Cb%3E")
+	want := []MatchedParameter{
+		{Name: "t", Value: "{{7*7}}", Reason: ReasonMarkup},
+		{Name: "echo", Value: "hi", Reason: ReasonSink},
+	}
+	if !reflect.DeepEqual(result.MatchedParams, want) {
+		t.Errorf("ScanURL() = %+v, want %+v", result.MatchedParams, want)
+	}
+
+	if err := os.WriteFile(path, []byte("markup_patterns:\n  - '('\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewXSSDetector(path); err == nil || !strings.Contains(err.Error(), "invalid markup pattern") {
+		t.Errorf("NewXSSDetector() error = %v, want invalid markup pattern", err)
+	}
+}
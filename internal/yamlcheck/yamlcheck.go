@@ -0,0 +1,208 @@
+// Package yamlcheck decodes YAML configuration files strictly: keys that do not match a field
+// are reported, with the closest known key as a suggestion, instead of being silently
+// ignored, and errors point at the line and column they refer to.
+package yamlcheck
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/PeteJStewart/urlsluice/internal/suggest"
+)
+
+// Error is a problem at a position of a YAML document
+type Error struct {
+	Line, Column int
+	Err          error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("line %d, column %d: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Decode decodes data into v, a pointer to a struct, like yaml.Unmarshal. Every key that
+// does not match a field of the struct, or of the structs nested in it, is reported before
+// anything is decoded. The parsed document is returned for Locate.
+func Decode(data []byte, v interface{}) (*yaml.Node, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	if root.Kind == 0 {
+		// An empty document leaves v unchanged, like yaml.Unmarshal
+		return &root, nil
+	}
+	if errs := check(&root, reflect.TypeOf(v), ""); len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	if err := root.Decode(v); err != nil {
+		return nil, err
+	}
+	return &root, nil
+}
+
+// unmarshalerType is the interface of types that decode themselves
+var unmarshalerType = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+
+// check returns an error for each unknown key in node, which is decoded into a value of type
+// t at path
+func check(node *yaml.Node, t reflect.Type, path string) []error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil
+		}
+		return check(node.Content[0], t, path)
+	case yaml.AliasNode:
+		return check(node.Alias, t, path)
+	}
+	if reflect.PointerTo(t).Implements(unmarshalerType) {
+		return nil
+	}
+
+	var errs []error
+	switch {
+	case t.Kind() == reflect.Struct && node.Kind == yaml.MappingNode:
+		fields := make(map[string]reflect.Type)
+		structFields(t, fields)
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "<<" {
+				continue
+			}
+			ft, ok := fields[key.Value]
+			if !ok {
+				msg := fmt.Sprintf("unknown key %q", key.Value)
+				if path != "" {
+					msg += " in " + path
+				}
+				if s := suggest.Closest(key.Value, names); s != "" {
+					msg += fmt.Sprintf(", did you mean %q?", s)
+				}
+				errs = append(errs, &Error{Line: key.Line, Column: key.Column, Err: errors.New(msg)})
+				continue
+			}
+			errs = append(errs, check(value, ft, join(path, key.Value))...)
+		}
+	case (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && node.Kind == yaml.SequenceNode:
+		for i, item := range node.Content {
+			errs = append(errs, check(item, t.Elem(), path+"["+strconv.Itoa(i)+"]")...)
+		}
+	case t.Kind() == reflect.Map && node.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			errs = append(errs, check(node.Content[i+1], t.Elem(), join(path, node.Content[i].Value))...)
+		}
+	}
+	// Anything else, such as a scalar where a struct is expected, is left for Decode to report
+	return errs
+}
+
+// structFields records the YAML keys of the fields of t and their types, following the
+// naming rules of yaml.v3: the name in the yaml tag, or else the lower-cased field name
+func structFields(t reflect.Type, fields map[string]reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("yaml")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if strings.Contains(opts, "inline") {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				structFields(ft, fields)
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields[name] = f.Type
+	}
+}
+
+// join appends a key to a path such as "profiles"
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// Locate adds the position of the node an error is about to err, when its message starts
+// with the path of a node of root, as in "tags[0]: pattern is required" or
+// "profiles.recon: ...". Other errors are returned unchanged.
+func Locate(root *yaml.Node, err error) error {
+	if err == nil || root == nil {
+		return err
+	}
+	path, _, ok := strings.Cut(err.Error(), ": ")
+	if !ok || strings.ContainsAny(path, " \t") {
+		return err
+	}
+	if node := lookup(root, path); node != nil {
+		return &Error{Line: node.Line, Column: node.Column, Err: err}
+	}
+	return err
+}
+
+// lookup returns the node at path, such as "tags[0]" or "profiles.recon", or nil. Map
+// entries are located at their key and sequence items at the item.
+func lookup(root *yaml.Node, path string) *yaml.Node {
+	node := root
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	var at *yaml.Node
+	for _, segment := range strings.Split(path, ".") {
+		key, rest, _ := strings.Cut(segment, "[")
+		if key != "" {
+			if node.Kind != yaml.MappingNode {
+				return nil
+			}
+			var found bool
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == key {
+					at, node, found = node.Content[i], node.Content[i+1], true
+					break
+				}
+			}
+			if !found {
+				return nil
+			}
+		}
+		for rest != "" {
+			index, next, ok := strings.Cut(rest, "]")
+			n, err := strconv.Atoi(index)
+			if !ok || err != nil || node.Kind != yaml.SequenceNode || n < 0 || n >= len(node.Content) {
+				return nil
+			}
+			node = node.Content[n]
+			at = node
+			rest = strings.TrimPrefix(next, "[")
+		}
+	}
+	return at
+}
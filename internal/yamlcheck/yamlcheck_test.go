@@ -0,0 +1,81 @@
+package yamlcheck
+
+import (
+	"errors"
+	"testing"
+)
+
+type rule struct {
+	Pattern string   `yaml:"pattern"`
+	Tag     string   `yaml:"tag"`
+	Types   []string `yaml:"types"`
+	ignored string
+}
+
+type document struct {
+	Tags     []rule                 `yaml:"tags"`
+	Profiles map[string]interface{} `yaml:"profiles"`
+	Named    map[string]rule        `yaml:"named"`
+	Limit    int
+	Hidden   string `yaml:"-"`
+}
+
+func TestDecode(t *testing.T) {
+	var doc document
+	data := "tags:\n  - &staging\n    pattern: staging\n    tag: staging\n  - *staging\nprofiles:\n  recon: {anything: true}\nlimit: 3\n"
+	if _, err := Decode([]byte(data), &doc); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if len(doc.Tags) != 2 || doc.Tags[1].Tag != "staging" || doc.Limit != 3 {
+		t.Errorf("Decode() = %+v", doc)
+	}
+	if _, err := Decode(nil, &doc); err != nil {
+		t.Errorf("Decode() of an empty document error = %v", err)
+	}
+}
+
+func TestDecode_UnknownKeys(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{"tgas: []\n", `line 1, column 1: unknown key "tgas", did you mean "tags"?`},
+		{"tags:\n  - pattern: x\n    tga: y\n", `line 3, column 5: unknown key "tga" in tags[0], did you mean "tag"?`},
+		{"named:\n  a:\n    scope: x\n", `line 3, column 5: unknown key "scope" in named.a`},
+		{"hidden: x\ntags:\n  - ignored: y\n", "line 1, column 1: unknown key \"hidden\"\nline 3, column 5: unknown key \"ignored\" in tags[0]"},
+	}
+	for _, tt := range tests {
+		var doc document
+		_, err := Decode([]byte(tt.data), &doc)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("Decode(%q) error = %v, want %q", tt.data, err, tt.want)
+		}
+	}
+}
+
+func TestLocate(t *testing.T) {
+	var doc document
+	root, err := Decode([]byte("tags:\n  - pattern: a\n  - tag: b\nprofiles:\n  recon:\n    x: 1\n"), &doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]string{
+		"tags[1]: pattern is required":     "line 3, column 5: tags[1]: pattern is required",
+		"profiles.recon: x: unsupported":   "line 5, column 3: profiles.recon: x: unsupported",
+		"tags[7]: pattern is required":     "tags[7]: pattern is required",
+		"duplicate job \"a\": in the file": "duplicate job \"a\": in the file",
+	}
+	for msg, want := range tests {
+		cause := errors.New(msg)
+		got := Locate(root, cause)
+		if got.Error() != want {
+			t.Errorf("Locate(%q) = %q, want %q", msg, got, want)
+		}
+		if !errors.Is(got, cause) {
+			t.Errorf("Locate(%q) does not wrap the error", msg)
+		}
+	}
+	if err := Locate(root, nil); err != nil {
+		t.Errorf("Locate(nil) = %v", err)
+	}
+}
@@ -2,7 +2,7 @@
 # test.sh
 
 # Run tests with coverage
-go test -coverprofile=coverage.out
+go test -coverprofile=coverage.out ./...
 
 # Display coverage report
 go tool cover -func=coverage.out